@@ -7,8 +7,7 @@ import (
 	"testing"
 )
 
-func createTestResource(t *testing.T, dir string) string {
-	content := `apiVersion: ai-resource/draft
+const testRuleYAML = `apiVersion: ai-resource/draft
 kind: Rule
 metadata:
   id: testRule
@@ -17,8 +16,10 @@ spec:
   enforcement: must
   body: Test rule body
 `
+
+func createTestResource(t *testing.T, dir string) string {
 	path := filepath.Join(dir, "test.yaml")
-	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+	if err := os.WriteFile(path, []byte(testRuleYAML), 0644); err != nil {
 		t.Fatalf("Failed to create test resource: %v", err)
 	}
 	return path
@@ -28,7 +29,7 @@ func TestCompileStdoutSingleTarget(t *testing.T) {
 	dir := t.TempDir()
 	resourceFile := createTestResource(t, dir)
 
-	err := compile(resourceFile, []string{"markdown"}, "stdout", false)
+	err := compile(resourceFile, buildConfig{Targets: []string{"markdown"}, Output: "stdout"})
 	if err != nil {
 		t.Fatalf("Expected no error, got: %v", err)
 	}
@@ -38,7 +39,7 @@ func TestCompileStdoutMultipleTargets(t *testing.T) {
 	dir := t.TempDir()
 	resourceFile := createTestResource(t, dir)
 
-	err := compile(resourceFile, []string{"markdown", "kiro"}, "stdout", false)
+	err := compile(resourceFile, buildConfig{Targets: []string{"markdown", "kiro"}, Output: "stdout"})
 	if err != nil {
 		t.Fatalf("Expected no error, got: %v", err)
 	}
@@ -49,7 +50,7 @@ func TestCompileFilesSingleTarget(t *testing.T) {
 	resourceFile := createTestResource(t, dir)
 	outputDir := filepath.Join(dir, "output")
 
-	err := compile(resourceFile, []string{"markdown"}, outputDir, false)
+	err := compile(resourceFile, buildConfig{Targets: []string{"markdown"}, Output: outputDir})
 	if err != nil {
 		t.Fatalf("Expected no error, got: %v", err)
 	}
@@ -65,7 +66,7 @@ func TestCompileFilesMultipleTargets(t *testing.T) {
 	resourceFile := createTestResource(t, dir)
 	outputDir := filepath.Join(dir, "output")
 
-	err := compile(resourceFile, []string{"markdown", "kiro"}, outputDir, false)
+	err := compile(resourceFile, buildConfig{Targets: []string{"markdown", "kiro"}, Output: outputDir})
 	if err != nil {
 		t.Fatalf("Expected no error, got: %v", err)
 	}
@@ -87,7 +88,7 @@ func TestCompileFilesFlat(t *testing.T) {
 	resourceFile := createTestResource(t, dir)
 	outputDir := filepath.Join(dir, "output")
 
-	err := compile(resourceFile, []string{"markdown"}, outputDir, true)
+	err := compile(resourceFile, buildConfig{Targets: []string{"markdown"}, Output: outputDir, Flat: true})
 	if err != nil {
 		t.Fatalf("Expected no error, got: %v", err)
 	}
@@ -104,7 +105,7 @@ func TestCompileFilesFlat(t *testing.T) {
 }
 
 func TestCompileErrorMissingFile(t *testing.T) {
-	err := compile("nonexistent.yaml", []string{"markdown"}, "stdout", false)
+	err := compile("nonexistent.yaml", buildConfig{Targets: []string{"markdown"}, Output: "stdout"})
 	if err == nil {
 		t.Fatal("Expected error for missing file, got nil")
 	}
@@ -120,7 +121,7 @@ func TestCompileErrorInvalidYAML(t *testing.T) {
 		t.Fatalf("Failed to create invalid YAML: %v", err)
 	}
 
-	err := compile(path, []string{"markdown"}, "stdout", false)
+	err := compile(path, buildConfig{Targets: []string{"markdown"}, Output: "stdout"})
 	if err == nil {
 		t.Fatal("Expected error for invalid YAML, got nil")
 	}
@@ -133,11 +134,143 @@ func TestCompileErrorUnknownTarget(t *testing.T) {
 	dir := t.TempDir()
 	resourceFile := createTestResource(t, dir)
 
-	err := compile(resourceFile, []string{"invalid"}, "stdout", false)
+	err := compile(resourceFile, buildConfig{Targets: []string{"invalid"}, Output: "stdout"})
 	if err == nil {
 		t.Fatal("Expected error for unknown target, got nil")
 	}
 	if !strings.Contains(err.Error(), "unknown target") {
 		t.Errorf("Expected 'unknown target' error, got: %v", err)
 	}
+
+	aliases := map[string]targetAlias{"strict-cursor": {Target: "cursor"}}
+	err = compile(resourceFile, buildConfig{Targets: []string{"strict-cursr"}, Aliases: aliases, Output: "stdout"})
+	if err == nil || !strings.Contains(err.Error(), "did you mean strict-cursor?") {
+		t.Errorf("Expected alias suggestion, got: %v", err)
+	}
+}
+
+func TestCompileWithOverlay(t *testing.T) {
+	dir := t.TempDir()
+	resourceFile := createTestResource(t, dir)
+	overlayFile := filepath.Join(dir, "prod.yaml")
+	if err := os.WriteFile(overlayFile, []byte("spec:\n  enforcement: should\n"), 0644); err != nil {
+		t.Fatalf("Failed to create overlay: %v", err)
+	}
+	outputDir := filepath.Join(dir, "output")
+
+	err := compile(resourceFile, buildConfig{Targets: []string{"markdown"}, Output: outputDir, Overlays: []string{overlayFile}})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	content, err := os.ReadFile(filepath.Join(outputDir, "markdown", "testRule.md"))
+	if err != nil {
+		t.Fatalf("Failed to read output: %v", err)
+	}
+	if !strings.Contains(string(content), "# Test Rule (SHOULD)") {
+		t.Errorf("Overlay not applied, got:\n%s", content)
+	}
+}
+
+func TestCompileCopilotInstallLayout(t *testing.T) {
+	dir := t.TempDir()
+	resourceFile := createTestResource(t, dir)
+	promptFile := writeTestFile(t, dir, "prompt.yaml", `apiVersion: ai-resource/draft
+kind: Prompt
+metadata:
+  id: review
+spec:
+  body: Review this change.
+`)
+	githubDir := filepath.Join(dir, ".github")
+
+	for _, file := range []string{resourceFile, promptFile} {
+		if err := compile(file, buildConfig{Targets: []string{"copilot"}, Output: githubDir, Flat: true}); err != nil {
+			t.Fatalf("compile(%s) error = %v", file, err)
+		}
+	}
+
+	for _, path := range []string{
+		filepath.Join(githubDir, "instructions", "testRule.instructions.md"),
+		filepath.Join(githubDir, "prompts", "review.prompt.md"),
+	} {
+		if _, err := os.Stat(path); err != nil {
+			t.Errorf("Expected file not created: %s", path)
+		}
+	}
+}
+
+func TestCompileLean(t *testing.T) {
+	dir := t.TempDir()
+	resourceFile := createTestResource(t, dir)
+	outputDir := filepath.Join(dir, "output")
+
+	err := compile(resourceFile, buildConfig{Targets: []string{"cursor"}, Output: outputDir, Flat: true, Lean: true})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	content, err := os.ReadFile(filepath.Join(outputDir, "rules", "testRule.mdc"))
+	if err != nil {
+		t.Fatalf("Failed to read output: %v", err)
+	}
+	if strings.Contains(string(content), "id: testRule") {
+		t.Errorf("Lean output contains metadata block:\n%s", content)
+	}
+	if !strings.HasSuffix(string(content), "---\n# Test Rule (MUST)\n\nTest rule body") {
+		t.Errorf("Lean output missing header and body:\n%s", content)
+	}
+}
+
+func TestCompileEmbedSource(t *testing.T) {
+	dir := t.TempDir()
+	resourceFile := createTestResource(t, dir)
+	outputDir := filepath.Join(dir, "output")
+
+	err := compile(resourceFile, buildConfig{Targets: []string{"markdown"}, Output: outputDir, Flat: true, EmbedSource: "path"})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	content, err := os.ReadFile(filepath.Join(outputDir, "testRule.md"))
+	if err != nil {
+		t.Fatalf("Failed to read output: %v", err)
+	}
+	want := "<!-- source: " + filepath.ToSlash(resourceFile) + "#testRule -->"
+	if !strings.HasSuffix(string(content), want) {
+		t.Errorf("Output missing source comment %q:\n%s", want, content)
+	}
+}
+
+func TestCompileLocale(t *testing.T) {
+	dir := t.TempDir()
+	resourceFile := filepath.Join(dir, "test.yaml")
+	content := `apiVersion: ai-resource/draft
+kind: Rule
+metadata:
+  id: testRule
+  name: Test Rule
+spec:
+  enforcement: must
+  body: Test rule body
+  bodies:
+    es: Cuerpo de la regla
+`
+	if err := os.WriteFile(resourceFile, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to create test resource: %v", err)
+	}
+	outputDir := filepath.Join(dir, "output")
+
+	err := compile(resourceFile, buildConfig{Targets: []string{"markdown"}, Output: outputDir, Flat: true, Locale: "es"})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	output, err := os.ReadFile(filepath.Join(outputDir, "testRule.md"))
+	if err != nil {
+		t.Fatalf("Failed to read output: %v", err)
+	}
+	if !strings.Contains(string(output), "Cuerpo de la regla") || strings.Contains(string(output), "Test rule body") {
+		t.Errorf("Output does not use the es body:\n%s", output)
+	}
 }
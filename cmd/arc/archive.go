@@ -0,0 +1,127 @@
+package main
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+	"time"
+)
+
+// isArchive reports whether output names an archive to write results into
+// rather than a directory: a .zip, .tar.gz, or .tgz file.
+func isArchive(output string) bool {
+	return strings.HasSuffix(output, ".zip") || strings.HasSuffix(output, ".tar.gz") || strings.HasSuffix(output, ".tgz")
+}
+
+// archive is a zip or gzipped tar file that results are written into with
+// the layout they would have in an output directory.
+type archive struct {
+	path     string
+	file     *os.File
+	modified time.Time
+	zip      *zip.Writer
+	gzip     *gzip.Writer
+	tar      *tar.Writer
+	paths    map[string]bool
+}
+
+// createArchive creates the archive at path, its format chosen by the file
+// extension.
+func createArchive(path string) (*archive, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create archive %s: %w", path, err)
+	}
+	a := &archive{path: path, file: f, modified: time.Now(), paths: make(map[string]bool)}
+	if strings.HasSuffix(path, ".zip") {
+		a.zip = zip.NewWriter(f)
+	} else {
+		a.gzip = gzip.NewWriter(f)
+		a.tar = tar.NewWriter(a.gzip)
+	}
+	return a, nil
+}
+
+// add writes results into the archive, in per-target directories unless
+// flat is set.
+func (a *archive) add(allResults []targetResults, flat bool, summary *buildSummary) error {
+	for _, tr := range allResults {
+		for _, result := range tr.results {
+			resultPath := result.Path
+			if !flat {
+				resultPath = tr.target + "/" + result.Path
+			}
+			if err := a.write(resultPath, result.Content); err != nil {
+				return err
+			}
+			summary.addResult(tr.target, len(result.Content))
+		}
+	}
+	return nil
+}
+
+// write adds a file at name, which like a result path must stay within the
+// archive. Two files with the same name are an error.
+func (a *archive) write(name, content string) error {
+	if _, err := resultFilePath(".", name); err != nil {
+		return err
+	}
+	name = path.Clean(name)
+	if a.paths[name] {
+		return fmt.Errorf("archive %s already has a file %s", a.path, name)
+	}
+	a.paths[name] = true
+
+	var w io.Writer
+	var err error
+	if a.zip != nil {
+		w, err = a.zip.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: a.modified})
+	} else {
+		w = a.tar
+		err = a.tar.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(content)), ModTime: a.modified, Typeflag: tar.TypeReg})
+	}
+	if err == nil {
+		_, err = io.WriteString(w, content)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write %s to archive %s: %w", name, a.path, err)
+	}
+	return nil
+}
+
+// close finishes the archive. A nil archive does nothing.
+func (a *archive) close() error {
+	if a == nil {
+		return nil
+	}
+	var err error
+	if a.zip != nil {
+		err = a.zip.Close()
+	} else if err = a.tar.Close(); err == nil {
+		err = a.gzip.Close()
+	}
+	if closeErr := a.file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(a.path)
+		return fmt.Errorf("failed to write archive %s: %w", a.path, err)
+	}
+	printWrote(a.path)
+	return nil
+}
+
+// discard closes and removes an archive left incomplete by a failed build.
+// A nil archive does nothing.
+func (a *archive) discard() {
+	if a == nil {
+		return
+	}
+	a.file.Close()
+	os.Remove(a.path)
+}
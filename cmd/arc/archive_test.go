@@ -0,0 +1,106 @@
+package main
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestBuildArchive(t *testing.T) {
+	dir := t.TempDir()
+	a := writeTestFile(t, dir, "a.yaml", mergeRuleA)
+	b := writeTestFile(t, dir, "b.yaml", mergeRuleB)
+	want := "cursor/rules/ruleA.mdc,cursor/rules/ruleB.mdc,gemini/GEMINI.md"
+
+	for _, name := range []string{"bundle.zip", "bundle.tar.gz"} {
+		t.Run(name, func(t *testing.T) {
+			bundle := filepath.Join(dir, name)
+			if err := runBuild([]string{"-target", "cursor", "-target", "gemini", "-output", bundle, a, b}); err != nil {
+				t.Fatalf("runBuild() error = %v", err)
+			}
+			files := readArchive(t, bundle)
+			var names []string
+			for name := range files {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			if got := strings.Join(names, ","); got != want {
+				t.Errorf("archive files = %s, want %s", got, want)
+			}
+			if gemini := files["gemini/GEMINI.md"]; !strings.Contains(gemini, "Rule A") || !strings.Contains(gemini, "Rule B") {
+				t.Errorf("GEMINI.md in archive = %q, want both rules merged", gemini)
+			}
+		})
+	}
+}
+
+func TestBuildArchiveRemovedOnError(t *testing.T) {
+	dir := t.TempDir()
+	bad := writeTestFile(t, dir, "bad.yaml", "apiVersion: ai-resource/draft\nkind: Rule\n")
+	bundle := filepath.Join(dir, "bundle.zip")
+
+	if err := runBuild([]string{"-target", "cursor", "-output", bundle, bad}); err == nil {
+		t.Fatal("runBuild() with an invalid resource succeeded")
+	}
+	if _, err := os.Stat(bundle); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("incomplete archive was kept: %v", err)
+	}
+}
+
+// readArchive returns the files of a zip or gzipped tar archive by name.
+func readArchive(t *testing.T, path string) map[string]string {
+	t.Helper()
+	files := make(map[string]string)
+	if strings.HasSuffix(path, ".zip") {
+		r, err := zip.OpenReader(path)
+		if err != nil {
+			t.Fatalf("Failed to open archive: %v", err)
+		}
+		defer r.Close()
+		for _, f := range r.File {
+			rc, err := f.Open()
+			if err != nil {
+				t.Fatal(err)
+			}
+			data, err := io.ReadAll(rc)
+			rc.Close()
+			if err != nil {
+				t.Fatal(err)
+			}
+			files[f.Name] = string(data)
+		}
+		return files
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("Failed to open archive: %v", err)
+	}
+	defer f.Close()
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return files
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		files[header.Name] = string(data)
+	}
+}
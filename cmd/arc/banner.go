@@ -0,0 +1,80 @@
+package main
+
+import (
+	"path/filepath"
+	"runtime/debug"
+	"strings"
+
+	"github.com/jomadu/ai-resource-compiler-go/pkg/compiler"
+)
+
+// banners adds a provenance banner to each result before it is written, for
+// -banner: the generatedMarker, arc's version, and the resource files the
+// result was compiled from, with their SHA-256. A nil banners adds none.
+type banners struct {
+	version string
+	hashes  sourceHashes
+}
+
+func newBanners() *banners {
+	return &banners{version: arcVersion(), hashes: make(sourceHashes)}
+}
+
+// add returns allResults with a banner added to each result. The results
+// are copied, as cached results are shared.
+func (b *banners) add(allResults []targetResults) ([]targetResults, error) {
+	if b == nil {
+		return allResults, nil
+	}
+	withBanners := make([]targetResults, len(allResults))
+	for i, tr := range allResults {
+		withBanners[i] = tr
+		withBanners[i].results = make([]compiler.CompilationResult, len(tr.results))
+		for j, result := range tr.results {
+			banner, err := b.text(tr.sources[result.Path])
+			if err != nil {
+				return nil, err
+			}
+			if tr.banner != nil && !result.Asset {
+				result.Content = tr.banner(result.Path, result.Content, banner)
+			}
+			withBanners[i].results[j] = result
+		}
+	}
+	return withBanners, nil
+}
+
+// text returns the banner of a result compiled from files, e.g.
+// "Generated by arc v1.2.0 from rules/naming.yaml (sha256 9f86d081884c). Do
+// not edit; edit the source and rebuild."
+func (b *banners) text(files []string) (string, error) {
+	var sb strings.Builder
+	sb.WriteString(generatedMarker)
+	if b.version != "" {
+		sb.WriteString(" " + b.version)
+	}
+	for i, file := range files {
+		hash, err := b.hashes.hash(file)
+		if err != nil {
+			return "", err
+		}
+		if i == 0 {
+			sb.WriteString(" from ")
+		} else {
+			sb.WriteString(", ")
+		}
+		sb.WriteString(filepath.ToSlash(file) + " (sha256 " + hash[:12] + ")")
+	}
+	sb.WriteString(". Do not edit; edit the source and rebuild.")
+	return sb.String(), nil
+}
+
+// arcVersion returns the version arc was built as, or "" for development
+// builds.
+func arcVersion() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok || info.Main.Version == "(devel)" {
+		return ""
+	}
+	return info.Main.Version
+}
@@ -0,0 +1,279 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/jomadu/ai-resource-compiler-go/pkg/compiler"
+	"github.com/jomadu/ai-resource-compiler-go/pkg/loader"
+)
+
+func runBuild(args []string) (err error) {
+	fs := flag.NewFlagSet("build", flag.ContinueOnError)
+	var targets, overlays arrayFlags
+	fs.Var(&targets, "target", "Target format to compile to (repeatable, overrides config)")
+	fs.Var(&overlays, "overlay", "Patch file applied after config overlays (repeatable)")
+	output := fs.String("output", "stdout", "Output mode: stdout, directory path, or .zip or .tar.gz archive (overrides config)")
+	flat := fs.Bool("flat", false, "Disable target subdirectories in file output mode (overrides config)")
+	layout := fs.String("layout", "", "Output layout: target (subdirectory per target) or native (each tool's own directory under -root) (overrides config)")
+	root := fs.String("root", "", "Project root of the native layout (overrides config)")
+	lean := fs.Bool("lean", false, "Omit the metadata block from compiled rules (overrides config)")
+	banner := fs.Bool("banner", false, "Mark every file as generated, naming arc's version and the source files (overrides config)")
+	incremental := fs.Bool("incremental", false, "Compile only resources changed since the last build, tracked in "+cacheFile+" (overrides config)")
+	force := fs.Bool("force", false, "Overwrite existing files arc did not generate")
+	manifest := fs.Bool("manifest", true, "Write an .arc-manifest.json of the generated files to each output directory, so rebuilds may overwrite them (overrides config)")
+	link := fs.String("link", "", "Write files identical to one already written as links to it: symlink or hardlink (overrides config)")
+	embedSource := fs.String("embed-source", "", "Append each rule's source: path or yaml (overrides config)")
+	configPath := fs.String("config", "", configFlagUsage)
+	profile := fs.String("profile", "", "Workspace config profile to activate")
+	prefix := fs.String("prefix", "", "Prepend to every generated file name (overrides config)")
+	pathTemplate := fs.String("path-template", "", "Rewrite generated paths, e.g. {dir}/arc/{file} (overrides config)")
+	locale := fs.String("locale", "", "Compile the body variant for this locale, e.g. es (overrides config)")
+	templates := fs.Bool("templates", false, "Execute bodies and fragments as Go templates (overrides config)")
+	only := fs.String("only", "", "Compile only these comma-separated rule or prompt IDs of a collection")
+	exclude := fs.String("exclude", "", "Skip these comma-separated rule or prompt IDs of a collection")
+	minEnforcement := fs.String("min-enforcement", "", "Compile only rules at or above this enforcement level, e.g. should (overrides config)")
+	tags := fs.String("tags", "", "Compile only rules and prompts with any of these comma-separated tags (overrides config)")
+	transform := fs.String("transform", "", "Transform bodies with these comma-separated transformers, e.g. smart-quotes,wrap=100 (overrides config)")
+	report := fs.String("report", "", "Print a report after building: tokens, the estimated tokens of each compiled file")
+	tokenBudget := fs.Int("token-budget", 0, "Warn about compiled files over this many estimated tokens (overrides config)")
+	locked := fs.Bool("locked", false, "Fail if a remote include is not pinned in "+loader.LockfileName)
+	summaryJSON := fs.String("summary-json", "", "Also write the build summary as JSON to this file")
+	outputFormat := fs.String("format", "text", "Format of results printed to stdout: text or json")
+	dryRunMode := fs.Bool("dry-run", false, "List the files that would be written without writing them")
+	diffMode := fs.Bool("diff", false, "Print a unified diff of each file that differs from what would be written, and fail if any does")
+	checkMode := fs.Bool("check", false, "List the files that differ from what would be written, and fail if any does")
+	statsFile := fs.String("stats-file", "", "Write compile counts, per-target durations, and errors as JSON to this file")
+
+	files, err := parseInterspersed(fs, args)
+	if err != nil {
+		return err
+	}
+
+	if *outputFormat != "text" && *outputFormat != "json" {
+		return fmt.Errorf("unknown format: %s (valid formats: text, json)", *outputFormat)
+	}
+
+	set := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) { set[f.Name] = true })
+
+	var settings buildSettings
+	var aliases map[string]targetAlias
+	lockPath := loader.LockfileName
+	cachePath := cacheFile
+	path := findConfigFile(*configPath)
+	if path != "" {
+		ws, err := loadWorkspaceConfig(path)
+		if err != nil {
+			return err
+		}
+		lockPath = filepath.Join(ws.dir, loader.LockfileName)
+		cachePath = filepath.Join(ws.dir, cacheFile)
+		if settings, err = ws.resolve(*profile); err != nil {
+			return err
+		}
+		if aliases, err = ws.targetAliases(); err != nil {
+			return err
+		}
+	} else if *profile != "" {
+		return fmt.Errorf("-profile requires a workspace config (%s)", defaultConfigFile)
+	}
+
+	cfg := settings.buildConfig(aliases)
+	cfg.Overlays = append(cfg.Overlays, overlays...)
+	cfg.Only = splitList(*only)
+	cfg.Exclude = splitList(*exclude)
+	if cfg.Output == "" {
+		cfg.Output = "stdout"
+	}
+	if cfg.Lock, err = loader.ReadLockfile(lockPath); err != nil {
+		return err
+	}
+	cfg.Lock.Frozen = *locked
+	if set["target"] {
+		cfg.Targets = targets
+	}
+	if set["output"] {
+		// -output sends every built-in target to one place; aliases keep
+		// their own directories.
+		cfg.Output = *output
+		cfg.TargetOutputs = nil
+	}
+	if set["flat"] {
+		cfg.Flat = *flat
+	}
+	if set["layout"] {
+		cfg.Layout = *layout
+	}
+	if set["root"] {
+		cfg.Root = *root
+	}
+	if err := applyLayout(&cfg); err != nil {
+		return err
+	}
+	if set["lean"] {
+		cfg.Lean = *lean
+	}
+	if set["banner"] {
+		cfg.Banner = *banner
+	}
+	if set["manifest"] {
+		cfg.Manifest = *manifest
+	}
+	if set["link"] {
+		cfg.Link = *link
+	}
+	if set["incremental"] {
+		cfg.Incremental = *incremental
+	}
+	if set["embed-source"] {
+		cfg.EmbedSource = *embedSource
+	}
+	if set["prefix"] {
+		cfg.Prefix = *prefix
+	}
+	if set["path-template"] {
+		cfg.PathTemplate = *pathTemplate
+	}
+	if set["locale"] {
+		cfg.Locale = *locale
+	}
+	if set["min-enforcement"] {
+		cfg.MinEnforcement = *minEnforcement
+	}
+	if set["tags"] {
+		cfg.Tags = splitList(*tags)
+	}
+	if set["transform"] {
+		cfg.Transformers = splitList(*transform)
+	}
+	if set["token-budget"] {
+		cfg.TokenBudget = *tokenBudget
+	}
+	switch *report {
+	case "":
+	case "tokens":
+		cfg.Tokens = &tokenReport{}
+	default:
+		return fmt.Errorf("unknown report: %s (valid reports: tokens)", *report)
+	}
+	if set["templates"] {
+		cfg.Templates = *templates
+	}
+
+	if len(files) == 0 {
+		files = settings.Resources
+	}
+	if files, err = expandResources(files); err != nil {
+		return err
+	}
+	if len(files) == 0 {
+		return fmt.Errorf("no resource files (pass files or set resources in %s)", defaultConfigFile)
+	}
+	if len(cfg.Targets) == 0 {
+		return fmt.Errorf("at least one target required (use -target or set targets in %s)", defaultConfigFile)
+	}
+
+	cfg.Summary = newBuildSummary()
+	if *dryRunMode || *diffMode || *checkMode {
+		cfg.DryRun = &dryRun{compare: *diffMode || *checkMode}
+	}
+	if *diffMode {
+		cfg.DryRun.diff = os.Stdout
+	}
+	cfg.Pending = &pendingResults{}
+	if *outputFormat == "json" {
+		cfg.JSON = &jsonOutput{}
+	}
+	if !*force {
+		cfg.Guard = newOverwriteGuard()
+	}
+	cfg.Outputs = newOutputPaths()
+	if cfg.Banner {
+		cfg.Banners = newBanners()
+	}
+	if cfg.Manifest && cfg.DryRun == nil {
+		cfg.Manifests = newManifests()
+	}
+	if cfg.Links, err = newLinker(cfg.Link); err != nil {
+		return err
+	}
+	if isArchive(cfg.Output) && cfg.DryRun == nil {
+		if cfg.Archive, err = createArchive(cfg.Output); err != nil {
+			return err
+		}
+		defer func() {
+			if err != nil {
+				cfg.Archive.discard()
+			}
+		}()
+	}
+	if cfg.Incremental && cfg.DryRun == nil {
+		if cfg.Cache, err = readBuildCache(cachePath); err != nil {
+			return err
+		}
+	}
+	if *statsFile != "" {
+		// Failed builds are written too, so their error counts are recorded.
+		cfg.Stats = compiler.NewStats()
+		defer func() {
+			if statsErr := writeStatsFile(*statsFile, cfg.Stats); err == nil {
+				err = statsErr
+			}
+		}()
+	}
+	cfg.Rulesets = buildRulesets(files, cfg)
+	if err := cfg.Rulesets.checkItems(cfg.Only, cfg.Exclude); err != nil {
+		return err
+	}
+	if err := cfg.Manifests.check(outputDirs(cfg)); err != nil {
+		return err
+	}
+	for _, file := range files {
+		if err := compile(file, cfg); err != nil {
+			return &fileError{File: file, Err: err}
+		}
+		cfg.Summary.Resources++
+	}
+	if err := writePending(cfg); err != nil {
+		return err
+	}
+	if err := cfg.Archive.close(); err != nil {
+		return err
+	}
+	if err := cfg.JSON.write(os.Stdout); err != nil {
+		return err
+	}
+	if err := cfg.Manifests.write(); err != nil {
+		return err
+	}
+	if err := cfg.Cache.write(); err != nil {
+		return err
+	}
+
+	if cfg.DryRun != nil && cfg.DryRun.compare {
+		return cfg.DryRun.checkStale()
+	}
+	if cfg.Tokens != nil {
+		cfg.Tokens.write(os.Stderr, cfg.TokenBudget)
+	}
+	if cfg.DryRun != nil {
+		cfg.DryRun.write(os.Stdout)
+		return nil
+	}
+
+	if cfg.Lock.Changed() {
+		if err := cfg.Lock.WriteFile(lockPath); err != nil {
+			return err
+		}
+		printWrote(lockPath)
+	}
+
+	writeSummary(os.Stderr, cfg.Summary)
+	if *summaryJSON != "" {
+		return writeSummaryJSON(*summaryJSON, cfg.Summary)
+	}
+	return nil
+}
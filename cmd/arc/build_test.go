@@ -0,0 +1,911 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jomadu/ai-resource-compiler-go/pkg/compiler"
+	"github.com/jomadu/ai-resource-compiler-go/pkg/loader"
+)
+
+func TestBuildWithProfile(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "rules"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	writeTestFile(t, dir, "rules/rule.yaml", `apiVersion: ai-resource/draft
+kind: Rule
+metadata:
+  id: ownership
+  name: Ownership
+spec:
+  enforcement: should
+  body: Ask ${team} in ${channel}.
+`)
+	if err := os.MkdirAll(filepath.Join(dir, "overlays"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	writeTestFile(t, dir, "overlays/base.yaml", "metadata:\n  name: Ownership\n")
+	writeTestFile(t, dir, "overlays/prod.yaml", "spec:\n  enforcement: must\n")
+	config := writeTestFile(t, dir, "arc.yaml", testWorkspaceConfig)
+
+	if err := runBuild([]string{"-config", config, "-profile", "prod"}); err != nil {
+		t.Fatalf("runBuild() error = %v", err)
+	}
+
+	content, err := os.ReadFile(filepath.Join(dir, "out", "kiro", "ownership.md"))
+	if err != nil {
+		t.Fatalf("Failed to read output: %v", err)
+	}
+	for _, want := range []string{"# Ownership (MUST)", "Ask platform in #prod."} {
+		if !strings.Contains(string(content), want) {
+			t.Errorf("Output missing %q:\n%s", want, content)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, "out", "markdown")); !os.IsNotExist(err) {
+		t.Error("base targets compiled despite profile override")
+	}
+}
+
+func TestBuildFlagsOverrideConfig(t *testing.T) {
+	dir := t.TempDir()
+	resourceFile := createTestResource(t, dir)
+	config := writeTestFile(t, dir, "arc.yaml", "targets: [kiro]\noutput: out\n")
+	outputDir := filepath.Join(dir, "flagged")
+
+	err := runBuild([]string{"-config", config, "-target", "markdown", "-output", outputDir, "-flat", resourceFile})
+	if err != nil {
+		t.Fatalf("runBuild() error = %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(outputDir, "testRule.md")); err != nil {
+		t.Errorf("Expected flag-selected output: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "out")); !os.IsNotExist(err) {
+		t.Error("config output used despite -output flag")
+	}
+}
+
+func TestBuildErrorProfileWithoutConfig(t *testing.T) {
+	dir := t.TempDir()
+	resourceFile := createTestResource(t, dir)
+
+	err := runBuild([]string{"-config", filepath.Join(dir, "missing.yaml"), "-profile", "prod", resourceFile})
+	if err == nil {
+		t.Fatal("Expected error for missing config, got nil")
+	}
+}
+
+func TestBuildWithTargetAlias(t *testing.T) {
+	dir := t.TempDir()
+	resourceFile := writeTestFile(t, dir, "rule.yaml", `apiVersion: ai-resource/draft
+kind: Rule
+metadata:
+  id: testRule
+  name: Test Rule
+spec:
+  enforcement: should
+  body: Test rule body
+`)
+	config := writeTestFile(t, dir, "arc.yaml", `output: out
+aliases:
+  cursor-strict:
+    target: cursor
+    output: .cursor
+    options:
+      alwaysApply: true
+`)
+
+	if err := runBuild([]string{"-config", config, "-target", "cursor-strict", "-target", "cursor", resourceFile}); err != nil {
+		t.Fatalf("runBuild() error = %v", err)
+	}
+
+	content, err := os.ReadFile(filepath.Join(dir, ".cursor", "rules", "testRule.mdc"))
+	if err != nil {
+		t.Fatalf("Failed to read alias output: %v", err)
+	}
+	if !strings.Contains(string(content), "alwaysApply: true") {
+		t.Errorf("Alias options not applied:\n%s", content)
+	}
+
+	content, err = os.ReadFile(filepath.Join(dir, "out", "cursor", "rules", "testRule.mdc"))
+	if err != nil {
+		t.Fatalf("Failed to read plain target output: %v", err)
+	}
+	if !strings.Contains(string(content), "alwaysApply: false") {
+		t.Errorf("Plain target affected by alias options:\n%s", content)
+	}
+}
+
+func TestBuildMergesGeminiContext(t *testing.T) {
+	dir := t.TempDir()
+	a := writeTestFile(t, dir, "a.yaml", mergeRuleA)
+	b := writeTestFile(t, dir, "b.yaml", mergeRuleB)
+	out := filepath.Join(dir, "out")
+
+	if err := runBuild([]string{"-target", "gemini", "-output", out, "-flat", a, b}); err != nil {
+		t.Fatalf("runBuild() error = %v", err)
+	}
+
+	content, err := os.ReadFile(filepath.Join(out, "GEMINI.md"))
+	if err != nil {
+		t.Fatalf("Failed to read output: %v", err)
+	}
+	if strings.Count(string(content), "# ") != 2 {
+		t.Errorf("GEMINI.md does not hold both rules:\n%s", content)
+	}
+}
+
+func TestBuildWithDiscoveredConfig(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+	resourceFile := createTestResource(t, dir)
+	writeTestFile(t, dir, ".arc.yaml", `resources: [`+filepath.Base(resourceFile)+`]
+targets: [cursor, markdown]
+output: out
+outputs:
+  cursor: .cursor
+options:
+  cursor:
+    alwaysApply: true
+`)
+
+	if err := runBuild(nil); err != nil {
+		t.Fatalf("runBuild() error = %v", err)
+	}
+
+	content, err := os.ReadFile(filepath.Join(dir, ".cursor", "rules", "testRule.mdc"))
+	if err != nil {
+		t.Fatalf("Failed to read per-target output: %v", err)
+	}
+	if !strings.Contains(string(content), "alwaysApply: true") {
+		t.Errorf("Target options not applied:\n%s", content)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "out", "markdown", "testRule.md")); err != nil {
+		t.Errorf("Expected markdown in the default output: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "out", "cursor")); !os.IsNotExist(err) {
+		t.Error("cursor written to the default output despite outputs")
+	}
+}
+
+func TestBuildTemplates(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+	writeTestFile(t, dir, "rule.yaml", `apiVersion: ai-resource/draft
+kind: Rule
+metadata:
+  id: langs
+spec:
+  enforcement: must
+  body: "Write {{ .langs | join \" or \" }}."
+`)
+	writeTestFile(t, dir, "arc.yaml", `resources: [rule.yaml]
+targets: [markdown]
+output: out
+manifest: true
+templateData:
+  langs: [Go, Rust]
+`)
+
+	if err := runBuild(nil); err != nil {
+		t.Fatalf("runBuild() error = %v", err)
+	}
+	content, err := os.ReadFile(filepath.Join(dir, "out", "markdown", "langs.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(content), "{{ .langs") {
+		t.Errorf("body executed without templates enabled:\n%s", content)
+	}
+
+	if err := runBuild([]string{"-templates"}); err != nil {
+		t.Fatalf("runBuild(-templates) error = %v", err)
+	}
+	if content, err = os.ReadFile(filepath.Join(dir, "out", "markdown", "langs.md")); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(content), "Write Go or Rust.") {
+		t.Errorf("template not executed:\n%s", content)
+	}
+}
+
+func TestBuildWritesManifest(t *testing.T) {
+	dir := t.TempDir()
+	resourceFile := createTestResource(t, dir)
+	outputDir := filepath.Join(dir, "out")
+
+	err := runBuild([]string{"-target", "markdown", "-target", "gemini", "-output", outputDir, "-manifest", resourceFile})
+	if err != nil {
+		t.Fatalf("runBuild() error = %v", err)
+	}
+
+	source, err := os.ReadFile(resourceFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, target := range []string{"markdown", "gemini"} {
+		data, err := os.ReadFile(filepath.Join(outputDir, target, manifestFile))
+		if err != nil {
+			t.Fatalf("Failed to read %s manifest: %v", target, err)
+		}
+		var m manifest
+		if err := json.Unmarshal(data, &m); err != nil {
+			t.Fatalf("Invalid %s manifest: %v", target, err)
+		}
+		if m.CompiledAt.IsZero() || len(m.Files) != 1 {
+			t.Fatalf("%s manifest = %+v, want a timestamp and one file", target, m)
+		}
+		entry := m.Files[0]
+		content, err := os.ReadFile(filepath.Join(outputDir, target, entry.Path))
+		if err != nil {
+			t.Fatalf("Manifest lists %s, which was not written: %v", entry.Path, err)
+		}
+		if entry.Target != target || entry.SHA256 != hashBytes(content) {
+			t.Errorf("%s entry = %+v, want target %s and the hash of %s", target, entry, target, entry.Path)
+		}
+		want := manifestSource{File: filepath.ToSlash(resourceFile), SHA256: hashBytes(source)}
+		if len(entry.Sources) != 1 || entry.Sources[0] != want {
+			t.Errorf("%s sources = %+v, want [%+v]", target, entry.Sources, want)
+		}
+	}
+}
+
+func TestBuildIncrementalTemplates(t *testing.T) {
+	t.Setenv("ARC_TEAM", "platform")
+	dir := t.TempDir()
+	writeTestFile(t, dir, "team.yaml", strings.Replace(testRuleYAML, "Test rule body", `Ask {{ env "ARC_TEAM" }}.`, 1))
+	writeTestFile(t, dir, "year.yaml", strings.Replace(strings.Replace(testRuleYAML, "testRule", "yearRule", 1), "Test rule body", "Reviewed {{ now.Year }}.", 1))
+	config := writeTestFile(t, dir, "arc.yaml", "resources: [team.yaml, year.yaml]\ntargets: [cursor]\noutput: out\nincremental: true\ntemplates: true\ntemplateEnv: [ARC_TEAM]\n")
+	stats := filepath.Join(dir, "stats.json")
+	build := func() int {
+		t.Helper()
+		if err := runBuild([]string{"-config", config, "-stats-file", stats}); err != nil {
+			t.Fatalf("runBuild() error = %v", err)
+		}
+		data, err := os.ReadFile(stats)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got struct {
+			Targets map[string]struct{ Compiles int }
+		}
+		if err := json.Unmarshal(data, &got); err != nil {
+			t.Fatal(err)
+		}
+		return got.Targets["cursor"].Compiles
+	}
+
+	if got := build(); got != 2 {
+		t.Fatalf("first build compiles = %d, want 2", got)
+	}
+	// The resource calling now is never cached.
+	if got := build(); got != 1 {
+		t.Errorf("second build compiles = %d, want 1", got)
+	}
+	// A variable templates may read is part of the cached settings.
+	t.Setenv("ARC_TEAM", "security")
+	if got := build(); got != 2 {
+		t.Errorf("build after changing ARC_TEAM compiles = %d, want 2", got)
+	}
+	content, err := os.ReadFile(filepath.Join(dir, "out", "cursor", "rules", "testRule.mdc"))
+	if err != nil || !strings.Contains(string(content), "Ask security.") {
+		t.Errorf("testRule.mdc = %q, %v, want the new ARC_TEAM", content, err)
+	}
+}
+
+func TestBuildWithoutManifest(t *testing.T) {
+	dir := t.TempDir()
+	resourceFile := createTestResource(t, dir)
+	outputDir := filepath.Join(dir, "out")
+
+	if err := runBuild([]string{"-target", "markdown", "-output", outputDir, "-manifest=false", resourceFile}); err != nil {
+		t.Fatalf("runBuild() error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(outputDir, "markdown", manifestFile)); !os.IsNotExist(err) {
+		t.Error("manifest written with -manifest=false")
+	}
+}
+
+func TestBuildIncremental(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, dir, "a.yaml", mergeRuleA)
+	b := writeTestFile(t, dir, "b.yaml", mergeRuleB)
+	config := writeTestFile(t, dir, "arc.yaml", "resources: [a.yaml, b.yaml]\ntargets: [cursor, gemini]\noutput: out\nincremental: true\nmanifest: true\n")
+	stats := filepath.Join(dir, "stats.json")
+	build := func() map[string]int {
+		t.Helper()
+		if err := runBuild([]string{"-config", config, "-stats-file", stats}); err != nil {
+			t.Fatalf("runBuild() error = %v", err)
+		}
+		data, err := os.ReadFile(stats)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got struct {
+			Targets map[string]struct{ Compiles int }
+		}
+		if err := json.Unmarshal(data, &got); err != nil {
+			t.Fatal(err)
+		}
+		compiles := make(map[string]int)
+		for target, s := range got.Targets {
+			compiles[target] = s.Compiles
+		}
+		return compiles
+	}
+
+	if got := build(); got["cursor"] != 2 || got["gemini"] != 2 {
+		t.Fatalf("first build compiles = %v, want every resource", got)
+	}
+	if _, err := os.Stat(filepath.Join(dir, cacheFile)); err != nil {
+		t.Fatalf("build cache not written next to the config: %v", err)
+	}
+
+	// Only the changed resource is compiled. Gemini merges every resource
+	// into one file, with the cached results of the unchanged one.
+	if err := os.WriteFile(b, []byte(strings.Replace(mergeRuleB, "name: Rule B", "name: Rule B2", 1)), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if got := build(); got["cursor"] != 1 || got["gemini"] != 1 {
+		t.Errorf("build after editing b.yaml compiles = %v, want one per target", got)
+	}
+	gemini, err := os.ReadFile(filepath.Join(dir, "out", "gemini", "GEMINI.md"))
+	if err != nil || !strings.Contains(string(gemini), "Rule A") || !strings.Contains(string(gemini), "Rule B2") {
+		t.Errorf("GEMINI.md = %q, %v, want both rules", gemini, err)
+	}
+
+	// A generated file edited by hand is rewritten from the cache.
+	edited := filepath.Join(dir, "out", "cursor", "rules", "ruleA.mdc")
+	if err := os.WriteFile(edited, []byte("edited\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if got := build(); len(got) != 0 {
+		t.Errorf("build after editing output compiles = %v, want none", got)
+	}
+	content, err := os.ReadFile(edited)
+	if err != nil || !strings.Contains(string(content), "Rule A") {
+		t.Errorf("edited output was not rewritten: %q, %v", content, err)
+	}
+}
+
+func TestBuildNativeLayout(t *testing.T) {
+	dir := t.TempDir()
+	resourceFile := createTestResource(t, dir)
+	root := filepath.Join(dir, "project")
+
+	err := runBuild([]string{"-target", "cursor", "-target", "gemini", "-target", "markdown", "-layout", "native", "-root", root, resourceFile})
+	if err != nil {
+		t.Fatalf("runBuild() error = %v", err)
+	}
+
+	for _, want := range []string{".cursor/rules/testRule.mdc", "GEMINI.md", "markdown/testRule.md"} {
+		if _, err := os.Stat(filepath.Join(root, filepath.FromSlash(want))); err != nil {
+			t.Errorf("Expected %s under the project root: %v", want, err)
+		}
+	}
+}
+
+func TestBuildNativeLayoutDiscoveryPaths(t *testing.T) {
+	dir := t.TempDir()
+	rule := createTestResource(t, dir)
+	prompt := writeTestFile(t, dir, "review.yaml", "apiVersion: ai-resource/draft\nkind: Prompt\nmetadata:\n  id: review\nspec:\n  body: Review the diff.\n")
+	command := writeTestFile(t, dir, "fix.yaml", "apiVersion: ai-resource/draft\nkind: Command\nmetadata:\n  id: fix\nspec:\n  body: Fix the build.\n")
+	context := writeTestFile(t, dir, "project.yaml", "apiVersion: ai-resource/draft\nkind: Context\nmetadata:\n  id: project\n  name: Project\nspec:\n  body: A Go service.\n")
+	config := writeTestFile(t, dir, "arc.yaml", "layout: native\nroot: project\noptions:\n  claude:\n    claudeMd: true\n")
+	root := filepath.Join(dir, "project")
+
+	err := runBuild([]string{"-config", config, "-target", "claude", "-target", "cursor", rule, prompt, command, context})
+	if err != nil {
+		t.Fatalf("runBuild() error = %v", err)
+	}
+
+	// Where Claude and Cursor discover each kind of file.
+	for _, want := range []string{
+		".claude/rules/testRule.md",
+		".claude/skills/review/SKILL.md",
+		".claude/commands/fix.md",
+		".cursor/rules/testRule.mdc",
+		".cursor/commands/review.md",
+		".cursor/commands/fix.md",
+	} {
+		if _, err := os.Stat(filepath.Join(root, filepath.FromSlash(want))); err != nil {
+			t.Errorf("Expected %s under the project root: %v", want, err)
+		}
+	}
+	for _, notWant := range []string{".claude/CLAUDE.md", ".cursor/AGENTS.md"} {
+		if _, err := os.Stat(filepath.Join(root, filepath.FromSlash(notWant))); err == nil {
+			t.Errorf("%s written; want it at the project root", notWant)
+		}
+	}
+
+	claudeMD, err := os.ReadFile(filepath.Join(root, "CLAUDE.md"))
+	if err != nil {
+		t.Fatalf("CLAUDE.md not written to the project root: %v", err)
+	}
+	if want := "# Project\n\nA Go service.\n\n@.claude/rules/testRule.md"; !strings.Contains(string(claudeMD), want) {
+		t.Errorf("CLAUDE.md = %q, want it to contain %q", claudeMD, want)
+	}
+	agentsMD, err := os.ReadFile(filepath.Join(root, "AGENTS.md"))
+	if err != nil || !strings.Contains(string(agentsMD), "A Go service.") {
+		t.Errorf("AGENTS.md = %q, %v, want the context at the project root", agentsMD, err)
+	}
+
+	if err := runClean([]string{"-config", config, "-target", "claude", "-target", "cursor"}); err != nil {
+		t.Fatalf("runClean() error = %v", err)
+	}
+	for _, gone := range []string{"CLAUDE.md", "AGENTS.md", ".claude/rules", ".cursor/rules"} {
+		if _, err := os.Stat(filepath.Join(root, filepath.FromSlash(gone))); !os.IsNotExist(err) {
+			t.Errorf("clean kept %s: %v", gone, err)
+		}
+	}
+}
+
+func TestBuildUnknownLayout(t *testing.T) {
+	dir := t.TempDir()
+	resourceFile := createTestResource(t, dir)
+
+	err := runBuild([]string{"-target", "cursor", "-layout", "tools", resourceFile})
+	if err == nil || !strings.Contains(err.Error(), "unknown layout: tools") {
+		t.Fatalf("runBuild() error = %v, want unknown layout", err)
+	}
+}
+
+func TestBuildRefusesToOverwrite(t *testing.T) {
+	dir := t.TempDir()
+	resourceFile := createTestResource(t, dir)
+	outputDir := filepath.Join(dir, "out")
+	if err := os.MkdirAll(filepath.Join(outputDir, "markdown"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	handWritten := writeTestFile(t, dir, "out/markdown/testRule.md", "hand-written\n")
+
+	err := runBuild([]string{"-target", "markdown", "-output", outputDir, resourceFile})
+	if err == nil || !strings.Contains(err.Error(), "refusing to overwrite") {
+		t.Fatalf("runBuild() error = %v, want refusal to overwrite", err)
+	}
+	if content, _ := os.ReadFile(handWritten); string(content) != "hand-written\n" {
+		t.Errorf("hand-written file overwritten:\n%s", content)
+	}
+
+	if err := runBuild([]string{"-target", "markdown", "-output", outputDir, "-force", "-manifest", resourceFile}); err != nil {
+		t.Fatalf("runBuild(-force) error = %v", err)
+	}
+
+	// Files a manifest lists, or that carry the generated marker, are arc's.
+	os.WriteFile(handWritten, []byte("edited\n"), 0644)
+	if err := runBuild([]string{"-target", "markdown", "-output", outputDir, resourceFile}); err != nil {
+		t.Errorf("runBuild() over a file the manifest lists error = %v", err)
+	}
+	os.Remove(filepath.Join(outputDir, "markdown", manifestFile))
+	os.WriteFile(handWritten, []byte("<!-- "+generatedMarker+" -->\n"), 0644)
+	if err := runBuild([]string{"-target", "markdown", "-output", outputDir, resourceFile}); err != nil {
+		t.Errorf("runBuild() over a marked file error = %v", err)
+	}
+}
+
+func TestBuildRecompilesEditedResource(t *testing.T) {
+	dir := t.TempDir()
+	resourceFile := createTestResource(t, dir)
+	outputDir := filepath.Join(dir, "out")
+
+	if err := runBuild([]string{"-target", "cursor", "-output", outputDir, resourceFile}); err != nil {
+		t.Fatalf("runBuild() error = %v", err)
+	}
+	data, err := os.ReadFile(resourceFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(resourceFile, []byte(strings.Replace(string(data), "Test rule body", "Edited rule body", 1)), 0644); err != nil {
+		t.Fatal(err)
+	}
+	// The manifest written by default marks the first build's files as
+	// arc's, so they are replaced.
+	if err := runBuild([]string{"-target", "cursor", "-output", outputDir, resourceFile}); err != nil {
+		t.Fatalf("runBuild() after editing the resource error = %v", err)
+	}
+	compiled, err := os.ReadFile(filepath.Join(outputDir, "cursor", "rules", "testRule.mdc"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(compiled), "Edited rule body") {
+		t.Errorf("recompiled file does not hold the edited body:\n%s", compiled)
+	}
+}
+
+func TestBuildRefusesForeignManifest(t *testing.T) {
+	dir := t.TempDir()
+	resourceFile := createTestResource(t, dir)
+	outputDir := filepath.Join(dir, "out")
+	if err := os.MkdirAll(filepath.Join(outputDir, "cursor"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	foreign := writeTestFile(t, dir, "out/cursor/"+manifestFile, `{"name": "extension"}`+"\n")
+
+	err := runBuild([]string{"-target", "cursor", "-output", outputDir, resourceFile})
+	if err == nil || !strings.Contains(err.Error(), "refusing to overwrite") {
+		t.Fatalf("runBuild() error = %v, want refusal to overwrite the manifest", err)
+	}
+	if content, _ := os.ReadFile(foreign); string(content) != `{"name": "extension"}`+"\n" {
+		t.Errorf("foreign manifest overwritten:\n%s", content)
+	}
+	if entries, _ := os.ReadDir(filepath.Join(outputDir, "cursor")); len(entries) != 1 {
+		t.Errorf("build wrote results despite the foreign manifest: %v", entries)
+	}
+	if err := runBuild([]string{"-target", "cursor", "-output", outputDir, "-manifest=false", resourceFile}); err != nil {
+		t.Errorf("runBuild(-manifest=false) error = %v", err)
+	}
+}
+
+func TestBuildBanner(t *testing.T) {
+	dir := t.TempDir()
+	a := writeTestFile(t, dir, "a.yaml", mergeRuleA)
+	b := writeTestFile(t, dir, "b.yaml", mergeRuleB)
+	outputDir := filepath.Join(dir, "out")
+
+	if err := runBuild([]string{"-target", "gemini", "-target", "cursor", "-output", outputDir, "-banner", a, b}); err != nil {
+		t.Fatalf("runBuild() error = %v", err)
+	}
+	gemini, err := os.ReadFile(filepath.Join(outputDir, "gemini", "GEMINI.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(gemini), "<!-- "+generatedMarker) || strings.Count(string(gemini), generatedMarker) != 1 {
+		t.Errorf("GEMINI.md does not open with a single banner:\n%s", gemini)
+	}
+	for _, file := range []string{a, b} {
+		if !strings.Contains(string(gemini), filepath.ToSlash(file)+" (sha256 ") {
+			t.Errorf("GEMINI.md banner does not name %s:\n%s", file, gemini)
+		}
+	}
+
+	cursor, err := os.ReadFile(filepath.Join(outputDir, "cursor", "rules", "ruleA.mdc"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(cursor), "---\ngenerated: "+generatedMarker) {
+		t.Errorf("Cursor rule has no generated frontmatter key:\n%s", cursor)
+	}
+
+	// Banners mark the files as arc's, so they are overwritten without a
+	// manifest.
+	if err := os.WriteFile(a, []byte(strings.Replace(mergeRuleA, "name: Rule A", "name: Rule A2", 1)), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := runBuild([]string{"-target", "gemini", "-target", "cursor", "-output", outputDir, "-banner", a, b}); err != nil {
+		t.Errorf("runBuild() over bannered files error = %v", err)
+	}
+}
+
+func TestBuildExtends(t *testing.T) {
+	dir := t.TempDir()
+	base := writeTestFile(t, dir, "base.yaml", `apiVersion: ai-resource/draft
+kind: Ruleset
+metadata:
+  id: base
+spec:
+  rules:
+    style:
+      name: Style
+      enforcement: should
+      body: Format with gofmt.
+`)
+	writeTestFile(t, dir, "child.yaml", `apiVersion: ai-resource/draft
+kind: Ruleset
+metadata:
+  id: child
+spec:
+  extends: [base]
+  rules:
+    style:
+      enforcement: must
+`)
+	config := writeTestFile(t, dir, "arc.yaml", "resources: [base.yaml, child.yaml]\ntargets: [markdown]\noutput: out\nincremental: true\nmanifest: true\n")
+	read := func(path string) string {
+		t.Helper()
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(data)
+	}
+	childStyle := filepath.Join(dir, "out", "markdown", "child_style.md")
+
+	if err := runBuild([]string{"-config", config}); err != nil {
+		t.Fatalf("runBuild() error = %v", err)
+	}
+	if got := read(childStyle); !strings.Contains(got, "Format with gofmt.") || !strings.Contains(got, "enforcement: must") {
+		t.Errorf("child_style.md does not override the inherited rule:\n%s", got)
+	}
+
+	// Changing the base recompiles the ruleset extending it.
+	if err := os.WriteFile(base, []byte(strings.Replace(read(base), "gofmt", "goimports", 1)), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := runBuild([]string{"-config", config}); err != nil {
+		t.Fatalf("rebuild error = %v", err)
+	}
+	if got := read(childStyle); !strings.Contains(got, "Format with goimports.") {
+		t.Errorf("child_style.md is stale after its base changed:\n%s", got)
+	}
+
+	if err := runBuild([]string{"-target", "markdown", filepath.Join(dir, "child.yaml")}); err == nil || !strings.Contains(err.Error(), "extends unknown ruleset base") {
+		t.Errorf("runBuild() without the base error = %v, want unknown ruleset", err)
+	}
+}
+
+func TestBuildMinEnforcement(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, dir, "a.yaml", mergeRuleA)
+	writeTestFile(t, dir, "b.yaml", mergeRuleB)
+	config := writeTestFile(t, dir, "arc.yaml", "resources: [a.yaml, b.yaml]\ntargets: [cursor]\noutput: out\nminEnforcement: must\nmanifest: true\n")
+	outputDir := filepath.Join(dir, "out", "cursor", "rules")
+
+	if err := runBuild([]string{"-config", config}); err != nil {
+		t.Fatalf("runBuild() error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(outputDir, "ruleA.mdc")); err != nil {
+		t.Errorf("must rule not written: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(outputDir, "ruleB.mdc")); err == nil {
+		t.Error("should rule written below the minimum enforcement")
+	}
+
+	if err := runBuild([]string{"-config", config, "-min-enforcement", "should"}); err != nil {
+		t.Fatalf("runBuild() error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(outputDir, "ruleB.mdc")); err != nil {
+		t.Errorf("-min-enforcement should did not write the should rule: %v", err)
+	}
+}
+
+func TestBuildTags(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, dir, "a.yaml", strings.Replace(mergeRuleA, "  name: Rule A\n", "  name: Rule A\n  tags: [backend]\n", 1))
+	writeTestFile(t, dir, "b.yaml", strings.Replace(mergeRuleB, "  name: Rule B\n", "  name: Rule B\n  tags: [frontend]\n", 1))
+	config := writeTestFile(t, dir, "arc.yaml", "resources: [a.yaml, b.yaml]\ntargets: [cursor]\noutput: out\ntags: [backend]\nmanifest: true\n")
+	outputDir := filepath.Join(dir, "out", "cursor", "rules")
+
+	if err := runBuild([]string{"-config", config}); err != nil {
+		t.Fatalf("runBuild() error = %v", err)
+	}
+	content, err := os.ReadFile(filepath.Join(outputDir, "ruleA.mdc"))
+	if err != nil {
+		t.Fatalf("backend rule not written: %v", err)
+	}
+	if !strings.Contains(string(content), "tags:\n  - backend\n") {
+		t.Errorf("ruleA.mdc lacks its tags:\n%s", content)
+	}
+	if _, err := os.Stat(filepath.Join(outputDir, "ruleB.mdc")); err == nil {
+		t.Error("frontend rule written when selecting backend")
+	}
+
+	if err := runBuild([]string{"-config", config, "-tags", "frontend,security"}); err != nil {
+		t.Fatalf("runBuild() error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(outputDir, "ruleB.mdc")); err != nil {
+		t.Errorf("-tags frontend did not write the frontend rule: %v", err)
+	}
+}
+
+func TestBuildTransformers(t *testing.T) {
+	dir := t.TempDir()
+	rule := "apiVersion: ai-resource/draft\nkind: Rule\nmetadata:\n  id: quotes\nspec:\n  enforcement: must\n  body: \"Say \u201cno\u201d.   \\n<!-- draft -->\\nDone.\"\n"
+	writeTestFile(t, dir, "rule.yaml", rule)
+	config := writeTestFile(t, dir, "arc.yaml", "resources: [rule.yaml]\ntargets: [markdown]\noutput: out\ntransformers: [smart-quotes, trim-trailing-whitespace]\n")
+	output := filepath.Join(dir, "out", "markdown", "quotes.md")
+
+	if err := runBuild([]string{"-config", config}); err != nil {
+		t.Fatalf("runBuild() error = %v", err)
+	}
+	content, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatalf("rule not written: %v", err)
+	}
+	if !strings.Contains(string(content), "Say \"no\".\n<!-- draft -->\nDone.") {
+		t.Errorf("quotes.md not transformed by the config's transformers:\n%s", content)
+	}
+
+	if err := runBuild([]string{"-config", config, "-force", "-transform", "strip-html-comments"}); err != nil {
+		t.Fatalf("runBuild() error = %v", err)
+	}
+	if content, _ = os.ReadFile(output); !strings.Contains(string(content), "Say \u201cno\u201d.   \nDone.") {
+		t.Errorf("-transform did not replace the config's transformers:\n%s", content)
+	}
+
+	if err := runBuild([]string{"-config", config, "-transform", "shout"}); err == nil || !strings.Contains(err.Error(), "unknown transformer") {
+		t.Errorf("runBuild() error = %v, want unknown transformer", err)
+	}
+}
+
+func TestBuildOnly(t *testing.T) {
+	dir := t.TempDir()
+	style := writeTestFile(t, dir, "style.yaml", "apiVersion: ai-resource/draft\nkind: Ruleset\nmetadata:\n  id: style\nspec:\n  rules:\n    naming:\n      body: Name things well.\n    errors:\n      body: Wrap errors.\n")
+	a := writeTestFile(t, dir, "a.yaml", mergeRuleA)
+	rule := createTestResource(t, dir)
+	outputDir := filepath.Join(dir, "out", "markdown")
+
+	// IDs each name an item of some resource; the others are skipped.
+	if err := runBuild([]string{"-target", "markdown", "-output", filepath.Join(dir, "out"), "-only", "naming,ruleA", style, a, rule}); err != nil {
+		t.Fatalf("runBuild(-only) error = %v", err)
+	}
+	for file, want := range map[string]bool{"style_naming.md": true, "ruleA.md": true, "style_errors.md": false, "testRule.md": false} {
+		if _, err := os.Stat(filepath.Join(outputDir, file)); (err == nil) != want {
+			t.Errorf("%s written = %v, want %v", file, err == nil, want)
+		}
+	}
+
+	if err := runBuild([]string{"-target", "markdown", "-output", filepath.Join(dir, "other"), "-exclude", "ruleA", a, rule}); err != nil {
+		t.Fatalf("runBuild(-exclude) error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "other", "markdown", "ruleA.md")); err == nil {
+		t.Error("-exclude ruleA wrote the standalone rule")
+	}
+
+	err := runBuild([]string{"-target", "markdown", "-output", filepath.Join(dir, "out"), "-only", "naming,nope", style, a})
+	if !errors.Is(err, compiler.ErrUnknownItem) || !strings.Contains(err.Error(), "nope") {
+		t.Errorf("runBuild() error = %v, want nope reported as unknown", err)
+	}
+}
+
+func TestBuildRuleLinks(t *testing.T) {
+	dir := t.TempDir()
+	ruleset := writeTestFile(t, dir, "style.yaml", "apiVersion: ai-resource/draft\nkind: Ruleset\nmetadata:\n  id: style\nspec:\n  rules:\n    naming:\n      name: Naming\n      body: See [errors](#rule:errors).\n")
+	rule := writeTestFile(t, dir, "errors.yaml", "apiVersion: ai-resource/draft\nkind: Rule\nmetadata:\n  id: errors\nspec:\n  enforcement: must\n  body: Name them per [naming](#rule:naming).\n")
+	outputDir := filepath.Join(dir, "out")
+
+	if err := runBuild([]string{"-target", "cursor", "-target", "copilot", "-output", outputDir, ruleset, rule}); err != nil {
+		t.Fatalf("runBuild() error = %v", err)
+	}
+	for file, want := range map[string]string{
+		"cursor/rules/style_naming.mdc":                     "See [errors](errors.mdc).",
+		"cursor/rules/errors.mdc":                           "Name them per [naming](style_naming.mdc).",
+		"copilot/instructions/errors.instructions.md":       "Name them per [naming](style_naming.instructions.md).",
+		"copilot/instructions/style_naming.instructions.md": "See [errors](errors.instructions.md).",
+	} {
+		content, err := os.ReadFile(filepath.Join(outputDir, filepath.FromSlash(file)))
+		if err != nil {
+			t.Fatalf("%s not written: %v", file, err)
+		}
+		if !strings.Contains(string(content), want) {
+			t.Errorf("%s does not contain %q:\n%s", file, want, content)
+		}
+	}
+
+	if err := runBuild([]string{"-target", "cursor", "-output", outputDir, "-force", ruleset}); err == nil || !strings.Contains(err.Error(), "no rule errors") {
+		t.Errorf("runBuild() error = %v, want an unresolved link", err)
+	}
+}
+
+func TestBuildPathCollisions(t *testing.T) {
+	dir := t.TempDir()
+	rule := "apiVersion: ai-resource/draft\nkind: Rule\nmetadata:\n  id: naming\nspec:\n  enforcement: must\n  body: %s\n"
+	team := writeTestFile(t, dir, "team.yaml", fmt.Sprintf(rule, "Name things well."))
+	vendored := writeTestFile(t, dir, "vendored.yaml", fmt.Sprintf(rule, "Name things well."))
+	legacy := writeTestFile(t, dir, "legacy.yaml", fmt.Sprintf(rule, "Name things briefly."))
+	outputDir := filepath.Join(dir, "out")
+
+	if err := runBuild([]string{"-target", "cursor", "-output", outputDir, team, vendored}); err != nil {
+		t.Fatalf("runBuild() error = %v, want identical results written once", err)
+	}
+
+	err := runBuild([]string{"-target", "cursor", "-output", outputDir, team, legacy})
+	if !errors.Is(err, compiler.ErrPathCollision) {
+		t.Fatalf("runBuild() error = %v, want ErrPathCollision", err)
+	}
+	if want := team + " (cursor) and " + legacy + " (cursor)"; !strings.Contains(err.Error(), want) {
+		t.Errorf("error %q does not name %s", err, want)
+	}
+	if want := filepath.Join("cursor", "rules", "naming.mdc"); !strings.Contains(err.Error(), want) {
+		t.Errorf("error %q does not name %s", err, want)
+	}
+
+	// Paths differing only in case collide on case-insensitive file systems.
+	upper := writeTestFile(t, dir, "upper.yaml", strings.Replace(fmt.Sprintf(rule, "Name things briefly."), "id: naming", "id: Naming", 1))
+	err = runBuild([]string{"-target", "cursor", "-output", outputDir, team, upper})
+	if !errors.Is(err, compiler.ErrPathCollision) {
+		t.Errorf("runBuild() error = %v, want naming.mdc and Naming.mdc colliding", err)
+	}
+
+	// Targets sharing a flat output directory collide too.
+	scoped := writeTestFile(t, dir, "scoped.yaml", strings.Replace(fmt.Sprintf(rule, "Hi."), "  body:", "  scope: [{files: [\"**/*.go\"]}]\n  body:", 1))
+	err = runBuild([]string{"-target", "markdown", "-target", "kiro", "-flat", "-output", outputDir, scoped})
+	if !errors.Is(err, compiler.ErrPathCollision) {
+		t.Errorf("runBuild() error = %v, want markdown and kiro colliding at naming.md", err)
+	}
+}
+
+func TestBuildRemoteResource(t *testing.T) {
+	rule := "apiVersion: ai-resource/draft\nkind: Rule\nmetadata:\n  id: cleanCode\nspec:\n  enforcement: must\n  body: Keep functions small.\n"
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, rule)
+	}))
+	defer server.Close()
+	defaultTransport := http.DefaultTransport
+	http.DefaultTransport = server.Client().Transport
+	defer func() { http.DefaultTransport = defaultTransport }()
+
+	dir := t.TempDir()
+	t.Chdir(dir)
+	url := server.URL + "/packs/clean-code.yaml"
+	sum := sha256.Sum256([]byte(rule))
+	pinned := url + "#sha256=" + hex.EncodeToString(sum[:])
+
+	if err := runBuild([]string{"-target", "cursor", "-output", "out", "-banner", "-manifest", "-incremental", pinned}); err != nil {
+		t.Fatalf("runBuild() error = %v", err)
+	}
+	content, err := os.ReadFile(filepath.Join("out", "cursor", "rules", "cleanCode.mdc"))
+	if err != nil {
+		t.Fatalf("rule not written: %v", err)
+	}
+	if want := "from " + pinned + " (sha256 " + hex.EncodeToString(sum[:])[:12] + ")"; !strings.Contains(string(content), want) {
+		t.Errorf("cleanCode.mdc has no banner naming %s:\n%s", want, content)
+	}
+	lock, err := os.ReadFile(loader.LockfileName)
+	if err != nil || !strings.Contains(string(lock), url+":") {
+		t.Errorf("%s = %q, %v, want %s pinned", loader.LockfileName, lock, err, url)
+	}
+
+	if err := runBuild([]string{"-target", "cursor", "-output", "out", url + "#sha256=" + strings.Repeat("0", 64)}); err == nil || !strings.Contains(err.Error(), "checksum mismatch") {
+		t.Errorf("runBuild() error = %v, want checksum mismatch", err)
+	}
+}
+
+func TestBuildPromptAssets(t *testing.T) {
+	dir := t.TempDir()
+	binary := "\x89PNG\r\n\x1a\n\x00\xff"
+	writeTestFile(t, dir, "logo.png", binary)
+	prompt := writeTestFile(t, dir, "brand.yaml", "apiVersion: ai-resource/draft\nkind: Prompt\nmetadata:\n  id: brand\nspec:\n  body: Put logo.png in the header.\n  assets: [logo.png]\n")
+	outputDir := filepath.Join(dir, "out")
+
+	if err := runBuild([]string{"-target", "claude", "-output", outputDir, "-banner", prompt}); err != nil {
+		t.Fatalf("runBuild() error = %v", err)
+	}
+	logo, err := os.ReadFile(filepath.Join(outputDir, "claude", "skills", "brand", "logo.png"))
+	if err != nil {
+		t.Fatalf("asset not written: %v", err)
+	}
+	if string(logo) != binary {
+		t.Errorf("logo.png = %q, want it copied unchanged without a banner", logo)
+	}
+
+	// A prefix names the skill's directory, not the SKILL.md Claude looks
+	// for, and its assets move with it.
+	if err := runBuild([]string{"-target", "claude", "-output", outputDir, "-prefix", "org-", prompt}); err != nil {
+		t.Fatalf("runBuild(-prefix) error = %v", err)
+	}
+	for _, want := range []string{"SKILL.md", "logo.png"} {
+		if _, err := os.Stat(filepath.Join(outputDir, "claude", "skills", "org-brand", want)); err != nil {
+			t.Errorf("%s not written to the prefixed skill directory: %v", want, err)
+		}
+	}
+}
+
+func TestBuildLenient(t *testing.T) {
+	t.Cleanup(func() { lenient = false })
+	dir := t.TempDir()
+	file := writeTestFile(t, dir, "rule.yaml", strings.Replace(testRuleYAML, "  enforcement: must", "  enforcment: must\n  enforcement: must", 1))
+
+	if err := runBuild([]string{"-target", "markdown", file}); err == nil || !strings.Contains(err.Error(), "enforcment") {
+		t.Fatalf("runBuild() error = %v, want the unknown field", err)
+	}
+	// A flag value that happens to be -lenient is not the flag.
+	if err := runBuild([]string{"-target", "markdown", "-prefix", "-lenient", file}); err == nil || lenient {
+		t.Fatalf("runBuild(-prefix -lenient) error = %v, lenient = %v, want the unknown field rejected", err, lenient)
+	}
+	for _, flag := range []string{"-lenient", "--lenient", "-lenient=true"} {
+		lenient = false
+		if err := runBuild([]string{"-target", "markdown", file, flag}); err != nil {
+			t.Errorf("runBuild(%s) error = %v", flag, err)
+		}
+	}
+}
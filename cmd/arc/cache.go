@@ -0,0 +1,240 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"regexp"
+
+	"github.com/jomadu/ai-resource-compiler-go/pkg/compiler"
+)
+
+// cacheFile is the incremental build cache, kept next to the workspace
+// config, or in the working directory without one.
+const cacheFile = ".arc-cache.json"
+
+// cacheVersion changes whenever the cache format does; a cache of another
+// version is discarded.
+const cacheVersion = 1
+
+// buildCache records, per resource file and target, the results of the last
+// -incremental build and what they were compiled from, so a resource whose
+// inputs and settings are unchanged is not compiled again.
+type buildCache struct {
+	Version int                              `json:"version"`
+	Sources map[string]map[string]cacheEntry `json:"sources"` // by resource file, then target
+
+	path string
+	seen map[string]bool // resource files of this build
+}
+
+// cacheEntry is the last compile of a resource file for a target.
+type cacheEntry struct {
+	// Settings is the SHA-256 of the build settings the results depend on;
+	// see cacheSettings.
+	Settings string `json:"settings"`
+
+	// Inputs are the SHA-256 of each file read: the resource, the overlays,
+	// the local files it includes, and the rulesets it extends.
+	Inputs map[string]string `json:"inputs"`
+
+	// Results are kept whole, not just hashed, so merging targets can merge
+	// them with those of other resources.
+	Results []compiler.CompilationResult `json:"results"`
+}
+
+// readBuildCache reads the cache at path. A missing or outdated cache is
+// replaced by an empty one, as is one that does not decode: it only makes
+// builds faster.
+func readBuildCache(path string) (*buildCache, error) {
+	cache := &buildCache{
+		Version: cacheVersion,
+		Sources: make(map[string]map[string]cacheEntry),
+		path:    path,
+		seen:    make(map[string]bool),
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return cache, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read build cache %s: %w", path, err)
+	}
+	var stored buildCache
+	if json.Unmarshal(data, &stored) == nil && stored.Version == cacheVersion && stored.Sources != nil {
+		cache.Sources = stored.Sources
+	}
+	return cache, nil
+}
+
+// lookup returns the cached results of resourceFile for the targets of cfg
+// whose entry is current, and the targets it must be compiled for.
+func (c *buildCache) lookup(resourceFile string, cfg buildConfig) ([]targetResults, []string, error) {
+	c.seen[resourceFile] = true
+	comp := newCompiler()
+	var cached []targetResults
+	var stale []string
+	for _, name := range cfg.Targets {
+		entry, ok := c.Sources[resourceFile][name]
+		if ok {
+			current, err := entry.current(cfg, name)
+			if err != nil {
+				return nil, nil, err
+			}
+			ok = current
+		}
+		if !ok {
+			stale = append(stale, name)
+			continue
+		}
+		target := name
+		if alias, isAlias := cfg.Aliases[name]; isAlias {
+			target = alias.Target
+		}
+		t, err := parseTarget(target)
+		if err != nil {
+			return nil, nil, unknownTarget(target, cfg.Aliases)
+		}
+		cached = append(cached, targetResults{
+			target:  name,
+			results: entry.Results,
+			output:  targetOutput(cfg, name),
+			merge:   mergeFunc(comp, t),
+			banner:  bannerFunc(comp, t),
+		})
+	}
+	return cached, stale, nil
+}
+
+// current reports whether e holds the results a compile for target would
+// return: the settings and every input are unchanged.
+func (e cacheEntry) current(cfg buildConfig, target string) (bool, error) {
+	settings, err := cacheSettings(cfg, target)
+	if err != nil || settings != e.Settings {
+		return false, err
+	}
+	for file, hash := range e.Inputs {
+		if h, err := hashFile(file); err != nil || h != hash {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// callsNow matches a template action calling now.
+var callsNow = regexp.MustCompile(`\{\{[^}]*\bnow\b`)
+
+// store records the results of each target of allResults compiled from
+// resource. Remote resource files, and resources with remote includes,
+// their own or those of the rulesets they extend, are not cached, nor are
+// templated resources calling now, whose output changes by itself.
+func (c *buildCache) store(resource *compiler.Resource, resourceFile string, allResults []targetResults, cfg buildConfig) error {
+	inputs := append([]string{resourceFile}, cfg.Overlays...)
+	files := append(baseFiles(resource), resource.IncludedFiles...)
+	for _, linked := range cfg.LinkResources {
+		// Links resolve to where these rules compile to.
+		files = append(files, linked.Source)
+		files = append(files, linked.IncludedFiles...)
+		files = append(files, baseFiles(linked)...)
+	}
+	for _, file := range append([]string{resourceFile}, files...) {
+		if isRemote(file) {
+			// Remote content may change without a new build noticing.
+			return nil
+		}
+	}
+	inputs = append(inputs, files...)
+	hashes := make(map[string]string, len(inputs))
+	for _, file := range inputs {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return fmt.Errorf("failed to hash %s for the build cache: %w", file, err)
+		}
+		if cfg.Templates && callsNow.Match(data) {
+			return nil
+		}
+		hashes[file] = hashBytes(data)
+	}
+
+	for _, tr := range allResults {
+		settings, err := cacheSettings(cfg, tr.target)
+		if err != nil {
+			return err
+		}
+		if c.Sources[resourceFile] == nil {
+			c.Sources[resourceFile] = make(map[string]cacheEntry)
+		}
+		c.Sources[resourceFile][tr.target] = cacheEntry{Settings: settings, Inputs: hashes, Results: tr.results}
+	}
+	return nil
+}
+
+// write writes the cache, dropping the resource files this build did not
+// compile. A nil cache writes nothing.
+func (c *buildCache) write() error {
+	if c == nil {
+		return nil
+	}
+	for file := range c.Sources {
+		if !c.seen[file] {
+			delete(c.Sources, file)
+		}
+	}
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(c.path, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write build cache %s: %w", c.path, err)
+	}
+	return nil
+}
+
+// cacheSettings returns the SHA-256 of the settings the results of target
+// depend on besides its input files, arc's own version and the environment
+// variables templates may read among them.
+func cacheSettings(cfg buildConfig, target string) (string, error) {
+	env := make(map[string]string, len(cfg.TemplateEnv))
+	for _, name := range cfg.TemplateEnv {
+		env[name] = os.Getenv(name)
+	}
+	data, err := json.Marshal(struct {
+		Arc            string
+		Target         string
+		Alias          targetAlias
+		Options        map[string]any
+		Lean           bool
+		EmbedSource    string
+		Variables      map[string]string
+		Locale         string
+		Templates      bool
+		TemplateData   map[string]any
+		TemplateEnv    map[string]string
+		Prefix         string
+		PathTemplate   string
+		Only, Exclude  []string
+		MinEnforcement string
+		Tags           []string
+		Transformers   []string
+	}{
+		arcVersion(), target, cfg.Aliases[target], cfg.TargetOptions[target],
+		cfg.Lean, cfg.EmbedSource, cfg.Variables, cfg.Locale, cfg.Templates, cfg.TemplateData, env,
+		cfg.Prefix, cfg.PathTemplate, cfg.Only, cfg.Exclude, cfg.MinEnforcement, cfg.Tags,
+		cfg.Transformers,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to hash build settings for target %s: %w", target, err)
+	}
+	return hashBytes(data), nil
+}
+
+// hashFile returns the hex-encoded SHA-256 of file's content.
+func hashFile(file string) (string, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return "", err
+	}
+	return hashBytes(data), nil
+}
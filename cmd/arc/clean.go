@@ -0,0 +1,303 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+)
+
+func runClean(args []string) error {
+	fs := flag.NewFlagSet("clean", flag.ContinueOnError)
+	var targets arrayFlags
+	fs.Var(&targets, "target", "Target whose output to remove (repeatable, overrides config)")
+	output := fs.String("output", "", "Output directory to clean (overrides config)")
+	flat := fs.Bool("flat", false, "Output was written without target subdirectories (overrides config)")
+	banner := fs.Bool("banner", false, "Output was written with banners (overrides config)")
+	layout := fs.String("layout", "", "Output layout the files were written with: target or native (overrides config)")
+	root := fs.String("root", "", "Project root of the native layout (overrides config)")
+	configPath := fs.String("config", "", configFlagUsage)
+	profile := fs.String("profile", "", "Workspace config profile whose output to remove")
+	dryRunMode := fs.Bool("dry-run", false, "List the files that would be removed without removing them")
+
+	files, err := parseInterspersed(fs, args)
+	if err != nil {
+		return err
+	}
+
+	set := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) { set[f.Name] = true })
+
+	var settings buildSettings
+	var aliases map[string]targetAlias
+	if path := findConfigFile(*configPath); path != "" {
+		ws, err := loadWorkspaceConfig(path)
+		if err != nil {
+			return err
+		}
+		if settings, err = ws.resolve(*profile); err != nil {
+			return err
+		}
+		if aliases, err = ws.targetAliases(); err != nil {
+			return err
+		}
+	} else if *profile != "" {
+		return fmt.Errorf("-profile requires a workspace config (%s)", defaultConfigFile)
+	}
+
+	cfg := settings.buildConfig(aliases)
+	if set["target"] {
+		cfg.Targets = targets
+	}
+	if set["output"] {
+		cfg.Output = *output
+		cfg.TargetOutputs = nil
+	}
+	if set["flat"] {
+		cfg.Flat = *flat
+	}
+	if set["banner"] {
+		cfg.Banner = *banner
+	}
+	if set["layout"] {
+		cfg.Layout = *layout
+	}
+	if set["root"] {
+		cfg.Root = *root
+	}
+	if err := applyLayout(&cfg); err != nil {
+		return err
+	}
+
+	// The manifests of a -manifest build list every file arc wrote, those of
+	// since removed resources included, so nothing needs compiling.
+	found, err := findManifests(outputDirs(cfg))
+	if err != nil {
+		return err
+	}
+	if len(found) > 0 {
+		var only []string
+		if set["target"] {
+			only = cfg.Targets
+		}
+		return cleanManifests(found, only, *dryRunMode, cfg.Summary)
+	}
+
+	if len(files) == 0 {
+		files = settings.Resources
+	}
+	if files, err = expandResources(files); err != nil {
+		return err
+	}
+	if len(files) == 0 {
+		return fmt.Errorf("no resource files (pass files or set resources in %s)", defaultConfigFile)
+	}
+	if len(cfg.Targets) == 0 {
+		return fmt.Errorf("at least one target required (use -target or set targets in %s)", defaultConfigFile)
+	}
+
+	// Compile everything first, so merged files are known in full and
+	// nothing is removed if a resource fails to compile.
+	cfg.Rulesets = buildRulesets(files, cfg)
+	var compiled []targetResults
+	pending := &pendingResults{}
+	for _, file := range files {
+		allResults, err := compileTargets(file, cfg)
+		if err != nil {
+			return &fileError{File: file, Err: err}
+		}
+		for _, tr := range withSources(allResults, file) {
+			if tr.merge != nil {
+				pending.add(tr)
+			} else {
+				compiled = append(compiled, tr)
+			}
+		}
+	}
+	merged, err := pending.merged()
+	if err != nil {
+		return err
+	}
+	generated := projectRootResults(append(compiled, merged...), cfg)
+	if cfg.Banner {
+		if generated, err = newBanners().add(generated); err != nil {
+			return err
+		}
+	}
+
+	removed, kept := 0, 0
+	for _, tr := range generated {
+		dir, flat := cfg.Output, cfg.Flat
+		if tr.output != "" {
+			dir, flat = tr.output, true
+		}
+		if dir == "" || dir == "stdout" {
+			return fmt.Errorf("no output directory to clean for target %s (use -output or set output in %s)", tr.target, defaultConfigFile)
+		}
+		for _, result := range tr.results {
+			resultPath := result.Path
+			if !flat {
+				resultPath = tr.target + "/" + result.Path
+			}
+			filePath, ok, err := removeResultFile(dir, resultPath, result.Content, *dryRunMode)
+			switch {
+			case err != nil:
+				return err
+			case ok:
+				removed++
+				printRemoved(filePath, *dryRunMode)
+			case filePath != "":
+				kept++
+				cfg.Summary.warn("%s was edited since it was generated; keeping it", filePath)
+			}
+		}
+	}
+
+	printCleaned(removed, kept, *dryRunMode)
+	return nil
+}
+
+// outputDirs returns the directories cfg writes files to: its output, unless
+// that is stdout, and the directories of its targets and aliases.
+func outputDirs(cfg buildConfig) []string {
+	var dirs []string
+	add := func(dir string) {
+		if dir != "" && dir != "stdout" && !slices.Contains(dirs, dir) {
+			dirs = append(dirs, dir)
+		}
+	}
+	add(cfg.Output)
+	for _, name := range cfg.Targets {
+		add(cfg.Aliases[name].Output)
+		add(cfg.TargetOutputs[name])
+		add(cfg.TargetRoots[name])
+	}
+	return dirs
+}
+
+// cleanManifests removes the files listed in the manifests found, of the
+// targets in only, or of every target if only is empty, keeping those edited
+// since they were written. A manifest is removed once it lists nothing else,
+// and otherwise rewritten with the files of the other targets.
+func cleanManifests(found []manifestLocation, only []string, dryRun bool, summary *buildSummary) error {
+	removed, kept := 0, 0
+	for _, loc := range found {
+		m, err := loc.read()
+		if err != nil {
+			return err
+		}
+		var remaining []manifestEntry
+		for _, entry := range m.Files {
+			if len(only) > 0 && !slices.Contains(only, entry.Target) {
+				remaining = append(remaining, entry)
+				continue
+			}
+			generated := func(content []byte) bool { return hashBytes(content) == entry.SHA256 }
+			filePath, ok, err := removeGeneratedFile(loc.dir, path.Join(path.Dir(loc.path), entry.Path), generated, dryRun)
+			switch {
+			case err != nil:
+				return err
+			case ok:
+				removed++
+				printRemoved(filePath, dryRun)
+			case filePath != "":
+				kept++
+				summary.warn("%s was edited since it was generated; keeping it", filePath)
+			}
+		}
+		if dryRun {
+			continue
+		}
+		if len(remaining) > 0 {
+			m.Files = remaining
+			if err := loc.write(m); err != nil {
+				return err
+			}
+			continue
+		}
+		anything := func([]byte) bool { return true }
+		if _, _, err := removeGeneratedFile(loc.dir, loc.path, anything, false); err != nil {
+			return err
+		}
+	}
+	printCleaned(removed, kept, dryRun)
+	return nil
+}
+
+// printRemoved reports a removed file, or one a dry run would remove.
+func printRemoved(filePath string, dryRun bool) {
+	if dryRun {
+		fmt.Printf("Would remove %s\n", filePath)
+	} else {
+		fmt.Fprintf(os.Stderr, "Removed %s\n", filePath)
+	}
+}
+
+// printCleaned reports how many files were removed and kept.
+func printCleaned(removed, kept int, dryRun bool) {
+	if dryRun {
+		fmt.Printf("%d file(s) would be removed (dry run)\n", removed)
+		return
+	}
+	fmt.Fprintf(os.Stderr, "Removed %d file(s), kept %d edited file(s)\n", removed, kept)
+}
+
+// removeResultFile removes resultPath within dir if it holds content, then
+// removes the directories that leaves empty, up to dir. It returns the file's
+// path, or "" if it does not exist, and whether it was removed; a file whose
+// content differs is kept. With dryRun, nothing is removed. As when writing,
+// nothing below dir is followed out of it.
+func removeResultFile(dir, resultPath, content string, dryRun bool) (string, bool, error) {
+	return removeGeneratedFile(dir, resultPath, func(existing []byte) bool {
+		return bytes.Equal(existing, []byte(content))
+	}, dryRun)
+}
+
+// removeGeneratedFile is removeResultFile for a file recognized by
+// generated, which reports whether its content is as arc wrote it.
+func removeGeneratedFile(dir, resultPath string, generated func([]byte) bool, dryRun bool) (string, bool, error) {
+	filePath, err := resultFilePath(dir, resultPath)
+	if err != nil {
+		return "", false, err
+	}
+	root, err := os.OpenRoot(dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, fmt.Errorf("failed to open output directory %s: %w", dir, err)
+	}
+	defer root.Close()
+
+	local := filepath.FromSlash(resultPath)
+	existing, err := readRootFile(root, local)
+	if errors.Is(err, fs.ErrNotExist) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, fmt.Errorf("failed to read file %s: %w", filePath, err)
+	}
+	if !generated(existing) {
+		return filePath, false, nil
+	}
+	if dryRun {
+		return filePath, true, nil
+	}
+	if err := root.Remove(local); err != nil {
+		return "", false, fmt.Errorf("failed to remove file %s: %w", filePath, err)
+	}
+
+	// Removing a directory that still has entries fails, which ends the
+	// pruning.
+	for parent := filepath.Dir(local); parent != "."; parent = filepath.Dir(parent) {
+		if root.Remove(parent) != nil {
+			break
+		}
+	}
+	return filePath, true, nil
+}
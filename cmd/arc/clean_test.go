@@ -0,0 +1,114 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCleanRemovesGeneratedFiles(t *testing.T) {
+	dir := t.TempDir()
+	rule := createTestResource(t, dir)
+	out := filepath.Join(dir, "out")
+	if err := runBuild([]string{"-target", "cursor", "-target", "claude", "-output", out, rule}); err != nil {
+		t.Fatalf("runBuild() error = %v", err)
+	}
+	edited := filepath.Join(out, "cursor", "rules", "testRule.mdc")
+	if err := os.WriteFile(edited, []byte("edited by hand\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	args := []string{"-target", "cursor", "-target", "claude", "-output", out, rule}
+	if err := runClean(append([]string{"-dry-run"}, args...)); err != nil {
+		t.Fatalf("runClean(-dry-run) error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(out, "claude", "rules", "testRule.md")); err != nil {
+		t.Fatalf("dry run removed a file: %v", err)
+	}
+
+	if err := runClean(args); err != nil {
+		t.Fatalf("runClean() error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(out, "claude")); !os.IsNotExist(err) {
+		t.Error("generated file or its emptied directory was kept")
+	}
+	if _, err := os.Stat(edited); err != nil {
+		t.Errorf("edited file was removed: %v", err)
+	}
+	if _, err := os.Stat(out); err != nil {
+		t.Errorf("output directory itself was removed: %v", err)
+	}
+}
+
+func TestCleanRequiresOutputDirectory(t *testing.T) {
+	dir := t.TempDir()
+	rule := createTestResource(t, dir)
+
+	if err := runClean([]string{"-target", "cursor", rule}); err == nil {
+		t.Error("runClean() without an output directory succeeded")
+	}
+}
+
+func TestCleanFromManifest(t *testing.T) {
+	dir := t.TempDir()
+	rule := createTestResource(t, dir)
+	stale := writeTestFile(t, dir, "stale.yaml", strings.Replace(mergeRuleA, "id: ruleA", "id: staleRule", 1))
+	out := filepath.Join(dir, "out")
+	if err := runBuild([]string{"-target", "cursor", "-target", "claude", "-output", out, "-manifest", rule, stale}); err != nil {
+		t.Fatalf("runBuild() error = %v", err)
+	}
+	// The stale resource is gone, so only the manifest knows its output.
+	if err := os.Remove(stale); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := runClean([]string{"-output", out, "-target", "claude"}); err != nil {
+		t.Fatalf("runClean(-target claude) error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(out, "claude")); !os.IsNotExist(err) {
+		t.Error("claude output or its manifest was kept")
+	}
+	if _, err := os.Stat(filepath.Join(out, "cursor", "rules", "staleRule.mdc")); err != nil {
+		t.Fatalf("cleaning claude removed cursor output: %v", err)
+	}
+
+	if err := runClean([]string{"-output", out}); err != nil {
+		t.Fatalf("runClean() error = %v", err)
+	}
+	entries, err := os.ReadDir(out)
+	if err != nil {
+		t.Fatalf("output directory itself was removed: %v", err)
+	}
+	if len(entries) != 0 {
+		t.Errorf("clean left %d entries in the output directory, want none", len(entries))
+	}
+}
+
+func TestCleanAfterRebuildWithoutResource(t *testing.T) {
+	dir := t.TempDir()
+	rule := createTestResource(t, dir)
+	stale := writeTestFile(t, dir, "stale.yaml", strings.Replace(mergeRuleA, "id: ruleA", "id: staleRule", 1))
+	out := filepath.Join(dir, "out")
+	if err := runBuild([]string{"-target", "cursor", "-output", out, "-manifest", rule, stale}); err != nil {
+		t.Fatalf("runBuild() error = %v", err)
+	}
+	// Rebuilding without the deleted resource keeps its output in the
+	// manifest, for clean to remove.
+	if err := os.Remove(stale); err != nil {
+		t.Fatal(err)
+	}
+	if err := runBuild([]string{"-target", "cursor", "-output", out, "-manifest", rule}); err != nil {
+		t.Fatalf("runBuild() rebuild error = %v", err)
+	}
+
+	if err := runClean([]string{"-output", out}); err != nil {
+		t.Fatalf("runClean() error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(out, "cursor", "rules", "staleRule.mdc")); !os.IsNotExist(err) {
+		t.Error("output of the deleted resource was kept")
+	}
+	if _, err := os.Stat(filepath.Join(out, "cursor")); !os.IsNotExist(err) {
+		t.Error("clean kept the cursor output directory")
+	}
+}
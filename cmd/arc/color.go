@@ -0,0 +1,62 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+)
+
+// ANSI escape codes for terminal output.
+const (
+	colorRed    = "\x1b[31m"
+	colorGreen  = "\x1b[32m"
+	colorYellow = "\x1b[33m"
+	colorReset  = "\x1b[0m"
+)
+
+// noColor disables color output; set by the -no-color flag.
+var noColor bool
+
+// isTerminal reports whether w is an interactive terminal. It is a variable
+// so tests can pretend to write to one.
+var isTerminal = func(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// useColor reports whether output to w should be colored: only for
+// terminals, and never when -no-color, NO_COLOR, or TERM=dumb is set.
+func useColor(w io.Writer) bool {
+	if noColor || os.Getenv("NO_COLOR") != "" || os.Getenv("TERM") == "dumb" {
+		return false
+	}
+	return isTerminal(w)
+}
+
+// colorize wraps s in color when output to w is colored.
+func colorize(w io.Writer, color, s string) string {
+	if !useColor(w) {
+		return s
+	}
+	return color + s + colorReset
+}
+
+// printWrote reports a file written by arc.
+func printWrote(path string) {
+	fmt.Fprintf(os.Stderr, "%s %s\n", colorize(os.Stderr, colorGreen, "Wrote"), path)
+}
+
+// printUnchanged reports a file arc left as it was, its content being
+// current.
+func printUnchanged(path string) {
+	fmt.Fprintf(os.Stderr, "Unchanged %s\n", path)
+}
+
+// errorLabel returns the "Error:" prefix of text error messages.
+func errorLabel(w io.Writer) string {
+	return colorize(w, colorRed, "Error:")
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestColorize(t *testing.T) {
+	defer func(orig func(io.Writer) bool) { isTerminal = orig }(isTerminal)
+	isTerminal = func(io.Writer) bool { return true }
+	t.Setenv("NO_COLOR", "")
+	t.Setenv("TERM", "xterm")
+
+	var w bytes.Buffer
+	if got, want := colorize(&w, colorRed, "Error:"), colorRed+"Error:"+colorReset; got != want {
+		t.Errorf("colorize() on terminal = %q, want %q", got, want)
+	}
+
+	t.Setenv("NO_COLOR", "1")
+	if got := colorize(&w, colorRed, "Error:"); got != "Error:" {
+		t.Errorf("colorize() with NO_COLOR = %q, want plain", got)
+	}
+	t.Setenv("NO_COLOR", "")
+
+	noColor = true
+	if got := colorize(&w, colorRed, "Error:"); got != "Error:" {
+		t.Errorf("colorize() with -no-color = %q, want plain", got)
+	}
+	noColor = false
+
+	isTerminal = func(io.Writer) bool { return false }
+	if got := colorize(&w, colorRed, "Error:"); got != "Error:" {
+		t.Errorf("colorize() when piped = %q, want plain", got)
+	}
+}
+
+func TestWriteDiffTextPlainWhenPiped(t *testing.T) {
+	var buf bytes.Buffer
+	writeDiffText(&buf, []resourceChange{{Change: "added", Item: "rules/new"}})
+	if got := buf.String(); strings.Contains(got, "\x1b[") || got != "+ rules/new\n" {
+		t.Errorf("writeDiffText() = %q, want plain text", got)
+	}
+}
+
+func TestNoColorFlag(t *testing.T) {
+	t.Cleanup(func() { noColor = false })
+	parse := func(args ...string) ([]string, error) {
+		fs := flag.NewFlagSet("diff", flag.ContinueOnError)
+		fs.SetOutput(io.Discard)
+		fs.String("prefix", "", "")
+		return parseInterspersed(fs, args)
+	}
+
+	rest, err := parse("a.yaml", "--no-color", "b.yaml")
+	if err != nil || !noColor || !reflect.DeepEqual(rest, []string{"a.yaml", "b.yaml"}) {
+		t.Errorf("parseInterspersed() = %v, %v with noColor %v, want it set", rest, err, noColor)
+	}
+	if _, err := parse("a.yaml"); err != nil || noColor {
+		t.Errorf("parseInterspersed() error = %v with noColor %v, want it unset when not given", err, noColor)
+	}
+	// A flag value that happens to be -no-color is not the flag.
+	rest, err = parse("-prefix", "-no-color", "a.yaml")
+	if err != nil || noColor || !reflect.DeepEqual(rest, []string{"a.yaml"}) {
+		t.Errorf("parseInterspersed() = %v, %v with noColor %v, want -no-color taken as the prefix", rest, err, noColor)
+	}
+}
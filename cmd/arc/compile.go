@@ -1,64 +1,648 @@
 package main
 
 import (
+	"errors"
 	"fmt"
-	"os"
+	"path/filepath"
+	"slices"
+	"strings"
 
+	"github.com/jomadu/ai-resource-compiler-go/internal/format"
+	"github.com/jomadu/ai-resource-compiler-go/internal/overlay"
+	"github.com/jomadu/ai-resource-compiler-go/internal/suggest"
 	"github.com/jomadu/ai-resource-compiler-go/pkg/compiler"
-	_ "github.com/jomadu/ai-resource-compiler-go/pkg/targets" // Register built-in targets
-	"gopkg.in/yaml.v3"
+	"github.com/jomadu/ai-resource-compiler-go/pkg/loader"
+	"github.com/jomadu/ai-resource-compiler-go/pkg/targets"
 )
 
 type targetResults struct {
 	target  string
 	results []compiler.CompilationResult
+
+	// output, if set, is the directory the results are always written to.
+	output string
+
+	// merge, if set, combines results of several resources into the files
+	// they share; see compiler.MergingTarget.
+	merge func([]compiler.CompilationResult) ([]compiler.CompilationResult, error)
+
+	// banner adds a provenance banner to a result; see
+	// compiler.BannerTarget.
+	banner func(path, content, banner string) string
+
+	// sources lists the resource files each result was compiled from, by
+	// path. Merged files have several.
+	sources map[string][]string
+}
+
+// withSources returns allResults recording that they were compiled from
+// resourceFile.
+func withSources(allResults []targetResults, resourceFile string) []targetResults {
+	for i, tr := range allResults {
+		allResults[i].sources = make(map[string][]string, len(tr.results))
+		for _, result := range tr.results {
+			allResults[i].sources[result.Path] = []string{resourceFile}
+		}
+	}
+	return allResults
+}
+
+// buildConfig holds the settings for a compile run.
+type buildConfig struct {
+	Targets     []string
+	Output      string
+	Flat        bool
+	Layout      string // layoutTarget, layoutNative, or "" for layoutTarget
+	Root        string // project root of the native layout; "" for the working directory
+	Lean        bool
+	EmbedSource string // "path", "yaml", or "" for none
+	Overlays    []string
+	Variables   map[string]string
+	Locale      string // body variant to compile; "" for the default body
+
+	// Templates executes bodies as Go templates with TemplateData as dot.
+	// TemplateEnv names the environment variables they may read.
+	Templates    bool
+	TemplateData map[string]any
+	TemplateEnv  []string
+
+	// Prefix and PathTemplate rename result files; see
+	// compiler.CompileOptions.
+	Prefix       string
+	PathTemplate string
+
+	// Only and Exclude select items of a Ruleset or Promptset by ID.
+	Only    []string
+	Exclude []string
+
+	// MinEnforcement compiles only rules at or above this enforcement
+	// level; "" compiles every rule.
+	MinEnforcement string
+
+	// Tags compiles only rules and prompts carrying any of these tags; nil
+	// compiles all of them.
+	Tags []string
+
+	// Transformers name the body transformers applied in order, as
+	// compiler.ParseTransformer reads them, e.g. "wrap=100".
+	Transformers []string
+
+	// TokenBudget, if positive, is the number of tokens a compiled file
+	// may take before a warning. Tokens, if set, collects the token count
+	// of every file for -report tokens.
+	TokenBudget int
+	Tokens      *tokenReport
+
+	// Aliases maps target alias names from the workspace config to presets.
+	Aliases map[string]targetAlias
+
+	// TargetOutputs and TargetOptions are the output directory and options
+	// of targets or aliases, by the names in Targets. Options override
+	// those of an alias key by key.
+	TargetOutputs map[string]string
+	TargetOptions map[string]map[string]any
+
+	// TargetRoots is the project root of the targets or aliases the native
+	// layout gave their output directory, by name, for the files their
+	// tools read from the root instead; see compiler.ProjectRootTarget.
+	TargetRoots map[string]string
+
+	// Lock pins remote includes; nil disables pinning.
+	Lock *loader.Lockfile
+
+	// Summary, if set, counts results and warnings across compiles.
+	Summary *buildSummary
+
+	// Stats, if set, collects compile metrics for -stats-file.
+	Stats *compiler.Stats
+
+	// DryRun, if set, records the files results would be written to
+	// instead of writing or printing them.
+	DryRun *dryRun
+
+	// Pending, if set, holds the results of merging targets until every
+	// resource is compiled; writePending then merges and writes them.
+	// Otherwise each resource's results are written on their own.
+	Pending *pendingResults
+
+	// Manifest writes an .arc-manifest.json of the files written to each output
+	// directory, by default. Manifests, if set, collects them; arc and
+	// runBuild write them once every result is written.
+	Manifest  bool
+	Manifests *manifests
+
+	// Incremental reuses the results of resources unchanged since the last
+	// build. Cache, if set, holds them and records this build's.
+	Incremental bool
+	Cache       *buildCache
+
+	// Rulesets, if set, resolves the extends of rulesets, and links to
+	// rules, against the other resource files of the build.
+	Rulesets *rulesetIndex
+	// LinkResources are the rules and rulesets of the other resource files
+	// links to rules resolve against, set by compile and compileTargets.
+	LinkResources []*compiler.Resource
+
+	// Guard, unless nil for -force, refuses to overwrite files arc did not
+	// generate.
+	Guard *overwriteGuard
+	// Outputs, if set, catches results written to the same file from
+	// different resource files or targets.
+	Outputs *outputPaths
+
+	// Link, "symlink" or "hardlink", writes files whose content the build
+	// already wrote elsewhere as links to that file. Links, if set, makes
+	// them.
+	Link  string
+	Links *linker
+
+	// Banner adds a banner naming arc, the version, and the source files to
+	// every result. Banners, if set, adds them as results are written.
+	Banner  bool
+	Banners *banners
+
+	// Archive, if set, receives the results that would go to Output, which
+	// names it.
+	Archive *archive
+	// JSON, if set, collects the results that would be printed to stdout,
+	// for -format json.
+	JSON *jsonOutput
+}
+
+// pendingResults are results of merging targets, grouped by target and
+// output directory.
+type pendingResults struct {
+	results []targetResults
+}
+
+// add appends the results of tr to those of the same target and output.
+func (p *pendingResults) add(tr targetResults) {
+	for i := range p.results {
+		if p.results[i].target == tr.target && p.results[i].output == tr.output {
+			p.results[i].results = append(p.results[i].results, tr.results...)
+			for path, files := range tr.sources {
+				for _, file := range files {
+					if !slices.Contains(p.results[i].sources[path], file) {
+						p.results[i].sources[path] = append(p.results[i].sources[path], file)
+					}
+				}
+			}
+			return
+		}
+	}
+	if tr.sources == nil {
+		tr.sources = make(map[string][]string)
+	}
+	p.results = append(p.results, tr)
+}
+
+// merged returns the pending results with each target's results merged.
+func (p *pendingResults) merged() ([]targetResults, error) {
+	merged := make([]targetResults, len(p.results))
+	for i, tr := range p.results {
+		results, err := tr.merge(tr.results)
+		if err != nil {
+			return nil, fmt.Errorf("merging results for target %s: %w", tr.target, err)
+		}
+		merged[i] = tr
+		merged[i].results = results
+	}
+	return merged, nil
+}
+
+// lenient loads resource files ignoring fields the format does not
+// define; set by the -lenient flag.
+var lenient bool
+
+func loadResource(path string) (*compiler.Resource, error) {
+	return (&loader.Loader{Context: interrupted, Lenient: lenient}).Load(path)
 }
 
-func compile(resourceFile string, targets []string, output string, flat bool) error {
-	data, err := os.ReadFile(resourceFile)
+func compile(resourceFile string, cfg buildConfig) error {
+	var allResults []targetResults
+	if cfg.Cache != nil {
+		cached, stale, err := cfg.Cache.lookup(resourceFile, cfg)
+		if err != nil {
+			return err
+		}
+		allResults, cfg.Targets = cached, stale
+	}
+
+	if len(cfg.Targets) > 0 {
+		resource, err := loadResolved(resourceFile, cfg)
+		if err != nil {
+			return err
+		}
+		if cfg.LinkResources, err = cfg.Rulesets.linked(resourceFile, resource); err != nil {
+			return err
+		}
+		compiled, err := compileResource(resource, cfg)
+		if err != nil {
+			return err
+		}
+		if cfg.Cache != nil {
+			if err := cfg.Cache.store(resource, resourceFile, compiled, cfg); err != nil {
+				return err
+			}
+		}
+		allResults = append(allResults, compiled...)
+	}
+
+	var ready []targetResults
+	for _, tr := range withSources(allResults, resourceFile) {
+		if len(tr.results) == 0 {
+			cfg.Summary.warn("%s: no output for target %s", resourceFile, tr.target)
+		}
+		if tr.merge != nil && cfg.Pending != nil {
+			cfg.Pending.add(tr)
+		} else {
+			ready = append(ready, tr)
+		}
+	}
+	return writeResults(ready, cfg)
+}
+
+// writePending merges and writes the results held in cfg.Pending.
+func writePending(cfg buildConfig) error {
+	if cfg.Pending == nil {
+		return nil
+	}
+	merged, err := cfg.Pending.merged()
 	if err != nil {
-		return fmt.Errorf("failed to read resource file: %w", err)
+		return err
 	}
+	return writeResults(merged, cfg)
+}
 
-	var resource compiler.Resource
-	if err := yaml.Unmarshal(data, &resource); err != nil {
-		return fmt.Errorf("failed to parse resource file: %w", err)
+// writeResults writes alias results to their output directories and the
+// others as cfg.Output says, or records them all for a dry run.
+func writeResults(allResults []targetResults, cfg buildConfig) error {
+	var claimed []targetResults
+	for _, tr := range projectRootResults(allResults, cfg) {
+		output, flat := cfg.Output, cfg.Flat
+		if tr.output != "" {
+			output, flat = tr.output, true
+		}
+		trs, err := cfg.Outputs.claim([]targetResults{tr}, output, flat, cfg.Summary)
+		if err != nil {
+			return err
+		}
+		claimed = append(claimed, trs...)
+	}
+	allResults = claimed
+	checkTokenBudget(allResults, cfg.TokenBudget, cfg.Summary)
+	cfg.Tokens.add(allResults)
+	allResults, err := cfg.Banners.add(allResults)
+	if err != nil {
+		return err
+	}
+	var aliasResults, otherResults []targetResults
+	for _, tr := range allResults {
+		if tr.output != "" {
+			aliasResults = append(aliasResults, tr)
+		} else {
+			otherResults = append(otherResults, tr)
+		}
+	}
+	if cfg.DryRun != nil {
+		for _, tr := range aliasResults {
+			if err := cfg.DryRun.add([]targetResults{tr}, tr.output, true); err != nil {
+				return err
+			}
+		}
+		return cfg.DryRun.add(otherResults, cfg.Output, cfg.Flat)
+	}
+	for _, tr := range aliasResults {
+		if err := cfg.Manifests.record([]targetResults{tr}, tr.output, true); err != nil {
+			return err
+		}
+		if err := outputFiles([]targetResults{tr}, tr.output, true, cfg.Guard, cfg.Links, cfg.Summary); err != nil {
+			return err
+		}
+	}
+	if cfg.Archive != nil {
+		return cfg.Archive.add(otherResults, cfg.Flat, cfg.Summary)
+	}
+	if cfg.Output == "stdout" && cfg.JSON != nil {
+		cfg.JSON.add(otherResults, cfg.Summary)
+		return nil
+	}
+	if cfg.Output == "stdout" {
+		return outputStdout(otherResults, cfg.Summary)
 	}
+	if err := cfg.Manifests.record(otherResults, cfg.Output, cfg.Flat); err != nil {
+		return err
+	}
+	return outputFiles(otherResults, cfg.Output, cfg.Flat, cfg.Guard, cfg.Links, cfg.Summary)
+}
+
+// compileTargets loads resourceFile with the configured overlays and
+// compiles it for each configured target or alias.
+func compileTargets(resourceFile string, cfg buildConfig) ([]targetResults, error) {
+	resource, err := loadResolved(resourceFile, cfg)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.LinkResources, err = cfg.Rulesets.linked(resourceFile, resource); err != nil {
+		return nil, err
+	}
+	return compileResource(resource, cfg)
+}
+
+// loadResolved loads resourceFile with the configured overlays applied and
+// its extends, if any, resolved by cfg.Rulesets.
+func loadResolved(resourceFile string, cfg buildConfig) (*compiler.Resource, error) {
+	resource, err := loadWithOverlays(resourceFile, cfg)
+	if err != nil {
+		return nil, err
+	}
+	return cfg.Rulesets.resolve(resourceFile, resource)
+}
+
+// loadWithOverlays loads resourceFile, which may be an https:// URL, with
+// the configured overlays applied.
+func loadWithOverlays(resourceFile string, cfg buildConfig) (*compiler.Resource, error) {
+	l := &loader.Loader{Context: interrupted, Lock: cfg.Lock, Lenient: lenient}
+	if l.Lock == nil && isRemote(resourceFile) {
+		// Record the checksum for banners and manifests, without writing
+		// a lockfile.
+		l.Lock = &loader.Lockfile{Version: 1, Remote: make(map[string]loader.LockEntry)}
+	}
+	for _, path := range cfg.Overlays {
+		o, err := overlay.Load(path)
+		if err != nil {
+			return nil, err
+		}
+		l.Patches = append(l.Patches, o)
+	}
+	resource, err := l.Load(resourceFile)
+	if err != nil {
+		return nil, err
+	}
+	if isRemote(resourceFile) {
+		if sum, ok := l.Lock.Checksum(resourceFile); ok {
+			remoteHashes[resourceFile] = sum
+		}
+	}
+	return resource, nil
+}
+
+// remoteHashes holds the SHA-256 of each remote resource file loaded, for
+// sourceHashes, as they cannot be read again like files.
+var remoteHashes = make(sourceHashes)
+
+// isRemote reports whether a resource file argument is a URL.
+func isRemote(resourceFile string) bool {
+	return strings.HasPrefix(resourceFile, "https://") || strings.HasPrefix(resourceFile, "http://")
+}
+
+// compileResource compiles resource for each configured target or alias.
+func compileResource(resource *compiler.Resource, cfg buildConfig) ([]targetResults, error) {
+	targets := cfg.Targets
 
 	targetEnums := make([]compiler.Target, len(targets))
+	targetOptions := make([]map[string]any, len(targets))
+	targetOutputs := make([]string, len(targets))
 	for i, t := range targets {
-		switch t {
-		case "markdown":
-			targetEnums[i] = compiler.TargetMarkdown
-		case "kiro":
-			targetEnums[i] = compiler.TargetKiro
-		case "cursor":
-			targetEnums[i] = compiler.TargetCursor
-		case "claude":
-			targetEnums[i] = compiler.TargetClaude
-		case "copilot":
-			targetEnums[i] = compiler.TargetCopilot
-		default:
-			return fmt.Errorf("unknown target: %s", t)
-		}
-	}
-
-	c := compiler.NewCompiler()
+		if alias, ok := cfg.Aliases[t]; ok {
+			t = alias.Target
+			targetOptions[i] = alias.Options
+		}
+		targetOutputs[i] = targetOutput(cfg, targets[i])
+		if options, ok := cfg.TargetOptions[targets[i]]; ok {
+			targetOptions[i] = mergeOptions(targetOptions[i], options)
+		}
+		target, err := parseTarget(t)
+		if err != nil {
+			return nil, unknownTarget(t, cfg.Aliases)
+		}
+		targetEnums[i] = target
+	}
+
+	var transformers []compiler.BodyTransformer
+	for _, name := range cfg.Transformers {
+		transformer, err := compiler.ParseTransformer(name)
+		if err != nil {
+			return nil, err
+		}
+		transformers = append(transformers, transformer)
+	}
+
+	var options []compiler.Option
+	if cfg.Stats != nil {
+		options = append(options, compiler.WithMetrics(cfg.Stats))
+	}
+	c := newCompiler(options...)
 	
 	// Compile each target separately to track which results belong to which target
 	var allResults []targetResults
 	
 	for i, targetEnum := range targetEnums {
-		opts := compiler.CompileOptions{Targets: []compiler.Target{targetEnum}}
-		results, err := c.Compile(&resource, opts)
+		opts := compiler.CompileOptions{
+			Targets:        []compiler.Target{targetEnum},
+			Variables:      cfg.Variables,
+			Locale:         cfg.Locale,
+			Lean:           cfg.Lean,
+			EmbedSource:    cfg.EmbedSource,
+			Only:           cfg.Only,
+			Exclude:        cfg.Exclude,
+			MinEnforcement: cfg.MinEnforcement,
+			Tags:           cfg.Tags,
+			Prefix:         cfg.Prefix,
+			PathTemplate:   cfg.PathTemplate,
+			Templates:      cfg.Templates,
+			TemplateData:   cfg.TemplateData,
+			TemplateEnv:    cfg.TemplateEnv,
+			Transformers:   transformers,
+			LinkResources:  cfg.LinkResources,
+
+			CollectErrors: true,
+		}
+		if targetOptions[i] != nil {
+			opts.TargetOptions = map[compiler.Target]map[string]any{targetEnum: targetOptions[i]}
+		}
+		results, err := c.CompileContext(interrupted, resource, opts)
 		if err != nil {
-			return fmt.Errorf("compilation failed for target %s: %w", targets[i], err)
+			var compileErr *compiler.CompileError
+			if errors.As(err, &compileErr) {
+				return nil, err
+			}
+			return nil, fmt.Errorf("compilation failed for target %s: %w", targets[i], err)
 		}
-		allResults = append(allResults, targetResults{target: targets[i], results: results})
+		allResults = append(allResults, targetResults{
+			target:  targets[i],
+			results: results,
+			output:  targetOutputs[i],
+			merge:   mergeFunc(c, targetEnum),
+			banner:  bannerFunc(c, targetEnum),
+		})
 	}
 
-	if output == "stdout" {
-		return outputStdout(allResults)
+	return allResults, nil
+}
+
+// targetOutput returns the directory the results of target, a built-in
+// target or alias, are always written to, or "" if they go to the output of
+// the build.
+func targetOutput(cfg buildConfig, target string) string {
+	if output, ok := cfg.TargetOutputs[target]; ok {
+		return output
 	}
-	return outputFiles(allResults, output, flat)
+	return cfg.Aliases[target].Output
+}
+
+// Output layouts for -layout.
+const (
+	layoutTarget = "target" // a subdirectory of the output per target, unless flat
+	layoutNative = "native" // each target's conventional directory under the root
+)
+
+// applyLayout gives each target of cfg without an output directory of its
+// own the directory its tool reads files from under cfg.Root, for the native
+// layout. Targets with no such directory get a subdirectory named after them.
+func applyLayout(cfg *buildConfig) error {
+	switch cfg.Layout {
+	case "", layoutTarget:
+		return nil
+	case layoutNative:
+	default:
+		return fmt.Errorf("unknown layout: %s (valid layouts: %s, %s)", cfg.Layout, layoutTarget, layoutNative)
+	}
+
+	root := cfg.Root
+	if root == "" {
+		root = "."
+	}
+	c := newCompiler()
+	outputs := make(map[string]string, len(cfg.Targets))
+	for name, output := range cfg.TargetOutputs {
+		outputs[name] = output
+	}
+	roots := make(map[string]string, len(cfg.Targets))
+	for _, name := range cfg.Targets {
+		if targetOutput(*cfg, name) != "" {
+			continue
+		}
+		target := name
+		if alias, ok := cfg.Aliases[name]; ok {
+			target = alias.Target
+		}
+		t, err := parseTarget(target)
+		if err != nil {
+			return unknownTarget(target, cfg.Aliases)
+		}
+		dir, ok := c.DefaultOutputDir(t)
+		if !ok {
+			dir = name
+		}
+		outputs[name] = filepath.Join(root, filepath.FromSlash(dir))
+		roots[name] = root
+	}
+	cfg.TargetOutputs = outputs
+	cfg.TargetRoots = roots
+	return nil
+}
+
+// projectRootResults returns allResults with the results that the tools of
+// targets in cfg.TargetRoots read from the project root, such as CLAUDE.md,
+// moved to results of their own written there.
+func projectRootResults(allResults []targetResults, cfg buildConfig) []targetResults {
+	if len(cfg.TargetRoots) == 0 {
+		return allResults
+	}
+	c := newCompiler()
+	var split []targetResults
+	for _, tr := range allResults {
+		root, ok := cfg.TargetRoots[tr.target]
+		target := tr.target
+		if alias, isAlias := cfg.Aliases[tr.target]; isAlias {
+			target = alias.Target
+		}
+		t, err := parseTarget(target)
+		if !ok || err != nil {
+			split = append(split, tr)
+			continue
+		}
+		rootResults, rest := c.ProjectRoot(t, tr.results)
+		tr.results = rest
+		split = append(split, tr)
+		if len(rootResults) > 0 {
+			tr.results, tr.output = rootResults, root
+			split = append(split, tr)
+		}
+	}
+	return split
+}
+
+// mergeFunc returns the function merging the results of target across
+// resources, or nil if it does not merge them.
+func mergeFunc(c *compiler.Compiler, target compiler.Target) func([]compiler.CompilationResult) ([]compiler.CompilationResult, error) {
+	if !c.Merges(target) {
+		return nil
+	}
+	return func(results []compiler.CompilationResult) ([]compiler.CompilationResult, error) {
+		return c.Merge(target, results)
+	}
+}
+
+// bannerFunc returns the function adding a provenance banner to the results
+// of target.
+func bannerFunc(c *compiler.Compiler, target compiler.Target) func(path, content, banner string) string {
+	return func(path, content, banner string) string {
+		return c.AddBanner(target, path, content, banner)
+	}
+}
+
+// mergeOptions returns base with the options of override replacing it key by
+// key.
+func mergeOptions(base, override map[string]any) map[string]any {
+	merged := make(map[string]any, len(base)+len(override))
+	for k, v := range base {
+		merged[k] = v
+	}
+	for k, v := range override {
+		merged[k] = v
+	}
+	return merged
+}
+
+// parseTarget maps a built-in target name to its compiler target.
+func parseTarget(name string) (compiler.Target, error) {
+	switch name {
+	case "markdown":
+		return compiler.TargetMarkdown, nil
+	case "kiro":
+		return compiler.TargetKiro, nil
+	case "cursor":
+		return compiler.TargetCursor, nil
+	case "claude":
+		return compiler.TargetClaude, nil
+	case "copilot":
+		return compiler.TargetCopilot, nil
+	case "gemini":
+		return compiler.TargetGemini, nil
+	case "json":
+		return compiler.TargetJSON, nil
+	case "agentsmd":
+		return compiler.TargetAgentsMD, nil
+	default:
+		return "", unknownTarget(name, nil)
+	}
+}
+
+// builtinTargets lists the built-in target names, in the order help and
+// errors show them.
+var builtinTargets = []string{"cursor", "kiro", "claude", "copilot", "gemini", "agentsmd", "markdown", "json"}
+
+// unknownTarget returns the error for an unrecognized target name, suggesting
+// the closest built-in target or alias.
+func unknownTarget(name string, aliases map[string]targetAlias) error {
+	candidates := append(append([]string(nil), builtinTargets...), format.SortedKeys(aliases)...)
+	return fmt.Errorf("%w: %s (%s)", compiler.ErrUnknownTarget, name, suggest.Hint(name, "targets", candidates))
+}
+
+// newCompiler returns a compiler with the built-in targets and options.
+func newCompiler(options ...compiler.Option) *compiler.Compiler {
+	options = append([]compiler.Option{compiler.WithoutDefaults(), compiler.WithTargets(targets.Defaults()...)}, options...)
+	return compiler.NewCompiler(options...)
 }
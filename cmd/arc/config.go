@@ -0,0 +1,339 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/jomadu/ai-resource-compiler-go/internal/format"
+	"gopkg.in/yaml.v3"
+)
+
+// defaultConfigFile is the workspace config read by arc build when -config is
+// not given.
+const defaultConfigFile = "arc.yaml"
+
+// configFileNames are the workspace configs looked for, in order, when
+// -config is not given.
+var configFileNames = []string{defaultConfigFile, ".arc.yaml", "arc.config.yaml"}
+
+// configFlagUsage is the usage of the -config flag.
+var configFlagUsage = "Workspace config file (default: the first of " + strings.Join(configFileNames, ", ") + " present)"
+
+// findConfigFile returns path if it is set, otherwise the first of
+// configFileNames in the current directory, or "" if there is none.
+func findConfigFile(path string) string {
+	if path != "" {
+		return path
+	}
+	for _, name := range configFileNames {
+		if _, err := os.Stat(name); err == nil {
+			return name
+		}
+	}
+	return ""
+}
+
+// buildSettings are the build inputs a workspace config or one of its
+// profiles can set.
+type buildSettings struct {
+	Resources    []string          `yaml:"resources"`
+	Targets      []string          `yaml:"targets"`
+	Output       string            `yaml:"output"`
+	Flat         *bool             `yaml:"flat"`
+	Layout       string            `yaml:"layout"`
+	Root         string            `yaml:"root"`
+	Lean         *bool             `yaml:"lean"`
+	Banner       *bool             `yaml:"banner"`
+	Manifest     *bool             `yaml:"manifest"`
+	Link         string            `yaml:"link"`
+	Incremental  *bool             `yaml:"incremental"`
+	EmbedSource  string            `yaml:"embedSource"`
+	Prefix       string            `yaml:"prefix"`
+	PathTemplate string            `yaml:"pathTemplate"`
+	Locale       string            `yaml:"locale"`
+	Overlays     []string          `yaml:"overlays"`
+	Variables    map[string]string `yaml:"variables"`
+
+	// Templates executes bodies as Go templates with TemplateData as dot;
+	// see compiler.CompileOptions.
+	Templates    *bool          `yaml:"templates"`
+	TemplateData map[string]any `yaml:"templateData"`
+	TemplateEnv  []string       `yaml:"templateEnv"`
+
+	// MinEnforcement compiles only rules at or above this enforcement
+	// level; see compiler.CompileOptions.
+	MinEnforcement string `yaml:"minEnforcement"`
+
+	// Tags compiles only rules and prompts carrying any of these tags; see
+	// compiler.CompileOptions.
+	Tags []string `yaml:"tags"`
+
+	// Transformers rewrite bodies before targets format them, e.g.
+	// [trim-trailing-whitespace, wrap=100]; see compiler.ParseTransformer.
+	Transformers []string `yaml:"transformers"`
+
+	// TokenBudget is the number of tokens a compiled file may take before
+	// build warns about it.
+	TokenBudget int `yaml:"tokenBudget"`
+
+	// Outputs and Options are keyed by built-in target or alias name. An
+	// output directory replaces Output for that target's results; options
+	// are passed to the target, over those of an alias.
+	Outputs map[string]string         `yaml:"outputs"`
+	Options map[string]map[string]any `yaml:"options"`
+}
+
+// targetAlias is a named target preset: a built-in target plus options and
+// an output directory, referenced by name wherever a target is expected.
+type targetAlias struct {
+	Target  string         `yaml:"target"`
+	Output  string         `yaml:"output"`
+	Options map[string]any `yaml:"options"`
+}
+
+// workspaceConfig is the arc.yaml workspace configuration: base settings plus
+// named profiles layered on top of them.
+type workspaceConfig struct {
+	buildSettings `yaml:",inline"`
+	Profiles      map[string]buildSettings `yaml:"profiles"`
+	Aliases       map[string]targetAlias   `yaml:"aliases"`
+
+	dir string
+}
+
+func loadWorkspaceConfig(path string) (*workspaceConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	var cfg workspaceConfig
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+	cfg.dir = filepath.Dir(path)
+	return &cfg, nil
+}
+
+// resolve returns the base settings with the named profile applied. Profile
+// resources, targets, output, flat, layout, root, lean, banner, link, embedSource, prefix,
+// pathTemplate, locale, minEnforcement, tags, transformers, tokenBudget, templates, and templateEnv replace the base values; overlays are
+// applied after the base overlays; variables, templateData, outputs, and
+// options are merged per key, with profile values winning. Relative paths are resolved
+// against the config file's directory.
+func (c *workspaceConfig) resolve(profile string) (buildSettings, error) {
+	settings := c.buildSettings
+	settings.Variables = make(map[string]string)
+	for k, v := range c.Variables {
+		settings.Variables[k] = v
+	}
+	settings.TemplateData = make(map[string]any)
+	for k, v := range c.TemplateData {
+		settings.TemplateData[k] = v
+	}
+	settings.Outputs = make(map[string]string)
+	for k, v := range c.Outputs {
+		settings.Outputs[k] = v
+	}
+	settings.Options = make(map[string]map[string]any)
+	for k, v := range c.Options {
+		settings.Options[k] = v
+	}
+
+	if profile != "" {
+		p, ok := c.Profiles[profile]
+		if !ok {
+			names := make([]string, 0, len(c.Profiles))
+			for name := range c.Profiles {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			return buildSettings{}, fmt.Errorf("unknown profile: %s (available profiles: %s)", profile, strings.Join(names, ", "))
+		}
+		if len(p.Resources) > 0 {
+			settings.Resources = p.Resources
+		}
+		if len(p.Targets) > 0 {
+			settings.Targets = p.Targets
+		}
+		if p.Output != "" {
+			settings.Output = p.Output
+		}
+		if p.Flat != nil {
+			settings.Flat = p.Flat
+		}
+		if p.Layout != "" {
+			settings.Layout = p.Layout
+		}
+		if p.Root != "" {
+			settings.Root = p.Root
+		}
+		if p.Lean != nil {
+			settings.Lean = p.Lean
+		}
+		if p.Banner != nil {
+			settings.Banner = p.Banner
+		}
+		if p.Manifest != nil {
+			settings.Manifest = p.Manifest
+		}
+		if p.Link != "" {
+			settings.Link = p.Link
+		}
+		if p.Incremental != nil {
+			settings.Incremental = p.Incremental
+		}
+		if p.EmbedSource != "" {
+			settings.EmbedSource = p.EmbedSource
+		}
+		if p.Prefix != "" {
+			settings.Prefix = p.Prefix
+		}
+		if p.PathTemplate != "" {
+			settings.PathTemplate = p.PathTemplate
+		}
+		if p.Locale != "" {
+			settings.Locale = p.Locale
+		}
+		if p.MinEnforcement != "" {
+			settings.MinEnforcement = p.MinEnforcement
+		}
+		if p.Tags != nil {
+			settings.Tags = p.Tags
+		}
+		if p.Transformers != nil {
+			settings.Transformers = p.Transformers
+		}
+		if p.TokenBudget != 0 {
+			settings.TokenBudget = p.TokenBudget
+		}
+		if p.Templates != nil {
+			settings.Templates = p.Templates
+		}
+		if p.TemplateEnv != nil {
+			settings.TemplateEnv = p.TemplateEnv
+		}
+		settings.Overlays = append(append([]string{}, c.Overlays...), p.Overlays...)
+		for k, v := range p.Variables {
+			settings.Variables[k] = v
+		}
+		for k, v := range p.TemplateData {
+			settings.TemplateData[k] = v
+		}
+		for k, v := range p.Outputs {
+			settings.Outputs[k] = v
+		}
+		for k, v := range p.Options {
+			settings.Options[k] = v
+		}
+	}
+
+	for _, name := range append(format.SortedKeys(settings.Outputs), format.SortedKeys(settings.Options)...) {
+		if _, ok := c.Aliases[name]; ok {
+			continue
+		}
+		if _, err := parseTarget(name); err != nil {
+			return buildSettings{}, unknownTarget(name, c.Aliases)
+		}
+	}
+	for name, output := range settings.Outputs {
+		settings.Outputs[name] = c.resolvePath(output)
+	}
+
+	settings.Resources = c.resolvePaths(settings.Resources)
+	settings.Overlays = c.resolvePaths(settings.Overlays)
+	// The native layout is relative to the config file's directory unless
+	// root says otherwise.
+	settings.Root = c.resolvePath(settings.Root)
+	if settings.Output != "" && settings.Output != "stdout" {
+		settings.Output = c.resolvePath(settings.Output)
+	}
+	return settings, nil
+}
+
+// buildConfig returns the compile settings s configures, with the target
+// aliases of the workspace config. Callers apply their flags on top.
+func (s buildSettings) buildConfig(aliases map[string]targetAlias) buildConfig {
+	cfg := buildConfig{
+		Targets:        s.Targets,
+		Output:         s.Output,
+		Layout:         s.Layout,
+		Root:           s.Root,
+		Overlays:       s.Overlays,
+		Variables:      s.Variables,
+		Locale:         s.Locale,
+		MinEnforcement: s.MinEnforcement,
+		Tags:           s.Tags,
+		Transformers:   s.Transformers,
+		TokenBudget:    s.TokenBudget,
+		TemplateData:   s.TemplateData,
+		TemplateEnv:    s.TemplateEnv,
+		Aliases:        aliases,
+		TargetOutputs:  s.Outputs,
+		TargetOptions:  s.Options,
+		EmbedSource:    s.EmbedSource,
+		Prefix:         s.Prefix,
+		PathTemplate:   s.PathTemplate,
+		Link:           s.Link,
+		Manifest:       true,
+	}
+	if s.Flat != nil {
+		cfg.Flat = *s.Flat
+	}
+	if s.Lean != nil {
+		cfg.Lean = *s.Lean
+	}
+	if s.Banner != nil {
+		cfg.Banner = *s.Banner
+	}
+	if s.Manifest != nil {
+		cfg.Manifest = *s.Manifest
+	}
+	if s.Incremental != nil {
+		cfg.Incremental = *s.Incremental
+	}
+	if s.Templates != nil {
+		cfg.Templates = *s.Templates
+	}
+	return cfg
+}
+
+// targetAliases validates the configured aliases and returns them with
+// output directories resolved against the config file's directory.
+func (c *workspaceConfig) targetAliases() (map[string]targetAlias, error) {
+	aliases := make(map[string]targetAlias, len(c.Aliases))
+	for name, alias := range c.Aliases {
+		if _, err := parseTarget(name); err == nil {
+			return nil, fmt.Errorf("alias %s shadows a built-in target", name)
+		}
+		if _, err := parseTarget(alias.Target); err != nil {
+			return nil, fmt.Errorf("alias %s: %w", name, err)
+		}
+		if alias.Output != "" {
+			alias.Output = c.resolvePath(alias.Output)
+		}
+		aliases[name] = alias
+	}
+	return aliases, nil
+}
+
+func (c *workspaceConfig) resolvePaths(paths []string) []string {
+	if paths == nil {
+		return nil
+	}
+	out := make([]string, len(paths))
+	for i, p := range paths {
+		out[i] = c.resolvePath(p)
+	}
+	return out
+}
+
+func (c *workspaceConfig) resolvePath(path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(c.dir, path)
+}
@@ -0,0 +1,207 @@
+package main
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const testWorkspaceConfig = `resources:
+  - rules/*.yaml
+targets: [markdown]
+output: out
+overlays:
+  - overlays/base.yaml
+variables:
+  team: platform
+  channel: "#eng"
+profiles:
+  prod:
+    targets: [cursor, kiro]
+    overlays:
+      - overlays/prod.yaml
+    variables:
+      channel: "#prod"
+  oss:
+    resources:
+      - public/*.yaml
+    output: stdout
+    flat: true
+    prefix: oss-
+`
+
+func TestWorkspaceConfigResolveBase(t *testing.T) {
+	dir := t.TempDir()
+	path := writeTestFile(t, dir, "arc.yaml", testWorkspaceConfig)
+
+	ws, err := loadWorkspaceConfig(path)
+	if err != nil {
+		t.Fatalf("loadWorkspaceConfig() error = %v", err)
+	}
+	settings, err := ws.resolve("")
+	if err != nil {
+		t.Fatalf("resolve() error = %v", err)
+	}
+
+	if !reflect.DeepEqual(settings.Targets, []string{"markdown"}) {
+		t.Errorf("Targets = %v, want [markdown]", settings.Targets)
+	}
+	if settings.Output != filepath.Join(dir, "out") {
+		t.Errorf("Output = %v, want path relative to config", settings.Output)
+	}
+	if settings.Resources[0] != filepath.Join(dir, "rules/*.yaml") {
+		t.Errorf("Resources = %v, want paths relative to config", settings.Resources)
+	}
+}
+
+func TestWorkspaceConfigResolveProfile(t *testing.T) {
+	dir := t.TempDir()
+	path := writeTestFile(t, dir, "arc.yaml", testWorkspaceConfig)
+
+	ws, err := loadWorkspaceConfig(path)
+	if err != nil {
+		t.Fatalf("loadWorkspaceConfig() error = %v", err)
+	}
+
+	prod, err := ws.resolve("prod")
+	if err != nil {
+		t.Fatalf("resolve(prod) error = %v", err)
+	}
+	if !reflect.DeepEqual(prod.Targets, []string{"cursor", "kiro"}) {
+		t.Errorf("Targets = %v, want profile targets", prod.Targets)
+	}
+	wantOverlays := []string{filepath.Join(dir, "overlays/base.yaml"), filepath.Join(dir, "overlays/prod.yaml")}
+	if !reflect.DeepEqual(prod.Overlays, wantOverlays) {
+		t.Errorf("Overlays = %v, want %v", prod.Overlays, wantOverlays)
+	}
+	if prod.Variables["team"] != "platform" || prod.Variables["channel"] != "#prod" {
+		t.Errorf("Variables = %v, want base merged with profile", prod.Variables)
+	}
+
+	oss, err := ws.resolve("oss")
+	if err != nil {
+		t.Fatalf("resolve(oss) error = %v", err)
+	}
+	if oss.Output != "stdout" || oss.Flat == nil || !*oss.Flat {
+		t.Errorf("oss output = %v flat = %v, want stdout and flat", oss.Output, oss.Flat)
+	}
+	if oss.Resources[0] != filepath.Join(dir, "public/*.yaml") {
+		t.Errorf("Resources = %v, want profile resources", oss.Resources)
+	}
+	if prod.Prefix != "" || oss.Prefix != "oss-" {
+		t.Errorf("Prefix = %q (prod), %q (oss), want only the oss profile prefix", prod.Prefix, oss.Prefix)
+	}
+
+	if ws.Variables["channel"] != "#eng" {
+		t.Error("resolving a profile modified the base variables")
+	}
+}
+
+func TestWorkspaceConfigUnknownProfile(t *testing.T) {
+	dir := t.TempDir()
+	path := writeTestFile(t, dir, "arc.yaml", testWorkspaceConfig)
+
+	ws, err := loadWorkspaceConfig(path)
+	if err != nil {
+		t.Fatalf("loadWorkspaceConfig() error = %v", err)
+	}
+	_, err = ws.resolve("staging")
+	if err == nil {
+		t.Fatal("Expected error for unknown profile, got nil")
+	}
+	if !strings.Contains(err.Error(), "available profiles: oss, prod") {
+		t.Errorf("Expected available profiles in error, got: %v", err)
+	}
+}
+
+func TestWorkspaceConfigTargetAliases(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  string
+		wantErr string
+	}{
+		{"valid", "aliases:\n  cursor-strict:\n    target: cursor\n    output: rules\n", ""},
+		{"shadows built-in", "aliases:\n  cursor:\n    target: markdown\n", "shadows a built-in target"},
+		{"unknown target", "aliases:\n  strict:\n    target: vim\n", "unknown target: vim"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			ws, err := loadWorkspaceConfig(writeTestFile(t, dir, "arc.yaml", tt.config))
+			if err != nil {
+				t.Fatalf("loadWorkspaceConfig() error = %v", err)
+			}
+			aliases, err := ws.targetAliases()
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("targetAliases() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("targetAliases() error = %v", err)
+			}
+			if got := aliases["cursor-strict"].Output; got != filepath.Join(dir, "rules") {
+				t.Errorf("Output = %q, want resolved against config dir", got)
+			}
+		})
+	}
+}
+
+func TestWorkspaceConfigTargetSettings(t *testing.T) {
+	dir := t.TempDir()
+	path := writeTestFile(t, dir, "arc.yaml", `outputs:
+  claude: .claude/skills
+options:
+  cursor: {alwaysApply: true}
+profiles:
+  review:
+    options:
+      cursor: {ruleTypes: true}
+`)
+
+	ws, err := loadWorkspaceConfig(path)
+	if err != nil {
+		t.Fatalf("loadWorkspaceConfig() error = %v", err)
+	}
+	settings, err := ws.resolve("review")
+	if err != nil {
+		t.Fatalf("resolve() error = %v", err)
+	}
+	if settings.Outputs["claude"] != filepath.Join(dir, ".claude/skills") {
+		t.Errorf("Outputs = %v, want paths relative to config", settings.Outputs)
+	}
+	if !reflect.DeepEqual(settings.Options["cursor"], map[string]any{"ruleTypes": true}) {
+		t.Errorf("Options = %v, want profile options", settings.Options)
+	}
+
+	path = writeTestFile(t, dir, "typo.yaml", "outputs:\n  cursr: rules\n")
+	if ws, err = loadWorkspaceConfig(path); err != nil {
+		t.Fatalf("loadWorkspaceConfig() error = %v", err)
+	}
+	if _, err := ws.resolve(""); err == nil || !strings.Contains(err.Error(), "cursor") {
+		t.Errorf("resolve() error = %v, want unknown target suggesting cursor", err)
+	}
+}
+
+func TestFindConfigFile(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+
+	if got := findConfigFile(""); got != "" {
+		t.Errorf("findConfigFile() = %q, want none", got)
+	}
+	writeTestFile(t, dir, "arc.config.yaml", "targets: [kiro]\n")
+	if got := findConfigFile(""); got != "arc.config.yaml" {
+		t.Errorf("findConfigFile() = %q, want arc.config.yaml", got)
+	}
+	writeTestFile(t, dir, ".arc.yaml", "targets: [kiro]\n")
+	if got := findConfigFile(""); got != ".arc.yaml" {
+		t.Errorf("findConfigFile() = %q, want .arc.yaml before arc.config.yaml", got)
+	}
+	if got := findConfigFile("custom.yaml"); got != "custom.yaml" {
+		t.Errorf("findConfigFile(custom.yaml) = %q", got)
+	}
+}
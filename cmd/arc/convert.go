@@ -0,0 +1,74 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+)
+
+func runConvert(args []string) error {
+	flags := flag.NewFlagSet("convert", flag.ContinueOnError)
+	from := flags.String("from", "", "Target whose files to convert: cursor, copilot, kiro, or claude")
+	to := flags.String("to", "", "Target or target alias to convert to")
+	id := flags.String("id", "", "ID of the ruleset the rules are read into (default: <from>Rules)")
+	output := flags.String("o", "stdout", "Output directory, or stdout")
+	lean := flags.Bool("lean", false, "Omit the metadata block from converted rules")
+	force := flags.Bool("force", false, "Overwrite existing files arc did not generate")
+	configPath := flags.String("config", "", configFlagUsage)
+
+	paths, err := parseInterspersed(flags, args)
+	if err != nil {
+		return err
+	}
+	if *to == "" {
+		return fmt.Errorf("-to required: the target to convert to")
+	}
+	resource, err := importResource(*from, *id, paths)
+	if err != nil {
+		return err
+	}
+
+	cfg := buildConfig{
+		Targets: []string{*to},
+		Output:  *output,
+		Flat:    true,
+		Lean:    *lean,
+		Pending: &pendingResults{},
+	}
+	if path := findConfigFile(*configPath); path != "" {
+		ws, err := loadWorkspaceConfig(path)
+		if err != nil {
+			return err
+		}
+		if cfg.Aliases, err = ws.targetAliases(); err != nil {
+			return err
+		}
+		settings, err := ws.resolve("")
+		if err != nil {
+			return err
+		}
+		cfg.TargetOptions = settings.Options
+	}
+	if !*force {
+		cfg.Guard = newOverwriteGuard()
+	}
+
+	results, err := compileResource(resource, cfg)
+	if err != nil {
+		return err
+	}
+	// Converted files go to -o, not to the output the config gives the
+	// target.
+	var ready []targetResults
+	for _, tr := range results {
+		tr.output = ""
+		if tr.merge != nil {
+			cfg.Pending.add(tr)
+		} else {
+			ready = append(ready, tr)
+		}
+	}
+	if err := writeResults(ready, cfg); err != nil {
+		return err
+	}
+	return writePending(cfg)
+}
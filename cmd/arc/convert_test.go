@@ -0,0 +1,38 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestConvertWritesTargetFiles(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, dir, "go-style.mdc", "---\ndescription: Go conventions\nglobs: **/*.go\n---\n# Go Style\n\nUse gofmt.\n")
+	out := filepath.Join(dir, "instructions")
+
+	if err := runConvert([]string{"-from", "cursor", "-to", "copilot", "-lean", filepath.Join(dir, "go-style.mdc"), "-o", out}); err != nil {
+		t.Fatalf("runConvert() error = %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(out, "instructions", "cursorRules_go-style.instructions.md"))
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	for _, want := range []string{"description: Go conventions\n", "applyTo: \"**/*.go\"\n", "# Go Style (SHOULD)\n\nUse gofmt."} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("output missing %q:\n%s", want, data)
+		}
+	}
+}
+
+func TestConvertErrors(t *testing.T) {
+	dir := t.TempDir()
+	if err := runConvert([]string{"-from", "cursor", dir}); err == nil || !strings.Contains(err.Error(), "-to required") {
+		t.Errorf("runConvert() error = %v, want -to error", err)
+	}
+	if err := runConvert([]string{"-to", "copilot", dir}); err == nil || !strings.Contains(err.Error(), "-from required") {
+		t.Errorf("runConvert() error = %v, want -from error", err)
+	}
+}
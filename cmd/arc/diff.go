@@ -0,0 +1,364 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/jomadu/ai-resource-compiler-go/internal/format"
+	"github.com/jomadu/ai-resource-compiler-go/pkg/compiler"
+)
+
+// resourceChange describes a single field-level difference between two resources.
+type resourceChange struct {
+	Change string `json:"change"` // added, removed, or modified
+	Item   string `json:"item,omitempty"`
+	Field  string `json:"field,omitempty"`
+	Old    string `json:"old,omitempty"`
+	New    string `json:"new,omitempty"`
+}
+
+// diffItem is the comparable view of a single rule or prompt.
+type diffItem struct {
+	Name        string
+	Description string
+	Enforcement string
+	Scope       []string
+	Body        string
+}
+
+// diffView is the comparable view of a resource: its metadata and items keyed by ID.
+type diffView struct {
+	Kind     string
+	Metadata format.Metadata
+	Items    map[string]diffItem
+}
+
+func runDiff(args []string) error {
+	fs := flag.NewFlagSet("diff", flag.ContinueOnError)
+	outputFormat := fs.String("format", "text", "Output format: text or json")
+
+	files, err := parseInterspersed(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(files) != 2 {
+		return fmt.Errorf("exactly two resource files required")
+	}
+	if *outputFormat != "text" && *outputFormat != "json" {
+		return fmt.Errorf("unknown format: %s (valid formats: text, json)", *outputFormat)
+	}
+
+	oldResource, err := loadResource(files[0])
+	if err != nil {
+		return &fileError{File: files[0], Err: err}
+	}
+	newResource, err := loadResource(files[1])
+	if err != nil {
+		return &fileError{File: files[1], Err: err}
+	}
+
+	changes := diffResources(oldResource, newResource)
+
+	if *outputFormat == "json" {
+		return writeDiffJSON(os.Stdout, files[0], files[1], changes)
+	}
+	writeDiffText(os.Stdout, changes)
+	return nil
+}
+
+// diffResources compares two resources field by field. Bodies are compared
+// after fragment resolution so that refactoring fragments is not reported.
+func diffResources(oldResource, newResource *compiler.Resource) []resourceChange {
+	oldView := newDiffView(oldResource)
+	newView := newDiffView(newResource)
+
+	var changes []resourceChange
+	modified := func(item, field, oldValue, newValue string) {
+		if oldValue != newValue {
+			changes = append(changes, resourceChange{Change: "modified", Item: item, Field: field, Old: oldValue, New: newValue})
+		}
+	}
+
+	modified("", "kind", oldView.Kind, newView.Kind)
+	modified("", "metadata.id", oldView.Metadata.ID, newView.Metadata.ID)
+	modified("", "metadata.name", oldView.Metadata.Name, newView.Metadata.Name)
+	modified("", "metadata.description", oldView.Metadata.Description, newView.Metadata.Description)
+
+	ids := make(map[string]bool)
+	for id := range oldView.Items {
+		ids[id] = true
+	}
+	for id := range newView.Items {
+		ids[id] = true
+	}
+	sorted := make([]string, 0, len(ids))
+	for id := range ids {
+		sorted = append(sorted, id)
+	}
+	sort.Strings(sorted)
+
+	for _, id := range sorted {
+		oldItem, inOld := oldView.Items[id]
+		newItem, inNew := newView.Items[id]
+		switch {
+		case !inOld:
+			changes = append(changes, resourceChange{Change: "added", Item: id})
+		case !inNew:
+			changes = append(changes, resourceChange{Change: "removed", Item: id})
+		default:
+			modified(id, "name", oldItem.Name, newItem.Name)
+			modified(id, "description", oldItem.Description, newItem.Description)
+			modified(id, "enforcement", oldItem.Enforcement, newItem.Enforcement)
+			modified(id, "scope", strings.Join(oldItem.Scope, ", "), strings.Join(newItem.Scope, ", "))
+			modified(id, "body", oldItem.Body, newItem.Body)
+		}
+	}
+
+	return changes
+}
+
+func newDiffView(resource *compiler.Resource) diffView {
+	view := diffView{Kind: resource.Kind, Items: make(map[string]diffItem)}
+
+	switch spec := resource.Spec.(type) {
+	case *format.Rule:
+		view.Metadata = spec.Metadata
+		view.Items[spec.Metadata.ID] = diffItem{
+			Name:        spec.Metadata.Name,
+			Description: spec.Metadata.Description,
+			Enforcement: spec.Spec.Enforcement,
+			Scope:       extractFiles(spec.Spec.Scope),
+			Body:        format.ResolveBody(spec.Spec.Body, spec.Spec.Fragments),
+		}
+	case *format.Ruleset:
+		view.Metadata = spec.Metadata
+		for id, item := range spec.Spec.Rules {
+			view.Items[id] = diffItem{
+				Name:        item.Name,
+				Description: item.Description,
+				Enforcement: item.Enforcement,
+				Scope:       extractFiles(item.Scope),
+				Body:        format.ResolveBody(item.Body, spec.Spec.Fragments),
+			}
+		}
+	case *format.Prompt:
+		view.Metadata = spec.Metadata
+		view.Items[spec.Metadata.ID] = diffItem{
+			Name:        spec.Metadata.Name,
+			Description: spec.Metadata.Description,
+			Body:        format.ResolveBody(spec.Spec.Body, spec.Spec.Fragments),
+		}
+	case *format.Command:
+		view.Metadata = spec.Metadata
+		view.Items[spec.Metadata.ID] = diffItem{
+			Name:        spec.Metadata.Name,
+			Description: spec.Metadata.Description,
+			Body:        format.ResolveBody(spec.Spec.Body, spec.Spec.Fragments),
+		}
+	case *format.Context:
+		view.Metadata = spec.Metadata
+		view.Items[spec.Metadata.ID] = diffItem{
+			Name:        spec.Metadata.Name,
+			Description: spec.Metadata.Description,
+			Body:        format.ResolveBody(spec.Spec.Body, spec.Spec.Fragments),
+		}
+	case *format.Promptset:
+		view.Metadata = spec.Metadata
+		for id, item := range spec.Spec.Prompts {
+			view.Items[id] = diffItem{
+				Name: item.Name,
+				Body: format.ResolveBody(item.Body, spec.Spec.Fragments),
+			}
+		}
+	}
+
+	return view
+}
+
+func extractFiles(scope []format.ScopeEntry) []string {
+	return format.ScopeGlobs(scope)
+}
+
+func writeDiffJSON(w io.Writer, oldFile, newFile string, changes []resourceChange) error {
+	report := struct {
+		Old     string           `json:"old"`
+		New     string           `json:"new"`
+		Changes []resourceChange `json:"changes"`
+	}{oldFile, newFile, changes}
+	if report.Changes == nil {
+		report.Changes = []resourceChange{}
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(report)
+}
+
+func writeDiffText(w io.Writer, changes []resourceChange) {
+	if len(changes) == 0 {
+		fmt.Fprintln(w, "No differences")
+		return
+	}
+
+	for _, c := range changes {
+		switch c.Change {
+		case "added":
+			fmt.Fprintln(w, colorize(w, colorGreen, "+ "+c.Item))
+		case "removed":
+			fmt.Fprintln(w, colorize(w, colorRed, "- "+c.Item))
+		default:
+			label := c.Field
+			if c.Item != "" {
+				label = c.Item + ": " + c.Field
+			}
+			if c.Field == "body" {
+				fmt.Fprintln(w, colorize(w, colorYellow, "~ "+label))
+				for _, line := range diffLines(strings.Split(c.Old, "\n"), strings.Split(c.New, "\n")) {
+					fmt.Fprintf(w, "    %s\n", line)
+				}
+				continue
+			}
+			fmt.Fprintln(w, colorize(w, colorYellow, fmt.Sprintf("~ %s: %q -> %q", label, c.Old, c.New)))
+		}
+	}
+}
+
+// diffLines returns a line diff of a and b based on their longest common
+// subsequence. Lines are prefixed with "-" (only in a), "+" (only in b), or
+// " " (in both).
+func diffLines(a, b []string) []string {
+	edits := lineEdits(a, b)
+	lines := make([]string, len(edits))
+	for i, e := range edits {
+		lines[i] = string(e.op) + " " + e.text
+	}
+	return lines
+}
+
+// lineEdit is a line of a line diff: op is '-' for a line only in the old
+// text, '+' for one only in the new text, and ' ' for one in both.
+type lineEdit struct {
+	op   byte
+	text string
+}
+
+// lineEdits returns the edits turning a into b, based on their longest
+// common subsequence.
+func lineEdits(a, b []string) []lineEdit {
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var edits []lineEdit
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			edits = append(edits, lineEdit{' ', a[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			edits = append(edits, lineEdit{'-', a[i]})
+			i++
+		default:
+			edits = append(edits, lineEdit{'+', b[j]})
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		edits = append(edits, lineEdit{'-', a[i]})
+	}
+	for ; j < len(b); j++ {
+		edits = append(edits, lineEdit{'+', b[j]})
+	}
+	return edits
+}
+
+// diffContext is the number of unchanged lines unifiedDiff shows around each
+// change.
+const diffContext = 3
+
+// unifiedDiff returns a unified diff turning old, named oldName, into
+// content, named newName, or nil if they are equal.
+func unifiedDiff(oldName, newName, old, content string) []string {
+	edits := lineEdits(splitLines(old), splitLines(content))
+	aLine := make([]int, len(edits)+1)
+	bLine := make([]int, len(edits)+1)
+	for k, e := range edits {
+		aLine[k+1], bLine[k+1] = aLine[k], bLine[k]
+		if e.op != '+' {
+			aLine[k+1]++
+		}
+		if e.op != '-' {
+			bLine[k+1]++
+		}
+	}
+
+	var lines []string
+	for k := 0; ; {
+		for k < len(edits) && edits[k].op == ' ' {
+			k++
+		}
+		if k == len(edits) {
+			break
+		}
+		// A hunk runs until more unchanged lines follow a change than the
+		// context of two hunks would show.
+		end := k
+		for j := k; j < len(edits); j++ {
+			if edits[j].op != ' ' {
+				end = j + 1
+			} else if j-end+1 > 2*diffContext {
+				break
+			}
+		}
+		from, to := max(k-diffContext, 0), min(end+diffContext, len(edits))
+
+		if lines == nil {
+			lines = []string{"--- " + oldName, "+++ " + newName}
+		}
+		lines = append(lines, fmt.Sprintf("@@ -%s +%s @@", hunkRange(aLine[from], aLine[to]), hunkRange(bLine[from], bLine[to])))
+		for _, e := range edits[from:to] {
+			text, newline := strings.CutSuffix(e.text, "\n")
+			lines = append(lines, string(e.op)+text)
+			if !newline {
+				lines = append(lines, `\ No newline at end of file`)
+			}
+		}
+		k = end
+	}
+	return lines
+}
+
+// hunkRange returns the range of a hunk header for the lines after start up
+// to end, e.g. "3,4", or "2,0" for none after line 2.
+func hunkRange(start, end int) string {
+	if start == end {
+		return fmt.Sprintf("%d,0", start)
+	}
+	return fmt.Sprintf("%d,%d", start+1, end-start)
+}
+
+// splitLines returns the lines of text, each with its newline, if any.
+func splitLines(text string) []string {
+	lines := strings.SplitAfter(text, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
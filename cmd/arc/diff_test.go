@@ -0,0 +1,185 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+const diffOldRuleset = `apiVersion: ai-resource/draft
+kind: Ruleset
+metadata:
+  id: cleanCode
+  name: Clean Code
+spec:
+  fragments:
+    naming: Use descriptive names.
+  rules:
+    meaningfulNames:
+      name: Use Meaningful Names
+      enforcement: should
+      scope:
+        - files: ["**/*.ts"]
+      body:
+        - $naming
+        - Avoid abbreviations.
+    removedRule:
+      name: Removed
+      enforcement: may
+      body: Gone soon.
+`
+
+const diffNewRuleset = `apiVersion: ai-resource/draft
+kind: Ruleset
+metadata:
+  id: cleanCode
+  name: Clean Code Practices
+spec:
+  rules:
+    meaningfulNames:
+      name: Use Meaningful Names
+      enforcement: must
+      scope:
+        - files: ["**/*.ts", "**/*.js"]
+      body: |-
+        Use descriptive names.
+
+        Avoid cryptic abbreviations.
+    addedRule:
+      name: Added
+      enforcement: must
+      body: New rule.
+`
+
+func TestDiffResources(t *testing.T) {
+	dir := t.TempDir()
+	oldResource, err := loadResource(writeTestFile(t, dir, "old.yaml", diffOldRuleset))
+	if err != nil {
+		t.Fatalf("loadResource() error = %v", err)
+	}
+	newResource, err := loadResource(writeTestFile(t, dir, "new.yaml", diffNewRuleset))
+	if err != nil {
+		t.Fatalf("loadResource() error = %v", err)
+	}
+
+	changes := diffResources(oldResource, newResource)
+
+	want := map[string]string{
+		"/metadata.name":              "modified",
+		"addedRule/":                  "added",
+		"removedRule/":                "removed",
+		"meaningfulNames/enforcement": "modified",
+		"meaningfulNames/scope":       "modified",
+		"meaningfulNames/body":        "modified",
+	}
+	if len(changes) != len(want) {
+		t.Errorf("got %d changes, want %d: %+v", len(changes), len(want), changes)
+	}
+	for _, c := range changes {
+		key := c.Item + "/" + c.Field
+		if want[key] != c.Change {
+			t.Errorf("unexpected change %+v", c)
+		}
+	}
+}
+
+func TestDiffResourcesIgnoresFragmentRefactoring(t *testing.T) {
+	dir := t.TempDir()
+	oldResource, err := loadResource(writeTestFile(t, dir, "old.yaml", diffOldRuleset))
+	if err != nil {
+		t.Fatalf("loadResource() error = %v", err)
+	}
+	inlined := strings.Replace(diffOldRuleset, `        - $naming
+`, `        - Use descriptive names.
+`, 1)
+	newResource, err := loadResource(writeTestFile(t, dir, "new.yaml", inlined))
+	if err != nil {
+		t.Fatalf("loadResource() error = %v", err)
+	}
+
+	if changes := diffResources(oldResource, newResource); len(changes) != 0 {
+		t.Errorf("inlining a fragment should not be a change, got %+v", changes)
+	}
+}
+
+func TestWriteDiffText(t *testing.T) {
+	changes := []resourceChange{
+		{Change: "added", Item: "addedRule"},
+		{Change: "modified", Item: "meaningfulNames", Field: "enforcement", Old: "should", New: "must"},
+		{Change: "modified", Item: "meaningfulNames", Field: "body", Old: "a\nb", New: "a\nc"},
+	}
+
+	var buf bytes.Buffer
+	writeDiffText(&buf, changes)
+	got := buf.String()
+
+	for _, want := range []string{
+		"+ addedRule",
+		`~ meaningfulNames: enforcement: "should" -> "must"`,
+		"~ meaningfulNames: body",
+		"    - b",
+		"    + c",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("text output missing %q:\n%s", want, got)
+		}
+	}
+}
+
+func TestWriteDiffJSON(t *testing.T) {
+	var buf bytes.Buffer
+	err := writeDiffJSON(&buf, "old.yaml", "new.yaml", []resourceChange{
+		{Change: "removed", Item: "removedRule"},
+	})
+	if err != nil {
+		t.Fatalf("writeDiffJSON() error = %v", err)
+	}
+
+	var report struct {
+		Old     string
+		New     string
+		Changes []resourceChange
+	}
+	if err := json.Unmarshal(buf.Bytes(), &report); err != nil {
+		t.Fatalf("output is not valid JSON: %v", err)
+	}
+	if report.Old != "old.yaml" || len(report.Changes) != 1 || report.Changes[0].Item != "removedRule" {
+		t.Errorf("unexpected report: %+v", report)
+	}
+}
+
+func TestDiffErrorArgumentCount(t *testing.T) {
+	err := runDiff([]string{"only-one.yaml"})
+	if err == nil {
+		t.Fatal("Expected error for wrong argument count, got nil")
+	}
+}
+
+func TestUnifiedDiff(t *testing.T) {
+	old := "a\nb\nc\nd\ne\nf\ng\nh\ni\nj\nk\nl\n"
+	content := "a\nB\nc\nd\ne\nf\ng\nh\ni\nj\nk\nl\nm"
+	got := strings.Join(unifiedDiff("old.md", "new.md", old, content), "\n")
+	want := `--- old.md
++++ new.md
+@@ -1,5 +1,5 @@
+ a
+-b
++B
+ c
+ d
+ e
+@@ -10,3 +10,4 @@
+ j
+ k
+ l
++m
+\ No newline at end of file`
+	if got != want {
+		t.Errorf("unifiedDiff() =\n%s\nwant\n%s", got, want)
+	}
+
+	if lines := unifiedDiff("a", "b", old, old); lines != nil {
+		t.Errorf("unifiedDiff() of equal text = %q, want nil", lines)
+	}
+}
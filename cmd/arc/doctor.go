@@ -0,0 +1,245 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// toolInstall describes where an AI tool keeps its configuration in a
+// repository.
+type toolInstall struct {
+	Target  string
+	Markers []string // paths whose presence shows the tool is in use
+	Dir     string   // directory compiled rules are installed into; see nativeDir
+	Manual  []string // hand-written instruction files the tool also loads
+}
+
+var toolInstalls = []toolInstall{
+	{Target: "cursor", Markers: []string{".cursor", ".cursorrules"}, Manual: []string{".cursorrules"}},
+	{Target: "claude", Markers: []string{".claude", "CLAUDE.md"}, Manual: []string{"CLAUDE.md"}},
+	{Target: "copilot", Markers: []string{".github/copilot-instructions.md", ".github/instructions", ".github/prompts"}, Manual: []string{".github/copilot-instructions.md"}},
+	{Target: "kiro", Markers: []string{".kiro"}},
+}
+
+// detectedTool is a tool found in the repository and the markers that
+// revealed it.
+type detectedTool struct {
+	toolInstall
+	Found []string
+}
+
+// doctorConflict is an existing file that arc would overwrite with
+// different content.
+type doctorConflict struct {
+	Target string
+	Path   string
+}
+
+func runDoctor(args []string) error {
+	fs := flag.NewFlagSet("doctor", flag.ContinueOnError)
+	configPath := fs.String("config", "", configFlagUsage)
+	profile := fs.String("profile", "", "Workspace config profile to check")
+
+	files, err := parseInterspersed(fs, args)
+	if err != nil {
+		return err
+	}
+
+	var settings buildSettings
+	var aliases map[string]targetAlias
+	path := findConfigFile(*configPath)
+	if path != "" {
+		ws, err := loadWorkspaceConfig(path)
+		if err != nil {
+			return err
+		}
+		if settings, err = ws.resolve(*profile); err != nil {
+			return err
+		}
+		if aliases, err = ws.targetAliases(); err != nil {
+			return err
+		}
+	}
+	if len(files) == 0 {
+		files = settings.Resources
+	}
+	if files, err = expandResources(files); err != nil {
+		return err
+	}
+
+	tools := detectTools(".")
+	for i := range tools {
+		tools[i].Dir = installDir(tools[i], aliases)
+		if output, ok := settings.Outputs[tools[i].Target]; ok {
+			tools[i].Dir = output
+		}
+	}
+	cfg := settings.buildConfig(nil)
+	conflicts, err := findConflicts(".", tools, files, cfg)
+	if err != nil {
+		return err
+	}
+
+	writeDoctorReport(os.Stdout, tools, aliases, len(files), conflicts)
+	if len(conflicts) > 0 {
+		return fmt.Errorf("%d file(s) would be overwritten", len(conflicts))
+	}
+	return nil
+}
+
+// detectTools returns the AI tools whose configuration exists under root.
+func detectTools(root string) []detectedTool {
+	var tools []detectedTool
+	for _, install := range toolInstalls {
+		tool := detectedTool{toolInstall: install}
+		tool.Dir = nativeDir(install.Target)
+		for _, marker := range install.Markers {
+			if _, err := os.Stat(filepath.Join(root, filepath.FromSlash(marker))); err == nil {
+				tool.Found = append(tool.Found, marker)
+			}
+		}
+		if len(tool.Found) > 0 {
+			tools = append(tools, tool)
+		}
+	}
+	return tools
+}
+
+// nativeDir returns the directory the native layout writes target to, such
+// as .cursor or .kiro/steering, relative to the project root.
+func nativeDir(target string) string {
+	t, err := parseTarget(target)
+	if err != nil {
+		return ""
+	}
+	dir, _ := newCompiler().DefaultOutputDir(t)
+	return dir
+}
+
+// installDir returns the directory a tool's compiled rules go to: the output
+// of an alias for its target, or the tool's default location.
+func installDir(tool detectedTool, aliases map[string]targetAlias) string {
+	names := make([]string, 0, len(aliases))
+	for name := range aliases {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if alias := aliases[name]; alias.Target == tool.Target && alias.Output != "" {
+			return alias.Output
+		}
+	}
+	return tool.Dir
+}
+
+// findConflicts compiles files for each detected tool and reports existing
+// files in the tool's install directory whose content differs from what arc
+// would write there.
+func findConflicts(root string, tools []detectedTool, files []string, cfg buildConfig) ([]doctorConflict, error) {
+	var conflicts []doctorConflict
+	cfg.Rulesets = buildRulesets(files, cfg)
+	for _, tool := range tools {
+		toolCfg := cfg
+		toolCfg.Targets = []string{tool.Target}
+		for _, file := range files {
+			allResults, err := compileTargets(file, toolCfg)
+			if err != nil {
+				return nil, &fileError{File: file, Err: err}
+			}
+			for _, tr := range allResults {
+				for _, result := range tr.results {
+					path, err := resultFilePath(tool.Dir, result.Path)
+					if err != nil {
+						return nil, err
+					}
+					if !filepath.IsAbs(path) {
+						path = filepath.Join(root, path)
+					}
+					existing, err := os.ReadFile(path)
+					if err != nil {
+						continue
+					}
+					if !bytes.Equal(existing, []byte(result.Content)) {
+						conflicts = append(conflicts, doctorConflict{Target: tool.Target, Path: path})
+					}
+				}
+			}
+		}
+	}
+	return conflicts, nil
+}
+
+// writeDoctorReport prints the detected tools, suggested aliases for tools
+// without one, and conflicts.
+func writeDoctorReport(w io.Writer, tools []detectedTool, aliases map[string]targetAlias, resources int, conflicts []doctorConflict) {
+	if len(tools) == 0 {
+		fmt.Fprintln(w, "No AI tool configurations found.")
+		var locations []string
+		for _, install := range toolInstalls {
+			locations = append(locations, fmt.Sprintf("%s (%s)", nativeDir(install.Target), install.Target))
+		}
+		fmt.Fprintf(w, "Recommended locations: %s\n", strings.Join(locations, ", "))
+		return
+	}
+
+	fmt.Fprintln(w, "Detected AI tools:")
+	for _, tool := range tools {
+		fmt.Fprintf(w, "  %-8s %s\n", tool.Target, strings.Join(tool.Found, ", "))
+	}
+
+	configured := make(map[string]bool)
+	for _, alias := range aliases {
+		if alias.Output != "" {
+			configured[alias.Target] = true
+		}
+	}
+	var suggest []detectedTool
+	for _, tool := range tools {
+		if !configured[tool.Target] {
+			suggest = append(suggest, tool)
+		}
+	}
+	if len(suggest) > 0 {
+		fmt.Fprintf(w, "\nSuggested aliases for %s:\n", defaultConfigFile)
+		fmt.Fprintln(w, "  aliases:")
+		for _, tool := range suggest {
+			fmt.Fprintf(w, "    %s-install:\n      target: %s\n      output: %s\n", tool.Target, tool.Target, tool.Dir)
+		}
+	}
+
+	var notes []string
+	for _, tool := range tools {
+		for _, manual := range tool.Manual {
+			for _, found := range tool.Found {
+				if found == manual {
+					notes = append(notes, fmt.Sprintf("%s is hand-written and loaded by %s alongside compiled rules", manual, tool.Target))
+				}
+			}
+		}
+	}
+	if len(notes) > 0 {
+		fmt.Fprintln(w, "\n"+colorize(w, colorYellow, "Notes:"))
+		for _, note := range notes {
+			fmt.Fprintf(w, "  %s\n", note)
+		}
+	}
+
+	if resources == 0 {
+		fmt.Fprintf(w, "\nNo resources to check for conflicts (pass files or set resources in %s).\n", defaultConfigFile)
+		return
+	}
+	if len(conflicts) == 0 {
+		fmt.Fprintln(w, "\n"+colorize(w, colorGreen, fmt.Sprintf("No conflicts: compiling %d resource file(s) would not overwrite existing files.", resources)))
+		return
+	}
+	fmt.Fprintln(w, "\n"+colorize(w, colorYellow, "Conflicts (existing files arc would overwrite):"))
+	for _, c := range conflicts {
+		fmt.Fprintf(w, "  %s (%s)\n", filepath.ToSlash(c.Path), c.Target)
+	}
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDetectTools(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, ".cursor", "rules"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(dir, ".github"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	writeTestFile(t, dir, ".cursorrules", "hand-written\n")
+	writeTestFile(t, dir, ".github/copilot-instructions.md", "hand-written\n")
+
+	tools := detectTools(dir)
+	if len(tools) != 2 {
+		t.Fatalf("detectTools() = %v, want cursor and copilot", tools)
+	}
+	if tools[0].Target != "cursor" || strings.Join(tools[0].Found, ",") != ".cursor,.cursorrules" {
+		t.Errorf("tools[0] = %s %v, want cursor [.cursor .cursorrules]", tools[0].Target, tools[0].Found)
+	}
+	if tools[1].Target != "copilot" {
+		t.Errorf("tools[1] = %s, want copilot", tools[1].Target)
+	}
+
+	aliases := map[string]targetAlias{"cursor-team": {Target: "cursor", Output: "team/rules"}}
+	if tools[0].Dir != ".cursor" {
+		t.Errorf("tools[0].Dir = %q, want .cursor", tools[0].Dir)
+	}
+	if got := installDir(tools[0], aliases); got != "team/rules" {
+		t.Errorf("installDir() = %q, want alias output", got)
+	}
+	if got := installDir(tools[1], aliases); got != ".github" {
+		t.Errorf("installDir() = %q, want .github", got)
+	}
+
+	var buf bytes.Buffer
+	writeDoctorReport(&buf, tools, aliases, 0, nil)
+	for _, want := range []string{
+		"cursor   .cursor, .cursorrules",
+		"copilot-install:\n      target: copilot\n      output: .github",
+		".cursorrules is hand-written and loaded by cursor",
+	} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("report missing %q:\n%s", want, buf.String())
+		}
+	}
+	if strings.Contains(buf.String(), "cursor-install") {
+		t.Errorf("report suggests an alias for cursor despite cursor-team:\n%s", buf.String())
+	}
+
+	buf.Reset()
+	writeDoctorReport(&buf, nil, nil, 0, nil)
+	if want := ".cursor (cursor), .claude (claude), .github (copilot), .kiro/steering (kiro)"; !strings.Contains(buf.String(), want) {
+		t.Errorf("report missing %q:\n%s", want, buf.String())
+	}
+}
+
+func TestFindConflicts(t *testing.T) {
+	dir := t.TempDir()
+	resourceFile := createTestResource(t, dir)
+	if err := os.MkdirAll(filepath.Join(dir, ".kiro", "steering"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	tools := detectTools(dir)
+
+	conflicts, err := findConflicts(dir, tools, []string{resourceFile}, buildConfig{})
+	if err != nil {
+		t.Fatalf("findConflicts() error = %v", err)
+	}
+	if len(conflicts) != 0 {
+		t.Errorf("findConflicts() = %v, want none before files exist", conflicts)
+	}
+
+	installed := writeTestFile(t, dir, ".kiro/steering/testRule.md", "hand-written steering\n")
+	conflicts, err = findConflicts(dir, tools, []string{resourceFile}, buildConfig{})
+	if err != nil {
+		t.Fatalf("findConflicts() error = %v", err)
+	}
+	if len(conflicts) != 1 || conflicts[0].Path != installed || conflicts[0].Target != "kiro" {
+		t.Errorf("findConflicts() = %v, want hand-written steering file", conflicts)
+	}
+
+	if err := runBuild([]string{"-target", "kiro", "-output", filepath.Join(dir, ".kiro", "steering"), "-flat", "-force", resourceFile}); err != nil {
+		t.Fatalf("runBuild() error = %v", err)
+	}
+	conflicts, err = findConflicts(dir, tools, []string{resourceFile}, buildConfig{})
+	if err != nil {
+		t.Fatalf("findConflicts() error = %v", err)
+	}
+	if len(conflicts) != 0 {
+		t.Errorf("findConflicts() = %v, want none once files match compiled output", conflicts)
+	}
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+)
+
+// plannedFile is a file a dry run would have written.
+type plannedFile struct {
+	Path   string
+	Target string
+	Size   int
+}
+
+// dryRun collects the files a compile would write instead of writing them.
+type dryRun struct {
+	files []plannedFile
+
+	// compare, if set, compares each planned file with the file on disk,
+	// for build -diff and -check. stale lists those that differ or are
+	// missing, and diff, if set, receives a unified diff of each.
+	compare bool
+	diff    io.Writer
+	stale   []string
+}
+
+// add records results as they would be written under outputDir, or printed
+// when outputDir is "stdout".
+func (d *dryRun) add(allResults []targetResults, outputDir string, flat bool) error {
+	for _, tr := range allResults {
+		for _, result := range tr.results {
+			path := tr.target + "/" + result.Path
+			if outputDir != "stdout" {
+				resultPath := result.Path
+				if !flat {
+					resultPath = path
+				}
+				var err error
+				if path, err = resultFilePath(outputDir, resultPath); err != nil {
+					return err
+				}
+			}
+			if d.compare {
+				if err := d.compareFile(outputDir, path, result.Content); err != nil {
+					return err
+				}
+			}
+			d.files = append(d.files, plannedFile{Path: filepath.ToSlash(path), Target: tr.target, Size: len(result.Content)})
+		}
+	}
+	return nil
+}
+
+// compareFile records the file at path as stale unless it holds content,
+// its SHA-256 matching, and writes their diff to d.diff, if set.
+func (d *dryRun) compareFile(outputDir, path, content string) error {
+	if outputDir == "stdout" || isArchive(outputDir) {
+		return fmt.Errorf("-diff and -check compare with files in an output directory, not %s", outputDir)
+	}
+	oldName := path
+	existing, err := os.ReadFile(path)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		oldName = "/dev/null"
+	case err != nil:
+		return fmt.Errorf("failed to read file %s: %w", path, err)
+	case hashBytes(existing) == hashBytes([]byte(content)):
+		return nil
+	}
+	d.stale = append(d.stale, filepath.ToSlash(path))
+	if d.diff == nil {
+		return nil
+	}
+	lines := unifiedDiff(oldName, path, string(existing), content)
+	if len(lines) == 0 {
+		// A new, empty file.
+		lines = []string{"--- " + oldName, "+++ " + path}
+	}
+	for _, line := range lines {
+		color := ""
+		switch {
+		case strings.HasPrefix(line, "+++"), strings.HasPrefix(line, "---"):
+		case strings.HasPrefix(line, "+"):
+			color = colorGreen
+		case strings.HasPrefix(line, "-"):
+			color = colorRed
+		}
+		if color != "" {
+			line = colorize(d.diff, color, line)
+		}
+		fmt.Fprintln(d.diff, line)
+	}
+	return nil
+}
+
+// checkStale returns an error counting the planned files that differ from
+// those on disk, if any, after listing them unless their diff was printed.
+func (d *dryRun) checkStale() error {
+	if len(d.stale) > 0 {
+		if d.diff == nil {
+			for _, path := range d.stale {
+				fmt.Printf("Out of date: %s\n", path)
+			}
+		}
+		return fmt.Errorf("%d of %d file(s) differ from the compiled output", len(d.stale), len(d.files))
+	}
+	fmt.Fprintf(os.Stderr, "%d file(s) up to date\n", len(d.files))
+	return nil
+}
+
+// write prints the planned files as a table followed by a total.
+func (d *dryRun) write(w io.Writer) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "PATH\tTARGET\tBYTES")
+	total := 0
+	for _, f := range d.files {
+		fmt.Fprintf(tw, "%s\t%s\t%d\n", f.Path, f.Target, f.Size)
+		total += f.Size
+	}
+	tw.Flush()
+	fmt.Fprintf(w, "%d file(s), %d bytes would be written (dry run)\n", len(d.files), total)
+}
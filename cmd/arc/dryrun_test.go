@@ -0,0 +1,106 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCompileDryRun(t *testing.T) {
+	dir := t.TempDir()
+	resourceFile := createTestResource(t, dir)
+	outputDir := filepath.Join(dir, "output")
+
+	plan := &dryRun{}
+	err := compile(resourceFile, buildConfig{Targets: []string{"cursor", "markdown"}, Output: outputDir, DryRun: plan})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if _, err := os.Stat(outputDir); !os.IsNotExist(err) {
+		t.Errorf("Dry run created the output directory: %v", err)
+	}
+
+	if len(plan.files) != 2 {
+		t.Fatalf("Planned %d files, want 2: %+v", len(plan.files), plan.files)
+	}
+	want := filepath.ToSlash(filepath.Join(outputDir, "cursor", "rules", "testRule.mdc"))
+	if f := plan.files[0]; f.Path != want || f.Target != "cursor" || f.Size == 0 {
+		t.Errorf("Planned file = %+v, want %s for cursor", f, want)
+	}
+
+	var buf bytes.Buffer
+	plan.write(&buf)
+	out := buf.String()
+	if !strings.HasPrefix(out, "PATH") || !strings.Contains(out, want) || !strings.Contains(out, "2 file(s)") {
+		t.Errorf("Dry run output:\n%s", out)
+	}
+}
+
+func TestCompileDryRunStdout(t *testing.T) {
+	dir := t.TempDir()
+	resourceFile := createTestResource(t, dir)
+
+	plan := &dryRun{}
+	if err := compile(resourceFile, buildConfig{Targets: []string{"markdown"}, Output: "stdout", DryRun: plan}); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if len(plan.files) != 1 || plan.files[0].Path != "markdown/testRule.md" {
+		t.Errorf("Planned files = %+v, want markdown/testRule.md", plan.files)
+	}
+}
+
+func TestBuildDiff(t *testing.T) {
+	dir := t.TempDir()
+	resourceFile := createTestResource(t, dir)
+	out := filepath.Join(dir, "out")
+	args := []string{"-target", "cursor", "-target", "markdown", "-output", out, resourceFile}
+	if err := runBuild(args); err != nil {
+		t.Fatalf("runBuild() error = %v", err)
+	}
+	if err := runBuild(append([]string{"-diff"}, args...)); err != nil {
+		t.Fatalf("runBuild(-diff) of current output error = %v", err)
+	}
+
+	edited := filepath.Join(out, "markdown", "testRule.md")
+	if err := os.WriteFile(edited, []byte("edited\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	var diff bytes.Buffer
+	d := dryRun{compare: true, diff: &diff}
+	if err := compile(resourceFile, buildConfig{Targets: []string{"cursor", "markdown"}, Output: out, DryRun: &d}); err != nil {
+		t.Fatalf("compile() error = %v", err)
+	}
+	if err := d.checkStale(); err == nil || !strings.Contains(err.Error(), "1 of 2") {
+		t.Errorf("checkStale() error = %v, want 1 of 2 files differing", err)
+	}
+	for _, want := range []string{"--- " + edited, "-edited", "+Test rule body"} {
+		if !strings.Contains(diff.String(), want) {
+			t.Errorf("diff missing %q:\n%s", want, diff.String())
+		}
+	}
+	if content, _ := os.ReadFile(edited); string(content) != "edited\n" {
+		t.Error("-diff rewrote the file")
+	}
+}
+
+func TestBuildCheck(t *testing.T) {
+	dir := t.TempDir()
+	resourceFile := createTestResource(t, dir)
+	out := filepath.Join(dir, "out")
+	args := []string{"-check", "-target", "cursor", "-target", "markdown", "-output", out, resourceFile}
+	if err := runBuild(args); err == nil || !strings.Contains(err.Error(), "2 of 2") {
+		t.Fatalf("runBuild(-check) before building error = %v, want 2 of 2 files differing", err)
+	}
+	if _, err := os.Stat(out); !os.IsNotExist(err) {
+		t.Error("-check wrote output")
+	}
+
+	if err := runBuild(args[1:]); err != nil {
+		t.Fatalf("runBuild() error = %v", err)
+	}
+	if err := runBuild(args); err != nil {
+		t.Errorf("runBuild(-check) of current output error = %v", err)
+	}
+}
@@ -0,0 +1,191 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/jomadu/ai-resource-compiler-go/pkg/compiler"
+	"github.com/jomadu/ai-resource-compiler-go/pkg/loader"
+	"gopkg.in/yaml.v3"
+)
+
+// errorFormat selects how errors are reported on stderr: "text" or "json";
+// set by the -error-format flag.
+var errorFormat = "text"
+
+// errorFormatFlag is the value of the -error-format flag, which sets
+// errorFormat.
+type errorFormatFlag struct{}
+
+func (errorFormatFlag) String() string {
+	return errorFormat
+}
+
+func (errorFormatFlag) Set(value string) error {
+	if value != "text" && value != "json" {
+		return fmt.Errorf("use text or json")
+	}
+	errorFormat = value
+	return nil
+}
+
+// fileError attributes an error to the resource file it occurred in.
+type fileError struct {
+	File string
+	Err  error
+}
+
+func (e *fileError) Error() string {
+	// A CompileError names the file itself.
+	var compileErr *compiler.CompileError
+	if errors.As(e.Err, &compileErr) && compileErr.File == e.File {
+		return e.Err.Error()
+	}
+	return e.File + ": " + e.Err.Error()
+}
+
+func (e *fileError) Unwrap() error {
+	return e.Err
+}
+
+// errorReport is the JSON form of an error. Line and Column are 1-based and
+// omitted when unknown.
+type errorReport struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	File    string `json:"file,omitempty"`
+	Line    int    `json:"line,omitempty"`
+	Column  int    `json:"column,omitempty"`
+	Field   string `json:"field,omitempty"`
+	Target  string `json:"target,omitempty"`
+}
+
+// fail reports err in the selected error format and exits. file names the
+// resource being processed when err does not carry one.
+func fail(err error, file string) {
+	reportError(os.Stderr, err, file)
+	os.Exit(1)
+}
+
+func reportError(w io.Writer, err error, file string) {
+	// Report each error of a list on its own line.
+	var list compiler.CompileErrors
+	if errors.As(err, &list) {
+		var fe *fileError
+		errors.As(err, &fe)
+		for _, e := range list {
+			var item error = e
+			if fe != nil {
+				item = &fileError{File: fe.File, Err: e}
+			}
+			reportError(w, item, file)
+		}
+		return
+	}
+	if errorFormat != "json" {
+		fmt.Fprintf(w, "%s %v\n", errorLabel(w), err)
+		return
+	}
+
+	report := errorReport{Code: errorCode(err), Message: err.Error(), File: file}
+	var fe *fileError
+	if errors.As(err, &fe) {
+		report.File = fe.File
+		report.Message = fe.Err.Error()
+	}
+	var compileErr *compiler.CompileError
+	if errors.As(err, &compileErr) {
+		report.Message = compileErr.Err.Error()
+		report.Field = compileErr.Field
+		report.Target = string(compileErr.Target)
+		if compileErr.File != "" {
+			report.File = compileErr.File
+		}
+	}
+	var unknownErr *loader.UnknownFieldError
+	if errors.As(err, &unknownErr) {
+		report.Field = unknownErr.Fields[0].Field
+	}
+	if report.File != "" {
+		report.Line, report.Column = errorPosition(err, report.File)
+	}
+	data, _ := json.Marshal(report)
+	fmt.Fprintln(w, string(data))
+}
+
+// errorCode classifies err for tools that act on the kind of failure.
+func errorCode(err error) string {
+	var validationErr *compiler.ValidationError
+	var unknownErr *loader.UnknownFieldError
+	var parseErr *loader.ParseError
+	switch {
+	case errors.Is(err, compiler.ErrUnknownTarget):
+		return "unknown_target"
+	case errors.Is(err, compiler.ErrUnsupportedVersion):
+		return "unsupported_version"
+	case errors.Is(err, compiler.ErrUnsupportedKind):
+		return "unsupported_kind"
+	case errors.Is(err, compiler.ErrOptionsNotSupported), errors.Is(err, compiler.ErrInvalidTargetOptions):
+		return "invalid_options"
+	case errors.Is(err, compiler.ErrUnknownItem):
+		return "unknown_item"
+	case errors.Is(err, compiler.ErrNoTargets):
+		return "no_targets"
+	case errors.Is(err, compiler.ErrLimitExceeded):
+		return "limit_exceeded"
+	case errors.Is(err, compiler.ErrPathCollision):
+		return "path_collision"
+	case errors.As(err, &validationErr):
+		return "validation"
+	case errors.As(err, &unknownErr):
+		return "unknown_field"
+	case errors.As(err, &parseErr):
+		return "parse"
+	default:
+		return "error"
+	}
+}
+
+// errorPosition returns where in file err occurred: the line reported by the
+// YAML parser or carried by a CompileError, or the position of the field or
+// value a validation error names. It returns zeros when the position is
+// unknown.
+func errorPosition(err error, file string) (line, column int) {
+	var parseErr *loader.ParseError
+	if errors.As(err, &parseErr) && parseErr.Line > 0 {
+		return parseErr.Line, parseErr.Column
+	}
+	var compileErr *compiler.CompileError
+	if errors.As(err, &compileErr) && compileErr.Line > 0 {
+		return compileErr.Line, compileErr.Column
+	}
+
+	resource := &compiler.Resource{Node: yamlRoot(file)}
+	var validationErr *compiler.ValidationError
+	switch {
+	case errors.As(err, &validationErr):
+		return resource.Position(validationErr.Field, validationErr.Value)
+	case errors.Is(err, compiler.ErrUnsupportedKind):
+		return resource.Position("kind", "")
+	case errors.Is(err, compiler.ErrUnsupportedVersion):
+		return resource.Position("apiVersion", "")
+	}
+	return 0, 0
+}
+
+// yamlRoot parses file as YAML, which JSON also is, and returns its root
+// node, or nil if it cannot be read or parsed.
+func yamlRoot(file string) *yaml.Node {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil
+	}
+	var doc yaml.Node
+	if yaml.Unmarshal(data, &doc) != nil || len(doc.Content) == 0 {
+		return nil
+	}
+	return doc.Content[0]
+}
@@ -0,0 +1,222 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestErrorFormatFlag(t *testing.T) {
+	t.Cleanup(func() { errorFormat = "text" })
+	parse := func(args ...string) ([]string, error) {
+		fs := flag.NewFlagSet("build", flag.ContinueOnError)
+		fs.SetOutput(io.Discard)
+		fs.String("profile", "", "")
+		return parseInterspersed(fs, args)
+	}
+
+	rest, err := parse("rule.yaml", "--error-format", "json", "-profile", "prod")
+	if err != nil || errorFormat != "json" || !reflect.DeepEqual(rest, []string{"rule.yaml"}) {
+		t.Errorf("parseInterspersed() = %v, %v with error format %q, want json", rest, err, errorFormat)
+	}
+	// Each command starts from text.
+	if _, err := parse("-error-format=text", "rule.yaml"); err != nil || errorFormat != "text" {
+		t.Errorf("parseInterspersed() error = %v with error format %q, want text", err, errorFormat)
+	}
+	// A flag value that happens to be -error-format is not the flag.
+	rest, err = parse("-profile", "-error-format", "json")
+	if err != nil || errorFormat != "text" || !reflect.DeepEqual(rest, []string{"json"}) {
+		t.Errorf("parseInterspersed() = %v, %v with error format %q, want -error-format taken as the profile", rest, err, errorFormat)
+	}
+
+	if _, err := parse("-error-format", "xml"); err == nil {
+		t.Error("parseInterspersed() accepted an invalid error format")
+	}
+	if _, err := parse("-error-format"); err == nil {
+		t.Error("parseInterspersed() accepted a missing error format")
+	}
+}
+
+func TestReportErrorJSON(t *testing.T) {
+	errorFormat = "json"
+	defer func() { errorFormat = "text" }()
+
+	dir := t.TempDir()
+	tests := []struct {
+		name    string
+		content string
+		want    errorReport
+	}{
+		{
+			name:    "syntax",
+			content: "apiVersion: ai-resource/draft\nkind: Rule\nmetadata: [\n",
+			want:    errorReport{Code: "parse", Line: 3},
+		},
+		{
+			name: "invalid id",
+			content: `apiVersion: ai-resource/draft
+kind: Ruleset
+metadata:
+  id: rules
+spec:
+  rules:
+    bad.id:
+      enforcement: must
+      body: Body
+`,
+			want: errorReport{Code: "validation", Line: 7, Column: 5, Field: "spec.rules"},
+		},
+		{
+			name: "empty scope",
+			content: `apiVersion: ai-resource/draft
+kind: Ruleset
+metadata:
+  id: rules
+spec:
+  rules:
+    style:
+      enforcement: must
+      scope:
+        - files: []
+      body: Body
+`,
+			want: errorReport{Code: "validation", Line: 10, Column: 9, Field: "spec.rules.style.scope"},
+		},
+		{
+			name:    "unsupported kind",
+			content: "apiVersion: ai-resource/draft\nkind: Widget\nmetadata:\n  id: w\n",
+			want:    errorReport{Code: "unsupported_kind", Line: 2, Column: 7},
+		},
+		{
+			name: "unknown field",
+			content: `apiVersion: ai-resource/draft
+kind: Rule
+metadata:
+  id: rule
+spec:
+  enforcment: must
+  body: Body
+`,
+			want: errorReport{Code: "unknown_field", Line: 6, Column: 3, Field: "spec.enforcment"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file := filepath.Join(dir, "resource.yaml")
+			if err := os.WriteFile(file, []byte(tt.content), 0644); err != nil {
+				t.Fatal(err)
+			}
+			err := compile(file, buildConfig{Targets: []string{"markdown"}, Output: "stdout"})
+			if err == nil {
+				t.Fatal("compile() succeeded, want error")
+			}
+
+			var buf bytes.Buffer
+			reportError(&buf, &fileError{File: file, Err: err}, "")
+			var got errorReport
+			if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+				t.Fatalf("output is not JSON: %v\n%s", err, buf.String())
+			}
+			if got.Message == "" {
+				t.Error("message is empty")
+			}
+			tt.want.File, tt.want.Message = file, got.Message
+			if got != tt.want {
+				t.Errorf("report = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReportErrorText(t *testing.T) {
+	var buf bytes.Buffer
+	reportError(&buf, &fileError{File: "rule.yaml", Err: os.ErrNotExist}, "")
+	if got, want := buf.String(), "Error: rule.yaml: file does not exist\n"; got != want {
+		t.Errorf("reportError() = %q, want %q", got, want)
+	}
+}
+
+func TestReportErrorCompileError(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "rules.yaml")
+	content := `apiVersion: ai-resource/draft
+kind: Ruleset
+metadata:
+  id: rules
+spec:
+  rules:
+    fooBar:
+      name: Foo (bar)
+      enforcement: must
+      body: Body
+`
+	if err := os.WriteFile(file, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	err := compile(file, buildConfig{Targets: []string{"markdown"}, Output: "stdout"})
+	if err == nil {
+		t.Fatal("compile() succeeded, want error")
+	}
+
+	var buf bytes.Buffer
+	reportError(&buf, &fileError{File: file, Err: err}, "")
+	want := "Error: " + file + ":8 spec.rules.fooBar.name: rule name cannot contain parentheses: 'Foo (bar)'\n"
+	if got := buf.String(); got != want {
+		t.Errorf("reportError() = %q, want %q", got, want)
+	}
+}
+
+func TestReportErrorCompileErrors(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "rules.yaml")
+	content := `apiVersion: ai-resource/draft
+kind: Ruleset
+metadata:
+  id: rules
+spec:
+  rules:
+    bad.id:
+      enforcement: must
+      body: Body
+    fooBar:
+      name: Foo (bar)
+      enforcement: must
+      body: Body
+`
+	if err := os.WriteFile(file, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	err := compile(file, buildConfig{Targets: []string{"markdown"}, Output: "stdout"})
+	if err == nil {
+		t.Fatal("compile() succeeded, want error")
+	}
+
+	var buf bytes.Buffer
+	reportError(&buf, &fileError{File: file, Err: err}, "")
+	want := "Error: " + file + ":7 spec.rules: ID contains invalid character '.' in 'bad.id'\n" +
+		"Error: " + file + ":11 spec.rules.fooBar.name: rule name cannot contain parentheses: 'Foo (bar)'\n"
+	if got := buf.String(); got != want {
+		t.Errorf("reportError() = %q, want %q", got, want)
+	}
+
+	errorFormat = "json"
+	defer func() { errorFormat = "text" }()
+	buf.Reset()
+	reportError(&buf, &fileError{File: file, Err: err}, "")
+	var lines []errorReport
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var report errorReport
+		if err := json.Unmarshal([]byte(line), &report); err != nil {
+			t.Fatalf("line is not JSON: %v\n%s", err, line)
+		}
+		lines = append(lines, report)
+	}
+	if len(lines) != 2 || lines[0].Line != 7 || lines[1].Field != "spec.rules.fooBar.name" {
+		t.Errorf("JSON reports = %+v, want one per problem", lines)
+	}
+}
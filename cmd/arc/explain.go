@@ -0,0 +1,96 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+
+	"github.com/jomadu/ai-resource-compiler-go/pkg/compiler"
+)
+
+func runExplain(args []string) error {
+	fs := flag.NewFlagSet("explain", flag.ContinueOnError)
+	lean := fs.Bool("lean", false, "Explain output with the metadata block omitted")
+	embedSource := fs.String("embed-source", "", "Explain output with each rule's source appended: path or yaml")
+	only := fs.String("only", "", "Explain only these comma-separated rule or prompt IDs of a collection")
+	exclude := fs.String("exclude", "", "Skip these comma-separated rule or prompt IDs of a collection")
+	configPath := fs.String("config", "", configFlagUsage)
+
+	positional, err := parseInterspersed(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 2 {
+		return fmt.Errorf("target and resource file required")
+	}
+	name, file := positional[0], positional[1]
+
+	var options map[string]any
+	path := findConfigFile(*configPath)
+	if path != "" {
+		ws, err := loadWorkspaceConfig(path)
+		if err != nil {
+			return err
+		}
+		aliases, err := ws.targetAliases()
+		if err != nil {
+			return err
+		}
+		settings, err := ws.resolve("")
+		if err != nil {
+			return err
+		}
+		configured, hasOptions := settings.Options[name]
+		if alias, ok := aliases[name]; ok {
+			name, options = alias.Target, alias.Options
+		}
+		if hasOptions {
+			options = mergeOptions(options, configured)
+		}
+	}
+	target, err := parseTarget(name)
+	if err != nil {
+		return err
+	}
+
+	resource, err := loadResource(file)
+	if err != nil {
+		return &fileError{File: file, Err: err}
+	}
+	opts := compiler.CompileOptions{
+		Lean:        *lean,
+		EmbedSource: *embedSource,
+		Only:        splitList(*only),
+		Exclude:     splitList(*exclude),
+	}
+	if options != nil {
+		opts.TargetOptions = map[compiler.Target]map[string]any{target: options}
+	}
+	if err := compiler.CheckItems([]*compiler.Resource{resource}, opts.Only, opts.Exclude); err != nil {
+		return err
+	}
+	explanations, err := newCompiler().Explain(resource, target, opts)
+	if err != nil {
+		return err
+	}
+
+	writeExplanations(os.Stdout, explanations)
+	return nil
+}
+
+// writeExplanations prints each output file followed by its field mappings.
+func writeExplanations(w io.Writer, explanations []compiler.Explanation) {
+	for i, e := range explanations {
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+		fmt.Fprintf(w, "%s (%s)\n", e.Path, e.Item)
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		for _, m := range e.Mappings {
+			fmt.Fprintf(tw, "  %s\t-> %s\n", m.Field, m.Output)
+		}
+		tw.Flush()
+	}
+}
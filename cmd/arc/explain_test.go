@@ -0,0 +1,41 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/jomadu/ai-resource-compiler-go/pkg/compiler"
+)
+
+func TestWriteExplanations(t *testing.T) {
+	var buf bytes.Buffer
+	writeExplanations(&buf, []compiler.Explanation{{
+		Item: "cleanCode/names",
+		Path: "cleanCode_names.mdc",
+		Mappings: []compiler.Mapping{
+			{Field: "enforcement: must", Output: "alwaysApply: true"},
+			{Field: "body", Output: "content below the heading"},
+		},
+	}})
+	got := buf.String()
+
+	for _, want := range []string{
+		"cleanCode_names.mdc (cleanCode/names)\n",
+		"  enforcement: must  -> alwaysApply: true\n",
+		"  body               -> content below the heading\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("output missing %q:\n%s", want, got)
+		}
+	}
+}
+
+func TestExplainErrors(t *testing.T) {
+	if err := runExplain([]string{"cursor"}); err == nil || !strings.Contains(err.Error(), "target and resource file required") {
+		t.Errorf("runExplain() error = %v, want argument error", err)
+	}
+	if err := runExplain([]string{"nope", "rule.yaml"}); err == nil || !strings.Contains(err.Error(), "unknown target") {
+		t.Errorf("runExplain() error = %v, want unknown target error", err)
+	}
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/jomadu/ai-resource-compiler-go/internal/format"
+	"github.com/jomadu/ai-resource-compiler-go/pkg/compiler"
+)
+
+// rulesetIndex holds the rules and rulesets of the resource files checked
+// or built together, so a ruleset's extends resolve against the others and
+// links between rules against all of them. The files are loaded when the
+// first ruleset with extends or resource with links needs them.
+type rulesetIndex struct {
+	files  []string
+	load   func(file string) (*compiler.Resource, error)
+	loaded bool
+	rules  map[string]*compiler.Resource // Rule and Ruleset resources by resource file
+	all    []*compiler.Resource          // every resource, in the order of files
+}
+
+func newRulesetIndex(files []string, load func(file string) (*compiler.Resource, error)) *rulesetIndex {
+	return &rulesetIndex{files: files, load: load}
+}
+
+// resolve returns resource, loaded from file, with its extends resolved
+// against the rulesets of the other files. Resources without extends, and
+// every resource given a nil index, are returned as they are.
+func (x *rulesetIndex) resolve(file string, resource *compiler.Resource) (*compiler.Resource, error) {
+	ruleset, ok := resource.Spec.(*format.Ruleset)
+	if x == nil || !ok || len(ruleset.Spec.Extends) == 0 {
+		return resource, nil
+	}
+	if err := x.loadAll("resolve extends"); err != nil {
+		return nil, err
+	}
+
+	batch := []*compiler.Resource{resource}
+	for _, f := range x.files {
+		if r, ok := x.rules[f]; ok && f != file && r.Kind == "Ruleset" {
+			batch = append(batch, r)
+		}
+	}
+	resolved, err := compiler.ResolveExtends(batch)
+	if err != nil {
+		return nil, err
+	}
+	return resolved[0], nil
+}
+
+// linked returns the rules and rulesets of the files other than file, their
+// extends resolved, for the links to rules of resource, loaded from file,
+// to resolve against. It returns nil for resources without such links and
+// for a nil index.
+func (x *rulesetIndex) linked(file string, resource *compiler.Resource) ([]*compiler.Resource, error) {
+	if x == nil || len(compiler.LinkedRules(resource)) == 0 {
+		return nil, nil
+	}
+	if err := x.loadAll("resolve links"); err != nil {
+		return nil, err
+	}
+	var resources []*compiler.Resource
+	for _, f := range x.files {
+		r, ok := x.rules[f]
+		if !ok || f == file {
+			continue
+		}
+		r, err := x.resolve(f, r)
+		if err != nil {
+			return nil, err
+		}
+		resources = append(resources, r)
+	}
+	return resources, nil
+}
+
+// checkItems returns an error for the IDs of only and exclude that no
+// resource of the files has; see compiler.CheckItems.
+func (x *rulesetIndex) checkItems(only, exclude []string) error {
+	if x == nil || len(only) == 0 && len(exclude) == 0 {
+		return nil
+	}
+	if err := x.loadAll("check -only and -exclude"); err != nil {
+		return err
+	}
+	return compiler.CheckItems(x.all, only, exclude)
+}
+
+// loadAll loads the files of the index once, keeping their rules and
+// rulesets; purpose says what for in errors.
+func (x *rulesetIndex) loadAll(purpose string) error {
+	if x.loaded {
+		return nil
+	}
+	x.rules = make(map[string]*compiler.Resource)
+	for _, f := range x.files {
+		r, err := x.load(f)
+		if err != nil {
+			return fmt.Errorf("failed to load %s to %s: %w", f, purpose, err)
+		}
+		x.all = append(x.all, r)
+		switch r.Spec.(type) {
+		case *format.Rule, *format.Ruleset:
+			x.rules[f] = r
+		}
+	}
+	x.loaded = true
+	return nil
+}
+
+// baseFiles returns the files the bases of resource, and theirs in turn,
+// were read from: resource files and the files they include.
+func baseFiles(resource *compiler.Resource) []string {
+	var files []string
+	for _, base := range resource.Bases {
+		files = append(files, base.Source)
+		files = append(files, base.IncludedFiles...)
+		files = append(files, baseFiles(base)...)
+	}
+	return files
+}
+
+// buildRulesets returns the index of the rulesets of files, loaded with the
+// overlays of cfg.
+func buildRulesets(files []string, cfg buildConfig) *rulesetIndex {
+	return newRulesetIndex(files, func(file string) (*compiler.Resource, error) {
+		return loadWithOverlays(file, cfg)
+	})
+}
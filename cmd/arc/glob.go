@@ -0,0 +1,112 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// expandResources expands glob patterns into a de-duplicated list of files,
+// in the order the patterns are given and lexical order within each. Patterns
+// without glob characters, and https:// URLs, are passed through so missing
+// files are reported when they are read; a glob that matches nothing is an
+// error.
+func expandResources(patterns []string) ([]string, error) {
+	var files []string
+	seen := make(map[string]bool)
+	for _, pattern := range patterns {
+		matches := []string{pattern}
+		if strings.ContainsAny(pattern, "*?[") && !isRemote(pattern) {
+			var err error
+			if matches, err = globFiles(pattern); err != nil {
+				return nil, fmt.Errorf("invalid resource pattern %q: %w", pattern, err)
+			}
+			if len(matches) == 0 {
+				return nil, fmt.Errorf("no resource files match %q", pattern)
+			}
+		}
+		for _, m := range matches {
+			if !seen[m] {
+				seen[m] = true
+				files = append(files, m)
+			}
+		}
+	}
+	return files, nil
+}
+
+// globFiles returns the files matching pattern in lexical order. Besides the
+// filepath.Match syntax, a "**" path element matches any number of
+// directories, including none, so "rules/**/*.yaml" selects a whole tree.
+func globFiles(pattern string) ([]string, error) {
+	elems := strings.Split(filepath.ToSlash(pattern), "/")
+	recursive := false
+	for _, elem := range elems {
+		if elem == "**" {
+			recursive = true
+		} else if _, err := path.Match(elem, ""); err != nil {
+			return nil, err
+		}
+	}
+	if !recursive {
+		return filepath.Glob(pattern)
+	}
+
+	// Walk from the longest leading directory without glob characters.
+	i := 0
+	for i < len(elems)-1 && !strings.ContainsAny(elems[i], "*?[") {
+		i++
+	}
+	root := filepath.FromSlash(strings.Join(elems[:i], "/"))
+	if root == "" && i > 0 {
+		root = string(filepath.Separator)
+	} else if root == "" {
+		root = "."
+	}
+
+	var matches []string
+	err := filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if p == root && errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(root, p)
+		if err != nil {
+			return err
+		}
+		if matchElems(elems[i:], strings.Split(filepath.ToSlash(rel), "/")) {
+			matches = append(matches, p)
+		}
+		return nil
+	})
+	return matches, err
+}
+
+// matchElems reports whether the path elements name match the pattern
+// elements, where "**" matches zero or more elements.
+func matchElems(pattern, name []string) bool {
+	if len(pattern) == 0 {
+		return len(name) == 0
+	}
+	if pattern[0] == "**" {
+		for i := 0; i <= len(name); i++ {
+			if matchElems(pattern[1:], name[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(name) == 0 {
+		return false
+	}
+	ok, _ := path.Match(pattern[0], name[0])
+	return ok && matchElems(pattern[1:], name[1:])
+}
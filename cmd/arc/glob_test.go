@@ -0,0 +1,81 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestExpandResources(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"b.yaml", "a.yaml", "notes.md", "team/c.yaml", "team/deep/d.yaml"} {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	join := func(names ...string) []string {
+		var paths []string
+		for _, name := range names {
+			paths = append(paths, filepath.Join(dir, filepath.FromSlash(name)))
+		}
+		return paths
+	}
+
+	tests := []struct {
+		name     string
+		patterns []string
+		want     []string
+	}{
+		{"single level", join("*.yaml"), join("a.yaml", "b.yaml")},
+		{"recursive", join("**/*.yaml"), join("a.yaml", "b.yaml", "team/c.yaml", "team/deep/d.yaml")},
+		{"recursive below a directory", join("team/**/*.yaml"), join("team/c.yaml", "team/deep/d.yaml")},
+		{"duplicates", join("b.yaml", "*.yaml"), join("b.yaml", "a.yaml")},
+		{"plain path", join("missing.yaml"), join("missing.yaml")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := expandResources(tt.patterns)
+			if err != nil {
+				t.Fatalf("expandResources() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("expandResources() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	for _, pattern := range []string{"*.json", "**/*.json", "missing/**/*.yaml"} {
+		_, err := expandResources(join(pattern))
+		if err == nil || !strings.Contains(err.Error(), "no resource files match") {
+			t.Errorf("expandResources(%q) error = %v, want no match error", pattern, err)
+		}
+	}
+	if _, err := expandResources(join("**/[.yaml")); err == nil {
+		t.Error("expandResources() accepted a malformed pattern")
+	}
+}
+
+func TestMatchElems(t *testing.T) {
+	tests := []struct {
+		pattern, name string
+		want          bool
+	}{
+		{"**/*.yaml", "a.yaml", true},
+		{"**/*.yaml", "x/y/a.yaml", true},
+		{"x/**/a.yaml", "x/a.yaml", true},
+		{"x/**/a.yaml", "y/a.yaml", false},
+		{"**", "x/y", true},
+		{"*.yaml", "x/a.yaml", false},
+	}
+	for _, tt := range tests {
+		if got := matchElems(strings.Split(tt.pattern, "/"), strings.Split(tt.name, "/")); got != tt.want {
+			t.Errorf("matchElems(%q, %q) = %v, want %v", tt.pattern, tt.name, got, tt.want)
+		}
+	}
+}
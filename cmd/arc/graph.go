@@ -0,0 +1,217 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/jomadu/ai-resource-compiler-go/internal/format"
+	"github.com/jomadu/ai-resource-compiler-go/pkg/compiler"
+)
+
+// graphNode is a resource, rule or prompt, fragment library, or fragment.
+type graphNode struct {
+	ID      string `json:"id"`
+	Kind    string `json:"kind"` // resource, item, library, or fragment
+	Label   string `json:"label"`
+	Missing bool   `json:"missing,omitempty"` // referenced but never defined
+}
+
+// graphEdge relates two nodes: a resource contains items and includes
+// libraries, resources and libraries define fragments, and rules and
+// prompts reference fragments.
+type graphEdge struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+	Kind string `json:"kind"`
+}
+
+// resourceGraph is the composition of a set of resource files. Libraries
+// included by several files appear once, so shared fragments are visible.
+type resourceGraph struct {
+	Nodes []graphNode `json:"nodes"`
+	Edges []graphEdge `json:"edges"`
+
+	seen map[string]bool
+}
+
+func runGraph(args []string) error {
+	fs := flag.NewFlagSet("graph", flag.ContinueOnError)
+	outputFormat := fs.String("format", "dot", "Output format: dot or json")
+
+	files, err := parseInterspersed(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(files) == 0 {
+		return fmt.Errorf("resource file required")
+	}
+	if files, err = expandResources(files); err != nil {
+		return err
+	}
+	if *outputFormat != "dot" && *outputFormat != "json" {
+		return fmt.Errorf("unknown format: %s (valid formats: dot, json)", *outputFormat)
+	}
+
+	g := &resourceGraph{seen: make(map[string]bool)}
+	for _, file := range files {
+		resource, err := loadResource(file)
+		if err != nil {
+			return &fileError{File: file, Err: err}
+		}
+		g.addResource(file, resource)
+	}
+
+	if *outputFormat == "json" {
+		return writeGraphJSON(os.Stdout, g)
+	}
+	writeGraphDOT(os.Stdout, g)
+	return nil
+}
+
+// addResource adds a resource file, its items, includes, and fragments.
+func (g *resourceGraph) addResource(file string, resource *compiler.Resource) {
+	id := filepath.ToSlash(file)
+	g.addNode(graphNode{ID: id, Kind: "resource", Label: resource.Kind + " " + format.BuildNamespacedPath(resource.Metadata.Namespace, resource.Metadata.ID)})
+
+	libraries := make(map[string]string, len(resource.Includes))
+	for _, include := range resource.Includes {
+		library := include
+		if !strings.Contains(include, "://") {
+			library = filepath.ToSlash(filepath.Join(filepath.Dir(file), include))
+		}
+		libraries[include] = library
+		g.addNode(graphNode{ID: library, Kind: "library", Label: library})
+		g.addEdge(id, library, "includes")
+	}
+
+	// fragmentID returns the node of a fragment, named by the library that
+	// defined it or, for the resource's own fragments, by the resource.
+	fragments := fragmentsOf(resource)
+	fragmentID := func(name string) string {
+		if include, ok := resource.IncludedFragments[name]; ok {
+			return libraries[include] + "$" + name
+		}
+		return id + "$" + name
+	}
+	for _, name := range format.SortedKeys(fragments) {
+		owner := id
+		if include, ok := resource.IncludedFragments[name]; ok {
+			owner = libraries[include]
+		}
+		g.addNode(graphNode{ID: fragmentID(name), Kind: "fragment", Label: "$" + name})
+		g.addEdge(owner, fragmentID(name), "defines")
+	}
+
+	references := func(from string, body format.Body) {
+		for _, part := range body.Array {
+			name, ok := strings.CutPrefix(part, "$")
+			if !ok {
+				continue
+			}
+			if _, defined := fragments[name]; !defined {
+				g.addNode(graphNode{ID: fragmentID(name), Kind: "fragment", Label: "$" + name, Missing: true})
+			}
+			g.addEdge(from, fragmentID(name), "references")
+		}
+	}
+	item := func(itemID, label string, body format.Body) {
+		node := id + "#" + itemID
+		g.addNode(graphNode{ID: node, Kind: "item", Label: label})
+		g.addEdge(id, node, "contains")
+		references(node, body)
+	}
+
+	switch spec := resource.Spec.(type) {
+	case *format.Rule:
+		references(id, spec.Spec.Body)
+	case *format.Ruleset:
+		for _, ruleID := range spec.Spec.RuleIDs() {
+			item(ruleID, "rule "+ruleID, spec.Spec.Rules[ruleID].Body)
+		}
+	case *format.Prompt:
+		references(id, spec.Spec.Body)
+	case *format.Command:
+		references(id, spec.Spec.Body)
+	case *format.Context:
+		references(id, spec.Spec.Body)
+	case *format.Promptset:
+		for _, promptID := range spec.Spec.PromptIDs() {
+			item(promptID, "prompt "+promptID, spec.Spec.Prompts[promptID].Body)
+		}
+	}
+}
+
+// fragmentsOf returns the fragments of a resource, including those merged
+// from libraries.
+func fragmentsOf(resource *compiler.Resource) map[string]string {
+	switch spec := resource.Spec.(type) {
+	case *format.Rule:
+		return spec.Spec.Fragments
+	case *format.Ruleset:
+		return spec.Spec.Fragments
+	case *format.Prompt:
+		return spec.Spec.Fragments
+	case *format.Promptset:
+		return spec.Spec.Fragments
+	case *format.Command:
+		return spec.Spec.Fragments
+	case *format.Context:
+		return spec.Spec.Fragments
+	}
+	return nil
+}
+
+// addNode adds node unless a node with its ID exists.
+func (g *resourceGraph) addNode(node graphNode) {
+	if g.seen[node.ID] {
+		return
+	}
+	g.seen[node.ID] = true
+	g.Nodes = append(g.Nodes, node)
+}
+
+// addEdge adds an edge unless the same edge exists.
+func (g *resourceGraph) addEdge(from, to, kind string) {
+	key := from + "\x00" + to + "\x00" + kind
+	if g.seen[key] {
+		return
+	}
+	g.seen[key] = true
+	g.Edges = append(g.Edges, graphEdge{From: from, To: to, Kind: kind})
+}
+
+func writeGraphJSON(w io.Writer, g *resourceGraph) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(g)
+}
+
+// graphShapes gives each node kind a distinct DOT shape.
+var graphShapes = map[string]string{
+	"resource": "box",
+	"item":     "ellipse",
+	"library":  "folder",
+	"fragment": "note",
+}
+
+func writeGraphDOT(w io.Writer, g *resourceGraph) {
+	fmt.Fprintln(w, "digraph arc {")
+	fmt.Fprintln(w, "  rankdir=LR;")
+	for _, node := range g.Nodes {
+		attrs := fmt.Sprintf("label=%s, shape=%s", strconv.Quote(node.Label), graphShapes[node.Kind])
+		if node.Missing {
+			attrs += ", style=dashed, color=red"
+		}
+		fmt.Fprintf(w, "  %s [%s];\n", strconv.Quote(node.ID), attrs)
+	}
+	for _, edge := range g.Edges {
+		fmt.Fprintf(w, "  %s -> %s [label=%s];\n", strconv.Quote(edge.From), strconv.Quote(edge.To), strconv.Quote(edge.Kind))
+	}
+	fmt.Fprintln(w, "}")
+}
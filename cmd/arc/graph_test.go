@@ -0,0 +1,114 @@
+package main
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestResourceGraph(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, dir, "common.yaml", "header: Follow the team conventions.\n")
+	ruleset := writeTestFile(t, dir, "ruleset.yaml", `apiVersion: ai-resource/draft
+kind: Ruleset
+include:
+  - common.yaml
+metadata:
+  id: goStyle
+spec:
+  fragments:
+    local: Local fragment.
+  rules:
+    naming:
+      enforcement: must
+      body:
+        - $header
+        - $local
+        - $missing
+`)
+	prompt := writeTestFile(t, dir, "prompt.yaml", `apiVersion: ai-resource/draft
+kind: Prompt
+include:
+  - common.yaml
+metadata:
+  id: review
+spec:
+  body:
+    - $header
+`)
+
+	g := &resourceGraph{seen: make(map[string]bool)}
+	for _, file := range []string{ruleset, prompt} {
+		resource, err := loadResource(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		g.addResource(file, resource)
+	}
+
+	rs, pr := filepath.ToSlash(ruleset), filepath.ToSlash(prompt)
+	lib := filepath.ToSlash(filepath.Join(dir, "common.yaml"))
+	want := []graphEdge{
+		{From: rs, To: lib, Kind: "includes"},
+		{From: lib, To: lib + "$header", Kind: "defines"},
+		{From: rs, To: rs + "$local", Kind: "defines"},
+		{From: rs, To: rs + "#naming", Kind: "contains"},
+		{From: rs + "#naming", To: lib + "$header", Kind: "references"},
+		{From: rs + "#naming", To: rs + "$local", Kind: "references"},
+		{From: rs + "#naming", To: rs + "$missing", Kind: "references"},
+		{From: pr, To: lib, Kind: "includes"},
+		{From: pr, To: lib + "$header", Kind: "references"},
+	}
+	if len(g.Edges) != len(want) {
+		t.Fatalf("edges = %+v, want %+v", g.Edges, want)
+	}
+	for i := range want {
+		if g.Edges[i] != want[i] {
+			t.Errorf("edge %d = %+v, want %+v", i, g.Edges[i], want[i])
+		}
+	}
+
+	libraries := 0
+	for _, node := range g.Nodes {
+		if node.Kind == "library" {
+			libraries++
+		}
+		if node.Missing != (node.ID == rs+"$missing") {
+			t.Errorf("node %s missing = %v", node.ID, node.Missing)
+		}
+	}
+	if libraries != 1 {
+		t.Errorf("shared library appears %d times, want once", libraries)
+	}
+}
+
+func TestWriteGraphDOT(t *testing.T) {
+	g := &resourceGraph{seen: make(map[string]bool)}
+	g.addNode(graphNode{ID: "rules.yaml", Kind: "resource", Label: "Ruleset style"})
+	g.addNode(graphNode{ID: "rules.yaml$gone", Kind: "fragment", Label: "$gone", Missing: true})
+	g.addEdge("rules.yaml", "rules.yaml$gone", "references")
+
+	var buf bytes.Buffer
+	writeGraphDOT(&buf, g)
+	got := buf.String()
+	for _, want := range []string{
+		"digraph arc {\n",
+		`  "rules.yaml" [label="Ruleset style", shape=box];`,
+		`  "rules.yaml$gone" [label="$gone", shape=note, style=dashed, color=red];`,
+		`  "rules.yaml" -> "rules.yaml$gone" [label="references"];`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("output missing %q:\n%s", want, got)
+		}
+	}
+}
+
+func TestGraphErrors(t *testing.T) {
+	if err := runGraph(nil); err == nil || !strings.Contains(err.Error(), "resource file required") {
+		t.Errorf("runGraph() error = %v, want argument error", err)
+	}
+	if err := runGraph([]string{"-format", "svg", "rule.yaml"}); err == nil || !strings.Contains(err.Error(), "unknown format") {
+		t.Errorf("runGraph() error = %v, want format error", err)
+	}
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/jomadu/ai-resource-compiler-go/internal/format"
+	"github.com/jomadu/ai-resource-compiler-go/pkg/compiler"
+)
+
+func runImport(args []string) error {
+	flags := flag.NewFlagSet("import", flag.ContinueOnError)
+	from := flags.String("from", "", "Target whose files to import: cursor, copilot, kiro, or claude")
+	id := flags.String("id", "", "ID of the imported ruleset (default: <target>Rules)")
+	output := flags.String("o", "", "Output file (default: stdout)")
+
+	paths, err := parseInterspersed(flags, args)
+	if err != nil {
+		return err
+	}
+	resource, err := importResource(*from, *id, paths)
+	if err != nil {
+		return err
+	}
+	data, err := encodeResource(resource)
+	if err != nil {
+		return err
+	}
+
+	if *output == "" {
+		_, err := os.Stdout.Write(data)
+		return err
+	}
+	if _, err := os.Stat(*output); err == nil {
+		return fmt.Errorf("%s already exists", *output)
+	}
+	if err := os.WriteFile(*output, data, 0644); err != nil {
+		return fmt.Errorf("failed to write file %s: %w", *output, err)
+	}
+	printWrote(*output)
+	return nil
+}
+
+// importResource reads the files of target from, under paths, into a
+// Ruleset with the given ID, or "<target>Rules" if id is empty.
+func importResource(from, id string, paths []string) (*compiler.Resource, error) {
+	if from == "" {
+		return nil, fmt.Errorf("-from required: the target whose files to import")
+	}
+	target, err := parseTarget(from)
+	if err != nil {
+		return nil, err
+	}
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("directory or file to import required, e.g. .cursor/rules")
+	}
+	if id == "" {
+		id = string(target) + "Rules"
+	}
+	if err := format.ValidateID(id); err != nil {
+		return nil, fmt.Errorf("-id: %w", err)
+	}
+
+	files, err := readImportFiles(paths)
+	if err != nil {
+		return nil, err
+	}
+	return newCompiler().Import(target, id, files)
+}
+
+// readImportFiles reads the files at paths, and those under the
+// directories among them, keyed by slash-separated path relative to the
+// directory given or, for files given themselves, by file name.
+func readImportFiles(paths []string) (map[string][]byte, error) {
+	files := make(map[string][]byte)
+	add := func(key, file string) error {
+		if _, dup := files[key]; dup {
+			return fmt.Errorf("%s is imported twice", key)
+		}
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", file, err)
+		}
+		files[key] = data
+		return nil
+	}
+
+	for _, p := range paths {
+		info, err := os.Stat(p)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			if err := add(filepath.Base(p), p); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		err = filepath.WalkDir(p, func(file string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() {
+				if file != p && strings.HasPrefix(d.Name(), ".") {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			rel, err := filepath.Rel(p, file)
+			if err != nil {
+				return err
+			}
+			return add(filepath.ToSlash(rel), file)
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return files, nil
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jomadu/ai-resource-compiler-go/internal/format"
+)
+
+func TestImportWritesRuleset(t *testing.T) {
+	dir := t.TempDir()
+	rules := filepath.Join(dir, ".cursor", "rules")
+	if err := os.MkdirAll(filepath.Join(rules, ".drafts"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	writeTestFile(t, rules, "go-style.mdc", "---\nglobs: **/*.go\n---\nUse gofmt.\n")
+	writeTestFile(t, rules, "concise.mdc", "---\nalwaysApply: true\n---\nBe concise.\n")
+	writeTestFile(t, filepath.Join(rules, ".drafts"), "draft.mdc", "---\nalwaysApply: true\n---\nNot yet.\n")
+	out := filepath.Join(dir, "rules.yaml")
+
+	if err := runImport([]string{"-from", "cursor", rules, "-id", "team", "-o", out}); err != nil {
+		t.Fatalf("runImport() error = %v", err)
+	}
+
+	resource, err := loadResource(out)
+	if err != nil {
+		t.Fatalf("loadResource() error = %v", err)
+	}
+	ruleset := resource.Spec.(*format.Ruleset)
+	if ruleset.Metadata.ID != "team" {
+		t.Errorf("ID = %s, want team", ruleset.Metadata.ID)
+	}
+	if got := strings.Join(ruleset.Spec.RuleIDs(), ","); got != "concise,go-style" {
+		t.Errorf("rules = %s, want concise,go-style", got)
+	}
+	if rule := ruleset.Spec.Rules["go-style"]; rule.Enforcement != "should" || len(rule.Scope) != 1 {
+		t.Errorf("go-style = %+v, want a scoped should rule", rule)
+	}
+
+	if err := runImport([]string{"-from", "cursor", rules, "-o", out}); err == nil || !strings.Contains(err.Error(), "already exists") {
+		t.Errorf("runImport() error = %v, want already exists error", err)
+	}
+}
+
+func TestImportErrors(t *testing.T) {
+	dir := t.TempDir()
+	if err := runImport([]string{dir}); err == nil || !strings.Contains(err.Error(), "-from required") {
+		t.Errorf("runImport() error = %v, want -from error", err)
+	}
+	if err := runImport([]string{"-from", "cursor"}); err == nil || !strings.Contains(err.Error(), "directory or file to import required") {
+		t.Errorf("runImport() error = %v, want argument error", err)
+	}
+	if err := runImport([]string{"-from", "cursor", dir}); err == nil || !strings.Contains(err.Error(), "no cursor rule files") {
+		t.Errorf("runImport() error = %v, want no rule files error", err)
+	}
+	if err := runImport([]string{"-from", "markdown", dir}); err == nil || !strings.Contains(err.Error(), "cannot import") {
+		t.Errorf("runImport() error = %v, want not importable error", err)
+	}
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// linker, for -link, writes each distinct content once: a result whose
+// content the build already wrote to another file becomes a link to that
+// file, its canonical copy, so targets whose files are identical, such as
+// markdown, kiro, and claude rules often are, share one. Where links cannot
+// be made, it writes copies instead.
+type linker struct {
+	mode      string            // "symlink" or "hardlink"
+	canonical map[string]string // absolute path of the first file with each content, by SHA-256
+	failed    bool              // a link failed, so copies are written
+}
+
+// newLinker returns the linker of mode, or nil for "", which writes copies.
+func newLinker(mode string) (*linker, error) {
+	switch mode {
+	case "":
+		return nil, nil
+	case "symlink", "hardlink":
+		return &linker{mode: mode, canonical: make(map[string]string)}, nil
+	}
+	return nil, fmt.Errorf("unknown link mode: %s (valid modes: symlink, hardlink)", mode)
+}
+
+// write writes content to resultPath within dir, as writeResultFile does,
+// or links it to the canonical copy of content, and returns the file's path
+// and whether it changed. A nil linker always writes.
+func (l *linker) write(dir, resultPath, content string, guard *overwriteGuard, summary *buildSummary) (string, bool, error) {
+	if l == nil || l.failed {
+		return writeResultFile(dir, resultPath, content, guard)
+	}
+	sum := sha256.Sum256([]byte(content))
+	key := hex.EncodeToString(sum[:])
+	filePath, err := resultFilePath(dir, resultPath)
+	if err != nil {
+		return "", false, err
+	}
+	file, err := filepath.Abs(filePath)
+	if err != nil {
+		return "", false, err
+	}
+	canonical, ok := l.canonical[key]
+	if !ok || canonical == file {
+		l.canonical[key] = file
+		return writeResultFile(dir, resultPath, content, guard)
+	}
+	if l.linked(file, canonical) {
+		return filePath, false, nil
+	}
+
+	// Writing the copy first creates the file's directories within dir and
+	// checks guard lets an existing file be replaced.
+	if _, _, err := writeResultFile(dir, resultPath, content, guard); err != nil {
+		return "", false, err
+	}
+	if err := os.Remove(file); err != nil {
+		return "", false, fmt.Errorf("failed to replace %s with a link: %w", filePath, err)
+	}
+	if err := l.link(file, canonical); err != nil {
+		l.failed = true
+		summary.warn("cannot %s %s to %s (%v); writing copies instead", l.mode, filePath, canonical, err)
+		return writeResultFile(dir, resultPath, content, guard)
+	}
+	return filePath, true, nil
+}
+
+// link makes file a link to canonical; symlinks are relative, so they keep
+// working when the output is moved or checked out elsewhere.
+func (l *linker) link(file, canonical string) error {
+	if l.mode == "hardlink" {
+		return os.Link(canonical, file)
+	}
+	target, err := filepath.Rel(filepath.Dir(file), canonical)
+	if err != nil {
+		target = canonical
+	}
+	return os.Symlink(target, file)
+}
+
+// linked reports whether file already is the link to canonical that link
+// would make.
+func (l *linker) linked(file, canonical string) bool {
+	if l.mode == "hardlink" {
+		fileInfo, err := os.Lstat(file)
+		if err != nil {
+			return false
+		}
+		canonicalInfo, err := os.Stat(canonical)
+		return err == nil && os.SameFile(fileInfo, canonicalInfo)
+	}
+	target, err := os.Readlink(file)
+	if err != nil {
+		return false
+	}
+	want, err := filepath.Rel(filepath.Dir(file), canonical)
+	return err == nil && target == want
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestBuildLink(t *testing.T) {
+	dir := t.TempDir()
+	rule := writeTestFile(t, dir, "rule.yaml", "apiVersion: ai-resource/draft\nkind: Rule\nmetadata:\n  id: naming\nspec:\n  enforcement: must\n  body: Name things well.\n")
+	outputDir := filepath.Join(dir, "out")
+	canonical := filepath.Join(outputDir, "markdown", "naming.md")
+	linked := filepath.Join(outputDir, "claude", "naming.md")
+
+	args := []string{"-target", "markdown", "-target", "claude", "-target", "cursor", "-output", outputDir, rule}
+	if err := runBuild(append([]string{"-link", "symlink"}, args...)); err != nil {
+		t.Fatalf("runBuild() error = %v", err)
+	}
+	if info, err := os.Lstat(canonical); err != nil || !info.Mode().IsRegular() {
+		t.Fatalf("markdown/naming.md = %v, %v, want a file", info, err)
+	}
+	target, err := os.Readlink(linked)
+	if err != nil {
+		t.Skipf("claude/naming.md is not a symlink, as where symlinks are not supported: %v", err)
+	}
+	if want := filepath.Join("..", "markdown", "naming.md"); target != want {
+		t.Errorf("claude/naming.md links to %s, want %s", target, want)
+	}
+	if info, err := os.Lstat(filepath.Join(outputDir, "cursor", "naming.mdc")); err != nil || !info.Mode().IsRegular() {
+		t.Errorf("cursor/naming.mdc = %v, %v, want a file of its own", info, err)
+	}
+
+	// Building with copies replaces the link instead of writing through it.
+	if err := runBuild(args); err != nil {
+		t.Fatalf("runBuild() error = %v", err)
+	}
+	if info, err := os.Lstat(linked); err != nil || !info.Mode().IsRegular() {
+		t.Errorf("claude/naming.md = %v, %v, want a copy", info, err)
+	}
+
+	if err := runBuild(append([]string{"-link", "hardlink"}, args...)); err != nil {
+		t.Fatalf("runBuild() error = %v", err)
+	}
+	canonicalInfo, err := os.Stat(canonical)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if linkedInfo, err := os.Stat(linked); err != nil || !os.SameFile(canonicalInfo, linkedInfo) {
+		t.Errorf("claude/naming.md is not hard linked to markdown/naming.md")
+	}
+
+	if err := runBuild(append([]string{"-link", "copy"}, args...)); err == nil {
+		t.Error("runBuild() accepted an unknown link mode")
+	}
+}
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/jomadu/ai-resource-compiler-go/internal/diagnostics"
+	"github.com/jomadu/ai-resource-compiler-go/pkg/lint"
+)
+
+// lintRules describes the built-in lint checks for SARIF logs.
+var lintRules = map[string]string{
+	"duplicate-id":        "A resource of the same kind and ID is defined in another file",
+	"empty-body":          "A body or translation is empty once fragments are resolved",
+	"long-body":           "A body is estimated to exceed the token budget",
+	"missing-description": "A rule, prompt, command, or context has no description",
+	"unused-fragment":     "A fragment is not referred to by any body",
+	"unmatched-scope":     "A scope glob matches no file in the repository",
+}
+
+func runLint(args []string) error {
+	fs := flag.NewFlagSet("lint", flag.ContinueOnError)
+	outputFormat := fs.String("format", "text", "Output format: text, json, or sarif")
+	configPath := fs.String("config", "", configFlagUsage)
+	profile := fs.String("profile", "", "Workspace config profile whose resources to lint")
+	root := fs.String("root", ".", "Repository whose files scope globs must match")
+	maxTokens := fs.Int("max-tokens", lint.DefaultMaxTokens, "Estimated tokens a body may take before long-body reports it")
+	disable := fs.String("disable", "", "Skip these comma-separated checks, e.g. missing-description")
+
+	files, err := parseInterspersed(fs, args)
+	if err != nil {
+		return err
+	}
+	if err := checkDiagnosticFormat(*outputFormat); err != nil {
+		return err
+	}
+	if len(files) == 0 {
+		if path := findConfigFile(*configPath); path != "" {
+			ws, err := loadWorkspaceConfig(path)
+			if err != nil {
+				return err
+			}
+			settings, err := ws.resolve(*profile)
+			if err != nil {
+				return err
+			}
+			files = settings.Resources
+		}
+	}
+	if len(files) == 0 {
+		return fmt.Errorf("resource file required (or list resources in %s)", defaultConfigFile)
+	}
+	if files, err = expandResources(files); err != nil {
+		return err
+	}
+	checks, err := lintChecks(*maxTokens, splitList(*disable))
+	if err != nil {
+		return err
+	}
+
+	lib := &lint.Library{Root: *root}
+	rulesets := newRulesetIndex(files, loadResource)
+	for _, file := range files {
+		resource, err := loadResource(file)
+		if err != nil {
+			return err
+		}
+		if resource, err = rulesets.resolve(file, resource); err != nil {
+			return fmt.Errorf("%s: %w", file, err)
+		}
+		lib.Resources = append(lib.Resources, resource)
+	}
+	findings, err := lint.New(checks...).Lint(lib)
+	if err != nil {
+		return err
+	}
+
+	report := []diagnostics.Diagnostic{}
+	errorCount := 0
+	for _, f := range findings {
+		line, column := f.Resource.Position(f.Field, f.Value)
+		report = append(report, diagnostics.Diagnostic{
+			File: f.Resource.Source, Line: line, Column: column, Field: f.Field,
+			Severity: string(f.Severity), Check: f.Check, Message: f.Message,
+		})
+		if f.Severity == lint.SeverityError {
+			errorCount++
+		}
+	}
+	err = writeDiagnostics(os.Stdout, *outputFormat, report, lintRules, func(w io.Writer) {
+		writeLintReport(w, len(files), report)
+	})
+	if err != nil {
+		return err
+	}
+	if errorCount > 0 {
+		return fmt.Errorf("%d lint error(s) in %d resource file(s)", errorCount, len(files))
+	}
+	return nil
+}
+
+// lintChecks returns the built-in checks less those named in disable, with
+// long-body at maxTokens.
+func lintChecks(maxTokens int, disable []string) ([]lint.Check, error) {
+	known := make(map[string]bool)
+	var names []string
+	for _, check := range lint.DefaultChecks() {
+		known[check.Name()] = true
+		names = append(names, check.Name())
+	}
+	skip := make(map[string]bool)
+	for _, name := range disable {
+		if !known[name] {
+			return nil, fmt.Errorf("unknown lint check: %s (checks: %s)", name, strings.Join(names, ", "))
+		}
+		skip[name] = true
+	}
+
+	var checks []lint.Check
+	for _, check := range lint.DefaultChecks() {
+		if skip[check.Name()] {
+			continue
+		}
+		if _, ok := check.(lint.LongBody); ok {
+			check = lint.LongBody{MaxTokens: maxTokens}
+		}
+		checks = append(checks, check)
+	}
+	if len(checks) == 0 {
+		return nil, fmt.Errorf("every lint check is disabled")
+	}
+	return checks, nil
+}
+
+// writeLintReport prints each finding as file:line:column: severity:
+// message [check], the form editors and CI annotations pick up.
+func writeLintReport(w io.Writer, checked int, report []diagnostics.Diagnostic) {
+	for _, d := range report {
+		position := d.File
+		if d.Line > 0 {
+			position = fmt.Sprintf("%s:%d:%d", d.File, d.Line, d.Column)
+		}
+		severity := colorize(w, colorYellow, d.Severity+":")
+		if d.Severity == string(lint.SeverityError) {
+			severity = colorize(w, colorRed, d.Severity+":")
+		}
+		fmt.Fprintf(w, "%s: %s %s [%s]\n", position, severity, d.Message, d.Check)
+	}
+	if len(report) == 0 {
+		fmt.Fprintf(w, "%s %d resource file(s) lint clean\n", colorize(w, colorGreen, "ok"), checked)
+	}
+}
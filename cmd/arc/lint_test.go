@@ -0,0 +1,47 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/jomadu/ai-resource-compiler-go/internal/diagnostics"
+)
+
+func TestRunLint(t *testing.T) {
+	dir := t.TempDir()
+	a := writeTestFile(t, dir, "a.yaml", mergeRuleA)
+	b := writeTestFile(t, dir, "b.yaml", mergeRuleB)
+
+	// Rule B has no description and rule A's scope matches no Go file, but
+	// warnings alone pass.
+	if err := runLint([]string{"-format", "json", "-root", dir, a, b}); err != nil {
+		t.Errorf("runLint() error = %v, want warnings only", err)
+	}
+
+	dup := writeTestFile(t, dir, "dup.yaml", mergeRuleA)
+	err := runLint([]string{"-root", dir, "-disable", "missing-description,unmatched-scope", a, dup})
+	if err == nil || !strings.Contains(err.Error(), "1 lint error(s) in 2") {
+		t.Errorf("runLint() error = %v, want the duplicate ID", err)
+	}
+
+	if err := runLint([]string{"-disable", "no-such-check", a}); err == nil || !strings.Contains(err.Error(), "unknown lint check: no-such-check") {
+		t.Errorf("runLint() error = %v, want unknown check", err)
+	}
+}
+
+func TestLintReport(t *testing.T) {
+	report := []diagnostics.Diagnostic{{File: "rules.yaml", Line: 4, Column: 7, Severity: "warning", Check: "missing-description", Message: "rule naming has no description"}}
+
+	var text bytes.Buffer
+	writeLintReport(&text, 1, report)
+	if want := "rules.yaml:4:7: warning: rule naming has no description [missing-description]\n"; text.String() != want {
+		t.Errorf("text report = %q, want %q", text.String(), want)
+	}
+
+	text.Reset()
+	writeLintReport(&text, 2, nil)
+	if !strings.Contains(text.String(), "2 resource file(s) lint clean") {
+		t.Errorf("text report = %q", text.String())
+	}
+}
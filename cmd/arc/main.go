@@ -1,12 +1,45 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"flag"
 	"fmt"
 	"os"
+	"os/signal"
 	"strings"
+
+	"github.com/jomadu/ai-resource-compiler-go/pkg/compiler"
 )
 
+// subcommands maps subcommand names to their handlers. Invocations that do not
+// start with a known subcommand fall through to the default compile flags.
+var subcommands = map[string]func(args []string) error{
+	"build":    runBuild,
+	"clean":    runClean,
+	"convert":  runConvert,
+	"diff":     runDiff,
+	"doctor":   runDoctor,
+	"explain":  runExplain,
+	"graph":    runGraph,
+	"import":   runImport,
+	"lint":     runLint,
+	"merge":    runMerge,
+	"new":      runNew,
+	"publish":  runPublish,
+	"pull":     runPull,
+	"push":     runPublish,
+	"schema":   runSchema,
+	"split":    runSplit,
+	"targets":  runTargets,
+	"test":     runTest,
+	"validate": runValidate,
+}
+
+// interrupted is done once arc is interrupted, so that compiling and
+// registry transfers stop early.
+var interrupted = context.Background()
+
 type arrayFlags []string
 
 func (a *arrayFlags) String() string {
@@ -19,12 +52,53 @@ func (a *arrayFlags) Set(value string) error {
 }
 
 func main() {
+	var err error
+	var stop context.CancelFunc
+	interrupted, stop = signal.NotifyContext(context.Background(), os.Interrupt)
+	go func() {
+		<-interrupted.Done()
+		stop() // a second interrupt exits at once
+	}()
+
+	if len(os.Args) > 1 {
+		if run, ok := subcommands[os.Args[1]]; ok {
+			if err := run(os.Args[2:]); err != nil {
+				if errors.Is(err, flag.ErrHelp) {
+					os.Exit(0)
+				}
+				fail(err, "")
+			}
+			return
+		}
+	}
+
 	var targets arrayFlags
 	flag.Var(&targets, "target", "Target format to compile to (repeatable)")
+	var overlays arrayFlags
+	flag.Var(&overlays, "overlay", "Patch file applied to the resource before compiling (repeatable)")
 	
-	output := flag.String("output", "stdout", "Output mode: stdout or directory path")
+	output := flag.String("output", "stdout", "Output mode: stdout, directory path, or .zip or .tar.gz archive")
 	flat := flag.Bool("flat", false, "Disable target subdirectories in file output mode")
+	layout := flag.String("layout", layoutTarget, "Output layout: target (subdirectory per target) or native (each tool's own directory under -root)")
+	root := flag.String("root", ".", "Project root of the native layout")
+	force := flag.Bool("force", false, "Overwrite existing files arc did not generate")
+	manifest := flag.Bool("manifest", true, "Write an .arc-manifest.json of the generated files to each output directory, so later runs may overwrite them")
+	lean := flag.Bool("lean", false, "Omit the metadata block from compiled rules")
+	banner := flag.Bool("banner", false, "Mark every file as generated, naming arc's version and the source files")
+	embedSource := flag.String("embed-source", "", "Append each rule's source: path (comment) or yaml (collapsed section)")
+	prefix := flag.String("prefix", "", "Prepend to every generated file name, e.g. org-")
+	pathTemplate := flag.String("path-template", "", "Rewrite generated paths: {dir}, {file}, {name}, {ext}, {target}")
+	only := flag.String("only", "", "Compile only these comma-separated rule or prompt IDs of a collection")
+	exclude := flag.String("exclude", "", "Skip these comma-separated rule or prompt IDs of a collection")
+	minEnforcement := flag.String("min-enforcement", "", "Compile only rules at or above this enforcement level, e.g. should")
+	tags := flag.String("tags", "", "Compile only rules and prompts with any of these comma-separated tags")
+	locale := flag.String("locale", "", "Compile the body variant for this locale, e.g. es")
+	transform := flag.String("transform", "", "Transform bodies with these comma-separated transformers, e.g. trim-trailing-whitespace,wrap=100")
+	outputFormat := flag.String("format", "text", "Format of results printed to stdout: text or json")
+	dryRunMode := flag.Bool("dry-run", false, "List the files that would be written without writing them")
+	statsFile := flag.String("stats-file", "", "Write compile counts, per-target durations, and errors as JSON to this file")
 	help := flag.Bool("help", false, "Show help information")
+	addGlobalFlags(flag.CommandLine)
 
 	flag.Parse()
 
@@ -35,53 +109,211 @@ func main() {
 
 	args := flag.Args()
 	if len(args) == 0 {
-		fmt.Fprintln(os.Stderr, "Error: resource file required")
-		printUsage()
-		os.Exit(1)
+		usageError("resource file required")
 	}
 
-	resourceFile := args[0]
-
 	if len(targets) == 0 {
-		fmt.Fprintln(os.Stderr, "Error: at least one target required")
-		printUsage()
-		os.Exit(1)
+		usageError("at least one target required")
 	}
-
-	if _, err := os.Stat(resourceFile); os.IsNotExist(err) {
-		fmt.Fprintf(os.Stderr, "Error: resource file not found: %s\n", resourceFile)
-		os.Exit(1)
+	if *outputFormat != "text" && *outputFormat != "json" {
+		fail(fmt.Errorf("unknown format: %s (valid formats: text, json)", *outputFormat), "")
 	}
 
-	validTargets := map[string]bool{
-		"markdown": true,
-		"kiro":     true,
-		"cursor":   true,
-		"claude":   true,
-		"copilot":  true,
+	// Quoted globs are expanded here, so they work the same in every shell.
+	resourceFiles, err := expandResources(args)
+	if err != nil {
+		fail(err, "")
+	}
+	for _, resourceFile := range resourceFiles {
+		if _, err := os.Stat(resourceFile); os.IsNotExist(err) && !isRemote(resourceFile) {
+			fail(fmt.Errorf("resource file not found: %s", resourceFile), "")
+		}
 	}
 
 	for _, target := range targets {
-		if !validTargets[target] {
-			fmt.Fprintf(os.Stderr, "Error: unknown target: %s\n\n", target)
-			fmt.Fprintln(os.Stderr, "Valid targets: cursor, kiro, claude, copilot, markdown")
-			os.Exit(1)
+		if _, err := parseTarget(target); err != nil {
+			fail(err, "")
+		}
+	}
+
+	cfg := buildConfig{
+		Targets:        targets,
+		Output:         *output,
+		Flat:           *flat,
+		Layout:         *layout,
+		Root:           *root,
+		Lean:           *lean,
+		EmbedSource:    *embedSource,
+		Overlays:       overlays,
+		Prefix:         *prefix,
+		PathTemplate:   *pathTemplate,
+		Only:           splitList(*only),
+		Exclude:        splitList(*exclude),
+		Locale:         *locale,
+		MinEnforcement: *minEnforcement,
+		Tags:           splitList(*tags),
+		Transformers:   splitList(*transform),
+	}
+	if err := applyLayout(&cfg); err != nil {
+		fail(err, "")
+	}
+	if *statsFile != "" {
+		cfg.Stats = compiler.NewStats()
+	}
+	if *dryRunMode {
+		cfg.DryRun = &dryRun{}
+	}
+	cfg.Pending = &pendingResults{}
+	if !*force {
+		cfg.Guard = newOverwriteGuard()
+	}
+	cfg.Outputs = newOutputPaths()
+	if *banner {
+		cfg.Banners = newBanners()
+	}
+	if *manifest && cfg.DryRun == nil {
+		cfg.Manifests = newManifests()
+	}
+	if *outputFormat == "json" {
+		cfg.JSON = &jsonOutput{}
+	}
+	cfg.Rulesets = buildRulesets(resourceFiles, cfg)
+	if err := cfg.Rulesets.checkItems(cfg.Only, cfg.Exclude); err != nil {
+		fail(err, "")
+	}
+	if err := cfg.Manifests.check(outputDirs(cfg)); err != nil {
+		fail(err, "")
+	}
+	if isArchive(cfg.Output) && cfg.DryRun == nil {
+		if cfg.Archive, err = createArchive(cfg.Output); err != nil {
+			fail(err, "")
+		}
+	}
+	var failedFile string
+	for _, resourceFile := range resourceFiles {
+		if err = compile(resourceFile, cfg); err != nil {
+			failedFile = resourceFile
+			break
+		}
+	}
+	if err == nil {
+		err = writePending(cfg)
+	}
+	if err == nil {
+		err = cfg.Archive.close()
+	} else {
+		cfg.Archive.discard()
+	}
+	if err == nil {
+		err = cfg.JSON.write(os.Stdout)
+	}
+	if err == nil {
+		err = cfg.Manifests.write()
+	}
+	if *statsFile != "" {
+		if statsErr := writeStatsFile(*statsFile, cfg.Stats); statsErr != nil {
+			fail(statsErr, "")
 		}
 	}
+	if err != nil {
+		fail(err, failedFile)
+	}
+	if cfg.DryRun != nil {
+		cfg.DryRun.write(os.Stdout)
+	}
+}
+
+// usageError reports a missing argument, followed by usage in text mode.
+func usageError(message string) {
+	if errorFormat == "json" {
+		fail(errors.New(message), "")
+	}
+	fmt.Fprintf(os.Stderr, "%s %s\n", errorLabel(os.Stderr), message)
+	printUsage()
+	os.Exit(1)
+}
+
+// addGlobalFlags defines the flags every command accepts on fs: -lenient,
+// -error-format, and -no-color.
+func addGlobalFlags(fs *flag.FlagSet) {
+	if fs.Lookup("lenient") == nil {
+		fs.BoolVar(&lenient, "lenient", false, "Ignore fields resource files define that the format does not")
+	}
+	if fs.Lookup("error-format") == nil {
+		errorFormat = "text"
+		fs.Var(errorFormatFlag{}, "error-format", "Report errors as text or json")
+	}
+	if fs.Lookup("no-color") == nil {
+		fs.BoolVar(&noColor, "no-color", false, "Disable colored output")
+	}
+}
 
-	if err := compile(resourceFile, targets, *output, *flat); err != nil {
-		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		os.Exit(1)
+// parseInterspersed parses flags that may appear before, between, or after
+// positional arguments and returns the positional arguments in order. The
+// global flags are defined on fs first.
+func parseInterspersed(fs *flag.FlagSet, args []string) ([]string, error) {
+	addGlobalFlags(fs)
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			return positional, nil
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
 	}
 }
 
 func printUsage() {
 	fmt.Fprintln(os.Stderr, "\nUsage:")
-	fmt.Fprintln(os.Stderr, "  arc [flags] <resource-file>")
+	fmt.Fprintln(os.Stderr, "  arc [flags] <resource-file>...")
+	fmt.Fprintln(os.Stderr, "  arc build [flags] [resource-file...]")
+	fmt.Fprintln(os.Stderr, "  arc validate [flags] <resource-file>...")
+	fmt.Fprintln(os.Stderr, "  arc targets [flags]")
+	fmt.Fprintln(os.Stderr, "  arc clean [flags] [resource-file...]")
+	fmt.Fprintln(os.Stderr, "  arc new [flags]")
+	fmt.Fprintln(os.Stderr, "  arc import -from <target> [flags] <dir-or-file>...")
+	fmt.Fprintln(os.Stderr, "  arc convert -from <target> -to <target> [flags] <dir-or-file>...")
+	fmt.Fprintln(os.Stderr, "  arc merge [flags] <rule-file>...")
+	fmt.Fprintln(os.Stderr, "  arc split [flags] <ruleset-file>")
+	fmt.Fprintln(os.Stderr, "  arc diff [flags] <old-file> <new-file>")
+	fmt.Fprintln(os.Stderr, "  arc explain [flags] <target> <resource-file>")
+	fmt.Fprintln(os.Stderr, "  arc doctor [flags] [resource-file...]")
+	fmt.Fprintln(os.Stderr, "  arc graph [flags] <resource-file>...")
+	fmt.Fprintln(os.Stderr, "  arc lint [flags] [resource-file...]")
+	fmt.Fprintln(os.Stderr, "  arc test [flags] [resource-file...]")
+	fmt.Fprintln(os.Stderr, "  arc publish [flags] <registry/repository:version> <resource-file>...")
+	fmt.Fprintln(os.Stderr, "  arc publish -compiled [flags] <registry/repository:version> <file-or-directory>...")
+	fmt.Fprintln(os.Stderr, "  arc pull [flags] <registry/repository:version>")
+	fmt.Fprintln(os.Stderr, "  arc schema [flags]")
 	fmt.Fprintln(os.Stderr, "\nFlags:")
-	fmt.Fprintln(os.Stderr, "  -target string   Target format (cursor, kiro, claude, copilot, markdown)")
-	fmt.Fprintln(os.Stderr, "  -output string   Output mode: stdout or directory path (default \"stdout\")")
+	fmt.Fprintln(os.Stderr, "  -target string   Target format (cursor, kiro, claude, copilot, gemini, agentsmd, markdown, json)")
+	fmt.Fprintln(os.Stderr, "  -output string   Output mode: stdout, directory path, or .zip or .tar.gz archive")
 	fmt.Fprintln(os.Stderr, "  -flat            Disable target subdirectories in file output mode")
+	fmt.Fprintln(os.Stderr, "  -format string   Format of results printed to stdout: text or json")
+	fmt.Fprintln(os.Stderr, "  -lean            Omit the metadata block from compiled rules")
+	fmt.Fprintln(os.Stderr, "  -embed-source    Append each rule's source: path or yaml")
+	fmt.Fprintln(os.Stderr, "  -overlay string  Patch file applied before compiling (repeatable)")
+	fmt.Fprintln(os.Stderr, "  -prefix string   Prepend to every generated file name")
+	fmt.Fprintln(os.Stderr, "  -path-template string")
+	fmt.Fprintln(os.Stderr, "                   Rewrite generated paths: {dir}, {file}, {name}, {ext}, {target}")
+	fmt.Fprintln(os.Stderr, "  -only string     Compile only these comma-separated items of a collection")
+	fmt.Fprintln(os.Stderr, "  -exclude string  Skip these comma-separated items of a collection")
+	fmt.Fprintln(os.Stderr, "  -min-enforcement string")
+	fmt.Fprintln(os.Stderr, "                   Compile only rules at or above this enforcement level")
+	fmt.Fprintln(os.Stderr, "  -tags string     Compile only rules and prompts with any of these tags")
+	fmt.Fprintln(os.Stderr, "  -locale string   Compile the body variant for this locale")
+	fmt.Fprintln(os.Stderr, "  -transform string")
+	fmt.Fprintln(os.Stderr, "                   Transform bodies with these comma-separated transformers")
+	fmt.Fprintln(os.Stderr, "  -dry-run         List the files that would be written, with target and size")
+	fmt.Fprintln(os.Stderr, "  -stats-file string")
+	fmt.Fprintln(os.Stderr, "                   Write compile metrics as JSON to this file")
+	fmt.Fprintln(os.Stderr, "  -error-format    Error output: text or json (all commands)")
+	fmt.Fprintln(os.Stderr, "  -no-color        Disable colored output (all commands)")
+	fmt.Fprintln(os.Stderr, "  -lenient         Ignore unknown fields in resource files (all commands)")
 	fmt.Fprintln(os.Stderr, "  -help            Show help")
 }
 
@@ -89,16 +321,94 @@ func printHelp() {
 	fmt.Println("Compile AI resources to target-specific formats")
 	fmt.Println()
 	fmt.Println("Usage:")
-	fmt.Println("  arc [flags] <resource-file>")
+	fmt.Println("  arc [flags] <resource-file>...")
+	fmt.Println("  arc <command> [flags] <args>")
+	fmt.Println()
+	fmt.Println("Commands:")
+	fmt.Println("  build            Compile using the workspace config (arc.yaml) and profiles")
+	fmt.Println("  clean            Remove the files build generated, keeping edited ones")
+	fmt.Println("  convert          Translate one tool's rule files into another tool's format")
+	fmt.Println("  diff             Compare two resources field by field")
+	fmt.Println("  doctor           Detect installed AI tools, suggest targets, and find conflicts")
+	fmt.Println("  explain          Show how a target maps each field of a resource")
+	fmt.Println("  graph            Export how resources, includes, and fragments connect (DOT or JSON)")
+	fmt.Println("  import           Read a tool's existing rule files, such as .cursor/rules, into a Ruleset")
+	fmt.Println("  lint             Find duplicate IDs, empty or long bodies, unused fragments, and more")
+	fmt.Println("  merge            Combine standalone Rule resources into a single Ruleset")
+	fmt.Println("  new              Create a resource file by answering prompts")
+	fmt.Println("  publish          Push resource or compiled files to an OCI registry as a versioned bundle")
+	fmt.Println("  pull             Download a resource or compiled bundle from an OCI registry")
+	fmt.Println("  push             Same as publish")
+	fmt.Println("  schema           Print the JSON Schema (or CUE schema) of resource files for editors")
+	fmt.Println("  split            Break a Ruleset into standalone Rule resources")
+	fmt.Println("  targets          List the available targets, workspace aliases, and resource kinds")
+	fmt.Println("  test             Compare compiled output with golden files (-update to accept)")
+	fmt.Println("  validate         Check resources and report every problem with its position")
 	fmt.Println()
 	fmt.Println("Arguments:")
-	fmt.Println("  resource-file    Path to resource file (YAML or JSON)")
+	fmt.Println("  resource-file    Path to resource file (YAML, JSON, or CUE) or a quoted glob such")
+	fmt.Println("                   as \"rules/**/*.yaml\", where ** matches any number of directories")
 	fmt.Println()
 	fmt.Println("Flags:")
 	fmt.Println("  -target string   Target format to compile to (repeatable)")
-	fmt.Println("                   Valid targets: cursor, kiro, claude, copilot, markdown")
-	fmt.Println("  -output string   Output mode: \"stdout\" or directory path (default \"stdout\")")
+	fmt.Println("                   Valid targets: cursor, kiro, claude, copilot, gemini, agentsmd,")
+	fmt.Println("                   markdown, json")
+	fmt.Println("  -output string   Output mode: \"stdout\", a directory path, or a .zip, .tar.gz, or")
+	fmt.Println("                   .tgz archive to write every file into (default \"stdout\")")
 	fmt.Println("  -flat            Disable target subdirectories in file output mode")
+	fmt.Println("  -layout string   \"target\" (default) for a subdirectory of -output per target, or")
+	fmt.Println("                   \"native\" to write each target where its tool reads files, such")
+	fmt.Println("                   as .cursor or .kiro/steering, under -root")
+	fmt.Println("  -root string     Project root of the native layout (default \".\")")
+	fmt.Println("  -force           Overwrite existing files arc did not generate, which are otherwise")
+	fmt.Println("                   refused: those no .arc-manifest.json lists that lack a generated")
+	fmt.Println("                   marker")
+	fmt.Println("  -manifest        Write an .arc-manifest.json of the generated files to each")
+	fmt.Println("                   output directory, so later runs may overwrite them")
+	fmt.Println("                   (default true)")
+	fmt.Println("  -format string   Print results to stdout as \"text\" (default), each under a")
+	fmt.Println("                   \"=== target/path ===\" banner, or as a \"json\" array of")
+	fmt.Println("                   {target, path, content} objects")
+	fmt.Println("  -lean            Omit the metadata block, keeping only the enforcement header and body")
+	fmt.Println("  -banner          Mark every file as generated by arc, naming its version and the")
+	fmt.Println("                   source files with their SHA-256, in a comment or frontmatter key")
+	fmt.Println("  -embed-source string")
+	fmt.Println("                   Append each rule's source for review: \"path\" (comment naming the")
+	fmt.Println("                   source file) or \"yaml\" (source YAML in a collapsed section)")
+	fmt.Println("  -overlay string  Patch file applied to the resource before compiling (repeatable)")
+	fmt.Println("                   Strategic merge (YAML mapping) or JSON patch (list of ops)")
+	fmt.Println("  -prefix string   Prepend to every generated file name, e.g. \"org-\", to tell")
+	fmt.Println("                   arc-managed files apart from hand-written ones")
+	fmt.Println("  -path-template string")
+	fmt.Println("                   Rewrite generated paths using {dir}, {file}, {name}, {ext}, and")
+	fmt.Println("                   {target}, e.g. \"{dir}/arc/{file}\"")
+	fmt.Println("  -only string     Compile only these comma-separated rule or prompt IDs of a")
+	fmt.Println("                   Ruleset or Promptset")
+	fmt.Println("  -exclude string  Skip these comma-separated rule or prompt IDs of a collection")
+	fmt.Println("  -min-enforcement string")
+	fmt.Println("                   Compile only rules at or above this enforcement level, e.g.")
+	fmt.Println("                   \"should\" for should and must rules; prompts are unaffected")
+	fmt.Println("  -tags string     Compile only rules and prompts with any of these comma-separated")
+	fmt.Println("                   tags, those of their collection included, e.g. backend,security")
+	fmt.Println("  -locale string   Compile each rule and prompt with its body for this locale,")
+	fmt.Println("                   e.g. \"es\"; items without a translation use their body")
+	fmt.Println("  -transform string")
+	fmt.Println("                   Pass each body, fragments resolved, through these comma-separated")
+	fmt.Println("                   transformers in order: trim-trailing-whitespace,")
+	fmt.Println("                   normalize-line-endings, strip-html-comments, smart-quotes, and")
+	fmt.Println("                   wrap=WIDTH")
+	fmt.Println("  -dry-run         Run the full compile but only list the files that would be")
+	fmt.Println("                   written, with their target and size in bytes")
+	fmt.Println("  -stats-file string")
+	fmt.Println("                   Write compile counts, per-target durations, and error counts")
+	fmt.Println("                   as JSON to this file, also when compilation fails")
+	fmt.Println("  -error-format string")
+	fmt.Println("                   Report errors as \"text\" (default) or \"json\" objects with code,")
+	fmt.Println("                   message, file, line, and column; accepted by every command")
+	fmt.Println("  -no-color        Disable colored output; also disabled by NO_COLOR and when")
+	fmt.Println("                   output is not a terminal")
+	fmt.Println("  -lenient         Ignore fields resource files define that the format does not,")
+	fmt.Println("                   which are otherwise an error naming each; accepted by every command")
 	fmt.Println("  -help            Show this help message")
 	fmt.Println()
 	fmt.Println("Examples:")
@@ -109,13 +419,66 @@ func printHelp() {
 	fmt.Println("  arc -target markdown -target kiro resource.yaml")
 	fmt.Println()
 	fmt.Println("  # Compile to cursor, write to target subdirectory")
-	fmt.Println("  arc -target cursor -output .cursor/rules resource.yaml")
+	fmt.Println("  arc -target cursor -output ./output resource.yaml")
 	fmt.Println()
 	fmt.Println("  # Compile to cursor, write directly to directory (no subdirectory)")
-	fmt.Println("  arc -target cursor -output .cursor/rules -flat resource.yaml")
+	fmt.Println("  arc -target cursor -output .cursor -flat resource.yaml")
 	fmt.Println()
 	fmt.Println("  # Compile to all targets, write to separate subdirectories")
 	fmt.Println("  arc -target cursor -target kiro -target claude -target copilot -target markdown -output ./output resource.yaml")
+	fmt.Println()
+	fmt.Println("  # Mark generated cursor rules so they stand out from hand-written ones")
+	fmt.Println("  arc -target cursor -output .cursor -flat -prefix org- resource.yaml")
+	fmt.Println()
+	fmt.Println("  # Iterate on a single rule of a large ruleset")
+	fmt.Println("  arc -target cursor -only meaningfulNames ruleset.yaml")
+	fmt.Println()
+	fmt.Println("  # Tighten enforcement for production with an overlay")
+	fmt.Println("  arc -target cursor -overlay overlays/prod.yaml resource.yaml")
+	fmt.Println()
+	fmt.Println("  # Build the resources, targets, and overlays of the prod profile in arc.yaml")
+	fmt.Println("  arc build -profile prod")
+	fmt.Println()
+	fmt.Println("  # See which compiled files take the most context, warning over 1500 tokens")
+	fmt.Println("  arc build -report tokens -token-budget 1500")
+	fmt.Println()
+	fmt.Println("  # Create a rule interactively, writing the body in $EDITOR")
+	fmt.Println("  arc new -o rules/naming.yaml")
+	fmt.Println()
+	fmt.Println("  # Migrate hand-written Cursor rules into a ruleset")
+	fmt.Println("  arc import -from cursor .cursor/rules -o rules/cursor.yaml")
+	fmt.Println()
+	fmt.Println("  # Turn Cursor rules into Copilot instructions without keeping a ruleset")
+	fmt.Println("  arc convert -from cursor -to copilot .cursor/rules -o .github/instructions")
+	fmt.Println()
+	fmt.Println("  # Combine rule files into a ruleset")
+	fmt.Println("  arc merge rule1.yaml rule2.yaml -o ruleset.yaml")
+	fmt.Println()
+	fmt.Println("  # Break a ruleset into one file per rule")
+	fmt.Println("  arc split ruleset.yaml -o rules/")
+	fmt.Println()
+	fmt.Println("  # Review rule changes as JSON")
+	fmt.Println("  arc diff -format json old.yaml new.yaml")
+	fmt.Println()
+	fmt.Println("  # Save the schema of resource files for editor validation")
+	fmt.Println("  arc schema > ai-resource.schema.json")
+	fmt.Println()
+	fmt.Println("  # Check which AI tools the repository uses and what arc would overwrite")
+	fmt.Println("  arc doctor")
+	fmt.Println()
+	fmt.Println("  # Check the workspace's rule library for problems validation lets through")
+	fmt.Println("  arc lint -disable missing-description")
+	fmt.Println()
+	fmt.Println("  # See which frontmatter, file names, and enforcement mapping cursor uses")
+	fmt.Println("  arc explain cursor resource.yaml")
+	fmt.Println()
+	fmt.Println("  # Share a rule library through a registry")
+	fmt.Println("  arc publish ghcr.io/acme/rules:1.2.0 rules/*.yaml")
+	fmt.Println("  arc pull ghcr.io/acme/rules:1.2.0 -o vendor/rules")
+	fmt.Println()
+	fmt.Println("  # Share compiled rules, ready to use without arc build")
+	fmt.Println("  arc push -compiled ghcr.io/acme/cursor-rules:1.2.0 .cursor/rules")
+	fmt.Println("  arc pull ghcr.io/acme/cursor-rules:1.2.0")
 }
 
 
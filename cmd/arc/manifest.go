@@ -0,0 +1,277 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// manifestFile is the manifest written to each output directory with
+// -manifest: to a target's subdirectory, or to the directory shared by the
+// targets written there with -flat. The leading dot and arc prefix keep it
+// clear of files tools read, such as an extension's manifest.json.
+const manifestFile = ".arc-manifest.json"
+
+// manifest lists the files arc wrote to an output directory, so later runs
+// and other tools know which files arc owns.
+type manifest struct {
+	CompiledAt time.Time       `json:"compiledAt"`
+	Files      []manifestEntry `json:"files"`
+}
+
+// manifestEntry is a written file. Path is relative to the manifest's
+// directory and slash-separated.
+type manifestEntry struct {
+	Path    string           `json:"path"`
+	Target  string           `json:"target"`
+	SHA256  string           `json:"sha256"`
+	Sources []manifestSource `json:"sources"`
+}
+
+// manifestSource is a resource file an output was compiled from. Merged
+// files, such as GEMINI.md, have several.
+type manifestSource struct {
+	File   string `json:"file"`
+	SHA256 string `json:"sha256"`
+}
+
+// manifests collects the manifest of every output directory during a build.
+// A nil manifests records nothing, so builds without -manifest share the
+// output code.
+type manifests struct {
+	compiledAt time.Time
+	dirs       map[string]*manifest
+	hashes     sourceHashes
+}
+
+func newManifests() *manifests {
+	return &manifests{
+		compiledAt: time.Now().UTC().Truncate(time.Second),
+		dirs:       make(map[string]*manifest),
+		hashes:     make(sourceHashes),
+	}
+}
+
+// record adds the results of allResults, written under outputDir, to the
+// manifests of their directories.
+func (m *manifests) record(allResults []targetResults, outputDir string, flat bool) error {
+	if m == nil {
+		return nil
+	}
+	for _, tr := range allResults {
+		dir := outputDir
+		if !flat {
+			dir = filepath.Join(outputDir, tr.target)
+		}
+		for _, result := range tr.results {
+			if result.Path == manifestFile {
+				return fmt.Errorf("target %s writes %s, which would overwrite the manifest", tr.target, manifestFile)
+			}
+			entry := manifestEntry{Path: result.Path, Target: tr.target, SHA256: hashBytes([]byte(result.Content))}
+			for _, file := range tr.sources[result.Path] {
+				hash, err := m.hashes.hash(file)
+				if err != nil {
+					return err
+				}
+				entry.Sources = append(entry.Sources, manifestSource{File: filepath.ToSlash(file), SHA256: hash})
+			}
+			if m.dirs[dir] == nil {
+				m.dirs[dir] = &manifest{CompiledAt: m.compiledAt}
+			}
+			m.dirs[dir].Files = append(m.dirs[dir].Files, entry)
+		}
+	}
+	return nil
+}
+
+// write writes the manifest of each directory, its files sorted by path.
+// Files an earlier manifest lists that this build did not write, such as
+// the output of resources since deleted, stay listed while they exist, so
+// arc clean still removes them.
+func (m *manifests) write() error {
+	if m == nil {
+		return nil
+	}
+	dirs := make([]string, 0, len(m.dirs))
+	for dir := range m.dirs {
+		dirs = append(dirs, dir)
+	}
+	sort.Strings(dirs)
+	for _, dir := range dirs {
+		if err := m.keepPrevious(dir); err != nil {
+			return err
+		}
+		files := m.dirs[dir].Files
+		sort.SliceStable(files, func(i, j int) bool { return files[i].Path < files[j].Path })
+		data, err := encodeManifest(m.dirs[dir])
+		if err != nil {
+			return err
+		}
+		filePath, changed, err := writeResultFile(dir, manifestFile, data, nil)
+		if err != nil {
+			return err
+		}
+		if changed {
+			printWrote(filePath)
+		}
+	}
+	return nil
+}
+
+// keepPrevious adds the files of the manifest already in dir that this
+// build did not write and that still exist to the manifest of dir. A
+// manifest that is not arc's is an error; see check.
+func (m *manifests) keepPrevious(dir string) error {
+	loc := manifestLocation{dir: dir, path: manifestFile}
+	if _, err := os.Stat(filepath.Join(dir, manifestFile)); err != nil {
+		return nil
+	}
+	previous, err := loc.read()
+	if err != nil {
+		return err
+	}
+	if previous.CompiledAt.IsZero() {
+		return foreignManifestError(loc)
+	}
+	written := make(map[string]bool)
+	for _, entry := range m.dirs[dir].Files {
+		written[entry.Path] = true
+	}
+	for _, entry := range previous.Files {
+		if written[entry.Path] {
+			continue
+		}
+		if _, err := os.Lstat(filepath.Join(dir, filepath.FromSlash(entry.Path))); err == nil {
+			m.dirs[dir].Files = append(m.dirs[dir].Files, entry)
+		}
+	}
+	return nil
+}
+
+// check returns an error if any of the manifests in dirs is not arc's, so
+// a build stops before writing any of its results rather than after.
+func (m *manifests) check(dirs []string) error {
+	if m == nil {
+		return nil
+	}
+	found, err := findManifests(dirs)
+	if err != nil {
+		return err
+	}
+	for _, loc := range found {
+		previous, err := loc.read()
+		if err != nil {
+			return err
+		}
+		if previous.CompiledAt.IsZero() {
+			return foreignManifestError(loc)
+		}
+	}
+	return nil
+}
+
+// foreignManifestError is the error for a manifest at loc that arc did not
+// write.
+func foreignManifestError(loc manifestLocation) error {
+	filePath := filepath.Join(loc.dir, filepath.FromSlash(loc.path))
+	return fmt.Errorf("refusing to overwrite %s, which arc did not generate (use -manifest=false to build without a manifest)", filePath)
+}
+
+// sourceHashes holds the SHA-256 of each resource file, by path, so each is
+// read once.
+type sourceHashes map[string]string
+
+// hash returns the SHA-256 of file, or of the remote resource file at that
+// URL as it was loaded.
+func (h sourceHashes) hash(file string) (string, error) {
+	if hash, ok := h[file]; ok {
+		return hash, nil
+	}
+	if hash, ok := remoteHashes[file]; ok {
+		return hash, nil
+	}
+	hash, err := hashFile(file)
+	if err != nil {
+		return "", fmt.Errorf("failed to hash %s: %w", file, err)
+	}
+	h[file] = hash
+	return hash, nil
+}
+
+// hashBytes returns the hex-encoded SHA-256 of data.
+func hashBytes(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// manifestLocation is a manifest found in an output directory: path, within
+// dir, is .arc-manifest.json or <target>/.arc-manifest.json.
+type manifestLocation struct {
+	dir  string
+	path string
+}
+
+// findManifests returns the manifests in each of dirs, for flat output, or
+// in their subdirectories, one per target; each once, though dirs may nest.
+func findManifests(dirs []string) ([]manifestLocation, error) {
+	var found []manifestLocation
+	seen := make(map[string]bool)
+	add := func(loc manifestLocation) {
+		filePath := filepath.Join(loc.dir, filepath.FromSlash(loc.path))
+		if !seen[filePath] {
+			seen[filePath] = true
+			found = append(found, loc)
+		}
+	}
+	for _, dir := range dirs {
+		if _, err := os.Stat(filepath.Join(dir, manifestFile)); err == nil {
+			add(manifestLocation{dir: dir, path: manifestFile})
+		}
+		matches, err := filepath.Glob(filepath.Join(dir, "*", manifestFile))
+		if err != nil {
+			return nil, err
+		}
+		for _, match := range matches {
+			add(manifestLocation{dir: dir, path: filepath.Base(filepath.Dir(match)) + "/" + manifestFile})
+		}
+	}
+	return found, nil
+}
+
+// read reads and decodes the manifest.
+func (loc manifestLocation) read() (*manifest, error) {
+	filePath := filepath.Join(loc.dir, filepath.FromSlash(loc.path))
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifest %s: %w", filePath, err)
+	}
+	var m manifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("invalid manifest %s: %w", filePath, err)
+	}
+	return &m, nil
+}
+
+// write replaces the manifest with m.
+func (loc manifestLocation) write(m *manifest) error {
+	data, err := encodeManifest(m)
+	if err != nil {
+		return err
+	}
+	_, _, err = writeResultFile(loc.dir, loc.path, data, nil)
+	return err
+}
+
+// encodeManifest returns m as indented JSON.
+func encodeManifest(m *manifest) (string, error) {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(data) + "\n", nil
+}
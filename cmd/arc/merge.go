@@ -0,0 +1,171 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/jomadu/ai-resource-compiler-go/internal/format"
+	"github.com/jomadu/ai-resource-compiler-go/pkg/compiler"
+	"gopkg.in/yaml.v3"
+)
+
+func runMerge(args []string) error {
+	fs := flag.NewFlagSet("merge", flag.ContinueOnError)
+	output := fs.String("o", "stdout", "Output file for the merged ruleset, or stdout")
+	id := fs.String("id", "", "Ruleset ID (default: output file name)")
+	name := fs.String("name", "", "Ruleset name")
+	description := fs.String("description", "", "Ruleset description")
+
+	files, err := parseInterspersed(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(files) == 0 {
+		return fmt.Errorf("at least one rule file required")
+	}
+
+	metadata := format.Metadata{ID: *id, Name: *name, Description: *description}
+	if metadata.ID == "" {
+		if *output == "stdout" {
+			return fmt.Errorf("ruleset id required when writing to stdout (use -id)")
+		}
+		base := filepath.Base(*output)
+		metadata.ID = strings.TrimSuffix(base, filepath.Ext(base))
+	}
+	if err := format.ValidateID(metadata.ID); err != nil {
+		return fmt.Errorf("invalid ruleset id: %w", err)
+	}
+
+	var rules []*format.Rule
+	sources := make(map[string]string)
+	for _, file := range files {
+		resource, err := loadResource(file)
+		if err != nil {
+			return fmt.Errorf("%s: %w", file, err)
+		}
+		if resource.Kind != "Rule" {
+			return fmt.Errorf("%s: expected kind Rule, got %s", file, resource.Kind)
+		}
+		rule := resource.Spec.(*format.Rule)
+		if prev, ok := sources[rule.Metadata.ID]; ok {
+			return fmt.Errorf("duplicate rule id %q in %s and %s", rule.Metadata.ID, prev, file)
+		}
+		sources[rule.Metadata.ID] = file
+		rules = append(rules, rule)
+	}
+
+	merged := mergeRules(metadata, rules)
+
+	data, err := encodeResource(merged)
+	if err != nil {
+		return err
+	}
+
+	if *output == "stdout" {
+		_, err := os.Stdout.Write(data)
+		return err
+	}
+	if err := os.WriteFile(*output, data, 0644); err != nil {
+		return fmt.Errorf("failed to write file %s: %w", *output, err)
+	}
+	printWrote(*output)
+	return nil
+}
+
+// mergeRules combines standalone rules into a single Ruleset resource, in
+// the order given. Fragments with identical content are stored once; fragments whose name is
+// already taken by different content are renamed to {ruleID}_{name} and the
+// rule body references are rewritten to match.
+func mergeRules(metadata format.Metadata, rules []*format.Rule) *compiler.Resource {
+	ruleset := &format.Ruleset{Metadata: metadata}
+	ruleset.Spec.Rules = make(map[string]format.RuleItem)
+	fragments := make(map[string]string)
+	byContent := make(map[string]string)
+
+	for _, rule := range rules {
+		rename := make(map[string]string)
+		names := make([]string, 0, len(rule.Spec.Fragments))
+		for fragName := range rule.Spec.Fragments {
+			names = append(names, fragName)
+		}
+		sort.Strings(names)
+
+		for _, fragName := range names {
+			content := rule.Spec.Fragments[fragName]
+			if existing, ok := byContent[content]; ok {
+				rename[fragName] = existing
+				continue
+			}
+			target := fragName
+			if _, taken := fragments[target]; taken {
+				target = rule.Metadata.ID + "_" + fragName
+				for n := 2; ; n++ {
+					if _, taken := fragments[target]; !taken {
+						break
+					}
+					target = fmt.Sprintf("%s_%s%d", rule.Metadata.ID, fragName, n)
+				}
+			}
+			fragments[target] = content
+			byContent[content] = target
+			rename[fragName] = target
+		}
+
+		ruleset.Spec.Rules[rule.Metadata.ID] = format.RuleItem{
+			Name:        rule.Metadata.Name,
+			Description: rule.Metadata.Description,
+			Enforcement: rule.Spec.Enforcement,
+			Scope:       rule.Spec.Scope,
+			Body:        renameFragmentRefs(rule.Spec.Body, rename),
+			Tags:        rule.Metadata.Tags,
+		}
+		ruleset.Spec.Order = append(ruleset.Spec.Order, rule.Metadata.ID)
+	}
+
+	if len(fragments) > 0 {
+		ruleset.Spec.Fragments = fragments
+	}
+
+	resource := &compiler.Resource{
+		APIVersion: "ai-resource/draft",
+		Kind:       "Ruleset",
+		Spec:       ruleset,
+	}
+	resource.Metadata.ID = metadata.ID
+	return resource
+}
+
+// renameFragmentRefs rewrites $fragment references in a body using rename.
+func renameFragmentRefs(body format.Body, rename map[string]string) format.Body {
+	if body.String != nil || len(body.Array) == 0 {
+		return body
+	}
+	out := format.Body{Array: make([]string, len(body.Array))}
+	for i, ref := range body.Array {
+		if strings.HasPrefix(ref, "$") {
+			if target, ok := rename[strings.TrimPrefix(ref, "$")]; ok {
+				ref = "$" + target
+			}
+		}
+		out.Array[i] = ref
+	}
+	return out
+}
+
+func encodeResource(resource *compiler.Resource) ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(resource); err != nil {
+		return nil, fmt.Errorf("failed to encode resource: %w", err)
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, fmt.Errorf("failed to encode resource: %w", err)
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jomadu/ai-resource-compiler-go/internal/format"
+)
+
+func writeTestFile(t *testing.T, dir, name, content string) string {
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+	return path
+}
+
+const mergeRuleA = `apiVersion: ai-resource/draft
+kind: Rule
+metadata:
+  id: ruleA
+  name: Rule A
+  description: First rule
+spec:
+  enforcement: must
+  scope:
+    - files: ["**/*.go"]
+  fragments:
+    shared: Shared text
+    intro: Intro for A
+  body:
+    - $intro
+    - $shared
+`
+
+const mergeRuleB = `apiVersion: ai-resource/draft
+kind: Rule
+metadata:
+  id: ruleB
+  name: Rule B
+spec:
+  enforcement: should
+  fragments:
+    common: Shared text
+    intro: Intro for B
+  body:
+    - $intro
+    - $common
+`
+
+func TestMergeWritesRuleset(t *testing.T) {
+	dir := t.TempDir()
+	a := writeTestFile(t, dir, "a.yaml", mergeRuleA)
+	b := writeTestFile(t, dir, "b.yaml", mergeRuleB)
+	out := filepath.Join(dir, "merged.yaml")
+
+	if err := runMerge([]string{a, b, "-o", out, "-name", "Merged"}); err != nil {
+		t.Fatalf("runMerge() error = %v", err)
+	}
+
+	resource, err := loadResource(out)
+	if err != nil {
+		t.Fatalf("loadResource() error = %v", err)
+	}
+	if resource.Kind != "Ruleset" {
+		t.Fatalf("Kind = %v, want Ruleset", resource.Kind)
+	}
+
+	ruleset := resource.Spec.(*format.Ruleset)
+	if ruleset.Metadata.ID != "merged" {
+		t.Errorf("ID = %v, want merged", ruleset.Metadata.ID)
+	}
+	if ruleset.Metadata.Name != "Merged" {
+		t.Errorf("Name = %v, want Merged", ruleset.Metadata.Name)
+	}
+
+	ruleA, ok := ruleset.Spec.Rules["ruleA"]
+	if !ok {
+		t.Fatal("ruleA missing from merged ruleset")
+	}
+	if ruleA.Name != "Rule A" || ruleA.Description != "First rule" || ruleA.Enforcement != "must" {
+		t.Errorf("ruleA metadata not preserved: %+v", ruleA)
+	}
+	if len(ruleA.Scope) != 1 || ruleA.Scope[0].Files[0] != "**/*.go" {
+		t.Errorf("ruleA scope not preserved: %+v", ruleA.Scope)
+	}
+
+	got := format.ResolveBody(ruleset.Spec.Rules["ruleB"].Body, ruleset.Spec.Fragments)
+	if got != "Intro for B\n\nShared text" {
+		t.Errorf("ruleB body = %q, want fragments resolved as before the merge", got)
+	}
+}
+
+func TestMergeDeduplicatesFragments(t *testing.T) {
+	dir := t.TempDir()
+	a := writeTestFile(t, dir, "a.yaml", mergeRuleA)
+	b := writeTestFile(t, dir, "b.yaml", mergeRuleB)
+	out := filepath.Join(dir, "merged.yaml")
+
+	if err := runMerge([]string{"-o", out, a, b}); err != nil {
+		t.Fatalf("runMerge() error = %v", err)
+	}
+
+	resource, err := loadResource(out)
+	if err != nil {
+		t.Fatalf("loadResource() error = %v", err)
+	}
+	fragments := resource.Spec.(*format.Ruleset).Spec.Fragments
+
+	if len(fragments) != 3 {
+		t.Errorf("got %d fragments, want 3 (shared content stored once): %v", len(fragments), fragments)
+	}
+	if _, ok := fragments["common"]; ok {
+		t.Error("duplicate content stored under second fragment name")
+	}
+	if fragments["ruleB_intro"] != "Intro for B" {
+		t.Errorf("conflicting fragment not renamed: %v", fragments)
+	}
+}
+
+func TestMergeErrorDuplicateRuleID(t *testing.T) {
+	dir := t.TempDir()
+	a := writeTestFile(t, dir, "a.yaml", mergeRuleA)
+	b := writeTestFile(t, dir, "b.yaml", mergeRuleA)
+
+	err := runMerge([]string{"-id", "merged", a, b})
+	if err == nil {
+		t.Fatal("Expected error for duplicate rule id, got nil")
+	}
+	if !strings.Contains(err.Error(), "duplicate rule id") {
+		t.Errorf("Expected 'duplicate rule id' error, got: %v", err)
+	}
+}
+
+func TestMergeErrorNonRuleKind(t *testing.T) {
+	dir := t.TempDir()
+	a := writeTestFile(t, dir, "a.yaml", mergeRuleA)
+	p := writeTestFile(t, dir, "p.yaml", `apiVersion: ai-resource/draft
+kind: Prompt
+metadata:
+  id: prompt
+spec:
+  body: Prompt body
+`)
+
+	err := runMerge([]string{"-id", "merged", a, p})
+	if err == nil {
+		t.Fatal("Expected error for non-Rule input, got nil")
+	}
+	if !strings.Contains(err.Error(), "expected kind Rule") {
+		t.Errorf("Expected 'expected kind Rule' error, got: %v", err)
+	}
+}
+
+func TestMergeErrorStdoutWithoutID(t *testing.T) {
+	dir := t.TempDir()
+	a := writeTestFile(t, dir, "a.yaml", mergeRuleA)
+
+	err := runMerge([]string{a})
+	if err == nil {
+		t.Fatal("Expected error for missing ruleset id, got nil")
+	}
+}
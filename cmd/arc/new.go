@@ -0,0 +1,308 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/jomadu/ai-resource-compiler-go/internal/format"
+	"github.com/jomadu/ai-resource-compiler-go/pkg/compiler"
+	"github.com/jomadu/ai-resource-compiler-go/pkg/loader"
+)
+
+func runNew(args []string) error {
+	fs := flag.NewFlagSet("new", flag.ContinueOnError)
+	output := fs.String("o", "", "Output file (default: <id>.yaml)")
+
+	positional, err := parseInterspersed(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) > 0 {
+		return fmt.Errorf("unexpected arguments: %s", strings.Join(positional, " "))
+	}
+
+	p := &prompter{in: bufio.NewReader(os.Stdin), out: os.Stderr}
+	resource, err := newResource(p)
+	if err != nil {
+		return err
+	}
+
+	path := *output
+	if path == "" {
+		path = resource.Metadata.ID + ".yaml"
+	}
+	if _, err := os.Stat(path); err == nil {
+		return fmt.Errorf("%s already exists", path)
+	}
+	data, err := encodeResource(resource)
+	if err != nil {
+		return err
+	}
+	if err := validateNewResource(data); err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write file %s: %w", path, err)
+	}
+	printWrote(path)
+	return nil
+}
+
+// newResource asks for the fields of a new resource of any kind. Rulesets
+// and promptsets are created with a single rule or prompt.
+func newResource(p *prompter) (*compiler.Resource, error) {
+	kind, err := p.choose("Kind", compiler.Kinds(), "Rule")
+	if err != nil {
+		return nil, err
+	}
+	isRule := kind == "Rule" || kind == "Ruleset"
+	collection := kind == "Ruleset" || kind == "Promptset"
+
+	var metadata format.Metadata
+	if metadata.ID, err = p.askValid("ID", "", format.ValidateID); err != nil {
+		return nil, err
+	}
+
+	var itemID string
+	if collection {
+		item := "Rule"
+		if !isRule {
+			item = "Prompt"
+		}
+		if itemID, err = p.askValid(item+" ID", "", format.ValidateID); err != nil {
+			return nil, err
+		}
+	}
+
+	validateName := func(string) error { return nil }
+	if isRule {
+		validateName = format.ValidateRuleName
+	}
+	name, err := p.askValid("Name", "", validateName)
+	if err != nil {
+		return nil, err
+	}
+	description, err := p.ask("Description (optional)", "")
+	if err != nil {
+		return nil, err
+	}
+
+	var enforcement string
+	var scope []format.ScopeEntry
+	if isRule {
+		if enforcement, err = p.choose("Enforcement", compiler.EnforcementNames(), "should"); err != nil {
+			return nil, err
+		}
+		globs, err := p.ask("Scope globs, comma-separated (optional)", "")
+		if err != nil {
+			return nil, err
+		}
+		if files := splitList(globs); len(files) > 0 {
+			scope = []format.ScopeEntry{{Files: files}}
+		}
+	}
+
+	var arguments []format.CommandArgument
+	if kind == "Command" {
+		if arguments, err = askArguments(p); err != nil {
+			return nil, err
+		}
+	}
+
+	text, err := editBody(p)
+	if err != nil {
+		return nil, err
+	}
+	body := format.Body{String: &text}
+
+	resource := &compiler.Resource{APIVersion: "ai-resource/draft", Kind: kind}
+	resource.Metadata.ID = metadata.ID
+	switch kind {
+	case "Rule":
+		metadata.Name, metadata.Description = name, description
+		resource.Spec = &format.Rule{Metadata: metadata, Spec: format.RuleSpec{Enforcement: enforcement, Scope: scope, Body: body}}
+	case "Ruleset":
+		ruleset := &format.Ruleset{Metadata: metadata}
+		ruleset.Spec.Rules = map[string]format.RuleItem{itemID: {
+			Name: name, Description: description, Enforcement: enforcement, Scope: scope, Body: body,
+		}}
+		resource.Spec = ruleset
+	case "Prompt":
+		metadata.Name, metadata.Description = name, description
+		resource.Spec = &format.Prompt{Metadata: metadata, Spec: format.PromptSpec{Body: body}}
+	case "Promptset":
+		promptset := &format.Promptset{Metadata: metadata}
+		promptset.Spec.Prompts = map[string]format.PromptItem{itemID: {Name: name, Body: body}}
+		resource.Spec = promptset
+	case "Command":
+		metadata.Name, metadata.Description = name, description
+		resource.Spec = &format.Command{Metadata: metadata, Spec: format.CommandSpec{Arguments: arguments, Body: body}}
+	case "Context":
+		metadata.Name, metadata.Description = name, description
+		resource.Spec = &format.Context{Metadata: metadata, Spec: format.ContextSpec{Body: body}}
+	default:
+		return nil, fmt.Errorf("arc new cannot create %s resources", kind)
+	}
+	return resource, nil
+}
+
+// askArguments asks for the argument names of a command until each is a
+// valid ID.
+func askArguments(p *prompter) ([]format.CommandArgument, error) {
+	for {
+		answer, err := p.ask("Arguments, comma-separated (optional)", "")
+		if err != nil {
+			return nil, err
+		}
+		var arguments []format.CommandArgument
+		var invalid error
+		for _, name := range splitList(answer) {
+			if invalid = format.ValidateID(name); invalid != nil {
+				break
+			}
+			arguments = append(arguments, format.CommandArgument{Name: name})
+		}
+		if invalid != nil {
+			fmt.Fprintf(p.out, "  %v\n", invalid)
+			continue
+		}
+		return arguments, nil
+	}
+}
+
+// validateNewResource checks that data loads and compiles like any resource
+// file before it is written.
+func validateNewResource(data []byte) error {
+	resource, err := (&loader.Loader{}).Parse(data, ".")
+	if err != nil {
+		return err
+	}
+	_, err = newCompiler().Compile(resource, compiler.CompileOptions{Targets: []compiler.Target{compiler.TargetMarkdown}})
+	if err != nil {
+		return fmt.Errorf("generated resource is invalid: %w", err)
+	}
+	return nil
+}
+
+// editBody opens $VISUAL or $EDITOR on a temporary file and returns its
+// content. Without an editor the body is read as a single line.
+func editBody(p *prompter) (string, error) {
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		return p.askValid("Body", "", nonEmpty)
+	}
+
+	f, err := os.CreateTemp("", "arc-body-*.md")
+	if err != nil {
+		return "", fmt.Errorf("failed to create body file: %w", err)
+	}
+	f.Close()
+	defer os.Remove(f.Name())
+
+	fmt.Fprintf(p.out, "Opening %s for the body...\n", editor)
+	argv := append(strings.Fields(editor), f.Name())
+	cmd := exec.Command(argv[0], argv[1:]...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("editor %s failed: %w", editor, err)
+	}
+
+	data, err := os.ReadFile(f.Name())
+	if err != nil {
+		return "", fmt.Errorf("failed to read body file: %w", err)
+	}
+	body := strings.TrimSpace(string(data))
+	if err := nonEmpty(body); err != nil {
+		return "", err
+	}
+	return body, nil
+}
+
+func nonEmpty(s string) error {
+	if strings.TrimSpace(s) == "" {
+		return errors.New("body cannot be empty")
+	}
+	return nil
+}
+
+// splitList splits a comma-separated answer into trimmed, non-empty values.
+func splitList(s string) []string {
+	var values []string
+	for _, v := range strings.Split(s, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
+// prompter asks questions on out and reads one answer per line from in.
+type prompter struct {
+	in  *bufio.Reader
+	out io.Writer
+}
+
+// ask returns the answer to question, or def if the answer is empty.
+func (p *prompter) ask(question, def string) (string, error) {
+	if def != "" {
+		fmt.Fprintf(p.out, "%s [%s]: ", question, def)
+	} else {
+		fmt.Fprintf(p.out, "%s: ", question)
+	}
+	line, err := p.in.ReadString('\n')
+	if err != nil && (err != io.EOF || line == "") {
+		if err == io.EOF {
+			return "", fmt.Errorf("no answer for %s", question)
+		}
+		return "", err
+	}
+	if answer := strings.TrimSpace(line); answer != "" {
+		return answer, nil
+	}
+	return def, nil
+}
+
+// askValid asks until the answer passes validate.
+func (p *prompter) askValid(question, def string, validate func(string) error) (string, error) {
+	for {
+		answer, err := p.ask(question, def)
+		if err != nil {
+			return "", err
+		}
+		if answer == "" {
+			fmt.Fprintf(p.out, "  %s is required\n", question)
+			continue
+		}
+		if err := validate(answer); err != nil {
+			fmt.Fprintf(p.out, "  %v\n", err)
+			continue
+		}
+		return answer, nil
+	}
+}
+
+// choose asks until the answer is one of choices, ignoring case.
+func (p *prompter) choose(question string, choices []string, def string) (string, error) {
+	question += " (" + strings.Join(choices, ", ") + ")"
+	for {
+		answer, err := p.ask(question, def)
+		if err != nil {
+			return "", err
+		}
+		for _, choice := range choices {
+			if strings.EqualFold(answer, choice) {
+				return choice, nil
+			}
+		}
+		fmt.Fprintf(p.out, "  choose one of %s\n", strings.Join(choices, ", "))
+	}
+}
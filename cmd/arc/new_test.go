@@ -0,0 +1,111 @@
+package main
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/jomadu/ai-resource-compiler-go/internal/format"
+)
+
+func testPrompter(answers string) *prompter {
+	return &prompter{in: bufio.NewReader(strings.NewReader(answers)), out: io.Discard}
+}
+
+func TestNewResourceRule(t *testing.T) {
+	t.Setenv("VISUAL", "")
+	t.Setenv("EDITOR", "")
+
+	// An invalid ID and enforcement are asked again.
+	p := testPrompter("\nbad id\nnaming\nMeaningful Names\nNames matter\nsometimes\nmust\n**/*.go, **/*.ts\nUse clear names.\n")
+	resource, err := newResource(p)
+	if err != nil {
+		t.Fatalf("newResource() error = %v", err)
+	}
+	if resource.Kind != "Rule" || resource.Metadata.ID != "naming" {
+		t.Fatalf("resource = %s %s, want Rule naming", resource.Kind, resource.Metadata.ID)
+	}
+	rule := resource.Spec.(*format.Rule)
+	if rule.Metadata.Name != "Meaningful Names" || rule.Metadata.Description != "Names matter" {
+		t.Errorf("Metadata = %+v", rule.Metadata)
+	}
+	if rule.Spec.Enforcement != "must" {
+		t.Errorf("Enforcement = %q, want must", rule.Spec.Enforcement)
+	}
+	if len(rule.Spec.Scope) != 1 || strings.Join(rule.Spec.Scope[0].Files, " ") != "**/*.go **/*.ts" {
+		t.Errorf("Scope = %v", rule.Spec.Scope)
+	}
+
+	data, err := encodeResource(resource)
+	if err != nil {
+		t.Fatalf("encodeResource() error = %v", err)
+	}
+	if err := validateNewResource(data); err != nil {
+		t.Errorf("validateNewResource() error = %v", err)
+	}
+}
+
+func TestNewResourceEditor(t *testing.T) {
+	dir := t.TempDir()
+	editor := writeTestFile(t, dir, "editor.sh", "#!/bin/sh\nprintf 'Review the diff.\\n\\nThen summarize it.\\n' > \"$1\"\n")
+	if err := os.Chmod(editor, 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("VISUAL", "")
+	t.Setenv("EDITOR", editor)
+
+	resource, err := newResource(testPrompter("promptset\nreview\nsummary\nSummary\n\n"))
+	if err != nil {
+		t.Fatalf("newResource() error = %v", err)
+	}
+	promptset := resource.Spec.(*format.Promptset)
+	got := format.ResolveBody(promptset.Spec.Prompts["summary"].Body, nil)
+	if got != "Review the diff.\n\nThen summarize it." {
+		t.Errorf("body = %q, want editor content", got)
+	}
+}
+
+func TestNewResourceCommandAndContext(t *testing.T) {
+	t.Setenv("VISUAL", "")
+	t.Setenv("EDITOR", "")
+
+	// An invalid argument name is asked again.
+	resource, err := newResource(testPrompter("command\nreview\nReview\n\nbad name\nfile, focus\nReview ${arg:file} for ${arg:focus}.\n"))
+	if err != nil {
+		t.Fatalf("newResource() error = %v", err)
+	}
+	command := resource.Spec.(*format.Command)
+	if len(command.Spec.Arguments) != 2 || command.Spec.Arguments[0].Name != "file" || command.Spec.Arguments[1].Name != "focus" {
+		t.Errorf("Arguments = %+v, want file and focus", command.Spec.Arguments)
+	}
+	data, err := encodeResource(resource)
+	if err != nil {
+		t.Fatalf("encodeResource() error = %v", err)
+	}
+	if err := validateNewResource(data); err != nil {
+		t.Errorf("validateNewResource() error = %v", err)
+	}
+
+	resource, err = newResource(testPrompter("context\noverview\nOverview\n\nRun go test ./... before committing.\n"))
+	if err != nil {
+		t.Fatalf("newResource() error = %v", err)
+	}
+	if resource.Kind != "Context" || resource.Spec.(*format.Context).Metadata.Name != "Overview" {
+		t.Errorf("resource = %s %+v, want Context overview", resource.Kind, resource.Spec)
+	}
+	if data, err = encodeResource(resource); err != nil {
+		t.Fatalf("encodeResource() error = %v", err)
+	}
+	if err := validateNewResource(data); err != nil {
+		t.Errorf("validateNewResource() error = %v", err)
+	}
+}
+
+func TestNewResourceIncompleteInput(t *testing.T) {
+	_, err := newResource(testPrompter("rule\n"))
+	if err == nil || !strings.Contains(err.Error(), "no answer for ID") {
+		t.Errorf("newResource() error = %v, want missing answer error", err)
+	}
+}
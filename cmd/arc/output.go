@@ -1,43 +1,353 @@
 package main
 
 import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"io/fs"
 	"os"
+	"path"
 	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/jomadu/ai-resource-compiler-go/pkg/compiler"
 )
 
-func outputStdout(allResults []targetResults) error {
+func outputStdout(allResults []targetResults, summary *buildSummary) error {
 	for _, tr := range allResults {
 		for _, result := range tr.results {
 			fmt.Printf("=== %s/%s ===\n", tr.target, result.Path)
-			fmt.Println(result.Content)
+			if utf8.ValidString(result.Content) {
+				fmt.Println(result.Content)
+			} else {
+				fmt.Printf("(binary asset, %d bytes)\n", len(result.Content))
+			}
 			fmt.Println()
+			summary.addResult(tr.target, len(result.Content))
 		}
 	}
 	return nil
 }
 
-func outputFiles(allResults []targetResults, outputDir string, flat bool) error {
+// jsonOutput collects the results printed with -format json, which are
+// written as one array once the build is done, so it always parses.
+type jsonOutput struct {
+	Results []jsonOutputResult
+}
+
+// jsonOutputResult is a result as -format json prints it. Binary assets
+// are base64-encoded, with Encoding set to "base64".
+type jsonOutputResult struct {
+	Target   string `json:"target"`
+	Path     string `json:"path"`
+	Content  string `json:"content"`
+	Encoding string `json:"encoding,omitempty"`
+}
+
+// add appends allResults to the array.
+func (o *jsonOutput) add(allResults []targetResults, summary *buildSummary) {
 	for _, tr := range allResults {
 		for _, result := range tr.results {
-			var filePath string
-			if flat {
-				filePath = filepath.Join(outputDir, result.Path)
-			} else {
-				filePath = filepath.Join(outputDir, tr.target, result.Path)
+			entry := jsonOutputResult{Target: tr.target, Path: result.Path, Content: result.Content}
+			if !utf8.ValidString(result.Content) {
+				entry.Content, entry.Encoding = base64.StdEncoding.EncodeToString([]byte(result.Content)), "base64"
 			}
+			o.Results = append(o.Results, entry)
+			summary.addResult(tr.target, len(result.Content))
+		}
+	}
+}
 
-			dir := filepath.Dir(filePath)
-			if err := os.MkdirAll(dir, 0755); err != nil {
-				return fmt.Errorf("failed to create directory %s: %w", dir, err)
+// write prints the results as an indented JSON array, empty if there are
+// none. A nil jsonOutput prints nothing.
+func (o *jsonOutput) write(w io.Writer) error {
+	if o == nil {
+		return nil
+	}
+	results := o.Results
+	if results == nil {
+		results = []jsonOutputResult{}
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(results)
+}
+
+// outputFiles writes results under outputDir, in per-target subdirectories
+// unless flat is set. Files whose content is already current are left
+// untouched, and guard decides whether other existing files may be
+// overwritten.
+func outputFiles(allResults []targetResults, outputDir string, flat bool, guard *overwriteGuard, links *linker, summary *buildSummary) error {
+	for _, tr := range allResults {
+		for _, result := range tr.results {
+			resultPath := result.Path
+			if !flat {
+				resultPath = tr.target + "/" + result.Path
 			}
+			filePath, changed, err := links.write(outputDir, resultPath, result.Content, guard, summary)
+			if err != nil {
+				return err
+			}
+			guard.written(filePath)
+			if !changed {
+				printUnchanged(filePath)
+				summary.addUnchanged(tr.target)
+				continue
+			}
+			printWrote(filePath)
+			summary.addResult(tr.target, len(result.Content))
+		}
+	}
+	return nil
+}
+
+// outputPaths records the files a build writes and the resource files each
+// was compiled from, so two resources, or two targets sharing a flat output
+// directory, compiled to the same file are caught instead of the last write
+// silently winning.
+type outputPaths struct {
+	files map[string]outputPath // by output directory and result path, case-folded
+}
 
-			if err := os.WriteFile(filePath, []byte(result.Content), 0644); err != nil {
-				return fmt.Errorf("failed to write file %s: %w", filePath, err)
+type outputPath struct {
+	path    string
+	target  string
+	sources []string
+	content string
+}
+
+func newOutputPaths() *outputPaths {
+	return &outputPaths{files: make(map[string]outputPath)}
+}
+
+// claim records the results of allResults, to be written to outputDir, and
+// returns them without those identical to a file already claimed, which are
+// warned about. A result for a claimed file with different content is an
+// error naming the resource files of both. Paths differing only in case
+// collide too, as they would on a case-insensitive file system. A nil
+// outputPaths claims nothing.
+func (o *outputPaths) claim(allResults []targetResults, outputDir string, flat bool, summary *buildSummary) ([]targetResults, error) {
+	if o == nil {
+		return allResults, nil
+	}
+	claimed := make([]targetResults, len(allResults))
+	for i, tr := range allResults {
+		claimed[i] = tr
+		claimed[i].results = nil
+		resultSources := tr.resultSources()
+		for j, result := range tr.results {
+			resultPath := result.Path
+			if !flat {
+				resultPath = tr.target + "/" + result.Path
+			}
+			filePath := filepath.Join(outputDir, filepath.FromSlash(resultPath))
+			key := strings.ToLower(filePath)
+			sources := resultSources[j]
+			previous, ok := o.files[key]
+			if !ok {
+				o.files[key] = outputPath{path: filePath, target: tr.target, sources: sources, content: result.Content}
+				claimed[i].results = append(claimed[i].results, result)
+				continue
 			}
+			if previous.content != result.Content {
+				return nil, fmt.Errorf("%w: %s is compiled differently from %s and %s", compiler.ErrPathCollision, describePaths(previous.path, filePath),
+					describeSources(previous.target, previous.sources), describeSources(tr.target, sources))
+			}
+			summary.warn("%s is compiled identically from %s and %s; writing it once", describePaths(previous.path, filePath),
+				describeSources(previous.target, previous.sources), describeSources(tr.target, sources))
+		}
+	}
+	return claimed, nil
+}
 
-			fmt.Fprintf(os.Stderr, "Wrote %s\n", filePath)
+// resultSources returns the resource files each result of tr was compiled
+// from. Results of several resources held back for merging share a path
+// without being merged when the target does not merge that file; the n-th
+// of them then came from the n-th resource file recorded for the path.
+func (tr targetResults) resultSources() [][]string {
+	count := make(map[string]int)
+	for _, result := range tr.results {
+		count[result.Path]++
+	}
+	seen := make(map[string]int)
+	sources := make([][]string, len(tr.results))
+	for i, result := range tr.results {
+		files := tr.sources[result.Path]
+		if n := count[result.Path]; n > 1 && len(files) == n {
+			files = files[seen[result.Path] : seen[result.Path]+1]
 		}
+		seen[result.Path]++
+		sources[i] = files
 	}
-	return nil
+	return sources
+}
+
+// describePaths names the file two results were compiled to: one path, or
+// both if they differ in case.
+func describePaths(previous, current string) string {
+	if previous == current {
+		return current
+	}
+	return previous + " and " + current
+}
+
+// describeSources names the resource files a result of target was compiled
+// from, e.g. "rules/a.yaml (cursor)".
+func describeSources(target string, sources []string) string {
+	if len(sources) == 0 {
+		return target
+	}
+	return strings.Join(sources, ", ") + " (" + target + ")"
+}
+
+// writeResultFile writes content to resultPath within dir, unless the file
+// already holds it, and returns the file's path and whether it was written.
+// An existing file is only replaced if guard allows it. dir itself may be a
+// symlink, but nothing below it is followed out of it: a symlinked
+// subdirectory or file that points outside dir is an error rather than a
+// write elsewhere on disk.
+func writeResultFile(dir, resultPath, content string, guard *overwriteGuard) (string, bool, error) {
+	filePath, err := resultFilePath(dir, resultPath)
+	if err != nil {
+		return "", false, err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", false, fmt.Errorf("failed to create directory %s: %w", dir, err)
+	}
+	root, err := os.OpenRoot(dir)
+	if err != nil {
+		return "", false, fmt.Errorf("failed to open output directory %s: %w", dir, err)
+	}
+	defer root.Close()
+
+	local := filepath.FromSlash(resultPath)
+	if info, err := root.Lstat(local); err == nil && info.Mode()&fs.ModeSymlink != 0 {
+		// A symlink, as -link makes, is replaced by the file rather than
+		// written through to the file it links to. It is read through root,
+		// so one pointing out of dir is not read but guarded as a file arc
+		// did not generate.
+		existing, err := readRootFile(root, local)
+		if err != nil || !bytes.Equal(existing, []byte(content)) {
+			if err := guard.check(dir, filePath, existing); err != nil {
+				return "", false, err
+			}
+		}
+		if err := root.Remove(local); err != nil {
+			return "", false, fmt.Errorf("failed to replace %s: %w", filePath, err)
+		}
+	} else if existing, err := readRootFile(root, local); err == nil {
+		if bytes.Equal(existing, []byte(content)) {
+			return filePath, false, nil
+		}
+		if err := guard.check(dir, filePath, existing); err != nil {
+			return "", false, err
+		}
+		// Replace the file rather than truncate it, so the files hard
+		// linked to it, as -link makes, keep their content.
+		if err := root.Remove(local); err != nil {
+			return "", false, fmt.Errorf("failed to replace %s: %w", filePath, err)
+		}
+	}
+
+	parent := ""
+	for _, part := range strings.Split(filepath.Dir(local), string(filepath.Separator)) {
+		if part == "." {
+			continue
+		}
+		parent = filepath.Join(parent, part)
+		if err := root.Mkdir(parent, 0755); err != nil && !errors.Is(err, fs.ErrExist) {
+			return "", false, fmt.Errorf("failed to create directory %s: %w", filepath.Join(dir, parent), err)
+		}
+	}
+
+	f, err := root.OpenFile(local, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return "", false, fmt.Errorf("failed to write file %s: %w", filePath, err)
+	}
+	if _, err := f.WriteString(content); err != nil {
+		f.Close()
+		return "", false, fmt.Errorf("failed to write file %s: %w", filePath, err)
+	}
+	if err := f.Close(); err != nil {
+		return "", false, fmt.Errorf("failed to write file %s: %w", filePath, err)
+	}
+	return filePath, true, nil
+}
+
+// generatedMarker marks a file as generated by arc, so builds may overwrite
+// it even where no manifest lists it.
+const generatedMarker = "Generated by arc"
+
+// overwriteGuard keeps builds from overwriting files arc did not generate.
+// An existing file that differs from its result is only overwritten if this
+// build wrote it, a manifest in its output directory lists it, as builds
+// write by default, or it contains generatedMarker. A nil guard, for -force, overwrites anything.
+type overwriteGuard struct {
+	owned map[string]bool // files arc generated, by path
+	read  map[string]bool // output directories whose manifests were read
+}
+
+func newOverwriteGuard() *overwriteGuard {
+	return &overwriteGuard{owned: make(map[string]bool), read: make(map[string]bool)}
+}
+
+// check returns an error unless filePath, a file of the output directory dir
+// holding existing, may be overwritten.
+func (g *overwriteGuard) check(dir, filePath string, existing []byte) error {
+	if g == nil || bytes.Contains(existing, []byte(generatedMarker)) {
+		return nil
+	}
+	if !g.read[dir] {
+		g.read[dir] = true
+		found, err := findManifests([]string{dir})
+		if err != nil {
+			return err
+		}
+		for _, loc := range found {
+			m, err := loc.read()
+			if err != nil {
+				return err
+			}
+			for _, entry := range m.Files {
+				g.owned[filepath.Join(loc.dir, filepath.FromSlash(path.Join(path.Dir(loc.path), entry.Path)))] = true
+			}
+		}
+	}
+	if g.owned[filePath] {
+		return nil
+	}
+	return fmt.Errorf("refusing to overwrite %s, which arc did not generate (no .arc-manifest.json lists it; use -force to overwrite it)", filePath)
+}
+
+// written records that the build wrote filePath, which it may write again.
+func (g *overwriteGuard) written(filePath string) {
+	if g != nil {
+		g.owned[filePath] = true
+	}
+}
+
+// readRootFile reads name within root.
+func readRootFile(root *os.Root, name string) ([]byte, error) {
+	f, err := root.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return io.ReadAll(f)
+}
+
+// resultFilePath returns the file a compilation result is written to in dir.
+// Result paths are slash-separated on every platform; they are converted to
+// the OS separator and must stay within dir.
+func resultFilePath(dir, resultPath string) (string, error) {
+	local := filepath.FromSlash(resultPath)
+	if !filepath.IsLocal(local) {
+		return "", fmt.Errorf("refusing to write result outside output directory: %s", resultPath)
+	}
+	return filepath.Join(dir, local), nil
 }
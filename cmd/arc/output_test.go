@@ -0,0 +1,136 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/jomadu/ai-resource-compiler-go/pkg/compiler"
+)
+
+func TestResultFilePath(t *testing.T) {
+	dir := filepath.Join("out", "claude")
+	got, err := resultFilePath(dir, "testPrompt/SKILL.md")
+	if err != nil {
+		t.Fatalf("resultFilePath() error = %v", err)
+	}
+	if want := filepath.Join("out", "claude", "testPrompt", "SKILL.md"); got != want {
+		t.Errorf("resultFilePath() = %q, want %q", got, want)
+	}
+
+	for _, p := range []string{"../escape.md", "/abs/rule.md", "a/../../escape.md", ""} {
+		if got, err := resultFilePath(dir, p); err == nil {
+			t.Errorf("resultFilePath(%q) = %q, want error", p, got)
+		}
+	}
+}
+
+func TestOutputFilesNestedPaths(t *testing.T) {
+	dir := t.TempDir()
+	results := []targetResults{{target: "claude", results: []compiler.CompilationResult{
+		{Path: "testPrompt/SKILL.md", Content: "skill"},
+	}}}
+	if err := outputFiles(results, dir, false, nil, nil, nil); err != nil {
+		t.Fatalf("outputFiles() error = %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "claude", "testPrompt", "SKILL.md"))
+	if err != nil || string(data) != "skill" {
+		t.Errorf("written file = %q, %v", data, err)
+	}
+
+	escape := []targetResults{{target: "claude", results: []compiler.CompilationResult{{Path: "../x.md"}}}}
+	if err := outputFiles(escape, dir, true, nil, nil, nil); err == nil {
+		t.Error("outputFiles() wrote a result outside the output directory")
+	}
+}
+
+func TestOutputFilesSymlinks(t *testing.T) {
+	base := t.TempDir()
+	outside := filepath.Join(base, "outside")
+	real := filepath.Join(base, "real")
+	for _, dir := range []string{outside, filepath.Join(real, "claude")} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Symlink(real, filepath.Join(base, "out")); err != nil {
+		t.Skipf("symlinks not supported: %v", err)
+	}
+	if err := os.Symlink(outside, filepath.Join(real, "claude", "linked")); err != nil {
+		t.Fatal(err)
+	}
+
+	// A symlinked output directory is written through.
+	results := []targetResults{{target: "claude", results: []compiler.CompilationResult{
+		{Path: "testPrompt/SKILL.md", Content: "skill"},
+	}}}
+	if err := outputFiles(results, filepath.Join(base, "out"), false, nil, nil, nil); err != nil {
+		t.Fatalf("outputFiles() error = %v", err)
+	}
+	if data, err := os.ReadFile(filepath.Join(real, "claude", "testPrompt", "SKILL.md")); err != nil || string(data) != "skill" {
+		t.Errorf("written file = %q, %v", data, err)
+	}
+
+	// A symlink inside it that leads elsewhere is not.
+	escape := []targetResults{{target: "claude", results: []compiler.CompilationResult{
+		{Path: "linked/SKILL.md", Content: "skill"},
+	}}}
+	if err := outputFiles(escape, real, false, nil, nil, nil); err == nil {
+		t.Error("outputFiles() followed a symlink out of the output directory")
+	}
+	if _, err := os.Stat(filepath.Join(outside, "SKILL.md")); err == nil {
+		t.Error("outputFiles() wrote outside the output directory")
+	}
+
+	// A result file symlinked out of it is not read, but refused like a
+	// file arc did not generate.
+	secret := filepath.Join(outside, "secret.md")
+	if err := os.WriteFile(secret, []byte("<!-- "+generatedMarker+" -->\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(secret, filepath.Join(real, "claude", "secret.md")); err != nil {
+		t.Fatal(err)
+	}
+	leak := []targetResults{{target: "claude", results: []compiler.CompilationResult{
+		{Path: "secret.md", Content: "rule"},
+	}}}
+	if err := outputFiles(leak, real, false, newOverwriteGuard(), nil, nil); err == nil || !strings.Contains(err.Error(), "refusing to overwrite") {
+		t.Errorf("outputFiles() over a symlink out of the output directory error = %v, want refusal", err)
+	}
+	if data, _ := os.ReadFile(secret); !strings.Contains(string(data), generatedMarker) {
+		t.Errorf("file outside the output directory changed: %q", data)
+	}
+}
+
+func TestJSONOutput(t *testing.T) {
+	var o *jsonOutput
+	var b strings.Builder
+	if err := o.write(&b); err != nil || b.Len() != 0 {
+		t.Fatalf("nil jsonOutput wrote %q, %v", b.String(), err)
+	}
+
+	o = &jsonOutput{}
+	if err := o.write(&b); err != nil || b.String() != "[]\n" {
+		t.Fatalf("empty jsonOutput wrote %q, %v, want []", b.String(), err)
+	}
+
+	b.Reset()
+	o.add([]targetResults{{target: "cursor", results: []compiler.CompilationResult{{Path: "a.mdc", Content: "Use <b>"}}}}, nil)
+	if err := o.write(&b); err != nil {
+		t.Fatalf("write() error = %v", err)
+	}
+	var got []map[string]string
+	if err := json.Unmarshal([]byte(b.String()), &got); err != nil {
+		t.Fatalf("output is not JSON: %v\n%s", err, b.String())
+	}
+	want := map[string]string{"target": "cursor", "path": "a.mdc", "content": "Use <b>"}
+	if len(got) != 1 || !reflect.DeepEqual(got[0], want) {
+		t.Errorf("output = %v, want [%v]", got, want)
+	}
+	if !strings.Contains(b.String(), "<b>") {
+		t.Errorf("content was HTML-escaped:\n%s", b.String())
+	}
+}
@@ -0,0 +1,28 @@
+package main
+
+import "testing"
+
+func TestResultFilePathWindows(t *testing.T) {
+	tests := []struct {
+		dir, path, want string
+	}{
+		{`C:\repo\.claude\skills`, "testPrompt/SKILL.md", `C:\repo\.claude\skills\testPrompt\SKILL.md`},
+		{`D:\out`, "org/team/rules_names.mdc", `D:\out\org\team\rules_names.mdc`},
+		{`\\server\share\out`, "names.md", `\\server\share\out\names.md`},
+	}
+	for _, tt := range tests {
+		got, err := resultFilePath(tt.dir, tt.path)
+		if err != nil {
+			t.Fatalf("resultFilePath(%q, %q) error = %v", tt.dir, tt.path, err)
+		}
+		if got != tt.want {
+			t.Errorf("resultFilePath(%q, %q) = %q, want %q", tt.dir, tt.path, got, tt.want)
+		}
+	}
+
+	for _, p := range []string{`C:/rules/names.md`, `C:names.md`, `..\escape.md`, "NUL"} {
+		if got, err := resultFilePath(`C:\out`, p); err == nil {
+			t.Errorf("resultFilePath(%q) = %q, want error", p, got)
+		}
+	}
+}
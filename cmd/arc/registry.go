@@ -0,0 +1,132 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/jomadu/ai-resource-compiler-go/pkg/bundle"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+func runPublish(args []string) error {
+	fs := flag.NewFlagSet("publish", flag.ContinueOnError)
+	var annotations arrayFlags
+	fs.Var(&annotations, "annotation", "Manifest annotation as key=value (repeatable)")
+	plainHTTP := fs.Bool("plain-http", false, "Use HTTP instead of HTTPS to reach the registry")
+	compiled := fs.Bool("compiled", false, "Publish compiled files, and the files under directories, instead of resource files")
+
+	positional, err := parseInterspersed(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) < 2 {
+		if *compiled {
+			return fmt.Errorf("registry reference and at least one compiled file or directory required")
+		}
+		return fmt.Errorf("registry reference and at least one resource file required")
+	}
+	ref, files := positional[0], positional[1:]
+
+	repo, err := bundle.Repository(ref, *plainHTTP)
+	if err != nil {
+		return err
+	}
+	tag := repo.Reference.Reference
+	if tag == "" || strings.Contains(tag, ":") {
+		return fmt.Errorf("registry reference must include a version tag: %s", ref)
+	}
+
+	manifestAnnotations := map[string]string{ocispec.AnnotationVersion: tag}
+	for _, a := range annotations {
+		key, value, ok := strings.Cut(a, "=")
+		if !ok || key == "" {
+			return fmt.Errorf("invalid annotation %q (expected key=value)", a)
+		}
+		manifestAnnotations[key] = value
+	}
+
+	push := bundle.Push
+	var bundleFiles []bundle.File
+	if *compiled {
+		push = bundle.PushCompiled
+		if bundleFiles, err = readCompiledFiles(files); err != nil {
+			return err
+		}
+	} else {
+		for _, file := range files {
+			data, err := os.ReadFile(file)
+			if err != nil {
+				return fmt.Errorf("failed to read resource file: %w", err)
+			}
+			bundleFiles = append(bundleFiles, bundle.File{Name: file, Data: data})
+		}
+	}
+
+	desc, err := push(interrupted, repo, tag, bundleFiles, manifestAnnotations)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "Published %s (%s)\n", ref, desc.Digest)
+	return nil
+}
+
+// readCompiledFiles reads the files at paths, and those under the
+// directories among them, for a compiled bundle. Each keeps the path it
+// has from the working directory, so that pulling the bundle into a project
+// puts it where arc build would have.
+func readCompiledFiles(paths []string) ([]bundle.File, error) {
+	var files []bundle.File
+	for _, p := range paths {
+		err := filepath.WalkDir(p, func(file string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() {
+				return err
+			}
+			data, err := os.ReadFile(file)
+			if err != nil {
+				return fmt.Errorf("failed to read compiled file: %w", err)
+			}
+			files = append(files, bundle.File{Name: file, Data: data})
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return files, nil
+}
+
+func runPull(args []string) error {
+	fs := flag.NewFlagSet("pull", flag.ContinueOnError)
+	output := fs.String("o", ".", "Directory to write the bundle's resource or compiled files to")
+	plainHTTP := fs.Bool("plain-http", false, "Use HTTP instead of HTTPS to reach the registry")
+
+	positional, err := parseInterspersed(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 1 {
+		return fmt.Errorf("exactly one registry reference required")
+	}
+	ref := positional[0]
+
+	repo, err := bundle.Repository(ref, *plainHTTP)
+	if err != nil {
+		return err
+	}
+	if repo.Reference.Reference == "" {
+		return fmt.Errorf("registry reference must include a version tag or digest: %s", ref)
+	}
+
+	written, err := bundle.Pull(interrupted, repo, repo.Reference.Reference, *output)
+	if err != nil {
+		return err
+	}
+	for _, path := range written {
+		printWrote(path)
+	}
+	return nil
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestReadCompiledFiles(t *testing.T) {
+	t.Chdir(t.TempDir())
+	for _, file := range []string{".cursor/rules/naming.mdc", ".cursor/rules/go/errors.mdc", "AGENTS.md"} {
+		if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(file, []byte(file), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	files, err := readCompiledFiles([]string{".cursor", "AGENTS.md"})
+	if err != nil {
+		t.Fatalf("readCompiledFiles() error = %v", err)
+	}
+	want := []string{".cursor/rules/go/errors.mdc", ".cursor/rules/naming.mdc", "AGENTS.md"}
+	if len(files) != len(want) {
+		t.Fatalf("readCompiledFiles() = %d files, want %v", len(files), want)
+	}
+	for i, name := range want {
+		if filepath.ToSlash(files[i].Name) != name || string(files[i].Data) != name {
+			t.Errorf("files[%d] = %s %q, want %s", i, files[i].Name, files[i].Data, name)
+		}
+	}
+
+	if _, err := readCompiledFiles([]string{"missing"}); err == nil {
+		t.Error("readCompiledFiles() expected error for a missing path")
+	}
+}
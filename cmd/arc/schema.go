@@ -0,0 +1,31 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/jomadu/ai-resource-compiler-go/pkg/resource"
+)
+
+func runSchema(args []string) error {
+	fs := flag.NewFlagSet("schema", flag.ContinueOnError)
+	schemaFormat := fs.String("format", "json", "Schema language: json (JSON Schema) or cue")
+	addGlobalFlags(fs)
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return fmt.Errorf("unexpected argument: %s", fs.Arg(0))
+	}
+	switch *schemaFormat {
+	case "json":
+		_, err := os.Stdout.Write(resource.MarshalJSONSchema())
+		return err
+	case "cue":
+		_, err := fmt.Fprint(os.Stdout, resource.CUESchema(""))
+		return err
+	}
+	return fmt.Errorf("unknown format: %s (valid formats: json, cue)", *schemaFormat)
+}
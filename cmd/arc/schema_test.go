@@ -0,0 +1,15 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSchemaErrors(t *testing.T) {
+	if err := runSchema([]string{"-format", "xsd"}); err == nil || !strings.Contains(err.Error(), "unknown format") {
+		t.Errorf("runSchema() error = %v, want unknown format error", err)
+	}
+	if err := runSchema([]string{"extra"}); err == nil || !strings.Contains(err.Error(), "unexpected argument") {
+		t.Errorf("runSchema() error = %v, want argument error", err)
+	}
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/jomadu/ai-resource-compiler-go/internal/format"
+	"github.com/jomadu/ai-resource-compiler-go/pkg/compiler"
+)
+
+func runSplit(args []string) error {
+	fs := flag.NewFlagSet("split", flag.ContinueOnError)
+	output := fs.String("o", ".", "Output directory for the standalone rule files")
+
+	files, err := parseInterspersed(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(files) != 1 {
+		return fmt.Errorf("exactly one ruleset file required")
+	}
+
+	resource, err := loadResource(files[0])
+	if err != nil {
+		return err
+	}
+	if resource.Kind != "Ruleset" {
+		return fmt.Errorf("%s: expected kind Ruleset, got %s", files[0], resource.Kind)
+	}
+
+	rules, err := splitRuleset(resource.Spec.(*format.Ruleset))
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(*output, 0755); err != nil {
+		return fmt.Errorf("failed to create directory %s: %w", *output, err)
+	}
+	for _, rule := range rules {
+		data, err := encodeResource(rule)
+		if err != nil {
+			return err
+		}
+		filePath := filepath.Join(*output, rule.Metadata.ID+".yaml")
+		if err := os.WriteFile(filePath, data, 0644); err != nil {
+			return fmt.Errorf("failed to write file %s: %w", filePath, err)
+		}
+		printWrote(filePath)
+	}
+	return nil
+}
+
+// splitRuleset breaks a ruleset into one standalone Rule resource per entry,
+// ordered by rule ID. Each rule receives a copy of only the ruleset fragments
+// its body references, so the emitted files are self-contained.
+func splitRuleset(ruleset *format.Ruleset) ([]*compiler.Resource, error) {
+	ids := make([]string, 0, len(ruleset.Spec.Rules))
+	for id := range ruleset.Spec.Rules {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	var resources []*compiler.Resource
+	for _, id := range ids {
+		if err := format.ValidateID(id); err != nil {
+			return nil, err
+		}
+		item := ruleset.Spec.Rules[id]
+
+		var fragments map[string]string
+		for _, ref := range item.Body.Array {
+			if !strings.HasPrefix(ref, "$") {
+				continue
+			}
+			key := strings.TrimPrefix(ref, "$")
+			content, ok := ruleset.Spec.Fragments[key]
+			if !ok {
+				continue
+			}
+			if fragments == nil {
+				fragments = make(map[string]string)
+			}
+			fragments[key] = content
+		}
+
+		rule := &format.Rule{
+			Metadata: format.Metadata{
+				ID:          id,
+				Namespace:   ruleset.Metadata.Namespace,
+				Name:        item.Name,
+				Description: item.Description,
+			},
+			Spec: format.RuleSpec{
+				Enforcement: item.Enforcement,
+				Scope:       item.Scope,
+				Body:        item.Body,
+				Fragments:   fragments,
+			},
+		}
+
+		resource := &compiler.Resource{
+			APIVersion: "ai-resource/draft",
+			Kind:       "Rule",
+			Spec:       rule,
+		}
+		resource.Metadata.ID = id
+		resource.Metadata.Namespace = ruleset.Metadata.Namespace
+		resources = append(resources, resource)
+	}
+	return resources, nil
+}
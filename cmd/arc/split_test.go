@@ -0,0 +1,125 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jomadu/ai-resource-compiler-go/internal/format"
+)
+
+const splitRulesetYAML = `apiVersion: ai-resource/draft
+kind: Ruleset
+metadata:
+  id: cleanCode
+  name: Clean Code
+spec:
+  fragments:
+    shared: Shared text
+    unused: Never referenced
+  rules:
+    meaningfulNames:
+      name: Use Meaningful Names
+      description: Names reveal intent
+      enforcement: must
+      scope:
+        - files: ["**/*.ts"]
+      body:
+        - Intro
+        - $shared
+    smallFunctions:
+      name: Keep Functions Small
+      enforcement: should
+      body: Functions should be small.
+`
+
+func TestSplitWritesRules(t *testing.T) {
+	dir := t.TempDir()
+	in := writeTestFile(t, dir, "ruleset.yaml", splitRulesetYAML)
+	outDir := filepath.Join(dir, "rules")
+
+	if err := runSplit([]string{in, "-o", outDir}); err != nil {
+		t.Fatalf("runSplit() error = %v", err)
+	}
+
+	resource, err := loadResource(filepath.Join(outDir, "meaningfulNames.yaml"))
+	if err != nil {
+		t.Fatalf("loadResource() error = %v", err)
+	}
+	if resource.Kind != "Rule" {
+		t.Fatalf("Kind = %v, want Rule", resource.Kind)
+	}
+
+	rule := resource.Spec.(*format.Rule)
+	if rule.Metadata.Name != "Use Meaningful Names" || rule.Metadata.Description != "Names reveal intent" {
+		t.Errorf("metadata not preserved: %+v", rule.Metadata)
+	}
+	if rule.Spec.Enforcement != "must" || len(rule.Spec.Scope) != 1 {
+		t.Errorf("spec not preserved: %+v", rule.Spec)
+	}
+	if len(rule.Spec.Fragments) != 1 || rule.Spec.Fragments["shared"] != "Shared text" {
+		t.Errorf("Fragments = %v, want only the referenced fragment", rule.Spec.Fragments)
+	}
+
+	data, err := os.ReadFile(filepath.Join(outDir, "smallFunctions.yaml"))
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	if strings.Contains(string(data), "fragments") {
+		t.Errorf("rule without fragment references should not carry fragments:\n%s", data)
+	}
+}
+
+func TestSplitMergeRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	in := writeTestFile(t, dir, "cleanCode.yaml", splitRulesetYAML)
+	outDir := filepath.Join(dir, "rules")
+
+	if err := runSplit([]string{in, "-o", outDir}); err != nil {
+		t.Fatalf("runSplit() error = %v", err)
+	}
+
+	merged := filepath.Join(dir, "cleanCode-merged.yaml")
+	err := runMerge([]string{
+		filepath.Join(outDir, "meaningfulNames.yaml"),
+		filepath.Join(outDir, "smallFunctions.yaml"),
+		"-id", "cleanCode",
+		"-o", merged,
+	})
+	if err != nil {
+		t.Fatalf("runMerge() error = %v", err)
+	}
+
+	original, err := loadResource(in)
+	if err != nil {
+		t.Fatalf("loadResource() error = %v", err)
+	}
+	roundTrip, err := loadResource(merged)
+	if err != nil {
+		t.Fatalf("loadResource() error = %v", err)
+	}
+
+	want := original.Spec.(*format.Ruleset)
+	got := roundTrip.Spec.(*format.Ruleset)
+	for id, item := range want.Spec.Rules {
+		wantBody := format.ResolveBody(item.Body, want.Spec.Fragments)
+		gotBody := format.ResolveBody(got.Spec.Rules[id].Body, got.Spec.Fragments)
+		if wantBody != gotBody {
+			t.Errorf("rule %s body = %q, want %q", id, gotBody, wantBody)
+		}
+	}
+}
+
+func TestSplitErrorNonRulesetKind(t *testing.T) {
+	dir := t.TempDir()
+	resourceFile := createTestResource(t, dir)
+
+	err := runSplit([]string{resourceFile, "-o", dir})
+	if err == nil {
+		t.Fatal("Expected error for non-Ruleset input, got nil")
+	}
+	if !strings.Contains(err.Error(), "expected kind Ruleset") {
+		t.Errorf("Expected 'expected kind Ruleset' error, got: %v", err)
+	}
+}
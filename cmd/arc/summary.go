@@ -0,0 +1,99 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/jomadu/ai-resource-compiler-go/internal/format"
+	"github.com/jomadu/ai-resource-compiler-go/pkg/compiler"
+)
+
+// buildSummary counts what a batch compile did. A nil summary counts
+// nothing, so single-file compiles can share the output code.
+type buildSummary struct {
+	Resources int            `json:"resources"`
+	Results   map[string]int `json:"results"`   // results per target
+	Bytes     int            `json:"bytes"`     // bytes written, excluding unchanged files
+	Unchanged int            `json:"unchanged"` // files skipped because their content was current
+	Warnings  int            `json:"warnings"`
+}
+
+func newBuildSummary() *buildSummary {
+	return &buildSummary{Results: make(map[string]int)}
+}
+
+// addResult records a result for target and the bytes written for it.
+func (s *buildSummary) addResult(target string, written int) {
+	if s == nil {
+		return
+	}
+	s.Results[target]++
+	s.Bytes += written
+}
+
+// addUnchanged records a result for target whose file was already current.
+func (s *buildSummary) addUnchanged(target string) {
+	if s == nil {
+		return
+	}
+	s.Results[target]++
+	s.Unchanged++
+}
+
+// warn reports a problem that does not stop the compile.
+func (s *buildSummary) warn(format string, args ...any) {
+	fmt.Fprintf(os.Stderr, "%s %s\n", colorize(os.Stderr, colorYellow, "Warning:"), fmt.Sprintf(format, args...))
+	if s != nil {
+		s.Warnings++
+	}
+}
+
+// writeSummary prints the summary as a single line, e.g.
+//
+//	Summary: 2 resource(s), 4 result(s) (claude 2, cursor 2), 5120 bytes written, 1 unchanged, 0 warning(s)
+func writeSummary(w io.Writer, s *buildSummary) {
+	total := 0
+	var perTarget []string
+	for _, target := range format.SortedKeys(s.Results) {
+		total += s.Results[target]
+		perTarget = append(perTarget, fmt.Sprintf("%s %d", target, s.Results[target]))
+	}
+	results := fmt.Sprintf("%d result(s)", total)
+	if len(perTarget) > 0 {
+		results += " (" + strings.Join(perTarget, ", ") + ")"
+	}
+
+	warnings := fmt.Sprintf("%d warning(s)", s.Warnings)
+	if s.Warnings > 0 {
+		warnings = colorize(w, colorYellow, warnings)
+	}
+	fmt.Fprintf(w, "Summary: %d resource(s), %s, %d bytes written, %d unchanged, %s\n",
+		s.Resources, results, s.Bytes, s.Unchanged, warnings)
+}
+
+// writeSummaryJSON writes the summary as JSON to path.
+func writeSummaryJSON(path string, s *buildSummary) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write summary %s: %w", path, err)
+	}
+	return nil
+}
+
+// writeStatsFile writes compile metrics as JSON to path.
+func writeStatsFile(path string, stats *compiler.Stats) error {
+	data, err := json.MarshalIndent(stats, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write stats %s: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestBuildSummary(t *testing.T) {
+	dir := t.TempDir()
+	rule := createTestResource(t, dir)
+	empty := writeTestFile(t, dir, "empty.yaml", "apiVersion: ai-resource/draft\nkind: Ruleset\nmetadata:\n  id: empty\nspec:\n  rules: {}\n")
+	out := filepath.Join(dir, "out")
+	summaryFile := filepath.Join(dir, "summary.json")
+
+	args := []string{"-target", "cursor", "-target", "claude", "-output", out, "-summary-json", summaryFile, rule, empty}
+	if err := runBuild(args); err != nil {
+		t.Fatalf("runBuild() error = %v", err)
+	}
+	first := readSummary(t, summaryFile)
+	if first.Resources != 2 || first.Results["cursor"] != 1 || first.Results["claude"] != 1 || first.Bytes == 0 || first.Unchanged != 0 {
+		t.Errorf("first summary = %+v", first)
+	}
+	if first.Warnings != 2 {
+		t.Errorf("warnings = %d, want one per target for the empty ruleset", first.Warnings)
+	}
+
+	if err := runBuild(args); err != nil {
+		t.Fatalf("runBuild() error = %v", err)
+	}
+	second := readSummary(t, summaryFile)
+	if second.Unchanged != 2 || second.Bytes != 0 {
+		t.Errorf("second summary = %+v, want both files unchanged", second)
+	}
+}
+
+func readSummary(t *testing.T, path string) buildSummary {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var s buildSummary
+	if err := json.Unmarshal(data, &s); err != nil {
+		t.Fatalf("summary is not JSON: %v\n%s", err, data)
+	}
+	return s
+}
+
+func TestWriteSummary(t *testing.T) {
+	s := newBuildSummary()
+	s.Resources = 2
+	s.addResult("cursor", 100)
+	s.addResult("claude", 50)
+	s.addUnchanged("cursor")
+
+	var buf bytes.Buffer
+	writeSummary(&buf, s)
+	want := "Summary: 2 resource(s), 3 result(s) (claude 1, cursor 2), 150 bytes written, 1 unchanged, 0 warning(s)\n"
+	if got := buf.String(); got != want {
+		t.Errorf("writeSummary() = %q, want %q", got, want)
+	}
+}
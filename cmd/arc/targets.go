@@ -0,0 +1,65 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/jomadu/ai-resource-compiler-go/internal/format"
+	"github.com/jomadu/ai-resource-compiler-go/pkg/compiler"
+)
+
+func runTargets(args []string) error {
+	fs := flag.NewFlagSet("targets", flag.ContinueOnError)
+	configPath := fs.String("config", "", configFlagUsage)
+	addGlobalFlags(fs)
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return fmt.Errorf("unexpected arguments: %s", strings.Join(fs.Args(), " "))
+	}
+
+	var aliases map[string]targetAlias
+	if path := findConfigFile(*configPath); path != "" {
+		ws, err := loadWorkspaceConfig(path)
+		if err != nil {
+			return err
+		}
+		if aliases, err = ws.targetAliases(); err != nil {
+			return err
+		}
+	}
+
+	writeTargets(os.Stdout, newCompiler(), aliases)
+	return nil
+}
+
+// writeTargets lists the targets registered with c, the workspace aliases,
+// and the resource kinds, e.g.
+//
+//	TARGET  VERSIONS           OUTPUT
+//	gemini  ai-resource/draft  shared files
+//	json    ai-resource/draft  file per rule or prompt
+func writeTargets(w io.Writer, c *compiler.Compiler, aliases map[string]targetAlias) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "TARGET\tVERSIONS\tOUTPUT")
+	for _, target := range c.Targets() {
+		output := "file per rule or prompt"
+		if c.Merges(target) {
+			output = "shared files"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", target, strings.Join(c.SupportedVersions(target), ", "), output)
+	}
+	for _, name := range format.SortedKeys(aliases) {
+		alias := aliases[name]
+		target, _ := parseTarget(alias.Target) // checked by targetAliases
+		fmt.Fprintf(tw, "%s\t%s\talias of %s\n", name, strings.Join(c.SupportedVersions(target), ", "), alias.Target)
+	}
+	tw.Flush()
+	fmt.Fprintf(w, "\nKinds: %s\n", strings.Join(compiler.Kinds(), ", "))
+}
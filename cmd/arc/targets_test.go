@@ -0,0 +1,31 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/jomadu/ai-resource-compiler-go/pkg/compiler"
+)
+
+func TestWriteTargets(t *testing.T) {
+	var buf bytes.Buffer
+	aliases := map[string]targetAlias{"cursor-strict": {Target: "cursor"}}
+	writeTargets(&buf, compiler.NewCompiler(), aliases)
+	out := buf.String()
+
+	for _, target := range builtinTargets {
+		if !strings.Contains(out, "\n"+target+" ") {
+			t.Errorf("output missing target %s:\n%s", target, out)
+		}
+	}
+	for _, want := range []string{
+		"shared files",
+		"alias of cursor",
+		"Kinds: Rule, Ruleset, Prompt, Promptset, Command, Context",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+}
@@ -0,0 +1,237 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/jomadu/ai-resource-compiler-go/internal/format"
+	"github.com/jomadu/ai-resource-compiler-go/pkg/loader"
+)
+
+// defaultGoldenDir holds the golden files checked by arc test when -golden is
+// not given.
+const defaultGoldenDir = "testdata/arc"
+
+// goldenMismatch is a golden file that does not match what arc compiles.
+type goldenMismatch struct {
+	Path   string
+	Reason string
+}
+
+func runTest(args []string) error {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	var targets arrayFlags
+	fs.Var(&targets, "target", "Target format to test (repeatable, overrides config)")
+	configPath := fs.String("config", "", configFlagUsage)
+	profile := fs.String("profile", "", "Workspace config profile to test")
+	locale := fs.String("locale", "", "Test the body variant for this locale (overrides config)")
+	golden := fs.String("golden", defaultGoldenDir, "Directory of golden files, laid out as <target>/<path>")
+	update := fs.Bool("update", false, "Rewrite the golden files from the current output")
+
+	files, err := parseInterspersed(fs, args)
+	if err != nil {
+		return err
+	}
+
+	var settings buildSettings
+	var aliases map[string]targetAlias
+	lockPath := loader.LockfileName
+	path := findConfigFile(*configPath)
+	if path != "" {
+		ws, err := loadWorkspaceConfig(path)
+		if err != nil {
+			return err
+		}
+		lockPath = filepath.Join(ws.dir, loader.LockfileName)
+		if settings, err = ws.resolve(*profile); err != nil {
+			return err
+		}
+		if aliases, err = ws.targetAliases(); err != nil {
+			return err
+		}
+	} else if *profile != "" {
+		return fmt.Errorf("-profile requires a workspace config (%s)", defaultConfigFile)
+	}
+
+	cfg := settings.buildConfig(aliases)
+	if len(targets) > 0 {
+		cfg.Targets = targets
+	}
+	if *locale != "" {
+		cfg.Locale = *locale
+	}
+	// Tests must not depend on the network changing: remote includes have
+	// to be pinned already.
+	if cfg.Lock, err = loader.ReadLockfile(lockPath); err != nil {
+		return err
+	}
+	cfg.Lock.Frozen = true
+
+	// Golden files no resource produces are only reported when every
+	// configured resource is tested.
+	checkStale := len(files) == 0
+	if len(files) == 0 {
+		files = settings.Resources
+	}
+	if files, err = expandResources(files); err != nil {
+		return err
+	}
+	if len(files) == 0 {
+		return fmt.Errorf("no resource files (pass files or set resources in %s)", defaultConfigFile)
+	}
+	if len(cfg.Targets) == 0 {
+		return fmt.Errorf("at least one target required (use -target or set targets in %s)", defaultConfigFile)
+	}
+
+	cfg.Rulesets = buildRulesets(files, cfg)
+	var compiled []targetResults
+	pending := &pendingResults{}
+	for _, file := range files {
+		allResults, err := compileTargets(file, cfg)
+		if err != nil {
+			return &fileError{File: file, Err: err}
+		}
+		for _, tr := range allResults {
+			if tr.merge != nil {
+				pending.add(tr)
+			} else {
+				compiled = append(compiled, tr)
+			}
+		}
+	}
+	// Merging targets write files shared by every resource.
+	merged, err := pending.merged()
+	if err != nil {
+		return err
+	}
+
+	want := make(map[string]string)
+	for _, tr := range append(compiled, merged...) {
+		for _, result := range tr.results {
+			goldenPath, err := resultFilePath(filepath.Join(*golden, tr.target), result.Path)
+			if err != nil {
+				return err
+			}
+			want[goldenPath] = result.Content
+		}
+	}
+
+	var stale []string
+	if checkStale {
+		if stale, err = staleGoldenFiles(*golden, cfg.Targets, want); err != nil {
+			return err
+		}
+	}
+
+	if *update {
+		return updateGoldenFiles(want, stale)
+	}
+	mismatches := checkGoldenFiles(want, stale)
+	writeTestReport(os.Stdout, len(want), mismatches)
+	if len(mismatches) > 0 {
+		return fmt.Errorf("%d golden file(s) differ (run arc test -update to accept the changes)", len(mismatches))
+	}
+	return nil
+}
+
+// staleGoldenFiles returns files under the golden directories of targets
+// that are not in want.
+func staleGoldenFiles(golden string, targets []string, want map[string]string) ([]string, error) {
+	var stale []string
+	for _, target := range targets {
+		err := filepath.WalkDir(filepath.Join(golden, target), func(path string, d fs.DirEntry, err error) error {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			if err != nil || d.IsDir() {
+				return err
+			}
+			if _, ok := want[path]; !ok {
+				stale = append(stale, path)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return stale, nil
+}
+
+// checkGoldenFiles compares each golden file with its compiled content.
+func checkGoldenFiles(want map[string]string, stale []string) []goldenMismatch {
+	var mismatches []goldenMismatch
+	for _, path := range format.SortedKeys(want) {
+		existing, err := os.ReadFile(path)
+		switch {
+		case errors.Is(err, fs.ErrNotExist):
+			mismatches = append(mismatches, goldenMismatch{Path: path, Reason: "missing golden file"})
+		case err != nil:
+			mismatches = append(mismatches, goldenMismatch{Path: path, Reason: err.Error()})
+		case !bytes.Equal(existing, []byte(want[path])):
+			mismatches = append(mismatches, goldenMismatch{Path: path, Reason: firstDifference(string(existing), want[path])})
+		}
+	}
+	for _, path := range stale {
+		mismatches = append(mismatches, goldenMismatch{Path: path, Reason: "golden file is no longer produced"})
+	}
+	return mismatches
+}
+
+// updateGoldenFiles writes want and removes stale golden files.
+func updateGoldenFiles(want map[string]string, stale []string) error {
+	for _, path := range format.SortedKeys(want) {
+		if existing, err := os.ReadFile(path); err == nil && bytes.Equal(existing, []byte(want[path])) {
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return fmt.Errorf("failed to create directory %s: %w", filepath.Dir(path), err)
+		}
+		if err := os.WriteFile(path, []byte(want[path]), 0644); err != nil {
+			return fmt.Errorf("failed to write file %s: %w", path, err)
+		}
+		printWrote(path)
+	}
+	for _, path := range stale {
+		if err := os.Remove(path); err != nil {
+			return fmt.Errorf("failed to remove stale golden file %s: %w", path, err)
+		}
+		fmt.Fprintf(os.Stderr, "Removed %s\n", path)
+	}
+	return nil
+}
+
+// firstDifference describes the first line at which a golden file differs
+// from the compiled content.
+func firstDifference(golden, compiled string) string {
+	goldenLines, compiledLines := strings.Split(golden, "\n"), strings.Split(compiled, "\n")
+	for i := 0; i < len(goldenLines) || i < len(compiledLines); i++ {
+		if i >= len(goldenLines) || i >= len(compiledLines) || goldenLines[i] != compiledLines[i] {
+			var g, c string
+			if i < len(goldenLines) {
+				g = goldenLines[i]
+			}
+			if i < len(compiledLines) {
+				c = compiledLines[i]
+			}
+			return fmt.Sprintf("line %d: golden %q, compiled %q", i+1, g, c)
+		}
+	}
+	return "content differs"
+}
+
+func writeTestReport(w io.Writer, checked int, mismatches []goldenMismatch) {
+	for _, m := range mismatches {
+		fmt.Fprintf(w, "%s %s: %s\n", colorize(w, colorRed, "FAIL"), filepath.ToSlash(m.Path), m.Reason)
+	}
+	if len(mismatches) == 0 {
+		fmt.Fprintf(w, "%s %d golden file(s) match\n", colorize(w, colorGreen, "ok"), checked)
+	}
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRunTestGoldenFiles(t *testing.T) {
+	dir := t.TempDir()
+	rule := createTestResource(t, dir)
+	golden := filepath.Join(dir, "golden")
+	args := []string{"-target", "cursor", "-golden", golden, rule}
+
+	if err := runTest(args); err == nil || !strings.Contains(err.Error(), "1 golden file(s) differ") {
+		t.Fatalf("runTest() error = %v, want missing golden file", err)
+	}
+	if err := runTest(append([]string{"-update"}, args...)); err != nil {
+		t.Fatalf("runTest(-update) error = %v", err)
+	}
+	matches, _ := filepath.Glob(filepath.Join(golden, "cursor", "rules", "*.mdc"))
+	if len(matches) != 1 {
+		t.Fatalf("golden files = %v, want one cursor rule", matches)
+	}
+	if err := runTest(args); err != nil {
+		t.Fatalf("runTest() error = %v after update", err)
+	}
+
+	if err := os.WriteFile(matches[0], []byte("edited\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := runTest(args); err == nil {
+		t.Error("runTest() passed with an edited golden file")
+	}
+}
+
+func TestRunTestStaleGoldenFiles(t *testing.T) {
+	dir := t.TempDir()
+	rule := createTestResource(t, dir)
+	golden := filepath.Join(dir, "golden")
+	config := writeTestFile(t, dir, "arc.yaml", "resources:\n  - "+rule+"\ntargets: [kiro]\n")
+	args := []string{"-config", config, "-golden", golden}
+
+	if err := runTest(append([]string{"-update"}, args...)); err != nil {
+		t.Fatalf("runTest(-update) error = %v", err)
+	}
+	stale := filepath.Join(golden, "kiro", "removed.md")
+	if err := os.WriteFile(stale, []byte("old rule\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := runTest(args); err == nil {
+		t.Fatal("runTest() passed with a stale golden file")
+	}
+	if err := runTest(append([]string{"-update"}, args...)); err != nil {
+		t.Fatalf("runTest(-update) error = %v", err)
+	}
+	if _, err := os.Stat(stale); !os.IsNotExist(err) {
+		t.Error("-update kept a stale golden file")
+	}
+}
+
+func TestFirstDifference(t *testing.T) {
+	if got, want := firstDifference("a\nb\n", "a\nc\n"), `line 2: golden "b", compiled "c"`; got != want {
+		t.Errorf("firstDifference() = %q, want %q", got, want)
+	}
+	if got, want := firstDifference("a", "a\nb"), `line 2: golden "", compiled "b"`; got != want {
+		t.Errorf("firstDifference() = %q, want %q", got, want)
+	}
+}
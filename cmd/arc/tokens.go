@@ -0,0 +1,80 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"text/tabwriter"
+)
+
+// tokenEntry is a compiled file and the tokens it takes.
+type tokenEntry struct {
+	Target string
+	Path   string
+	Tokens int
+	Bytes  int
+}
+
+// tokenReport collects the token counts of the files a build compiles, for
+// -report tokens. A nil report collects nothing.
+type tokenReport struct {
+	entries []tokenEntry
+}
+
+// add records the results of allResults.
+func (r *tokenReport) add(allResults []targetResults) {
+	if r == nil {
+		return
+	}
+	for _, tr := range allResults {
+		for _, result := range tr.results {
+			r.entries = append(r.entries, tokenEntry{Target: tr.target, Path: result.Path, Tokens: result.Stats.Tokens, Bytes: result.Stats.Bytes})
+		}
+	}
+}
+
+// write prints the files as a table, largest first, followed by a total.
+// Files over budget, if positive, are marked.
+func (r *tokenReport) write(w io.Writer, budget int) {
+	entries := append([]tokenEntry(nil), r.entries...)
+	sort.SliceStable(entries, func(i, j int) bool {
+		if entries[i].Tokens != entries[j].Tokens {
+			return entries[i].Tokens > entries[j].Tokens
+		}
+		return entries[i].Target+"/"+entries[i].Path < entries[j].Target+"/"+entries[j].Path
+	})
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "TOKENS\tBYTES\tTARGET\tPATH")
+	total, over := 0, 0
+	for _, e := range entries {
+		mark := ""
+		if budget > 0 && e.Tokens > budget {
+			mark = "  " + colorize(w, colorYellow, "over budget")
+			over++
+		}
+		fmt.Fprintf(tw, "%d\t%d\t%s\t%s%s\n", e.Tokens, e.Bytes, e.Target, e.Path, mark)
+		total += e.Tokens
+	}
+	tw.Flush()
+	fmt.Fprintf(w, "%d file(s), about %d tokens", len(entries), total)
+	if budget > 0 {
+		fmt.Fprintf(w, ", %d over the budget of %d", over, budget)
+	}
+	fmt.Fprintln(w)
+}
+
+// checkTokenBudget warns about each result of allResults that takes more
+// than budget tokens, unless budget is zero.
+func checkTokenBudget(allResults []targetResults, budget int, summary *buildSummary) {
+	if budget <= 0 {
+		return
+	}
+	for _, tr := range allResults {
+		for _, result := range tr.results {
+			if result.Stats.Tokens > budget {
+				summary.warn("%s/%s: about %d tokens, over the budget of %d", tr.target, result.Path, result.Stats.Tokens, budget)
+			}
+		}
+	}
+}
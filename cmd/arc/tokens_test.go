@@ -0,0 +1,63 @@
+package main
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jomadu/ai-resource-compiler-go/pkg/compiler"
+)
+
+func TestTokenReport(t *testing.T) {
+	results := []targetResults{{target: "claude", results: []compiler.CompilationResult{
+		{Path: "small.md", Stats: compiler.ResultStats{Bytes: 8, Tokens: 2}},
+		{Path: "large.md", Stats: compiler.ResultStats{Bytes: 400, Tokens: 100}},
+	}}}
+
+	summary := newBuildSummary()
+	checkTokenBudget(results, 50, summary)
+	if summary.Warnings != 1 {
+		t.Errorf("warnings = %d, want 1 for the file over budget", summary.Warnings)
+	}
+
+	report := &tokenReport{}
+	report.add(results)
+	var buf bytes.Buffer
+	report.write(&buf, 50)
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 4 {
+		t.Fatalf("report =\n%s", buf.String())
+	}
+	if !strings.Contains(lines[1], "large.md  over budget") || !strings.Contains(lines[2], "small.md") {
+		t.Errorf("report not sorted by tokens or not marking the file over budget:\n%s", buf.String())
+	}
+	if want := "2 file(s), about 102 tokens, 1 over the budget of 50"; lines[3] != want {
+		t.Errorf("total = %q, want %q", lines[3], want)
+	}
+}
+
+func TestBuildTokenBudget(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, dir, "a.yaml", mergeRuleA)
+	config := writeTestFile(t, dir, "arc.yaml", "resources: [a.yaml]\ntargets: [cursor]\noutput: out\ntokenBudget: 1\n")
+	summaryFile := filepath.Join(dir, "summary.json")
+
+	if err := runBuild([]string{"-config", config, "-report", "tokens", "-summary-json", summaryFile}); err != nil {
+		t.Fatalf("runBuild() error = %v", err)
+	}
+	if got := readSummary(t, summaryFile); got.Warnings != 1 {
+		t.Errorf("warnings = %d, want 1 for the file over the configured budget", got.Warnings)
+	}
+
+	if err := runBuild([]string{"-config", config, "-token-budget", "100000", "-summary-json", summaryFile}); err != nil {
+		t.Fatalf("runBuild() error = %v", err)
+	}
+	if got := readSummary(t, summaryFile); got.Warnings != 0 {
+		t.Errorf("warnings = %d, want none under -token-budget", got.Warnings)
+	}
+
+	if err := runBuild([]string{"-config", config, "-report", "sizes"}); err == nil || !strings.Contains(err.Error(), "unknown report") {
+		t.Errorf("runBuild(-report sizes) error = %v, want unknown report", err)
+	}
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/jomadu/ai-resource-compiler-go/internal/diagnostics"
+	"github.com/jomadu/ai-resource-compiler-go/pkg/compiler"
+	"github.com/jomadu/ai-resource-compiler-go/pkg/loader"
+)
+
+func runValidate(args []string) error {
+	fs := flag.NewFlagSet("validate", flag.ContinueOnError)
+	outputFormat := fs.String("format", "text", "Output format: text, json, or sarif")
+
+	files, err := parseInterspersed(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(files) == 0 {
+		return fmt.Errorf("resource file required")
+	}
+	if err := checkDiagnosticFormat(*outputFormat); err != nil {
+		return err
+	}
+	if files, err = expandResources(files); err != nil {
+		return err
+	}
+
+	report := []diagnostics.Diagnostic{}
+	invalid := 0
+	rulesets := newRulesetIndex(files, loadResource)
+	for _, file := range files {
+		found := validateFile(file, rulesets)
+		if len(found) > 0 {
+			invalid++
+		}
+		report = append(report, found...)
+	}
+
+	err = writeDiagnostics(os.Stdout, *outputFormat, report, nil, func(w io.Writer) {
+		writeValidateReport(w, len(files), report)
+	})
+	if err != nil {
+		return err
+	}
+	if invalid > 0 {
+		return fmt.Errorf("%d problem(s) in %d of %d resource file(s)", len(report), invalid, len(files))
+	}
+	return nil
+}
+
+// validateFile loads file and returns its problems, positioned at the field
+// or value they concern. The extends of a ruleset are resolved by rulesets,
+// unless nil.
+func validateFile(file string, rulesets *rulesetIndex) []diagnostics.Diagnostic {
+	// Unknown fields are reported by compiler.Validate, each with the other
+	// problems, rather than failing the load.
+	resource, err := (&loader.Loader{Context: interrupted, Lenient: true}).Load(file)
+	if err != nil {
+		line, column := errorPosition(err, file)
+		return []diagnostics.Diagnostic{{File: file, Line: line, Column: column, Message: err.Error()}}
+	}
+	resolved, err := rulesets.resolve(file, resource)
+	if err != nil {
+		// The diagnostic names the file already.
+		var compileErr *compiler.CompileError
+		if errors.As(err, &compileErr) {
+			err = compileErr.Err
+		}
+		line, column := resource.Position("spec.extends", "")
+		return []diagnostics.Diagnostic{{File: file, Line: line, Column: column, Field: "spec.extends", Message: err.Error()}}
+	}
+
+	problems := compiler.Validate(resolved)
+	if len(problems) == 0 {
+		return nil
+	}
+	found := make([]diagnostics.Diagnostic, len(problems))
+	for i, p := range problems {
+		line, column := resource.Position(p.Field, p.Value)
+		found[i] = diagnostics.Diagnostic{File: file, Line: line, Column: column, Field: p.Field, Message: p.Message}
+	}
+	return found
+}
+
+// writeValidateReport prints each diagnostic as file:line:column: message,
+// the form editors and CI annotations pick up.
+func writeValidateReport(w io.Writer, checked int, report []diagnostics.Diagnostic) {
+	for _, d := range report {
+		position := d.File
+		if d.Line > 0 {
+			position = fmt.Sprintf("%s:%d:%d", d.File, d.Line, d.Column)
+		}
+		fmt.Fprintf(w, "%s: %s %s\n", position, colorize(w, colorRed, "error:"), d.Message)
+	}
+	if len(report) == 0 {
+		fmt.Fprintf(w, "%s %d resource file(s) valid\n", colorize(w, colorGreen, "ok"), checked)
+	}
+}
+
+// checkDiagnosticFormat reports whether format is a -format value of
+// validate and lint.
+func checkDiagnosticFormat(format string) error {
+	switch format {
+	case "text", "json", "sarif":
+		return nil
+	}
+	return fmt.Errorf("unknown format: %s (valid formats: text, json, sarif)", format)
+}
+
+// writeDiagnostics prints report in format: as a JSON array, as a SARIF log
+// for code scanning with rules describing the checks, or as text with
+// writeText.
+func writeDiagnostics(w io.Writer, format string, report []diagnostics.Diagnostic, rules map[string]string, writeText func(io.Writer)) error {
+	switch format {
+	case "json":
+		return diagnostics.WriteJSON(w, report)
+	case "sarif":
+		tool := diagnostics.Tool{
+			Name:           "arc",
+			Version:        arcVersion(),
+			InformationURI: "https://github.com/jomadu/ai-resource-compiler-go",
+			Rules:          rules,
+			DefaultRule:    "validation",
+		}
+		return diagnostics.WriteSARIF(w, tool, report)
+	}
+	writeText(w)
+	return nil
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/jomadu/ai-resource-compiler-go/internal/diagnostics"
+)
+
+const invalidRuleset = `apiVersion: ai-resource/draft
+kind: Ruleset
+metadata:
+  id: rules
+spec:
+  fragments:
+    header: Header
+  rules:
+    naming:
+      enforcement: always
+      body:
+        - $header
+        - $missing
+`
+
+func TestValidateFile(t *testing.T) {
+	dir := t.TempDir()
+	file := writeTestFile(t, dir, "rules.yaml", invalidRuleset)
+
+	got := validateFile(file, nil)
+	want := []diagnostics.Diagnostic{
+		{File: file, Line: 10, Column: 20, Field: "spec.rules.naming.enforcement"},
+		{File: file, Line: 13, Column: 11, Field: "spec.rules.naming.body"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("validateFile() = %+v, want %d diagnostics", got, len(want))
+	}
+	for i, w := range want {
+		if got[i].Line != w.Line || got[i].Column != w.Column || got[i].Field != w.Field {
+			t.Errorf("diagnostic %d = %+v, want %+v", i, got[i], w)
+		}
+	}
+
+	if d := validateFile(createTestResource(t, dir), nil); len(d) != 0 {
+		t.Errorf("validateFile() = %+v for a valid resource", d)
+	}
+}
+
+func TestValidateReport(t *testing.T) {
+	report := []diagnostics.Diagnostic{{File: "rules.yaml", Line: 10, Column: 20, Message: "unknown enforcement"}}
+
+	var text bytes.Buffer
+	writeValidateReport(&text, 1, report)
+	if !strings.HasPrefix(text.String(), "rules.yaml:10:20: error: unknown enforcement") {
+		t.Errorf("text report = %q", text.String())
+	}
+
+	var out bytes.Buffer
+	if err := writeDiagnostics(&out, "json", report, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	var decoded []diagnostics.Diagnostic
+	if err := json.Unmarshal(out.Bytes(), &decoded); err != nil || len(decoded) != 1 || decoded[0].Line != 10 {
+		t.Errorf("JSON report = %s, %v", out.String(), err)
+	}
+
+	out.Reset()
+	if err := writeDiagnostics(&out, "sarif", report, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), `"version": "2.1.0"`) || !strings.Contains(out.String(), `"ruleId": "validation"`) {
+		t.Errorf("SARIF report = %s", out.String())
+	}
+}
+
+func TestRunValidateFails(t *testing.T) {
+	dir := t.TempDir()
+	file := writeTestFile(t, dir, "rules.yaml", invalidRuleset)
+
+	err := runValidate([]string{"-format", "json", file, createTestResource(t, dir)})
+	if err == nil || !strings.Contains(err.Error(), "2 problem(s) in 1 of 2") {
+		t.Errorf("runValidate() error = %v, want 2 problems in 1 of 2 files", err)
+	}
+}
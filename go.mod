@@ -3,6 +3,12 @@ module github.com/jomadu/ai-resource-compiler-go
 go 1.24.5
 
 require (
-	github.com/jomadu/ai-resource-core-go v0.0.0-20260224030203-5a699d8ebe94 // indirect
-	gopkg.in/yaml.v3 v3.0.1 // indirect
+	github.com/opencontainers/image-spec v1.1.1
+	gopkg.in/yaml.v3 v3.0.1
+	oras.land/oras-go/v2 v2.6.0
+)
+
+require (
+	github.com/opencontainers/go-digest v1.0.0 // indirect
+	golang.org/x/sync v0.14.0 // indirect
 )
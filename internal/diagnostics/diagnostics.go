@@ -0,0 +1,44 @@
+// Package diagnostics holds the problems arc reports in resource files, as
+// arc validate and arc lint find them, and writes them as JSON or as SARIF
+// for code scanning tools.
+package diagnostics
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// Severities of a diagnostic.
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+)
+
+// Diagnostic is a problem found in a resource file. Line and Column are
+// 1-based and omitted when unknown, as for resources written in CUE.
+// Severity and Check are set for lint findings; a diagnostic without a
+// severity is an error.
+type Diagnostic struct {
+	File     string `json:"file"`
+	Line     int    `json:"line,omitempty"`
+	Column   int    `json:"column,omitempty"`
+	Field    string `json:"field,omitempty"`
+	Severity string `json:"severity,omitempty"`
+	Check    string `json:"check,omitempty"`
+	Message  string `json:"message"`
+}
+
+// Level returns the severity of d, SeverityError if it has none.
+func (d Diagnostic) Level() string {
+	if d.Severity == "" {
+		return SeverityError
+	}
+	return d.Severity
+}
+
+// WriteJSON writes diagnostics as an indented JSON array.
+func WriteJSON(w io.Writer, diagnostics []Diagnostic) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(diagnostics)
+}
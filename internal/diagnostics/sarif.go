@@ -0,0 +1,164 @@
+package diagnostics
+
+import (
+	"encoding/json"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// SARIFSchema and SARIFVersion identify the SARIF format WriteSARIF writes.
+const (
+	SARIFSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+	SARIFVersion = "2.1.0"
+)
+
+// Tool describes the tool that produced the diagnostics, for the SARIF
+// run's driver.
+type Tool struct {
+	Name           string
+	Version        string
+	InformationURI string
+
+	// Rules describes the checks by name, e.g. "unused-fragment". Checks of
+	// diagnostics missing from it are listed without a description.
+	Rules map[string]string
+
+	// DefaultRule is the rule ID of diagnostics without a Check, such as
+	// validation problems.
+	DefaultRule string
+}
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	Version        string      `json:"version,omitempty"`
+	InformationURI string      `json:"informationUri,omitempty"`
+	Rules          []sarifRule `json:"rules,omitempty"`
+}
+
+type sarifRule struct {
+	ID               string        `json:"id"`
+	ShortDescription *sarifMessage `json:"shortDescription,omitempty"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	RuleIndex int             `json:"ruleIndex"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           *sarifRegion          `json:"region,omitempty"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn,omitempty"`
+}
+
+// WriteSARIF writes diagnostics as a SARIF 2.1.0 log with a single run of
+// tool, the format GitHub code scanning uploads take. Relative file paths
+// are written as relative URIs, so results are annotated on the files of
+// the repository they were found in.
+func WriteSARIF(w io.Writer, tool Tool, diagnostics []Diagnostic) error {
+	ruleIDs := make(map[string]bool)
+	for _, d := range diagnostics {
+		ruleIDs[ruleID(tool, d)] = true
+	}
+	for id := range tool.Rules {
+		ruleIDs[id] = true
+	}
+	ids := make([]string, 0, len(ruleIDs))
+	for id := range ruleIDs {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	driver := sarifDriver{Name: tool.Name, Version: tool.Version, InformationURI: tool.InformationURI}
+	index := make(map[string]int, len(ids))
+	for i, id := range ids {
+		rule := sarifRule{ID: id}
+		if description, ok := tool.Rules[id]; ok {
+			rule.ShortDescription = &sarifMessage{Text: description}
+		}
+		driver.Rules = append(driver.Rules, rule)
+		index[id] = i
+	}
+
+	results := make([]sarifResult, 0, len(diagnostics))
+	for _, d := range diagnostics {
+		location := sarifPhysicalLocation{ArtifactLocation: sarifArtifactLocation{URI: fileURI(d.File)}}
+		if d.Line > 0 {
+			location.Region = &sarifRegion{StartLine: d.Line, StartColumn: d.Column}
+		}
+		id := ruleID(tool, d)
+		results = append(results, sarifResult{
+			RuleID:    id,
+			RuleIndex: index[id],
+			Level:     d.Level(),
+			Message:   sarifMessage{Text: d.Message},
+			Locations: []sarifLocation{{PhysicalLocation: location}},
+		})
+	}
+
+	log := sarifLog{
+		Schema:  SARIFSchema,
+		Version: SARIFVersion,
+		Runs:    []sarifRun{{Tool: sarifTool{Driver: driver}, Results: results}},
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(log)
+}
+
+// ruleID returns the SARIF rule of d: its check, or the tool's default.
+func ruleID(tool Tool, d Diagnostic) string {
+	if d.Check != "" {
+		return d.Check
+	}
+	return tool.DefaultRule
+}
+
+// fileURI returns file as a URI reference: a relative one for a relative
+// path, and a file URI for an absolute one.
+func fileURI(file string) string {
+	uri := filepath.ToSlash(file)
+	if filepath.IsAbs(file) {
+		if !strings.HasPrefix(uri, "/") {
+			uri = "/" + uri // a Windows drive letter
+		}
+		return "file://" + uri
+	}
+	return strings.TrimPrefix(uri, "./")
+}
@@ -0,0 +1,75 @@
+package diagnostics
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+)
+
+func TestWriteSARIF(t *testing.T) {
+	report := []Diagnostic{
+		{File: "./rules/naming.yaml", Line: 10, Column: 20, Message: "unknown enforcement"},
+		{File: "rules/security.yaml", Severity: SeverityWarning, Check: "unused-fragment", Message: "fragment intro is not used"},
+	}
+	tool := Tool{Name: "arc", Rules: map[string]string{"unused-fragment": "A fragment is not used"}, DefaultRule: "validation"}
+
+	var out bytes.Buffer
+	if err := WriteSARIF(&out, tool, report); err != nil {
+		t.Fatalf("WriteSARIF() error = %v", err)
+	}
+	var log struct {
+		Version string `json:"version"`
+		Runs    []struct {
+			Tool struct {
+				Driver struct {
+					Name  string `json:"name"`
+					Rules []struct {
+						ID               string `json:"id"`
+						ShortDescription *struct {
+							Text string `json:"text"`
+						} `json:"shortDescription"`
+					} `json:"rules"`
+				} `json:"driver"`
+			} `json:"tool"`
+			Results []struct {
+				RuleID    string `json:"ruleId"`
+				RuleIndex int    `json:"ruleIndex"`
+				Level     string `json:"level"`
+				Locations []struct {
+					PhysicalLocation struct {
+						ArtifactLocation struct {
+							URI string `json:"uri"`
+						} `json:"artifactLocation"`
+						Region *struct {
+							StartLine   int `json:"startLine"`
+							StartColumn int `json:"startColumn"`
+						} `json:"region"`
+					} `json:"physicalLocation"`
+				} `json:"locations"`
+			} `json:"results"`
+		} `json:"runs"`
+	}
+	if err := json.Unmarshal(out.Bytes(), &log); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, out.String())
+	}
+	if log.Version != "2.1.0" || len(log.Runs) != 1 || log.Runs[0].Tool.Driver.Name != "arc" {
+		t.Fatalf("log = %s", out.String())
+	}
+	run := log.Runs[0]
+	if rules := run.Tool.Driver.Rules; len(rules) != 2 || rules[0].ID != "unused-fragment" || rules[0].ShortDescription == nil || rules[1].ID != "validation" {
+		t.Errorf("rules = %+v", rules)
+	}
+
+	first := run.Results[0]
+	location := first.Locations[0].PhysicalLocation
+	if first.RuleID != "validation" || first.RuleIndex != 1 || first.Level != "error" || location.ArtifactLocation.URI != "rules/naming.yaml" {
+		t.Errorf("result 0 = %+v", first)
+	}
+	if location.Region == nil || location.Region.StartLine != 10 || location.Region.StartColumn != 20 {
+		t.Errorf("region = %+v, want line 10, column 20", location.Region)
+	}
+	second := run.Results[1]
+	if second.RuleID != "unused-fragment" || second.Level != "warning" || second.Locations[0].PhysicalLocation.Region != nil {
+		t.Errorf("result 1 = %+v", second)
+	}
+}
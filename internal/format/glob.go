@@ -0,0 +1,86 @@
+package format
+
+import (
+	"path"
+	"strings"
+)
+
+// MatchGlob reports whether the slash-separated path name matches the scope
+// glob pattern. Besides the path.Match syntax, a "**" element matches any
+// number of directories, including none, and "{a,b}" matches either
+// alternative, as the globs of editor rule files do. A malformed pattern
+// matches nothing.
+func MatchGlob(pattern, name string) bool {
+	for _, p := range expandBraces(pattern) {
+		if matchGlobElems(strings.Split(p, "/"), strings.Split(name, "/")) {
+			return true
+		}
+	}
+	return false
+}
+
+// expandBraces returns the patterns a pattern with "{a,b}" alternatives
+// stands for.
+func expandBraces(pattern string) []string {
+	open := strings.IndexByte(pattern, '{')
+	if open < 0 {
+		return []string{pattern}
+	}
+	depth := 0
+	for i := open; i < len(pattern); i++ {
+		switch pattern[i] {
+		case '{':
+			depth++
+		case '}':
+			if depth--; depth > 0 {
+				continue
+			}
+			var out []string
+			for _, alt := range splitAlternatives(pattern[open+1 : i]) {
+				out = append(out, expandBraces(pattern[:open]+alt+pattern[i+1:])...)
+			}
+			return out
+		}
+	}
+	return []string{pattern}
+}
+
+// splitAlternatives splits the inside of a brace group at its top-level
+// commas.
+func splitAlternatives(s string) []string {
+	var alts []string
+	depth, start := 0, 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '{':
+			depth++
+		case '}':
+			depth--
+		case ',':
+			if depth == 0 {
+				alts = append(alts, s[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(alts, s[start:])
+}
+
+func matchGlobElems(pattern, name []string) bool {
+	if len(pattern) == 0 {
+		return len(name) == 0
+	}
+	if pattern[0] == "**" {
+		for i := 0; i <= len(name); i++ {
+			if matchGlobElems(pattern[1:], name[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(name) == 0 {
+		return false
+	}
+	ok, _ := path.Match(pattern[0], name[0])
+	return ok && matchGlobElems(pattern[1:], name[1:])
+}
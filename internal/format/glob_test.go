@@ -0,0 +1,25 @@
+package format
+
+import "testing"
+
+func TestMatchGlob(t *testing.T) {
+	tests := []struct {
+		pattern, name string
+		want          bool
+	}{
+		{"**/*.go", "main.go", true},
+		{"**/*.go", "cmd/arc/main.go", true},
+		{"src/**", "src/a/b.ts", true},
+		{"src/**", "lib/a.ts", false},
+		{"*.ts", "src/a.ts", false},
+		{"{src,lib}/**/*.ts", "lib/x/a.ts", true},
+		{"**/*.{ts,tsx}", "app/view.tsx", true},
+		{"**/*.{ts,tsx}", "app/view.js", false},
+		{"[", "[", false},
+	}
+	for _, tt := range tests {
+		if got := MatchGlob(tt.pattern, tt.name); got != tt.want {
+			t.Errorf("MatchGlob(%q, %q) = %v, want %v", tt.pattern, tt.name, got, tt.want)
+		}
+	}
+}
@@ -2,75 +2,37 @@ package format
 
 import (
 	"fmt"
+	"sort"
 	"strings"
-)
-
-// Placeholder types until ai-resource-core-go is implemented
-type Metadata struct {
-	ID          string
-	Name        string
-	Description string
-}
-
-type Body struct {
-	String *string
-	Array  []string
-}
-
-type ScopeEntry struct {
-	Files []string
-}
-
-type RuleItem struct {
-	Name        string
-	Description string
-	Enforcement string
-	Scope       []ScopeEntry
-	Body        Body
-}
-
-type RuleSpec struct {
-	Enforcement string
-	Scope       []ScopeEntry
-	Body        Body
-	Fragments   map[string]string
-}
-
-type Ruleset struct {
-	Metadata Metadata
-	Spec     struct {
-		Rules     map[string]RuleItem
-		Fragments map[string]string
-	}
-}
 
-type Rule struct {
-	Metadata Metadata
-	Spec     RuleSpec
-}
-
-type PromptItem struct {
-	Name string
-	Body Body
-}
-
-type PromptSpec struct {
-	Body      Body
-	Fragments map[string]string
-}
-
-type Promptset struct {
-	Metadata Metadata
-	Spec     struct {
-		Prompts   map[string]PromptItem
-		Fragments map[string]string
-	}
-}
+	"github.com/jomadu/ai-resource-compiler-go/pkg/resource"
+)
 
-type Prompt struct {
-	Metadata Metadata
-	Spec     PromptSpec
-}
+// Aliases for the spec types, which live in pkg/resource so external target
+// compilers can use them.
+type (
+	Metadata      = resource.Metadata
+	Body          = resource.Body
+	ScopeEntry    = resource.ScopeEntry
+	RuleItem      = resource.RuleItem
+	RuleSpec      = resource.RuleSpec
+	Rule          = resource.Rule
+	RulesetSpec   = resource.RulesetSpec
+	Ruleset       = resource.Ruleset
+	PromptItem    = resource.PromptItem
+	PromptSpec    = resource.PromptSpec
+	Prompt        = resource.Prompt
+	PromptsetSpec = resource.PromptsetSpec
+	Promptset     = resource.Promptset
+	Asset         = resource.Asset
+
+	CommandArgument = resource.CommandArgument
+	CommandSpec     = resource.CommandSpec
+	Command         = resource.Command
+
+	ContextSpec = resource.ContextSpec
+	Context     = resource.Context
+)
 
 // GenerateRuleMetadataBlockFromRuleset generates complete rule content from a ruleset.
 // Returns: metadata block + enforcement header + resolved body
@@ -78,38 +40,18 @@ func GenerateRuleMetadataBlockFromRuleset(ruleset *Ruleset, ruleID string) strin
 	ruleSpec := ruleset.Spec.Rules[ruleID]
 	body := resolveBody(ruleSpec.Body, ruleset.Spec.Fragments)
 
+	collection := newYAMLMapping().
+		add("id", ruleset.Metadata.ID).
+		addIf("namespace", ruleset.Metadata.Namespace).
+		addIf("name", ruleset.Metadata.Name).
+		addIf("description", ruleset.Metadata.Description).
+		addListIf("tags", ruleset.Metadata.Tags).
+		add("rules", stringList(ruleset.Spec.RuleIDs()))
+	rule := ruleMetadata(ruleID, "", ruleSpec.Name, ruleSpec.Description, ruleSpec.Enforcement, ruleSpec.Tags, ruleSpec.Scope)
+
 	var sb strings.Builder
 	sb.WriteString("---\n")
-	sb.WriteString("ruleset:\n")
-	sb.WriteString(fmt.Sprintf("  id: %s\n", ruleset.Metadata.ID))
-	if ruleset.Metadata.Name != "" {
-		sb.WriteString(fmt.Sprintf("  name: %s\n", ruleset.Metadata.Name))
-	}
-	if ruleset.Metadata.Description != "" {
-		sb.WriteString(fmt.Sprintf("  description: %s\n", ruleset.Metadata.Description))
-	}
-	sb.WriteString("  rules:\n")
-	for id := range ruleset.Spec.Rules {
-		sb.WriteString(fmt.Sprintf("    - %s\n", id))
-	}
-	sb.WriteString("rule:\n")
-	sb.WriteString(fmt.Sprintf("  id: %s\n", ruleID))
-	if ruleSpec.Name != "" {
-		sb.WriteString(fmt.Sprintf("  name: %s\n", ruleSpec.Name))
-	}
-	if ruleSpec.Description != "" {
-		sb.WriteString(fmt.Sprintf("  description: %s\n", ruleSpec.Description))
-	}
-	sb.WriteString(fmt.Sprintf("  enforcement: %s\n", ruleSpec.Enforcement))
-	if len(ruleSpec.Scope) > 0 {
-		sb.WriteString("  scope:\n")
-		sb.WriteString("    files:\n")
-		for _, entry := range ruleSpec.Scope {
-			for _, file := range entry.Files {
-				sb.WriteString(fmt.Sprintf("      - \"%s\"\n", file))
-			}
-		}
-	}
+	sb.WriteString(EncodeYAML(&newYAMLMapping().add("ruleset", collection).add("rule", rule).node))
 	sb.WriteString("---\n\n")
 
 	header := generateEnforcementHeader(ruleSpec.Name, ruleSpec.Enforcement)
@@ -120,30 +62,38 @@ func GenerateRuleMetadataBlockFromRuleset(ruleset *Ruleset, ruleID string) strin
 	return sb.String()
 }
 
+// ruleMetadata returns the metadata of a rule: its ID, namespace, name,
+// description, enforcement, tags, and the globs of its scope, languages and
+// directories written as the globs they stand for.
+func ruleMetadata(id, namespace, name, description, enforcement string, tags []string, scope []ScopeEntry) *yamlMapping {
+	m := newYAMLMapping().
+		add("id", id).
+		addIf("namespace", namespace).
+		addIf("name", name).
+		addIf("description", description).
+		add("enforcement", enforcement).
+		addListIf("tags", tags)
+	if files := ScopeIncludes(scope); len(files) > 0 {
+		s := newYAMLMapping().add("files", globList(files))
+		if exclude := ScopeExcludes(scope); len(exclude) > 0 {
+			s.add("exclude", globList(exclude))
+		}
+		m.add("scope", s)
+	}
+	return m
+}
+
 // GenerateRuleMetadataBlockFromRule generates complete rule content from a standalone rule.
 // Returns: metadata block + enforcement header + resolved body
 func GenerateRuleMetadataBlockFromRule(rule *Rule) string {
 	body := resolveBody(rule.Spec.Body, rule.Spec.Fragments)
 
+	metadata := ruleMetadata(rule.Metadata.ID, rule.Metadata.Namespace, rule.Metadata.Name,
+		rule.Metadata.Description, rule.Spec.Enforcement, rule.Metadata.Tags, rule.Spec.Scope)
+
 	var sb strings.Builder
 	sb.WriteString("---\n")
-	sb.WriteString(fmt.Sprintf("id: %s\n", rule.Metadata.ID))
-	if rule.Metadata.Name != "" {
-		sb.WriteString(fmt.Sprintf("name: %s\n", rule.Metadata.Name))
-	}
-	if rule.Metadata.Description != "" {
-		sb.WriteString(fmt.Sprintf("description: %s\n", rule.Metadata.Description))
-	}
-	sb.WriteString(fmt.Sprintf("enforcement: %s\n", rule.Spec.Enforcement))
-	if len(rule.Spec.Scope) > 0 {
-		sb.WriteString("scope:\n")
-		sb.WriteString("  files:\n")
-		for _, entry := range rule.Spec.Scope {
-			for _, file := range entry.Files {
-				sb.WriteString(fmt.Sprintf("    - \"%s\"\n", file))
-			}
-		}
-	}
+	sb.WriteString(EncodeYAML(&metadata.node))
 	sb.WriteString("---\n\n")
 
 	header := generateEnforcementHeader(rule.Metadata.Name, rule.Spec.Enforcement)
@@ -154,6 +104,29 @@ func GenerateRuleMetadataBlockFromRule(rule *Rule) string {
 	return sb.String()
 }
 
+// GenerateLeanRuleFromRuleset generates rule content from a ruleset without
+// the metadata block.
+// Returns: enforcement header + resolved body
+func GenerateLeanRuleFromRuleset(ruleset *Ruleset, ruleID string) string {
+	ruleSpec := ruleset.Spec.Rules[ruleID]
+	body := resolveBody(ruleSpec.Body, ruleset.Spec.Fragments)
+	return generateEnforcementHeader(ruleSpec.Name, ruleSpec.Enforcement) + "\n\n" + body
+}
+
+// GenerateLeanRuleFromRule generates rule content from a standalone rule
+// without the metadata block.
+// Returns: enforcement header + resolved body
+func GenerateLeanRuleFromRule(rule *Rule) string {
+	body := resolveBody(rule.Spec.Body, rule.Spec.Fragments)
+	return generateEnforcementHeader(rule.Metadata.Name, rule.Spec.Enforcement) + "\n\n" + body
+}
+
+// EnforcementHeader returns the heading that precedes a rule's body, e.g.
+// "# Use Meaningful Names (MUST)".
+func EnforcementHeader(name, enforcement string) string {
+	return generateEnforcementHeader(name, enforcement)
+}
+
 func generateEnforcementHeader(name, enforcement string) string {
 	return fmt.Sprintf("# %s (%s)", name, strings.ToUpper(enforcement))
 }
@@ -183,3 +156,15 @@ func resolveBody(body Body, fragments map[string]string) string {
 	}
 	return ""
 }
+
+// SortedKeys returns the keys of a rule or prompt map in lexical order.
+// Items are compiled and listed in this order so output does not depend on
+// map iteration.
+func SortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -20,10 +20,7 @@ func TestGenerateRuleMetadataBlockFromRuleset(t *testing.T) {
 					Name:        "Clean Code",
 					Description: "Clean code practices",
 				},
-				Spec: struct {
-					Rules     map[string]RuleItem
-					Fragments map[string]string
-				}{
+				Spec: RulesetSpec{
 					Rules: map[string]RuleItem{
 						"meaningfulNames": {
 							Name:        "Use Meaningful Names",
@@ -66,16 +63,33 @@ func TestGenerateRuleMetadataBlockFromRuleset(t *testing.T) {
 				"Use descriptive variable names.",
 			},
 		},
+		{
+			name: "tags",
+			ruleset: &Ruleset{
+				Metadata: Metadata{ID: "security", Tags: []string{"shared"}},
+				Spec: RulesetSpec{
+					Rules: map[string]RuleItem{
+						"noSecrets": {
+							Enforcement: "must",
+							Tags:        []string{"backend", "security"},
+							Body:        Body{String: strPtr("Never commit secrets.")},
+						},
+					},
+				},
+			},
+			ruleID: "noSecrets",
+			want: []string{
+				"  id: security\n  tags:\n    - shared\n  rules:",
+				"  enforcement: must\n  tags:\n    - backend\n    - security\n",
+			},
+		},
 		{
 			name: "minimal metadata",
 			ruleset: &Ruleset{
 				Metadata: Metadata{
 					ID: "simple",
 				},
-				Spec: struct {
-					Rules     map[string]RuleItem
-					Fragments map[string]string
-				}{
+				Spec: RulesetSpec{
 					Rules: map[string]RuleItem{
 						"rule1": {
 							Name:        "Rule One",
@@ -173,6 +187,24 @@ func TestGenerateRuleMetadataBlockFromRule(t *testing.T) {
 				"Minimal rule.",
 			},
 		},
+		{
+			name: "namespace",
+			rule: &Rule{
+				Metadata: Metadata{
+					ID:        "noSecrets",
+					Namespace: "platform",
+					Name:      "No Secrets",
+				},
+				Spec: RuleSpec{
+					Enforcement: "must",
+					Body:        Body{String: strPtr("Never commit secrets.")},
+				},
+			},
+			want: []string{
+				"id: noSecrets\nnamespace: platform\n",
+				"# No Secrets (MUST)",
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -190,3 +222,27 @@ func TestGenerateRuleMetadataBlockFromRule(t *testing.T) {
 func strPtr(s string) *string {
 	return &s
 }
+
+func TestGenerateLeanRule(t *testing.T) {
+	rule := &Rule{
+		Metadata: Metadata{ID: "noSecrets", Name: "No Secrets"},
+		Spec: RuleSpec{
+			Enforcement: "must",
+			Body:        Body{Array: []string{"$intro", "Never commit secrets."}},
+			Fragments:   map[string]string{"intro": "Security first."},
+		},
+	}
+	want := "# No Secrets (MUST)\n\nSecurity first.\n\nNever commit secrets."
+	if got := GenerateLeanRuleFromRule(rule); got != want {
+		t.Errorf("GenerateLeanRuleFromRule() = %q, want %q", got, want)
+	}
+
+	ruleset := &Ruleset{Metadata: Metadata{ID: "security"}}
+	ruleset.Spec.Rules = map[string]RuleItem{
+		"noSecrets": {Name: "No Secrets", Enforcement: "should", Body: Body{String: strPtr("Never commit secrets.")}},
+	}
+	want = "# No Secrets (SHOULD)\n\nNever commit secrets."
+	if got := GenerateLeanRuleFromRuleset(ruleset, "noSecrets"); got != want {
+		t.Errorf("GenerateLeanRuleFromRuleset() = %q, want %q", got, want)
+	}
+}
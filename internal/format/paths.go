@@ -1,5 +1,14 @@
 package format
 
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+)
+
+// The paths built here are relative and slash-separated on every platform;
+// callers writing files convert them with filepath.FromSlash.
+
 // BuildCollectionPath generates a file path for a collection item.
 // Returns: {collectionID}_{itemID}{extension}
 func BuildCollectionPath(collectionID, itemID, extension string) string {
@@ -12,6 +21,15 @@ func BuildStandalonePath(resourceID, extension string) string {
 	return resourceID + extension
 }
 
+// BuildNamespacedPath places a compiled path under the resource's namespace.
+// Returns: {namespace}/{path}, or path unchanged when namespace is empty
+func BuildNamespacedPath(namespace, path string) string {
+	if namespace == "" {
+		return path
+	}
+	return namespace + "/" + path
+}
+
 // BuildClaudeCollectionPath generates a directory path for a Claude collection item.
 // Returns: {collectionID}_{itemID}/SKILL.md
 func BuildClaudeCollectionPath(collectionID, itemID string) string {
@@ -23,3 +41,83 @@ func BuildClaudeCollectionPath(collectionID, itemID string) string {
 func BuildClaudeStandalonePath(resourceID string) string {
 	return resourceID + "/SKILL.md"
 }
+
+// MaxFileNameLength is the length, in bytes, SanitizePath keeps each file
+// and directory name within: the limit of most filesystems.
+const MaxFileNameLength = 255
+
+// windowsReserved are the device names Windows reserves, with or without an
+// extension, in any case.
+var windowsReserved = map[string]bool{
+	"CON": true, "PRN": true, "AUX": true, "NUL": true,
+	"COM1": true, "COM2": true, "COM3": true, "COM4": true, "COM5": true, "COM6": true, "COM7": true, "COM8": true, "COM9": true,
+	"LPT1": true, "LPT2": true, "LPT3": true, "LPT4": true, "LPT5": true, "LPT6": true, "LPT7": true, "LPT8": true, "LPT9": true,
+}
+
+// SanitizePath returns p, a slash-separated relative path, with each name
+// made valid on Windows, macOS, and Linux alike:
+//   - characters Windows forbids (<>:"\|?*), control characters, and
+//     non-ASCII characters become '_'. ASCII names read back byte for byte
+//     on filesystems that normalize Unicode, as macOS ones do, where the
+//     same text spelled differently would not.
+//   - a trailing dot or space, which Windows drops, becomes '_'.
+//   - a name reserved by Windows, such as CON or nul.md, gets a '_' before
+//     its extension.
+//   - a name longer than MaxFileNameLength is cut short, keeping its
+//     extension.
+//
+// A changed name gets a '-' and 8 hex digits of the SHA-256 of the
+// original before its extension, so names that differ only in what was
+// replaced or cut stay distinct. Names already valid, as those built from
+// IDs are, are returned unchanged.
+func SanitizePath(p string) string {
+	names := strings.Split(p, "/")
+	for i, name := range names {
+		names[i] = sanitizeName(name)
+	}
+	return strings.Join(names, "/")
+}
+
+func sanitizeName(name string) string {
+	if name == "" || name == "." || name == ".." {
+		return name
+	}
+	var b strings.Builder
+	for _, r := range name {
+		if r < 0x20 || r == 0x7f || r > 0x7e || strings.ContainsRune(`<>:"\|?*`, r) {
+			b.WriteByte('_')
+		} else {
+			b.WriteRune(r)
+		}
+	}
+	sanitized := b.String()
+	if last := sanitized[len(sanitized)-1]; last == '.' || last == ' ' {
+		sanitized = sanitized[:len(sanitized)-1] + "_"
+	}
+	base, ext := splitExt(sanitized)
+	if windowsReserved[strings.ToUpper(strings.TrimRight(base, " "))] {
+		base += "_"
+	}
+	if base+ext == name && len(name) <= MaxFileNameLength {
+		return name
+	}
+
+	sum := sha256.Sum256([]byte(name))
+	suffix := "-" + hex.EncodeToString(sum[:4])
+	if len(ext) > MaxFileNameLength/2 {
+		ext = ext[:MaxFileNameLength/2]
+	}
+	if keep := MaxFileNameLength - len(suffix) - len(ext); len(base) > keep {
+		base = base[:keep]
+	}
+	return base + suffix + ext
+}
+
+// splitExt splits name before its first dot, after a leading one, so
+// "a.instructions.md" has the extension ".instructions.md".
+func splitExt(name string) (base, ext string) {
+	if i := strings.Index(name[1:], "."); i >= 0 {
+		return name[:i+1], name[i+1:]
+	}
+	return name, ""
+}
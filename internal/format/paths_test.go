@@ -1,6 +1,11 @@
 package format
 
-import "testing"
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"testing"
+)
 
 func TestBuildCollectionPath(t *testing.T) {
 	tests := []struct {
@@ -138,3 +143,68 @@ func TestBuildClaudeStandalonePath(t *testing.T) {
 		})
 	}
 }
+
+func TestBuildNamespacedPath(t *testing.T) {
+	tests := []struct {
+		name      string
+		namespace string
+		path      string
+		want      string
+	}{
+		{name: "no namespace", namespace: "", path: "cleanCode_meaningfulNames.md", want: "cleanCode_meaningfulNames.md"},
+		{name: "namespace", namespace: "platform", path: "cleanCode_meaningfulNames.md", want: "platform/cleanCode_meaningfulNames.md"},
+		{name: "claude skill", namespace: "platform", path: "review/SKILL.md", want: "platform/review/SKILL.md"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := BuildNamespacedPath(tt.namespace, tt.path)
+			if got != tt.want {
+				t.Errorf("BuildNamespacedPath() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSanitizePath(t *testing.T) {
+	long := strings.Repeat("a", 300)
+	tests := []struct {
+		name string
+		path string
+		want string
+	}{
+		{name: "valid", path: "rules/cleanCode_meaningfulNames.instructions.md", want: "rules/cleanCode_meaningfulNames.instructions.md"},
+		{name: "spaces and dots inside", path: "my rules/v1.2 notes.md", want: "my rules/v1.2 notes.md"},
+		{name: "forbidden characters", path: "a:b/c?.md", want: "a_b-" + hashOf("a:b") + "/c_-" + hashOf("c?.md") + ".md"},
+		{name: "non-ASCII", path: "règles.md", want: "r_gles-" + hashOf("règles.md") + ".md"},
+		{name: "trailing dot", path: "notes./x.md", want: "notes_-" + hashOf("notes.") + "/x.md"},
+		{name: "reserved name", path: "nul.md", want: "nul_-" + hashOf("nul.md") + ".md"},
+		{name: "reserved name any case", path: "Com1", want: "Com1_-" + hashOf("Com1")},
+		{name: "not reserved", path: "console.md", want: "console.md"},
+		{name: "too long", path: long + ".prompt.md", want: long[:MaxFileNameLength-9-len(".prompt.md")] + "-" + hashOf(long+".prompt.md") + ".prompt.md"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := SanitizePath(tt.path)
+			if got != tt.want {
+				t.Errorf("SanitizePath(%q) = %q, want %q", tt.path, got, tt.want)
+			}
+			for _, name := range strings.Split(got, "/") {
+				if len(name) > MaxFileNameLength {
+					t.Errorf("name %q is %d bytes long", name, len(name))
+				}
+			}
+		})
+	}
+
+	// Names cut to the same prefix stay distinct.
+	if a, b := SanitizePath(long+"1.md"), SanitizePath(long+"2.md"); a == b {
+		t.Errorf("SanitizePath() = %q for both long names", a)
+	}
+}
+
+func hashOf(name string) string {
+	sum := sha256.Sum256([]byte(name))
+	return hex.EncodeToString(sum[:4])
+}
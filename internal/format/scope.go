@@ -0,0 +1,99 @@
+package format
+
+import (
+	"path"
+	"sort"
+	"strings"
+)
+
+// languageExtensions maps the languages a scope entry can name to the file
+// extensions of their sources.
+var languageExtensions = map[string][]string{
+	"c":          {"c", "h"},
+	"cpp":        {"cc", "cpp", "cxx", "hh", "hpp"},
+	"csharp":     {"cs"},
+	"go":         {"go"},
+	"java":       {"java"},
+	"javascript": {"js", "jsx", "mjs", "cjs"},
+	"kotlin":     {"kt", "kts"},
+	"markdown":   {"md"},
+	"php":        {"php"},
+	"proto":      {"proto"},
+	"python":     {"py", "pyi"},
+	"ruby":       {"rb"},
+	"rust":       {"rs"},
+	"shell":      {"sh", "bash"},
+	"sql":        {"sql"},
+	"swift":      {"swift"},
+	"terraform":  {"tf"},
+	"typescript": {"ts", "tsx"},
+	"yaml":       {"yaml", "yml"},
+}
+
+// Languages returns the languages a scope entry can name, sorted.
+func Languages() []string {
+	languages := make([]string, 0, len(languageExtensions))
+	for language := range languageExtensions {
+		languages = append(languages, language)
+	}
+	sort.Strings(languages)
+	return languages
+}
+
+// ScopeIncludes returns the glob patterns of the files a rule's scope
+// applies to: the files globs of each entry, "**/*.<ext>" for each
+// extension of its languages, and "<dir>/**" for each of its directories,
+// "**" for the project root. A pattern listed twice is returned once.
+func ScopeIncludes(scope []ScopeEntry) []string {
+	var globs []string
+	seen := make(map[string]bool)
+	add := func(glob string) {
+		if !seen[glob] {
+			seen[glob] = true
+			globs = append(globs, glob)
+		}
+	}
+	for _, entry := range scope {
+		for _, glob := range entry.Files {
+			add(glob)
+		}
+		for _, language := range entry.Languages {
+			for _, ext := range languageExtensions[strings.ToLower(language)] {
+				add("**/*." + ext)
+			}
+		}
+		for _, dir := range entry.Directories {
+			if dir = path.Clean(dir); dir == "." {
+				add("**")
+			} else {
+				add(dir + "/**")
+			}
+		}
+	}
+	return globs
+}
+
+// ScopeExcludes returns the exclude globs of a rule's scope. Each entry's
+// exclusions apply to the whole scope once flattened into one list.
+func ScopeExcludes(scope []ScopeEntry) []string {
+	var globs []string
+	for _, entry := range scope {
+		globs = append(globs, entry.Exclude...)
+	}
+	return globs
+}
+
+// ScopeGlobs returns the glob list of a rule's scope for targets that take
+// one: ScopeIncludes followed by ScopeExcludes, each prefixed with "!" as
+// gitignore-style glob lists write exclusions. It is empty for an unscoped
+// rule.
+func ScopeGlobs(scope []ScopeEntry) []string {
+	globs := ScopeIncludes(scope)
+	if len(globs) == 0 {
+		return nil
+	}
+	for _, glob := range ScopeExcludes(scope) {
+		globs = append(globs, "!"+glob)
+	}
+	return globs
+}
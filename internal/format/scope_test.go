@@ -0,0 +1,25 @@
+package format
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestScopeGlobs(t *testing.T) {
+	scope := []ScopeEntry{
+		{Files: []string{"Makefile"}, Languages: []string{"typescript"}, Exclude: []string{"**/*.d.ts"}},
+		{Directories: []string{"cmd/", "."}, Files: []string{"Makefile"}, Exclude: []string{"vendor/**"}},
+	}
+
+	wantIncludes := []string{"Makefile", "**/*.ts", "**/*.tsx", "cmd/**", "**"}
+	if got := ScopeIncludes(scope); !reflect.DeepEqual(got, wantIncludes) {
+		t.Errorf("ScopeIncludes() = %v, want %v", got, wantIncludes)
+	}
+	want := append(wantIncludes, "!**/*.d.ts", "!vendor/**")
+	if got := ScopeGlobs(scope); !reflect.DeepEqual(got, want) {
+		t.Errorf("ScopeGlobs() = %v, want %v", got, want)
+	}
+	if got := ScopeGlobs(nil); got != nil {
+		t.Errorf("ScopeGlobs(nil) = %v, want nil", got)
+	}
+}
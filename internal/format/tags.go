@@ -0,0 +1,29 @@
+package format
+
+// MergeTags returns the tags of lists in order, each once: the tags of a
+// rule or prompt are those of its collection followed by its own.
+func MergeTags(lists ...[]string) []string {
+	var tags []string
+	seen := make(map[string]bool)
+	for _, list := range lists {
+		for _, tag := range list {
+			if !seen[tag] {
+				seen[tag] = true
+				tags = append(tags, tag)
+			}
+		}
+	}
+	return tags
+}
+
+// HasAnyTag reports whether tags includes any of want.
+func HasAnyTag(tags, want []string) bool {
+	for _, tag := range tags {
+		for _, w := range want {
+			if tag == w {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,19 @@
+package format
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMergeTags(t *testing.T) {
+	got := MergeTags([]string{"shared", "backend"}, nil, []string{"backend", "security"})
+	if want := []string{"shared", "backend", "security"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("MergeTags() = %v, want %v", got, want)
+	}
+	if got := MergeTags(nil, nil); got != nil {
+		t.Errorf("MergeTags() = %v, want nil", got)
+	}
+	if !HasAnyTag(got, []string{"mobile", "security"}) || HasAnyTag(got, []string{"mobile"}) || HasAnyTag(got, nil) {
+		t.Error("HasAnyTag() matched wrongly")
+	}
+}
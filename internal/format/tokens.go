@@ -0,0 +1,10 @@
+package format
+
+import "unicode/utf8"
+
+// EstimateTokens estimates the number of model tokens text takes, at about
+// four characters a token, the usual rate for English prose and code. It is
+// meant for budgets and warnings, not exact accounting.
+func EstimateTokens(text string) int {
+	return (utf8.RuneCountInString(text) + 3) / 4
+}
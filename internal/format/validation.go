@@ -2,29 +2,55 @@ package format
 
 import (
 	"fmt"
+	"path"
 	"strings"
 )
 
+// ValidationError reports a resource field whose value is missing or invalid.
+type ValidationError struct {
+	Field   string // Field path, e.g. "metadata.id" or "id" for any resource ID
+	Value   string // Offending value, if any
+	Message string
+}
+
+func (e *ValidationError) Error() string {
+	return e.Message
+}
+
 // ValidateID checks if an ID contains only allowed characters.
 // Allowed: a-z, A-Z, 0-9, -, _
 func ValidateID(id string) error {
 	if id == "" {
-		return fmt.Errorf("ID cannot be empty")
+		return &ValidationError{Field: "id", Message: "ID cannot be empty"}
 	}
 
 	for _, char := range id {
 		if !isValidIDChar(char) {
-			return fmt.Errorf("ID contains invalid character '%c' in '%s'", char, id)
+			return &ValidationError{Field: "id", Value: id, Message: fmt.Sprintf("ID contains invalid character '%c' in '%s'", char, id)}
 		}
 	}
 
 	return nil
 }
 
+// ValidateNamespace checks a metadata namespace. A namespace is one or more
+// IDs separated by '/'; an empty namespace is allowed.
+func ValidateNamespace(namespace string) error {
+	if namespace == "" {
+		return nil
+	}
+	for _, segment := range strings.Split(namespace, "/") {
+		if err := ValidateID(segment); err != nil {
+			return &ValidationError{Field: "metadata.namespace", Value: namespace, Message: fmt.Sprintf("invalid namespace '%s': %v", namespace, err)}
+		}
+	}
+	return nil
+}
+
 // ValidateRuleName checks if a rule name contains parentheses.
 func ValidateRuleName(name string) error {
 	if strings.ContainsAny(name, "()") {
-		return fmt.Errorf("rule name cannot contain parentheses: '%s'", name)
+		return &ValidationError{Field: "name", Value: name, Message: fmt.Sprintf("rule name cannot contain parentheses: '%s'", name)}
 	}
 	return nil
 }
@@ -36,3 +62,60 @@ func isValidIDChar(char rune) bool {
 		char == '-' ||
 		char == '_'
 }
+
+// ValidateScope checks a rule's scope. A scope that is present must list at
+// least one pattern, and every entry must have file patterns, languages, or
+// directories, none of them blank; an empty placeholder would compile to
+// meaningless empty glob frontmatter. Languages must be known, and
+// directories relative paths within the project. field is the scope's path
+// in the resource and rule names the rule in messages.
+func ValidateScope(scope []ScopeEntry, field, rule string) error {
+	if scope != nil && len(scope) == 0 {
+		return &ValidationError{Field: field, Message: fmt.Sprintf("rule '%s' has an empty scope: list file patterns or remove scope", rule)}
+	}
+	for i, entry := range scope {
+		if len(entry.Files) == 0 && len(entry.Languages) == 0 && len(entry.Directories) == 0 {
+			return &ValidationError{Field: field, Message: fmt.Sprintf("rule '%s' scope entry %d has no file patterns, languages, or directories", rule, i+1)}
+		}
+		for _, pattern := range append(append([]string(nil), entry.Files...), entry.Exclude...) {
+			if strings.TrimSpace(pattern) == "" {
+				return &ValidationError{Field: field, Message: fmt.Sprintf("rule '%s' scope entry %d has an empty file pattern", rule, i+1)}
+			}
+		}
+		for _, language := range entry.Languages {
+			if _, ok := languageExtensions[strings.ToLower(language)]; !ok {
+				return &ValidationError{Field: field, Message: fmt.Sprintf("rule '%s' scope entry %d has unknown language %q (known languages: %s)", rule, i+1, language, strings.Join(Languages(), ", "))}
+			}
+		}
+		for _, dir := range entry.Directories {
+			clean := path.Clean(strings.TrimSpace(dir))
+			if strings.TrimSpace(dir) == "" || path.IsAbs(clean) || clean == ".." || strings.HasPrefix(clean, "../") {
+				return &ValidationError{Field: field, Message: fmt.Sprintf("rule '%s' scope entry %d has directory %q, which is not a relative path within the project", rule, i+1, dir)}
+			}
+		}
+	}
+	return nil
+}
+
+// ValidateAssets checks the assets of a prompt. Each must have a path, or
+// data set in code, and a name that is a relative path within the prompt's
+// directory, unique among them. field is the assets' path in the resource
+// and prompt names the prompt in messages.
+func ValidateAssets(assets []Asset, field, prompt string) error {
+	seen := make(map[string]bool)
+	for i, asset := range assets {
+		if strings.TrimSpace(asset.Path) == "" && asset.Data == nil {
+			return &ValidationError{Field: field, Message: fmt.Sprintf("prompt '%s' asset %d has no path", prompt, i+1)}
+		}
+		name := asset.FileName()
+		clean := path.Clean(name)
+		if strings.TrimSpace(name) == "" || path.IsAbs(clean) || clean == "." || clean == ".." || strings.HasPrefix(clean, "../") {
+			return &ValidationError{Field: field, Value: name, Message: fmt.Sprintf("prompt '%s' asset %d has name %q, which is not a relative path within the prompt's directory", prompt, i+1, name)}
+		}
+		if seen[clean] {
+			return &ValidationError{Field: field, Value: name, Message: fmt.Sprintf("prompt '%s' ships more than one asset named %q", prompt, clean)}
+		}
+		seen[clean] = true
+	}
+	return nil
+}
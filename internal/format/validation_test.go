@@ -1,6 +1,9 @@
 package format
 
-import "testing"
+import (
+	"strings"
+	"testing"
+)
 
 func TestValidateID(t *testing.T) {
 	tests := []struct {
@@ -73,3 +76,87 @@ func TestValidateRuleName(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateNamespace(t *testing.T) {
+	tests := []struct {
+		name      string
+		namespace string
+		wantErr   bool
+	}{
+		{name: "empty", namespace: "", wantErr: false},
+		{name: "single segment", namespace: "platform", wantErr: false},
+		{name: "nested", namespace: "acme/platform", wantErr: false},
+		{name: "leading slash", namespace: "/platform", wantErr: true},
+		{name: "trailing slash", namespace: "platform/", wantErr: true},
+		{name: "parent segment", namespace: "../platform", wantErr: true},
+		{name: "invalid char", namespace: "plat form", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateNamespace(tt.namespace)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateNamespace(%q) error = %v, wantErr %v", tt.namespace, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateScope(t *testing.T) {
+	tests := []struct {
+		name    string
+		scope   []ScopeEntry
+		wantErr bool
+	}{
+		{name: "absent", scope: nil, wantErr: false},
+		{name: "patterns", scope: []ScopeEntry{{Files: []string{"**/*.go"}}}, wantErr: false},
+		{name: "empty list", scope: []ScopeEntry{}, wantErr: true},
+		{name: "entry without files", scope: []ScopeEntry{{}}, wantErr: true},
+		{name: "empty files", scope: []ScopeEntry{{Files: []string{}}}, wantErr: true},
+		{name: "blank pattern", scope: []ScopeEntry{{Files: []string{"*.go", " "}}}, wantErr: true},
+		{name: "languages and directories", scope: []ScopeEntry{{Languages: []string{"Go"}, Directories: []string{"cmd/"}, Exclude: []string{"**/*_test.go"}}}, wantErr: false},
+		{name: "exclude only", scope: []ScopeEntry{{Exclude: []string{"vendor/**"}}}, wantErr: true},
+		{name: "blank exclude", scope: []ScopeEntry{{Files: []string{"*.go"}, Exclude: []string{""}}}, wantErr: true},
+		{name: "unknown language", scope: []ScopeEntry{{Languages: []string{"cobol"}}}, wantErr: true},
+		{name: "directory outside project", scope: []ScopeEntry{{Directories: []string{"../lib"}}}, wantErr: true},
+		{name: "absolute directory", scope: []ScopeEntry{{Directories: []string{"/src"}}}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateScope(tt.scope, "spec.scope", "goStyle")
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateScope(%v) error = %v, wantErr %v", tt.scope, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateAssets(t *testing.T) {
+	tests := []struct {
+		name    string
+		assets  []Asset
+		wantErr string
+	}{
+		{name: "valid", assets: []Asset{{Path: "check.sh"}, {Path: "docs/ref.md", Name: "reference/ref.md"}}},
+		{name: "data without path", assets: []Asset{{Name: "logo.png", Data: []byte{0}}}},
+		{name: "no path", assets: []Asset{{Name: "logo.png"}}, wantErr: "has no path"},
+		{name: "name outside directory", assets: []Asset{{Path: "x.sh", Name: "../x.sh"}}, wantErr: "not a relative path"},
+		{name: "absolute name", assets: []Asset{{Path: "x.sh", Name: "/x.sh"}}, wantErr: "not a relative path"},
+		{name: "duplicate name", assets: []Asset{{Path: "a/check.sh"}, {Path: "b/check.sh"}}, wantErr: "more than one asset"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateAssets(tt.assets, "spec.assets", "deploy")
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("ValidateAssets() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ValidateAssets() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
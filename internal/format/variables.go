@@ -0,0 +1,48 @@
+package format
+
+import "regexp"
+
+var variablePattern = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_.-]*)\}`)
+
+// ExpandVariables replaces ${name} references with values from vars.
+// References to names not present in vars are left unchanged, so bodies that
+// contain shell-style ${...} text compile as written.
+func ExpandVariables(text string, vars map[string]string) string {
+	if len(vars) == 0 {
+		return text
+	}
+	return variablePattern.ReplaceAllStringFunc(text, func(ref string) string {
+		name := variablePattern.FindStringSubmatch(ref)[1]
+		if value, ok := vars[name]; ok {
+			return value
+		}
+		return ref
+	})
+}
+
+var argumentPattern = regexp.MustCompile(`\$\{arg:([A-Za-z0-9_-]+)\}`)
+
+// ExpandArguments replaces ${arg:name} references to the arguments of a
+// command with placeholder(i, arg), where i is the argument's index in args.
+// References to undeclared arguments are left unchanged.
+func ExpandArguments(text string, args []CommandArgument, placeholder func(int, CommandArgument) string) string {
+	return argumentPattern.ReplaceAllStringFunc(text, func(ref string) string {
+		name := argumentPattern.FindStringSubmatch(ref)[1]
+		for i, arg := range args {
+			if arg.Name == name {
+				return placeholder(i, arg)
+			}
+		}
+		return ref
+	})
+}
+
+// ArgumentReferences returns the argument names text refers to as
+// ${arg:name}, in order of appearance.
+func ArgumentReferences(text string) []string {
+	var names []string
+	for _, m := range argumentPattern.FindAllStringSubmatch(text, -1) {
+		names = append(names, m[1])
+	}
+	return names
+}
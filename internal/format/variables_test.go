@@ -0,0 +1,57 @@
+package format
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestExpandVariables(t *testing.T) {
+	vars := map[string]string{
+		"team":       "platform",
+		"max_length": "80",
+	}
+
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{name: "single", text: "Owned by ${team}.", want: "Owned by platform."},
+		{name: "multiple", text: "${team}: lines under ${max_length}", want: "platform: lines under 80"},
+		{name: "undefined left as is", text: "Run ${HOME}/bin/tool", want: "Run ${HOME}/bin/tool"},
+		{name: "fragment reference untouched", text: "$team", want: "$team"},
+		{name: "no references", text: "Plain body", want: "Plain body"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ExpandVariables(tt.text, vars); got != tt.want {
+				t.Errorf("ExpandVariables() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExpandVariablesNoVars(t *testing.T) {
+	if got := ExpandVariables("Owned by ${team}.", nil); got != "Owned by ${team}." {
+		t.Errorf("ExpandVariables() = %q, want input unchanged", got)
+	}
+}
+
+func TestExpandArguments(t *testing.T) {
+	args := []CommandArgument{{Name: "issue"}, {Name: "branch"}}
+	placeholder := func(i int, arg CommandArgument) string {
+		return fmt.Sprintf("$%d(%s)", i+1, arg.Name)
+	}
+
+	got := ExpandArguments("Fix ${arg:issue} on ${arg:branch}, not ${arg:other} or ${team}.", args, placeholder)
+	want := "Fix $1(issue) on $2(branch), not ${arg:other} or ${team}."
+	if got != want {
+		t.Errorf("ExpandArguments() = %q, want %q", got, want)
+	}
+
+	refs := ArgumentReferences("${arg:issue} ${arg:other} ${team}")
+	if len(refs) != 2 || refs[0] != "issue" || refs[1] != "other" {
+		t.Errorf("ArgumentReferences() = %v, want [issue other]", refs)
+	}
+}
@@ -0,0 +1,99 @@
+package format
+
+import (
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Generated YAML — frontmatter and metadata blocks — is emitted through the
+// yaml encoder rather than string formatting, so values that need quoting
+// are quoted. Glob patterns are always double-quoted: values such as
+// "**/*.ts" or "{src,lib}/**" are otherwise aliases or flow mappings to a
+// YAML parser, and "src/**/*.{ts,tsx}" is plain only to YAML 1.2 parsers.
+
+// Globs is a list of glob patterns, encoded as double-quoted scalars. A nil
+// or empty list is encoded as [].
+type Globs []string
+
+// MarshalYAML implements yaml.Marshaler.
+func (g Globs) MarshalYAML() (interface{}, error) {
+	return globList(g), nil
+}
+
+func globList(globs []string) *yaml.Node {
+	node := &yaml.Node{Kind: yaml.SequenceNode}
+	if len(globs) == 0 {
+		node.Style = yaml.FlowStyle
+	}
+	for _, glob := range globs {
+		node.Content = append(node.Content, GlobNode(glob))
+	}
+	return node
+}
+
+// GlobNode returns a double-quoted scalar node for a glob pattern.
+func GlobNode(glob string) *yaml.Node {
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Style: yaml.DoubleQuotedStyle, Value: glob}
+}
+
+// EncodeYAML encodes v with two-space indentation. Strings are quoted only
+// when a parser would otherwise read them as another type or as syntax.
+func EncodeYAML(v interface{}) string {
+	var b strings.Builder
+	encoder := yaml.NewEncoder(&b)
+	encoder.SetIndent(2)
+	encoder.Encode(v)
+	encoder.Close()
+	return b.String()
+}
+
+// yamlMapping builds a mapping node whose keys keep the order given.
+type yamlMapping struct {
+	node yaml.Node
+}
+
+func newYAMLMapping() *yamlMapping {
+	return &yamlMapping{node: yaml.Node{Kind: yaml.MappingNode}}
+}
+
+// add appends key with value, a *yaml.Node or a string.
+func (m *yamlMapping) add(key string, value interface{}) *yamlMapping {
+	var node *yaml.Node
+	switch v := value.(type) {
+	case *yaml.Node:
+		node = v
+	case *yamlMapping:
+		node = &v.node
+	case string:
+		node = &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: v}
+	}
+	m.node.Content = append(m.node.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: key}, node)
+	return m
+}
+
+// addIf appends key only when value is not empty.
+func (m *yamlMapping) addIf(key, value string) *yamlMapping {
+	if value != "" {
+		m.add(key, value)
+	}
+	return m
+}
+
+// addListIf appends key with values as a block sequence only when there
+// are values.
+func (m *yamlMapping) addListIf(key string, values []string) *yamlMapping {
+	if len(values) > 0 {
+		m.add(key, stringList(values))
+	}
+	return m
+}
+
+// stringList returns a block sequence of strings.
+func stringList(values []string) *yaml.Node {
+	node := &yaml.Node{Kind: yaml.SequenceNode}
+	for _, v := range values {
+		node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: v})
+	}
+	return node
+}
@@ -0,0 +1,68 @@
+package format
+
+import (
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestEncodeGlobsQuoted(t *testing.T) {
+	got := EncodeYAML(struct {
+		Globs Globs `yaml:"globs"`
+		None  Globs `yaml:"none"`
+	}{Globs: Globs{"**/*.ts", "{src,lib}/**", "src/**/*.{ts,tsx}"}})
+
+	want := "globs:\n  - \"**/*.ts\"\n  - \"{src,lib}/**\"\n  - \"src/**/*.{ts,tsx}\"\nnone: []\n"
+	if got != want {
+		t.Errorf("EncodeYAML() = %q, want %q", got, want)
+	}
+}
+
+// TestMetadataBlockRoundTrip checks that values needing quoting survive a
+// strict YAML parse of the generated metadata block.
+func TestMetadataBlockRoundTrip(t *testing.T) {
+	name := "Note: {important}"
+	description := "*emphasis* and # not a comment"
+	globs := []string{"**/*.ts", "{src,lib}/**/*.{ts,tsx}", `path\with"quote`}
+	ruleset := &Ruleset{
+		Metadata: Metadata{ID: "rules", Name: "true", Description: "[draft]"},
+		Spec: RulesetSpec{Rules: map[string]RuleItem{"r1": {
+			Name:        name,
+			Description: description,
+			Enforcement: "must",
+			Scope:       []ScopeEntry{{Files: globs}},
+			Body:        Body{String: strPtr("body")},
+		}}},
+	}
+
+	content := GenerateRuleMetadataBlockFromRuleset(ruleset, "r1")
+	block := strings.SplitN(strings.TrimPrefix(content, "---\n"), "---\n", 2)[0]
+
+	var parsed struct {
+		Ruleset struct {
+			Name        string   `yaml:"name"`
+			Description string   `yaml:"description"`
+			Rules       []string `yaml:"rules"`
+		} `yaml:"ruleset"`
+		Rule struct {
+			Name        string `yaml:"name"`
+			Description string `yaml:"description"`
+			Scope       struct {
+				Files []string `yaml:"files"`
+			} `yaml:"scope"`
+		} `yaml:"rule"`
+	}
+	if err := yaml.Unmarshal([]byte(block), &parsed); err != nil {
+		t.Fatalf("metadata block is not valid YAML: %v\n%s", err, block)
+	}
+	if parsed.Ruleset.Name != "true" || parsed.Ruleset.Description != "[draft]" {
+		t.Errorf("ruleset = %+v", parsed.Ruleset)
+	}
+	if parsed.Rule.Name != name || parsed.Rule.Description != description {
+		t.Errorf("rule name, description = %q, %q", parsed.Rule.Name, parsed.Rule.Description)
+	}
+	if strings.Join(parsed.Rule.Scope.Files, "|") != strings.Join(globs, "|") {
+		t.Errorf("files = %q, want %q", parsed.Rule.Scope.Files, globs)
+	}
+}
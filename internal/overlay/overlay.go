@@ -0,0 +1,277 @@
+// Package overlay applies patch files to resource documents before they are
+// decoded, so a base resource can be adjusted per environment without
+// copying it.
+//
+// Two patch styles are supported:
+//
+//   - Strategic merge: a YAML mapping merged into the resource. Mappings are
+//     merged recursively, other values replace the base value, and a null
+//     value deletes the key. If the patch sets kind or metadata.id, it only
+//     applies to resources with matching values.
+//
+//   - JSON patch: a YAML sequence of {op, path, value} operations using
+//     JSON Pointer paths (e.g. /spec/rules/naming/enforcement). Supported
+//     operations are add, replace, and remove.
+package overlay
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Overlay is a parsed patch file.
+type Overlay struct {
+	// Source identifies the overlay in error messages (usually its file path).
+	Source string
+
+	merge *yaml.Node
+	ops   []operation
+}
+
+type operation struct {
+	Op    string    `yaml:"op"`
+	Path  string    `yaml:"path"`
+	Value yaml.Node `yaml:"value"`
+}
+
+// Load reads and parses an overlay file.
+func Load(path string) (*Overlay, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read overlay file: %w", err)
+	}
+	o, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	o.Source = path
+	return o, nil
+}
+
+// Parse parses overlay content. A mapping is treated as a strategic merge
+// patch and a sequence as a list of JSON patch operations.
+func Parse(data []byte) (*Overlay, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse overlay: %w", err)
+	}
+	if len(doc.Content) == 0 {
+		return nil, fmt.Errorf("overlay is empty")
+	}
+
+	root := doc.Content[0]
+	switch root.Kind {
+	case yaml.MappingNode:
+		return &Overlay{merge: root}, nil
+	case yaml.SequenceNode:
+		var ops []operation
+		if err := root.Decode(&ops); err != nil {
+			return nil, fmt.Errorf("failed to parse patch operations: %w", err)
+		}
+		for i, op := range ops {
+			switch op.Op {
+			case "add", "replace":
+				if op.Value.Kind == 0 {
+					return nil, fmt.Errorf("operation %d: %s requires a value", i, op.Op)
+				}
+			case "remove":
+			default:
+				return nil, fmt.Errorf("operation %d: unsupported op %q (valid ops: add, replace, remove)", i, op.Op)
+			}
+			if !strings.HasPrefix(op.Path, "/") {
+				return nil, fmt.Errorf("operation %d: path must start with '/': %q", i, op.Path)
+			}
+		}
+		return &Overlay{ops: ops}, nil
+	default:
+		return nil, fmt.Errorf("overlay must be a mapping (strategic merge) or a sequence (JSON patch)")
+	}
+}
+
+// Apply patches a parsed resource document in place. It reports whether the
+// overlay applied; strategic merge patches whose kind or metadata.id do not
+// match the document are skipped.
+func (o *Overlay) Apply(doc *yaml.Node) (bool, error) {
+	root := doc
+	if root.Kind == yaml.DocumentNode {
+		if len(root.Content) == 0 {
+			return false, fmt.Errorf("resource document is empty")
+		}
+		root = root.Content[0]
+	}
+	if root.Kind != yaml.MappingNode {
+		return false, fmt.Errorf("resource document must be a mapping")
+	}
+
+	if o.merge != nil {
+		if !selects(o.merge, root) {
+			return false, nil
+		}
+		mergeMapping(root, o.merge)
+		return true, nil
+	}
+
+	for i, op := range o.ops {
+		if err := applyOperation(root, op); err != nil {
+			return false, o.errorf("operation %d (%s %s): %v", i, op.Op, op.Path, err)
+		}
+	}
+	return true, nil
+}
+
+func (o *Overlay) errorf(format string, args ...interface{}) error {
+	err := fmt.Errorf(format, args...)
+	if o.Source != "" {
+		return fmt.Errorf("overlay %s: %w", o.Source, err)
+	}
+	return fmt.Errorf("overlay: %w", err)
+}
+
+// selects reports whether the kind and metadata.id set in patch (if any)
+// match the resource document.
+func selects(patch, root *yaml.Node) bool {
+	if kind := lookup(patch, "kind"); kind != nil {
+		if base := lookup(root, "kind"); base == nil || base.Value != kind.Value {
+			return false
+		}
+	}
+	if id := lookup(lookup(patch, "metadata"), "id"); id != nil {
+		if base := lookup(lookup(root, "metadata"), "id"); base == nil || base.Value != id.Value {
+			return false
+		}
+	}
+	return true
+}
+
+func lookup(mapping *yaml.Node, key string) *yaml.Node {
+	if mapping == nil || mapping.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			return mapping.Content[i+1]
+		}
+	}
+	return nil
+}
+
+func mergeMapping(dst, src *yaml.Node) {
+	for i := 0; i+1 < len(src.Content); i += 2 {
+		key, value := src.Content[i], src.Content[i+1]
+
+		idx := -1
+		for j := 0; j+1 < len(dst.Content); j += 2 {
+			if dst.Content[j].Value == key.Value {
+				idx = j
+				break
+			}
+		}
+
+		switch {
+		case value.Tag == "!!null":
+			if idx >= 0 {
+				dst.Content = append(dst.Content[:idx], dst.Content[idx+2:]...)
+			}
+		case idx < 0:
+			dst.Content = append(dst.Content, clone(key), clone(value))
+		case value.Kind == yaml.MappingNode && dst.Content[idx+1].Kind == yaml.MappingNode:
+			mergeMapping(dst.Content[idx+1], value)
+		default:
+			dst.Content[idx+1] = clone(value)
+		}
+	}
+}
+
+func applyOperation(root *yaml.Node, op operation) error {
+	tokens := strings.Split(op.Path[1:], "/")
+	for i, token := range tokens {
+		tokens[i] = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+	}
+
+	parent := root
+	for _, token := range tokens[:len(tokens)-1] {
+		next, err := childNode(parent, token)
+		if err != nil {
+			return err
+		}
+		parent = next
+	}
+	last := tokens[len(tokens)-1]
+
+	switch parent.Kind {
+	case yaml.MappingNode:
+		for j := 0; j+1 < len(parent.Content); j += 2 {
+			if parent.Content[j].Value != last {
+				continue
+			}
+			if op.Op == "remove" {
+				parent.Content = append(parent.Content[:j], parent.Content[j+2:]...)
+			} else {
+				parent.Content[j+1] = clone(&op.Value)
+			}
+			return nil
+		}
+		if op.Op != "add" {
+			return fmt.Errorf("key %q not found", last)
+		}
+		parent.Content = append(parent.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: last}, clone(&op.Value))
+		return nil
+	case yaml.SequenceNode:
+		if op.Op == "add" && last == "-" {
+			parent.Content = append(parent.Content, clone(&op.Value))
+			return nil
+		}
+		index, err := strconv.Atoi(last)
+		limit := len(parent.Content)
+		if op.Op == "add" {
+			limit++
+		}
+		if err != nil || index < 0 || index >= limit {
+			return fmt.Errorf("invalid index %q", last)
+		}
+		switch op.Op {
+		case "add":
+			parent.Content = append(parent.Content[:index], append([]*yaml.Node{clone(&op.Value)}, parent.Content[index:]...)...)
+		case "replace":
+			parent.Content[index] = clone(&op.Value)
+		case "remove":
+			parent.Content = append(parent.Content[:index], parent.Content[index+1:]...)
+		}
+		return nil
+	default:
+		return fmt.Errorf("cannot index into scalar value")
+	}
+}
+
+func childNode(node *yaml.Node, token string) (*yaml.Node, error) {
+	switch node.Kind {
+	case yaml.MappingNode:
+		if value := lookup(node, token); value != nil {
+			return value, nil
+		}
+		return nil, fmt.Errorf("key %q not found", token)
+	case yaml.SequenceNode:
+		index, err := strconv.Atoi(token)
+		if err != nil || index < 0 || index >= len(node.Content) {
+			return nil, fmt.Errorf("invalid index %q", token)
+		}
+		return node.Content[index], nil
+	default:
+		return nil, fmt.Errorf("cannot index into scalar value")
+	}
+}
+
+// clone deep-copies a node so an overlay applied to several documents never
+// shares nodes between them.
+func clone(node *yaml.Node) *yaml.Node {
+	c := *node
+	c.Content = make([]*yaml.Node, len(node.Content))
+	for i, n := range node.Content {
+		c.Content[i] = clone(n)
+	}
+	return &c
+}
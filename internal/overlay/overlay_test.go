@@ -0,0 +1,170 @@
+package overlay
+
+import (
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+const baseResource = `apiVersion: ai-resource/draft
+kind: Ruleset
+metadata:
+  id: cleanCode
+  name: Clean Code
+spec:
+  rules:
+    naming:
+      name: Naming
+      enforcement: should
+      scope:
+        - files: ["**/*.ts"]
+      body: Use good names.
+    comments:
+      name: Comments
+      enforcement: may
+      body: Comment why, not what.
+`
+
+func parseDoc(t *testing.T, content string) *yaml.Node {
+	var doc yaml.Node
+	if err := yaml.Unmarshal([]byte(content), &doc); err != nil {
+		t.Fatalf("failed to parse document: %v", err)
+	}
+	return &doc
+}
+
+func encodeDoc(t *testing.T, doc *yaml.Node) string {
+	out, err := yaml.Marshal(doc)
+	if err != nil {
+		t.Fatalf("failed to encode document: %v", err)
+	}
+	return string(out)
+}
+
+func TestApplyStrategicMerge(t *testing.T) {
+	o, err := Parse([]byte(`spec:
+  rules:
+    naming:
+      enforcement: must
+    comments: null
+`))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	doc := parseDoc(t, baseResource)
+	applied, err := o.Apply(doc)
+	if err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	if !applied {
+		t.Fatal("Apply() = false, want true")
+	}
+
+	got := encodeDoc(t, doc)
+	if !strings.Contains(got, "enforcement: must") {
+		t.Errorf("enforcement not tightened:\n%s", got)
+	}
+	if !strings.Contains(got, "name: Naming") || !strings.Contains(got, "Use good names.") {
+		t.Errorf("unpatched fields of merged mapping were lost:\n%s", got)
+	}
+	if strings.Contains(got, "comments") {
+		t.Errorf("null value did not delete key:\n%s", got)
+	}
+}
+
+func TestApplyStrategicMergeSelector(t *testing.T) {
+	o, err := Parse([]byte(`kind: Ruleset
+metadata:
+  id: otherRuleset
+spec:
+  rules:
+    naming:
+      enforcement: must
+`))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	doc := parseDoc(t, baseResource)
+	applied, err := o.Apply(doc)
+	if err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	if applied {
+		t.Error("Apply() = true for non-matching metadata.id, want false")
+	}
+	if strings.Contains(encodeDoc(t, doc), "enforcement: must") {
+		t.Error("non-matching overlay modified the document")
+	}
+}
+
+func TestApplyJSONPatch(t *testing.T) {
+	o, err := Parse([]byte(`- op: replace
+  path: /spec/rules/naming/enforcement
+  value: must
+- op: add
+  path: /spec/rules/naming/scope/0/files/-
+  value: "**/*.js"
+- op: remove
+  path: /spec/rules/comments
+`))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	doc := parseDoc(t, baseResource)
+	if _, err := o.Apply(doc); err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+
+	got := encodeDoc(t, doc)
+	for _, want := range []string{"enforcement: must", "**/*.js", "**/*.ts"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("patched document missing %q:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "comments") {
+		t.Errorf("remove did not delete key:\n%s", got)
+	}
+}
+
+func TestApplyJSONPatchMissingPath(t *testing.T) {
+	o, err := Parse([]byte(`- op: replace
+  path: /spec/rules/missing/enforcement
+  value: must
+`))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	_, err = o.Apply(parseDoc(t, baseResource))
+	if err == nil {
+		t.Fatal("Apply() expected error for missing path")
+	}
+	if !strings.Contains(err.Error(), `"missing" not found`) {
+		t.Errorf("Error = %v, want missing key error", err)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{name: "empty", content: ""},
+		{name: "scalar", content: "just a string"},
+		{name: "unknown op", content: "- op: move\n  path: /spec\n"},
+		{name: "relative path", content: "- op: remove\n  path: spec/rules\n"},
+		{name: "missing value", content: "- op: replace\n  path: /spec/rules\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Parse([]byte(tt.content)); err == nil {
+				t.Error("Parse() expected error")
+			}
+		})
+	}
+}
@@ -0,0 +1,55 @@
+// Package suggest finds the likely intended value for a misspelled name, so
+// errors about unknown kinds and targets can say "did you mean Ruleset?".
+package suggest
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// Closest returns the candidate nearest to name by edit distance, ignoring
+// case. It reports false when no candidate is close enough to be a likely
+// typo: more than a third of name's length away, and at least one edit.
+func Closest(name string, candidates []string) (string, bool) {
+	limit := max(utf8.RuneCountInString(name)/3, 1)
+	best, bestDistance := "", limit+1
+	for _, candidate := range candidates {
+		if d := distance(strings.ToLower(name), strings.ToLower(candidate)); d < bestDistance {
+			best, bestDistance = candidate, d
+		}
+	}
+	return best, best != ""
+}
+
+// Hint describes the valid values for an unknown name, leading with the
+// closest one: "did you mean Ruleset? valid kinds: Rule, Ruleset". noun names
+// the values, e.g. "kinds".
+func Hint(name, noun string, candidates []string) string {
+	valid := "valid " + noun + ": " + strings.Join(candidates, ", ")
+	if closest, ok := Closest(name, candidates); ok {
+		return "did you mean " + closest + "? " + valid
+	}
+	return valid
+}
+
+// distance returns the Levenshtein distance between a and b.
+func distance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
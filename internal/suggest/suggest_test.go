@@ -0,0 +1,38 @@
+package suggest
+
+import "testing"
+
+func TestClosest(t *testing.T) {
+	kinds := []string{"Rule", "Ruleset", "Prompt", "Promptset"}
+	tests := []struct {
+		name   string
+		want   string
+		wantOK bool
+	}{
+		{name: "Rulset", want: "Ruleset", wantOK: true},
+		{name: "ruleset", want: "Ruleset", wantOK: true},
+		{name: "Promt", want: "Prompt", wantOK: true},
+		{name: "PromptSets", want: "Promptset", wantOK: true},
+		{name: "Widget", wantOK: false},
+		{name: "", wantOK: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := Closest(tt.name, kinds)
+			if ok != tt.wantOK || got != tt.want {
+				t.Errorf("Closest(%q) = %q, %v, want %q, %v", tt.name, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestHint(t *testing.T) {
+	targets := []string{"markdown", "kiro", "cursor", "claude", "copilot"}
+	if got, want := Hint("cusor", "targets", targets), "did you mean cursor? valid targets: markdown, kiro, cursor, claude, copilot"; got != want {
+		t.Errorf("Hint() = %q, want %q", got, want)
+	}
+	if got, want := Hint("vim", "targets", targets), "valid targets: markdown, kiro, cursor, claude, copilot"; got != want {
+		t.Errorf("Hint() = %q, want %q", got, want)
+	}
+}
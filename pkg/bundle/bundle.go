@@ -0,0 +1,219 @@
+// Package bundle packages resource files into OCI artifacts so shared rule
+// libraries can be published to and pulled from a container registry.
+//
+// A bundle is an OCI image manifest with artifact type ArtifactType. Each
+// resource file is a layer titled with its relative path, and the config blob
+// lists the kind and id of every resource in the bundle. Compiled bundles,
+// of artifact type CompiledArtifactType, hold compiled files instead, so
+// projects can pull rules ready to use without compiling them.
+package bundle
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/jomadu/ai-resource-compiler-go/pkg/loader"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/content/memory"
+	"oras.land/oras-go/v2/registry/remote"
+	"oras.land/oras-go/v2/registry/remote/auth"
+	"oras.land/oras-go/v2/registry/remote/credentials"
+	"oras.land/oras-go/v2/registry/remote/retry"
+)
+
+// Media types used by resource bundles.
+const (
+	ArtifactType      = "application/vnd.ai-resource.bundle.v1"
+	ConfigMediaType   = "application/vnd.ai-resource.bundle.config.v1+json"
+	ResourceMediaType = "application/vnd.ai-resource.resource.v1+yaml"
+
+	CompiledArtifactType = "application/vnd.ai-resource.compiled.v1"
+	CompiledMediaType    = "application/vnd.ai-resource.compiled-file.v1"
+)
+
+// Config is the bundle's config blob.
+type Config struct {
+	Resources []Entry `json:"resources"`
+}
+
+// Entry describes one file in a bundle. Kind and ID are empty for the
+// files of compiled bundles.
+type Entry struct {
+	File string `json:"file"`
+	Kind string `json:"kind,omitempty"`
+	ID   string `json:"id,omitempty"`
+}
+
+// File is a resource or compiled file to publish. Name is the path recorded
+// in the bundle and must be relative; Data is the file content.
+type File struct {
+	Name string
+	Data []byte
+}
+
+// Push packs files into a bundle and pushes it to dst under tag. Every file
+// must parse as a resource. Annotations are added to the manifest.
+func Push(ctx context.Context, dst oras.Target, tag string, files []File, annotations map[string]string) (ocispec.Descriptor, error) {
+	if len(files) == 0 {
+		return ocispec.Descriptor{}, fmt.Errorf("bundle requires at least one resource file")
+	}
+	return push(ctx, dst, tag, ArtifactType, ResourceMediaType, files, annotations, func(name string, data []byte) (Entry, error) {
+		resource, err := (&loader.Loader{}).Parse(data, filepath.Dir(name))
+		if err != nil {
+			return Entry{}, fmt.Errorf("%s: %w", name, err)
+		}
+		return Entry{File: name, Kind: resource.Kind, ID: resource.Metadata.ID}, nil
+	})
+}
+
+// PushCompiled packs compiled files, as arc build writes them, into a
+// compiled bundle and pushes it to dst under tag. Annotations are added to
+// the manifest.
+func PushCompiled(ctx context.Context, dst oras.Target, tag string, files []File, annotations map[string]string) (ocispec.Descriptor, error) {
+	if len(files) == 0 {
+		return ocispec.Descriptor{}, fmt.Errorf("bundle requires at least one compiled file")
+	}
+	return push(ctx, dst, tag, CompiledArtifactType, CompiledMediaType, files, annotations, func(name string, data []byte) (Entry, error) {
+		return Entry{File: name}, nil
+	})
+}
+
+// push packs files into a bundle of artifactType, each a layer of
+// mediaType described in the config by entry, and pushes it to dst.
+func push(ctx context.Context, dst oras.Target, tag, artifactType, mediaType string, files []File, annotations map[string]string, entry func(name string, data []byte) (Entry, error)) (ocispec.Descriptor, error) {
+
+	store := memory.New()
+	var config Config
+	var layers []ocispec.Descriptor
+	seen := make(map[string]bool)
+
+	for _, f := range files {
+		name := filepath.ToSlash(filepath.Clean(f.Name))
+		if !filepath.IsLocal(f.Name) {
+			return ocispec.Descriptor{}, fmt.Errorf("bundle file path must be relative and within the working directory: %s", f.Name)
+		}
+		if seen[name] {
+			return ocispec.Descriptor{}, fmt.Errorf("duplicate bundle file: %s", name)
+		}
+		seen[name] = true
+
+		e, err := entry(name, f.Data)
+		if err != nil {
+			return ocispec.Descriptor{}, err
+		}
+		config.Resources = append(config.Resources, e)
+
+		desc := content.NewDescriptorFromBytes(mediaType, f.Data)
+		desc.Annotations = map[string]string{ocispec.AnnotationTitle: name}
+		if err := store.Push(ctx, desc, bytes.NewReader(f.Data)); err != nil {
+			return ocispec.Descriptor{}, err
+		}
+		layers = append(layers, desc)
+	}
+
+	configData, err := json.Marshal(config)
+	if err != nil {
+		return ocispec.Descriptor{}, fmt.Errorf("failed to encode bundle config: %w", err)
+	}
+	configDesc := content.NewDescriptorFromBytes(ConfigMediaType, configData)
+	if err := store.Push(ctx, configDesc, bytes.NewReader(configData)); err != nil {
+		return ocispec.Descriptor{}, err
+	}
+
+	manifest, err := oras.PackManifest(ctx, store, oras.PackManifestVersion1_1, artifactType, oras.PackManifestOptions{
+		Layers:              layers,
+		ConfigDescriptor:    &configDesc,
+		ManifestAnnotations: annotations,
+	})
+	if err != nil {
+		return ocispec.Descriptor{}, fmt.Errorf("failed to pack bundle: %w", err)
+	}
+	if err := store.Tag(ctx, manifest, tag); err != nil {
+		return ocispec.Descriptor{}, err
+	}
+
+	if _, err := oras.Copy(ctx, store, tag, dst, tag, oras.DefaultCopyOptions); err != nil {
+		return ocispec.Descriptor{}, fmt.Errorf("failed to push bundle: %w", err)
+	}
+	return manifest, nil
+}
+
+// Pull fetches the bundle tagged ref from src and writes its resource files,
+// or the files of a compiled bundle, under dir, returning the paths written.
+func Pull(ctx context.Context, src oras.ReadOnlyTarget, ref, dir string) ([]string, error) {
+	store := memory.New()
+	desc, err := oras.Copy(ctx, src, ref, store, ref, oras.DefaultCopyOptions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to pull bundle: %w", err)
+	}
+
+	data, err := content.FetchAll(ctx, store, desc)
+	if err != nil {
+		return nil, err
+	}
+	var manifest ocispec.Manifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("failed to parse bundle manifest: %w", err)
+	}
+	var mediaType string
+	switch manifest.ArtifactType {
+	case ArtifactType:
+		mediaType = ResourceMediaType
+	case CompiledArtifactType:
+		mediaType = CompiledMediaType
+	default:
+		return nil, fmt.Errorf("%s is not a resource bundle (artifact type %q)", ref, manifest.ArtifactType)
+	}
+
+	var written []string
+	for _, layer := range manifest.Layers {
+		if layer.MediaType != mediaType {
+			continue
+		}
+		name := layer.Annotations[ocispec.AnnotationTitle]
+		if name == "" || !filepath.IsLocal(filepath.FromSlash(name)) {
+			return nil, fmt.Errorf("bundle contains invalid file path: %q", name)
+		}
+
+		data, err := content.FetchAll(ctx, store, layer)
+		if err != nil {
+			return nil, err
+		}
+		target := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return nil, fmt.Errorf("failed to create directory: %w", err)
+		}
+		if err := os.WriteFile(target, data, 0644); err != nil {
+			return nil, fmt.Errorf("failed to write file: %w", err)
+		}
+		written = append(written, target)
+	}
+	return written, nil
+}
+
+// Repository returns a remote repository for reference (registry/name[:tag]),
+// authenticating with the credentials stored by docker login.
+func Repository(reference string, plainHTTP bool) (*remote.Repository, error) {
+	repo, err := remote.NewRepository(reference)
+	if err != nil {
+		return nil, fmt.Errorf("invalid registry reference %q: %w", reference, err)
+	}
+	repo.PlainHTTP = plainHTTP
+
+	store, err := credentials.NewStoreFromDocker(credentials.StoreOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load registry credentials: %w", err)
+	}
+	repo.Client = &auth.Client{
+		Client:     retry.DefaultClient,
+		Cache:      auth.NewCache(),
+		Credential: credentials.Credential(store),
+	}
+	return repo, nil
+}
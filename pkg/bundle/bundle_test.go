@@ -0,0 +1,205 @@
+package bundle
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/content/memory"
+)
+
+const ruleYAML = `apiVersion: ai-resource/draft
+kind: Rule
+metadata:
+  id: noSecrets
+spec:
+  enforcement: must
+  body: Never commit secrets.
+`
+
+const rulesetYAML = `apiVersion: ai-resource/draft
+kind: Ruleset
+metadata:
+  id: goStyle
+spec:
+  rules:
+    formatting:
+      enforcement: must
+      body: Run gofmt.
+`
+
+func TestPushPull(t *testing.T) {
+	ctx := context.Background()
+	registry := memory.New()
+
+	files := []File{
+		{Name: "rules/no-secrets.yaml", Data: []byte(ruleYAML)},
+		{Name: "go-style.yaml", Data: []byte(rulesetYAML)},
+	}
+	desc, err := Push(ctx, registry, "1.0.0", files, map[string]string{ocispec.AnnotationVersion: "1.0.0"})
+	if err != nil {
+		t.Fatalf("Push() error = %v", err)
+	}
+
+	data, err := content.FetchAll(ctx, registry, desc)
+	if err != nil {
+		t.Fatalf("FetchAll() error = %v", err)
+	}
+	var manifest ocispec.Manifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if manifest.ArtifactType != ArtifactType {
+		t.Errorf("ArtifactType = %q, want %q", manifest.ArtifactType, ArtifactType)
+	}
+	if manifest.Annotations[ocispec.AnnotationVersion] != "1.0.0" {
+		t.Errorf("version annotation = %q, want 1.0.0", manifest.Annotations[ocispec.AnnotationVersion])
+	}
+
+	configData, err := content.FetchAll(ctx, registry, manifest.Config)
+	if err != nil {
+		t.Fatalf("FetchAll() config error = %v", err)
+	}
+	var config Config
+	if err := json.Unmarshal(configData, &config); err != nil {
+		t.Fatalf("Unmarshal() config error = %v", err)
+	}
+	want := []Entry{
+		{File: "rules/no-secrets.yaml", Kind: "Rule", ID: "noSecrets"},
+		{File: "go-style.yaml", Kind: "Ruleset", ID: "goStyle"},
+	}
+	if len(config.Resources) != len(want) {
+		t.Fatalf("Resources = %v, want %v", config.Resources, want)
+	}
+	for i := range want {
+		if config.Resources[i] != want[i] {
+			t.Errorf("Resources[%d] = %v, want %v", i, config.Resources[i], want[i])
+		}
+	}
+
+	dir := t.TempDir()
+	written, err := Pull(ctx, registry, "1.0.0", dir)
+	if err != nil {
+		t.Fatalf("Pull() error = %v", err)
+	}
+	if len(written) != 2 {
+		t.Fatalf("Pull() wrote %v, want 2 files", written)
+	}
+	got, err := os.ReadFile(filepath.Join(dir, "rules", "no-secrets.yaml"))
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	if string(got) != ruleYAML {
+		t.Errorf("pulled content = %q, want original", got)
+	}
+}
+
+func TestPushErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		files   []File
+		wantErr string
+	}{
+		{"no files", nil, "at least one resource file"},
+		{"escaping path", []File{{Name: "../rule.yaml", Data: []byte(ruleYAML)}}, "must be relative"},
+		{"duplicate", []File{{Name: "a.yaml", Data: []byte(ruleYAML)}, {Name: "./a.yaml", Data: []byte(ruleYAML)}}, "duplicate bundle file"},
+		{"invalid resource", []File{{Name: "bad.yaml", Data: []byte("kind: [")}}, "bad.yaml"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Push(context.Background(), memory.New(), "1.0.0", tt.files, nil)
+			if err == nil {
+				t.Fatal("Push() expected error")
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPullRejectsOtherArtifacts(t *testing.T) {
+	ctx := context.Background()
+	registry := memory.New()
+
+	desc, err := Push(ctx, registry, "1.0.0", []File{{Name: "rule.yaml", Data: []byte(ruleYAML)}}, nil)
+	if err != nil {
+		t.Fatalf("Push() error = %v", err)
+	}
+	data, _ := content.FetchAll(ctx, registry, desc)
+	var manifest ocispec.Manifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	manifest.ArtifactType = "application/vnd.example.other"
+	other, _ := json.Marshal(manifest)
+	otherDesc := content.NewDescriptorFromBytes(ocispec.MediaTypeImageManifest, other)
+	if err := registry.Push(ctx, otherDesc, strings.NewReader(string(other))); err != nil {
+		t.Fatalf("Push() manifest error = %v", err)
+	}
+	if err := registry.Tag(ctx, otherDesc, "other"); err != nil {
+		t.Fatalf("Tag() error = %v", err)
+	}
+
+	_, err = Pull(ctx, registry, "other", t.TempDir())
+	if err == nil || !strings.Contains(err.Error(), "not a resource bundle") {
+		t.Errorf("Pull() error = %v, want artifact type error", err)
+	}
+}
+
+func TestPushPullCompiled(t *testing.T) {
+	ctx := context.Background()
+	registry := memory.New()
+
+	files := []File{
+		{Name: ".cursor/rules/no-secrets.mdc", Data: []byte("Never commit secrets.\n")},
+		{Name: "AGENTS.md", Data: []byte("# Rules\n")},
+	}
+	desc, err := PushCompiled(ctx, registry, "1.0.0", files, nil)
+	if err != nil {
+		t.Fatalf("PushCompiled() error = %v", err)
+	}
+
+	data, err := content.FetchAll(ctx, registry, desc)
+	if err != nil {
+		t.Fatalf("FetchAll() error = %v", err)
+	}
+	var manifest ocispec.Manifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if manifest.ArtifactType != CompiledArtifactType {
+		t.Errorf("ArtifactType = %q, want %q", manifest.ArtifactType, CompiledArtifactType)
+	}
+	for _, layer := range manifest.Layers {
+		if layer.MediaType != CompiledMediaType {
+			t.Errorf("layer MediaType = %q, want %q", layer.MediaType, CompiledMediaType)
+		}
+	}
+
+	dir := t.TempDir()
+	written, err := Pull(ctx, registry, "1.0.0", dir)
+	if err != nil {
+		t.Fatalf("Pull() error = %v", err)
+	}
+	if len(written) != 2 {
+		t.Fatalf("Pull() wrote %v, want 2 files", written)
+	}
+	got, err := os.ReadFile(filepath.Join(dir, ".cursor", "rules", "no-secrets.mdc"))
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	if string(got) != "Never commit secrets.\n" {
+		t.Errorf("pulled content = %q, want original", got)
+	}
+
+	if _, err := PushCompiled(ctx, registry, "2.0.0", nil, nil); err == nil || !strings.Contains(err.Error(), "at least one compiled file") {
+		t.Errorf("PushCompiled() without files error = %v", err)
+	}
+}
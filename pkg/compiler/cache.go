@@ -0,0 +1,118 @@
+package compiler
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/jomadu/ai-resource-compiler-go/internal/format"
+	"gopkg.in/yaml.v3"
+)
+
+// cacheVersion is part of every cache key; bump it when compiled output
+// changes so stale entries are not reused.
+const cacheVersion = 2
+
+// cacheKey identifies the output of compiling resource with tc and options.
+// The second return value is false if the resource cannot be keyed, in which
+// case the cache is bypassed.
+func cacheKey(target Target, tc TargetCompiler, options map[string]any, resource *Resource) (string, bool) {
+	data, err := yaml.Marshal(resource)
+	if err != nil {
+		return "", false
+	}
+	key, err := json.Marshal(struct {
+		Version  int
+		Target   Target
+		Compiler string
+		Options  map[string]any
+		Resource string
+		Source   string
+		Assets   string
+	}{cacheVersion, target, fmt.Sprintf("%T", tc), options, string(data), resource.Source, assetDigest(resource)})
+	if err != nil {
+		return "", false
+	}
+	sum := sha256.Sum256(key)
+	return hex.EncodeToString(sum[:]), true
+}
+
+// assetDigest hashes the data of the assets of resource, which its YAML
+// leaves out.
+func assetDigest(resource *Resource) string {
+	var lists [][]format.Asset
+	switch spec := resource.Spec.(type) {
+	case *format.Prompt:
+		lists = append(lists, spec.Spec.Assets)
+	case *format.Promptset:
+		lists = append(lists, spec.Spec.Assets)
+		for _, id := range spec.Spec.PromptIDs() {
+			lists = append(lists, spec.Spec.Prompts[id].Assets)
+		}
+	}
+	h := sha256.New()
+	for _, assets := range lists {
+		for _, asset := range assets {
+			sum := sha256.Sum256(asset.Data)
+			h.Write(sum[:])
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// cachedResult is a CompilationResult as cached. Assets are kept in Data,
+// as JSON strings cannot hold binary content.
+type cachedResult struct {
+	Path    string
+	Content string `json:",omitempty"`
+	Data    []byte `json:",omitempty"`
+	Asset   bool   `json:",omitempty"`
+}
+
+// readCache returns the cached results for key, if present.
+func (c *Compiler) readCache(key string) ([]CompilationResult, bool) {
+	data, err := os.ReadFile(filepath.Join(c.cacheDir, key+".json"))
+	if err != nil {
+		return nil, false
+	}
+	var cached []cachedResult
+	if err := json.Unmarshal(data, &cached); err != nil {
+		return nil, false
+	}
+	results := make([]CompilationResult, len(cached))
+	for i, r := range cached {
+		results[i] = CompilationResult{Path: r.Path, Content: r.Content, Asset: r.Asset}
+		if r.Asset {
+			results[i].Content = string(r.Data)
+		}
+	}
+	return results, true
+}
+
+// writeCache stores results under key. Failures are logged and otherwise
+// ignored, since the cache only saves work.
+func (c *Compiler) writeCache(key string, results []CompilationResult) {
+	cached := make([]cachedResult, len(results))
+	for i, r := range results {
+		cached[i] = cachedResult{Path: r.Path, Content: r.Content}
+		if r.Asset {
+			cached[i] = cachedResult{Path: r.Path, Data: []byte(r.Content), Asset: true}
+		}
+	}
+	data, err := json.Marshal(cached)
+	if err == nil {
+		err = os.MkdirAll(c.cacheDir, 0755)
+	}
+	if err == nil {
+		tmp := filepath.Join(c.cacheDir, key+".tmp")
+		if err = os.WriteFile(tmp, data, 0644); err == nil {
+			err = os.Rename(tmp, filepath.Join(c.cacheDir, key+".json"))
+		}
+	}
+	if err != nil {
+		c.logger.Warn("failed to write compile cache", "dir", c.cacheDir, "error", err)
+	}
+}
@@ -0,0 +1,42 @@
+package compiler
+
+import "fmt"
+
+// pathOwner is the resource a result path was first compiled from, and the
+// content it was compiled to.
+type pathOwner struct {
+	resource *Resource
+	content  string
+}
+
+// claimPaths records the paths of results, compiled for target from
+// resource, in owners, and returns results without those already compiled
+// identically from an earlier resource, such as the same rule defined in two
+// files, which are logged. A result at a path an earlier resource compiled
+// to different content is an error naming both resources.
+func (c *Compiler) claimPaths(owners map[string]pathOwner, target Target, resource *Resource, results []CompilationResult) ([]CompilationResult, error) {
+	kept := results[:0:0]
+	for _, result := range results {
+		owner, ok := owners[result.Path]
+		if !ok {
+			owners[result.Path] = pathOwner{resource: resource, content: result.Content}
+			kept = append(kept, result)
+			continue
+		}
+		if owner.content != result.Content {
+			return nil, fmt.Errorf("%w: %s is also compiled, differently, from %s", ErrPathCollision, result.Path, resourceName(owner.resource))
+		}
+		c.logger.Warn("identical result compiled from several resources", "target", target, "path", result.Path,
+			"resource", resourceName(resource), "first", resourceName(owner.resource))
+	}
+	return kept, nil
+}
+
+// resourceName names resource in messages: by the file it was loaded from,
+// or else by its kind and ID.
+func resourceName(resource *Resource) string {
+	if resource.Source != "" {
+		return resource.Source
+	}
+	return resource.Kind + " " + resource.Metadata.ID
+}
@@ -0,0 +1,42 @@
+package compiler
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestCompileAll_PathCollisions(t *testing.T) {
+	c := NewCompiler(WithoutDefaults(), WithTarget(TargetMarkdown, &linkingCompiler{}))
+	opts := CompileOptions{Targets: []Target{TargetMarkdown}}
+	first := testRule("Name things well.")
+	first.Source = "team/naming.yaml"
+	same := testRule("Name things well.")
+	same.Source = "vendor/naming.yaml"
+	different := testRule("Name things briefly.")
+	different.Source = "legacy/naming.yaml"
+
+	results, err := c.CompileAll([]*Resource{first, same}, opts)
+	if err != nil {
+		t.Fatalf("CompileAll() error = %v", err)
+	}
+	if len(results) != 1 || results[0].Path != "testRule.md" {
+		t.Errorf("CompileAll() = %+v, want testRule.md once", results)
+	}
+
+	_, err = c.CompileAll([]*Resource{first, different}, opts)
+	if !errors.Is(err, ErrPathCollision) {
+		t.Fatalf("CompileAll() error = %v, want ErrPathCollision", err)
+	}
+	for _, want := range []string{"legacy/naming.yaml", "testRule.md", "team/naming.yaml"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q does not name %s", err, want)
+		}
+	}
+
+	// A prefix renames both alike, so they still collide.
+	opts.Prefix = "org-"
+	if _, err := c.CompileAll([]*Resource{first, different}, opts); !errors.Is(err, ErrPathCollision) || !strings.Contains(err.Error(), "org-testRule.md") {
+		t.Errorf("CompileAll() error = %v, want a collision at org-testRule.md", err)
+	}
+}
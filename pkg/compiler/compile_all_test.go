@@ -0,0 +1,88 @@
+package compiler
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/jomadu/ai-resource-compiler-go/internal/format"
+)
+
+// mockIndexCompiler writes one index of every resource compiled together.
+type mockIndexCompiler struct {
+	mockMarkdownCompiler
+}
+
+func (m *mockIndexCompiler) CompileAll(resources []*Resource) ([]CompilationResult, error) {
+	ids := make([]string, len(resources))
+	for i, resource := range resources {
+		ids[i] = resource.Metadata.ID
+	}
+	return []CompilationResult{{Path: "index.md", Content: strings.Join(ids, "\n")}}, nil
+}
+
+func namedRule(id, body string) *Resource {
+	resource := testRule(body)
+	resource.Metadata.ID = id
+	resource.Spec.(*format.Rule).Metadata.ID = id
+	return resource
+}
+
+func TestCompileAll(t *testing.T) {
+	c := setupCompiler()
+	c.RegisterTarget("index", &mockIndexCompiler{})
+
+	resources := []*Resource{namedRule("first", "First"), namedRule("second", "${name}")}
+	results, err := c.CompileAll(resources, CompileOptions{
+		Targets:   []Target{TargetMarkdown, "index"},
+		Variables: map[string]string{"name": "Second"},
+	})
+	if err != nil {
+		t.Fatalf("CompileAll() error = %v", err)
+	}
+	want := []CompilationResult{
+		{Path: "first.md", Content: "mock content", Stats: ResultStats{Bytes: 12, Tokens: 3}},
+		{Path: "second.md", Content: "mock content", Stats: ResultStats{Bytes: 12, Tokens: 3}},
+		{Path: "index.md", Content: "first\nsecond", Stats: ResultStats{Bytes: 12, Tokens: 3}},
+	}
+	if !reflect.DeepEqual(results, want) {
+		t.Errorf("CompileAll() = %+v, want %+v", results, want)
+	}
+
+	// Compile still compiles the resource alone.
+	results, err = c.Compile(resources[0], CompileOptions{Targets: []Target{"index"}})
+	if err != nil || len(results) != 1 || results[0].Path != "first.md" {
+		t.Errorf("Compile() = %+v, %v, want first.md", results, err)
+	}
+}
+
+func TestCompileAll_Errors(t *testing.T) {
+	c := setupCompiler()
+	c.RegisterTarget("index", &mockIndexCompiler{})
+	bad := namedRule("bad.id", "Body")
+	bad.Source = "bad.yaml"
+	old := namedRule("old", "Body")
+	old.APIVersion = "ai-resource/v0"
+	resources := []*Resource{namedRule("good", "Body"), bad}
+
+	if _, err := c.CompileAll(resources, CompileOptions{}); !errors.Is(err, ErrNoTargets) {
+		t.Errorf("CompileAll() without targets error = %v, want ErrNoTargets", err)
+	}
+
+	_, err := c.CompileAll(resources, CompileOptions{Targets: []Target{"index"}})
+	var compileErr *CompileError
+	if !errors.As(err, &compileErr) || compileErr.File != "bad.yaml" {
+		t.Errorf("CompileAll() error = %v, want a CompileError in bad.yaml", err)
+	}
+
+	opts := CompileOptions{Targets: []Target{TargetMarkdown, "index"}, CollectErrors: true}
+	_, err = c.CompileAll([]*Resource{namedRule("good", "Body"), old}, opts)
+	var list CompileErrors
+	if !errors.As(err, &list) || len(list) != 2 || list[0].Target != TargetMarkdown || list[1].Target != "index" {
+		t.Fatalf("CompileAll() error = %v, want one error for markdown and one for index", err)
+	}
+	if !errors.Is(err, ErrUnsupportedVersion) {
+		t.Error("errors.Is(err, ErrUnsupportedVersion) = false")
+	}
+}
@@ -1,32 +1,73 @@
 package compiler
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
 	"sync"
+	"time"
+
+	"github.com/jomadu/ai-resource-compiler-go/internal/suggest"
 )
 
 var (
-	defaultCompiler     *Compiler
-	defaultCompilerOnce sync.Once
+	defaultTargets   = make(map[Target]TargetCompiler)
+	defaultTargetsMu sync.Mutex
 )
 
 // Compiler orchestrates compilation across multiple target formats.
 type Compiler struct {
-	targets map[Target]TargetCompiler
+	targets   map[Target]TargetCompiler
+	logger    *slog.Logger
+	cacheDir  string
+	limits    Limits
+	metrics   Metrics
+	tokenizer Tokenizer
+
+	validators []Validator
+	preHooks   []PreCompileHook
+	postHooks  []PostCompileHook
 }
 
-// NewCompiler creates a new compiler instance with all built-in targets registered.
-func NewCompiler() *Compiler {
-	defaultCompilerOnce.Do(func() {
-		defaultCompiler = &Compiler{
-			targets: make(map[Target]TargetCompiler),
-		}
-	})
-	// Return a copy with the same registered targets
+// NewCompiler creates a new compiler instance configured by opts. Unless
+// WithoutDefaults is given, it starts with the targets registered with
+// RegisterDefaultTarget, which are the built-in targets once pkg/targets is
+// imported; compilers given their targets explicitly depend on no global
+// state, so any number can be used side by side.
+func NewCompiler(opts ...Option) *Compiler {
+	cfg := &config{
+		targets:   make(map[Target]TargetCompiler),
+		logger:    slog.New(slog.DiscardHandler),
+		metrics:   noMetrics{},
+		tokenizer: TokenizerFunc(EstimateTokens),
+	}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
 	c := &Compiler{
-		targets: make(map[Target]TargetCompiler),
+		targets:   make(map[Target]TargetCompiler),
+		logger:    cfg.logger,
+		cacheDir:  cfg.cacheDir,
+		limits:    cfg.limits,
+		metrics:   cfg.metrics,
+		tokenizer: cfg.tokenizer,
+
+		validators: cfg.validators,
+		preHooks:   cfg.preHooks,
+		postHooks:  cfg.postHooks,
+	}
+	if !cfg.noDefaults {
+		defaultTargetsMu.Lock()
+		for k, v := range defaultTargets {
+			c.targets[k] = v
+		}
+		defaultTargetsMu.Unlock()
 	}
-	for k, v := range defaultCompiler.targets {
+	for k, v := range cfg.targets {
 		c.targets[k] = v
 	}
 	return c
@@ -41,63 +82,555 @@ func (c *Compiler) RegisterTarget(target Target, compiler TargetCompiler) error
 	return nil
 }
 
-// RegisterDefaultTarget registers a target compiler in the default compiler instance.
-// This is used by target packages to register themselves during initialization.
+// RegisterDefaultTarget registers a target compiler with the built-in
+// targets used by NewCompiler. This is used by target packages to register
+// themselves during initialization.
+//
+// Deprecated: registration is global, so it affects every compiler in the
+// program. Pass targets to NewCompiler with WithTarget or WithTargets
+// instead, e.g. WithTargets(targets.Defaults()...) after WithoutDefaults.
 func RegisterDefaultTarget(target Target, compiler TargetCompiler) {
-	defaultCompilerOnce.Do(func() {
-		defaultCompiler = &Compiler{
-			targets: make(map[Target]TargetCompiler),
-		}
-	})
-	defaultCompiler.targets[target] = compiler
+	defaultTargetsMu.Lock()
+	defer defaultTargetsMu.Unlock()
+	defaultTargets[target] = compiler
 }
 
 // Compile transforms a resource into one or more target formats.
 func (c *Compiler) Compile(resource *Resource, opts CompileOptions) ([]CompilationResult, error) {
-	// Step 1: Validate resource
-	if resource.APIVersion == "" {
-		return nil, fmt.Errorf("missing apiVersion")
+	return c.CompileContext(context.Background(), resource, opts)
+}
+
+// CompileContext compiles resource as Compile does, stopping with ctx's
+// error once ctx is done. The context is checked before each target
+// compiles and passed to targets that implement ContextTarget.
+func (c *Compiler) CompileContext(ctx context.Context, resource *Resource, opts CompileOptions) ([]CompilationResult, error) {
+	results, err := c.compile(ctx, resource, opts)
+	c.metrics.CompileDone(resource.Kind, err)
+	return results, err
+}
+
+func (c *Compiler) compile(ctx context.Context, resource *Resource, opts CompileOptions) ([]CompilationResult, error) {
+	var results []CompilationResult
+	err := c.compileTo(ctx, resource, opts, func(_ Target, result CompilationResult) error {
+		result.Stats = c.resultStats(result)
+		results = append(results, result)
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
-	if resource.Kind == "" {
-		return nil, fmt.Errorf("missing kind")
+	return results, nil
+}
+
+// CompileTo compiles resource as Compile does, but passes each file to sink
+// as soon as its target has compiled it instead of returning them all, so
+// the results of one target are released before the next compiles. Files
+// of targets that compiled before an error have already been written to
+// sink; with CollectErrors, so have those of every target that succeeded.
+func (c *Compiler) CompileTo(resource *Resource, opts CompileOptions, sink OutputSink) error {
+	return c.CompileToContext(context.Background(), resource, opts, sink)
+}
+
+// CompileToContext compiles resource as CompileTo does, stopping with ctx's
+// error once ctx is done, as CompileContext does.
+func (c *Compiler) CompileToContext(ctx context.Context, resource *Resource, opts CompileOptions, sink OutputSink) error {
+	err := c.compileTo(ctx, resource, opts, func(target Target, result CompilationResult) error {
+		return sink.WriteResult(target, result.Path, result.Content)
+	})
+	c.metrics.CompileDone(resource.Kind, err)
+	return err
+}
+
+// compileTo compiles resource, passing each result to emit.
+func (c *Compiler) compileTo(ctx context.Context, resource *Resource, opts CompileOptions, emit func(Target, CompilationResult) error) error {
+	resource, err := c.prepare(resource, opts)
+	if err != nil || resource == nil {
+		return err
+	}
+
+	// Step 4: Compile for each target, passing its results on
+	limits := c.limits.WithDefaults()
+	var errs CompileErrors
+	outputSize := 0
+	for _, target := range opts.Targets {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		start := time.Now()
+		targetResults, err := c.compileFor(ctx, target, resource, opts)
+		if err == nil {
+			targetResults, err = c.rewriteLinks(target, []*Resource{resource}, targetResults, opts)
+		}
+		if err == nil {
+			targetResults, err = c.postCompile(target, targetResults)
+		}
+		c.metrics.TargetDone(target, time.Since(start), err)
+		if err != nil && opts.CollectErrors {
+			errs = append(errs, locate(resource, target, err))
+			continue
+		}
+		if err != nil {
+			return compileError(resource, target, err)
+		}
+		for _, result := range targetResults {
+			if outputSize += len(result.Content); Exceeds(outputSize, limits.MaxOutputSize) {
+				err := fmt.Errorf("%w: output of %s exceeds %d bytes", ErrLimitExceeded, resource.Metadata.ID, limits.MaxOutputSize)
+				return compileError(resource, target, err)
+			}
+			if opts.TokenBudget > 0 && !result.Asset {
+				c.checkBudget(target, result.Path, c.tokenizer.CountTokens(result.Content), opts.TokenBudget)
+			}
+			if err := emit(target, result); err != nil {
+				return fmt.Errorf("writing %s for target %s: %w", result.Path, target, err)
+			}
+		}
 	}
-	if resource.Metadata.ID == "" {
-		return nil, fmt.Errorf("missing metadata.id")
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// prepare validates resource and returns the copy of it targets compile,
+// with its items selected, its bodies localized, its variables and
+// templates expanded, and its bodies transformed, once the pre-compile
+// hooks accept it. It returns nil if MinEnforcement or Tags leaves nothing
+// to compile.
+func (c *Compiler) prepare(resource *Resource, opts CompileOptions) (*Resource, error) {
+	// Step 1: Validate resource
+	if problems := c.validateResource(resource); len(problems) > 0 {
+		if !opts.CollectErrors {
+			return nil, compileError(resource, "", problems[0])
+		}
+		errs := make(CompileErrors, len(problems))
+		for i, problem := range problems {
+			errs[i] = locate(resource, "", problem)
+		}
+		return nil, errs
 	}
 
 	// Step 2: Validate options
 	if len(opts.Targets) == 0 {
-		return nil, fmt.Errorf("no targets specified")
+		return nil, ErrNoTargets
+	}
+
+	// Step 3: Select items and body variants, substitute variables, and
+	// execute templates
+	filtered, err := filterItems(resource, opts.Only, opts.Exclude)
+	if err != nil {
+		return nil, compileError(resource, "", err)
+	}
+	if filtered, err = filterEnforcement(filtered, opts.MinEnforcement); err != nil {
+		return nil, compileError(resource, "", err)
+	}
+	if filtered != nil {
+		filtered = filterTags(filtered, opts.Tags)
+	}
+	if filtered == nil {
+		return nil, nil
+	}
+	resource = filtered
+	resource = localize(resource, opts.Locale)
+	resource = expandVariables(resource, opts.Variables)
+	if opts.Templates {
+		executed, err := executeTemplates(resource, opts, c.limits.WithDefaults().MaxBodySize)
+		if err != nil {
+			return nil, compileError(resource, "", err)
+		}
+		resource = executed
+	}
+	resource = transformBodies(resource, opts.Transformers)
+	if err := checkBodySizes(resource, c.limits.WithDefaults().MaxBodySize); err != nil {
+		return nil, compileError(resource, "", err)
 	}
+	if err := c.preCompile(resource); err != nil {
+		return nil, compileError(resource, "", err)
+	}
+	return resource, nil
+}
+
+// CompileAll compiles resources together into one or more target formats.
+// Targets implementing AggregateTargetCompiler see every resource at once
+// and can produce combined files, such as an index or a single document.
+// Other targets compile each resource as Compile does, and the results of a
+// MergingTarget are merged across all of them. Results are returned target
+// by target, in the order of opts.Targets. Rulesets extending others among
+// resources are resolved first; see ResolveExtends.
+func (c *Compiler) CompileAll(resources []*Resource, opts CompileOptions) ([]CompilationResult, error) {
+	return c.CompileAllContext(context.Background(), resources, opts)
+}
 
-	// Step 3: Compile for each target
+// CompileAllContext compiles resources as CompileAll does, stopping with
+// ctx's error once ctx is done. The context is checked before each resource
+// is prepared and compiled and passed to targets that implement
+// ContextTarget.
+func (c *Compiler) CompileAllContext(ctx context.Context, resources []*Resource, opts CompileOptions) ([]CompilationResult, error) {
 	var results []CompilationResult
-	for _, target := range opts.Targets {
-		compiler, ok := c.targets[target]
-		if !ok {
-			return nil, fmt.Errorf("unknown target: %s", target)
+	resolved, err := ResolveExtends(resources)
+	if err == nil {
+		results, err = c.compileAll(ctx, resolved, opts)
+	}
+	for _, resource := range resources {
+		c.metrics.CompileDone(resource.Kind, err)
+	}
+	return results, err
+}
+
+func (c *Compiler) compileAll(ctx context.Context, resources []*Resource, opts CompileOptions) ([]CompilationResult, error) {
+	if err := CheckItems(resources, opts.Only, opts.Exclude); err != nil {
+		return nil, err
+	}
+	var errs CompileErrors
+	prepared := make([]*Resource, 0, len(resources))
+	for _, resource := range resources {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		p, err := c.prepare(resource, opts)
+		if err != nil && opts.CollectErrors && !errors.Is(err, ErrNoTargets) {
+			errs = collectErrors(errs, resource, "", err)
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if p != nil {
+			prepared = append(prepared, p)
 		}
+	}
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	if len(opts.Targets) == 0 {
+		return nil, ErrNoTargets
+	}
 
-		// Check version compatibility
-		supported := false
-		for _, version := range compiler.SupportedVersions() {
-			if version == resource.APIVersion {
-				supported = true
-				break
+	limits := c.limits.WithDefaults()
+	var results []CompilationResult
+	for _, target := range opts.Targets {
+		start := time.Now()
+		targetResults, err := c.compileAllFor(ctx, target, prepared, opts)
+		if err == nil {
+			if targetResults, err = c.rewriteLinks(target, prepared, targetResults, opts); err == nil {
+				targetResults, err = c.postCompile(target, targetResults)
+			}
+			if err != nil {
+				err = &CompileError{Target: target, Err: err}
 			}
 		}
-		if !supported {
-			return nil, fmt.Errorf("target %s does not support apiVersion: %s", target, resource.APIVersion)
+		c.metrics.TargetDone(target, time.Since(start), err)
+		if err != nil && opts.CollectErrors {
+			errs = collectErrors(errs, nil, target, err)
+			continue
 		}
-
-		// Compile resource
-		targetResults, err := compiler.Compile(resource)
 		if err != nil {
 			return nil, err
 		}
 		results = append(results, targetResults...)
 	}
+	if len(errs) > 0 {
+		return nil, errs
+	}
+
+	outputSize := 0
+	for i, result := range results {
+		if outputSize += len(result.Content); Exceeds(outputSize, limits.MaxOutputSize) {
+			return nil, fmt.Errorf("%w: output of %d resources exceeds %d bytes", ErrLimitExceeded, len(resources), limits.MaxOutputSize)
+		}
+		results[i].Stats = c.resultStats(result)
+	}
+	return results, nil
+}
+
+// compileAllFor compiles resources, already prepared, for a single target.
+func (c *Compiler) compileAllFor(ctx context.Context, target Target, resources []*Resource, opts CompileOptions) ([]CompilationResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if _, ok := c.targets[target].(AggregateTargetCompiler); !ok {
+		var results []CompilationResult
+		var errs CompileErrors
+		owners := make(map[string]pathOwner)
+		for _, resource := range resources {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+			resourceResults, err := c.compileFor(ctx, target, resource, opts)
+			if err == nil && !c.Merges(target) {
+				// Merging targets combine results sharing a path instead.
+				resourceResults, err = c.claimPaths(owners, target, resource, resourceResults)
+			}
+			if err != nil && opts.CollectErrors {
+				errs = append(errs, locate(resource, target, err))
+				continue
+			}
+			if err != nil {
+				return nil, compileError(resource, target, err)
+			}
+			results = append(results, resourceResults...)
+		}
+		if len(errs) > 0 {
+			return nil, errs
+		}
+		merged, err := c.Merge(target, results)
+		if err != nil {
+			return nil, &CompileError{Target: target, Err: err}
+		}
+		return merged, nil
+	}
 
-	// Step 4: Return aggregated results
+	// Every resource must be of a version the target compiles; the options
+	// are the same for each.
+	var configured TargetCompiler
+	for _, resource := range resources {
+		compiler, _, err := c.configuredTarget(target, resource, opts)
+		if err != nil {
+			return nil, compileError(resource, target, err)
+		}
+		configured = compiler
+	}
+	aggregate, ok := configured.(AggregateTargetCompiler)
+	if !ok {
+		return nil, nil
+	}
+	c.logger.Debug("compiling resources together", "target", target, "resources", len(resources))
+	results, err := aggregate.CompileAll(resources)
+	if err != nil {
+		return nil, &CompileError{Target: target, Err: err}
+	}
+	if err := renameResults(results, target, "", opts.Prefix, opts.PathTemplate); err != nil {
+		return nil, &CompileError{Target: target, Err: err}
+	}
 	return results, nil
 }
+
+// collectErrors appends err to errs: each error of a CompileErrors, or err
+// as a CompileError locating it in resource, if known, for target.
+func collectErrors(errs CompileErrors, resource *Resource, target Target, err error) CompileErrors {
+	var list CompileErrors
+	if errors.As(err, &list) {
+		return append(errs, list...)
+	}
+	if resource == nil {
+		resource = &Resource{}
+	}
+	return append(errs, locate(resource, target, err))
+}
+
+// compileFor compiles resource for a single target and names its results.
+func (c *Compiler) compileFor(ctx context.Context, target Target, resource *Resource, opts CompileOptions) ([]CompilationResult, error) {
+	compiler, options, err := c.configuredTarget(target, resource, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	// Compile resource, reusing cached results when available
+	results, err := c.compileTarget(ctx, target, compiler, options, resource)
+	if err != nil {
+		return nil, err
+	}
+	if err := renameResults(results, target, resource.Metadata.Namespace, opts.Prefix, opts.PathTemplate); err != nil {
+		return nil, err
+	}
+	if merging, ok := compiler.(MergingTarget); ok {
+		return merging.Merge(results)
+	}
+	return results, nil
+}
+
+// Targets returns the registered targets, sorted by name.
+func (c *Compiler) Targets() []Target {
+	targets := make([]Target, 0, len(c.targets))
+	for target := range c.targets {
+		targets = append(targets, target)
+	}
+	sort.Slice(targets, func(i, j int) bool { return targets[i] < targets[j] })
+	return targets
+}
+
+// SupportedVersions returns the apiVersions target compiles, or nil if it is
+// not registered.
+func (c *Compiler) SupportedVersions(target Target) []string {
+	compiler, ok := c.targets[target]
+	if !ok {
+		return nil
+	}
+	return compiler.SupportedVersions()
+}
+
+// Merges reports whether target combines results into shared files, so
+// results of several Compile calls must be passed to Merge together.
+func (c *Compiler) Merges(target Target) bool {
+	_, ok := c.targets[target].(MergingTarget)
+	return ok
+}
+
+// AddBanner returns content, the file at path compiled for target, with
+// banner added in the form target chooses, for targets implementing
+// BannerTarget. Line breaks and other runs of whitespace in banner become
+// single spaces. Files of other targets are returned unchanged.
+func (c *Compiler) AddBanner(target Target, path, content, banner string) string {
+	bannerTarget, ok := c.targets[target].(BannerTarget)
+	if !ok {
+		return content
+	}
+	banner = strings.Join(strings.Fields(banner), " ")
+	return bannerTarget.AddBanner(path, content, banner)
+}
+
+// DefaultOutputDir returns the directory of the project, relative to its
+// root, that target's tool reads files from, for targets implementing
+// NativeLayoutTarget. ok is false for other targets.
+func (c *Compiler) DefaultOutputDir(target Target) (dir string, ok bool) {
+	native, ok := c.targets[target].(NativeLayoutTarget)
+	if !ok {
+		return "", false
+	}
+	return native.DefaultOutputDir(), true
+}
+
+// ProjectRoot returns the results of target that its tool reads from the
+// project root rather than DefaultOutputDir apart from the rest, for targets
+// implementing ProjectRootTarget. Other targets have no root results.
+func (c *Compiler) ProjectRoot(target Target, results []CompilationResult) (root, rest []CompilationResult) {
+	rootTarget, ok := c.targets[target].(ProjectRootTarget)
+	if !ok {
+		return nil, results
+	}
+	return rootTarget.ProjectRoot(results)
+}
+
+// Merge combines the results of several Compile calls for target into the
+// files they share, for targets implementing MergingTarget. Results of other
+// targets are returned unchanged.
+func (c *Compiler) Merge(target Target, results []CompilationResult) ([]CompilationResult, error) {
+	merging, ok := c.targets[target].(MergingTarget)
+	if !ok {
+		return results, nil
+	}
+	merged, err := merging.Merge(results)
+	if err != nil {
+		return nil, err
+	}
+	for i := range merged {
+		merged[i].Stats = c.resultStats(merged[i])
+	}
+	return merged, nil
+}
+
+// validateResource returns the problems Compile rejects: missing required
+// fields, invalid IDs, namespaces, and rule names, and invalid scopes.
+// Unknown fields are left to the loader, which rejects them unless lenient.
+func validateResource(resource *Resource) []*ValidationError {
+	v := &validator{}
+	v.resource(resource)
+	return v.errs
+}
+
+// validateResource returns the problems of the built-in checks or, if there
+// are none, those of the first of the compiler's validators to find any.
+func (c *Compiler) validateResource(resource *Resource) []*ValidationError {
+	if problems := validateResource(resource); len(problems) > 0 {
+		return problems
+	}
+	for _, validator := range c.validators {
+		if problems := validator.Validate(resource); len(problems) > 0 {
+			return problems
+		}
+	}
+	return nil
+}
+
+// configuredTarget returns the compiler for target, configured with the
+// options opts sets for it, after checking it supports the resource's
+// apiVersion. It also returns the effective options.
+func (c *Compiler) configuredTarget(target Target, resource *Resource, opts CompileOptions) (TargetCompiler, map[string]any, error) {
+	compiler, ok := c.targets[target]
+	if !ok {
+		names := make([]string, 0, len(c.targets))
+		for name := range c.targets {
+			names = append(names, string(name))
+		}
+		sort.Strings(names)
+		return nil, nil, fmt.Errorf("%w: %s (%s)", ErrUnknownTarget, target, suggest.Hint(string(target), "targets", names))
+	}
+
+	// Check version compatibility
+	supported := false
+	for _, version := range compiler.SupportedVersions() {
+		if version == resource.APIVersion {
+			supported = true
+			break
+		}
+	}
+	if !supported {
+		return nil, nil, fmt.Errorf("%w: %s for target %s", ErrUnsupportedVersion, resource.APIVersion, target)
+	}
+
+	// Apply target options
+	options := opts.TargetOptions[target]
+	if _, configurable := compiler.(ConfigurableTarget); configurable {
+		if _, set := options["lean"]; !set && opts.Lean {
+			options = withOption(options, "lean", true)
+		}
+		if _, set := options["embedSource"]; !set && opts.EmbedSource != "" {
+			options = withOption(options, "embedSource", opts.EmbedSource)
+		}
+	}
+	if len(options) > 0 {
+		configurable, ok := compiler.(ConfigurableTarget)
+		if !ok {
+			return nil, nil, fmt.Errorf("%w: %s", ErrOptionsNotSupported, target)
+		}
+		configured, err := configurable.Configure(options)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%w for %s: %w", ErrInvalidTargetOptions, target, err)
+		}
+		compiler = configured
+	}
+	return compiler, options, nil
+}
+
+// compileTarget compiles resource with a single target compiler, consulting
+// the cache if one is configured.
+func (c *Compiler) compileTarget(ctx context.Context, target Target, compiler TargetCompiler, options map[string]any, resource *Resource) ([]CompilationResult, error) {
+	logger := c.logger.With("target", target, "kind", resource.Kind, "id", resource.Metadata.ID)
+
+	var key string
+	cacheable := false
+	if c.cacheDir != "" {
+		key, cacheable = cacheKey(target, compiler, options, resource)
+		if cacheable {
+			if results, ok := c.readCache(key); ok {
+				logger.Debug("compile cache hit", "key", key)
+				return results, nil
+			}
+		}
+	}
+
+	logger.Debug("compiling resource")
+	var results []CompilationResult
+	var err error
+	if contextual, ok := compiler.(ContextTarget); ok {
+		results, err = contextual.CompileContext(ctx, resource)
+	} else {
+		results, err = compiler.Compile(resource)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if cacheable {
+		c.writeCache(key, results)
+	}
+	return results, nil
+}
+
+// withOption returns a copy of options with name set to value.
+func withOption(options map[string]any, name string, value any) map[string]any {
+	out := make(map[string]any, len(options)+1)
+	for k, v := range options {
+		out[k] = v
+	}
+	out[name] = value
+	return out
+}
@@ -1,8 +1,14 @@
 package compiler
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/jomadu/ai-resource-compiler-go/internal/format"
 )
@@ -13,9 +19,7 @@ func strPtr(s string) *string {
 
 // setupCompiler creates a compiler with a mock target registered
 func setupCompiler() *Compiler {
-	c := &Compiler{
-		targets: make(map[Target]TargetCompiler),
-	}
+	c := NewCompiler(WithoutDefaults())
 	c.RegisterTarget(TargetMarkdown, &mockMarkdownCompiler{})
 	c.RegisterTarget(TargetKiro, &mockMarkdownCompiler{})
 	c.RegisterTarget(TargetCursor, &mockCursorCompiler{})
@@ -209,6 +213,121 @@ func TestCompiler_Ruleset(t *testing.T) {
 	}
 }
 
+func TestCompiler_Namespace(t *testing.T) {
+	c := setupCompiler()
+	resource := &Resource{
+		APIVersion: "ai-resource/draft",
+		Kind:       "Rule",
+		Spec: &format.Rule{
+			Metadata: format.Metadata{ID: "testRule", Namespace: "platform"},
+			Spec: format.RuleSpec{
+				Enforcement: "must",
+				Body:        format.Body{String: strPtr("Test body")},
+			},
+		},
+	}
+	resource.Metadata.ID = "testRule"
+	resource.Metadata.Namespace = "platform"
+
+	results, err := c.Compile(resource, CompileOptions{Targets: []Target{TargetMarkdown, TargetCursor}})
+	if err != nil {
+		t.Fatalf("Compile() error = %v", err)
+	}
+	want := []string{"platform/testRule.md", "platform/testRule.mdc"}
+	for i, r := range results {
+		if r.Path != want[i] {
+			t.Errorf("results[%d].Path = %v, want %v", i, r.Path, want[i])
+		}
+	}
+
+	resource.Metadata.Namespace = "../escape"
+	_, err = c.Compile(resource, CompileOptions{Targets: []Target{TargetMarkdown}})
+	var verr *ValidationError
+	if !errors.As(err, &verr) || verr.Field != "metadata.namespace" {
+		t.Errorf("Compile() error = %v, want metadata.namespace ValidationError", err)
+	}
+}
+
+func TestCompiler_TargetOptionsUnsupported(t *testing.T) {
+	c := setupCompiler()
+	resource := &Resource{
+		APIVersion: "ai-resource/draft",
+		Kind:       "Rule",
+		Spec: &format.Rule{
+			Metadata: format.Metadata{ID: "testRule"},
+			Spec: format.RuleSpec{
+				Enforcement: "must",
+				Body:        format.Body{String: strPtr("Test body")},
+			},
+		},
+	}
+	resource.Metadata.ID = "testRule"
+
+	opts := CompileOptions{
+		Targets:       []Target{TargetMarkdown},
+		TargetOptions: map[Target]map[string]any{TargetMarkdown: {"alwaysApply": true}},
+	}
+	_, err := c.Compile(resource, opts)
+	if !errors.Is(err, ErrOptionsNotSupported) {
+		t.Errorf("Compile() error = %v, want options not accepted error", err)
+	}
+}
+
+// mockConfigurableCompiler records the options it is configured with
+type mockConfigurableCompiler struct {
+	mockMarkdownCompiler
+	options map[string]any
+}
+
+func (m *mockConfigurableCompiler) Configure(options map[string]any) (TargetCompiler, error) {
+	return &mockConfigurableCompiler{options: options}, nil
+}
+
+func (m *mockConfigurableCompiler) Compile(resource *Resource) ([]CompilationResult, error) {
+	return []CompilationResult{{Path: "out.md", Content: fmt.Sprint(m.options)}}, nil
+}
+
+func TestCompiler_Lean(t *testing.T) {
+	c := setupCompiler()
+	c.RegisterTarget(TargetClaude, &mockConfigurableCompiler{})
+	resource := &Resource{
+		APIVersion: "ai-resource/draft",
+		Kind:       "Rule",
+		Spec: &format.Rule{
+			Metadata: format.Metadata{ID: "testRule"},
+			Spec: format.RuleSpec{
+				Enforcement: "must",
+				Body:        format.Body{String: strPtr("Test body")},
+			},
+		},
+	}
+	resource.Metadata.ID = "testRule"
+
+	results, err := c.Compile(resource, CompileOptions{Targets: []Target{TargetClaude, TargetMarkdown}, Lean: true})
+	if err != nil {
+		t.Fatalf("Compile() error = %v", err)
+	}
+	if results[0].Content != "map[lean:true]" {
+		t.Errorf("configurable target options = %v, want map[lean:true]", results[0].Content)
+	}
+	if results[1].Content != "mock content" {
+		t.Errorf("non-configurable target content = %v, want mock content", results[1].Content)
+	}
+
+	opts := CompileOptions{
+		Targets:       []Target{TargetClaude},
+		TargetOptions: map[Target]map[string]any{TargetClaude: {"lean": false}},
+		Lean:          true,
+	}
+	results, err = c.Compile(resource, opts)
+	if err != nil {
+		t.Fatalf("Compile() error = %v", err)
+	}
+	if results[0].Content != "map[lean:false]" {
+		t.Errorf("options = %v, want per-target lean to win", results[0].Content)
+	}
+}
+
 func TestCompiler_MissingAPIVersion(t *testing.T) {
 	c := NewCompiler()
 	resource := &Resource{
@@ -224,8 +343,9 @@ func TestCompiler_MissingAPIVersion(t *testing.T) {
 	if err == nil {
 		t.Fatal("Compile() expected error for missing apiVersion")
 	}
-	if !strings.Contains(err.Error(), "apiVersion") {
-		t.Errorf("Error = %v, want apiVersion error", err)
+	var verr *ValidationError
+	if !errors.As(err, &verr) || verr.Field != "apiVersion" {
+		t.Errorf("Error = %v, want apiVersion ValidationError", err)
 	}
 }
 
@@ -244,8 +364,9 @@ func TestCompiler_MissingKind(t *testing.T) {
 	if err == nil {
 		t.Fatal("Compile() expected error for missing kind")
 	}
-	if !strings.Contains(err.Error(), "kind") {
-		t.Errorf("Error = %v, want kind error", err)
+	var verr *ValidationError
+	if !errors.As(err, &verr) || verr.Field != "kind" {
+		t.Errorf("Error = %v, want kind ValidationError", err)
 	}
 }
 
@@ -264,8 +385,33 @@ func TestCompiler_MissingID(t *testing.T) {
 	if err == nil {
 		t.Fatal("Compile() expected error for missing metadata.id")
 	}
-	if !strings.Contains(err.Error(), "metadata.id") {
-		t.Errorf("Error = %v, want metadata.id error", err)
+	var verr *ValidationError
+	if !errors.As(err, &verr) || verr.Field != "metadata.id" {
+		t.Errorf("Error = %v, want metadata.id ValidationError", err)
+	}
+}
+
+func TestCompiler_EmptyScope(t *testing.T) {
+	c := NewCompiler()
+	resource := &Resource{
+		APIVersion: "ai-resource/draft",
+		Kind:       "Ruleset",
+		Spec: &format.Ruleset{
+			Metadata: format.Metadata{ID: "style"},
+		},
+	}
+	resource.Metadata.ID = "style"
+	resource.Spec.(*format.Ruleset).Spec.Rules = map[string]format.RuleItem{
+		"naming": {Enforcement: "must", Scope: []format.ScopeEntry{{Files: []string{}}}},
+	}
+
+	_, err := c.Compile(resource, CompileOptions{Targets: []Target{TargetMarkdown}})
+	var verr *ValidationError
+	if !errors.As(err, &verr) || verr.Field != "spec.rules.naming.scope" {
+		t.Fatalf("Error = %v, want spec.rules.naming.scope ValidationError", err)
+	}
+	if !strings.Contains(verr.Message, "style/naming") {
+		t.Errorf("Message = %q, want it to name the rule", verr.Message)
 	}
 }
 
@@ -285,7 +431,7 @@ func TestCompiler_NoTargets(t *testing.T) {
 	if err == nil {
 		t.Fatal("Compile() expected error for no targets")
 	}
-	if !strings.Contains(err.Error(), "no targets") {
+	if !errors.Is(err, ErrNoTargets) {
 		t.Errorf("Error = %v, want no targets error", err)
 	}
 }
@@ -316,7 +462,7 @@ func TestCompiler_UnknownTarget(t *testing.T) {
 	if err == nil {
 		t.Fatal("Compile() expected error for unknown target")
 	}
-	if !strings.Contains(err.Error(), "unknown target") {
+	if !errors.Is(err, ErrUnknownTarget) {
 		t.Errorf("Error = %v, want unknown target error", err)
 	}
 }
@@ -347,7 +493,7 @@ func TestCompiler_UnsupportedVersion(t *testing.T) {
 	if err == nil {
 		t.Fatal("Compile() expected error for unsupported version")
 	}
-	if !strings.Contains(err.Error(), "does not support apiVersion") {
+	if !errors.Is(err, ErrUnsupportedVersion) {
 		t.Errorf("Error = %v, want unsupported version error", err)
 	}
 }
@@ -394,3 +540,102 @@ func (m *mockCompiler) Compile(resource *Resource) ([]CompilationResult, error)
 		{Path: "mock.txt", Content: "mock content"},
 	}, nil
 }
+
+func TestExpandVariables(t *testing.T) {
+	resource := &Resource{
+		APIVersion: "ai-resource/draft",
+		Kind:       "Ruleset",
+		Spec: &format.Ruleset{
+			Metadata: format.Metadata{ID: "testRuleset"},
+		},
+	}
+	ruleset := resource.Spec.(*format.Ruleset)
+	ruleset.Spec.Rules = map[string]format.RuleItem{
+		"rule1": {
+			Name:        "Rule 1",
+			Enforcement: "must",
+			Body:        format.Body{Array: []string{"Owned by ${team}.", "$footer"}},
+		},
+	}
+	ruleset.Spec.Fragments = map[string]string{"footer": "Ask ${team} in ${channel}."}
+
+	expanded := expandVariables(resource, map[string]string{"team": "platform"})
+
+	got := expanded.Spec.(*format.Ruleset)
+	body := format.ResolveBody(got.Spec.Rules["rule1"].Body, got.Spec.Fragments)
+	want := "Owned by platform.\n\nAsk platform in ${channel}."
+	if body != want {
+		t.Errorf("body = %q, want %q", body, want)
+	}
+
+	original := format.ResolveBody(ruleset.Spec.Rules["rule1"].Body, ruleset.Spec.Fragments)
+	if !strings.Contains(original, "${team}") {
+		t.Errorf("original resource was modified: %q", original)
+	}
+}
+
+func TestCompiler_Targets(t *testing.T) {
+	c := setupCompiler()
+
+	if got, want := c.Targets(), []Target{TargetCursor, TargetKiro, TargetMarkdown}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Targets() = %v, want %v", got, want)
+	}
+	if got := c.SupportedVersions(TargetCursor); !reflect.DeepEqual(got, []string{"ai-resource/draft"}) {
+		t.Errorf("SupportedVersions(cursor) = %v", got)
+	}
+	if got := c.SupportedVersions(TargetClaude); got != nil {
+		t.Errorf("SupportedVersions() of an unregistered target = %v, want nil", got)
+	}
+}
+
+// waitingCompiler implements ContextTarget, waiting for its context to be
+// done before it returns.
+type waitingCompiler struct {
+	mockMarkdownCompiler
+	ctx context.Context
+}
+
+func (m *waitingCompiler) CompileContext(ctx context.Context, resource *Resource) ([]CompilationResult, error) {
+	m.ctx = ctx
+	if ctx.Value(waitKey{}) == nil {
+		return m.Compile(resource)
+	}
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+type waitKey struct{}
+
+func TestCompileContext(t *testing.T) {
+	tc := &waitingCompiler{}
+	c := NewCompiler(WithoutDefaults(), WithTarget(TargetMarkdown, tc))
+	opts := CompileOptions{Targets: []Target{TargetMarkdown}}
+
+	if _, err := c.Compile(testRule("body"), opts); err != nil || tc.ctx == nil {
+		t.Fatalf("Compile() error = %v, context = %v; want the target given a context", err, tc.ctx)
+	}
+
+	ctx, cancel := context.WithTimeout(context.WithValue(context.Background(), waitKey{}, true), 10*time.Millisecond)
+	defer cancel()
+	_, err := c.CompileContext(ctx, testRule("body"), opts)
+	var compileErr *CompileError
+	if !errors.Is(err, context.DeadlineExceeded) || !errors.As(err, &compileErr) || compileErr.Target != TargetMarkdown {
+		t.Errorf("CompileContext() error = %v, want the deadline exceeded in target markdown", err)
+	}
+
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	tc.ctx = nil
+	if _, err := c.CompileContext(canceled, testRule("body"), opts); !errors.Is(err, context.Canceled) {
+		t.Errorf("CompileContext() error = %v, want context.Canceled", err)
+	}
+	if err := c.CompileToContext(canceled, testRule("body"), opts, WriterSink(io.Discard)); !errors.Is(err, context.Canceled) {
+		t.Errorf("CompileToContext() error = %v, want context.Canceled", err)
+	}
+	if _, err := c.CompileAllContext(canceled, []*Resource{testRule("body")}, opts); !errors.Is(err, context.Canceled) {
+		t.Errorf("CompileAllContext() error = %v, want context.Canceled", err)
+	}
+	if tc.ctx != nil {
+		t.Error("target compiled after the context was canceled")
+	}
+}
@@ -0,0 +1,115 @@
+package compiler
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Activation is when a tool loads a rule of some enforcement level. Targets
+// map it to their own mechanism: Cursor rule types, Kiro inclusion modes,
+// and alwaysApply flags.
+type Activation string
+
+const (
+	// ActivationAlways loads the rule into every request.
+	ActivationAlways Activation = "always"
+	// ActivationScoped loads the rule when a file matching its scope is in
+	// play, or always if it has no scope.
+	ActivationScoped Activation = "scoped"
+	// ActivationOnRequest lists the rule for the agent, which loads it when
+	// its description is relevant.
+	ActivationOnRequest Activation = "onRequest"
+	// ActivationManual loads the rule only when the user references it.
+	ActivationManual Activation = "manual"
+)
+
+// EnforcementLevel is a value rules can give enforcement, and how targets
+// treat rules of that level.
+type EnforcementLevel struct {
+	// Name is the level as written in resources, e.g. "must".
+	Name string
+
+	// Rank orders levels from weakest to strongest: may is 1, should 2, and
+	// must 3.
+	Rank int
+
+	// Activation is when tools load rules of this level by default.
+	Activation Activation
+}
+
+var (
+	enforcementMu     sync.RWMutex
+	enforcementLevels = map[string]EnforcementLevel{
+		"may":    {Name: "may", Rank: 1, Activation: ActivationOnRequest},
+		"should": {Name: "should", Rank: 2, Activation: ActivationScoped},
+		"must":   {Name: "must", Rank: 3, Activation: ActivationAlways},
+	}
+)
+
+// RegisterEnforcementLevel adds an enforcement level, such as "must-not",
+// or changes how targets treat an existing one. Rules of a level that is
+// not registered fail to compile. Register levels during initialization,
+// before compiling.
+func RegisterEnforcementLevel(level EnforcementLevel) error {
+	if level.Name == "" || strings.TrimSpace(level.Name) != level.Name {
+		return fmt.Errorf("invalid enforcement level name %q", level.Name)
+	}
+	switch level.Activation {
+	case ActivationAlways, ActivationScoped, ActivationOnRequest, ActivationManual:
+	default:
+		return fmt.Errorf("enforcement level %s has unknown activation %q (expected always, scoped, onRequest, or manual)", level.Name, level.Activation)
+	}
+	enforcementMu.Lock()
+	defer enforcementMu.Unlock()
+	enforcementLevels[level.Name] = level
+	return nil
+}
+
+// LookupEnforcement returns the enforcement level named name.
+func LookupEnforcement(name string) (EnforcementLevel, bool) {
+	enforcementMu.RLock()
+	defer enforcementMu.RUnlock()
+	level, ok := enforcementLevels[name]
+	return level, ok
+}
+
+// EnforcementLevels returns the registered enforcement levels, weakest
+// first.
+func EnforcementLevels() []EnforcementLevel {
+	enforcementMu.RLock()
+	defer enforcementMu.RUnlock()
+	levels := make([]EnforcementLevel, 0, len(enforcementLevels))
+	for _, level := range enforcementLevels {
+		levels = append(levels, level)
+	}
+	sort.Slice(levels, func(i, j int) bool {
+		if levels[i].Rank != levels[j].Rank {
+			return levels[i].Rank < levels[j].Rank
+		}
+		return levels[i].Name < levels[j].Name
+	})
+	return levels
+}
+
+// EnforcementNames returns the names of the registered enforcement levels,
+// weakest first.
+func EnforcementNames() []string {
+	levels := EnforcementLevels()
+	names := make([]string, len(levels))
+	for i, level := range levels {
+		names[i] = level.Name
+	}
+	return names
+}
+
+// expectedEnforcement lists the registered enforcement levels for messages,
+// e.g. "may, should, or must".
+func expectedEnforcement() string {
+	names := EnforcementNames()
+	if len(names) == 1 {
+		return names[0]
+	}
+	return strings.Join(names[:len(names)-1], ", ") + ", or " + names[len(names)-1]
+}
@@ -0,0 +1,42 @@
+package compiler
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/jomadu/ai-resource-compiler-go/internal/format"
+)
+
+func TestEnforcementLevels(t *testing.T) {
+	if got := EnforcementNames(); !reflect.DeepEqual(got, []string{"may", "should", "must"}) {
+		t.Errorf("EnforcementNames() = %v, want may, should, must", got)
+	}
+
+	resource := testRule("Body")
+	resource.Spec.(*format.Rule).Spec.Enforcement = "must-not"
+	opts := CompileOptions{Targets: []Target{TargetMarkdown}}
+	_, err := setupCompiler().Compile(resource, opts)
+	if err == nil || !strings.Contains(err.Error(), `unknown enforcement "must-not" (expected may, should, or must)`) {
+		t.Errorf("Compile() error = %v, want unknown enforcement", err)
+	}
+
+	if err := RegisterEnforcementLevel(EnforcementLevel{Name: "must-not", Rank: 3, Activation: ActivationAlways}); err != nil {
+		t.Fatalf("RegisterEnforcementLevel() error = %v", err)
+	}
+	defer func() {
+		enforcementMu.Lock()
+		delete(enforcementLevels, "must-not")
+		enforcementMu.Unlock()
+	}()
+	if _, err := setupCompiler().Compile(resource, opts); err != nil {
+		t.Errorf("Compile() error = %v after registering must-not", err)
+	}
+	if got := EnforcementNames(); !reflect.DeepEqual(got, []string{"may", "should", "must", "must-not"}) {
+		t.Errorf("EnforcementNames() = %v, want must-not ranked with must", got)
+	}
+
+	if err := RegisterEnforcementLevel(EnforcementLevel{Name: "never", Activation: "sometimes"}); err == nil {
+		t.Error("RegisterEnforcementLevel() accepted an unknown activation")
+	}
+}
@@ -0,0 +1,124 @@
+package compiler
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/jomadu/ai-resource-compiler-go/internal/format"
+)
+
+// Errors returned by Compile and the built-in targets. They are wrapped with
+// context, so test for them with errors.Is.
+var (
+	ErrNoTargets            = errors.New("no targets specified")
+	ErrUnknownTarget        = errors.New("unknown target")
+	ErrUnsupportedVersion   = errors.New("unsupported apiVersion")
+	ErrUnsupportedKind      = errors.New("unsupported kind")
+	ErrOptionsNotSupported  = errors.New("target does not accept options")
+	ErrInvalidTargetOptions = errors.New("invalid target options")
+	ErrUnknownItem          = errors.New("unknown item")
+	ErrLimitExceeded        = errors.New("limit exceeded")
+	ErrPathCollision        = errors.New("output path collision")
+)
+
+// ValidationError reports a resource field whose value is missing or
+// invalid. Retrieve it with errors.As to find the offending field:
+//
+//	var verr *compiler.ValidationError
+//	if errors.As(err, &verr) {
+//		fmt.Println(verr.Field)
+//	}
+type ValidationError = format.ValidationError
+
+// CompileError locates an error returned by Compile: the resource file, the
+// line and column and path of the field involved, and the target being
+// compiled, each when known. Its message reads like
+//
+//	claude: rules.yaml:14 spec.rules.fooBar.name: rule name cannot contain parentheses
+//
+// Unwrap it, or use errors.Is and errors.As, to reach the underlying error.
+type CompileError struct {
+	File   string // Resource.Source
+	Line   int    // 1-based; 0 when unknown
+	Column int    // 1-based; 0 when unknown
+	Field  string // dotted field path, e.g. "spec.rules.fooBar.name"
+	Target Target // "" for errors found before compiling for a target
+	Err    error
+}
+
+func (e *CompileError) Error() string {
+	var prefix string
+	if e.Target != "" {
+		prefix = string(e.Target) + ": "
+	}
+	location := e.File
+	if location != "" && e.Line > 0 {
+		location += ":" + strconv.Itoa(e.Line)
+	}
+	switch {
+	case location != "" && e.Field != "":
+		prefix += location + " " + e.Field + ": "
+	case location != "":
+		prefix += location + ": "
+	case e.Field != "":
+		prefix += e.Field + ": "
+	}
+	return prefix + e.Err.Error()
+}
+
+func (e *CompileError) Unwrap() error {
+	return e.Err
+}
+
+// CompileErrors lists every problem Compile found with
+// CompileOptions.CollectErrors set, one per line in its message. errors.Is
+// and errors.As look through each of them.
+type CompileErrors []*CompileError
+
+func (e CompileErrors) Error() string {
+	messages := make([]string, len(e))
+	for i, err := range e {
+		messages[i] = err.Error()
+	}
+	return strings.Join(messages, "\n")
+}
+
+func (e CompileErrors) Unwrap() []error {
+	errs := make([]error, len(e))
+	for i, err := range e {
+		errs[i] = err
+	}
+	return errs
+}
+
+// compileError wraps err, found compiling resource for target, in a
+// CompileError, locating the field of a ValidationError. It returns err
+// unchanged if it is a CompileError already or there is nothing to add.
+func compileError(resource *Resource, target Target, err error) error {
+	var compileErr *CompileError
+	if errors.As(err, &compileErr) {
+		return err
+	}
+	e := locate(resource, target, err)
+	if e.File == "" && e.Field == "" && e.Target == "" {
+		return err
+	}
+	return e
+}
+
+// locate returns err as a CompileError, adding the position of the field
+// of a ValidationError.
+func locate(resource *Resource, target Target, err error) *CompileError {
+	var compileErr *CompileError
+	if errors.As(err, &compileErr) {
+		return compileErr
+	}
+	e := &CompileError{File: resource.Source, Target: target, Err: err}
+	var validationErr *ValidationError
+	if errors.As(err, &validationErr) && validationErr.Field != "" {
+		e.Field = validationErr.Field
+		e.Line, e.Column = resource.Position(validationErr.Field, validationErr.Value)
+	}
+	return e
+}
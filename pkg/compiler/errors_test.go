@@ -0,0 +1,119 @@
+package compiler
+
+import (
+	"errors"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestCompileError(t *testing.T) {
+	var resource Resource
+	content := `apiVersion: ai-resource/draft
+kind: Ruleset
+metadata:
+  id: rules
+spec:
+  rules:
+    fooBar:
+      name: Foo (bar)
+      enforcement: must
+      body: Body
+`
+	if err := yaml.Unmarshal([]byte(content), &resource); err != nil {
+		t.Fatal(err)
+	}
+	resource.Source = "rules.yaml"
+
+	_, err := setupCompiler().Compile(&resource, CompileOptions{Targets: []Target{TargetMarkdown}})
+	var compileErr *CompileError
+	if !errors.As(err, &compileErr) {
+		t.Fatalf("Compile() error = %v, want a CompileError", err)
+	}
+	if compileErr.Line != 8 || compileErr.Column != 13 {
+		t.Errorf("position = %d:%d, want 8:13", compileErr.Line, compileErr.Column)
+	}
+	want := "rules.yaml:8 spec.rules.fooBar.name: rule name cannot contain parentheses: 'Foo (bar)'"
+	if err.Error() != want {
+		t.Errorf("Error() = %q, want %q", err.Error(), want)
+	}
+	var validationErr *ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Error("CompileError does not unwrap to the ValidationError")
+	}
+}
+
+func TestCompileError_Target(t *testing.T) {
+	resource := &Resource{APIVersion: "ai-resource/v9", Kind: "Rule", Source: "rule.yaml"}
+	resource.Metadata.ID = "rule"
+
+	_, err := setupCompiler().Compile(resource, CompileOptions{Targets: []Target{TargetMarkdown}})
+	if !errors.Is(err, ErrUnsupportedVersion) {
+		t.Fatalf("Compile() error = %v, want ErrUnsupportedVersion", err)
+	}
+	var compileErr *CompileError
+	if !errors.As(err, &compileErr) || compileErr.Target != TargetMarkdown || compileErr.File != "rule.yaml" {
+		t.Errorf("Compile() error = %#v, want a CompileError for markdown in rule.yaml", err)
+	}
+}
+
+func TestCompile_CollectErrors(t *testing.T) {
+	var resource Resource
+	content := `apiVersion: ai-resource/draft
+kind: Ruleset
+metadata:
+  id: rules
+spec:
+  rules:
+    bad.id:
+      enforcement: must
+      body: Body
+    fooBar:
+      name: Foo (bar)
+      enforcement: must
+      body: Body
+`
+	if err := yaml.Unmarshal([]byte(content), &resource); err != nil {
+		t.Fatal(err)
+	}
+	resource.Source = "rules.yaml"
+	c := setupCompiler()
+
+	_, err := c.Compile(&resource, CompileOptions{Targets: []Target{TargetMarkdown}})
+	var list CompileErrors
+	if errors.As(err, &list) {
+		t.Errorf("Compile() without CollectErrors returned %d errors, want the first", len(list))
+	}
+
+	_, err = c.Compile(&resource, CompileOptions{Targets: []Target{TargetMarkdown}, CollectErrors: true})
+	if !errors.As(err, &list) {
+		t.Fatalf("Compile() error = %v, want CompileErrors", err)
+	}
+	want := "rules.yaml:7 spec.rules: ID contains invalid character '.' in 'bad.id'\n" +
+		"rules.yaml:11 spec.rules.fooBar.name: rule name cannot contain parentheses: 'Foo (bar)'"
+	if err.Error() != want {
+		t.Errorf("Error() = %q, want %q", err.Error(), want)
+	}
+	var validationErr *ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Error("CompileErrors does not unwrap to a ValidationError")
+	}
+}
+
+func TestCompile_CollectErrorsTargets(t *testing.T) {
+	resource := &Resource{APIVersion: "ai-resource/v9", Kind: "Rule", Source: "rule.yaml"}
+	resource.Metadata.ID = "rule"
+
+	opts := CompileOptions{Targets: []Target{TargetMarkdown, TargetCursor}, CollectErrors: true}
+	_, err := setupCompiler().Compile(resource, opts)
+	var list CompileErrors
+	if !errors.As(err, &list) {
+		t.Fatalf("Compile() error = %v, want CompileErrors", err)
+	}
+	if len(list) != 2 || list[0].Target != TargetMarkdown || list[1].Target != TargetCursor {
+		t.Errorf("Compile() errors = %v, want one for markdown and one for cursor", err)
+	}
+	if !errors.Is(err, ErrUnsupportedVersion) {
+		t.Error("errors.Is(err, ErrUnsupportedVersion) = false")
+	}
+}
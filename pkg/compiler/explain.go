@@ -0,0 +1,76 @@
+package compiler
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/jomadu/ai-resource-compiler-go/internal/format"
+)
+
+// ErrNotExplainable is returned by Explain for targets that do not implement
+// ExplainingTarget.
+var ErrNotExplainable = errors.New("target cannot explain its output")
+
+// Mapping describes how one resource field appears in a target's output.
+type Mapping struct {
+	Field  string // resource field and value, e.g. "enforcement: must"
+	Output string // what the target emits for it, e.g. "alwaysApply: true"
+}
+
+// Explanation describes one output file of a target.
+type Explanation struct {
+	Item     string // rule or prompt ID, prefixed with the collection ID
+	Path     string
+	Mappings []Mapping
+}
+
+// ExplainingTarget is implemented by target compilers that can describe how
+// they map a resource's fields to their output.
+type ExplainingTarget interface {
+	TargetCompiler
+
+	// Explain returns one explanation per output file Compile would produce
+	// for resource.
+	Explain(resource *Resource) ([]Explanation, error)
+}
+
+// Explain describes how target, configured with the options opts sets for
+// it, compiles resource. Explanations are sorted by path.
+func (c *Compiler) Explain(resource *Resource, target Target, opts CompileOptions) ([]Explanation, error) {
+	if problems := c.validateResource(resource); len(problems) > 0 {
+		return nil, problems[0]
+	}
+	resource, err := filterItems(resource, opts.Only, opts.Exclude)
+	if err != nil || resource == nil {
+		return nil, err
+	}
+	resource = expandVariables(resource, opts.Variables)
+	if opts.Templates {
+		if resource, err = executeTemplates(resource, opts, c.limits.WithDefaults().MaxBodySize); err != nil {
+			return nil, err
+		}
+	}
+	resource = transformBodies(resource, opts.Transformers)
+	compiler, _, err := c.configuredTarget(target, resource, opts)
+	if err != nil {
+		return nil, err
+	}
+	explainer, ok := compiler.(ExplainingTarget)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrNotExplainable, target)
+	}
+
+	explanations, err := explainer.Explain(resource)
+	if err != nil {
+		return nil, err
+	}
+	for i := range explanations {
+		path := format.BuildNamespacedPath(resource.Metadata.Namespace, explanations[i].Path)
+		if explanations[i].Path, err = renamePath(path, target, opts.Prefix, opts.PathTemplate); err != nil {
+			return nil, err
+		}
+	}
+	sort.Slice(explanations, func(i, j int) bool { return explanations[i].Path < explanations[j].Path })
+	return explanations, nil
+}
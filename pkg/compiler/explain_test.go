@@ -0,0 +1,50 @@
+package compiler
+
+import (
+	"errors"
+	"testing"
+)
+
+// mockExplainingCompiler explains each result of mockMarkdownCompiler.
+type mockExplainingCompiler struct {
+	mockMarkdownCompiler
+}
+
+func (m *mockExplainingCompiler) Explain(resource *Resource) ([]Explanation, error) {
+	results, err := m.Compile(resource)
+	if err != nil {
+		return nil, err
+	}
+	var explanations []Explanation
+	for _, r := range results {
+		explanations = append(explanations, Explanation{
+			Item:     resource.Metadata.ID,
+			Path:     r.Path,
+			Mappings: []Mapping{{Field: "body", Output: "file content"}},
+		})
+	}
+	return explanations, nil
+}
+
+func TestCompiler_Explain(t *testing.T) {
+	c := NewCompiler(WithoutDefaults())
+	c.RegisterTarget(TargetMarkdown, &mockExplainingCompiler{})
+	c.RegisterTarget(TargetCursor, &mockCursorCompiler{})
+
+	resource := testRule("body")
+	resource.Metadata.Namespace = "platform"
+	explanations, err := c.Explain(resource, TargetMarkdown, CompileOptions{})
+	if err != nil {
+		t.Fatalf("Explain() error = %v", err)
+	}
+	if len(explanations) != 1 || explanations[0].Path != "platform/testRule.md" {
+		t.Errorf("Explain() = %v, want namespaced testRule.md", explanations)
+	}
+
+	if _, err := c.Explain(resource, TargetCursor, CompileOptions{}); !errors.Is(err, ErrNotExplainable) {
+		t.Errorf("Explain() error = %v, want ErrNotExplainable", err)
+	}
+	if _, err := c.Explain(resource, "unknown", CompileOptions{}); !errors.Is(err, ErrUnknownTarget) {
+		t.Errorf("Explain() error = %v, want ErrUnknownTarget", err)
+	}
+}
@@ -0,0 +1,208 @@
+package compiler
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/jomadu/ai-resource-compiler-go/internal/format"
+)
+
+// ResolveExtends returns resources with the extends of every Ruleset
+// resolved against the other Rulesets among them. An extends entry names a
+// ruleset by ID, in the extending ruleset's namespace, or as
+// "namespace/id". The resolved ruleset has the rules of its bases, in the
+// order extends lists them, followed by its own. A rule the ruleset
+// defines with the ID of an inherited one overrides it field by field: the
+// name, description, enforcement, scope, body, bodies, and tags it sets
+// replace the inherited ones, and those it leaves out are kept. Fragments are
+// merged the same way, the ruleset's own winning.
+//
+// Two bases defining the same rule or fragment differently is a conflict
+// the extending ruleset must settle by defining it itself. Unknown bases
+// and extends cycles are errors too.
+//
+// Resolved rulesets are copies, with Extends cleared and Bases set; other
+// resources are returned as they are. CompileAll resolves extends itself;
+// call ResolveExtends before Compile or Validate, which see one resource
+// at a time.
+func ResolveExtends(resources []*Resource) ([]*Resource, error) {
+	r := &extendsResolver{
+		byKey:    make(map[string][]*Resource),
+		resolved: make(map[*Resource]*Resource),
+		visiting: make(map[*Resource]bool),
+	}
+	for _, resource := range resources {
+		if _, ok := resource.Spec.(*format.Ruleset); ok {
+			key := rulesetKey(resource.Metadata.Namespace, resource.Metadata.ID)
+			r.byKey[key] = append(r.byKey[key], resource)
+		}
+	}
+
+	out := make([]*Resource, len(resources))
+	for i, resource := range resources {
+		resolved, err := r.resolve(resource, nil)
+		if err != nil {
+			return nil, compileError(resource, "", err)
+		}
+		out[i] = resolved
+	}
+	return out, nil
+}
+
+// extendsResolver resolves the extends of a batch of resources, each
+// ruleset once.
+type extendsResolver struct {
+	byKey    map[string][]*Resource
+	resolved map[*Resource]*Resource
+	visiting map[*Resource]bool
+}
+
+// rulesetKey identifies a ruleset in extends: "namespace/id", or the ID of
+// a ruleset without a namespace.
+func rulesetKey(namespace, id string) string {
+	if namespace == "" {
+		return id
+	}
+	return namespace + "/" + id
+}
+
+// resolve returns resource with its extends resolved. chain lists the IDs
+// of the rulesets extending it, to report cycles.
+func (r *extendsResolver) resolve(resource *Resource, chain []string) (*Resource, error) {
+	ruleset, ok := resource.Spec.(*format.Ruleset)
+	if !ok || len(ruleset.Spec.Extends) == 0 {
+		return resource, nil
+	}
+	if resolved, ok := r.resolved[resource]; ok {
+		return resolved, nil
+	}
+	chain = append(chain, resource.Metadata.ID)
+	if r.visiting[resource] {
+		return nil, fmt.Errorf("extends cycle: %s", strings.Join(chain, " -> "))
+	}
+	r.visiting[resource] = true
+	defer delete(r.visiting, resource)
+
+	var bases []*Resource
+	for _, name := range ruleset.Spec.Extends {
+		base, err := r.lookup(resource, name)
+		if err != nil {
+			return nil, err
+		}
+		if base, err = r.resolve(base, chain); err != nil {
+			return nil, err
+		}
+		bases = append(bases, base)
+	}
+
+	merged, err := extendRuleset(ruleset, bases)
+	if err != nil {
+		return nil, err
+	}
+	out := *resource
+	out.Spec = merged
+	out.Bases = bases
+	r.resolved[resource] = &out
+	return &out, nil
+}
+
+// lookup returns the ruleset name refers to from resource's extends.
+func (r *extendsResolver) lookup(resource *Resource, name string) (*Resource, error) {
+	key := name
+	if !strings.Contains(name, "/") {
+		key = rulesetKey(resource.Metadata.Namespace, name)
+	}
+	switch found := r.byKey[key]; len(found) {
+	case 0:
+		return nil, fmt.Errorf("ruleset %s extends unknown ruleset %s", resource.Metadata.ID, name)
+	case 1:
+		return found[0], nil
+	default:
+		return nil, fmt.Errorf("ruleset %s extends %s, which is defined %d times", resource.Metadata.ID, name, len(found))
+	}
+}
+
+// extendRuleset returns ruleset merged over its resolved bases.
+func extendRuleset(ruleset *format.Ruleset, bases []*Resource) (*format.Ruleset, error) {
+	out := *ruleset
+	out.Spec.Extends = nil
+	out.Spec.Rules = make(map[string]format.RuleItem)
+	out.Spec.Fragments = nil
+	out.Spec.Order = nil
+
+	ruleFrom := make(map[string]string)
+	fragmentFrom := make(map[string]string)
+	for _, base := range bases {
+		spec := base.Spec.(*format.Ruleset).Spec
+		for _, id := range spec.RuleIDs() {
+			item := spec.Rules[id]
+			if first, ok := ruleFrom[id]; ok {
+				if _, overridden := ruleset.Spec.Rules[id]; !overridden && !reflect.DeepEqual(out.Spec.Rules[id], item) {
+					return nil, fmt.Errorf("rule %q is defined differently by %s and %s; define it in %s to settle it", id, first, base.Metadata.ID, ruleset.Metadata.ID)
+				}
+				continue
+			}
+			out.Spec.Rules[id] = item
+			out.Spec.Order = append(out.Spec.Order, id)
+			ruleFrom[id] = base.Metadata.ID
+		}
+		for _, name := range format.SortedKeys(spec.Fragments) {
+			content := spec.Fragments[name]
+			if first, ok := fragmentFrom[name]; ok {
+				if _, overridden := ruleset.Spec.Fragments[name]; !overridden && out.Spec.Fragments[name] != content {
+					return nil, fmt.Errorf("fragment %q is defined differently by %s and %s; define it in %s to settle it", name, first, base.Metadata.ID, ruleset.Metadata.ID)
+				}
+				continue
+			}
+			if out.Spec.Fragments == nil {
+				out.Spec.Fragments = make(map[string]string)
+			}
+			out.Spec.Fragments[name] = content
+			fragmentFrom[name] = base.Metadata.ID
+		}
+	}
+
+	for _, id := range ruleset.Spec.RuleIDs() {
+		item := ruleset.Spec.Rules[id]
+		if inherited, ok := out.Spec.Rules[id]; ok {
+			out.Spec.Rules[id] = overrideRule(inherited, item)
+			continue
+		}
+		out.Spec.Rules[id] = item
+		out.Spec.Order = append(out.Spec.Order, id)
+	}
+	for name, content := range ruleset.Spec.Fragments {
+		if out.Spec.Fragments == nil {
+			out.Spec.Fragments = make(map[string]string)
+		}
+		out.Spec.Fragments[name] = content
+	}
+	return &out, nil
+}
+
+// overrideRule returns inherited with the fields item sets replaced.
+func overrideRule(inherited, item format.RuleItem) format.RuleItem {
+	if item.Name != "" {
+		inherited.Name = item.Name
+	}
+	if item.Description != "" {
+		inherited.Description = item.Description
+	}
+	if item.Enforcement != "" {
+		inherited.Enforcement = item.Enforcement
+	}
+	if item.Scope != nil {
+		inherited.Scope = item.Scope
+	}
+	if !item.Body.IsZero() {
+		inherited.Body = item.Body
+	}
+	if item.Bodies != nil {
+		inherited.Bodies = item.Bodies
+	}
+	if item.Tags != nil {
+		inherited.Tags = item.Tags
+	}
+	return inherited
+}
@@ -0,0 +1,145 @@
+package compiler
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jomadu/ai-resource-compiler-go/internal/format"
+	"gopkg.in/yaml.v3"
+)
+
+func parseTestResource(t *testing.T, src string) *Resource {
+	t.Helper()
+	var resource Resource
+	if err := yaml.Unmarshal([]byte(src), &resource); err != nil {
+		t.Fatalf("failed to parse resource: %v", err)
+	}
+	return &resource
+}
+
+const baseRuleset = `apiVersion: ai-resource/draft
+kind: Ruleset
+metadata:
+  id: base
+spec:
+  fragments:
+    team: Platform
+  rules:
+    style:
+      name: Style
+      enforcement: should
+      scope:
+        - files: ["**/*.go"]
+      body: Format with gofmt.
+    tests:
+      enforcement: must
+      body: Add tests.
+`
+
+func TestResolveExtends(t *testing.T) {
+	child := parseTestResource(t, `apiVersion: ai-resource/draft
+kind: Ruleset
+metadata:
+  id: child
+spec:
+  extends: [base]
+  fragments:
+    team: Tools
+  rules:
+    docs:
+      enforcement: may
+      body: Write docs.
+    style:
+      enforcement: must
+`)
+	base := parseTestResource(t, baseRuleset)
+
+	resolved, err := ResolveExtends([]*Resource{child, base})
+	if err != nil {
+		t.Fatalf("ResolveExtends() error = %v", err)
+	}
+	if resolved[1] != base {
+		t.Error("ResolveExtends() copied a ruleset without extends")
+	}
+	spec := resolved[0].Spec.(*format.Ruleset).Spec
+	if got := strings.Join(spec.RuleIDs(), ","); got != "style,tests,docs" {
+		t.Errorf("rule order = %s, want style,tests,docs", got)
+	}
+	style := spec.Rules["style"]
+	if style.Enforcement != "must" || style.Name != "Style" || *style.Body.String != "Format with gofmt." || len(style.Scope) != 1 {
+		t.Errorf("overridden rule = %+v, want enforcement must and the rest inherited", style)
+	}
+	if spec.Fragments["team"] != "Tools" {
+		t.Errorf("fragment team = %q, want the ruleset's own", spec.Fragments["team"])
+	}
+	if len(spec.Extends) != 0 || len(resolved[0].Bases) != 1 || resolved[0].Bases[0] != base {
+		t.Errorf("Extends = %v, Bases = %v, want extends resolved to base", spec.Extends, resolved[0].Bases)
+	}
+	if child.Spec.(*format.Ruleset).Spec.Rules["style"].Enforcement != "must" || len(child.Spec.(*format.Ruleset).Spec.Extends) != 1 {
+		t.Error("ResolveExtends() modified the extending ruleset")
+	}
+	if problems := Validate(resolved[0]); len(problems) != 0 {
+		t.Errorf("Validate() = %v for the resolved ruleset", problems)
+	}
+}
+
+func TestResolveExtendsErrors(t *testing.T) {
+	ruleset := func(id, extends, rules string) *Resource {
+		return parseTestResource(t, "apiVersion: ai-resource/draft\nkind: Ruleset\nmetadata:\n  id: "+id+"\nspec:\n  extends: ["+extends+"]\n  rules:\n"+rules)
+	}
+	rule := func(id, body string) string {
+		return "    " + id + ":\n      enforcement: must\n      body: " + body + "\n"
+	}
+
+	tests := []struct {
+		name      string
+		resources []*Resource
+		want      string
+	}{
+		{"unknown base", []*Resource{ruleset("child", "missing", rule("a", "A"))}, "extends unknown ruleset missing"},
+		{"cycle", []*Resource{ruleset("a", "b", rule("a", "A")), ruleset("b", "a", rule("b", "B"))}, "extends cycle: a -> b -> a"},
+		{
+			"conflicting bases",
+			[]*Resource{ruleset("child", "one, two", rule("c", "C")), ruleset("one", "", rule("x", "One")), ruleset("two", "", rule("x", "Two"))},
+			`rule "x" is defined differently by one and two`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ResolveExtends(tt.resources)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("ResolveExtends() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+
+	// The extending ruleset settles a conflict by defining the rule.
+	resources := []*Resource{
+		ruleset("child", "one, two", rule("x", "Child")),
+		ruleset("one", "", rule("x", "One")),
+		ruleset("two", "", rule("x", "Two")),
+	}
+	resolved, err := ResolveExtends(resources)
+	if err != nil {
+		t.Fatalf("ResolveExtends() error = %v", err)
+	}
+	if body := resolved[0].Spec.(*format.Ruleset).Spec.Rules["x"].Body; *body.String != "Child" {
+		t.Errorf("rule x body = %q, want Child", *body.String)
+	}
+}
+
+func TestCompileAllExtends(t *testing.T) {
+	c := setupCompiler()
+	child := parseTestResource(t, "apiVersion: ai-resource/draft\nkind: Ruleset\nmetadata:\n  id: child\nspec:\n  extends: [base]\n  rules: {}\n")
+
+	if _, err := c.Compile(child, CompileOptions{Targets: []Target{TargetMarkdown}}); err == nil || !strings.Contains(err.Error(), "unresolved extends") {
+		t.Errorf("Compile() error = %v, want unresolved extends", err)
+	}
+	results, err := c.CompileAll([]*Resource{child, parseTestResource(t, baseRuleset)}, CompileOptions{Targets: []Target{TargetMarkdown}})
+	if err != nil {
+		t.Fatalf("CompileAll() error = %v", err)
+	}
+	if len(results) == 0 {
+		t.Error("CompileAll() returned no results")
+	}
+}
@@ -0,0 +1,219 @@
+package compiler
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/jomadu/ai-resource-compiler-go/internal/format"
+)
+
+// filterItems returns resource with only the rules or prompts of a Ruleset or
+// Promptset selected by only and exclude, or nil if only names none of them.
+// An empty only selects every item. A standalone Rule or Prompt is returned
+// if only, when set, names it and exclude does not, and nil otherwise; other
+// standalone resources are returned unchanged. IDs no resource of the build
+// has are reported by CheckItems.
+func filterItems(resource *Resource, only, exclude []string) (*Resource, error) {
+	if len(only) == 0 && len(exclude) == 0 {
+		return resource, nil
+	}
+
+	out := *resource
+	switch spec := resource.Spec.(type) {
+	case *format.Rule, *format.Prompt:
+		id := resource.Metadata.ID
+		if (len(only) > 0 && !slices.Contains(only, id)) || slices.Contains(exclude, id) {
+			return nil, nil
+		}
+		return resource, nil
+	case *format.Ruleset:
+		keep, err := selectItems(resource.Metadata.ID, mapKeys(spec.Spec.Rules), only, exclude)
+		if err != nil || keep == nil {
+			return nil, err
+		}
+		ruleset := *spec
+		ruleset.Spec.Rules = make(map[string]format.RuleItem, len(keep))
+		for _, id := range keep {
+			ruleset.Spec.Rules[id] = spec.Spec.Rules[id]
+		}
+		out.Spec = &ruleset
+	case *format.Promptset:
+		keep, err := selectItems(resource.Metadata.ID, mapKeys(spec.Spec.Prompts), only, exclude)
+		if err != nil || keep == nil {
+			return nil, err
+		}
+		promptset := *spec
+		promptset.Spec.Prompts = make(map[string]format.PromptItem, len(keep))
+		for _, id := range keep {
+			promptset.Spec.Prompts[id] = spec.Spec.Prompts[id]
+		}
+		out.Spec = &promptset
+	default:
+		return resource, nil
+	}
+	return &out, nil
+}
+
+// filterEnforcement returns resource without the rules whose enforcement
+// ranks below min, or nil if no rule is left. An empty min keeps every rule.
+func filterEnforcement(resource *Resource, min string) (*Resource, error) {
+	if min == "" {
+		return resource, nil
+	}
+	level, ok := LookupEnforcement(min)
+	if !ok {
+		return nil, fmt.Errorf("unknown minimum enforcement %q (expected %s)", min, expectedEnforcement())
+	}
+	meets := func(enforcement string) bool {
+		l, _ := LookupEnforcement(enforcement)
+		return l.Rank >= level.Rank
+	}
+
+	switch spec := resource.Spec.(type) {
+	case *format.Rule:
+		if !meets(spec.Spec.Enforcement) {
+			return nil, nil
+		}
+	case *format.Ruleset:
+		ruleset := *spec
+		ruleset.Spec.Rules = make(map[string]format.RuleItem)
+		for id, item := range spec.Spec.Rules {
+			if meets(item.Enforcement) {
+				ruleset.Spec.Rules[id] = item
+			}
+		}
+		if len(ruleset.Spec.Rules) == 0 {
+			return nil, nil
+		}
+		out := *resource
+		out.Spec = &ruleset
+		return &out, nil
+	}
+	return resource, nil
+}
+
+// filterTags returns resource without the rules and prompts that carry none
+// of tags, or nil if nothing is left. An item carries the tags of its
+// collection and its own; other resources carry their metadata tags. Empty
+// tags keep everything.
+func filterTags(resource *Resource, tags []string) *Resource {
+	if len(tags) == 0 {
+		return resource
+	}
+
+	var own []string
+	switch spec := resource.Spec.(type) {
+	case *format.Rule:
+		own = spec.Metadata.Tags
+	case *format.Prompt:
+		own = spec.Metadata.Tags
+	case *format.Command:
+		own = spec.Metadata.Tags
+	case *format.Context:
+		own = spec.Metadata.Tags
+	case *format.Ruleset:
+		ruleset := *spec
+		ruleset.Spec.Rules = make(map[string]format.RuleItem)
+		for id, item := range spec.Spec.Rules {
+			if format.HasAnyTag(format.MergeTags(spec.Metadata.Tags, item.Tags), tags) {
+				ruleset.Spec.Rules[id] = item
+			}
+		}
+		if len(ruleset.Spec.Rules) == 0 {
+			return nil
+		}
+		out := *resource
+		out.Spec = &ruleset
+		return &out
+	case *format.Promptset:
+		promptset := *spec
+		promptset.Spec.Prompts = make(map[string]format.PromptItem)
+		for id, item := range spec.Spec.Prompts {
+			if format.HasAnyTag(format.MergeTags(spec.Metadata.Tags, item.Tags), tags) {
+				promptset.Spec.Prompts[id] = item
+			}
+		}
+		if len(promptset.Spec.Prompts) == 0 {
+			return nil
+		}
+		out := *resource
+		out.Spec = &promptset
+		return &out
+	}
+	if !format.HasAnyTag(own, tags) {
+		return nil
+	}
+	return resource
+}
+
+// selectItems returns the IDs in ids selected by only and exclude, or nil if
+// only names none of them. It is an error for only and exclude to leave none
+// of the items only names.
+func selectItems(collection string, ids, only, exclude []string) ([]string, error) {
+	if len(only) > 0 && !slices.ContainsFunc(ids, func(id string) bool { return slices.Contains(only, id) }) {
+		return nil, nil
+	}
+
+	selected := make(map[string]bool)
+	for _, id := range only {
+		selected[id] = true
+	}
+	excluded := make(map[string]bool)
+	for _, id := range exclude {
+		excluded[id] = true
+	}
+	var keep []string
+	for _, id := range ids {
+		if (len(only) == 0 || selected[id]) && !excluded[id] {
+			keep = append(keep, id)
+		}
+	}
+	if len(keep) == 0 {
+		return nil, fmt.Errorf("no items of %s left after filtering", collection)
+	}
+	return keep, nil
+}
+
+// CheckItems returns ErrUnknownItem for the first ID of only or exclude that
+// none of resources has: no rule or prompt of a Ruleset or Promptset and no
+// standalone Rule or Prompt. Compile skips the resources an ID does not
+// match, so a build of several resources checks the IDs against all of them
+// at once; CompileAll does so itself.
+func CheckItems(resources []*Resource, only, exclude []string) error {
+	if len(only) == 0 && len(exclude) == 0 {
+		return nil
+	}
+	known := make(map[string]bool)
+	for _, resource := range resources {
+		switch spec := resource.Spec.(type) {
+		case *format.Rule, *format.Prompt:
+			known[resource.Metadata.ID] = true
+		case *format.Ruleset:
+			for id := range spec.Spec.Rules {
+				known[id] = true
+			}
+		case *format.Promptset:
+			for id := range spec.Spec.Prompts {
+				known[id] = true
+			}
+		}
+	}
+	for _, id := range append(append([]string(nil), only...), exclude...) {
+		if !known[id] {
+			ids := mapKeys(known)
+			sort.Strings(ids)
+			return fmt.Errorf("%w: %s (items: %s)", ErrUnknownItem, id, strings.Join(ids, ", "))
+		}
+	}
+	return nil
+}
+
+func mapKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	return keys
+}
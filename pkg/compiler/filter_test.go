@@ -0,0 +1,190 @@
+package compiler
+
+import (
+	"errors"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/jomadu/ai-resource-compiler-go/internal/format"
+)
+
+func TestFilterItems(t *testing.T) {
+	resource := &Resource{
+		APIVersion: "ai-resource/draft",
+		Kind:       "Ruleset",
+		Spec: &format.Ruleset{Spec: format.RulesetSpec{Rules: map[string]format.RuleItem{
+			"a": {}, "b": {}, "c": {},
+		}}},
+	}
+	resource.Metadata.ID = "rules"
+
+	tests := []struct {
+		name          string
+		only, exclude []string
+		want          []string
+	}{
+		{name: "none", want: []string{"a", "b", "c"}},
+		{name: "only", only: []string{"a", "c"}, want: []string{"a", "c"}},
+		{name: "exclude", exclude: []string{"b"}, want: []string{"a", "c"}},
+		{name: "both", only: []string{"a", "b"}, exclude: []string{"b"}, want: []string{"a"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := filterItems(resource, tt.only, tt.exclude)
+			if err != nil {
+				t.Fatalf("filterItems() error = %v", err)
+			}
+			ids := mapKeys(got.Spec.(*format.Ruleset).Spec.Rules)
+			sort.Strings(ids)
+			if !reflect.DeepEqual(ids, tt.want) {
+				t.Errorf("filterItems() items = %v, want %v", ids, tt.want)
+			}
+		})
+	}
+
+	if len(resource.Spec.(*format.Ruleset).Spec.Rules) != 3 {
+		t.Error("filterItems() modified the input resource")
+	}
+	if got, err := filterItems(resource, []string{"z"}, nil); got != nil || err != nil {
+		t.Errorf("filterItems() = %v, %v, want the collection without z skipped", got, err)
+	}
+	if got, err := filterItems(resource, []string{"a", "z"}, nil); err != nil || len(got.Spec.(*format.Ruleset).Spec.Rules) != 1 {
+		t.Errorf("filterItems() = %v, %v, want a kept though z is elsewhere", got, err)
+	}
+	if _, err := filterItems(resource, nil, []string{"a", "b", "c"}); err == nil {
+		t.Error("filterItems() excluding every item succeeded, want error")
+	}
+}
+
+func TestFilterItems_Standalone(t *testing.T) {
+	resource := &Resource{Kind: "Rule", Spec: &format.Rule{}}
+	resource.Metadata.ID = "naming"
+
+	tests := []struct {
+		name          string
+		only, exclude []string
+		kept          bool
+	}{
+		{name: "only names it", only: []string{"naming", "other"}, kept: true},
+		{name: "only names others", only: []string{"other"}},
+		{name: "excluded", exclude: []string{"naming"}},
+		{name: "others excluded", exclude: []string{"other"}, kept: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := filterItems(resource, tt.only, tt.exclude)
+			if err != nil || (got == resource) != tt.kept || (got == nil) == tt.kept {
+				t.Errorf("filterItems() = %v, %v, want kept %v", got, err, tt.kept)
+			}
+		})
+	}
+
+	command := &Resource{Kind: "Command", Spec: &format.Command{}}
+	if got, err := filterItems(command, []string{"other"}, nil); err != nil || got != command {
+		t.Errorf("filterItems() = %v, %v, want the command unchanged", got, err)
+	}
+}
+
+func TestCheckItems(t *testing.T) {
+	ruleset := &Resource{Kind: "Ruleset", Spec: &format.Ruleset{Spec: format.RulesetSpec{Rules: map[string]format.RuleItem{"a": {}}}}}
+	promptset := &Resource{Kind: "Promptset", Spec: &format.Promptset{Spec: format.PromptsetSpec{Prompts: map[string]format.PromptItem{"b": {}}}}}
+	rule := &Resource{Kind: "Rule", Spec: &format.Rule{}}
+	rule.Metadata.ID = "c"
+	resources := []*Resource{ruleset, promptset, rule}
+
+	if err := CheckItems(resources, []string{"a", "b"}, []string{"c"}); err != nil {
+		t.Errorf("CheckItems() error = %v, want every ID found in some resource", err)
+	}
+	err := CheckItems(resources, []string{"a"}, []string{"z"})
+	if !errors.Is(err, ErrUnknownItem) || !strings.Contains(err.Error(), "z (items: a, b, c)") {
+		t.Errorf("CheckItems() error = %v, want ErrUnknownItem for z", err)
+	}
+}
+
+func TestFilterEnforcement(t *testing.T) {
+	resource := &Resource{
+		APIVersion: "ai-resource/draft",
+		Kind:       "Ruleset",
+		Spec: &format.Ruleset{Spec: format.RulesetSpec{Rules: map[string]format.RuleItem{
+			"a": {Enforcement: "may"}, "b": {Enforcement: "should"}, "c": {Enforcement: "must"},
+		}}},
+	}
+
+	tests := []struct {
+		min  string
+		want []string
+	}{
+		{"", []string{"a", "b", "c"}},
+		{"may", []string{"a", "b", "c"}},
+		{"should", []string{"b", "c"}},
+		{"must", []string{"c"}},
+	}
+	for _, tt := range tests {
+		got, err := filterEnforcement(resource, tt.min)
+		if err != nil {
+			t.Fatalf("filterEnforcement(%q) error = %v", tt.min, err)
+		}
+		ids := mapKeys(got.Spec.(*format.Ruleset).Spec.Rules)
+		sort.Strings(ids)
+		if !reflect.DeepEqual(ids, tt.want) {
+			t.Errorf("filterEnforcement(%q) items = %v, want %v", tt.min, ids, tt.want)
+		}
+	}
+	if _, err := filterEnforcement(resource, "always"); err == nil {
+		t.Error("filterEnforcement() accepted an unknown level")
+	}
+
+	// A rule below the minimum compiles to nothing.
+	rule := testRule("Body")
+	rule.Spec.(*format.Rule).Spec.Enforcement = "should"
+	results, err := setupCompiler().Compile(rule, CompileOptions{Targets: []Target{TargetMarkdown}, MinEnforcement: "must"})
+	if err != nil || len(results) != 0 {
+		t.Errorf("Compile() = %v, %v, want no results", results, err)
+	}
+}
+
+func TestFilterTags(t *testing.T) {
+	resource := &Resource{
+		APIVersion: "ai-resource/draft",
+		Kind:       "Ruleset",
+		Spec: &format.Ruleset{
+			Metadata: format.Metadata{Tags: []string{"shared"}},
+			Spec: format.RulesetSpec{Rules: map[string]format.RuleItem{
+				"a": {Tags: []string{"backend"}}, "b": {Tags: []string{"security", "frontend"}}, "c": {},
+			}},
+		},
+	}
+
+	tests := []struct {
+		tags []string
+		want []string
+	}{
+		{nil, []string{"a", "b", "c"}},
+		{[]string{"backend"}, []string{"a"}},
+		{[]string{"backend", "security"}, []string{"a", "b"}},
+		{[]string{"shared"}, []string{"a", "b", "c"}},
+	}
+	for _, tt := range tests {
+		got := filterTags(resource, tt.tags)
+		ids := mapKeys(got.Spec.(*format.Ruleset).Spec.Rules)
+		sort.Strings(ids)
+		if !reflect.DeepEqual(ids, tt.want) {
+			t.Errorf("filterTags(%v) items = %v, want %v", tt.tags, ids, tt.want)
+		}
+	}
+	if got := filterTags(resource, []string{"mobile"}); got != nil {
+		t.Errorf("filterTags() = %v, want nil when no rule matches", got)
+	}
+
+	// A standalone rule carries its metadata tags.
+	rule := testRule("Body")
+	rule.Spec.(*format.Rule).Metadata.Tags = []string{"backend"}
+	for tags, want := range map[string]int{"backend": 1, "frontend": 0} {
+		results, err := setupCompiler().Compile(rule, CompileOptions{Targets: []Target{TargetMarkdown}, Tags: []string{tags}})
+		if err != nil || len(results) != want {
+			t.Errorf("Compile(Tags: %s) = %d results, %v, want %d", tags, len(results), err, want)
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package compiler
+
+import "fmt"
+
+// PreCompileHook inspects each resource before it compiles, e.g. to enforce
+// an organization's conventions. Add hooks with WithPreCompileHook.
+type PreCompileHook interface {
+	// PreCompile is called with resource once it has passed validation and
+	// its items have been selected and its bodies localized and expanded,
+	// before any target compiles it. It must not modify resource. An error
+	// stops the compile as a validation problem would.
+	PreCompile(resource *Resource) error
+}
+
+// PreCompileFunc adapts a function to PreCompileHook.
+type PreCompileFunc func(resource *Resource) error
+
+// PreCompile calls f.
+func (f PreCompileFunc) PreCompile(resource *Resource) error {
+	return f(resource)
+}
+
+// PostCompileHook transforms each file the targets produce, e.g. to insert a
+// banner or redact internal hostnames, without writing a target. Add hooks
+// with WithPostCompileHook.
+type PostCompileHook interface {
+	// PostCompile returns result, a file of target, as it should be written
+	// instead. It sees files as Compile returns them: renamed, and merged
+	// across resources by merging targets. Assets are passed too; check
+	// result.Asset before treating Content as text. An error stops the
+	// compile as a target's error would.
+	PostCompile(target Target, result CompilationResult) (CompilationResult, error)
+}
+
+// PostCompileFunc adapts a function to PostCompileHook.
+type PostCompileFunc func(target Target, result CompilationResult) (CompilationResult, error)
+
+// PostCompile calls f.
+func (f PostCompileFunc) PostCompile(target Target, result CompilationResult) (CompilationResult, error) {
+	return f(target, result)
+}
+
+// preCompile runs the compiler's pre-compile hooks on resource, in the
+// order they were added, stopping at the first error.
+func (c *Compiler) preCompile(resource *Resource) error {
+	for _, hook := range c.preHooks {
+		if err := hook.PreCompile(resource); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// postCompile runs the compiler's post-compile hooks on results of target,
+// in the order they were added, each seeing the file the previous returned.
+func (c *Compiler) postCompile(target Target, results []CompilationResult) ([]CompilationResult, error) {
+	if len(c.postHooks) == 0 {
+		return results, nil
+	}
+	out := make([]CompilationResult, len(results))
+	for i, result := range results {
+		for _, hook := range c.postHooks {
+			transformed, err := hook.PostCompile(target, result)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", result.Path, err)
+			}
+			result = transformed
+		}
+		out[i] = result
+	}
+	return out, nil
+}
@@ -0,0 +1,87 @@
+package compiler
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestPreCompileHook(t *testing.T) {
+	var seen []string
+	errNoNamespace := &ValidationError{Field: "metadata.namespace", Message: "namespace required"}
+	c := NewCompiler(WithoutDefaults(), WithTargets(&mockMarkdownCompiler{}),
+		WithPreCompileHook(PreCompileFunc(func(resource *Resource) error {
+			seen = append(seen, resource.Metadata.ID)
+			if resource.Metadata.Namespace == "" {
+				return errNoNamespace
+			}
+			return nil
+		})))
+	opts := CompileOptions{Targets: []Target{TargetMarkdown}}
+
+	_, err := c.Compile(testRule("body"), opts)
+	var compileErr *CompileError
+	if !errors.Is(err, errNoNamespace) || !errors.As(err, &compileErr) || compileErr.Field != "metadata.namespace" {
+		t.Fatalf("Compile() error = %v, want the hook's error on metadata.namespace", err)
+	}
+
+	resource := testRule("body")
+	resource.Metadata.Namespace = "acme"
+	if _, err := c.Compile(resource, opts); err != nil {
+		t.Fatalf("Compile() error = %v", err)
+	}
+	if _, err := c.CompileAll([]*Resource{resource}, opts); err != nil {
+		t.Fatalf("CompileAll() error = %v", err)
+	}
+	if strings.Join(seen, ",") != "testRule,testRule,testRule" {
+		t.Errorf("hook saw %v, want each compile", seen)
+	}
+}
+
+func TestPostCompileHook(t *testing.T) {
+	banner := PostCompileFunc(func(target Target, result CompilationResult) (CompilationResult, error) {
+		result.Content = "<!-- " + string(target) + " -->\n" + result.Content
+		return result, nil
+	})
+	redact := PostCompileFunc(func(_ Target, result CompilationResult) (CompilationResult, error) {
+		result.Content = strings.ReplaceAll(result.Content, "mock", "[redacted]")
+		return result, nil
+	})
+	c := NewCompiler(WithoutDefaults(), WithTargets(&mockMarkdownCompiler{}),
+		WithPostCompileHook(redact), WithPostCompileHook(banner))
+	opts := CompileOptions{Targets: []Target{TargetMarkdown}}
+	want := "<!-- markdown -->\n[redacted] content"
+
+	results, err := c.Compile(testRule("body"), opts)
+	if err != nil {
+		t.Fatalf("Compile() error = %v", err)
+	}
+	if len(results) != 1 || results[0].Content != want {
+		t.Fatalf("Compile() = %+v, want content %q", results, want)
+	}
+	if results[0].Stats.Bytes != len(want) {
+		t.Errorf("Stats.Bytes = %d, want the size after the hooks, %d", results[0].Stats.Bytes, len(want))
+	}
+
+	results, err = c.CompileAll([]*Resource{testRule("body")}, opts)
+	if err != nil {
+		t.Fatalf("CompileAll() error = %v", err)
+	}
+	if len(results) != 1 || results[0].Content != want {
+		t.Errorf("CompileAll() = %+v, want content %q", results, want)
+	}
+
+	errSecret := errors.New("secret found")
+	c = NewCompiler(WithoutDefaults(), WithTargets(&mockMarkdownCompiler{}),
+		WithPostCompileHook(PostCompileFunc(func(Target, CompilationResult) (CompilationResult, error) {
+			return CompilationResult{}, errSecret
+		})))
+	_, err = c.Compile(testRule("body"), opts)
+	var compileErr *CompileError
+	if !errors.Is(err, errSecret) || !errors.As(err, &compileErr) || compileErr.Target != TargetMarkdown {
+		t.Fatalf("Compile() error = %v, want the hook's error in target markdown", err)
+	}
+	if !strings.Contains(err.Error(), "testRule.md: secret found") {
+		t.Errorf("Compile() error = %v, want the file named", err)
+	}
+}
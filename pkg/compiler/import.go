@@ -0,0 +1,85 @@
+package compiler
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/jomadu/ai-resource-compiler-go/internal/format"
+)
+
+// ErrNotImportable is returned by Import for targets that do not implement
+// ImportingTarget.
+var ErrNotImportable = errors.New("target cannot import files")
+
+// ImportedRule is a rule read back from a file in a target's format.
+type ImportedRule struct {
+	// ID is the rule's ID in the imported ruleset, usually the file name
+	// without its extension.
+	ID   string
+	Rule format.RuleItem
+}
+
+// ImportingTarget is implemented by target compilers that can read files in
+// their format, hand-written or compiled, back into rules, so existing rules
+// can be migrated into resources.
+type ImportingTarget interface {
+	TargetCompiler
+
+	// ImportRule parses content, the file at path relative to the directory
+	// imported, into a rule. ok is false for files that are not rules in the
+	// target's format, such as prompts or context files, which are skipped.
+	ImportRule(path string, content []byte) (rule ImportedRule, ok bool, err error)
+}
+
+// Import reads files in target's format, keyed by slash-separated path,
+// into a Ruleset with the given ID. Rules are ordered by path; files the
+// target does not recognize as rules are skipped. The ruleset is validated
+// as Compile would.
+func (c *Compiler) Import(target Target, id string, files map[string][]byte) (*Resource, error) {
+	tc, ok := c.targets[target]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownTarget, target)
+	}
+	importer, ok := tc.(ImportingTarget)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrNotImportable, target)
+	}
+
+	paths := make([]string, 0, len(files))
+	for p := range files {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+
+	ruleset := &format.Ruleset{
+		Metadata: format.Metadata{ID: id},
+		Spec:     format.RulesetSpec{Rules: make(map[string]format.RuleItem)},
+	}
+	from := make(map[string]string)
+	for _, p := range paths {
+		imported, ok, err := importer.ImportRule(p, files[p])
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", p, err)
+		}
+		if !ok {
+			continue
+		}
+		if other, dup := from[imported.ID]; dup {
+			return nil, fmt.Errorf("rule %s is imported from both %s and %s", imported.ID, other, p)
+		}
+		from[imported.ID] = p
+		ruleset.Spec.Rules[imported.ID] = imported.Rule
+		ruleset.Spec.Order = append(ruleset.Spec.Order, imported.ID)
+	}
+	if len(ruleset.Spec.Order) == 0 {
+		return nil, fmt.Errorf("no %s rule files among %d file(s)", target, len(files))
+	}
+
+	resource := &Resource{APIVersion: "ai-resource/draft", Kind: "Ruleset", Spec: ruleset}
+	resource.Metadata.ID = id
+	if problems := c.validateResource(resource); len(problems) > 0 {
+		return nil, problems[0]
+	}
+	return resource, nil
+}
@@ -0,0 +1,59 @@
+package compiler
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/jomadu/ai-resource-compiler-go/internal/format"
+)
+
+// mockImportingCompiler imports each .md file as a must rule named after
+// the file, with the file's content as its body.
+type mockImportingCompiler struct {
+	mockMarkdownCompiler
+}
+
+func (m *mockImportingCompiler) ImportRule(path string, content []byte) (ImportedRule, bool, error) {
+	id, ok := strings.CutSuffix(path[strings.LastIndex(path, "/")+1:], ".md")
+	if !ok {
+		return ImportedRule{}, false, nil
+	}
+	body := string(content)
+	return ImportedRule{ID: id, Rule: format.RuleItem{Name: id, Enforcement: "must", Body: format.Body{String: &body}}}, true, nil
+}
+
+func TestCompiler_Import(t *testing.T) {
+	c := NewCompiler(WithoutDefaults())
+	c.RegisterTarget(TargetMarkdown, &mockImportingCompiler{})
+	c.RegisterTarget(TargetCursor, &mockCursorCompiler{})
+
+	resource, err := c.Import(TargetMarkdown, "imported", map[string][]byte{
+		"b.md":      []byte("Second."),
+		"a.md":      []byte("First."),
+		"notes.txt": []byte("Skipped."),
+	})
+	if err != nil {
+		t.Fatalf("Import() error = %v", err)
+	}
+	ruleset := resource.Spec.(*format.Ruleset)
+	if resource.Kind != "Ruleset" || resource.Metadata.ID != "imported" || ruleset.Metadata.ID != "imported" {
+		t.Errorf("Import() = %s %s, want Ruleset imported", resource.Kind, resource.Metadata.ID)
+	}
+	if got := strings.Join(ruleset.Spec.Order, ","); got != "a,b" {
+		t.Errorf("Import() order = %s, want a,b", got)
+	}
+
+	if _, err := c.Import(TargetMarkdown, "imported", map[string][]byte{"x/a.md": nil, "y/a.md": nil}); err == nil || !strings.Contains(err.Error(), "imported from both x/a.md and y/a.md") {
+		t.Errorf("Import() error = %v, want duplicate rule error", err)
+	}
+	if _, err := c.Import(TargetMarkdown, "imported", map[string][]byte{"notes.txt": nil}); err == nil || !strings.Contains(err.Error(), "no markdown rule files") {
+		t.Errorf("Import() error = %v, want no rule files error", err)
+	}
+	if _, err := c.Import(TargetCursor, "imported", nil); !errors.Is(err, ErrNotImportable) {
+		t.Errorf("Import() error = %v, want ErrNotImportable", err)
+	}
+	if _, err := c.Import("unknown", "imported", nil); !errors.Is(err, ErrUnknownTarget) {
+		t.Errorf("Import() error = %v, want ErrUnknownTarget", err)
+	}
+}
@@ -1,9 +1,11 @@
 package compiler
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/jomadu/ai-resource-compiler-go/internal/format"
+	"github.com/jomadu/ai-resource-compiler-go/internal/suggest"
 	"gopkg.in/yaml.v3"
 )
 
@@ -13,9 +15,46 @@ type Resource struct {
 	APIVersion string
 	Kind       string
 	Metadata   struct {
-		ID string
+		ID        string
+		Namespace string
 	}
+	// Spec is one of *resource.Rule, *resource.Ruleset, *resource.Prompt,
+	// *resource.Promptset, *resource.Command, or *resource.Context, matching
+	// Kind.
 	Spec interface{}
+
+	// Source is the file the resource was loaded from, if known.
+	Source string
+
+	// Includes lists the fragment libraries and resource files the resource
+	// file includes, as written. IncludedFragments and IncludedItems map each
+	// fragment, and each rule or prompt, merged from an include to the
+	// include that defined it.
+	Includes          []string
+	IncludedFragments map[string]string
+	IncludedItems     map[string]string
+
+	// IncludedFiles lists every file read for the includes and assets,
+	// those of included resource files too: paths as the loader opened
+	// them, or URLs.
+	IncludedFiles []string
+
+	// Bases lists the rulesets a Ruleset's extends resolved to, set by
+	// ResolveExtends.
+	Bases []*Resource
+
+	// Node is the YAML node the resource was decoded from, used to report
+	// the line and column of invalid fields. It is nil for resources built
+	// in code or evaluated from CUE.
+	Node *yaml.Node
+}
+
+// kinds lists the resource kinds, in the order suggestions name them.
+var kinds = []string{"Rule", "Ruleset", "Prompt", "Promptset", "Command", "Context"}
+
+// Kinds returns the resource kinds a Resource can hold.
+func Kinds() []string {
+	return append([]string(nil), kinds...)
 }
 
 // UnmarshalYAML implements custom YAML unmarshaling for Resource.
@@ -26,9 +65,11 @@ func (r *Resource) UnmarshalYAML(node *yaml.Node) error {
 		APIVersion string    `yaml:"apiVersion"`
 		Kind       string    `yaml:"kind"`
 		Metadata   struct {
-			ID          string `yaml:"id"`
-			Name        string `yaml:"name"`
-			Description string `yaml:"description,omitempty"`
+			ID          string   `yaml:"id"`
+			Namespace   string   `yaml:"namespace,omitempty"`
+			Name        string   `yaml:"name"`
+			Description string   `yaml:"description,omitempty"`
+			Tags        []string `yaml:"tags,omitempty"`
 		} `yaml:"metadata"`
 		Spec yaml.Node `yaml:"spec"`
 	}
@@ -40,57 +81,136 @@ func (r *Resource) UnmarshalYAML(node *yaml.Node) error {
 
 	r.APIVersion = raw.APIVersion
 	r.Kind = raw.Kind
+	r.Node = node
 	r.Metadata.ID = raw.Metadata.ID
+	r.Metadata.Namespace = raw.Metadata.Namespace
 
 	// Unmarshal Spec based on Kind
 	switch raw.Kind {
 	case "Rule":
 		var rule format.Rule
-		if err := raw.Spec.Decode(&rule); err != nil {
+		if err := raw.Spec.Decode(&rule.Spec); err != nil {
 			return fmt.Errorf("failed to decode Rule spec: %w", err)
 		}
 		// Copy metadata from top level
 		rule.Metadata.ID = raw.Metadata.ID
+		rule.Metadata.Namespace = raw.Metadata.Namespace
 		rule.Metadata.Name = raw.Metadata.Name
 		rule.Metadata.Description = raw.Metadata.Description
+		rule.Metadata.Tags = raw.Metadata.Tags
 		r.Spec = &rule
 	case "Ruleset":
 		var ruleset format.Ruleset
-		if err := raw.Spec.Decode(&ruleset); err != nil {
+		if err := raw.Spec.Decode(&ruleset.Spec); err != nil {
 			return fmt.Errorf("failed to decode Ruleset spec: %w", err)
 		}
 		// Copy metadata from top level
 		ruleset.Metadata.ID = raw.Metadata.ID
+		ruleset.Metadata.Namespace = raw.Metadata.Namespace
 		ruleset.Metadata.Name = raw.Metadata.Name
 		ruleset.Metadata.Description = raw.Metadata.Description
+		ruleset.Metadata.Tags = raw.Metadata.Tags
 		r.Spec = &ruleset
 	case "Prompt":
 		var prompt format.Prompt
-		if err := raw.Spec.Decode(&prompt); err != nil {
+		if err := raw.Spec.Decode(&prompt.Spec); err != nil {
 			return fmt.Errorf("failed to decode Prompt spec: %w", err)
 		}
 		// Copy metadata from top level
 		prompt.Metadata.ID = raw.Metadata.ID
+		prompt.Metadata.Namespace = raw.Metadata.Namespace
 		prompt.Metadata.Name = raw.Metadata.Name
 		prompt.Metadata.Description = raw.Metadata.Description
+		prompt.Metadata.Tags = raw.Metadata.Tags
 		r.Spec = &prompt
 	case "Promptset":
 		var promptset format.Promptset
-		if err := raw.Spec.Decode(&promptset); err != nil {
+		if err := raw.Spec.Decode(&promptset.Spec); err != nil {
 			return fmt.Errorf("failed to decode Promptset spec: %w", err)
 		}
 		// Copy metadata from top level
 		promptset.Metadata.ID = raw.Metadata.ID
+		promptset.Metadata.Namespace = raw.Metadata.Namespace
 		promptset.Metadata.Name = raw.Metadata.Name
 		promptset.Metadata.Description = raw.Metadata.Description
+		promptset.Metadata.Tags = raw.Metadata.Tags
 		r.Spec = &promptset
+	case "Command":
+		var command format.Command
+		if err := raw.Spec.Decode(&command.Spec); err != nil {
+			return fmt.Errorf("failed to decode Command spec: %w", err)
+		}
+		// Copy metadata from top level
+		command.Metadata.ID = raw.Metadata.ID
+		command.Metadata.Namespace = raw.Metadata.Namespace
+		command.Metadata.Name = raw.Metadata.Name
+		command.Metadata.Description = raw.Metadata.Description
+		command.Metadata.Tags = raw.Metadata.Tags
+		r.Spec = &command
+	case "Context":
+		var context format.Context
+		if err := raw.Spec.Decode(&context.Spec); err != nil {
+			return fmt.Errorf("failed to decode Context spec: %w", err)
+		}
+		// Copy metadata from top level
+		context.Metadata.ID = raw.Metadata.ID
+		context.Metadata.Namespace = raw.Metadata.Namespace
+		context.Metadata.Name = raw.Metadata.Name
+		context.Metadata.Description = raw.Metadata.Description
+		context.Metadata.Tags = raw.Metadata.Tags
+		r.Spec = &context
 	default:
-		return fmt.Errorf("unsupported kind: %s", raw.Kind)
+		return fmt.Errorf("%w: %s (%s)", ErrUnsupportedKind, raw.Kind, suggest.Hint(raw.Kind, "kinds", kinds))
 	}
 
 	return nil
 }
 
+// MarshalYAML implements custom YAML marshaling for Resource.
+// It emits the same apiVersion/kind/metadata/spec shape read by UnmarshalYAML.
+func (r Resource) MarshalYAML() (interface{}, error) {
+	type rawResource struct {
+		APIVersion string          `yaml:"apiVersion"`
+		Kind       string          `yaml:"kind"`
+		Metadata   format.Metadata `yaml:"metadata"`
+		Spec       interface{}     `yaml:"spec"`
+	}
+
+	raw := rawResource{APIVersion: r.APIVersion, Kind: r.Kind}
+
+	switch spec := r.Spec.(type) {
+	case *format.Rule:
+		raw.Metadata = spec.Metadata
+		raw.Spec = spec.Spec
+	case *format.Ruleset:
+		raw.Metadata = spec.Metadata
+		raw.Spec = spec.Spec
+	case *format.Prompt:
+		raw.Metadata = spec.Metadata
+		raw.Spec = spec.Spec
+	case *format.Promptset:
+		raw.Metadata = spec.Metadata
+		raw.Spec = spec.Spec
+	case *format.Command:
+		raw.Metadata = spec.Metadata
+		raw.Spec = spec.Spec
+	case *format.Context:
+		raw.Metadata = spec.Metadata
+		raw.Spec = spec.Spec
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedKind, r.Kind)
+	}
+
+	if raw.Metadata.ID == "" {
+		raw.Metadata.ID = r.Metadata.ID
+	}
+	if raw.Metadata.Namespace == "" {
+		raw.Metadata.Namespace = r.Metadata.Namespace
+	}
+
+	return raw, nil
+}
+
 // TargetCompiler transforms resources into target-specific formats.
 type TargetCompiler interface {
 	// Name returns the target identifier (matches Target enum value).
@@ -105,3 +225,93 @@ type TargetCompiler interface {
 	// Returns one result per rule/prompt.
 	Compile(resource *Resource) ([]CompilationResult, error)
 }
+
+// ConfigurableTarget is implemented by target compilers that accept
+// target-specific options (see CompileOptions.TargetOptions).
+type ConfigurableTarget interface {
+	TargetCompiler
+
+	// Configure returns a compiler that applies the given options. It must not
+	// modify the receiver and should reject unknown option names.
+	Configure(options map[string]any) (TargetCompiler, error)
+}
+
+// MergingTarget is implemented by target compilers that combine rules into
+// shared documents, such as a single GEMINI.md, rather than writing a file
+// per rule. Their Compile returns one result per rule or prompt as usual,
+// several of which may share a path; Merge combines them into the final
+// files. The Compiler merges the results of each Compile call, and callers
+// compiling several resources for such a target merge all of their results
+// again with Compiler.Merge.
+type MergingTarget interface {
+	TargetCompiler
+
+	// Merge combines results that share a path, in the order the paths first
+	// appear. Merging already merged results with more results must give the
+	// same files as merging everything at once.
+	Merge(results []CompilationResult) ([]CompilationResult, error)
+}
+
+// BannerTarget is implemented by target compilers that can mark a file as
+// generated, in a form that suits the file: an HTML comment in Markdown, a
+// frontmatter key in .mdc, a comment in TOML. Banners are added to final
+// files, after merging, so they never need merging themselves.
+type BannerTarget interface {
+	TargetCompiler
+
+	// AddBanner returns content, the file at path, with banner, one line of
+	// plain text, added where the tool reading the file ignores it.
+	AddBanner(path, content, banner string) string
+}
+
+// NativeLayoutTarget is implemented by target compilers whose tool reads its
+// files from a conventional directory of the project, such as .cursor/rules,
+// so builds can install results there without being told where.
+type NativeLayoutTarget interface {
+	TargetCompiler
+
+	// DefaultOutputDir returns that directory, relative to the project root
+	// and slash-separated: "." for the root itself.
+	DefaultOutputDir() string
+}
+
+// ProjectRootTarget is implemented by native layout targets some of whose
+// files their tool reads from the project root rather than DefaultOutputDir,
+// such as CLAUDE.md, so builds can install those there.
+type ProjectRootTarget interface {
+	NativeLayoutTarget
+
+	// ProjectRoot returns those of results, relative to DefaultOutputDir,
+	// apart from the rest. The paths of root, which keep their names, are
+	// relative to the project root, and any reference in them to the rest
+	// points into DefaultOutputDir.
+	ProjectRoot(results []CompilationResult) (root, rest []CompilationResult)
+}
+
+// AggregateTargetCompiler is implemented by target compilers that compile
+// a set of resources together, so they can produce combined artifacts such as
+// index files, single-file outputs, or links between resources.
+// Compiler.CompileAll passes them every resource at once instead of calling
+// Compile for each; Compiler.Compile still calls Compile.
+type AggregateTargetCompiler interface {
+	TargetCompiler
+
+	// CompileAll compiles resources, each validated and prepared as for
+	// Compile, into files. Unlike Compile results, the paths are not
+	// prefixed with the resources' namespaces.
+	CompileAll(resources []*Resource) ([]CompilationResult, error)
+}
+
+// ContextTarget is implemented by target compilers whose work is worth
+// cancelling, such as those that call a network service. Compiler.Compile
+// and its variants call CompileContext instead of Compile, with the context
+// given to CompileContext, CompileToContext, or CompileAllContext, or
+// context.Background for the variants without one. The built-in targets
+// only transform text, so the compiler checks the context between them.
+type ContextTarget interface {
+	TargetCompiler
+
+	// CompileContext compiles resource as Compile does, returning early with
+	// ctx's error once ctx is done.
+	CompileContext(ctx context.Context, resource *Resource) ([]CompilationResult, error)
+}
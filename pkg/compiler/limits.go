@@ -0,0 +1,125 @@
+package compiler
+
+import (
+	"fmt"
+
+	"github.com/jomadu/ai-resource-compiler-go/internal/format"
+)
+
+// Limits bounds the size of the resources arc accepts and the output it
+// produces, so untrusted or generated resources cannot exhaust memory. The
+// loader enforces the input limits and the Compiler the output limits. A zero
+// field uses the value from DefaultLimits; a negative field disables that
+// limit.
+type Limits struct {
+	// MaxFileSize is the largest resource file or fragment library, in bytes.
+	MaxFileSize int
+
+	// MaxDepth is the deepest nesting of YAML mappings and sequences.
+	MaxDepth int
+
+	// MaxExpandedNodes is the most YAML nodes a document may expand to once
+	// aliases are resolved.
+	MaxExpandedNodes int
+
+	// MaxBodySize is the largest rule or prompt body once fragments and
+	// variables are expanded, in bytes.
+	MaxBodySize int
+
+	// MaxOutputSize is the most content a single Compile or CompileAll call
+	// may produce across all targets, in bytes.
+	MaxOutputSize int
+}
+
+// DefaultLimits are generous for hand-written resources.
+var DefaultLimits = Limits{
+	MaxFileSize:      4 << 20,
+	MaxDepth:         64,
+	MaxExpandedNodes: 100000,
+	MaxBodySize:      1 << 20,
+	MaxOutputSize:    32 << 20,
+}
+
+// WithDefaults returns l with its zero fields set from DefaultLimits.
+func (l Limits) WithDefaults() Limits {
+	fill := func(v *int, def int) {
+		if *v == 0 {
+			*v = def
+		}
+	}
+	fill(&l.MaxFileSize, DefaultLimits.MaxFileSize)
+	fill(&l.MaxDepth, DefaultLimits.MaxDepth)
+	fill(&l.MaxExpandedNodes, DefaultLimits.MaxExpandedNodes)
+	fill(&l.MaxBodySize, DefaultLimits.MaxBodySize)
+	fill(&l.MaxOutputSize, DefaultLimits.MaxOutputSize)
+	return l
+}
+
+// Exceeds reports whether value is over limit. Negative limits are disabled.
+func Exceeds(value, limit int) bool {
+	return limit >= 0 && value > limit
+}
+
+// checkBodySizes returns an error if any rule or prompt body of resource
+// expands beyond limit.
+func checkBodySizes(resource *Resource, limit int) error {
+	check := func(item string, body format.Body, fragments map[string]string) error {
+		if size := bodySize(body, fragments, limit); Exceeds(size, limit) {
+			return fmt.Errorf("%w: body of %s expands to more than %d bytes", ErrLimitExceeded, item, limit)
+		}
+		return nil
+	}
+
+	id := resource.Metadata.ID
+	switch spec := resource.Spec.(type) {
+	case *format.Rule:
+		return check(id, spec.Spec.Body, spec.Spec.Fragments)
+	case *format.Ruleset:
+		for _, ruleID := range spec.Spec.RuleIDs() {
+			if err := check(id+"/"+ruleID, spec.Spec.Rules[ruleID].Body, spec.Spec.Fragments); err != nil {
+				return err
+			}
+		}
+	case *format.Prompt:
+		return check(id, spec.Spec.Body, spec.Spec.Fragments)
+	case *format.Promptset:
+		for _, promptID := range spec.Spec.PromptIDs() {
+			if err := check(id+"/"+promptID, spec.Spec.Prompts[promptID].Body, spec.Spec.Fragments); err != nil {
+				return err
+			}
+		}
+	case *format.Command:
+		return check(id, spec.Spec.Body, spec.Spec.Fragments)
+	case *format.Context:
+		return check(id, spec.Spec.Body, spec.Spec.Fragments)
+	}
+	return nil
+}
+
+// bodySize returns the length of body as format.ResolveBody would resolve
+// it, without building the string. It stops counting once limit is passed.
+func bodySize(body format.Body, fragments map[string]string, limit int) int {
+	if body.String != nil {
+		return len(*body.String)
+	}
+	size, parts := 0, 0
+	for _, ref := range body.Array {
+		part, isRef := ref, len(ref) > 0 && ref[0] == '$'
+		if isRef {
+			fragment, ok := fragments[ref[1:]]
+			if !ok {
+				continue
+			}
+			part = fragment
+		}
+		if parts > 0 {
+			size += len("\n\n")
+		}
+		size += len(part)
+		parts++
+		if Exceeds(size, limit) {
+			break
+		}
+	}
+	return size
+}
@@ -0,0 +1,53 @@
+package compiler
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/jomadu/ai-resource-compiler-go/internal/format"
+)
+
+func TestCompileBodySizeLimit(t *testing.T) {
+	// Each reference expands the fragment again, so a short body can
+	// expand far beyond its written size.
+	resource := testRule("")
+	rule := resource.Spec.(*format.Rule)
+	rule.Spec.Body = format.Body{Array: []string{"$big", "$big", "$big", "$big"}}
+	rule.Spec.Fragments = map[string]string{"big": strings.Repeat("x", 100)}
+
+	c := NewCompiler(WithTargets(&mockMarkdownCompiler{}), WithLimits(Limits{MaxBodySize: 300}))
+	_, err := c.Compile(resource, CompileOptions{Targets: []Target{TargetMarkdown}})
+	if !errors.Is(err, ErrLimitExceeded) || !strings.Contains(err.Error(), "body of testRule") {
+		t.Errorf("Compile() error = %v, want body size limit error", err)
+	}
+
+	c = NewCompiler(WithTargets(&mockMarkdownCompiler{}), WithLimits(Limits{MaxBodySize: 500}))
+	if _, err := c.Compile(resource, CompileOptions{Targets: []Target{TargetMarkdown}}); err != nil {
+		t.Errorf("Compile() error = %v, want body within limit", err)
+	}
+}
+
+func TestCompileOutputSizeLimit(t *testing.T) {
+	resource := testRule("body")
+
+	// The mock target writes 12 bytes of content.
+	c := NewCompiler(WithTargets(&mockMarkdownCompiler{}), WithLimits(Limits{MaxOutputSize: 10}))
+	_, err := c.Compile(resource, CompileOptions{Targets: []Target{TargetMarkdown}})
+	if !errors.Is(err, ErrLimitExceeded) {
+		t.Errorf("Compile() error = %v, want output size limit error", err)
+	}
+
+	c = NewCompiler(WithTargets(&mockMarkdownCompiler{}), WithLimits(Limits{MaxOutputSize: -1}))
+	if _, err := c.Compile(resource, CompileOptions{Targets: []Target{TargetMarkdown}}); err != nil {
+		t.Errorf("Compile() error = %v, want disabled limit", err)
+	}
+}
+
+func TestBodySize(t *testing.T) {
+	fragments := map[string]string{"a": "aaa", "b": "bb"}
+	body := format.Body{Array: []string{"$a", "text", "$missing", "$b"}}
+	if got, want := bodySize(body, fragments, -1), len(format.ResolveBody(body, fragments)); got != want {
+		t.Errorf("bodySize() = %d, want %d", got, want)
+	}
+}
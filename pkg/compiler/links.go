@@ -0,0 +1,220 @@
+package compiler
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/jomadu/ai-resource-compiler-go/internal/format"
+)
+
+// ErrUnresolvedLink is returned by Compile for a link to a rule that is
+// neither in the resource compiled nor in CompileOptions.LinkResources, or
+// whose ID names rules of more than one ruleset.
+var ErrUnresolvedLink = errors.New("unresolved rule link")
+
+// ruleLink matches a Markdown link to a rule, [text](#rule:ref), ref being a
+// rule's ID, or a ruleset's ID and one of its rules' IDs joined by a slash.
+var ruleLink = regexp.MustCompile(`\[([^\]]*)\]\(#rule:([A-Za-z0-9_-]+(?:/[A-Za-z0-9_-]+)?)\)`)
+
+// LinkedRules returns the references of the rules resource links to with
+// [text](#rule:ref) in its bodies and fragments, in the order they first
+// appear.
+func LinkedRules(resource *Resource) []string {
+	var refs []string
+	seen := make(map[string]bool)
+	for _, text := range resourceTexts(resource) {
+		for _, m := range ruleLink.FindAllStringSubmatch(text, -1) {
+			if !seen[m[2]] {
+				seen[m[2]] = true
+				refs = append(refs, m[2])
+			}
+		}
+	}
+	return refs
+}
+
+// resourceTexts returns every body, translation, and fragment of resource.
+func resourceTexts(resource *Resource) []string {
+	var texts []string
+	addBody := func(body format.Body) {
+		if body.String != nil {
+			texts = append(texts, *body.String)
+		}
+		texts = append(texts, body.Array...)
+	}
+	add := func(body format.Body, bodies map[string]format.Body) {
+		addBody(body)
+		for _, locale := range format.SortedKeys(bodies) {
+			addBody(bodies[locale])
+		}
+	}
+	addFragments := func(fragments map[string]string) {
+		for _, name := range format.SortedKeys(fragments) {
+			texts = append(texts, fragments[name])
+		}
+	}
+
+	switch spec := resource.Spec.(type) {
+	case *format.Rule:
+		add(spec.Spec.Body, spec.Spec.Bodies)
+		addFragments(spec.Spec.Fragments)
+	case *format.Ruleset:
+		for _, id := range spec.Spec.RuleIDs() {
+			add(spec.Spec.Rules[id].Body, spec.Spec.Rules[id].Bodies)
+		}
+		addFragments(spec.Spec.Fragments)
+	case *format.Prompt:
+		add(spec.Spec.Body, spec.Spec.Bodies)
+		addFragments(spec.Spec.Fragments)
+	case *format.Promptset:
+		for _, id := range spec.Spec.PromptIDs() {
+			add(spec.Spec.Prompts[id].Body, spec.Spec.Prompts[id].Bodies)
+		}
+		addFragments(spec.Spec.Fragments)
+	case *format.Command:
+		add(spec.Spec.Body, spec.Spec.Bodies)
+		addFragments(spec.Spec.Fragments)
+	case *format.Context:
+		add(spec.Spec.Body, spec.Spec.Bodies)
+		addFragments(spec.Spec.Fragments)
+	}
+	return texts
+}
+
+// ruleIndex resolves rule references to the rules of a set of resources,
+// each named by its ID or, in a ruleset, its ruleset's ID, a slash, and its
+// own ID, as Explanation.Item names it.
+type ruleIndex struct {
+	refs  map[string]bool
+	short map[string][]string // rule ID of a ruleset's rule to its full references
+}
+
+func newRuleIndex(resources []*Resource) *ruleIndex {
+	x := &ruleIndex{refs: make(map[string]bool), short: make(map[string][]string)}
+	for _, resource := range resources {
+		switch spec := resource.Spec.(type) {
+		case *format.Rule:
+			x.refs[resource.Metadata.ID] = true
+		case *format.Ruleset:
+			for _, id := range spec.Spec.RuleIDs() {
+				ref := resource.Metadata.ID + "/" + id
+				if !x.refs[ref] {
+					x.refs[ref] = true
+					x.short[id] = append(x.short[id], ref)
+				}
+			}
+		}
+	}
+	return x
+}
+
+// resolve returns the full reference of the rule ref names. A bare ID names
+// a Rule of that ID or else the only ruleset rule of that ID.
+func (x *ruleIndex) resolve(ref string) (string, error) {
+	if x.refs[ref] {
+		return ref, nil
+	}
+	switch matches := x.short[ref]; {
+	case len(matches) == 1:
+		return matches[0], nil
+	case len(matches) > 1:
+		return "", fmt.Errorf("%w: %s names rules of several rulesets (%s); link to one of them", ErrUnresolvedLink, ref, strings.Join(matches, ", "))
+	}
+	return "", fmt.Errorf("%w: no rule %s", ErrUnresolvedLink, ref)
+}
+
+// rewriteLinks returns results, compiled for target from resources, with
+// each link to a rule pointing to the file target compiled the rule to,
+// relative to the linking file. The rules are those of resources and of
+// opts.LinkResources. Links to rules target has no file for, as targets not
+// implementing ExplainingTarget have none, or compiles into the linking
+// file itself, as merging targets do, are replaced by their text.
+func (c *Compiler) rewriteLinks(target Target, resources []*Resource, results []CompilationResult, opts CompileOptions) ([]CompilationResult, error) {
+	linking := false
+	for _, result := range results {
+		if !result.Asset && strings.Contains(result.Content, "](#rule:") {
+			linking = true
+			break
+		}
+	}
+	if !linking {
+		return results, nil
+	}
+
+	all := append(append([]*Resource(nil), resources...), opts.LinkResources...)
+	index := newRuleIndex(all)
+	paths, err := c.rulePaths(target, all, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]CompilationResult, len(results))
+	for i, result := range results {
+		out[i] = result
+		if result.Asset {
+			continue
+		}
+		var linkErr error
+		out[i].Content = ruleLink.ReplaceAllStringFunc(result.Content, func(link string) string {
+			m := ruleLink.FindStringSubmatch(link)
+			ref, err := index.resolve(m[2])
+			if err != nil {
+				if linkErr == nil {
+					linkErr = fmt.Errorf("%s: %w", result.Path, err)
+				}
+				return link
+			}
+			to, ok := paths[ref]
+			if !ok || to == result.Path {
+				return m[1]
+			}
+			rel, err := filepath.Rel(filepath.FromSlash(path.Dir(result.Path)), filepath.FromSlash(to))
+			if err != nil {
+				return m[1]
+			}
+			return "[" + m[1] + "](" + filepath.ToSlash(rel) + ")"
+		})
+		if linkErr != nil {
+			return nil, linkErr
+		}
+	}
+	return out, nil
+}
+
+// rulePaths returns the paths target compiles the rules of resources to, by
+// full reference, as Explain names them.
+func (c *Compiler) rulePaths(target Target, resources []*Resource, opts CompileOptions) (map[string]string, error) {
+	paths := make(map[string]string)
+	for _, resource := range resources {
+		if resource.Kind != "Rule" && resource.Kind != "Ruleset" {
+			continue
+		}
+		compiler, _, err := c.configuredTarget(target, resource, opts)
+		if err != nil {
+			return nil, err
+		}
+		explainer, ok := compiler.(ExplainingTarget)
+		if !ok {
+			return paths, nil
+		}
+		explanations, err := explainer.Explain(resource)
+		if err != nil {
+			return nil, fmt.Errorf("locating the rules of %s: %w", resource.Metadata.ID, err)
+		}
+		for _, e := range explanations {
+			if e.Path == "(none)" {
+				continue
+			}
+			p, err := renamePath(format.BuildNamespacedPath(resource.Metadata.Namespace, e.Path), target, opts.Prefix, opts.PathTemplate)
+			if err != nil {
+				return nil, err
+			}
+			paths[e.Item] = p
+		}
+	}
+	return paths, nil
+}
@@ -0,0 +1,135 @@
+package compiler
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/jomadu/ai-resource-compiler-go/internal/format"
+)
+
+// linkingCompiler compiles each rule to its body, under rules/ for a
+// Ruleset's rules, and explains where each rule goes.
+type linkingCompiler struct {
+	mockMarkdownCompiler
+}
+
+func (m *linkingCompiler) paths(resource *Resource) map[string]string {
+	paths := make(map[string]string)
+	switch spec := resource.Spec.(type) {
+	case *format.Rule:
+		paths[resource.Metadata.ID] = resource.Metadata.ID + ".md"
+	case *format.Ruleset:
+		for id := range spec.Spec.Rules {
+			paths[resource.Metadata.ID+"/"+id] = "rules/" + resource.Metadata.ID + "_" + id + ".md"
+		}
+	}
+	return paths
+}
+
+func (m *linkingCompiler) Compile(resource *Resource) ([]CompilationResult, error) {
+	var results []CompilationResult
+	for _, ref := range format.SortedKeys(m.paths(resource)) {
+		var body format.Body
+		switch spec := resource.Spec.(type) {
+		case *format.Rule:
+			body = spec.Spec.Body
+		case *format.Ruleset:
+			body = spec.Spec.Rules[ref[len(resource.Metadata.ID)+1:]].Body
+		}
+		results = append(results, CompilationResult{Path: m.paths(resource)[ref], Content: *body.String})
+	}
+	return results, nil
+}
+
+func (m *linkingCompiler) Explain(resource *Resource) ([]Explanation, error) {
+	var explanations []Explanation
+	for ref, path := range m.paths(resource) {
+		explanations = append(explanations, Explanation{Item: ref, Path: path})
+	}
+	return explanations, nil
+}
+
+func linkingRuleset(id string, bodies map[string]string) *Resource {
+	rules := make(map[string]format.RuleItem)
+	for ruleID, body := range bodies {
+		rules[ruleID] = format.RuleItem{Name: ruleID, Enforcement: "must", Body: format.Body{String: strPtr(body)}}
+	}
+	resource := &Resource{APIVersion: "ai-resource/draft", Kind: "Ruleset", Spec: &format.Ruleset{
+		Metadata: format.Metadata{ID: id},
+		Spec:     format.RulesetSpec{Rules: rules},
+	}}
+	resource.Metadata.ID = id
+	return resource
+}
+
+func TestLinkedRules(t *testing.T) {
+	resource := linkingRuleset("style", map[string]string{
+		"a": "See [naming](#rule:naming) and [errors](#rule:go/errors).",
+		"b": "Also [naming](#rule:naming), not [a heading](#naming).",
+	})
+	if got, want := LinkedRules(resource), []string{"naming", "go/errors"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("LinkedRules() = %v, want %v", got, want)
+	}
+}
+
+func TestCompile_RuleLinks(t *testing.T) {
+	c := NewCompiler(WithoutDefaults(), WithTarget(TargetMarkdown, &linkingCompiler{}))
+	opts := CompileOptions{Targets: []Target{TargetMarkdown}}
+	style := linkingRuleset("style", map[string]string{
+		"naming":  "Name things well.",
+		"testing": "See [naming](#rule:naming) and [errors](#rule:errors).",
+	})
+	errorsRule := testRule("Wrap errors; see [testing](#rule:style/testing).")
+	errorsRule.Metadata.ID = "errors"
+	errorsRule.Spec.(*format.Rule).Metadata.ID = "errors"
+
+	_, err := c.Compile(style, opts)
+	if !errors.Is(err, ErrUnresolvedLink) || !strings.Contains(err.Error(), "no rule errors") {
+		t.Fatalf("Compile() error = %v, want an unresolved link to errors", err)
+	}
+
+	opts.LinkResources = []*Resource{errorsRule}
+	results, err := c.Compile(style, opts)
+	if err != nil {
+		t.Fatalf("Compile() error = %v", err)
+	}
+	want := "See [naming](style_naming.md) and [errors](../errors.md)."
+	if results[1].Path != "rules/style_testing.md" || results[1].Content != want {
+		t.Errorf("results[1] = %+v, want content %q", results[1], want)
+	}
+
+	results, err = c.CompileAll([]*Resource{style, errorsRule}, CompileOptions{Targets: []Target{TargetMarkdown}, Prefix: "org-"})
+	if err != nil {
+		t.Fatalf("CompileAll() error = %v", err)
+	}
+	var got []string
+	for _, result := range results {
+		got = append(got, result.Path+": "+result.Content)
+	}
+	wantAll := []string{
+		"rules/org-style_naming.md: Name things well.",
+		"rules/org-style_testing.md: See [naming](org-style_naming.md) and [errors](../org-errors.md).",
+		"org-errors.md: Wrap errors; see [testing](rules/org-style_testing.md).",
+	}
+	if !reflect.DeepEqual(got, wantAll) {
+		t.Errorf("CompileAll() =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(wantAll, "\n"))
+	}
+
+	other := linkingRuleset("other", map[string]string{"naming": "Name them differently."})
+	_, err = c.CompileAll([]*Resource{style, errorsRule, other}, CompileOptions{Targets: []Target{TargetMarkdown}})
+	if !errors.Is(err, ErrUnresolvedLink) || !strings.Contains(err.Error(), "style/naming, other/naming") {
+		t.Errorf("CompileAll() error = %v, want an ambiguous link", err)
+	}
+
+	// Targets that cannot say where rules go keep only the link text.
+	c = NewCompiler(WithoutDefaults(), WithTarget(TargetMarkdown, &bodyCompiler{}))
+	results, err = c.Compile(style, opts)
+	if err != nil {
+		t.Fatalf("Compile() error = %v", err)
+	}
+	if want := "See naming and errors."; results[1].Content != want {
+		t.Errorf("content = %q, want %q", results[1].Content, want)
+	}
+}
@@ -0,0 +1,74 @@
+package compiler
+
+import "github.com/jomadu/ai-resource-compiler-go/internal/format"
+
+// localize returns a copy of resource whose bodies are the variants for
+// locale, falling back to the default body where a rule or prompt has no
+// translation. Translations are dropped from the copy either way, so targets
+// only see the body that compiles. The original resource is not modified.
+func localize(resource *Resource, locale string) *Resource {
+	out := *resource
+	switch spec := resource.Spec.(type) {
+	case *format.Rule:
+		if spec.Spec.Bodies == nil {
+			return resource
+		}
+		rule := *spec
+		rule.Spec.Body = localBody(spec.Spec.Body, spec.Spec.Bodies, locale)
+		rule.Spec.Bodies = nil
+		out.Spec = &rule
+	case *format.Ruleset:
+		ruleset := *spec
+		ruleset.Spec.Rules = make(map[string]format.RuleItem, len(spec.Spec.Rules))
+		for id, item := range spec.Spec.Rules {
+			item.Body = localBody(item.Body, item.Bodies, locale)
+			item.Bodies = nil
+			ruleset.Spec.Rules[id] = item
+		}
+		out.Spec = &ruleset
+	case *format.Prompt:
+		if spec.Spec.Bodies == nil {
+			return resource
+		}
+		prompt := *spec
+		prompt.Spec.Body = localBody(spec.Spec.Body, spec.Spec.Bodies, locale)
+		prompt.Spec.Bodies = nil
+		out.Spec = &prompt
+	case *format.Promptset:
+		promptset := *spec
+		promptset.Spec.Prompts = make(map[string]format.PromptItem, len(spec.Spec.Prompts))
+		for id, item := range spec.Spec.Prompts {
+			item.Body = localBody(item.Body, item.Bodies, locale)
+			item.Bodies = nil
+			promptset.Spec.Prompts[id] = item
+		}
+		out.Spec = &promptset
+	case *format.Command:
+		if spec.Spec.Bodies == nil {
+			return resource
+		}
+		command := *spec
+		command.Spec.Body = localBody(spec.Spec.Body, spec.Spec.Bodies, locale)
+		command.Spec.Bodies = nil
+		out.Spec = &command
+	case *format.Context:
+		if spec.Spec.Bodies == nil {
+			return resource
+		}
+		context := *spec
+		context.Spec.Body = localBody(spec.Spec.Body, spec.Spec.Bodies, locale)
+		context.Spec.Bodies = nil
+		out.Spec = &context
+	default:
+		return resource
+	}
+	return &out
+}
+
+// localBody returns the body for locale, or body if there is none.
+func localBody(body format.Body, bodies map[string]format.Body, locale string) format.Body {
+	if variant, ok := bodies[locale]; ok && locale != "" {
+		return variant
+	}
+	return body
+}
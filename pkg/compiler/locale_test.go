@@ -0,0 +1,61 @@
+package compiler
+
+import (
+	"testing"
+
+	"github.com/jomadu/ai-resource-compiler-go/internal/format"
+)
+
+func TestLocalize(t *testing.T) {
+	str := func(s string) format.Body { return format.Body{String: &s} }
+	resource := &Resource{
+		APIVersion: "ai-resource/draft",
+		Kind:       "Ruleset",
+		Spec: &format.Ruleset{Spec: format.RulesetSpec{Rules: map[string]format.RuleItem{
+			"translated":   {Body: str("Use names."), Bodies: map[string]format.Body{"es": str("Usa nombres.")}},
+			"untranslated": {Body: str("Write tests.")},
+		}}},
+	}
+
+	tests := []struct {
+		locale string
+		want   map[string]string
+	}{
+		{locale: "", want: map[string]string{"translated": "Use names.", "untranslated": "Write tests."}},
+		{locale: "es", want: map[string]string{"translated": "Usa nombres.", "untranslated": "Write tests."}},
+		{locale: "ja", want: map[string]string{"translated": "Use names.", "untranslated": "Write tests."}},
+	}
+	for _, tt := range tests {
+		rules := localize(resource, tt.locale).Spec.(*format.Ruleset).Spec.Rules
+		for id, want := range tt.want {
+			if got := *rules[id].Body.String; got != want {
+				t.Errorf("localize(%q) body of %s = %q, want %q", tt.locale, id, got, want)
+			}
+			if rules[id].Bodies != nil {
+				t.Errorf("localize(%q) kept the bodies of %s", tt.locale, id)
+			}
+		}
+	}
+
+	if *resource.Spec.(*format.Ruleset).Spec.Rules["translated"].Body.String != "Use names." {
+		t.Error("localize() modified the input resource")
+	}
+}
+
+func TestLocalize_Rule(t *testing.T) {
+	body, es := "Use names.", "Usa nombres."
+	resource := &Resource{Kind: "Rule", Spec: &format.Rule{Spec: format.RuleSpec{
+		Body:   format.Body{String: &body},
+		Bodies: map[string]format.Body{"es": {Array: []string{es}}},
+	}}}
+
+	rule := localize(resource, "es").Spec.(*format.Rule)
+	if len(rule.Spec.Body.Array) != 1 || rule.Spec.Body.Array[0] != es {
+		t.Errorf("localize() body = %+v, want %q", rule.Spec.Body, es)
+	}
+
+	plain := &Resource{Kind: "Rule", Spec: &format.Rule{Spec: format.RuleSpec{Body: format.Body{String: &body}}}}
+	if got := localize(plain, "es"); got != plain {
+		t.Error("localize() copied a rule without translations")
+	}
+}
@@ -0,0 +1,65 @@
+package compiler
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/jomadu/ai-resource-compiler-go/internal/format"
+)
+
+// mockMergingCompiler writes every rule to shared.md and merges by
+// concatenation.
+type mockMergingCompiler struct{ mockMarkdownCompiler }
+
+func (m *mockMergingCompiler) Name() string { return "merging" }
+
+func (m *mockMergingCompiler) Compile(resource *Resource) ([]CompilationResult, error) {
+	var results []CompilationResult
+	for _, id := range format.SortedKeys(resource.Spec.(*format.Ruleset).Spec.Rules) {
+		results = append(results, CompilationResult{Path: "shared.md", Content: id})
+	}
+	return results, nil
+}
+
+func (m *mockMergingCompiler) Merge(results []CompilationResult) ([]CompilationResult, error) {
+	if len(results) == 0 {
+		return nil, nil
+	}
+	merged := CompilationResult{Path: results[0].Path}
+	for _, result := range results {
+		merged.Content += result.Content
+	}
+	return []CompilationResult{merged}, nil
+}
+
+func TestCompileMergingTarget(t *testing.T) {
+	c := NewCompiler(WithTargets(&mockMergingCompiler{}))
+	resource := &Resource{
+		APIVersion: "ai-resource/draft",
+		Kind:       "Ruleset",
+		Spec: &format.Ruleset{Spec: format.RulesetSpec{Rules: map[string]format.RuleItem{
+			"a": {}, "b": {},
+		}}},
+	}
+	resource.Metadata.ID = "rules"
+
+	results, err := c.Compile(resource, CompileOptions{Targets: []Target{"merging"}})
+	if err != nil {
+		t.Fatalf("Compile() error = %v", err)
+	}
+	if want := []CompilationResult{{Path: "shared.md", Content: "ab", Stats: ResultStats{Bytes: 2, Tokens: 1}}}; !reflect.DeepEqual(results, want) {
+		t.Errorf("Compile() = %+v, want %+v", results, want)
+	}
+
+	if !c.Merges("merging") || c.Merges(TargetMarkdown) {
+		t.Error("Merges() does not match the targets implementing MergingTarget")
+	}
+	merged, err := c.Merge("merging", append(results, CompilationResult{Path: "shared.md", Content: "c"}))
+	if err != nil || len(merged) != 1 || merged[0].Content != "abc" {
+		t.Errorf("Merge() = %+v, %v, want one file with abc", merged, err)
+	}
+	plain := []CompilationResult{{Path: "x.md"}, {Path: "x.md"}}
+	if got, _ := c.Merge(TargetMarkdown, plain); !reflect.DeepEqual(got, plain) {
+		t.Errorf("Merge() changed results of a target without merging: %+v", got)
+	}
+}
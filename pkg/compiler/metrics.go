@@ -0,0 +1,81 @@
+package compiler
+
+import (
+	"encoding/json"
+	"sync"
+	"time"
+)
+
+// Metrics receives measurements from a Compiler, so services embedding it
+// can export them to Prometheus, OpenTelemetry, or a similar system.
+// Implementations must be safe for concurrent use.
+type Metrics interface {
+	// CompileDone is called once per Compile call with the resource kind and
+	// the error Compile returns, if any.
+	CompileDone(kind string, err error)
+
+	// TargetDone is called each time a target compiles a resource, with the
+	// time it took, including cache lookups, and its error, if any.
+	TargetDone(target Target, duration time.Duration, err error)
+}
+
+type noMetrics struct{}
+
+func (noMetrics) CompileDone(string, error)               {}
+func (noMetrics) TargetDone(Target, time.Duration, error) {}
+
+// Stats is a Metrics that keeps running totals in memory. Its JSON form is
+// what arc -stats-file writes.
+type Stats struct {
+	mu sync.Mutex
+
+	Compiles int                     `json:"compiles"`
+	Errors   int                     `json:"errors"`
+	Targets  map[Target]*TargetStats `json:"targets"`
+}
+
+// TargetStats are the totals for one target.
+type TargetStats struct {
+	Compiles int           `json:"compiles"`
+	Errors   int           `json:"errors"`
+	Duration time.Duration `json:"durationNanos"`
+}
+
+// NewStats returns empty Stats.
+func NewStats() *Stats {
+	return &Stats{Targets: make(map[Target]*TargetStats)}
+}
+
+// CompileDone counts a Compile call and whether it failed.
+func (s *Stats) CompileDone(kind string, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.Compiles++
+	if err != nil {
+		s.Errors++
+	}
+}
+
+// TargetDone adds a target compilation to the target's totals.
+func (s *Stats) TargetDone(target Target, duration time.Duration, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ts, ok := s.Targets[target]
+	if !ok {
+		ts = &TargetStats{}
+		s.Targets[target] = ts
+	}
+	ts.Compiles++
+	ts.Duration += duration
+	if err != nil {
+		ts.Errors++
+	}
+}
+
+// MarshalJSON encodes the totals so far.
+func (s *Stats) MarshalJSON() ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	type stats Stats // without the MarshalJSON method
+	return json.Marshal((*stats)(s))
+}
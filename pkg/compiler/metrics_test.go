@@ -0,0 +1,51 @@
+package compiler
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestStats(t *testing.T) {
+	stats := NewStats()
+	c := NewCompiler(WithoutDefaults(), WithTargets(&mockMarkdownCompiler{}), WithMetrics(stats))
+	resource := testRule("body")
+
+	if _, err := c.Compile(resource, CompileOptions{Targets: []Target{TargetMarkdown}}); err != nil {
+		t.Fatalf("Compile() error = %v", err)
+	}
+	if _, err := c.Compile(resource, CompileOptions{Targets: []Target{TargetMarkdown, TargetCursor}}); err == nil {
+		t.Fatal("Compile() with an unregistered target succeeded")
+	}
+
+	if stats.Compiles != 2 || stats.Errors != 1 {
+		t.Errorf("compiles, errors = %d, %d, want 2, 1", stats.Compiles, stats.Errors)
+	}
+	if got := stats.Targets[TargetMarkdown]; got == nil || got.Compiles != 2 || got.Errors != 0 {
+		t.Errorf("markdown stats = %+v, want 2 compiles without errors", got)
+	}
+	if got := stats.Targets[TargetCursor]; got == nil || got.Compiles != 1 || got.Errors != 1 {
+		t.Errorf("cursor stats = %+v, want 1 failed compile", got)
+	}
+
+	data, err := json.Marshal(stats)
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+	var decoded struct {
+		Compiles int                       `json:"compiles"`
+		Targets  map[string]map[string]any `json:"targets"`
+	}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+	if decoded.Compiles != 2 || decoded.Targets["cursor"]["errors"] != 1.0 {
+		t.Errorf("JSON = %s", data)
+	}
+}
+
+func TestWithMetricsNil(t *testing.T) {
+	c := NewCompiler(WithTargets(&mockMarkdownCompiler{}), WithMetrics(nil))
+	if _, err := c.Compile(testRule("body"), CompileOptions{Targets: []Target{TargetMarkdown}}); err != nil {
+		t.Errorf("Compile() error = %v", err)
+	}
+}
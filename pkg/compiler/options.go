@@ -0,0 +1,118 @@
+package compiler
+
+import "log/slog"
+
+// Option configures a Compiler created by NewCompiler.
+type Option func(*config)
+
+type config struct {
+	targets    map[Target]TargetCompiler
+	noDefaults bool
+	logger     *slog.Logger
+	cacheDir   string
+	limits     Limits
+	metrics    Metrics
+	tokenizer  Tokenizer
+	validators []Validator
+	preHooks   []PreCompileHook
+	postHooks  []PostCompileHook
+}
+
+// WithTarget registers compiler under target, which need not be its Name,
+// replacing any default target of the same name.
+func WithTarget(target Target, compiler TargetCompiler) Option {
+	return func(cfg *config) {
+		cfg.targets[target] = compiler
+	}
+}
+
+// WithTargets registers target compilers under their Name, replacing any
+// default target of the same name.
+func WithTargets(compilers ...TargetCompiler) Option {
+	return func(cfg *config) {
+		for _, tc := range compilers {
+			cfg.targets[Target(tc.Name())] = tc
+		}
+	}
+}
+
+// WithoutDefaults starts from an empty target set instead of the built-in
+// targets registered with RegisterDefaultTarget. Together with WithTargets
+// and targets.Defaults, it gives a compiler that depends on no global state.
+func WithoutDefaults() Option {
+	return func(cfg *config) {
+		cfg.noDefaults = true
+	}
+}
+
+// WithLogger sets the logger that receives debug records for each target
+// compilation and cache lookup. By default nothing is logged.
+func WithLogger(logger *slog.Logger) Option {
+	return func(cfg *config) {
+		cfg.logger = logger
+	}
+}
+
+// WithCache stores compilation results under dir and reuses them when the
+// same resource is compiled again for the same target and options. Delete the
+// directory to clear the cache.
+func WithCache(dir string) Option {
+	return func(cfg *config) {
+		cfg.cacheDir = dir
+	}
+}
+
+// WithLimits bounds the size of expanded bodies and of the output of each
+// Compile call. Unset fields use DefaultLimits.
+func WithLimits(limits Limits) Option {
+	return func(cfg *config) {
+		cfg.limits = limits
+	}
+}
+
+// WithMetrics reports compile counts, per-target durations, and errors to
+// metrics. Use NewStats to collect them in memory.
+func WithMetrics(metrics Metrics) Option {
+	return func(cfg *config) {
+		if metrics == nil {
+			metrics = noMetrics{}
+		}
+		cfg.metrics = metrics
+	}
+}
+
+// WithTokenizer counts the tokens of compiled output with tokenizer instead
+// of EstimateTokens, e.g. with the tokenizer of the model it is for.
+func WithTokenizer(tokenizer Tokenizer) Option {
+	return func(cfg *config) {
+		if tokenizer == nil {
+			tokenizer = TokenizerFunc(EstimateTokens)
+		}
+		cfg.tokenizer = tokenizer
+	}
+}
+
+// WithValidator adds validator to the checks a resource must pass before it
+// compiles, after the built-in ones. Validators run in the order given.
+func WithValidator(validator Validator) Option {
+	return func(cfg *config) {
+		cfg.validators = append(cfg.validators, validator)
+	}
+}
+
+// WithPreCompileHook adds hook to those called with each resource before it
+// compiles. Hooks run in the order given.
+func WithPreCompileHook(hook PreCompileHook) Option {
+	return func(cfg *config) {
+		cfg.preHooks = append(cfg.preHooks, hook)
+	}
+}
+
+// WithPostCompileHook adds hook to those each compiled file passes through
+// before it is returned or written. Hooks run in the order given, each
+// seeing the file the previous one returned.
+func WithPostCompileHook(hook PostCompileHook) Option {
+	return func(cfg *config) {
+		cfg.postHooks = append(cfg.postHooks, hook)
+	}
+}
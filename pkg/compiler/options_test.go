@@ -0,0 +1,214 @@
+package compiler
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/jomadu/ai-resource-compiler-go/internal/format"
+)
+
+// countingCompiler counts how often Compile is called.
+type countingCompiler struct {
+	mockMarkdownCompiler
+	calls int
+}
+
+func (m *countingCompiler) Compile(resource *Resource) ([]CompilationResult, error) {
+	m.calls++
+	return m.mockMarkdownCompiler.Compile(resource)
+}
+
+func testRule(body string) *Resource {
+	resource := &Resource{
+		APIVersion: "ai-resource/draft",
+		Kind:       "Rule",
+		Spec: &format.Rule{
+			Metadata: format.Metadata{ID: "testRule"},
+			Spec: format.RuleSpec{
+				Enforcement: "must",
+				Body:        format.Body{String: strPtr(body)},
+			},
+		},
+	}
+	resource.Metadata.ID = "testRule"
+	return resource
+}
+
+func TestNewCompiler_Options(t *testing.T) {
+	RegisterDefaultTarget("default-only", &mockMarkdownCompiler{})
+
+	if _, ok := NewCompiler().targets["default-only"]; !ok {
+		t.Error("NewCompiler() missing default target")
+	}
+
+	c := NewCompiler(WithoutDefaults(), WithTargets(&mockCursorCompiler{}))
+	if _, ok := c.targets["default-only"]; ok {
+		t.Error("WithoutDefaults() kept default target")
+	}
+	if _, ok := c.targets[TargetCursor]; !ok {
+		t.Error("WithTargets() did not register cursor")
+	}
+	_, err := c.Compile(testRule("body"), CompileOptions{Targets: []Target{"default-only"}})
+	if !errors.Is(err, ErrUnknownTarget) {
+		t.Errorf("Compile() error = %v, want ErrUnknownTarget", err)
+	}
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	c = NewCompiler(WithoutDefaults(), WithTargets(&mockCursorCompiler{}), WithLogger(logger))
+	if _, err := c.Compile(testRule("body"), CompileOptions{Targets: []Target{TargetCursor}}); err != nil {
+		t.Fatalf("Compile() error = %v", err)
+	}
+	if !strings.Contains(buf.String(), "target=cursor") {
+		t.Errorf("log = %q, want target attribute", buf.String())
+	}
+}
+
+func TestNewCompiler_WithTarget(t *testing.T) {
+	c := NewCompiler(WithoutDefaults(), WithTarget("docs", &mockMarkdownCompiler{}))
+	if _, ok := c.targets[TargetMarkdown]; ok {
+		t.Error("WithTarget() registered the target under its Name")
+	}
+	results, err := c.Compile(testRule("body"), CompileOptions{Targets: []Target{"docs"}})
+	if err != nil {
+		t.Fatalf("Compile() error = %v", err)
+	}
+	if len(results) != 1 {
+		t.Errorf("results = %v, want one", results)
+	}
+}
+
+func TestNewCompiler_WithValidator(t *testing.T) {
+	var calls []string
+	validator := func(name string, problems ...*ValidationError) Validator {
+		return ValidatorFunc(func(*Resource) []*ValidationError {
+			calls = append(calls, name)
+			return problems
+		})
+	}
+	tagRequired := &ValidationError{Field: "metadata.tags", Message: "missing tag"}
+	c := NewCompiler(WithoutDefaults(), WithTargets(&mockMarkdownCompiler{}),
+		WithValidator(validator("first")), WithValidator(validator("second", tagRequired)), WithValidator(validator("third")))
+
+	_, err := c.Compile(testRule("body"), CompileOptions{Targets: []Target{TargetMarkdown}})
+	var compileErr *CompileError
+	if !errors.As(err, &compileErr) || compileErr.Field != "metadata.tags" {
+		t.Fatalf("Compile() error = %v, want CompileError on metadata.tags", err)
+	}
+	if strings.Join(calls, ",") != "first,second" {
+		t.Errorf("validators called = %v, want first,second", calls)
+	}
+
+	calls = nil
+	problems := c.Validate(testRule("body"))
+	if len(problems) != 1 || problems[0] != tagRequired {
+		t.Errorf("Validate() = %v, want the validator's problem", problems)
+	}
+	if strings.Join(calls, ",") != "first,second,third" {
+		t.Errorf("validators called = %v, want all three", calls)
+	}
+
+	calls = nil
+	invalid := testRule("body")
+	invalid.Metadata.ID = ""
+	if _, err := c.Compile(invalid, CompileOptions{Targets: []Target{TargetMarkdown}}); err == nil {
+		t.Fatal("Compile() succeeded without an ID")
+	}
+	if len(calls) != 0 {
+		t.Errorf("validators called = %v, want none after a built-in problem", calls)
+	}
+}
+
+func TestNewCompiler_WithCache(t *testing.T) {
+	dir := t.TempDir()
+	tc := &countingCompiler{}
+	c := NewCompiler(WithoutDefaults(), WithCache(dir))
+	c.RegisterTarget(TargetMarkdown, tc)
+	opts := CompileOptions{Targets: []Target{TargetMarkdown}}
+
+	for i := 0; i < 2; i++ {
+		results, err := c.Compile(testRule("body"), opts)
+		if err != nil {
+			t.Fatalf("Compile() error = %v", err)
+		}
+		if len(results) != 1 || results[0].Path != "testRule.md" {
+			t.Errorf("results = %v, want testRule.md", results)
+		}
+	}
+	if tc.calls != 1 {
+		t.Errorf("target compiled %d times, want 1 (second from cache)", tc.calls)
+	}
+
+	if _, err := c.Compile(testRule("changed body"), opts); err != nil {
+		t.Fatalf("Compile() error = %v", err)
+	}
+	if tc.calls != 2 {
+		t.Errorf("target compiled %d times after change, want 2", tc.calls)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("ReadDir() error = %v", err)
+	}
+	if len(entries) != 2 {
+		t.Errorf("cache has %d entries, want 2", len(entries))
+	}
+}
+
+// assetCompiler compiles a prompt to SKILL.md and its assets.
+type assetCompiler struct {
+	mockMarkdownCompiler
+	calls int
+}
+
+func (m *assetCompiler) Compile(resource *Resource) ([]CompilationResult, error) {
+	m.calls++
+	prompt := resource.Spec.(*format.Prompt)
+	results := []CompilationResult{{Path: prompt.Metadata.ID + "/SKILL.md", Content: "Use the logo."}}
+	for _, asset := range prompt.Spec.Assets {
+		results = append(results, CompilationResult{Path: prompt.Metadata.ID + "/" + asset.FileName(), Content: string(asset.Data), Asset: true})
+	}
+	return results, nil
+}
+
+func TestNewCompiler_WithCacheAssets(t *testing.T) {
+	tc := &assetCompiler{}
+	c := NewCompiler(WithoutDefaults(), WithCache(t.TempDir()))
+	c.RegisterTarget(TargetClaude, tc)
+	opts := CompileOptions{Targets: []Target{TargetClaude}}
+	prompt := func(data string) *Resource {
+		resource := &Resource{APIVersion: "ai-resource/draft", Kind: "Prompt", Spec: &format.Prompt{
+			Metadata: format.Metadata{ID: "brand"},
+			Spec:     format.PromptSpec{Body: format.Body{String: strPtr("Use the logo.")}, Assets: []format.Asset{{Path: "logo.png", Data: []byte(data)}}},
+		}}
+		resource.Metadata.ID = "brand"
+		return resource
+	}
+
+	binary := "\x89PNG\x00\xff"
+	for i := 0; i < 2; i++ {
+		results, err := c.Compile(prompt(binary), opts)
+		if err != nil {
+			t.Fatalf("Compile() error = %v", err)
+		}
+		if len(results) != 2 || !results[1].Asset || results[1].Content != binary {
+			t.Fatalf("results = %+v, want the binary asset intact", results)
+		}
+		if stats := results[1].Stats; stats.Bytes != len(binary) || stats.Tokens != 0 {
+			t.Errorf("asset Stats = %+v, want bytes and no tokens", stats)
+		}
+	}
+	if tc.calls != 1 {
+		t.Errorf("target compiled %d times, want 1 (second from cache)", tc.calls)
+	}
+	if _, err := c.Compile(prompt("changed"), opts); err != nil {
+		t.Fatalf("Compile() error = %v", err)
+	}
+	if tc.calls != 2 {
+		t.Errorf("target compiled %d times after the asset changed, want 2", tc.calls)
+	}
+}
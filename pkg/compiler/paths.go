@@ -0,0 +1,102 @@
+package compiler
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/jomadu/ai-resource-compiler-go/internal/format"
+)
+
+// placeholder matches a {name} reference in a path template.
+var placeholder = regexp.MustCompile(`\{([^{}]*)\}`)
+
+// skillFile is the file name of a skill, which its tool finds by name: a
+// prefix goes on the skill's directory instead.
+const skillFile = "SKILL.md"
+
+// contextFiles are the names of the context files tools load by name, which
+// keep them whatever the prefix.
+var contextFiles = map[string]bool{
+	"AGENTS.md":               true,
+	"CLAUDE.md":               true,
+	"GEMINI.md":               true,
+	"copilot-instructions.md": true,
+}
+
+// renamePath applies a path template and then a filename prefix to a result
+// path produced for target, and makes its names valid on every platform
+// with format.SanitizePath. See CompileOptions.PathTemplate for the
+// placeholders. The prefix leaves the names tools look for alone: it goes
+// on the directory of a SKILL.md and not on context files.
+func renamePath(p string, target Target, prefix, template string) (string, error) {
+	if template != "" {
+		dir, file := path.Split(p)
+		name, ext := file, ""
+		if i := strings.Index(file, "."); i > 0 {
+			name, ext = file[:i], file[i:]
+		}
+		values := map[string]string{
+			"dir":    strings.TrimSuffix(dir, "/"),
+			"file":   file,
+			"name":   name,
+			"ext":    ext,
+			"target": string(target),
+		}
+		var unknown string
+		p = placeholder.ReplaceAllStringFunc(template, func(ref string) string {
+			key := ref[1 : len(ref)-1]
+			value, ok := values[key]
+			if !ok && unknown == "" {
+				unknown = key
+			}
+			return value
+		})
+		if unknown != "" {
+			return "", fmt.Errorf("invalid path template %q: unknown placeholder {%s}", template, unknown)
+		}
+		p = path.Clean(strings.TrimPrefix(p, "/"))
+		if p == "." || p == ".." || strings.HasPrefix(p, "../") {
+			return "", fmt.Errorf("invalid path template %q: produces %q", template, p)
+		}
+	}
+	if prefix != "" {
+		dir, file := path.Split(p)
+		switch {
+		case file == skillFile && dir != "":
+			parent, name := path.Split(strings.TrimSuffix(dir, "/"))
+			p = parent + prefix + name + "/" + file
+		case !contextFiles[file]:
+			p = dir + prefix + file
+		}
+	}
+	return format.SanitizePath(p), nil
+}
+
+// renameResults applies renamePath to the path of each result, after
+// prefixing it with namespace. Assets instead move with the result before
+// them, the prompt they are shipped with, keeping their place relative to
+// it so the prompt's references to them still resolve.
+func renameResults(results []CompilationResult, target Target, namespace, prefix, template string) error {
+	var fromDir, toDir string
+	for i := range results {
+		p := format.BuildNamespacedPath(namespace, results[i].Path)
+		if results[i].Asset {
+			rel, ok := strings.CutPrefix(p, fromDir)
+			if !ok {
+				return fmt.Errorf("asset %s is not in the directory of %s", p, strings.TrimSuffix(fromDir, "/"))
+			}
+			results[i].Path = toDir + rel
+			continue
+		}
+		renamed, err := renamePath(p, target, prefix, template)
+		if err != nil {
+			return err
+		}
+		results[i].Path = renamed
+		fromDir, _ = path.Split(p)
+		toDir, _ = path.Split(renamed)
+	}
+	return nil
+}
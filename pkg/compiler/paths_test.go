@@ -0,0 +1,67 @@
+package compiler
+
+import "testing"
+
+func TestRenamePath(t *testing.T) {
+	tests := []struct {
+		name     string
+		path     string
+		prefix   string
+		template string
+		want     string
+	}{
+		{name: "unchanged", path: "cleanCode_names.mdc", want: "cleanCode_names.mdc"},
+		{name: "prefix", path: "cleanCode_names.mdc", prefix: "org-", want: "org-cleanCode_names.mdc"},
+		{name: "prefix in directory", path: "instructions/names.instructions.md", prefix: "org-", want: "instructions/org-names.instructions.md"},
+		{name: "template", path: "instructions/names.instructions.md", template: "{dir}/arc/{name}{ext}", want: "instructions/arc/names.instructions.md"},
+		{name: "template without dir", path: "names.md", template: "{dir}/{target}/{file}", want: "cursor/names.md"},
+		{name: "template and prefix", path: "names.md", template: "arc/{file}", prefix: "org-", want: "arc/org-names.md"},
+		{name: "prefix on skill directory", path: "skills/review_check/SKILL.md", prefix: "org-", want: "skills/org-review_check/SKILL.md"},
+		{name: "prefix on standalone skill directory", path: "review/SKILL.md", prefix: "org-", want: "org-review/SKILL.md"},
+		{name: "prefix skips context file", path: "CLAUDE.md", prefix: "org-", want: "CLAUDE.md"},
+		{name: "prefix skips namespaced context file", path: "platform/AGENTS.md", prefix: "org-", want: "platform/AGENTS.md"},
+		{name: "prefix skips copilot instructions", path: "copilot-instructions.md", prefix: "org-", want: "copilot-instructions.md"},
+		{name: "sanitized", path: "names.md", template: "{target}:{name}{ext}", want: "cursor_names-68d17ffa.md"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := renamePath(tt.path, TargetCursor, tt.prefix, tt.template)
+			if err != nil {
+				t.Fatalf("renamePath() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("renamePath() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenamePath_InvalidTemplate(t *testing.T) {
+	for _, template := range []string{"{dir}/{id}", "../{file}", "{dir}"} {
+		if got, err := renamePath("names.md", TargetCursor, "", template); err == nil {
+			t.Errorf("renamePath(%q) = %q, want error", template, got)
+		}
+	}
+}
+
+func TestRenameResults(t *testing.T) {
+	results := []CompilationResult{
+		{Path: "review/SKILL.md"},
+		{Path: "review/scripts/check.sh", Asset: true},
+		{Path: "names.md"},
+	}
+	if err := renameResults(results, TargetClaude, "platform", "org-", "{dir}/arc/{file}"); err != nil {
+		t.Fatalf("renameResults() error = %v", err)
+	}
+	want := []string{"platform/review/org-arc/SKILL.md", "platform/review/org-arc/scripts/check.sh", "platform/arc/org-names.md"}
+	for i, result := range results {
+		if result.Path != want[i] {
+			t.Errorf("results[%d].Path = %q, want %q", i, result.Path, want[i])
+		}
+	}
+
+	stray := []CompilationResult{{Path: "a/SKILL.md"}, {Path: "b/logo.png", Asset: true}}
+	if err := renameResults(stray, TargetClaude, "", "", ""); err == nil {
+		t.Error("renameResults() accepted an asset outside its prompt's directory")
+	}
+}
@@ -0,0 +1,89 @@
+package compiler
+
+import (
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Position returns the line and column, both 1-based, of a field of the
+// resource, given as a dotted path such as "spec.rules.naming.name". If
+// value is set, the position of that value within the field is returned
+// instead, or anywhere in the resource if the field does not exist; this
+// locates map keys such as rule IDs and list elements such as fragment
+// references. List elements in the path are numbered from 0, e.g.
+// "spec.scope.0.files". A field that does not exist, such as a missing
+// required one, is located at the closest field containing it. It returns
+// zeros when the position is unknown.
+func (r *Resource) Position(field, value string) (line, column int) {
+	root := r.Node
+	if root == nil {
+		return 0, 0
+	}
+	if root.Kind == yaml.DocumentNode && len(root.Content) > 0 {
+		root = root.Content[0]
+	}
+
+	node, closest := fieldNode(root, field)
+	if value != "" {
+		within := node
+		if within == nil {
+			within = root
+		}
+		if found := valueNode(within, value); found != nil {
+			node = found
+		}
+	}
+	if node == nil {
+		node = closest
+	}
+	if node == nil {
+		return 0, 0
+	}
+	return node.Line, node.Column
+}
+
+// fieldNode returns the value node at a dotted field path from root, or nil
+// if the path does not exist, along with the node of the closest field on
+// the path that does, other than root.
+func fieldNode(root *yaml.Node, field string) (node, closest *yaml.Node) {
+	if field == "" {
+		return nil, nil
+	}
+	node = root
+	for _, key := range strings.Split(field, ".") {
+		var next *yaml.Node
+		switch node.Kind {
+		case yaml.MappingNode:
+			for i := 0; i+1 < len(node.Content); i += 2 {
+				if node.Content[i].Value == key {
+					next = node.Content[i+1]
+					break
+				}
+			}
+		case yaml.SequenceNode:
+			if i, err := strconv.Atoi(key); err == nil && i >= 0 && i < len(node.Content) {
+				next = node.Content[i]
+			}
+		}
+		if next == nil {
+			return nil, closest
+		}
+		node, closest = next, next
+	}
+	return node, closest
+}
+
+// valueNode returns the first scalar, key or value, equal to value.
+func valueNode(node *yaml.Node, value string) *yaml.Node {
+	if node.Kind == yaml.ScalarNode && node.Value == value {
+		return node
+	}
+	for _, child := range node.Content {
+		if found := valueNode(child, value); found != nil {
+			return found
+		}
+	}
+	return nil
+}
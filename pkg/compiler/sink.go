@@ -0,0 +1,32 @@
+package compiler
+
+import (
+	"fmt"
+	"io"
+)
+
+// OutputSink receives the files Compiler.CompileTo produces, one at a time,
+// so they can be written out, to disk, an archive, or object storage, without
+// holding every result in memory.
+type OutputSink interface {
+	// WriteResult receives a compiled file of target. path is relative and
+	// slash-separated, as in CompilationResult. An error stops the compile.
+	WriteResult(target Target, path, content string) error
+}
+
+// SinkFunc adapts a function to an OutputSink.
+type SinkFunc func(target Target, path, content string) error
+
+// WriteResult calls f.
+func (f SinkFunc) WriteResult(target Target, path, content string) error {
+	return f(target, path, content)
+}
+
+// WriterSink returns an OutputSink writing each file to w under a
+// "=== target/path ===" header, as arc prints results to stdout.
+func WriterSink(w io.Writer) OutputSink {
+	return SinkFunc(func(target Target, path, content string) error {
+		_, err := fmt.Fprintf(w, "=== %s/%s ===\n%s\n\n", target, path, content)
+		return err
+	})
+}
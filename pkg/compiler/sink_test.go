@@ -0,0 +1,53 @@
+package compiler
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestCompileTo(t *testing.T) {
+	c := setupCompiler()
+	var got []string
+	sink := SinkFunc(func(target Target, path, content string) error {
+		got = append(got, string(target)+"/"+path)
+		return nil
+	})
+
+	err := c.CompileTo(testRule("body"), CompileOptions{Targets: []Target{TargetCursor, TargetMarkdown}}, sink)
+	if err != nil {
+		t.Fatalf("CompileTo() error = %v", err)
+	}
+	want := []string{"cursor/testRule.mdc", "markdown/testRule.md"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("sink received %v, want %v", got, want)
+	}
+}
+
+func TestCompileTo_SinkError(t *testing.T) {
+	c := setupCompiler()
+	errFull := errors.New("disk full")
+	calls := 0
+	sink := SinkFunc(func(Target, string, string) error {
+		calls++
+		return errFull
+	})
+
+	err := c.CompileTo(testRule("body"), CompileOptions{Targets: []Target{TargetCursor, TargetMarkdown}}, sink)
+	if !errors.Is(err, errFull) {
+		t.Fatalf("CompileTo() error = %v, want the sink's error", err)
+	}
+	if calls != 1 {
+		t.Errorf("sink called %d times after failing, want 1", calls)
+	}
+}
+
+func TestWriterSink(t *testing.T) {
+	var b strings.Builder
+	if err := WriterSink(&b).WriteResult(TargetMarkdown, "rule.md", "body"); err != nil {
+		t.Fatalf("WriteResult() error = %v", err)
+	}
+	if want := "=== markdown/rule.md ===\nbody\n\n"; b.String() != want {
+		t.Errorf("output = %q, want %q", b.String(), want)
+	}
+}
@@ -0,0 +1,247 @@
+package compiler
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/jomadu/ai-resource-compiler-go/internal/format"
+)
+
+// executeTemplates returns a copy of resource whose bodies and fragments have
+// been executed as Go templates, with the data of opts as dot. Fragment
+// references ($name) are left for fragment resolution. A template expanding
+// beyond limit bytes is an error. The original resource is not modified.
+func executeTemplates(resource *Resource, opts CompileOptions, limit int) (*Resource, error) {
+	newTemplater := func(fragments map[string]string) *templater {
+		return newTemplater(fragments, opts.TemplateData, opts.TemplateEnv, limit)
+	}
+	out := *resource
+	var err error
+	switch spec := resource.Spec.(type) {
+	case *format.Rule:
+		t := newTemplater(spec.Spec.Fragments)
+		rule := *spec
+		if rule.Spec.Body, err = t.body("spec.body", spec.Spec.Body); err != nil {
+			return nil, err
+		}
+		if rule.Spec.Fragments, err = t.allFragments(); err != nil {
+			return nil, err
+		}
+		out.Spec = &rule
+	case *format.Ruleset:
+		t := newTemplater(spec.Spec.Fragments)
+		ruleset := *spec
+		ruleset.Spec.Rules = make(map[string]format.RuleItem, len(spec.Spec.Rules))
+		for _, id := range spec.Spec.RuleIDs() {
+			item := spec.Spec.Rules[id]
+			if item.Body, err = t.body("spec.rules."+id+".body", item.Body); err != nil {
+				return nil, err
+			}
+			ruleset.Spec.Rules[id] = item
+		}
+		if ruleset.Spec.Fragments, err = t.allFragments(); err != nil {
+			return nil, err
+		}
+		out.Spec = &ruleset
+	case *format.Prompt:
+		t := newTemplater(spec.Spec.Fragments)
+		prompt := *spec
+		if prompt.Spec.Body, err = t.body("spec.body", spec.Spec.Body); err != nil {
+			return nil, err
+		}
+		if prompt.Spec.Fragments, err = t.allFragments(); err != nil {
+			return nil, err
+		}
+		out.Spec = &prompt
+	case *format.Promptset:
+		t := newTemplater(spec.Spec.Fragments)
+		promptset := *spec
+		promptset.Spec.Prompts = make(map[string]format.PromptItem, len(spec.Spec.Prompts))
+		for _, id := range spec.Spec.PromptIDs() {
+			item := spec.Spec.Prompts[id]
+			if item.Body, err = t.body("spec.prompts."+id+".body", item.Body); err != nil {
+				return nil, err
+			}
+			promptset.Spec.Prompts[id] = item
+		}
+		if promptset.Spec.Fragments, err = t.allFragments(); err != nil {
+			return nil, err
+		}
+		out.Spec = &promptset
+	case *format.Command:
+		t := newTemplater(spec.Spec.Fragments)
+		command := *spec
+		if command.Spec.Body, err = t.body("spec.body", spec.Spec.Body); err != nil {
+			return nil, err
+		}
+		if command.Spec.Fragments, err = t.allFragments(); err != nil {
+			return nil, err
+		}
+		out.Spec = &command
+	case *format.Context:
+		t := newTemplater(spec.Spec.Fragments)
+		context := *spec
+		if context.Spec.Body, err = t.body("spec.body", spec.Spec.Body); err != nil {
+			return nil, err
+		}
+		if context.Spec.Fragments, err = t.allFragments(); err != nil {
+			return nil, err
+		}
+		out.Spec = &context
+	default:
+		return resource, nil
+	}
+	return &out, nil
+}
+
+// templater executes the templates of one resource, whose fragments the
+// include function reads.
+type templater struct {
+	fragments map[string]string
+	data      any
+	env       map[string]bool // variables env may read
+	limit     int
+	including map[string]bool
+	included  map[string]string // executed fragments, by name
+}
+
+func newTemplater(fragments map[string]string, data any, env []string, limit int) *templater {
+	t := &templater{
+		fragments: fragments,
+		data:      data,
+		env:       make(map[string]bool, len(env)),
+		limit:     limit,
+		including: make(map[string]bool),
+		included:  make(map[string]string),
+	}
+	for _, name := range env {
+		t.env[name] = true
+	}
+	return t
+}
+
+// body executes the literal parts of body, reporting errors in field.
+func (t *templater) body(field string, body format.Body) (format.Body, error) {
+	if body.String != nil {
+		s, err := t.execute(field, *body.String)
+		if err != nil {
+			return format.Body{}, err
+		}
+		return format.Body{String: &s}, nil
+	}
+	if body.Array == nil {
+		return body, nil
+	}
+	arr := make([]string, len(body.Array))
+	for i, part := range body.Array {
+		if strings.HasPrefix(part, "$") && !strings.HasPrefix(part, "${") {
+			arr[i] = part
+			continue
+		}
+		s, err := t.execute(field, part)
+		if err != nil {
+			return format.Body{}, err
+		}
+		arr[i] = s
+	}
+	return format.Body{Array: arr}, nil
+}
+
+// allFragments returns the fragments with each executed.
+func (t *templater) allFragments() (map[string]string, error) {
+	if t.fragments == nil {
+		return nil, nil
+	}
+	out := make(map[string]string, len(t.fragments))
+	for _, name := range format.SortedKeys(t.fragments) {
+		s, err := t.include(name)
+		if err != nil {
+			return nil, err
+		}
+		out[name] = s
+	}
+	return out, nil
+}
+
+// execute runs text as a template named field. Text without actions is
+// returned as is.
+func (t *templater) execute(field, text string) (string, error) {
+	if !strings.Contains(text, "{{") {
+		return text, nil
+	}
+	tmpl, err := template.New(field).Option("missingkey=error").Funcs(t.funcs()).Parse(text)
+	if err != nil {
+		return "", &ValidationError{Field: field, Message: err.Error()}
+	}
+	var b strings.Builder
+	if err := tmpl.Execute(&b, t.data); err != nil {
+		return "", &ValidationError{Field: field, Message: err.Error()}
+	}
+	if Exceeds(b.Len(), t.limit) {
+		return "", fmt.Errorf("%w: %s expands to more than %d bytes", ErrLimitExceeded, field, t.limit)
+	}
+	return b.String(), nil
+}
+
+// include returns the named fragment, executed as a template. Each fragment
+// is executed once, however often it is included.
+func (t *templater) include(name string) (string, error) {
+	if s, ok := t.included[name]; ok {
+		return s, nil
+	}
+	content, ok := t.fragments[name]
+	if !ok {
+		return "", fmt.Errorf("unknown fragment %s", name)
+	}
+	if t.including[name] {
+		return "", fmt.Errorf("fragment %s includes itself", name)
+	}
+	t.including[name] = true
+	defer delete(t.including, name)
+	s, err := t.execute("spec.fragments."+name, content)
+	if err != nil {
+		return "", err
+	}
+	t.included[name] = s
+	return s, nil
+}
+
+// getenv returns the environment variable name, if CompileOptions.TemplateEnv
+// allows templates to read it.
+func (t *templater) getenv(name string) (string, error) {
+	if !t.env[name] {
+		return "", fmt.Errorf("env: %s is not among the variables templates may read", name)
+	}
+	return os.Getenv(name), nil
+}
+
+// funcs returns the functions templates may call.
+func (t *templater) funcs() template.FuncMap {
+	return template.FuncMap{
+		"upper":   strings.ToUpper,
+		"lower":   strings.ToLower,
+		"join":    join,
+		"include": t.include,
+		"now":     time.Now,
+		"env":     t.getenv,
+	}
+}
+
+// join joins the elements of a slice with sep, formatting each with
+// fmt.Sprint, so lists decoded from YAML or JSON join as well as []string.
+// It takes the slice last, for use in pipelines: {{ .langs | join ", " }}.
+func join(sep string, items any) (string, error) {
+	v := reflect.ValueOf(items)
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return "", fmt.Errorf("join: cannot join %T", items)
+	}
+	parts := make([]string, v.Len())
+	for i := range parts {
+		parts[i] = fmt.Sprint(v.Index(i).Interface())
+	}
+	return strings.Join(parts, sep), nil
+}
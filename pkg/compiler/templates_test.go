@@ -0,0 +1,132 @@
+package compiler
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/jomadu/ai-resource-compiler-go/internal/format"
+)
+
+func TestExecuteTemplates(t *testing.T) {
+	t.Setenv("ARC_TEAM", "platform")
+	resource := &Resource{
+		APIVersion: "ai-resource/draft",
+		Kind:       "Ruleset",
+		Spec: &format.Ruleset{
+			Metadata: format.Metadata{ID: "testRuleset"},
+		},
+	}
+	ruleset := resource.Spec.(*format.Ruleset)
+	ruleset.Spec.Rules = map[string]format.RuleItem{
+		"rule1": {
+			Enforcement: "must",
+			Body: format.Body{Array: []string{
+				"Use {{ .langs | join \", \" }}.",
+				"{{ range .langs }}| {{ upper . }} |\n{{ end }}",
+				"$footer",
+			}},
+		},
+	}
+	ruleset.Spec.Fragments = map[string]string{
+		"footer":  `{{ include "contact" }}`,
+		"contact": `Ask {{ env "ARC_TEAM" | lower }}.`,
+	}
+
+	executed, err := executeTemplates(resource, CompileOptions{TemplateData: map[string]any{"langs": []any{"go", "rust"}}, TemplateEnv: []string{"ARC_TEAM"}}, -1)
+	if err != nil {
+		t.Fatalf("executeTemplates() error = %v", err)
+	}
+
+	got := executed.Spec.(*format.Ruleset)
+	body := format.ResolveBody(got.Spec.Rules["rule1"].Body, got.Spec.Fragments)
+	want := "Use go, rust.\n\n| GO |\n| RUST |\n\n\nAsk platform."
+	if body != want {
+		t.Errorf("body = %q, want %q", body, want)
+	}
+	if !strings.Contains(ruleset.Spec.Fragments["footer"], "{{") {
+		t.Error("original resource was modified")
+	}
+}
+
+func TestExecuteTemplates_Errors(t *testing.T) {
+	tests := []struct {
+		name      string
+		body      string
+		fragments map[string]string
+		want      string
+	}{
+		{name: "missing key", body: "{{ .missing }}", want: "missing"},
+		{name: "unknown function", body: "{{ shout . }}", want: `function "shout" not defined`},
+		{name: "unknown fragment", body: `{{ include "nope" }}`, want: "unknown fragment nope"},
+		{name: "cycle", body: `{{ include "a" }}`, fragments: map[string]string{"a": `{{ include "a" }}`}, want: "includes itself"},
+		{name: "env not allowed", body: `{{ env "HOME" }}`, want: "HOME is not among the variables templates may read"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resource := &Resource{Kind: "Rule", Spec: &format.Rule{Spec: format.RuleSpec{
+				Body:      format.Body{String: &tt.body},
+				Fragments: tt.fragments,
+			}}}
+			_, err := executeTemplates(resource, CompileOptions{TemplateData: map[string]any{}}, -1)
+			var validationErr *ValidationError
+			if !errors.As(err, &validationErr) || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("executeTemplates() error = %v, want ValidationError containing %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestExecuteTemplates_NestedIncludes(t *testing.T) {
+	// Each fragment includes the previous one twice, so expanding the
+	// includes one by one would take 2^40 steps.
+	fragments := map[string]string{"f0": "x"}
+	for i := 1; i <= 40; i++ {
+		fragments[fmt.Sprintf("f%d", i)] = fmt.Sprintf(`{{ include "f%d" }}{{ include "f%d" }}`, i-1, i-1)
+	}
+	body := `{{ include "f40" }}`
+	resource := &Resource{Kind: "Rule", Spec: &format.Rule{Spec: format.RuleSpec{
+		Body:      format.Body{String: &body},
+		Fragments: fragments,
+	}}}
+
+	_, err := executeTemplates(resource, CompileOptions{}, 1<<20)
+	if err == nil || !strings.Contains(err.Error(), "expands to more than 1048576 bytes") {
+		t.Fatalf("executeTemplates() error = %v, want the body size limit exceeded", err)
+	}
+
+	// Fragments included several times are executed once.
+	small := `{{ include "f3" }}`
+	executed, err := executeTemplates(&Resource{Kind: "Rule", Spec: &format.Rule{Spec: format.RuleSpec{
+		Body:      format.Body{String: &small},
+		Fragments: map[string]string{"f0": "x", "f1": fragments["f1"], "f2": fragments["f2"], "f3": fragments["f3"]},
+	}}}, CompileOptions{}, 1<<20)
+	if err != nil {
+		t.Fatalf("executeTemplates() error = %v", err)
+	}
+	if got := *executed.Spec.(*format.Rule).Spec.Body.String; got != "xxxxxxxx" {
+		t.Errorf("body = %q, want xxxxxxxx", got)
+	}
+}
+
+func TestCompile_Templates(t *testing.T) {
+	body := "{{ .name }}"
+	resource := &Resource{APIVersion: "ai-resource/draft", Kind: "Rule", Source: "rule.yaml", Spec: &format.Rule{
+		Metadata: format.Metadata{ID: "rule"},
+		Spec:     format.RuleSpec{Enforcement: "must", Body: format.Body{String: &body}},
+	}}
+	resource.Metadata.ID = "rule"
+	c := setupCompiler()
+
+	// Without Templates, bodies are compiled as written.
+	if _, err := c.Compile(resource, CompileOptions{Targets: []Target{TargetMarkdown}}); err != nil {
+		t.Fatalf("Compile() error = %v", err)
+	}
+
+	_, err := c.Compile(resource, CompileOptions{Targets: []Target{TargetMarkdown}, Templates: true})
+	var compileErr *CompileError
+	if !errors.As(err, &compileErr) || compileErr.Field != "spec.body" {
+		t.Errorf("Compile() error = %v, want a CompileError in spec.body", err)
+	}
+}
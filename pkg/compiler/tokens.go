@@ -0,0 +1,43 @@
+package compiler
+
+import "github.com/jomadu/ai-resource-compiler-go/internal/format"
+
+// Tokenizer counts the model tokens of compiled output, for
+// CompilationResult.Stats and CompileOptions.TokenBudget. Plug in the
+// tokenizer of the model the output is for with WithTokenizer.
+type Tokenizer interface {
+	CountTokens(text string) int
+}
+
+// TokenizerFunc adapts a function to Tokenizer.
+type TokenizerFunc func(text string) int
+
+// CountTokens returns f(text).
+func (f TokenizerFunc) CountTokens(text string) int {
+	return f(text)
+}
+
+// EstimateTokens is the default tokenizer: it estimates tokens at about
+// four characters each, close enough for English prose and code to keep an
+// eye on budgets.
+func EstimateTokens(text string) int {
+	return format.EstimateTokens(text)
+}
+
+// resultStats returns the stats of result. Assets, which may be binary,
+// take no tokens.
+func (c *Compiler) resultStats(result CompilationResult) ResultStats {
+	stats := ResultStats{Bytes: len(result.Content)}
+	if !result.Asset {
+		stats.Tokens = c.tokenizer.CountTokens(result.Content)
+	}
+	return stats
+}
+
+// checkBudget logs a warning for a result whose tokens exceed budget, unless
+// budget is zero.
+func (c *Compiler) checkBudget(target Target, path string, tokens, budget int) {
+	if budget > 0 && tokens > budget {
+		c.logger.Warn("output exceeds token budget", "target", target, "path", path, "tokens", tokens, "budget", budget)
+	}
+}
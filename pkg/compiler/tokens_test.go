@@ -0,0 +1,35 @@
+package compiler
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestResultStats(t *testing.T) {
+	results, err := setupCompiler().Compile(testRule("Use descriptive names."), CompileOptions{Targets: []Target{TargetMarkdown}})
+	if err != nil {
+		t.Fatalf("Compile() error = %v", err)
+	}
+	for _, result := range results {
+		if result.Stats.Bytes != len(result.Content) || result.Stats.Tokens != EstimateTokens(result.Content) || result.Stats.Tokens == 0 {
+			t.Errorf("Stats of %s = %+v for %d bytes", result.Path, result.Stats, len(result.Content))
+		}
+	}
+
+	words := TokenizerFunc(func(text string) int { return len(strings.Fields(text)) })
+	var buf bytes.Buffer
+	c := NewCompiler(WithoutDefaults(), WithTokenizer(words), WithLogger(slog.New(slog.NewTextHandler(&buf, nil))))
+	c.RegisterTarget(TargetMarkdown, &mockMarkdownCompiler{})
+	results, err = c.Compile(testRule("Use descriptive names."), CompileOptions{Targets: []Target{TargetMarkdown}, TokenBudget: 1})
+	if err != nil {
+		t.Fatalf("Compile() error = %v", err)
+	}
+	if got, want := results[0].Stats.Tokens, len(strings.Fields(results[0].Content)); got != want {
+		t.Errorf("Stats.Tokens = %d, want %d from the plugged-in tokenizer", got, want)
+	}
+	if !strings.Contains(buf.String(), "output exceeds token budget") || !strings.Contains(buf.String(), "budget=1") {
+		t.Errorf("log = %q, want a token budget warning", buf.String())
+	}
+}
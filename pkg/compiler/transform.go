@@ -0,0 +1,227 @@
+package compiler
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/jomadu/ai-resource-compiler-go/internal/format"
+)
+
+// BodyTransformer rewrites the body of a rule, prompt, command, or context
+// before targets format it, e.g. to normalize whitespace across resources
+// written by different people. Set them with CompileOptions.Transformers.
+type BodyTransformer interface {
+	TransformBody(body string) string
+}
+
+// BodyTransformerFunc adapts a function to BodyTransformer.
+type BodyTransformerFunc func(body string) string
+
+// TransformBody returns f(body).
+func (f BodyTransformerFunc) TransformBody(body string) string {
+	return f(body)
+}
+
+// TrimTrailingWhitespace removes spaces and tabs at the end of each line.
+func TrimTrailingWhitespace() BodyTransformer {
+	return BodyTransformerFunc(func(body string) string {
+		lines := strings.Split(body, "\n")
+		for i, line := range lines {
+			lines[i] = strings.TrimRight(line, " \t")
+		}
+		return strings.Join(lines, "\n")
+	})
+}
+
+// NormalizeLineEndings turns Windows (\r\n) and classic Mac (\r) line
+// endings into \n.
+func NormalizeLineEndings() BodyTransformer {
+	return BodyTransformerFunc(func(body string) string {
+		return strings.ReplaceAll(strings.ReplaceAll(body, "\r\n", "\n"), "\r", "\n")
+	})
+}
+
+var (
+	htmlCommentLine = regexp.MustCompile(`(?ms)^[ \t]*<!--.*?-->[ \t]*\n`)
+	htmlComment     = regexp.MustCompile(`(?s)<!--.*?-->`)
+)
+
+// StripHTMLComments removes <!-- ... --> comments outside fenced code
+// blocks, with the lines that hold nothing else.
+func StripHTMLComments() BodyTransformer {
+	return BodyTransformerFunc(func(body string) string {
+		return outsideCode(body, func(text string) string {
+			return htmlComment.ReplaceAllString(htmlCommentLine.ReplaceAllString(text, ""), "")
+		})
+	})
+}
+
+var smartQuotes = strings.NewReplacer(
+	"‘", "'", "’", "'", "‚", "'", "‛", "'",
+	"“", `"`, "”", `"`, "„", `"`, "‟", `"`,
+)
+
+// ReplaceSmartQuotes replaces typographic quotes, as word processors insert
+// them, with straight ASCII quotes.
+func ReplaceSmartQuotes() BodyTransformer {
+	return BodyTransformerFunc(smartQuotes.Replace)
+}
+
+// listMarker matches the indentation and marker a wrapped line's
+// continuations align with: a list item's "- ", "* ", "+ ", or "1. ", or a
+// quote's "> ".
+var listMarker = regexp.MustCompile(`^\s*(?:[-*+] |\d+[.)] |> )?`)
+
+// WrapLines wraps lines longer than width characters at spaces, outside
+// fenced code blocks. Continuations of list items are indented under the
+// item's text and those of quotes repeat the "> ". Headings, table rows,
+// lines indented as code, and words longer than width are left as they are.
+func WrapLines(width int) BodyTransformer {
+	return BodyTransformerFunc(func(body string) string {
+		return outsideCode(body, func(text string) string {
+			lines := strings.Split(text, "\n")
+			var out []string
+			for _, line := range lines {
+				out = append(out, wrapLine(line, width)...)
+			}
+			return strings.Join(out, "\n")
+		})
+	})
+}
+
+func wrapLine(line string, width int) []string {
+	trimmed := strings.TrimLeft(line, " ")
+	if utf8.RuneCountInString(line) <= width || strings.HasPrefix(trimmed, "#") || strings.HasPrefix(trimmed, "|") ||
+		strings.HasPrefix(line, "\t") || len(line)-len(trimmed) >= 4 {
+		return []string{line}
+	}
+	prefix := listMarker.FindString(line)
+	continuation := strings.Repeat(" ", len(prefix))
+	if strings.HasSuffix(prefix, "> ") {
+		continuation = prefix
+	}
+
+	var lines []string
+	current := prefix
+	empty := true
+	for _, word := range strings.Fields(line[len(prefix):]) {
+		if !empty && utf8.RuneCountInString(current)+1+utf8.RuneCountInString(word) > width {
+			lines = append(lines, current)
+			current, empty = continuation, true
+		}
+		if !empty {
+			current += " "
+		}
+		current += word
+		empty = false
+	}
+	return append(lines, current)
+}
+
+// outsideCode applies f to the parts of body outside fenced code blocks,
+// which open and close with a line starting ``` or ~~~.
+func outsideCode(body string, f func(string) string) string {
+	var out, text strings.Builder
+	fence := ""
+	lines := strings.SplitAfter(body, "\n")
+	for _, line := range lines {
+		trimmed := strings.TrimLeft(line, " ")
+		switch {
+		case fence == "" && (strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~")):
+			out.WriteString(f(text.String()))
+			text.Reset()
+			fence = trimmed[:3]
+			out.WriteString(line)
+		case fence != "":
+			out.WriteString(line)
+			if strings.HasPrefix(trimmed, fence) {
+				fence = ""
+			}
+		default:
+			text.WriteString(line)
+		}
+	}
+	out.WriteString(f(text.String()))
+	return out.String()
+}
+
+// ParseTransformer returns the built-in transformer named by spec:
+// trim-trailing-whitespace, normalize-line-endings, strip-html-comments,
+// smart-quotes, or wrap=WIDTH, as the arc command line and arc.yaml name
+// them.
+func ParseTransformer(spec string) (BodyTransformer, error) {
+	name, value, hasValue := strings.Cut(spec, "=")
+	switch name {
+	case "wrap":
+		width, err := strconv.Atoi(value)
+		if !hasValue || err != nil || width <= 0 {
+			return nil, fmt.Errorf("transformer wrap needs a positive width, e.g. wrap=100")
+		}
+		return WrapLines(width), nil
+	case "trim-trailing-whitespace":
+		return TrimTrailingWhitespace(), nil
+	case "normalize-line-endings":
+		return NormalizeLineEndings(), nil
+	case "strip-html-comments":
+		return StripHTMLComments(), nil
+	case "smart-quotes":
+		return ReplaceSmartQuotes(), nil
+	}
+	return nil, fmt.Errorf("unknown transformer %q (supported: trim-trailing-whitespace, normalize-line-endings, strip-html-comments, smart-quotes, wrap=WIDTH)", spec)
+}
+
+// transformBodies returns a copy of resource with each body resolved, its
+// fragments included, and passed through transformers in order. The
+// original resource is not modified.
+func transformBodies(resource *Resource, transformers []BodyTransformer) *Resource {
+	if len(transformers) == 0 {
+		return resource
+	}
+	transform := func(body format.Body, fragments map[string]string) format.Body {
+		text := format.ResolveBody(body, fragments)
+		for _, transformer := range transformers {
+			text = transformer.TransformBody(text)
+		}
+		return format.Body{String: &text}
+	}
+
+	out := *resource
+	switch spec := resource.Spec.(type) {
+	case *format.Rule:
+		rule := *spec
+		rule.Spec.Body = transform(spec.Spec.Body, spec.Spec.Fragments)
+		out.Spec = &rule
+	case *format.Ruleset:
+		ruleset := *spec
+		ruleset.Spec.Rules = make(map[string]format.RuleItem, len(spec.Spec.Rules))
+		for id, item := range spec.Spec.Rules {
+			item.Body = transform(item.Body, spec.Spec.Fragments)
+			ruleset.Spec.Rules[id] = item
+		}
+		out.Spec = &ruleset
+	case *format.Prompt:
+		prompt := *spec
+		prompt.Spec.Body = transform(spec.Spec.Body, spec.Spec.Fragments)
+		out.Spec = &prompt
+	case *format.Promptset:
+		promptset := *spec
+		promptset.Spec.Prompts = make(map[string]format.PromptItem, len(spec.Spec.Prompts))
+		for id, item := range spec.Spec.Prompts {
+			item.Body = transform(item.Body, spec.Spec.Fragments)
+			promptset.Spec.Prompts[id] = item
+		}
+		out.Spec = &promptset
+	case *format.Command:
+		command := *spec
+		command.Spec.Body = transform(spec.Spec.Body, spec.Spec.Fragments)
+		out.Spec = &command
+	case *format.Context:
+		context := *spec
+		context.Spec.Body = transform(spec.Spec.Body, spec.Spec.Fragments)
+		out.Spec = &context
+	}
+	return &out
+}
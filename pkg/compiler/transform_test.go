@@ -0,0 +1,97 @@
+package compiler
+
+import (
+	"testing"
+
+	"github.com/jomadu/ai-resource-compiler-go/internal/format"
+)
+
+func TestBodyTransformers(t *testing.T) {
+	tests := []struct {
+		name        string
+		transformer BodyTransformer
+		body        string
+		want        string
+	}{
+		{"trim trailing whitespace", TrimTrailingWhitespace(), "Use tabs.  \n\t\nNo spaces.\t", "Use tabs.\n\nNo spaces."},
+		{"normalize line endings", NormalizeLineEndings(), "one\r\ntwo\rthree\n", "one\ntwo\nthree\n"},
+		{
+			"strip html comments",
+			StripHTMLComments(),
+			"Intro <!-- todo -->text.\n<!-- reviewed\nby legal -->\nRule.\n```html\n<!-- kept -->\n```\n",
+			"Intro text.\nRule.\n```html\n<!-- kept -->\n```\n",
+		},
+		{"smart quotes", ReplaceSmartQuotes(), "Say “don’t” and ‘no’.", `Say "don't" and 'no'.`},
+		{
+			"wrap lines",
+			WrapLines(20),
+			"Keep functions short and focused.\n- Name things for what they do.\n> Quoted advice is wrapped too.\n# A heading longer than twenty\n```\nfunc aVeryLongFunctionName(argument string) {}\n```",
+			"Keep functions short\nand focused.\n- Name things for\n  what they do.\n> Quoted advice is\n> wrapped too.\n# A heading longer than twenty\n```\nfunc aVeryLongFunctionName(argument string) {}\n```",
+		},
+		{"wrap long word", WrapLines(5), "a https://example.com b", "a\nhttps://example.com\nb"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.transformer.TransformBody(tt.body); got != tt.want {
+				t.Errorf("TransformBody() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseTransformer(t *testing.T) {
+	for _, spec := range []string{"trim-trailing-whitespace", "normalize-line-endings", "strip-html-comments", "smart-quotes", "wrap=80"} {
+		if _, err := ParseTransformer(spec); err != nil {
+			t.Errorf("ParseTransformer(%q) error = %v", spec, err)
+		}
+	}
+	for _, spec := range []string{"wrap", "wrap=0", "wrap=wide", "uppercase"} {
+		if _, err := ParseTransformer(spec); err == nil {
+			t.Errorf("ParseTransformer(%q) succeeded, want an error", spec)
+		}
+	}
+}
+
+func TestCompile_Transformers(t *testing.T) {
+	c := NewCompiler(WithoutDefaults(), WithTargets(&bodyCompiler{}))
+	resource := &Resource{APIVersion: "ai-resource/draft", Kind: "Ruleset", Spec: &format.Ruleset{
+		Metadata: format.Metadata{ID: "rules"},
+		Spec: format.RulesetSpec{
+			Fragments: map[string]string{"footer": "See the “style guide”.  "},
+			Rules: map[string]format.RuleItem{
+				"naming": {Name: "Naming", Enforcement: "must", Body: format.Body{Array: []string{"Name things well.  ", "$footer"}}},
+			},
+		},
+	}}
+	resource.Metadata.ID = "rules"
+	original := resource.Spec.(*format.Ruleset).Spec.Rules["naming"].Body.Array[0]
+
+	results, err := c.Compile(resource, CompileOptions{
+		Targets:      []Target{TargetMarkdown},
+		Transformers: []BodyTransformer{TrimTrailingWhitespace(), ReplaceSmartQuotes()},
+	})
+	if err != nil {
+		t.Fatalf("Compile() error = %v", err)
+	}
+	want := "Name things well.\n\nSee the \"style guide\"."
+	if len(results) != 1 || results[0].Content != want {
+		t.Errorf("results = %+v, want body %q", results, want)
+	}
+	if got := resource.Spec.(*format.Ruleset).Spec.Rules["naming"].Body.Array[0]; got != original {
+		t.Errorf("resource modified: body = %q", got)
+	}
+}
+
+// bodyCompiler compiles each rule of a resource to its resolved body.
+type bodyCompiler struct {
+	mockMarkdownCompiler
+}
+
+func (m *bodyCompiler) Compile(resource *Resource) ([]CompilationResult, error) {
+	var results []CompilationResult
+	ruleset := resource.Spec.(*format.Ruleset)
+	for _, id := range ruleset.Spec.RuleIDs() {
+		results = append(results, CompilationResult{Path: id + ".md", Content: format.ResolveBody(ruleset.Spec.Rules[id].Body, ruleset.Spec.Fragments)})
+	}
+	return results, nil
+}
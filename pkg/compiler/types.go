@@ -9,15 +9,142 @@ const (
 	TargetClaude   Target = "claude"
 	TargetCopilot  Target = "copilot"
 	TargetMarkdown Target = "markdown"
+	TargetGemini   Target = "gemini"
+	TargetJSON     Target = "json"
+	TargetAgentsMD Target = "agentsmd"
 )
 
 // CompileOptions configures compilation behavior.
 type CompileOptions struct {
 	Targets []Target
+
+	// Variables are substituted for ${name} references in bodies and
+	// fragments before target compilation. Undefined references are left as is.
+	Variables map[string]string
+
+	// Templates executes bodies and fragments as Go text/template templates,
+	// after variables are substituted, with TemplateData as dot. Besides the
+	// built-in functions, templates can call upper, lower, join (e.g.
+	// {{ .langs | join ", " }}), include (a fragment by name), now, and env.
+	// A missing map key is an error.
+	Templates    bool
+	TemplateData any
+
+	// TemplateEnv names the environment variables templates may read with
+	// env; reading any other is an error, so resources, remote ones among
+	// them, cannot read the rest of the environment.
+	TemplateEnv []string
+
+	// Transformers rewrite each body, with its fragments resolved, in
+	// order, after variables and templates are expanded and before targets
+	// format it. See TrimTrailingWhitespace, WrapLines, and the other
+	// built-in transformers, and ParseTransformer.
+	Transformers []BodyTransformer
+
+	// LinkResources are resources whose rules bodies may link to, with
+	// [text](#rule:ref), besides those of the resources compiled. Each link
+	// is rewritten to the file its target compiles the rule to; see
+	// LinkedRules. CompileAll links between the resources it compiles
+	// without them.
+	LinkResources []*Resource
+
+	// Locale selects the body variant each rule and prompt compiles with,
+	// from its bodies map. Items without a variant for Locale, and every
+	// item when Locale is empty, compile with their default body.
+	Locale string
+
+	// TargetOptions holds target-specific options keyed by target. Options
+	// are passed to targets implementing ConfigurableTarget; setting options
+	// for any other target is an error.
+	TargetOptions map[Target]map[string]any
+
+	// Lean omits the metadata block from rules for every target, emitting
+	// only the enforcement header and body. It sets the "lean" option of each
+	// ConfigurableTarget unless TargetOptions sets it explicitly; other
+	// targets ignore it.
+	Lean bool
+
+	// EmbedSource appends a reference to each rule's source to its compiled
+	// content for every target: "path" adds a comment naming the source file
+	// and "yaml" adds the rule's source YAML in a collapsed section. Like Lean,
+	// it sets the "embedSource" option of each ConfigurableTarget unless
+	// TargetOptions sets it explicitly.
+	EmbedSource string
+
+	// Only compiles just the rules or prompts with these IDs, of a Ruleset or
+	// Promptset or standalone, and Exclude skips the ones with these IDs.
+	// Resources Only names nothing of compile to nothing, and Commands and
+	// Contexts ignore both. CompileAll returns ErrUnknownItem for an ID none
+	// of its resources has; see CheckItems for builds compiling resources
+	// one at a time.
+	Only    []string
+	Exclude []string
+
+	// MinEnforcement compiles only the rules whose enforcement level ranks
+	// at or above it, e.g. "should" for should and must rules, to keep a
+	// slim set for tools with little context. A Ruleset left without rules,
+	// or a Rule below it, compiles to nothing. Prompts, commands, and
+	// contexts are unaffected.
+	MinEnforcement string
+
+	// Tags compiles only the rules and prompts carrying at least one of
+	// these tags, to build a project's subset of a shared library. A rule
+	// or prompt carries the tags of its Ruleset or Promptset and its own;
+	// other resources carry their metadata tags. A resource left with
+	// nothing compiles to nothing.
+	Tags []string
+
+	// TokenBudget, if positive, is the number of tokens a compiled file
+	// may take. Larger files are still compiled, but logged as warnings,
+	// since oversized rules crowd out the agent's working context. See
+	// CompilationResult.Stats for the count of every file.
+	TokenBudget int
+
+	// PathTemplate rewrites each result path. It may reference {dir}, the
+	// path's directory; {file}, its file name; {name} and {ext}, the file
+	// name before and from its first dot; and {target}. For example,
+	// "{dir}/arc/{file}" moves every file into an arc subdirectory. Names
+	// it produces that some platform forbids have the offending characters
+	// replaced and a hash of the original appended.
+	PathTemplate string
+
+	// Prefix is prepended to the file name of each result path, after
+	// PathTemplate is applied, e.g. "org-" to tell generated files apart
+	// from hand-written ones. Files tools find by name keep it: a skill's
+	// directory is prefixed instead of its SKILL.md, and context files
+	// such as CLAUDE.md are not prefixed.
+	Prefix string
+
+	// CollectErrors reports every problem instead of stopping at the first:
+	// Compile validates each rule and prompt, and compiles for each target,
+	// returning the problems found as CompileErrors.
+	CollectErrors bool
 }
 
 // CompilationResult contains compiled output.
 type CompilationResult struct {
+	// Path is relative and slash-separated on every platform, e.g.
+	// "testPrompt/SKILL.md". Convert it with filepath.FromSlash before
+	// joining it with an output directory.
 	Path    string
 	Content string
+
+	// Asset marks a file shipped with a prompt from its spec.assets. Its
+	// Content is the file's bytes as they are, which may be binary, so it
+	// takes no tokens and is not given a banner.
+	Asset bool
+
+	// Stats describes Content. Compile, CompileAll, and Merge set it.
+	Stats ResultStats
+}
+
+// ResultStats describes a compiled file.
+type ResultStats struct {
+	// Bytes is the size of the content.
+	Bytes int `json:"bytes"`
+
+	// Tokens is the number of model tokens the content takes, as counted
+	// by the compiler's Tokenizer: an estimate unless WithTokenizer plugs
+	// in a real one.
+	Tokens int `json:"tokens"`
 }
@@ -0,0 +1,243 @@
+package compiler
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/jomadu/ai-resource-compiler-go/internal/format"
+	"github.com/jomadu/ai-resource-compiler-go/pkg/resource"
+)
+
+// Validate checks resource without compiling it and returns every problem
+// found, each naming the field it is in: missing required fields, invalid
+// IDs, rule names, command arguments, and asset names, unknown enforcement levels,
+// fragment and argument references nothing defines, and malformed scope
+// globs. Resources decoded from YAML are also checked against the JSON
+// Schema of the format, for unknown fields and values of the wrong type;
+// see resource.CheckSchema. Compile stops at the first
+// problem it rejects, and accepts missing enforcement, fragment references,
+// and scope globs, so Validate is stricter than Compile. Enforcement levels
+// are those registered; see RegisterEnforcementLevel.
+func Validate(resource *Resource) []*ValidationError {
+	v := &validator{strict: true}
+	v.resource(resource)
+	v.schema(resource)
+	return v.errs
+}
+
+// Validator checks a resource against rules of its own, such as a naming
+// convention or a required tag, returning every problem found. Validators
+// are added with WithValidator and run by Compile, CompileTo, Explain, and
+// Import, which reject the resource if any problem is returned, and by
+// Compiler.Validate.
+type Validator interface {
+	Validate(resource *Resource) []*ValidationError
+}
+
+// ValidatorFunc adapts a function to Validator.
+type ValidatorFunc func(resource *Resource) []*ValidationError
+
+// Validate implements Validator.
+func (f ValidatorFunc) Validate(resource *Resource) []*ValidationError {
+	return f(resource)
+}
+
+// Validate returns the problems the package Validate finds in resource and
+// those of the compiler's validators.
+func (c *Compiler) Validate(resource *Resource) []*ValidationError {
+	problems := Validate(resource)
+	for _, validator := range c.validators {
+		problems = append(problems, validator.Validate(resource)...)
+	}
+	return problems
+}
+
+// validator collects the problems Validate finds. Unless strict, it skips
+// the checks Compile does not enforce.
+type validator struct {
+	strict bool
+	errs   []*ValidationError
+}
+
+func (v *validator) resource(resource *Resource) {
+	if resource.APIVersion == "" {
+		v.add("apiVersion", "", "missing apiVersion")
+	}
+	if resource.Kind == "" {
+		v.add("kind", "", "missing kind")
+	}
+	if resource.Metadata.ID == "" {
+		v.add("metadata.id", "", "missing metadata.id")
+	} else {
+		v.check("metadata.id", format.ValidateID(resource.Metadata.ID))
+	}
+	v.check("metadata.namespace", format.ValidateNamespace(resource.Metadata.Namespace))
+
+	switch spec := resource.Spec.(type) {
+	case *format.Rule:
+		v.check("metadata.name", format.ValidateRuleName(spec.Metadata.Name))
+		v.rule("spec", resource.Metadata.ID, spec.Spec.Enforcement, spec.Spec.Scope)
+		v.bodies("spec", spec.Spec.Body, spec.Spec.Bodies, spec.Spec.Fragments)
+	case *format.Ruleset:
+		if len(spec.Spec.Extends) > 0 {
+			// Its rules may override inherited ones in part, so they are
+			// only checked once extends is resolved.
+			v.add("spec.extends", strings.Join(spec.Spec.Extends, ", "), "unresolved extends; resolve it with the rulesets extended, as CompileAll and ResolveExtends do")
+			return
+		}
+		for _, id := range spec.Spec.RuleIDs() {
+			item := spec.Spec.Rules[id]
+			field := "spec.rules." + id
+			v.itemID("spec.rules", id)
+			v.check(field+".name", format.ValidateRuleName(item.Name))
+			v.rule(field, resource.Metadata.ID+"/"+id, item.Enforcement, item.Scope)
+			v.bodies(field, item.Body, item.Bodies, spec.Spec.Fragments)
+		}
+	case *format.Prompt:
+		v.bodies("spec", spec.Spec.Body, spec.Spec.Bodies, spec.Spec.Fragments)
+		v.check("spec.assets", format.ValidateAssets(spec.Spec.Assets, "spec.assets", resource.Metadata.ID))
+	case *format.Promptset:
+		for _, id := range spec.Spec.PromptIDs() {
+			item := spec.Spec.Prompts[id]
+			field := "spec.prompts." + id
+			v.itemID("spec.prompts", id)
+			v.bodies(field, item.Body, item.Bodies, spec.Spec.Fragments)
+			assets := append(append([]format.Asset(nil), spec.Spec.Assets...), item.Assets...)
+			v.check(field+".assets", format.ValidateAssets(assets, field+".assets", resource.Metadata.ID+"/"+id))
+		}
+	case *format.Command:
+		v.arguments(spec)
+		v.bodies("spec", spec.Spec.Body, spec.Spec.Bodies, spec.Spec.Fragments)
+	case *format.Context:
+		v.bodies("spec", spec.Spec.Body, spec.Spec.Bodies, spec.Spec.Fragments)
+	}
+}
+
+// schema records the problems resource.CheckSchema finds in the document r
+// was decoded from, other than those in fields already reported. Missing
+// fields are not recorded in the rules of a ruleset that extends others,
+// which may override inherited rules in part.
+func (v *validator) schema(r *Resource) {
+	if r.Node == nil {
+		return
+	}
+	extends := len(r.Bases) > 0
+	if ruleset, ok := r.Spec.(*format.Ruleset); ok && len(ruleset.Spec.Extends) > 0 {
+		extends = true
+	}
+	reported := make(map[string]bool, len(v.errs))
+	for _, err := range v.errs {
+		reported[err.Field] = true
+	}
+	for _, err := range resource.CheckSchema(r.Node) {
+		if err.Missing && extends && strings.HasPrefix(err.Field, "spec.rules.") {
+			continue
+		}
+		if !reported[err.Field] {
+			v.add(err.Field, "", err.Message)
+		}
+	}
+}
+
+func (v *validator) add(field, value, message string) {
+	v.errs = append(v.errs, &ValidationError{Field: field, Value: value, Message: message})
+}
+
+// check records err, if any, as a problem in field.
+func (v *validator) check(field string, err error) {
+	if err == nil {
+		return
+	}
+	var validationErr *ValidationError
+	if errors.As(err, &validationErr) {
+		v.add(field, validationErr.Value, validationErr.Message)
+		return
+	}
+	v.add(field, "", err.Error())
+}
+
+// itemID checks the ID of a rule or prompt of a collection. The problem is
+// recorded in the collection, with the ID as its value, as the ID is a key
+// there.
+func (v *validator) itemID(collection, id string) {
+	if err := format.ValidateID(id); err != nil {
+		v.check(collection, err)
+		v.errs[len(v.errs)-1].Value = id
+	}
+}
+
+// rule checks the enforcement and scope of the rule named ref at field.
+func (v *validator) rule(field, ref, enforcement string, scope []format.ScopeEntry) {
+	v.check(field+".scope", format.ValidateScope(scope, field+".scope", ref))
+	if _, ok := LookupEnforcement(enforcement); !ok && enforcement != "" {
+		v.add(field+".enforcement", enforcement, fmt.Sprintf("rule '%s' has unknown enforcement %q (expected %s)", ref, enforcement, expectedEnforcement()))
+	}
+	if !v.strict {
+		return
+	}
+
+	if enforcement == "" {
+		v.add(field+".enforcement", "", fmt.Sprintf("rule '%s' is missing enforcement", ref))
+	}
+
+	for _, entry := range scope {
+		for _, pattern := range append(append([]string(nil), entry.Files...), entry.Exclude...) {
+			if _, err := path.Match(pattern, ""); err != nil {
+				v.add(field+".scope", pattern, fmt.Sprintf("rule '%s' has a malformed scope glob %q", ref, pattern))
+			}
+		}
+	}
+}
+
+// arguments checks the argument names of command and that its bodies refer
+// only to declared arguments.
+func (v *validator) arguments(command *format.Command) {
+	declared := make(map[string]bool)
+	for _, arg := range command.Spec.Arguments {
+		switch {
+		case format.ValidateID(arg.Name) != nil:
+			v.add("spec.arguments", arg.Name, fmt.Sprintf("argument name %q may only contain letters, digits, '-', and '_'", arg.Name))
+		case declared[arg.Name]:
+			v.add("spec.arguments", arg.Name, fmt.Sprintf("duplicate argument %s", arg.Name))
+		}
+		declared[arg.Name] = true
+	}
+
+	check := func(field string, body format.Body) {
+		for _, name := range format.ArgumentReferences(format.ResolveBody(body, command.Spec.Fragments)) {
+			if !declared[name] {
+				ref := "${arg:" + name + "}"
+				v.add(field, ref, fmt.Sprintf("unknown argument %s (declare it in spec.arguments)", ref))
+			}
+		}
+	}
+	check("spec.body", command.Spec.Body)
+	for _, locale := range format.SortedKeys(command.Spec.Bodies) {
+		check("spec.bodies."+locale, command.Spec.Bodies[locale])
+	}
+}
+
+// bodies checks the fragment references of a body and its translations.
+func (v *validator) bodies(field string, body format.Body, bodies map[string]format.Body, fragments map[string]string) {
+	if !v.strict {
+		return
+	}
+	v.references(field+".body", body, fragments)
+	for _, locale := range format.SortedKeys(bodies) {
+		v.references(field+".bodies."+locale, bodies[locale], fragments)
+	}
+}
+
+func (v *validator) references(field string, body format.Body, fragments map[string]string) {
+	for _, part := range body.Array {
+		name, ok := strings.CutPrefix(part, "$")
+		if !ok {
+			continue
+		}
+		if _, defined := fragments[name]; !defined {
+			v.add(field, part, fmt.Sprintf("unknown fragment %s", part))
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package compiler
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jomadu/ai-resource-compiler-go/internal/format"
+	"gopkg.in/yaml.v3"
+)
+
+func TestValidate(t *testing.T) {
+	resource := &Resource{
+		APIVersion: "ai-resource/draft",
+		Kind:       "Ruleset",
+		Spec: &format.Ruleset{Spec: format.RulesetSpec{
+			Rules: map[string]format.RuleItem{
+				"bad id": {Enforcement: "must", Body: format.Body{String: strPtr("Body")}},
+				"naming": {
+					Name:        "Naming (strict)",
+					Enforcement: "always",
+					Scope:       []format.ScopeEntry{{Files: []string{"src/[a"}}},
+					Body:        format.Body{Array: []string{"$header", "$missing"}},
+					Bodies:      map[string]format.Body{"es": {Array: []string{"$gone"}}},
+				},
+			},
+			Fragments: map[string]string{"header": "Header"},
+		}},
+	}
+	resource.Metadata.ID = "rules"
+
+	want := []ValidationError{
+		{Field: "spec.rules", Value: "bad id"},
+		{Field: "spec.rules.naming.name", Value: "Naming (strict)"},
+		{Field: "spec.rules.naming.enforcement", Value: "always"},
+		{Field: "spec.rules.naming.scope", Value: "src/[a"},
+		{Field: "spec.rules.naming.body", Value: "$missing"},
+		{Field: "spec.rules.naming.bodies.es", Value: "$gone"},
+	}
+	got := Validate(resource)
+	if len(got) != len(want) {
+		t.Fatalf("Validate() returned %d problems, want %d: %v", len(got), len(want), got)
+	}
+	for i, w := range want {
+		if got[i].Field != w.Field || got[i].Value != w.Value {
+			t.Errorf("problem %d = %s %q (%s), want %s %q", i, got[i].Field, got[i].Value, got[i].Message, w.Field, w.Value)
+		}
+	}
+}
+
+func TestValidateMissingFields(t *testing.T) {
+	got := Validate(&Resource{Spec: &format.Prompt{}})
+	var fields []string
+	for _, p := range got {
+		fields = append(fields, p.Field)
+	}
+	if len(fields) != 3 || fields[0] != "apiVersion" || fields[1] != "kind" || fields[2] != "metadata.id" {
+		t.Errorf("Validate() fields = %v, want apiVersion, kind, metadata.id", fields)
+	}
+}
+
+func TestValidateCommandArguments(t *testing.T) {
+	resource := &Resource{
+		APIVersion: "ai-resource/draft",
+		Kind:       "Command",
+		Spec: &format.Command{Spec: format.CommandSpec{
+			Arguments: []format.CommandArgument{{Name: "issue"}, {Name: "issue"}, {Name: "bad name"}},
+			Body:      format.Body{String: strPtr("Fix ${arg:issue} on ${arg:branch}.")},
+		}},
+	}
+	resource.Metadata.ID = "fixIssue"
+
+	want := []ValidationError{
+		{Field: "spec.arguments", Value: "issue"},
+		{Field: "spec.arguments", Value: "bad name"},
+		{Field: "spec.body", Value: "${arg:branch}"},
+	}
+	got := Validate(resource)
+	if len(got) != len(want) {
+		t.Fatalf("Validate() returned %d problems, want %d: %v", len(got), len(want), got)
+	}
+	for i, w := range want {
+		if got[i].Field != w.Field || got[i].Value != w.Value {
+			t.Errorf("problem %d = %s %q (%s), want %s %q", i, got[i].Field, got[i].Value, got[i].Message, w.Field, w.Value)
+		}
+	}
+}
+
+func TestValidateSchema(t *testing.T) {
+	var resource Resource
+	err := yaml.Unmarshal([]byte(`apiVersion: ai-resource/draft
+kind: Ruleset
+metadata:
+  id: rules
+spec:
+  rules:
+    naming:
+      enforcment: must
+      body: Body
+`), &resource)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var fields []string
+	for _, p := range Validate(&resource) {
+		fields = append(fields, p.Field)
+	}
+	if strings.Join(fields, ", ") != "spec.rules.naming.enforcement, spec.rules.naming.enforcment" {
+		t.Errorf("Validate() fields = %v, want the missing enforcement once and the unknown field", fields)
+	}
+	if line, _ := resource.Position("spec.rules.naming.enforcement", ""); line != 8 {
+		t.Errorf("missing field line = %d, want 8, that of its rule", line)
+	}
+}
@@ -0,0 +1,92 @@
+package compiler
+
+import (
+	"strings"
+
+	"github.com/jomadu/ai-resource-compiler-go/internal/format"
+)
+
+// expandVariables returns a copy of resource with ${name} references in
+// bodies and fragments replaced by values from vars. The original resource
+// is not modified.
+func expandVariables(resource *Resource, vars map[string]string) *Resource {
+	if len(vars) == 0 {
+		return resource
+	}
+
+	out := *resource
+	switch spec := resource.Spec.(type) {
+	case *format.Rule:
+		rule := *spec
+		rule.Spec.Body = expandBody(spec.Spec.Body, vars)
+		rule.Spec.Fragments = expandFragments(spec.Spec.Fragments, vars)
+		out.Spec = &rule
+	case *format.Ruleset:
+		ruleset := *spec
+		ruleset.Spec.Rules = make(map[string]format.RuleItem, len(spec.Spec.Rules))
+		for id, item := range spec.Spec.Rules {
+			item.Body = expandBody(item.Body, vars)
+			ruleset.Spec.Rules[id] = item
+		}
+		ruleset.Spec.Fragments = expandFragments(spec.Spec.Fragments, vars)
+		out.Spec = &ruleset
+	case *format.Prompt:
+		prompt := *spec
+		prompt.Spec.Body = expandBody(spec.Spec.Body, vars)
+		prompt.Spec.Fragments = expandFragments(spec.Spec.Fragments, vars)
+		out.Spec = &prompt
+	case *format.Promptset:
+		promptset := *spec
+		promptset.Spec.Prompts = make(map[string]format.PromptItem, len(spec.Spec.Prompts))
+		for id, item := range spec.Spec.Prompts {
+			item.Body = expandBody(item.Body, vars)
+			promptset.Spec.Prompts[id] = item
+		}
+		promptset.Spec.Fragments = expandFragments(spec.Spec.Fragments, vars)
+		out.Spec = &promptset
+	case *format.Command:
+		command := *spec
+		command.Spec.Body = expandBody(spec.Spec.Body, vars)
+		command.Spec.Fragments = expandFragments(spec.Spec.Fragments, vars)
+		out.Spec = &command
+	case *format.Context:
+		context := *spec
+		context.Spec.Body = expandBody(spec.Spec.Body, vars)
+		context.Spec.Fragments = expandFragments(spec.Spec.Fragments, vars)
+		out.Spec = &context
+	}
+	return &out
+}
+
+// expandBody expands variables in literal body content. Fragment references
+// ($name) are left for fragment resolution; their content is expanded via
+// expandFragments.
+func expandBody(body format.Body, vars map[string]string) format.Body {
+	if body.String != nil {
+		s := format.ExpandVariables(*body.String, vars)
+		return format.Body{String: &s}
+	}
+	if body.Array == nil {
+		return body
+	}
+	arr := make([]string, len(body.Array))
+	for i, part := range body.Array {
+		if strings.HasPrefix(part, "$") && !strings.HasPrefix(part, "${") {
+			arr[i] = part
+			continue
+		}
+		arr[i] = format.ExpandVariables(part, vars)
+	}
+	return format.Body{Array: arr}
+}
+
+func expandFragments(fragments map[string]string, vars map[string]string) map[string]string {
+	if fragments == nil {
+		return nil
+	}
+	out := make(map[string]string, len(fragments))
+	for name, content := range fragments {
+		out[name] = format.ExpandVariables(content, vars)
+	}
+	return out
+}
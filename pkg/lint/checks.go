@@ -0,0 +1,255 @@
+package lint
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jomadu/ai-resource-compiler-go/internal/format"
+	"github.com/jomadu/ai-resource-compiler-go/pkg/compiler"
+)
+
+// DefaultMaxTokens is the body budget of LongBody when MaxTokens is not set.
+const DefaultMaxTokens = 2000
+
+// DuplicateID reports resources of the same kind and ID defined in more
+// than one file, which compile to the same output files.
+type DuplicateID struct{}
+
+func (DuplicateID) Name() string { return "duplicate-id" }
+
+func (DuplicateID) Run(lib *Library) ([]Finding, error) {
+	first := make(map[string]*compiler.Resource)
+	var findings []Finding
+	for _, resource := range lib.Resources {
+		key := resource.Kind + " " + resource.Metadata.Namespace + "/" + resource.Metadata.ID
+		original, ok := first[key]
+		if !ok {
+			first[key] = resource
+			continue
+		}
+		if original.Source == resource.Source {
+			continue
+		}
+		findings = append(findings, Finding{
+			Severity: SeverityError,
+			Resource: resource,
+			Field:    "metadata.id",
+			Value:    resource.Metadata.ID,
+			Message:  fmt.Sprintf("%s %s is also defined in %s", resource.Kind, resource.Metadata.ID, original.Source),
+		})
+	}
+	return findings, nil
+}
+
+// EmptyBody reports rules, prompts, commands, and contexts whose body, or a
+// translation of it, is empty once fragments are resolved.
+type EmptyBody struct{}
+
+func (EmptyBody) Name() string { return "empty-body" }
+
+func (EmptyBody) Run(lib *Library) ([]Finding, error) {
+	var findings []Finding
+	for _, resource := range lib.Resources {
+		for _, it := range items(resource) {
+			it.variants(func(field, content string) {
+				if strings.TrimSpace(content) == "" {
+					findings = append(findings, Finding{
+						Severity: SeverityError,
+						Resource: resource,
+						Field:    field,
+						Message:  fmt.Sprintf("%s %s has an empty body", it.kind, it.ref),
+					})
+				}
+			})
+		}
+	}
+	return findings, nil
+}
+
+// LongBody reports bodies estimated to take more than MaxTokens tokens,
+// which crowd out the agent's working context.
+type LongBody struct {
+	// MaxTokens is the budget of a body; DefaultMaxTokens if zero.
+	MaxTokens int
+}
+
+func (LongBody) Name() string { return "long-body" }
+
+func (c LongBody) Run(lib *Library) ([]Finding, error) {
+	budget := c.MaxTokens
+	if budget <= 0 {
+		budget = DefaultMaxTokens
+	}
+	var findings []Finding
+	for _, resource := range lib.Resources {
+		for _, it := range items(resource) {
+			it.variants(func(field, content string) {
+				if tokens := format.EstimateTokens(content); tokens > budget {
+					findings = append(findings, Finding{
+						Severity: SeverityWarning,
+						Resource: resource,
+						Field:    field,
+						Message:  fmt.Sprintf("%s %s body is about %d tokens, over the budget of %d", it.kind, it.ref, tokens, budget),
+					})
+				}
+			})
+		}
+	}
+	return findings, nil
+}
+
+// MissingDescription reports rules, prompts, commands, and contexts without
+// a description, which tools show and agents use to decide what to load.
+type MissingDescription struct{}
+
+func (MissingDescription) Name() string { return "missing-description" }
+
+func (MissingDescription) Run(lib *Library) ([]Finding, error) {
+	var findings []Finding
+	for _, resource := range lib.Resources {
+		for _, it := range items(resource) {
+			if it.hasDescription && strings.TrimSpace(it.description) == "" {
+				findings = append(findings, Finding{
+					Severity: SeverityWarning,
+					Resource: resource,
+					Field:    it.descriptionField,
+					Message:  fmt.Sprintf("%s %s has no description", it.kind, it.ref),
+				})
+			}
+		}
+	}
+	return findings, nil
+}
+
+// UnusedFragment reports fragments no body or translation refers to.
+// Fragments merged from a fragment library are not reported, and those of
+// a ruleset count as used when a ruleset extending it uses them. Rulesets
+// with resolved extends are not reported, as their fragments include the
+// inherited ones.
+type UnusedFragment struct{}
+
+func (UnusedFragment) Name() string { return "unused-fragment" }
+
+func (UnusedFragment) Run(lib *Library) ([]Finding, error) {
+	// used holds the fragments referred to, by the file defining them.
+	used := make(map[string]map[string]bool)
+	var mark func(resource *compiler.Resource, name string)
+	mark = func(resource *compiler.Resource, name string) {
+		if used[resource.Source] == nil {
+			used[resource.Source] = make(map[string]bool)
+		}
+		used[resource.Source][name] = true
+		for _, base := range resource.Bases {
+			mark(base, name)
+		}
+	}
+	for _, resource := range lib.Resources {
+		for _, it := range items(resource) {
+			for _, body := range append([]format.Body{it.body}, bodyValues(it.bodies)...) {
+				for _, part := range body.Array {
+					if name, ok := strings.CutPrefix(part, "$"); ok {
+						mark(resource, name)
+					}
+				}
+			}
+		}
+	}
+
+	var findings []Finding
+	for _, resource := range lib.Resources {
+		if len(resource.Bases) > 0 {
+			continue
+		}
+		fragments := fragmentsOf(resource)
+		for _, name := range format.SortedKeys(fragments) {
+			if _, included := resource.IncludedFragments[name]; included || used[resource.Source][name] {
+				continue
+			}
+			findings = append(findings, Finding{
+				Severity: SeverityWarning,
+				Resource: resource,
+				Field:    "spec.fragments." + name,
+				Message:  fmt.Sprintf("fragment %s is not used", name),
+			})
+		}
+	}
+	return findings, nil
+}
+
+// UnmatchedScope reports scope globs, including those languages and
+// directories stand for, that match no file under the library's Root: a
+// typo, or a rule for code the repository does not have. It is skipped
+// when Root is empty.
+type UnmatchedScope struct{}
+
+func (UnmatchedScope) Name() string { return "unmatched-scope" }
+
+func (UnmatchedScope) Run(lib *Library) ([]Finding, error) {
+	if lib.Root == "" {
+		return nil, nil
+	}
+	files, err := lib.Files()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list files under %s: %w", lib.Root, err)
+	}
+
+	matched := make(map[string]bool)
+	matches := func(glob string) bool {
+		if m, ok := matched[glob]; ok {
+			return m
+		}
+		matched[glob] = false
+		for _, file := range files {
+			if format.MatchGlob(glob, file) {
+				matched[glob] = true
+				break
+			}
+		}
+		return matched[glob]
+	}
+
+	var findings []Finding
+	for _, resource := range lib.Resources {
+		for _, it := range items(resource) {
+			for _, glob := range format.ScopeIncludes(it.scope) {
+				if !matches(glob) {
+					findings = append(findings, Finding{
+						Severity: SeverityWarning,
+						Resource: resource,
+						Field:    it.field + ".scope",
+						Value:    glob,
+						Message:  fmt.Sprintf("%s %s scope %q matches no file", it.kind, it.ref, glob),
+					})
+				}
+			}
+		}
+	}
+	return findings, nil
+}
+
+func bodyValues(bodies map[string]format.Body) []format.Body {
+	values := make([]format.Body, 0, len(bodies))
+	for _, locale := range format.SortedKeys(bodies) {
+		values = append(values, bodies[locale])
+	}
+	return values
+}
+
+// fragmentsOf returns the fragments of resource.
+func fragmentsOf(resource *compiler.Resource) map[string]string {
+	switch spec := resource.Spec.(type) {
+	case *format.Rule:
+		return spec.Spec.Fragments
+	case *format.Ruleset:
+		return spec.Spec.Fragments
+	case *format.Prompt:
+		return spec.Spec.Fragments
+	case *format.Promptset:
+		return spec.Spec.Fragments
+	case *format.Command:
+		return spec.Spec.Fragments
+	case *format.Context:
+		return spec.Spec.Fragments
+	}
+	return nil
+}
@@ -0,0 +1,241 @@
+// Package lint checks a library of resources for problems validation lets
+// through but that make the compiled rules worse for agents: IDs defined in
+// more than one file, empty or overly long bodies, missing descriptions,
+// unused fragments, and scopes that match no file in the repository.
+//
+// Each problem is found by a Check. DefaultChecks returns the built-in
+// ones; pass others to New to add project-specific checks.
+package lint
+
+import (
+	"io/fs"
+	"path/filepath"
+	"sort"
+
+	"github.com/jomadu/ai-resource-compiler-go/internal/format"
+	"github.com/jomadu/ai-resource-compiler-go/pkg/compiler"
+)
+
+// Severity is how serious a finding is.
+type Severity string
+
+const (
+	// SeverityError marks a problem that breaks compiled output, such as
+	// two files writing the same rule.
+	SeverityError Severity = "error"
+	// SeverityWarning marks a problem that weakens compiled output.
+	SeverityWarning Severity = "warning"
+)
+
+// Finding is a problem a check found in a resource.
+type Finding struct {
+	// Check is the name of the check that found the problem, e.g.
+	// "empty-body".
+	Check    string
+	Severity Severity
+
+	// Resource is the resource the problem is in. Field is the dotted path
+	// of the field concerned, e.g. "spec.rules.naming.body", and Value the
+	// offending value, if any, as in compiler.ValidationError; both locate
+	// the problem with Resource.Position.
+	Resource *compiler.Resource
+	Field    string
+	Value    string
+
+	Message string
+}
+
+// Check finds one kind of problem in a library.
+type Check interface {
+	// Name identifies the check in findings and configuration, e.g.
+	// "unused-fragment".
+	Name() string
+
+	// Run returns the problems found in lib. Findings need not set Check.
+	Run(lib *Library) ([]Finding, error)
+}
+
+// Library is the set of resources linted together, such as the resource
+// files of a workspace.
+type Library struct {
+	// Resources are the resources to lint, with Source set to the file
+	// each was loaded from.
+	Resources []*compiler.Resource
+
+	// Root is the repository the rules apply to, whose files scope globs
+	// are matched against. Checks that need the repository's files are
+	// skipped when it is empty.
+	Root string
+
+	files  []string
+	listed bool
+}
+
+// Files returns the paths of the files under Root, relative to it and
+// slash-separated, skipping the .git directory. They are listed once, when
+// a check first asks for them.
+func (l *Library) Files() ([]string, error) {
+	if l.listed || l.Root == "" {
+		return l.files, nil
+	}
+	err := filepath.WalkDir(l.Root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if d.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		rel, err := filepath.Rel(l.Root, p)
+		if err != nil {
+			return err
+		}
+		l.files = append(l.files, filepath.ToSlash(rel))
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	l.listed = true
+	return l.files, nil
+}
+
+// Linter runs checks over libraries.
+type Linter struct {
+	checks []Check
+}
+
+// New returns a linter running checks, or DefaultChecks if none are given.
+func New(checks ...Check) *Linter {
+	if len(checks) == 0 {
+		checks = DefaultChecks()
+	}
+	return &Linter{checks: checks}
+}
+
+// DefaultChecks returns the built-in checks, with LongBody at its default
+// budget.
+func DefaultChecks() []Check {
+	return []Check{
+		DuplicateID{},
+		EmptyBody{},
+		LongBody{},
+		MissingDescription{},
+		UnusedFragment{},
+		UnmatchedScope{},
+	}
+}
+
+// Checks returns the checks l runs.
+func (l *Linter) Checks() []Check {
+	return l.checks
+}
+
+// Lint runs every check over lib and returns the findings ordered by
+// resource file, then field.
+func (l *Linter) Lint(lib *Library) ([]Finding, error) {
+	var findings []Finding
+	for _, check := range l.checks {
+		found, err := check.Run(lib)
+		if err != nil {
+			return nil, err
+		}
+		for i := range found {
+			if found[i].Check == "" {
+				found[i].Check = check.Name()
+			}
+		}
+		findings = append(findings, found...)
+	}
+	sort.SliceStable(findings, func(i, j int) bool {
+		a, b := findings[i], findings[j]
+		if a.Resource.Source != b.Resource.Source {
+			return a.Resource.Source < b.Resource.Source
+		}
+		return a.Field < b.Field
+	})
+	return findings, nil
+}
+
+// item is a rule, prompt, command, or context of a resource, standalone or
+// in a collection.
+type item struct {
+	kind  string // "rule", "prompt", "command", or "context"
+	ref   string // the ID, or "collection/id" for items of a collection
+	field string // the path of the item's fields: "spec" or "spec.rules.<id>"
+
+	description      string
+	descriptionField string
+	hasDescription   bool // whether the kind has a description at all
+
+	body      format.Body
+	bodies    map[string]format.Body
+	fragments map[string]string
+	scope     []format.ScopeEntry
+}
+
+// items returns the items of resource, those of a collection in order.
+func items(resource *compiler.Resource) []item {
+	switch spec := resource.Spec.(type) {
+	case *format.Rule:
+		return []item{{
+			kind: "rule", ref: spec.Metadata.ID, field: "spec",
+			description: spec.Metadata.Description, descriptionField: "metadata.description", hasDescription: true,
+			body: spec.Spec.Body, bodies: spec.Spec.Bodies, fragments: spec.Spec.Fragments, scope: spec.Spec.Scope,
+		}}
+	case *format.Ruleset:
+		var out []item
+		for _, id := range spec.Spec.RuleIDs() {
+			rule := spec.Spec.Rules[id]
+			field := "spec.rules." + id
+			out = append(out, item{
+				kind: "rule", ref: spec.Metadata.ID + "/" + id, field: field,
+				description: rule.Description, descriptionField: field + ".description", hasDescription: true,
+				body: rule.Body, bodies: rule.Bodies, fragments: spec.Spec.Fragments, scope: rule.Scope,
+			})
+		}
+		return out
+	case *format.Prompt:
+		return []item{{
+			kind: "prompt", ref: spec.Metadata.ID, field: "spec",
+			description: spec.Metadata.Description, descriptionField: "metadata.description", hasDescription: true,
+			body: spec.Spec.Body, bodies: spec.Spec.Bodies, fragments: spec.Spec.Fragments,
+		}}
+	case *format.Promptset:
+		var out []item
+		for _, id := range spec.Spec.PromptIDs() {
+			prompt := spec.Spec.Prompts[id]
+			field := "spec.prompts." + id
+			out = append(out, item{
+				kind: "prompt", ref: spec.Metadata.ID + "/" + id, field: field,
+				description: prompt.Description, descriptionField: field + ".description", hasDescription: true,
+				body: prompt.Body, bodies: prompt.Bodies, fragments: spec.Spec.Fragments,
+			})
+		}
+		return out
+	case *format.Command:
+		return []item{{
+			kind: "command", ref: spec.Metadata.ID, field: "spec",
+			description: spec.Metadata.Description, descriptionField: "metadata.description", hasDescription: true,
+			body: spec.Spec.Body, bodies: spec.Spec.Bodies, fragments: spec.Spec.Fragments,
+		}}
+	case *format.Context:
+		return []item{{
+			kind: "context", ref: spec.Metadata.ID, field: "spec",
+			description: spec.Metadata.Description, descriptionField: "metadata.description", hasDescription: true,
+			body: spec.Spec.Body, bodies: spec.Spec.Bodies, fragments: spec.Spec.Fragments,
+		}}
+	}
+	return nil
+}
+
+// variants calls fn with the field and resolved content of its body and of
+// each translation, in locale order.
+func (it item) variants(fn func(field, content string)) {
+	fn(it.field+".body", format.ResolveBody(it.body, it.fragments))
+	for _, locale := range format.SortedKeys(it.bodies) {
+		fn(it.field+".bodies."+locale, format.ResolveBody(it.bodies[locale], it.fragments))
+	}
+}
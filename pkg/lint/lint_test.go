@@ -0,0 +1,126 @@
+package lint
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/jomadu/ai-resource-compiler-go/pkg/compiler"
+	"gopkg.in/yaml.v3"
+)
+
+func parseResource(t *testing.T, source, src string) *compiler.Resource {
+	t.Helper()
+	var resource compiler.Resource
+	if err := yaml.Unmarshal([]byte(src), &resource); err != nil {
+		t.Fatalf("failed to parse %s: %v", source, err)
+	}
+	resource.Source = source
+	return &resource
+}
+
+const securityRuleset = `apiVersion: ai-resource/draft
+kind: Ruleset
+metadata:
+  id: security
+spec:
+  fragments:
+    intro: Security first.
+    unused: Never referenced.
+  rules:
+    noSecrets:
+      description: Keep secrets out of the repository
+      enforcement: must
+      scope:
+        - files: ["**/*.go"]
+      body: [$intro, Never commit secrets.]
+    goodLogs:
+      enforcement: should
+      scope:
+        - languages: [rust]
+      body: "  "
+`
+
+// findings returns the findings as "check field" strings, in order.
+func findings(found []Finding) []string {
+	out := make([]string, len(found))
+	for i, f := range found {
+		out[i] = f.Check + " " + f.Field
+	}
+	return out
+}
+
+func TestLint(t *testing.T) {
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "main.go"), []byte("package main\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	lib := &Library{
+		Resources: []*compiler.Resource{
+			parseResource(t, "a.yaml", securityRuleset),
+			parseResource(t, "b.yaml", strings.Replace(securityRuleset, "unused: Never referenced.", "other: Also unused.", 1)),
+		},
+		Root: root,
+	}
+
+	found, err := New().Lint(lib)
+	if err != nil {
+		t.Fatalf("Lint() error = %v", err)
+	}
+	want := []string{
+		"unused-fragment spec.fragments.unused",
+		"empty-body spec.rules.goodLogs.body",
+		"missing-description spec.rules.goodLogs.description",
+		"unmatched-scope spec.rules.goodLogs.scope",
+		"duplicate-id metadata.id",
+		"unused-fragment spec.fragments.other",
+		"empty-body spec.rules.goodLogs.body",
+		"missing-description spec.rules.goodLogs.description",
+		"unmatched-scope spec.rules.goodLogs.scope",
+	}
+	if got := findings(found); !reflect.DeepEqual(got, want) {
+		t.Errorf("Lint() = %v, want %v", got, want)
+	}
+	if f := found[4]; f.Severity != SeverityError || f.Resource.Source != "b.yaml" || !strings.Contains(f.Message, "also defined in a.yaml") {
+		t.Errorf("duplicate-id finding = %+v", f)
+	}
+	if f := found[3]; f.Value != "**/*.rs" {
+		t.Errorf("unmatched-scope value = %q, want the glob rust stands for", f.Value)
+	}
+	if line, _ := found[1].Resource.Position(found[1].Field, found[1].Value); line != 20 {
+		t.Errorf("empty-body line = %d, want 20", line)
+	}
+}
+
+func TestLongBody(t *testing.T) {
+	lib := &Library{Resources: []*compiler.Resource{parseResource(t, "a.yaml", securityRuleset)}}
+	found, err := New(LongBody{MaxTokens: 5}).Lint(lib)
+	if err != nil {
+		t.Fatalf("Lint() error = %v", err)
+	}
+	if got := findings(found); !reflect.DeepEqual(got, []string{"long-body spec.rules.noSecrets.body"}) {
+		t.Errorf("Lint() = %v", got)
+	}
+	if !strings.Contains(found[0].Message, "about 10 tokens, over the budget of 5") {
+		t.Errorf("message = %q", found[0].Message)
+	}
+}
+
+func TestUnusedFragmentExtends(t *testing.T) {
+	base := parseResource(t, "base.yaml", "apiVersion: ai-resource/draft\nkind: Ruleset\nmetadata:\n  id: base\nspec:\n  fragments:\n    shared: Shared text.\n  rules: {}\n")
+	child := parseResource(t, "child.yaml", "apiVersion: ai-resource/draft\nkind: Ruleset\nmetadata:\n  id: child\nspec:\n  extends: [base]\n  rules:\n    a:\n      enforcement: must\n      body: [$shared]\n")
+	resolved, err := compiler.ResolveExtends([]*compiler.Resource{base, child})
+	if err != nil {
+		t.Fatalf("ResolveExtends() error = %v", err)
+	}
+
+	found, err := New(UnusedFragment{}).Lint(&Library{Resources: resolved})
+	if err != nil {
+		t.Fatalf("Lint() error = %v", err)
+	}
+	if len(found) != 0 {
+		t.Errorf("Lint() = %v, want the base fragment used by its extender", findings(found))
+	}
+}
@@ -0,0 +1,44 @@
+package loader
+
+import (
+	"fmt"
+
+	"github.com/jomadu/ai-resource-compiler-go/internal/format"
+	"github.com/jomadu/ai-resource-compiler-go/pkg/compiler"
+)
+
+// readAssets reads the assets of a Prompt or Promptset, from paths relative
+// to baseDir or from https:// URLs, and records the files read. Assets whose
+// Data is already set are left as they are.
+func (l *Loader) readAssets(resource *compiler.Resource, baseDir string) error {
+	var lists [][]format.Asset
+	switch spec := resource.Spec.(type) {
+	case *format.Prompt:
+		lists = append(lists, spec.Spec.Assets)
+	case *format.Promptset:
+		lists = append(lists, spec.Spec.Assets)
+		for _, id := range spec.Spec.PromptIDs() {
+			lists = append(lists, spec.Spec.Prompts[id].Assets)
+		}
+	}
+
+	maxSize := l.Limits.WithDefaults().MaxFileSize
+	for _, assets := range lists {
+		for i := range assets {
+			asset := &assets[i]
+			if asset.Data != nil || asset.Path == "" {
+				continue
+			}
+			file, data, err := l.readRelative(asset.Path, baseDir)
+			if err != nil {
+				return fmt.Errorf("asset %s: %w", asset.Path, err)
+			}
+			if err := checkFileSize("asset "+asset.Path, data, maxSize); err != nil {
+				return err
+			}
+			asset.Data = data
+			resource.IncludedFiles = append(resource.IncludedFiles, file)
+		}
+	}
+	return nil
+}
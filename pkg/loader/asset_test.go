@@ -0,0 +1,69 @@
+package loader
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jomadu/ai-resource-compiler-go/internal/format"
+)
+
+func TestLoadAssets(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "skills/scripts/check.sh", "#!/bin/sh\nexit 0\n")
+	writeFile(t, dir, "skills/logo.png", "\x89PNG\r\n\x1a\n\x00")
+	writeFile(t, dir, "skills/shared.yaml", `apiVersion: ai-resource/draft
+kind: Promptset
+metadata:
+  id: shared
+spec:
+  assets: [logo.png]
+  prompts:
+    lint:
+      body: Lint.
+`)
+	path := writeFile(t, dir, "skills/review.yaml", `apiVersion: ai-resource/draft
+kind: Promptset
+include: [shared.yaml]
+metadata:
+  id: review
+spec:
+  prompts:
+    check:
+      body: Run scripts/check.sh.
+      assets:
+        - path: scripts/check.sh
+          name: scripts/check.sh
+`)
+
+	resource, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	prompts := resource.Spec.(*format.Promptset).Spec.Prompts
+	check := prompts["check"].Assets
+	if len(check) != 1 || check[0].FileName() != "scripts/check.sh" || string(check[0].Data) != "#!/bin/sh\nexit 0\n" {
+		t.Errorf("check assets = %+v", check)
+	}
+	lint := prompts["lint"].Assets
+	if len(lint) != 1 || lint[0].FileName() != "logo.png" || string(lint[0].Data) != "\x89PNG\r\n\x1a\n\x00" {
+		t.Errorf("included lint assets = %+v, want the included promptset's binary asset unchanged", lint)
+	}
+	if got := strings.Join(resource.IncludedFiles, " "); !strings.Contains(got, "check.sh") || !strings.Contains(got, "logo.png") {
+		t.Errorf("IncludedFiles = %v, want the assets read", resource.IncludedFiles)
+	}
+}
+
+func TestLoadMissingAsset(t *testing.T) {
+	dir := t.TempDir()
+	path := writeFile(t, dir, "prompt.yaml", `apiVersion: ai-resource/draft
+kind: Prompt
+metadata:
+  id: deploy
+spec:
+  body: Deploy.
+  assets: [missing.sh]
+`)
+	if _, err := Load(path); err == nil || !strings.Contains(err.Error(), "asset missing.sh") {
+		t.Errorf("Load() error = %v, want the missing asset named", err)
+	}
+}
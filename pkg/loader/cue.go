@@ -0,0 +1,81 @@
+package loader
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/jomadu/ai-resource-compiler-go/pkg/resource"
+)
+
+// cuePackage matches the package clause of a CUE file.
+var cuePackage = regexp.MustCompile(`(?m)^package\s+([A-Za-z_][A-Za-z0-9_]*)`)
+
+// isCUE reports whether path names a CUE resource file.
+func isCUE(path string) bool {
+	return strings.HasSuffix(path, ".cue")
+}
+
+// evalCUE evaluates a CUE resource file to YAML with the cue command. The
+// generated resource schema is evaluated alongside it, in the file's
+// package, so documents that embed #Rule, #Ruleset, #Prompt, #Promptset, or
+// #Resource are type-checked before they are decoded.
+func (l *Loader) evalCUE(path string, data []byte) ([]byte, error) {
+	tmp, err := os.MkdirTemp("", "arc-cue-*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tmp)
+
+	pkg := ""
+	if m := cuePackage.FindSubmatch(data); m != nil {
+		pkg = string(m[1])
+	}
+	schema := filepath.Join(tmp, "ai-resource-schema.cue")
+	if err := os.WriteFile(schema, []byte(resource.CUESchema(pkg)), 0644); err != nil {
+		return nil, err
+	}
+
+	// Files on disk are evaluated in place so their imports resolve; files
+	// from an fs.FS are copied out first.
+	source, dir := path, ""
+	if l.FS != nil {
+		source = filepath.Join(tmp, "resource.cue")
+		if err := os.WriteFile(source, data, 0644); err != nil {
+			return nil, err
+		}
+	} else if source, err = filepath.Abs(path); err != nil {
+		return nil, err
+	} else {
+		dir = filepath.Dir(source)
+	}
+
+	command := l.CUECommand
+	if command == "" {
+		command = "cue"
+	}
+	ctx := l.context()
+	cmd := exec.CommandContext(ctx, command, "export", "--out", "yaml", source, schema)
+	cmd.Dir = dir
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			return nil, fmt.Errorf("cue: %w", ctx.Err())
+		}
+		if errors.Is(err, exec.ErrNotFound) {
+			return nil, fmt.Errorf("loading CUE resources requires the cue command (https://cuelang.org): %w", err)
+		}
+		message := strings.TrimSpace(stderr.String())
+		if message == "" {
+			message = err.Error()
+		}
+		return nil, &ParseError{Err: fmt.Errorf("cue: %s", message)}
+	}
+	return stdout.Bytes(), nil
+}
@@ -0,0 +1,98 @@
+package loader
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/jomadu/ai-resource-compiler-go/internal/format"
+)
+
+// fakeCUE writes a cue stand-in that records its arguments and the schema
+// it was given, then prints output.
+func fakeCUE(t *testing.T, output string) (command, argsFile string) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("fake cue command is a shell script")
+	}
+	dir := t.TempDir()
+	argsFile = filepath.Join(dir, "args")
+	script := "#!/bin/sh\necho \"$@\" > " + argsFile + "\ncat \"$5\" >> " + argsFile + "\ncat <<'EOF'\n" + output + "EOF\n"
+	command = filepath.Join(dir, "cue")
+	if err := os.WriteFile(command, []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	return command, argsFile
+}
+
+func TestLoadCUE(t *testing.T) {
+	command, argsFile := fakeCUE(t, `apiVersion: ai-resource/draft
+kind: Rule
+metadata:
+  id: naming
+  name: Naming
+spec:
+  enforcement: must
+  body: Use clear names.
+`)
+	dir := t.TempDir()
+	path := writeFile(t, dir, "naming.cue", "package rules\n\n#Rule & {\n\tmetadata: id: \"naming\"\n}\n")
+
+	resource, err := (&Loader{CUECommand: command}).Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if rule, ok := resource.Spec.(*format.Rule); !ok || rule.Spec.Enforcement != "must" {
+		t.Errorf("Spec = %#v, want the evaluated rule", resource.Spec)
+	}
+
+	args, err := os.ReadFile(argsFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(args), "export --out yaml "+path+" ") {
+		t.Errorf("cue arguments = %q", args)
+	}
+	if !strings.Contains(string(args), "package rules\n") || !strings.Contains(string(args), "#Resource:") {
+		t.Errorf("schema was not passed in the resource's package:\n%s", args)
+	}
+}
+
+func TestLoadCUEErrors(t *testing.T) {
+	dir := t.TempDir()
+	path := writeFile(t, dir, "rule.cue", "#Rule & {}\n")
+
+	_, err := (&Loader{CUECommand: "arc-test-missing-cue"}).Load(path)
+	if err == nil || !strings.Contains(err.Error(), "requires the cue command") {
+		t.Errorf("Load() error = %v, want missing cue error", err)
+	}
+
+	command, _ := fakeCUE(t, "")
+	if err := os.WriteFile(command, []byte("#!/bin/sh\necho 'metadata.id: invalid value' >&2\nexit 1\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	_, err = (&Loader{CUECommand: command}).Load(path)
+	var parseErr *ParseError
+	if !errors.As(err, &parseErr) || !strings.Contains(err.Error(), "cue: metadata.id: invalid value") {
+		t.Errorf("Load() error = %v, want cue ParseError", err)
+	}
+}
+
+func TestLoadCUECanceled(t *testing.T) {
+	command, _ := fakeCUE(t, "")
+	if err := os.WriteFile(command, []byte("#!/bin/sh\nsleep 10\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	path := writeFile(t, t.TempDir(), "rule.cue", "#Rule & {}\n")
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := (&Loader{CUECommand: command, Context: ctx}).Load(path)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Load() error = %v, want context.Canceled", err)
+	}
+}
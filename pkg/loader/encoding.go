@@ -0,0 +1,37 @@
+package loader
+
+import (
+	"bytes"
+	"errors"
+)
+
+var (
+	bomUTF8    = []byte{0xEF, 0xBB, 0xBF}
+	bomUTF16BE = []byte{0xFE, 0xFF}
+	bomUTF16LE = []byte{0xFF, 0xFE}
+)
+
+// errUTF16 is returned for files saved as UTF-16, which some Windows editors
+// use by default.
+var errUTF16 = errors.New("file is UTF-16 encoded; save it as UTF-8")
+
+// normalizeText prepares file content for YAML decoding: it removes a UTF-8
+// byte order mark and converts CRLF and CR line endings to LF, so content
+// authored on Windows compiles to the same output. UTF-16 content is
+// rejected with a clear error rather than a YAML syntax error.
+func normalizeText(data []byte) ([]byte, error) {
+	if bytes.HasPrefix(data, bomUTF16BE) || bytes.HasPrefix(data, bomUTF16LE) {
+		return nil, errUTF16
+	}
+	// UTF-16 without a byte order mark: ASCII characters have a zero byte.
+	if len(data) >= 2 && (data[0] == 0) != (data[1] == 0) {
+		return nil, errUTF16
+	}
+
+	data = bytes.TrimPrefix(data, bomUTF8)
+	if bytes.IndexByte(data, '\r') >= 0 {
+		data = bytes.ReplaceAll(data, []byte("\r\n"), []byte("\n"))
+		data = bytes.ReplaceAll(data, []byte("\r"), []byte("\n"))
+	}
+	return data, nil
+}
@@ -0,0 +1,48 @@
+package loader
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"unicode/utf16"
+
+	"github.com/jomadu/ai-resource-compiler-go/internal/format"
+)
+
+const windowsRule = "apiVersion: ai-resource/draft\r\nkind: Rule\r\nmetadata:\r\n  id: testRule\r\nspec:\r\n  enforcement: must\r\n  body: |\r\n    First line\r\n    Second line\r\n"
+
+func TestParseBOMAndCRLF(t *testing.T) {
+	resource, err := (&Loader{}).Parse(append([]byte("\xEF\xBB\xBF"), windowsRule...), ".")
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	body := resource.Spec.(*format.Rule).Spec.Body.String
+	if body == nil || *body != "First line\nSecond line\n" {
+		t.Errorf("Body = %q, want LF line endings", *body)
+	}
+}
+
+func TestParseUTF16(t *testing.T) {
+	units := utf16.Encode([]rune("apiVersion: ai-resource/draft\n"))
+	le := []byte{0xFF, 0xFE}
+	noBOM := []byte{}
+	for _, u := range units {
+		le = append(le, byte(u), byte(u>>8))
+		noBOM = append(noBOM, byte(u), byte(u>>8))
+	}
+
+	for name, data := range map[string][]byte{"with BOM": le, "without BOM": noBOM} {
+		_, err := (&Loader{}).Parse(data, ".")
+		var parseErr *ParseError
+		if !errors.As(err, &parseErr) || !strings.Contains(err.Error(), "UTF-16") {
+			t.Errorf("%s: Parse() error = %v, want UTF-16 error", name, err)
+		}
+	}
+}
+
+func TestNormalizeText(t *testing.T) {
+	got, err := normalizeText([]byte("a\r\nb\rc\n"))
+	if err != nil || string(got) != "a\nb\nc\n" {
+		t.Errorf("normalizeText() = %q, %v", got, err)
+	}
+}
@@ -0,0 +1,308 @@
+package loader
+
+import (
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/jomadu/ai-resource-compiler-go/internal/format"
+	"github.com/jomadu/ai-resource-compiler-go/pkg/compiler"
+	"gopkg.in/yaml.v3"
+)
+
+// includeFiles merges the files the resource includes, read from paths
+// relative to baseDir or fetched from https:// URLs. A fragment library, a
+// YAML mapping of fragment name to content, is merged into the resource's
+// fragments. A resource file, such as a Rule included by a Ruleset, adds its
+// rules or prompts, after those of the resource, and its fragments; see
+// includeResource. The same fragment name defined with different content by
+// two includes, or by an include and the resource itself, is an error. The
+// includes, the files read, and the include each merged fragment or item came
+// from are recorded on the resource. chain lists the resource files being
+// loaded, to detect include cycles.
+func (l *Loader) includeFiles(resource *compiler.Resource, includes []string, baseDir string, chain []string) error {
+	if len(includes) == 0 {
+		return nil
+	}
+
+	fragments := fragmentsOf(resource)
+	if fragments == nil {
+		return fmt.Errorf("include is not supported for kind: %s", resource.Kind)
+	}
+	if *fragments == nil {
+		*fragments = make(map[string]string)
+	}
+
+	resource.Includes = includes
+	origin := make(map[string]string)
+	for name := range *fragments {
+		origin[name] = "the resource"
+	}
+
+	for _, include := range includes {
+		file, data, err := l.readInclude(include, baseDir)
+		if err != nil {
+			return fmt.Errorf("include %s: %w", include, err)
+		}
+		resource.IncludedFiles = append(resource.IncludedFiles, file)
+
+		var library map[string]string
+		if isResourceDocument(data) {
+			included, err := l.parseIncluded(file, data, chain)
+			if err != nil {
+				return fmt.Errorf("include %s: %w", include, err)
+			}
+			if err := includeResource(resource, included, include); err != nil {
+				return fmt.Errorf("include %s: %w", include, err)
+			}
+			resource.IncludedFiles = append(resource.IncludedFiles, included.IncludedFiles...)
+			library = *fragmentsOf(included)
+		} else if library, err = parseFragmentLibrary(data); err != nil {
+			return fmt.Errorf("include %s: %w", include, err)
+		}
+
+		for _, name := range format.SortedKeys(library) {
+			content := library[name]
+			if existing, ok := (*fragments)[name]; ok {
+				if existing != content {
+					return fmt.Errorf("include %s: fragment %q conflicts with definition in %s", include, name, origin[name])
+				}
+				continue
+			}
+			(*fragments)[name] = content
+			origin[name] = include
+			if resource.IncludedFragments == nil {
+				resource.IncludedFragments = make(map[string]string)
+			}
+			resource.IncludedFragments[name] = include
+		}
+	}
+
+	return nil
+}
+
+// readInclude reads include, returning the file or URL it was read from.
+func (l *Loader) readInclude(include, baseDir string) (string, []byte, error) {
+	file, data, err := l.readRelative(include, baseDir)
+	if err != nil {
+		return "", nil, err
+	}
+	if err := checkFileSize("included file "+include, data, l.Limits.WithDefaults().MaxFileSize); err != nil {
+		return "", nil, err
+	}
+	if data, err = normalizeText(data); err != nil {
+		return "", nil, err
+	}
+	return file, data, nil
+}
+
+// readRelative reads name, a path relative to baseDir or a URL, returning
+// the file or URL it was read from. Relative names in a remote resource
+// file, whose baseDir is its URL, are resolved against that URL.
+func (l *Loader) readRelative(name, baseDir string) (string, []byte, error) {
+	file := name
+	if isRemote(baseDir) && !isRemote(name) {
+		base, err := url.Parse(baseDir)
+		if err != nil {
+			return "", nil, err
+		}
+		ref, err := url.Parse(filepath.ToSlash(name))
+		if err != nil {
+			return "", nil, err
+		}
+		file = base.ResolveReference(ref).String()
+	}
+
+	if isRemote(file) {
+		data, err := l.fetch(file)
+		return file, data, err
+	}
+	file = l.join(baseDir, name)
+	data, err := l.readFile(file)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to read file: %w", err)
+	}
+	return file, data, nil
+}
+
+// isResourceDocument reports whether data is a resource file, with an
+// apiVersion and kind, rather than a fragment library.
+func isResourceDocument(data []byte) bool {
+	var h struct {
+		APIVersion string `yaml:"apiVersion"`
+		Kind       string `yaml:"kind"`
+	}
+	return yaml.Unmarshal(data, &h) == nil && h.APIVersion != "" && h.Kind != ""
+}
+
+// parseIncluded parses the included resource file, resolving its own
+// includes against its directory. Including a file already being loaded is
+// an include cycle.
+func (l *Loader) parseIncluded(file string, data []byte, chain []string) (*compiler.Resource, error) {
+	key := l.fileKey(file)
+	if slices.Contains(chain, key) {
+		return nil, fmt.Errorf("include cycle: %s", strings.Join(append(chain, key), " -> "))
+	}
+	if isCUE(file) && !isRemote(file) {
+		var err error
+		if data, err = l.evalCUE(file, data); err != nil {
+			return nil, err
+		}
+	}
+	baseDir := file
+	if !isRemote(file) {
+		baseDir = l.dir(file)
+	}
+	return l.parse(data, baseDir, append(chain, key))
+}
+
+// fileKey identifies a resource file for cycle detection: its absolute path,
+// its path within l.FS, or its URL.
+func (l *Loader) fileKey(file string) string {
+	if l.FS != nil || isRemote(file) {
+		return file
+	}
+	if abs, err := filepath.Abs(file); err == nil {
+		return abs
+	}
+	return filepath.Clean(file)
+}
+
+// includeResource adds the rules or prompts of included to resource: a Rule
+// or Ruleset to a Ruleset, a Prompt or Promptset to a Promptset. Included
+// items follow those already defined, in order, and take the name and
+// description of a standalone Rule or Prompt from its metadata. An ID
+// defined twice, or an included namespace other than the resource's, is an
+// error.
+func includeResource(resource, included *compiler.Resource, include string) error {
+	if included.Metadata.Namespace != "" && included.Metadata.Namespace != resource.Metadata.Namespace {
+		return fmt.Errorf("namespace %q differs from the including resource's %q", included.Metadata.Namespace, resource.Metadata.Namespace)
+	}
+	if resource.IncludedItems == nil {
+		resource.IncludedItems = make(map[string]string)
+	}
+	unsupported := fmt.Errorf("a %s cannot include a %s", resource.Kind, included.Kind)
+
+	switch spec := resource.Spec.(type) {
+	case *format.Ruleset:
+		items := make(map[string]format.RuleItem)
+		var ids []string
+		switch inc := included.Spec.(type) {
+		case *format.Rule:
+			ids = []string{inc.Metadata.ID}
+			items[inc.Metadata.ID] = format.RuleItem{
+				Name:        inc.Metadata.Name,
+				Description: inc.Metadata.Description,
+				Enforcement: inc.Spec.Enforcement,
+				Scope:       inc.Spec.Scope,
+				Body:        inc.Spec.Body,
+				Bodies:      inc.Spec.Bodies,
+				Tags:        inc.Metadata.Tags,
+			}
+		case *format.Ruleset:
+			ids = inc.Spec.RuleIDs()
+			for id, item := range inc.Spec.Rules {
+				item.Tags = format.MergeTags(inc.Metadata.Tags, item.Tags)
+				items[id] = item
+			}
+		default:
+			return unsupported
+		}
+		if spec.Spec.Rules == nil {
+			spec.Spec.Rules = make(map[string]format.RuleItem)
+		}
+		spec.Spec.Order = spec.Spec.RuleIDs()
+		for _, id := range ids {
+			if _, ok := spec.Spec.Rules[id]; ok {
+				return duplicateItem("rule", id, resource.IncludedItems)
+			}
+			spec.Spec.Rules[id] = items[id]
+			spec.Spec.Order = append(spec.Spec.Order, id)
+			resource.IncludedItems[id] = include
+		}
+	case *format.Promptset:
+		items := make(map[string]format.PromptItem)
+		var ids []string
+		switch inc := included.Spec.(type) {
+		case *format.Prompt:
+			ids = []string{inc.Metadata.ID}
+			items[inc.Metadata.ID] = format.PromptItem{
+				Name:         inc.Metadata.Name,
+				Description:  inc.Metadata.Description,
+				AllowedTools: inc.Spec.AllowedTools,
+				Arguments:    inc.Spec.Arguments,
+				Body:         inc.Spec.Body,
+				Bodies:       inc.Spec.Bodies,
+				Tags:         inc.Metadata.Tags,
+				Assets:       inc.Spec.Assets,
+				Mode:         inc.Spec.Mode,
+				Model:        inc.Spec.Model,
+				Tools:        inc.Spec.Tools,
+			}
+		case *format.Promptset:
+			ids = inc.Spec.PromptIDs()
+			for id, item := range inc.Spec.Prompts {
+				item.Tags = format.MergeTags(inc.Metadata.Tags, item.Tags)
+				item.Assets = append(append([]format.Asset(nil), inc.Spec.Assets...), item.Assets...)
+				items[id] = item
+			}
+		default:
+			return unsupported
+		}
+		if spec.Spec.Prompts == nil {
+			spec.Spec.Prompts = make(map[string]format.PromptItem)
+		}
+		spec.Spec.Order = spec.Spec.PromptIDs()
+		for _, id := range ids {
+			if _, ok := spec.Spec.Prompts[id]; ok {
+				return duplicateItem("prompt", id, resource.IncludedItems)
+			}
+			spec.Spec.Prompts[id] = items[id]
+			spec.Spec.Order = append(spec.Spec.Order, id)
+			resource.IncludedItems[id] = include
+		}
+	default:
+		return unsupported
+	}
+	return nil
+}
+
+// duplicateItem reports a rule or prompt ID defined twice, naming the
+// include that defined it first, if any.
+func duplicateItem(kind, id string, includedItems map[string]string) error {
+	if first, ok := includedItems[id]; ok {
+		return fmt.Errorf("%s %q is already defined by include %s", kind, id, first)
+	}
+	return fmt.Errorf("%s %q is already defined by the resource", kind, id)
+}
+
+// parseFragmentLibrary decodes a fragment library.
+func parseFragmentLibrary(data []byte) (map[string]string, error) {
+	var library map[string]string
+	if err := yaml.Unmarshal(data, &library); err != nil {
+		return nil, fmt.Errorf("fragment library must map fragment names to strings: %w", err)
+	}
+	return library, nil
+}
+
+// fragmentsOf returns a pointer to the fragment map of the resource's spec,
+// or nil if the kind has no fragments.
+func fragmentsOf(resource *compiler.Resource) *map[string]string {
+	switch spec := resource.Spec.(type) {
+	case *format.Rule:
+		return &spec.Spec.Fragments
+	case *format.Ruleset:
+		return &spec.Spec.Fragments
+	case *format.Prompt:
+		return &spec.Spec.Fragments
+	case *format.Promptset:
+		return &spec.Spec.Fragments
+	case *format.Command:
+		return &spec.Spec.Fragments
+	case *format.Context:
+		return &spec.Spec.Fragments
+	}
+	return nil
+}
@@ -0,0 +1,307 @@
+package loader
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jomadu/ai-resource-compiler-go/internal/format"
+)
+
+func TestIncludeFragmentLibraries(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "lib/common.yaml", `header: Follow the team conventions.
+footer: "Ask in #eng if unsure."
+`)
+	writeFile(t, dir, "lib/go.yaml", `gofmt: Run gofmt before committing.
+`)
+	path := writeFile(t, dir, "rules/ruleset.yaml", `apiVersion: ai-resource/draft
+kind: Ruleset
+include:
+  - ../lib/common.yaml
+  - ../lib/go.yaml
+metadata:
+  id: goStyle
+spec:
+  fragments:
+    local: Local fragment.
+  rules:
+    formatting:
+      name: Formatting
+      enforcement: must
+      body:
+        - $header
+        - $gofmt
+        - $local
+        - $footer
+`)
+
+	resource, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	ruleset := resource.Spec.(*format.Ruleset)
+	body := format.ResolveBody(ruleset.Spec.Rules["formatting"].Body, ruleset.Spec.Fragments)
+	want := "Follow the team conventions.\n\nRun gofmt before committing.\n\nLocal fragment.\n\nAsk in #eng if unsure."
+	if body != want {
+		t.Errorf("body = %q, want %q", body, want)
+	}
+
+	if len(resource.Includes) != 2 || resource.Includes[1] != "../lib/go.yaml" {
+		t.Errorf("Includes = %v", resource.Includes)
+	}
+	if got := resource.IncludedFragments["gofmt"]; got != "../lib/go.yaml" {
+		t.Errorf("IncludedFragments[gofmt] = %q, want ../lib/go.yaml", got)
+	}
+	if _, ok := resource.IncludedFragments["local"]; ok {
+		t.Error("IncludedFragments lists a fragment defined by the resource")
+	}
+}
+
+func TestIncludeIntoResourceWithoutFragments(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "common.yaml", "greeting: Hello.\n")
+	path := writeFile(t, dir, "prompt.yaml", `apiVersion: ai-resource/draft
+kind: Prompt
+include: [common.yaml]
+metadata:
+  id: greet
+spec:
+  body: [$greeting]
+`)
+
+	resource, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	prompt := resource.Spec.(*format.Prompt)
+	if got := format.ResolveBody(prompt.Spec.Body, prompt.Spec.Fragments); got != "Hello." {
+		t.Errorf("body = %q, want Hello.", got)
+	}
+}
+
+func TestIncludeConflicts(t *testing.T) {
+	tests := []struct {
+		name     string
+		resource string
+		want     string
+	}{
+		{
+			name: "two libraries",
+			resource: `apiVersion: ai-resource/draft
+kind: Rule
+include: [a.yaml, b.yaml]
+metadata:
+  id: r
+spec:
+  enforcement: must
+  body: [$shared]
+`,
+			want: `fragment "shared" conflicts with definition in a.yaml`,
+		},
+		{
+			name: "library and resource",
+			resource: `apiVersion: ai-resource/draft
+kind: Rule
+include: [a.yaml]
+metadata:
+  id: r
+spec:
+  enforcement: must
+  fragments:
+    shared: Local version.
+  body: [$shared]
+`,
+			want: `fragment "shared" conflicts with definition in the resource`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeFile(t, dir, "a.yaml", "shared: Version A.\n")
+			writeFile(t, dir, "b.yaml", "shared: Version B.\n")
+			path := writeFile(t, dir, "rule.yaml", tt.resource)
+
+			_, err := Load(path)
+			if err == nil {
+				t.Fatal("Load() expected conflict error")
+			}
+			if !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestIncludeIdenticalDefinitionsAllowed(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "a.yaml", "shared: Same.\n")
+	writeFile(t, dir, "b.yaml", "shared: Same.\n")
+	path := writeFile(t, dir, "rule.yaml", `apiVersion: ai-resource/draft
+kind: Rule
+include: [a.yaml, b.yaml]
+metadata:
+  id: r
+spec:
+  enforcement: must
+  body: [$shared]
+`)
+
+	if _, err := Load(path); err != nil {
+		t.Errorf("Load() error = %v, want identical definitions accepted", err)
+	}
+}
+
+func TestIncludeErrors(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "bad.yaml", "nested:\n  not: a string\n")
+
+	missing := writeFile(t, dir, "missing.yaml", `apiVersion: ai-resource/draft
+kind: Rule
+include: [nope.yaml]
+metadata:
+  id: r
+spec:
+  enforcement: must
+  body: x
+`)
+	if _, err := Load(missing); err == nil || !strings.Contains(err.Error(), "include nope.yaml") {
+		t.Errorf("Load() missing library error = %v", err)
+	}
+
+	invalid := writeFile(t, dir, "invalid.yaml", `apiVersion: ai-resource/draft
+kind: Rule
+include: [bad.yaml]
+metadata:
+  id: r
+spec:
+  enforcement: must
+  body: x
+`)
+	if _, err := Load(invalid); err == nil || !strings.Contains(err.Error(), "must map fragment names to strings") {
+		t.Errorf("Load() invalid library error = %v", err)
+	}
+}
+
+func TestIncludeResources(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "rules/naming.yaml", `apiVersion: ai-resource/draft
+kind: Rule
+include: [../lib/common.yaml]
+metadata:
+  id: naming
+  name: Meaningful Names
+  description: Names reveal intent
+spec:
+  enforcement: must
+  scope:
+    - files: ["**/*.go"]
+  body: [$header, Name things well.]
+`)
+	writeFile(t, dir, "lib/common.yaml", "header: Follow the team conventions.\n")
+	writeFile(t, dir, "rules/testing.yaml", `apiVersion: ai-resource/draft
+kind: Ruleset
+metadata:
+  id: testing
+spec:
+  fragments:
+    suffix: Run the tests.
+  rules:
+    coverage:
+      name: Coverage
+      enforcement: should
+      body: [Cover new code., $suffix]
+    tables:
+      name: Table Tests
+      enforcement: may
+      body: Prefer table tests.
+`)
+	path := writeFile(t, dir, "style.yaml", `apiVersion: ai-resource/draft
+kind: Ruleset
+include: [rules/naming.yaml, rules/testing.yaml]
+metadata:
+  id: style
+spec:
+  rules:
+    formatting:
+      name: Formatting
+      enforcement: must
+      body: Run gofmt.
+`)
+
+	resource, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	ruleset := resource.Spec.(*format.Ruleset)
+	if got := strings.Join(ruleset.Spec.RuleIDs(), ","); got != "formatting,naming,coverage,tables" {
+		t.Errorf("RuleIDs() = %s, want the resource's rules, then the included ones in order", got)
+	}
+	naming := ruleset.Spec.Rules["naming"]
+	if naming.Name != "Meaningful Names" || naming.Description != "Names reveal intent" || naming.Enforcement != "must" || len(naming.Scope) != 1 {
+		t.Errorf("naming = %+v, want the included rule with its metadata", naming)
+	}
+	if got := format.ResolveBody(naming.Body, ruleset.Spec.Fragments); got != "Follow the team conventions.\n\nName things well." {
+		t.Errorf("naming body = %q, want its nested include's fragment resolved", got)
+	}
+	if got := format.ResolveBody(ruleset.Spec.Rules["coverage"].Body, ruleset.Spec.Fragments); got != "Cover new code.\n\nRun the tests." {
+		t.Errorf("coverage body = %q", got)
+	}
+	if got := resource.IncludedItems["tables"]; got != "rules/testing.yaml" {
+		t.Errorf("IncludedItems[tables] = %q, want rules/testing.yaml", got)
+	}
+	if len(resource.IncludedFiles) != 3 {
+		t.Errorf("IncludedFiles = %v, want both rule files and the nested library", resource.IncludedFiles)
+	}
+}
+
+func TestIncludeResourceErrors(t *testing.T) {
+	rule := func(id string) string {
+		return "apiVersion: ai-resource/draft\nkind: Rule\nmetadata:\n  id: " + id + "\nspec:\n  enforcement: must\n  body: x\n"
+	}
+	tests := []struct {
+		name  string
+		files map[string]string
+		want  string
+	}{
+		{
+			name: "duplicate ID",
+			files: map[string]string{
+				"a.yaml":   rule("naming"),
+				"b.yaml":   rule("naming"),
+				"set.yaml": "apiVersion: ai-resource/draft\nkind: Ruleset\ninclude: [a.yaml, b.yaml]\nmetadata:\n  id: set\nspec: {}\n",
+			},
+			want: `include b.yaml: rule "naming" is already defined by include a.yaml`,
+		},
+		{
+			name: "cycle",
+			files: map[string]string{
+				"a.yaml":   "apiVersion: ai-resource/draft\nkind: Ruleset\ninclude: [set.yaml]\nmetadata:\n  id: a\nspec: {}\n",
+				"set.yaml": "apiVersion: ai-resource/draft\nkind: Ruleset\ninclude: [a.yaml]\nmetadata:\n  id: set\nspec: {}\n",
+			},
+			want: "include cycle:",
+		},
+		{
+			name: "kind",
+			files: map[string]string{
+				"prompt.yaml": "apiVersion: ai-resource/draft\nkind: Prompt\nmetadata:\n  id: p\nspec:\n  body: x\n",
+				"set.yaml":    "apiVersion: ai-resource/draft\nkind: Ruleset\ninclude: [prompt.yaml]\nmetadata:\n  id: set\nspec: {}\n",
+			},
+			want: "a Ruleset cannot include a Prompt",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, content := range tt.files {
+				writeFile(t, dir, name, content)
+			}
+			_, err := Load(filepath.Join(dir, "set.yaml"))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Load() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,498 @@
+// Package loader reads resource files into compiler.Resource values,
+// resolving load-time directives such as include. Remote includes are
+// fetched over HTTPS and can be pinned with a Lockfile.
+package loader
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jomadu/ai-resource-compiler-go/pkg/compiler"
+	"github.com/jomadu/ai-resource-compiler-go/pkg/resource"
+	"gopkg.in/yaml.v3"
+)
+
+// Patch modifies a parsed resource document before it is decoded. It reports
+// whether it applied to the document.
+type Patch interface {
+	Apply(doc *yaml.Node) (bool, error)
+}
+
+// Loader reads resource files. The zero value is ready to use.
+type Loader struct {
+	// Patches are applied, in order, to each document before it is decoded.
+	Patches []Patch
+
+	// Client fetches https:// resource files and includes. Defaults to a
+	// client with a timeout of FetchTimeout.
+	Client *http.Client
+
+	// Context, if set, cancels fetches of remote content and cue commands
+	// when done.
+	Context context.Context
+
+	// Lock, if set, pins the checksums of fetched remote content.
+	Lock *Lockfile
+
+	// FS, if set, is read instead of the OS filesystem for resource files and
+	// local includes. Paths are then slash-separated and relative to its root.
+	FS fs.FS
+
+	// CUECommand is the cue executable used to evaluate .cue resource files.
+	// Defaults to "cue" on PATH.
+	CUECommand string
+
+	// Limits bounds file size, nesting depth, and alias expansion. Unset
+	// fields use compiler.DefaultLimits.
+	Limits compiler.Limits
+
+	// Lenient ignores fields the format does not define, such as a
+	// misspelled "enforcment:". By default they are an UnknownFieldError.
+	Lenient bool
+}
+
+// UnknownFieldError reports fields of a resource document that the format
+// does not define, each with its dotted path and position.
+type UnknownFieldError struct {
+	Fields []*resource.SchemaError
+}
+
+func (e *UnknownFieldError) Error() string {
+	messages := make([]string, len(e.Fields))
+	for i, field := range e.Fields {
+		messages[i] = field.Error()
+	}
+	return strings.Join(messages, "; ")
+}
+
+// checkKnownFields returns an error naming the fields of doc the format does
+// not define, if any.
+func checkKnownFields(doc *yaml.Node) error {
+	var unknown []*resource.SchemaError
+	for _, problem := range resource.CheckSchema(doc) {
+		if problem.Unknown {
+			unknown = append(unknown, problem)
+		}
+	}
+	if len(unknown) == 0 {
+		return nil
+	}
+	return &ParseError{Line: unknown[0].Line, Column: unknown[0].Column, Err: &UnknownFieldError{Fields: unknown}}
+}
+
+// ParseError reports a resource file that is not valid YAML or does not
+// decode into a resource. Line and Column are 1-based, or 0 when unknown.
+type ParseError struct {
+	Line   int
+	Column int
+	Err    error
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("failed to parse resource file: %v", e.Err)
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// yamlLine matches the position yaml.v3 puts in its error messages.
+var yamlLine = regexp.MustCompile(`line (\d+)`)
+
+// parseError wraps a YAML error, taking the line from its message.
+func parseError(err error) *ParseError {
+	pe := &ParseError{Err: err}
+	if m := yamlLine.FindStringSubmatch(err.Error()); m != nil {
+		pe.Line, _ = strconv.Atoi(m[1])
+	}
+	return pe
+}
+
+// schemaError returns the ParseError of doc failing to decode with err: the
+// first value of the wrong type that resource.CheckSchema finds, which names
+// the field, or else err. An unsupported kind is left to err, which
+// suggests the kind meant.
+func schemaError(doc *yaml.Node, err error) *ParseError {
+	if errors.Is(err, compiler.ErrUnsupportedKind) {
+		return parseError(err)
+	}
+	for _, problem := range resource.CheckSchema(doc) {
+		if !problem.Missing && !problem.Unknown {
+			return &ParseError{Line: problem.Line, Column: problem.Column, Err: problem}
+		}
+	}
+	return parseError(err)
+}
+
+// MaxExpandedNodes bounds the number of YAML nodes a resource document may
+// expand to once aliases are resolved. Anchors and aliases, including merge
+// keys, are supported, but a document whose aliases nest to expand beyond
+// this limit ("billion laughs") is rejected before it is decoded.
+//
+// Deprecated: set Loader.Limits.MaxExpandedNodes; this is its default.
+const MaxExpandedNodes = 100000
+
+// checkAliasExpansion returns an error if doc expands to more than limit
+// nodes. The size of each aliased node is computed once, so the check is
+// linear in the size of the document as written.
+func checkAliasExpansion(doc *yaml.Node, limit int) error {
+	if limit < 0 {
+		return nil
+	}
+	sizes := make(map[*yaml.Node]int)
+	var size func(n *yaml.Node) int
+	size = func(n *yaml.Node) int {
+		if n.Kind == yaml.AliasNode && n.Alias != nil {
+			n = n.Alias
+		}
+		if s, ok := sizes[n]; ok {
+			return s
+		}
+		sizes[n] = limit + 1 // guards against alias cycles
+		total := 1
+		for _, child := range n.Content {
+			if total += size(child); total > limit {
+				break
+			}
+		}
+		sizes[n] = total
+		return total
+	}
+	if size(doc) > limit {
+		return &ParseError{Err: fmt.Errorf("%w: document expands to more than %d nodes through aliases", compiler.ErrLimitExceeded, limit)}
+	}
+	return nil
+}
+
+// checkDepth returns an error at the first mapping or sequence nested more
+// than limit levels deep. Aliases are not followed; checkAliasExpansion
+// bounds what they add.
+func checkDepth(n *yaml.Node, depth, limit int) error {
+	if n.Kind == yaml.MappingNode || n.Kind == yaml.SequenceNode {
+		if depth++; compiler.Exceeds(depth, limit) {
+			return &ParseError{
+				Line:   n.Line,
+				Column: n.Column,
+				Err:    fmt.Errorf("%w: line %d: nesting is deeper than %d levels", compiler.ErrLimitExceeded, n.Line, limit),
+			}
+		}
+	}
+	for _, child := range n.Content {
+		if err := checkDepth(child, depth, limit); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkFileSize returns an error if data is larger than limit. data may
+// be cut short one byte past the limit, so its size is not reported.
+func checkFileSize(what string, data []byte, limit int) error {
+	if compiler.Exceeds(len(data), limit) {
+		return fmt.Errorf("%w: %s is more than %d bytes", compiler.ErrLimitExceeded, what, limit)
+	}
+	return nil
+}
+
+// checkDuplicateKeys returns an error for the first mapping in doc that
+// defines a key twice. yaml.v3 keeps the last value of a duplicated key in
+// some cases, so rules would otherwise be lost without notice.
+func checkDuplicateKeys(n *yaml.Node) error {
+	if n.Kind == yaml.MappingNode {
+		seen := make(map[string]*yaml.Node)
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			key := n.Content[i]
+			if key.Kind != yaml.ScalarNode || key.Value == "<<" {
+				continue
+			}
+			if first, ok := seen[key.Value]; ok {
+				return &ParseError{
+					Line:   key.Line,
+					Column: key.Column,
+					Err:    fmt.Errorf("line %d: duplicate key %q (first defined at line %d)", key.Line, key.Value, first.Line),
+				}
+			}
+			seen[key.Value] = key
+		}
+	}
+	for _, child := range n.Content {
+		if err := checkDuplicateKeys(child); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// header holds the load-time directives of a resource document.
+type header struct {
+	Include []string `yaml:"include"`
+}
+
+// Load reads a resource file with the default loader.
+func Load(path string) (*compiler.Resource, error) {
+	return (&Loader{}).Load(path)
+}
+
+// LoadFS reads every resource file in fsys matching glob with the default
+// loader. It is intended for resources embedded with go:embed.
+func LoadFS(fsys fs.FS, glob string) ([]*compiler.Resource, error) {
+	return (&Loader{}).LoadFS(fsys, glob)
+}
+
+// Load reads and decodes a resource file. Relative include paths are
+// resolved against the file's directory, and an include cycle is an
+// error. Files ending in .cue are evaluated with the cue command first.
+//
+// path may also be an https:// URL, fetched as remote includes are, whose
+// relative includes resolve against the URL. A "#sha256=HEX" fragment pins
+// the content's checksum; see Lockfile for pinning without one.
+func (l *Loader) Load(path string) (*compiler.Resource, error) {
+	if isRemote(path) {
+		return l.loadRemote(path)
+	}
+	data, err := l.readFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read resource file: %w", err)
+	}
+	if isCUE(path) {
+		if err := checkFileSize("resource file", data, l.Limits.WithDefaults().MaxFileSize); err != nil {
+			return nil, err
+		}
+		if data, err = l.evalCUE(path, data); err != nil {
+			return nil, err
+		}
+	}
+	resource, err := l.parse(data, l.dir(path), []string{l.fileKey(path)})
+	if err != nil {
+		return nil, err
+	}
+	resource.Source = path
+	if isCUE(path) {
+		// Positions in the evaluated YAML do not match the .cue file.
+		resource.Node = nil
+	}
+	return resource, nil
+}
+
+// loadRemote is Load for the resource file at url.
+func (l *Loader) loadRemote(url string) (*compiler.Resource, error) {
+	if isCUE(stripChecksum(url)) {
+		return nil, fmt.Errorf("remote CUE resource files are not supported: %s", url)
+	}
+	data, err := l.fetch(url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read resource file: %w", err)
+	}
+	resource, err := l.parse(data, url, []string{l.fileKey(url)})
+	if err != nil {
+		return nil, err
+	}
+	resource.Source = url
+	return resource, nil
+}
+
+// Parse decodes resource content. Relative include paths are resolved
+// against baseDir.
+func (l *Loader) Parse(data []byte, baseDir string) (*compiler.Resource, error) {
+	return l.parse(data, baseDir, nil)
+}
+
+// parse is Parse for a document included by the resource files of chain.
+func (l *Loader) parse(data []byte, baseDir string, chain []string) (*compiler.Resource, error) {
+	limits := l.Limits.WithDefaults()
+	if err := checkFileSize("resource file", data, limits.MaxFileSize); err != nil {
+		return nil, err
+	}
+	data, err := normalizeText(data)
+	if err != nil {
+		return nil, &ParseError{Err: err}
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, parseError(err)
+	}
+	if err := checkDepth(&doc, 0, limits.MaxDepth); err != nil {
+		return nil, err
+	}
+	if err := checkAliasExpansion(&doc, limits.MaxExpandedNodes); err != nil {
+		return nil, err
+	}
+	if err := checkDuplicateKeys(&doc); err != nil {
+		return nil, err
+	}
+	for _, p := range l.Patches {
+		if _, err := p.Apply(&doc); err != nil {
+			return nil, err
+		}
+	}
+
+	var resource compiler.Resource
+	if err := doc.Decode(&resource); err != nil {
+		return nil, schemaError(&doc, err)
+	}
+	if !l.Lenient {
+		if err := checkKnownFields(&doc); err != nil {
+			return nil, err
+		}
+	}
+
+	var h header
+	if err := doc.Decode(&h); err != nil {
+		return nil, parseError(err)
+	}
+	if err := l.readAssets(&resource, baseDir); err != nil {
+		return nil, err
+	}
+	if err := l.includeFiles(&resource, h.Include, baseDir, chain); err != nil {
+		return nil, err
+	}
+
+	return &resource, nil
+}
+
+// LoadFS reads every resource file in fsys matching glob, in lexical order.
+// The pattern syntax is that of fs.Glob. Includes are resolved within fsys.
+func (l *Loader) LoadFS(fsys fs.FS, glob string) ([]*compiler.Resource, error) {
+	matches, err := fs.Glob(fsys, glob)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pattern %q: %w", glob, err)
+	}
+	if len(matches) == 0 {
+		return nil, fmt.Errorf("no resource files match %s", glob)
+	}
+
+	fsLoader := *l
+	fsLoader.FS = fsys
+	var resources []*compiler.Resource
+	for _, match := range matches {
+		resource, err := fsLoader.Load(match)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", match, err)
+		}
+		resources = append(resources, resource)
+	}
+	return resources, nil
+}
+
+// context returns l.Context, or the background context if it is unset.
+func (l *Loader) context() context.Context {
+	if l.Context == nil {
+		return context.Background()
+	}
+	return l.Context
+}
+
+// readFile reads name from l.FS, or from the OS filesystem if FS is unset.
+// Like fetch, it stops one byte past the file size limit, so checkFileSize
+// rejects an oversized file without it being read whole.
+func (l *Loader) readFile(name string) ([]byte, error) {
+	var (
+		f   io.ReadCloser
+		err error
+	)
+	if l.FS != nil {
+		f, err = l.FS.Open(name)
+	} else {
+		f, err = os.Open(name)
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return l.readLimited(f)
+}
+
+// readLimited reads r up to one byte past the file size limit.
+func (l *Loader) readLimited(r io.Reader) ([]byte, error) {
+	if limit := l.Limits.WithDefaults().MaxFileSize; limit >= 0 {
+		r = io.LimitReader(r, int64(limit)+1)
+	}
+	return io.ReadAll(r)
+}
+
+// dir returns the directory of name, using slash paths within l.FS.
+func (l *Loader) dir(name string) string {
+	if l.FS != nil {
+		return path.Dir(name)
+	}
+	return filepath.Dir(name)
+}
+
+// join resolves name against dir unless it is absolute.
+func (l *Loader) join(dir, name string) string {
+	if l.FS != nil {
+		return path.Join(dir, name)
+	}
+	if filepath.IsAbs(name) {
+		return name
+	}
+	return filepath.Join(dir, name)
+}
+
+// FetchTimeout bounds each fetch of remote content by a Loader without a
+// Client, so a stalled server cannot hang a build.
+const FetchTimeout = 30 * time.Second
+
+// defaultClient fetches remote content for Loaders without a Client.
+var defaultClient = &http.Client{Timeout: FetchTimeout}
+
+// isRemote reports whether path refers to remote content.
+func isRemote(path string) bool {
+	return strings.HasPrefix(path, "https://") || strings.HasPrefix(path, "http://")
+}
+
+// fetch downloads remote content over HTTPS and verifies it against the
+// checksum its "#sha256=HEX" fragment pins, if any, and the lockfile.
+func (l *Loader) fetch(rawURL string) ([]byte, error) {
+	if !strings.HasPrefix(rawURL, "https://") {
+		return nil, fmt.Errorf("remote content must use https: %s", rawURL)
+	}
+	url, pin := splitChecksum(rawURL)
+
+	client := l.Client
+	if client == nil {
+		client = defaultClient
+	}
+	req, err := http.NewRequestWithContext(l.context(), http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s: %w", url, err)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch %s: %s", url, resp.Status)
+	}
+	data, err := l.readLimited(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s: %w", url, err)
+	}
+	if err := checkFileSize(url, data, l.Limits.WithDefaults().MaxFileSize); err != nil {
+		return nil, err
+	}
+
+	if pin != "" {
+		if got := sha256Hex(data); got != pin {
+			return nil, fmt.Errorf("checksum mismatch for %s: the URL pins sha256 %s, fetched %s", url, pin, got)
+		}
+	}
+	if l.Lock != nil {
+		if err := l.Lock.verify(url, data, pin != ""); err != nil {
+			return nil, err
+		}
+	}
+	return data, nil
+}
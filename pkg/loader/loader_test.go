@@ -0,0 +1,385 @@
+package loader
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/jomadu/ai-resource-compiler-go/internal/format"
+	"github.com/jomadu/ai-resource-compiler-go/pkg/compiler"
+	"gopkg.in/yaml.v3"
+)
+
+func writeFile(t *testing.T, dir, name, content string) string {
+	path := filepath.Join(dir, name)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+	return path
+}
+
+func TestLoadRule(t *testing.T) {
+	dir := t.TempDir()
+	path := writeFile(t, dir, "rule.yaml", `apiVersion: ai-resource/draft
+kind: Rule
+metadata:
+  id: testRule
+  namespace: platform
+  name: Test Rule
+  description: A test rule
+spec:
+  enforcement: must
+  scope:
+    - files: ["**/*.go"]
+  body: Test rule body
+`)
+
+	resource, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if resource.Kind != "Rule" || resource.Metadata.ID != "testRule" || resource.Metadata.Namespace != "platform" {
+		t.Fatalf("unexpected resource: %+v", resource)
+	}
+
+	rule := resource.Spec.(*format.Rule)
+	if rule.Metadata.Name != "Test Rule" || rule.Metadata.Description != "A test rule" || rule.Metadata.Namespace != "platform" {
+		t.Errorf("Metadata = %+v", rule.Metadata)
+	}
+	if rule.Spec.Enforcement != "must" {
+		t.Errorf("Enforcement = %v, want must", rule.Spec.Enforcement)
+	}
+	if len(rule.Spec.Scope) != 1 || rule.Spec.Scope[0].Files[0] != "**/*.go" {
+		t.Errorf("Scope = %+v", rule.Spec.Scope)
+	}
+	if rule.Spec.Body.String == nil || *rule.Spec.Body.String != "Test rule body" {
+		t.Errorf("Body = %+v", rule.Spec.Body)
+	}
+}
+
+func TestLoadRulesetArrayBody(t *testing.T) {
+	dir := t.TempDir()
+	path := writeFile(t, dir, "ruleset.yaml", `apiVersion: ai-resource/draft
+kind: Ruleset
+metadata:
+  id: cleanCode
+spec:
+  fragments:
+    intro: Intro text
+  rules:
+    naming:
+      name: Naming
+      enforcement: should
+      body:
+        - $intro
+        - Use good names.
+`)
+
+	resource, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	ruleset := resource.Spec.(*format.Ruleset)
+	body := format.ResolveBody(ruleset.Spec.Rules["naming"].Body, ruleset.Spec.Fragments)
+	if body != "Intro text\n\nUse good names." {
+		t.Errorf("body = %q", body)
+	}
+}
+
+func TestLoadAnchorsAndMergeKeys(t *testing.T) {
+	dir := t.TempDir()
+	path := writeFile(t, dir, "rules.yaml", `apiVersion: ai-resource/draft
+kind: Ruleset
+metadata:
+  id: goStyle
+defaults: &defaults
+  enforcement: must
+  scope: &goFiles
+    - files: ["**/*.go"]
+spec:
+  rules:
+    formatting:
+      <<: *defaults
+      name: Formatting
+      body: Run gofmt.
+    naming:
+      name: Naming
+      enforcement: should
+      scope: *goFiles
+      body: Use MixedCaps.
+`)
+
+	resource, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	rules := resource.Spec.(*format.Ruleset).Spec.Rules
+	formatting, naming := rules["formatting"], rules["naming"]
+	if formatting.Enforcement != "must" || formatting.Name != "Formatting" {
+		t.Errorf("merged rule = %+v, want defaults merged with its own fields", formatting)
+	}
+	for id, rule := range map[string]format.RuleItem{"formatting": formatting, "naming": naming} {
+		if len(rule.Scope) != 1 || len(rule.Scope[0].Files) != 1 || rule.Scope[0].Files[0] != "**/*.go" {
+			t.Errorf("%s scope = %+v, want aliased scope", id, rule.Scope)
+		}
+	}
+	if naming.Enforcement != "should" {
+		t.Errorf("naming enforcement = %s, want should", naming.Enforcement)
+	}
+}
+
+func TestLoadAliasExpansionLimit(t *testing.T) {
+	var b strings.Builder
+	b.WriteString("apiVersion: ai-resource/draft\nkind: Rule\nmetadata:\n  id: lol\n")
+	b.WriteString(`l0: &l0 ["lol","lol","lol","lol","lol","lol","lol","lol","lol","lol"]` + "\n")
+	for i := 1; i < 8; i++ {
+		fmt.Fprintf(&b, "l%d: &l%d [", i, i)
+		for j := 0; j < 10; j++ {
+			if j > 0 {
+				b.WriteString(",")
+			}
+			fmt.Fprintf(&b, "*l%d", i-1)
+		}
+		b.WriteString("]\n")
+	}
+	b.WriteString("spec:\n  enforcement: must\n  body: x\n")
+
+	_, err := (&Loader{}).Parse([]byte(b.String()), ".")
+	var parseErr *ParseError
+	if !errors.As(err, &parseErr) || !strings.Contains(err.Error(), "through aliases") {
+		t.Fatalf("Parse() error = %v, want alias expansion error", err)
+	}
+}
+
+func TestLoadLimits(t *testing.T) {
+	rule := "apiVersion: ai-resource/draft\nkind: Rule\nmetadata:\n  id: r\nspec:\n  enforcement: must\n  body: x\n"
+	nested := rule + "deep: " + strings.Repeat("[", 10) + strings.Repeat("]", 10) + "\n"
+
+	tests := []struct {
+		name   string
+		limits compiler.Limits
+		data   string
+		want   string
+	}{
+		{"file size", compiler.Limits{MaxFileSize: 10}, rule, "resource file is"},
+		{"depth", compiler.Limits{MaxDepth: 5}, nested, "nesting is deeper than 5 levels"},
+		{"expansion", compiler.Limits{MaxExpandedNodes: 10}, rule, "through aliases"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := (&Loader{Limits: tt.limits}).Parse([]byte(tt.data), ".")
+			if !errors.Is(err, compiler.ErrLimitExceeded) || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Parse() error = %v, want %q limit error", err, tt.want)
+			}
+		})
+	}
+
+	if _, err := (&Loader{}).Parse([]byte(nested), "."); err != nil {
+		t.Errorf("Parse() with default limits error = %v", err)
+	}
+	if _, err := (&Loader{Limits: compiler.Limits{MaxFileSize: -1, MaxDepth: -1}}).Parse([]byte(nested), "."); err != nil {
+		t.Errorf("Parse() with disabled limits error = %v", err)
+	}
+}
+
+func TestReadFileStopsPastLimit(t *testing.T) {
+	fsys := fstest.MapFS{"big.yaml": {Data: []byte(strings.Repeat("x", 100))}}
+	l := &Loader{FS: fsys, Limits: compiler.Limits{MaxFileSize: 10}}
+	data, err := l.readFile("big.yaml")
+	if err != nil {
+		t.Fatalf("readFile() error = %v", err)
+	}
+	if len(data) != 11 {
+		t.Errorf("readFile() read %d bytes, want 11", len(data))
+	}
+	if _, err := l.Load("big.yaml"); !errors.Is(err, compiler.ErrLimitExceeded) {
+		t.Errorf("Load() error = %v, want limit error", err)
+	}
+}
+
+func TestLoadDuplicateKeys(t *testing.T) {
+	_, err := (&Loader{}).Parse([]byte(`apiVersion: ai-resource/draft
+kind: Ruleset
+metadata:
+  id: rules
+defaults: &defaults
+  enforcement: must
+spec:
+  rules:
+    rule1:
+      <<: *defaults
+      body: one
+    rule1:
+      enforcement: should
+      body: two
+`), ".")
+	var parseErr *ParseError
+	if !errors.As(err, &parseErr) {
+		t.Fatalf("Parse() error = %v, want ParseError", err)
+	}
+	if parseErr.Line != 12 || parseErr.Column != 5 {
+		t.Errorf("position = %d:%d, want 12:5", parseErr.Line, parseErr.Column)
+	}
+	if want := `duplicate key "rule1" (first defined at line 9)`; !strings.Contains(err.Error(), want) {
+		t.Errorf("Parse() error = %v, want %q", err, want)
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	dir := t.TempDir()
+
+	if _, err := Load(filepath.Join(dir, "missing.yaml")); err == nil || !strings.Contains(err.Error(), "failed to read resource file") {
+		t.Errorf("Load() missing file error = %v", err)
+	}
+
+	invalid := writeFile(t, dir, "invalid.yaml", "invalid: yaml: content:")
+	if _, err := Load(invalid); err == nil || !strings.Contains(err.Error(), "failed to parse resource file") {
+		t.Errorf("Load() invalid YAML error = %v", err)
+	}
+
+	unknown := writeFile(t, dir, "unknown.yaml", "apiVersion: ai-resource/draft\nkind: Widget\nmetadata:\n  id: w\n")
+	if _, err := Load(unknown); err == nil || !strings.Contains(err.Error(), "unsupported kind") {
+		t.Errorf("Load() unknown kind error = %v", err)
+	}
+
+	misspelled := writeFile(t, dir, "misspelled.yaml", "apiVersion: ai-resource/draft\nkind: Rulset\nmetadata:\n  id: r\n")
+	if _, err := Load(misspelled); err == nil || !strings.Contains(err.Error(), "did you mean Ruleset?") {
+		t.Errorf("Load() misspelled kind error = %v, want a suggestion", err)
+	}
+
+	wrongType := writeFile(t, dir, "wrongtype.yaml", "apiVersion: ai-resource/draft\nkind: Rule\nmetadata:\n  id: r\nspec:\n  enforcement: must\n  scope:\n    - files: \"**/*.go\"\n  body: text\n")
+	_, err := Load(wrongType)
+	var parseErr *ParseError
+	if !errors.As(err, &parseErr) || parseErr.Line != 8 || !strings.Contains(err.Error(), "spec.scope.0.files must be a list of strings") {
+		t.Errorf("Load() wrong type error = %v, want the field at line 8", err)
+	}
+}
+
+func TestLoadUnknownFields(t *testing.T) {
+	data := []byte(`apiVersion: ai-resource/draft
+kind: Ruleset
+metadata:
+  id: rules
+  decription: Misspelled
+spec:
+  rules:
+    naming:
+      enforcment: must
+      enforcement: must
+      body: Body
+`)
+
+	_, err := (&Loader{}).Parse(data, ".")
+	var unknownErr *UnknownFieldError
+	if !errors.As(err, &unknownErr) {
+		t.Fatalf("Parse() error = %v, want UnknownFieldError", err)
+	}
+	var fields []string
+	for _, f := range unknownErr.Fields {
+		fields = append(fields, f.Field)
+	}
+	if strings.Join(fields, ", ") != "metadata.decription, spec.rules.naming.enforcment" {
+		t.Errorf("unknown fields = %v", fields)
+	}
+	var parseErr *ParseError
+	if !errors.As(err, &parseErr) || parseErr.Line != 5 || parseErr.Column != 3 {
+		t.Errorf("Parse() error = %v, want ParseError at 5:3", err)
+	}
+	if want := "line 9: unknown field spec.rules.naming.enforcment (did you mean enforcement?)"; !strings.Contains(err.Error(), want) {
+		t.Errorf("Parse() error = %v, want %q", err, want)
+	}
+
+	resource, err := (&Loader{Lenient: true}).Parse(data, ".")
+	if err != nil {
+		t.Fatalf("lenient Parse() error = %v", err)
+	}
+	if got := resource.Spec.(*format.Ruleset).Spec.Rules["naming"].Enforcement; got != "must" {
+		t.Errorf("enforcement = %q, want must", got)
+	}
+}
+
+type setEnforcement string
+
+func (p setEnforcement) Apply(doc *yaml.Node) (bool, error) {
+	spec := doc.Content[0].Content
+	for i := 0; i+1 < len(spec); i += 2 {
+		if spec[i].Value == "spec" {
+			return true, spec[i+1].Encode(map[string]string{"enforcement": string(p), "body": "patched"})
+		}
+	}
+	return false, nil
+}
+
+func TestLoaderPatches(t *testing.T) {
+	dir := t.TempDir()
+	path := writeFile(t, dir, "rule.yaml", `apiVersion: ai-resource/draft
+kind: Rule
+metadata:
+  id: testRule
+spec:
+  enforcement: may
+  body: original
+`)
+
+	l := &Loader{Patches: []Patch{setEnforcement("must")}}
+	resource, err := l.Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if got := resource.Spec.(*format.Rule).Spec.Enforcement; got != "must" {
+		t.Errorf("Enforcement = %v, want patched value must", got)
+	}
+}
+
+func TestLoadFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"rules/b.yaml": {Data: []byte(`apiVersion: ai-resource/draft
+kind: Rule
+include: [../lib/common.yaml]
+metadata:
+  id: second
+spec:
+  enforcement: must
+  body: [$shared]
+`)},
+		"rules/a.yaml": {Data: []byte(`apiVersion: ai-resource/draft
+kind: Rule
+metadata:
+  id: first
+spec:
+  enforcement: should
+  body: First rule.
+`)},
+		"lib/common.yaml": {Data: []byte("shared: Shared text.\n")},
+	}
+
+	resources, err := LoadFS(fsys, "rules/*.yaml")
+	if err != nil {
+		t.Fatalf("LoadFS() error = %v", err)
+	}
+	if len(resources) != 2 {
+		t.Fatalf("LoadFS() returned %d resources, want 2", len(resources))
+	}
+	if resources[0].Metadata.ID != "first" || resources[1].Metadata.ID != "second" {
+		t.Errorf("IDs = %s, %s, want first, second", resources[0].Metadata.ID, resources[1].Metadata.ID)
+	}
+	if resources[1].Source != "rules/b.yaml" {
+		t.Errorf("Source = %q, want rules/b.yaml", resources[1].Source)
+	}
+	rule := resources[1].Spec.(*format.Rule)
+	if got := format.ResolveBody(rule.Spec.Body, rule.Spec.Fragments); got != "Shared text." {
+		t.Errorf("body = %q, want included fragment", got)
+	}
+
+	if _, err := LoadFS(fsys, "prompts/*.yaml"); err == nil || !strings.Contains(err.Error(), "no resource files match") {
+		t.Errorf("LoadFS() error = %v, want no match error", err)
+	}
+}
@@ -0,0 +1,136 @@
+package loader
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// LockfileName is the conventional name of the lockfile written next to the
+// workspace config.
+const LockfileName = "arc.lock"
+
+// Lockfile pins the SHA-256 checksums of remote content fetched while
+// loading, so later loads either reproduce the same inputs or fail.
+type Lockfile struct {
+	Version int                  `yaml:"version"`
+	Remote  map[string]LockEntry `yaml:"remote,omitempty"`
+
+	// Frozen rejects remote content that is not already pinned instead of
+	// recording it.
+	Frozen bool `yaml:"-"`
+
+	changed bool
+}
+
+// LockEntry is the pinned state of one remote URL.
+type LockEntry struct {
+	SHA256 string `yaml:"sha256"`
+}
+
+// ReadLockfile reads a lockfile. A missing file yields an empty lockfile.
+func ReadLockfile(path string) (*Lockfile, error) {
+	lock := &Lockfile{Version: 1, Remote: make(map[string]LockEntry)}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return lock, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read lockfile: %w", err)
+	}
+	if err := yaml.Unmarshal(data, lock); err != nil {
+		return nil, fmt.Errorf("failed to parse lockfile %s: %w", path, err)
+	}
+	if lock.Version != 1 {
+		return nil, fmt.Errorf("unsupported lockfile version %d in %s", lock.Version, path)
+	}
+	if lock.Remote == nil {
+		lock.Remote = make(map[string]LockEntry)
+	}
+	return lock, nil
+}
+
+// Changed reports whether new entries were recorded since the lockfile was read.
+func (l *Lockfile) Changed() bool {
+	return l.changed
+}
+
+// WriteFile writes the lockfile to path.
+func (l *Lockfile) WriteFile(path string) error {
+	var buf bytes.Buffer
+	buf.WriteString("# Generated by arc. Pins remote resource content; commit this file.\n")
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(l); err != nil {
+		return fmt.Errorf("failed to encode lockfile: %w", err)
+	}
+	if err := encoder.Close(); err != nil {
+		return fmt.Errorf("failed to encode lockfile: %w", err)
+	}
+	if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		return fmt.Errorf("failed to write lockfile: %w", err)
+	}
+	l.changed = false
+	return nil
+}
+
+// Checksum returns the SHA-256 pinned for url, which content fetched while
+// loading with the lockfile is recorded under. A "#sha256=HEX" fragment of
+// url is ignored.
+func (l *Lockfile) Checksum(url string) (string, bool) {
+	entry, ok := l.Remote[stripChecksum(url)]
+	return entry.SHA256, ok
+}
+
+// verify checks fetched content against the pinned checksum, recording a
+// new pin when the URL is not locked yet. Frozen lockfiles accept new URLs
+// only if pinned, as when their URL pins a checksum itself.
+func (l *Lockfile) verify(url string, data []byte, pinned bool) error {
+	got := sha256Hex(data)
+
+	entry, ok := l.Remote[url]
+	if !ok {
+		if l.Frozen && !pinned {
+			return fmt.Errorf("%s is not pinned in %s", url, LockfileName)
+		}
+		l.Remote[url] = LockEntry{SHA256: got}
+		l.changed = true
+		return nil
+	}
+	if entry.SHA256 != got {
+		return fmt.Errorf("checksum mismatch for %s: %s pins sha256 %s, fetched %s (remove the entry to accept the new content)", url, LockfileName, entry.SHA256, got)
+	}
+	return nil
+}
+
+// checksumFragment introduces the checksum a URL pins in its fragment, as in
+// https://rules.example.com/clean-code.yaml#sha256=HEX.
+const checksumFragment = "#sha256="
+
+// splitChecksum returns url without the checksum its fragment pins, and
+// that checksum, or "" if it pins none.
+func splitChecksum(url string) (string, string) {
+	if i := strings.LastIndex(url, checksumFragment); i >= 0 {
+		return url[:i], strings.ToLower(url[i+len(checksumFragment):])
+	}
+	return url, ""
+}
+
+// stripChecksum returns url without the checksum its fragment pins.
+func stripChecksum(url string) string {
+	url, _ = splitChecksum(url)
+	return url
+}
+
+// sha256Hex returns the hex-encoded SHA-256 of data.
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,211 @@
+package loader
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jomadu/ai-resource-compiler-go/internal/format"
+)
+
+func remoteRule(url string) string {
+	return fmt.Sprintf(`apiVersion: ai-resource/draft
+kind: Rule
+include:
+  - %s/lib/common.yaml
+metadata:
+  id: remoteRule
+spec:
+  enforcement: must
+  body: [$shared]
+`, url)
+}
+
+func TestRemoteIncludeRecordsAndVerifiesLock(t *testing.T) {
+	library := "shared: Remote text.\n"
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, library)
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	path := writeFile(t, dir, "rule.yaml", remoteRule(server.URL))
+	lockPath := filepath.Join(dir, LockfileName)
+
+	lock, err := ReadLockfile(lockPath)
+	if err != nil {
+		t.Fatalf("ReadLockfile() error = %v", err)
+	}
+	l := &Loader{Client: server.Client(), Lock: lock}
+
+	resource, err := l.Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	rule := resource.Spec.(*format.Rule)
+	if got := format.ResolveBody(rule.Spec.Body, rule.Spec.Fragments); got != "Remote text." {
+		t.Errorf("body = %q, want remote fragment", got)
+	}
+	if !lock.Changed() {
+		t.Fatal("Changed() = false after recording a new pin")
+	}
+	if err := lock.WriteFile(lockPath); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	lock, err = ReadLockfile(lockPath)
+	if err != nil {
+		t.Fatalf("ReadLockfile() error = %v", err)
+	}
+	if len(lock.Remote) != 1 {
+		t.Fatalf("Remote = %v, want one pinned URL", lock.Remote)
+	}
+	l.Lock = lock
+	if _, err := l.Load(path); err != nil {
+		t.Fatalf("Load() with matching lock error = %v", err)
+	}
+	if lock.Changed() {
+		t.Error("Changed() = true when content matched the pin")
+	}
+
+	library = "shared: Tampered text.\n"
+	_, err = l.Load(path)
+	if err == nil {
+		t.Fatal("Load() expected checksum mismatch error")
+	}
+	if !strings.Contains(err.Error(), "checksum mismatch") {
+		t.Errorf("Error = %v, want checksum mismatch", err)
+	}
+}
+
+func TestRemoteIncludeFrozenLock(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "shared: Remote text.\n")
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	path := writeFile(t, dir, "rule.yaml", remoteRule(server.URL))
+
+	lock, err := ReadLockfile(filepath.Join(dir, LockfileName))
+	if err != nil {
+		t.Fatalf("ReadLockfile() error = %v", err)
+	}
+	lock.Frozen = true
+
+	_, err = (&Loader{Client: server.Client(), Lock: lock}).Load(path)
+	if err == nil {
+		t.Fatal("Load() expected error for unpinned URL with frozen lock")
+	}
+	if !strings.Contains(err.Error(), "is not pinned") {
+		t.Errorf("Error = %v, want not pinned error", err)
+	}
+}
+
+func TestRemoteIncludeErrors(t *testing.T) {
+	server := httptest.NewTLSServer(http.NotFoundHandler())
+	defer server.Close()
+
+	dir := t.TempDir()
+	path := writeFile(t, dir, "rule.yaml", remoteRule(server.URL))
+	_, err := (&Loader{Client: server.Client()}).Load(path)
+	if err == nil || !strings.Contains(err.Error(), "404") {
+		t.Errorf("Load() error = %v, want HTTP status error", err)
+	}
+
+	insecure := writeFile(t, dir, "insecure.yaml", remoteRule("http://example.com"))
+	_, err = Load(insecure)
+	if err == nil || !strings.Contains(err.Error(), "must use https") {
+		t.Errorf("Load() error = %v, want https required error", err)
+	}
+}
+
+func TestLoadRemoteResource(t *testing.T) {
+	files := map[string]string{
+		"/packs/clean-code.yaml": "apiVersion: ai-resource/draft\nkind: Rule\ninclude: [lib/common.yaml]\nmetadata:\n  id: cleanCode\nspec:\n  enforcement: must\n  body: [$shared]\n",
+		"/packs/lib/common.yaml": "shared: Remote text.\n",
+	}
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		content, ok := files[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprint(w, content)
+	}))
+	defer server.Close()
+	url := server.URL + "/packs/clean-code.yaml"
+	lock := &Lockfile{Version: 1, Remote: make(map[string]LockEntry)}
+	l := &Loader{Client: server.Client(), Lock: lock}
+
+	resource, err := l.Load(url)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	rule := resource.Spec.(*format.Rule)
+	if got := format.ResolveBody(rule.Spec.Body, rule.Spec.Fragments); got != "Remote text." {
+		t.Errorf("body = %q, want the fragment of the include relative to the URL", got)
+	}
+	if resource.Source != url {
+		t.Errorf("Source = %q, want %q", resource.Source, url)
+	}
+	sum, ok := lock.Checksum(url)
+	if want := sha256Hex([]byte(files["/packs/clean-code.yaml"])); !ok || sum != want {
+		t.Errorf("Checksum() = %q, %v, want %q", sum, ok, want)
+	}
+
+	// A checksum in the URL pins the content, even for a frozen lockfile.
+	frozen := &Lockfile{Version: 1, Remote: make(map[string]LockEntry), Frozen: true}
+	pinned := url + "#sha256=" + sum
+	if _, err := (&Loader{Client: server.Client(), Lock: frozen}).Load(pinned); err == nil || !strings.Contains(err.Error(), "common.yaml is not pinned") {
+		t.Errorf("Load() error = %v, want only the unpinned include rejected", err)
+	}
+	if got, _ := frozen.Checksum(pinned); got != sum {
+		t.Errorf("Checksum() = %q, want the URL's pin recorded", got)
+	}
+	files["/packs/clean-code.yaml"] += "# changed\n"
+	if _, err := (&Loader{Client: server.Client()}).Load(pinned); err == nil || !strings.Contains(err.Error(), "the URL pins sha256 "+sum) {
+		t.Errorf("Load() error = %v, want checksum mismatch", err)
+	}
+
+	if _, err := (&Loader{Client: server.Client()}).Load(server.URL + "/packs/missing.yaml"); err == nil || !strings.Contains(err.Error(), "404") {
+		t.Errorf("Load() error = %v, want HTTP status error", err)
+	}
+	if _, err := (&Loader{Client: server.Client()}).Load(server.URL + "/packs/rule.cue"); err == nil || !strings.Contains(err.Error(), "not supported") {
+		t.Errorf("Load() error = %v, want remote CUE rejected", err)
+	}
+}
+
+func TestLoadRemoteCanceled(t *testing.T) {
+	stalled := make(chan struct{})
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-stalled:
+		}
+	}))
+	defer server.Close()
+	defer close(stalled)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err := (&Loader{Client: server.Client(), Context: ctx}).Load(server.URL + "/rule.yaml")
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Load() error = %v, want the context's deadline exceeded", err)
+	}
+}
+
+func TestReadLockfileErrors(t *testing.T) {
+	dir := t.TempDir()
+	path := writeFile(t, dir, LockfileName, "version: 2\n")
+
+	if _, err := ReadLockfile(path); err == nil || !strings.Contains(err.Error(), "unsupported lockfile version") {
+		t.Errorf("ReadLockfile() error = %v, want version error", err)
+	}
+}
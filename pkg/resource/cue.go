@@ -0,0 +1,117 @@
+package resource
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// CUESchema returns CUE definitions for the resource format, generated from
+// the spec types in this package so the two cannot drift. Fields are named by
+// their yaml tags and are optional when tagged omitempty; a cue tag replaces
+// a field's generated constraint. pkg, if set, is written as the package
+// clause so the schema can sit beside resources of that package.
+//
+// Resources are constrained by embedding one of #Rule, #Ruleset, #Prompt,
+// #Promptset, #Command, #Context, or #Resource.
+func CUESchema(pkg string) string {
+	g := &cueGenerator{defined: make(map[reflect.Type]bool)}
+	kinds := []any{Rule{}, Ruleset{}, Prompt{}, Promptset{}, Command{}, Context{}}
+
+	var b strings.Builder
+	b.WriteString("// Code generated from github.com/jomadu/ai-resource-compiler-go/pkg/resource. DO NOT EDIT.\n\n")
+	if pkg != "" {
+		fmt.Fprintf(&b, "package %s\n\n", pkg)
+	}
+
+	var names []string
+	for _, kind := range kinds {
+		t := reflect.TypeOf(kind)
+		names = append(names, "#"+t.Name())
+		fmt.Fprintf(&b, "#%s: {\n\tapiVersion: %q\n\tkind: %q\n\tinclude?: [...string]\n", t.Name(), "ai-resource/draft", t.Name())
+		g.writeFields(&b, t)
+		b.WriteString("}\n\n")
+	}
+	fmt.Fprintf(&b, "#Resource: %s\n", strings.Join(names, " | "))
+
+	for _, def := range g.defs {
+		b.WriteString("\n" + def)
+	}
+	return b.String()
+}
+
+// cueGenerator collects the definitions of the struct types a schema uses,
+// in the order they are first referenced.
+type cueGenerator struct {
+	defined map[reflect.Type]bool
+	defs    []string
+}
+
+// writeFields writes a line per field of struct type t.
+func (g *cueGenerator) writeFields(b *strings.Builder, t reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.Tag.Get("yaml") == "-" {
+			continue
+		}
+		name, optional := cueFieldName(field)
+		if optional {
+			name += "?"
+		}
+		constraint := field.Tag.Get("cue")
+		if constraint == "" {
+			constraint = g.cueType(field.Type)
+		}
+		fmt.Fprintf(b, "\t%s: %s\n", name, constraint)
+	}
+}
+
+// cueType returns the CUE constraint for t, defining named structs as they
+// are first seen.
+func (g *cueGenerator) cueType(t reflect.Type) string {
+	switch {
+	case t == reflect.TypeOf(Body{}):
+		return "string | [...string]"
+	case t == reflect.TypeOf(Asset{}):
+		return "string | " + g.cueStruct(t)
+	case t.Kind() == reflect.String:
+		return "string"
+	case t.Kind() == reflect.Bool:
+		return "bool"
+	case t.Kind() == reflect.Int:
+		return "int"
+	case t.Kind() == reflect.Slice:
+		return "[..." + g.cueType(t.Elem()) + "]"
+	case t.Kind() == reflect.Map:
+		return "{[string]: " + g.cueType(t.Elem()) + "}"
+	case t.Kind() == reflect.Struct:
+		return g.cueStruct(t)
+	}
+	return "_"
+}
+
+// cueStruct returns a reference to the definition of struct type t,
+// defining it if it is first seen.
+func (g *cueGenerator) cueStruct(t reflect.Type) string {
+	if !g.defined[t] {
+		g.defined[t] = true
+		var b strings.Builder
+		fmt.Fprintf(&b, "#%s: {\n", t.Name())
+		g.writeFields(&b, t)
+		b.WriteString("}\n")
+		g.defs = append(g.defs, b.String())
+	}
+	return "#" + t.Name()
+}
+
+// cueFieldName returns a field's YAML name and whether it may be omitted.
+// Untagged fields use the lowercased field name, as yaml.v3 does; of those,
+// only the fragments of RulesetSpec and PromptsetSpec are optional.
+func cueFieldName(field reflect.StructField) (string, bool) {
+	tag, ok := field.Tag.Lookup("yaml")
+	if !ok {
+		return strings.ToLower(field.Name), field.Name == "Fragments"
+	}
+	name, options, _ := strings.Cut(tag, ",")
+	return name, strings.Contains(options, "omitempty")
+}
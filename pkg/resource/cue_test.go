@@ -0,0 +1,52 @@
+package resource
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "rewrite the schemas in schema/")
+
+// TestCUESchemaUpToDate checks that the published schema matches the Go
+// types. Run with -update after changing them.
+func TestCUESchemaUpToDate(t *testing.T) {
+	path := filepath.Join("..", "..", "schema", "resource.cue")
+	got := CUESchema("")
+	if *update {
+		if err := os.WriteFile(path, []byte(got), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != string(want) {
+		t.Errorf("%s is out of date; run go test ./pkg/resource -update", path)
+	}
+}
+
+func TestCUESchema(t *testing.T) {
+	schema := CUESchema("rules")
+	for _, want := range []string{
+		"package rules\n",
+		"#Resource: #Rule | #Ruleset | #Prompt | #Promptset | #Command | #Context\n",
+		"\tkind: \"Ruleset\"\n",
+		"\tid: string & =~\"^[A-Za-z0-9_-]+$\"\n",
+		"\tname?: string\n",
+		"\tbody: string | [...string]\n",
+		"\trules: {[string]: #RuleItem}\n",
+		"\tfragments?: {[string]: string}\n",
+		"\tscope?: [...#ScopeEntry]\n",
+	} {
+		if !strings.Contains(schema, want) {
+			t.Errorf("schema missing %q:\n%s", want, schema)
+		}
+	}
+	if strings.Count(schema, "#RuleItem: {") != 1 {
+		t.Error("#RuleItem defined more than once")
+	}
+}
@@ -0,0 +1,154 @@
+package resource
+
+import (
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// JSONSchemaID is the $id of the JSON Schema, where it is published.
+const JSONSchemaID = "https://raw.githubusercontent.com/jomadu/ai-resource-compiler-go/main/schema/resource.schema.json"
+
+// Schema is a JSON Schema, holding the keywords the resource schema uses.
+// AdditionalProperties is false, for closed objects, or a *Schema.
+type Schema struct {
+	Schema               string             `json:"$schema,omitempty"`
+	ID                   string             `json:"$id,omitempty"`
+	Title                string             `json:"title,omitempty"`
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Const                string             `json:"const,omitempty"`
+	Enum                 []string           `json:"enum,omitempty"`
+	Pattern              string             `json:"pattern,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties any                `json:"additionalProperties,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	AnyOf                []*Schema          `json:"anyOf,omitempty"`
+	AllOf                []*Schema          `json:"allOf,omitempty"`
+	If                   *Schema            `json:"if,omitempty"`
+	Then                 *Schema            `json:"then,omitempty"`
+	Defs                 map[string]*Schema `json:"$defs,omitempty"`
+}
+
+// JSONSchema returns the JSON Schema of the resource format, generated from
+// the spec types in this package as CUESchema is, for editors and other
+// tools that validate YAML against JSON Schema. A document is checked
+// against the definition of its kind; fields not in the format are
+// rejected, except at the top level, where other keys may define anchors
+// for the rest of the document. A cue tag of the form string & =~"pattern" becomes the field's
+// pattern.
+func JSONSchema() *Schema {
+	g := &jsonSchemaGenerator{defs: make(map[string]*Schema)}
+	kinds := []any{Rule{}, Ruleset{}, Prompt{}, Promptset{}, Command{}, Context{}}
+
+	root := &Schema{
+		Schema:     "https://json-schema.org/draft/2020-12/schema",
+		ID:         JSONSchemaID,
+		Title:      "AI resource",
+		Type:       "object",
+		Properties: map[string]*Schema{"kind": {}},
+		Required:   []string{"kind"},
+		Defs:       g.defs,
+	}
+	for _, kind := range kinds {
+		t := reflect.TypeOf(kind)
+		def := g.object(t)
+		def.Properties["apiVersion"] = &Schema{Const: "ai-resource/draft"}
+		def.Properties["kind"] = &Schema{Const: t.Name()}
+		def.Properties["include"] = &Schema{Type: "array", Items: &Schema{Type: "string"}}
+		def.Required = append([]string{"apiVersion", "kind"}, def.Required...)
+		def.AdditionalProperties = nil // other top-level keys hold anchors
+		g.defs[t.Name()] = def
+
+		root.Properties["kind"].Enum = append(root.Properties["kind"].Enum, t.Name())
+		root.AllOf = append(root.AllOf, &Schema{
+			If:   &Schema{Properties: map[string]*Schema{"kind": {Const: t.Name()}}, Required: []string{"kind"}},
+			Then: &Schema{Ref: "#/$defs/" + t.Name()},
+		})
+	}
+	return root
+}
+
+// MarshalJSONSchema returns JSONSchema as indented JSON, as published in
+// schema/resource.schema.json.
+func MarshalJSONSchema() []byte {
+	data, err := json.MarshalIndent(JSONSchema(), "", "  ")
+	if err != nil {
+		panic(err)
+	}
+	return append(data, '\n')
+}
+
+// jsonSchemaGenerator collects the definitions of the struct types a schema
+// uses.
+type jsonSchemaGenerator struct {
+	defs map[string]*Schema
+}
+
+// object returns the closed object schema of struct type t.
+func (g *jsonSchemaGenerator) object(t reflect.Type) *Schema {
+	s := &Schema{Type: "object", Properties: make(map[string]*Schema), AdditionalProperties: false}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.Tag.Get("yaml") == "-" {
+			continue
+		}
+		name, optional := cueFieldName(field)
+		if !optional {
+			s.Required = append(s.Required, name)
+		}
+		property := g.schema(field.Type)
+		if pattern, ok := cuePattern(field.Tag.Get("cue")); ok {
+			property.Pattern = pattern
+		}
+		s.Properties[name] = property
+	}
+	return s
+}
+
+// schema returns the schema for t, defining named structs as they are first
+// seen.
+func (g *jsonSchemaGenerator) schema(t reflect.Type) *Schema {
+	switch {
+	case t == reflect.TypeOf(Body{}):
+		return &Schema{AnyOf: []*Schema{{Type: "string"}, {Type: "array", Items: &Schema{Type: "string"}}}}
+	case t == reflect.TypeOf(Asset{}):
+		return &Schema{AnyOf: []*Schema{{Type: "string"}, g.ref(t)}}
+	case t.Kind() == reflect.String:
+		return &Schema{Type: "string"}
+	case t.Kind() == reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case t.Kind() == reflect.Int:
+		return &Schema{Type: "integer"}
+	case t.Kind() == reflect.Slice:
+		return &Schema{Type: "array", Items: g.schema(t.Elem())}
+	case t.Kind() == reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: g.schema(t.Elem())}
+	case t.Kind() == reflect.Struct:
+		return g.ref(t)
+	}
+	return &Schema{}
+}
+
+// ref returns a reference to the definition of struct type t, defining it
+// if it is first seen.
+func (g *jsonSchemaGenerator) ref(t reflect.Type) *Schema {
+	if _, ok := g.defs[t.Name()]; !ok {
+		g.defs[t.Name()] = nil // reserved against recursion
+		g.defs[t.Name()] = g.object(t)
+	}
+	return &Schema{Ref: "#/$defs/" + t.Name()}
+}
+
+// cuePattern returns the regular expression of a cue tag of the form
+// string & =~"pattern".
+func cuePattern(tag string) (string, bool) {
+	_, quoted, ok := strings.Cut(tag, "=~")
+	if !ok {
+		return "", false
+	}
+	pattern, err := strconv.Unquote(strings.TrimSpace(quoted))
+	return pattern, err == nil
+}
@@ -0,0 +1,163 @@
+package resource
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+// TestJSONSchemaUpToDate checks that the published JSON Schema matches the
+// Go types. Run with -update after changing them.
+func TestJSONSchemaUpToDate(t *testing.T) {
+	path := filepath.Join("..", "..", "schema", "resource.schema.json")
+	got := MarshalJSONSchema()
+	if *update {
+		if err := os.WriteFile(path, got, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != string(want) {
+		t.Errorf("%s is out of date; run go test ./pkg/resource -update", path)
+	}
+}
+
+func TestJSONSchema(t *testing.T) {
+	schema := JSONSchema()
+	if got := schema.Properties["kind"].Enum; !reflect.DeepEqual(got, []string{"Rule", "Ruleset", "Prompt", "Promptset", "Command", "Context"}) {
+		t.Errorf("kind enum = %v", got)
+	}
+	item := schema.Defs["RuleItem"]
+	if item == nil {
+		t.Fatal("RuleItem not defined")
+	}
+	if !reflect.DeepEqual(item.Required, []string{"enforcement", "body"}) {
+		t.Errorf("RuleItem required = %v, want enforcement, body", item.Required)
+	}
+	if item.AdditionalProperties != false {
+		t.Errorf("RuleItem additionalProperties = %v, want false", item.AdditionalProperties)
+	}
+	if got := schema.Defs["Metadata"].Properties["id"].Pattern; got != "^[A-Za-z0-9_-]+$" {
+		t.Errorf("id pattern = %q", got)
+	}
+}
+
+func TestCheckSchema(t *testing.T) {
+	tests := []struct {
+		name string
+		doc  string
+		want []string // field: message
+	}{
+		{
+			name: "valid",
+			doc: `apiVersion: ai-resource/draft
+kind: Rule
+metadata:
+  id: naming
+spec:
+  enforcement: must
+  scope:
+    - files: ["**/*.go"]
+  body: [Intro, $shared]
+  fragments:
+    shared: Shared
+`,
+		},
+		{
+			name: "unknown fields",
+			doc: `apiVersion: ai-resource/draft
+kind: Ruleset
+metadata:
+  id: clean
+spec:
+  rules:
+    naming:
+      enforcment: must
+      enforcement: must
+      bodyy: text
+      body: text
+`,
+			want: []string{
+				"spec.rules.naming.enforcment: unknown field spec.rules.naming.enforcment (did you mean enforcement?)",
+				"spec.rules.naming.bodyy: unknown field spec.rules.naming.bodyy (did you mean body?)",
+			},
+		},
+		{
+			name: "wrong types",
+			doc: `apiVersion: ai-resource/draft
+kind: Prompt
+metadata:
+  id: review
+spec:
+  body: {text: Review}
+  assets:
+    - [a.md]
+  tools: read
+`,
+			want: []string{
+				"spec.body: spec.body must be a string or a list of strings",
+				"spec.assets.0: spec.assets.0 must be a string or a mapping",
+				"spec.tools: spec.tools must be a list of strings",
+			},
+		},
+		{
+			name: "missing fields",
+			doc: `apiVersion: ai-resource/draft
+kind: Rule
+metadata:
+  name: Naming
+spec:
+  body: text
+`,
+			want: []string{
+				"metadata.id: missing required field metadata.id",
+				"spec.enforcement: missing required field spec.enforcement",
+			},
+		},
+		{
+			name: "kind and apiVersion",
+			doc: `apiVersion: v1
+kind: Rulez
+`,
+			want: []string{"kind: kind must be one of Rule, Ruleset, Prompt, Promptset, Command, Context, not \"Rulez\""},
+		},
+		{
+			name: "merge keys",
+			doc: `apiVersion: ai-resource/draft
+kind: Ruleset
+metadata:
+  id: clean
+spec:
+  rules:
+    base: &base
+      enforcement: should
+      body: text
+    naming:
+      <<: *base
+      name: Naming
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var doc yaml.Node
+			if err := yaml.Unmarshal([]byte(tt.doc), &doc); err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, err := range CheckSchema(&doc) {
+				got = append(got, err.Field+": "+err.Message)
+			}
+			if strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+				t.Errorf("CheckSchema() =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(tt.want, "\n"))
+			}
+		})
+	}
+}
@@ -0,0 +1,384 @@
+// Package resource defines the spec types of AI resources. A
+// compiler.Resource holds one of *Rule, *Ruleset, *Prompt, *Promptset,
+// *Command, or *Context in its Spec field, so target compilers switch on
+// these types.
+package resource
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Metadata identifies a resource.
+type Metadata struct {
+	ID          string `yaml:"id" cue:"string & =~\"^[A-Za-z0-9_-]+$\""`
+	Namespace   string `yaml:"namespace,omitempty"`
+	Name        string `yaml:"name,omitempty"`
+	Description string `yaml:"description,omitempty"`
+
+	// Tags label the resource, and every rule or prompt in it, for
+	// selection with CompileOptions.Tags.
+	Tags []string `yaml:"tags,omitempty"`
+}
+
+// Body is rule or prompt content, authored either as a single string or as
+// a list of literal strings and $fragment references.
+type Body struct {
+	String *string
+	Array  []string
+}
+
+// UnmarshalYAML decodes a body written either as a string or as a list of
+// literal strings and $fragment references.
+func (b *Body) UnmarshalYAML(node *yaml.Node) error {
+	switch node.Kind {
+	case yaml.ScalarNode:
+		var s string
+		if err := node.Decode(&s); err != nil {
+			return err
+		}
+		b.String = &s
+		b.Array = nil
+	case yaml.SequenceNode:
+		var arr []string
+		if err := node.Decode(&arr); err != nil {
+			return err
+		}
+		b.String = nil
+		b.Array = arr
+	default:
+		return fmt.Errorf("line %d: body must be a string or a list of strings", node.Line)
+	}
+	return nil
+}
+
+// MarshalYAML encodes the body in the same shape it was authored in.
+func (b Body) MarshalYAML() (interface{}, error) {
+	if b.String != nil {
+		return *b.String, nil
+	}
+	return b.Array, nil
+}
+
+// IsZero reports whether the body has no content, so omitempty drops it.
+func (b Body) IsZero() bool {
+	return b.String == nil && len(b.Array) == 0
+}
+
+// ScopeEntry limits a rule to matching files: those its Files globs match,
+// the source files of its Languages, and every file under its Directories,
+// less those its Exclude globs match.
+type ScopeEntry struct {
+	Files       []string `yaml:"files,omitempty"`
+	Exclude     []string `yaml:"exclude,omitempty"`
+	Languages   []string `yaml:"languages,omitempty"`
+	Directories []string `yaml:"directories,omitempty"`
+}
+
+// RuleItem is a rule within a Ruleset.
+type RuleItem struct {
+	Name        string       `yaml:"name,omitempty"`
+	Description string       `yaml:"description,omitempty"`
+	Enforcement string       `yaml:"enforcement"`
+	Scope       []ScopeEntry `yaml:"scope,omitempty"`
+	Body        Body         `yaml:"body"`
+
+	// Bodies holds translations of Body keyed by locale, e.g. "es".
+	Bodies map[string]Body `yaml:"bodies,omitempty"`
+
+	// Tags label the rule, in addition to those of its ruleset.
+	Tags []string `yaml:"tags,omitempty"`
+}
+
+// RuleSpec is the spec of a standalone Rule.
+type RuleSpec struct {
+	Enforcement string            `yaml:"enforcement"`
+	Scope       []ScopeEntry      `yaml:"scope,omitempty"`
+	Body        Body              `yaml:"body"`
+	Bodies      map[string]Body   `yaml:"bodies,omitempty"`
+	Fragments   map[string]string `yaml:"fragments,omitempty"`
+}
+
+// Rule is a standalone rule resource.
+type Rule struct {
+	Metadata Metadata
+	Spec     RuleSpec
+}
+
+// RulesetSpec is the spec of a Ruleset: rules keyed by ID and the fragments
+// they share.
+type RulesetSpec struct {
+	// Extends names the rulesets this one inherits rules and fragments
+	// from, by ID or "namespace/id"; see compiler.ResolveExtends.
+	Extends []string `yaml:"extends,omitempty"`
+
+	Rules     map[string]RuleItem
+	Fragments map[string]string
+
+	// Order lists the rule IDs in the order they are written in the source
+	// YAML. Use RuleIDs to visit rules in that order.
+	Order []string `yaml:"-"`
+}
+
+// UnmarshalYAML decodes the spec, recording the order of its rules.
+func (s *RulesetSpec) UnmarshalYAML(node *yaml.Node) error {
+	type plain RulesetSpec
+	if err := node.Decode((*plain)(s)); err != nil {
+		return err
+	}
+	s.Order = mappingKeys(node, "rules")
+	return nil
+}
+
+// MarshalYAML encodes the spec with its rules in the order of RuleIDs.
+func (s RulesetSpec) MarshalYAML() (interface{}, error) {
+	rules, err := orderedMapping(s.Rules, s.RuleIDs())
+	if err != nil {
+		return nil, err
+	}
+	return struct {
+		Extends   []string          `yaml:"extends,omitempty"`
+		Rules     *yaml.Node        `yaml:"rules"`
+		Fragments map[string]string `yaml:"fragments,omitempty"`
+	}{s.Extends, rules, s.Fragments}, nil
+}
+
+// RuleIDs returns the IDs of the rules in the order they are written. Rules
+// missing from Order, such as those added in code, follow sorted by ID.
+func (s RulesetSpec) RuleIDs() []string {
+	return orderedKeys(s.Rules, s.Order)
+}
+
+// Ruleset is a collection of rules.
+type Ruleset struct {
+	Metadata Metadata
+	Spec     RulesetSpec
+}
+
+// PromptItem is a prompt within a Promptset.
+type PromptItem struct {
+	Name         string   `yaml:"name,omitempty"`
+	Description  string   `yaml:"description,omitempty"`
+	AllowedTools []string `yaml:"allowedTools,omitempty"`
+	Arguments    string   `yaml:"arguments,omitempty"`
+	Body         Body     `yaml:"body"`
+
+	// Bodies holds translations of Body keyed by locale, e.g. "es".
+	Bodies map[string]Body `yaml:"bodies,omitempty"`
+
+	// Tags label the prompt, in addition to those of its promptset.
+	Tags []string `yaml:"tags,omitempty"`
+
+	// Assets are shipped with the prompt, after those of its promptset.
+	Assets []Asset `yaml:"assets,omitempty"`
+
+	// Mode, Model, and Tools configure how Copilot runs the prompt; see
+	// PromptSpec.
+	Mode  string   `yaml:"mode,omitempty"`
+	Model string   `yaml:"model,omitempty"`
+	Tools []string `yaml:"tools,omitempty"`
+}
+
+// PromptSpec is the spec of a standalone Prompt.
+type PromptSpec struct {
+	AllowedTools []string          `yaml:"allowedTools,omitempty"`
+	Arguments    string            `yaml:"arguments,omitempty"`
+	Body         Body              `yaml:"body"`
+	Bodies       map[string]Body   `yaml:"bodies,omitempty"`
+	Fragments    map[string]string `yaml:"fragments,omitempty"`
+	Assets       []Asset           `yaml:"assets,omitempty"`
+
+	// Mode is the chat mode Copilot runs the prompt in: ask, edit, or
+	// agent. Model names the model to run it with, and Tools the tools it
+	// may use, e.g. codebase or githubRepo. Targets without such settings
+	// ignore them.
+	Mode  string   `yaml:"mode,omitempty"`
+	Model string   `yaml:"model,omitempty"`
+	Tools []string `yaml:"tools,omitempty"`
+}
+
+// Prompt is a standalone prompt resource.
+type Prompt struct {
+	Metadata Metadata
+	Spec     PromptSpec
+}
+
+// PromptsetSpec is the spec of a Promptset: prompts keyed by ID and the
+// fragments they share.
+type PromptsetSpec struct {
+	Prompts   map[string]PromptItem
+	Fragments map[string]string
+
+	// Assets are shipped with every prompt of the set.
+	Assets []Asset `yaml:"assets,omitempty"`
+
+	// Order lists the prompt IDs in the order they are written in the
+	// source YAML. Use PromptIDs to visit prompts in that order.
+	Order []string `yaml:"-"`
+}
+
+// UnmarshalYAML decodes the spec, recording the order of its prompts.
+func (s *PromptsetSpec) UnmarshalYAML(node *yaml.Node) error {
+	type plain PromptsetSpec
+	if err := node.Decode((*plain)(s)); err != nil {
+		return err
+	}
+	s.Order = mappingKeys(node, "prompts")
+	return nil
+}
+
+// MarshalYAML encodes the spec with its prompts in the order of PromptIDs.
+func (s PromptsetSpec) MarshalYAML() (interface{}, error) {
+	prompts, err := orderedMapping(s.Prompts, s.PromptIDs())
+	if err != nil {
+		return nil, err
+	}
+	return struct {
+		Prompts   *yaml.Node        `yaml:"prompts"`
+		Fragments map[string]string `yaml:"fragments,omitempty"`
+		Assets    []Asset           `yaml:"assets,omitempty"`
+	}{prompts, s.Fragments, s.Assets}, nil
+}
+
+// PromptIDs returns the IDs of the prompts in the order they are written.
+// Prompts missing from Order, such as those added in code, follow sorted by
+// ID.
+func (s PromptsetSpec) PromptIDs() []string {
+	return orderedKeys(s.Prompts, s.Order)
+}
+
+// Promptset is a collection of prompts.
+type Promptset struct {
+	Metadata Metadata
+	Spec     PromptsetSpec
+}
+
+// Asset is a file shipped alongside a compiled prompt, such as a script or
+// reference document a Claude skill points to. It is written either as the
+// path of the file or as a mapping with a path and a name.
+type Asset struct {
+	// Path is the file to ship, relative to the resource file.
+	Path string `yaml:"path"`
+
+	// Name is where the file goes, relative to the compiled prompt and
+	// slash-separated, e.g. "scripts/check.sh". It defaults to the file name
+	// of Path.
+	Name string `yaml:"name,omitempty"`
+
+	// Data is the content of the file, which may be binary. The loader reads
+	// it from Path; resources built in code set it themselves.
+	Data []byte `yaml:"-"`
+}
+
+// UnmarshalYAML decodes an asset written as a path or as a mapping.
+func (a *Asset) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		a.Name = ""
+		return node.Decode(&a.Path)
+	}
+	type plain Asset
+	return node.Decode((*plain)(a))
+}
+
+// FileName returns Name, or the file name of Path if Name is empty.
+func (a Asset) FileName() string {
+	if a.Name != "" {
+		return a.Name
+	}
+	return path.Base(filepath.ToSlash(a.Path))
+}
+
+// CommandArgument is an argument of a Command. The command's body refers to
+// it as ${arg:name}, which each target maps to its own argument syntax.
+type CommandArgument struct {
+	Name        string `yaml:"name"`
+	Description string `yaml:"description,omitempty"`
+	Required    bool   `yaml:"required,omitempty"`
+}
+
+// CommandSpec is the spec of a Command.
+type CommandSpec struct {
+	Arguments    []CommandArgument `yaml:"arguments,omitempty"`
+	AllowedTools []string          `yaml:"allowedTools,omitempty"`
+	Body         Body              `yaml:"body"`
+	Bodies       map[string]Body   `yaml:"bodies,omitempty"`
+	Fragments    map[string]string `yaml:"fragments,omitempty"`
+}
+
+// Command is a slash-command workflow, invoked by name with arguments.
+type Command struct {
+	Metadata Metadata
+	Spec     CommandSpec
+}
+
+// ContextSpec is the spec of a Context.
+type ContextSpec struct {
+	Body      Body              `yaml:"body"`
+	Bodies    map[string]Body   `yaml:"bodies,omitempty"`
+	Fragments map[string]string `yaml:"fragments,omitempty"`
+}
+
+// Context is always-on project context, such as an overview, build commands,
+// and conventions, that tools load in every session. Targets merge every
+// Context into a single file, such as CLAUDE.md or AGENTS.md.
+type Context struct {
+	Metadata Metadata
+	Spec     ContextSpec
+}
+
+// mappingKeys returns the keys of the mapping at key in node, in order.
+func mappingKeys(node *yaml.Node, key string) []string {
+	if node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value != key || node.Content[i+1].Kind != yaml.MappingNode {
+			continue
+		}
+		items := node.Content[i+1].Content
+		keys := make([]string, 0, len(items)/2)
+		for j := 0; j+1 < len(items); j += 2 {
+			keys = append(keys, items[j].Value)
+		}
+		return keys
+	}
+	return nil
+}
+
+// orderedKeys returns the keys of m listed in order, then the rest sorted.
+func orderedKeys[V any](m map[string]V, order []string) []string {
+	keys := make([]string, 0, len(m))
+	seen := make(map[string]bool, len(m))
+	for _, key := range order {
+		if _, ok := m[key]; ok && !seen[key] {
+			seen[key] = true
+			keys = append(keys, key)
+		}
+	}
+	var rest []string
+	for key := range m {
+		if !seen[key] {
+			rest = append(rest, key)
+		}
+	}
+	sort.Strings(rest)
+	return append(keys, rest...)
+}
+
+// orderedMapping encodes m as a YAML mapping with its keys in the given
+// order.
+func orderedMapping[V any](m map[string]V, keys []string) (*yaml.Node, error) {
+	node := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	for _, key := range keys {
+		var value yaml.Node
+		if err := value.Encode(m[key]); err != nil {
+			return nil, err
+		}
+		node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, &value)
+	}
+	return node, nil
+}
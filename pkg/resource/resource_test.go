@@ -0,0 +1,91 @@
+package resource
+
+import (
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestBodyYAML(t *testing.T) {
+	tests := []struct {
+		name string
+		yaml string
+	}{
+		{"string", "body: Use clear names.\n"},
+		{"list", "body:\n    - $header\n    - Use clear names.\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var spec RuleSpec
+			if err := yaml.Unmarshal([]byte(tt.yaml), &spec); err != nil {
+				t.Fatalf("Unmarshal() error = %v", err)
+			}
+			if spec.Body.IsZero() {
+				t.Fatal("Body.IsZero() = true after decoding")
+			}
+			out, err := yaml.Marshal(struct {
+				Body Body `yaml:"body"`
+			}{spec.Body})
+			if err != nil {
+				t.Fatalf("Marshal() error = %v", err)
+			}
+			if string(out) != tt.yaml {
+				t.Errorf("Marshal() = %q, want %q", out, tt.yaml)
+			}
+		})
+	}
+
+	var spec RuleSpec
+	if err := yaml.Unmarshal([]byte("body: {text: x}\n"), &spec); err == nil {
+		t.Error("Unmarshal() expected error for mapping body")
+	}
+}
+
+func TestRulesetSpecOrder(t *testing.T) {
+	content := `rules:
+    zeta:
+        enforcement: must
+        body: Z
+    "123":
+        enforcement: may
+        body: Num
+    alpha:
+        enforcement: should
+        body: A
+`
+	var spec RulesetSpec
+	if err := yaml.Unmarshal([]byte(content), &spec); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if got, want := strings.Join(spec.RuleIDs(), ","), "zeta,123,alpha"; got != want {
+		t.Errorf("RuleIDs() = %s, want %s", got, want)
+	}
+
+	out, err := yaml.Marshal(spec)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	if string(out) != content {
+		t.Errorf("Marshal() = %q, want %q", out, content)
+	}
+
+	// Rules added in code follow the written ones, sorted.
+	spec.Rules["beta"] = RuleItem{Enforcement: "may"}
+	spec.Rules["aardvark"] = RuleItem{Enforcement: "may"}
+	delete(spec.Rules, "123")
+	if got, want := strings.Join(spec.RuleIDs(), ","), "zeta,alpha,aardvark,beta"; got != want {
+		t.Errorf("RuleIDs() = %s, want %s", got, want)
+	}
+}
+
+func TestPromptsetSpecOrder(t *testing.T) {
+	var spec PromptsetSpec
+	if err := yaml.Unmarshal([]byte("prompts:\n  review: {body: R}\n  deploy: {body: D}\n"), &spec); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if got, want := strings.Join(spec.PromptIDs(), ","), "review,deploy"; got != want {
+		t.Errorf("PromptIDs() = %s, want %s", got, want)
+	}
+}
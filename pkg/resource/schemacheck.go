@@ -0,0 +1,278 @@
+package resource
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/jomadu/ai-resource-compiler-go/internal/suggest"
+	"gopkg.in/yaml.v3"
+)
+
+// SchemaError is a way a resource document does not match JSONSchema.
+type SchemaError struct {
+	// Field is the dotted path of the field concerned, e.g.
+	// "spec.rules.naming.enforcment", list elements being numbered from 0.
+	Field string
+	// Line and Column locate the field, or its mapping if it is missing;
+	// both are 1-based.
+	Line, Column int
+	// Missing is set for required fields the document lacks, Unknown for
+	// fields the format does not define.
+	Missing bool
+	Unknown bool
+	Message string
+}
+
+func (e *SchemaError) Error() string {
+	if e.Line > 0 {
+		return fmt.Sprintf("line %d: %s", e.Line, e.Message)
+	}
+	return e.Message
+}
+
+// CheckSchema checks a parsed resource document against JSONSchema and
+// returns every problem found: unknown fields, values of the wrong type, and
+// missing required fields. Any scalar, null included, is accepted where a
+// string is expected, as the decoder converts it. Anchors, aliases, and
+// merge keys are followed.
+func CheckSchema(doc *yaml.Node) []*SchemaError {
+	root := JSONSchema()
+	c := &schemaChecker{defs: root.Defs, patterns: make(map[string]*regexp.Regexp)}
+	if doc.Kind == yaml.DocumentNode {
+		if len(doc.Content) == 0 {
+			return nil
+		}
+		doc = doc.Content[0]
+	}
+	c.check(root, doc, "")
+	sort.SliceStable(c.errs, func(i, j int) bool { return c.errs[i].Line < c.errs[j].Line })
+	return c.errs
+}
+
+type schemaChecker struct {
+	defs     map[string]*Schema
+	patterns map[string]*regexp.Regexp
+	errs     []*SchemaError
+}
+
+func (c *schemaChecker) add(node *yaml.Node, field string, format string, args ...any) {
+	c.errs = append(c.errs, &SchemaError{Field: field, Line: node.Line, Column: node.Column, Message: fmt.Sprintf(format, args...)})
+}
+
+// check records the problems of node, at field, against s.
+func (c *schemaChecker) check(s *Schema, node *yaml.Node, field string) {
+	for node.Kind == yaml.AliasNode {
+		node = node.Alias
+	}
+	if s.Ref != "" {
+		c.check(c.defs[strings.TrimPrefix(s.Ref, "#/$defs/")], node, field)
+		return
+	}
+	if len(s.AnyOf) > 0 {
+		var alternatives []string
+		for _, alternative := range s.AnyOf {
+			if c.matches(alternative, node) {
+				c.check(alternative, node, field)
+				return
+			}
+			alternatives = append(alternatives, c.typeName(alternative))
+		}
+		c.add(node, field, "%s must be %s", label(field), strings.Join(alternatives, " or "))
+		return
+	}
+	if s.Type != "" && !matchesType(s.Type, node) {
+		c.add(node, field, "%s must be %s", label(field), c.typeName(s))
+		return
+	}
+	if s.Const != "" && node.Value != s.Const {
+		c.add(node, field, "%s must be %q, not %q", label(field), s.Const, node.Value)
+		return
+	}
+	if len(s.Enum) > 0 && !contains(s.Enum, node.Value) {
+		c.add(node, field, "%s must be one of %s, not %q", label(field), strings.Join(s.Enum, ", "), node.Value)
+		return
+	}
+	if s.Pattern != "" && node.Kind == yaml.ScalarNode && !c.pattern(s.Pattern).MatchString(node.Value) {
+		c.add(node, field, "%s %q must match %s", label(field), node.Value, s.Pattern)
+	}
+
+	switch node.Kind {
+	case yaml.SequenceNode:
+		if s.Items != nil {
+			for i, item := range node.Content {
+				c.check(s.Items, item, join(field, fmt.Sprint(i)))
+			}
+		}
+	case yaml.MappingNode:
+		c.mapping(s, node, field)
+	}
+	for _, sub := range s.AllOf {
+		if sub.If == nil || c.matches(sub.If, node) {
+			c.check(sub.Then, node, field)
+		}
+	}
+}
+
+// mapping records the problems of the keys of mapping node against s.
+func (c *schemaChecker) mapping(s *Schema, node *yaml.Node, field string) {
+	pairs := mappingPairs(node)
+	seen := make(map[string]bool)
+	for _, pair := range pairs {
+		key := pair[0].Value
+		seen[key] = true
+		if property, ok := s.Properties[key]; ok {
+			c.check(property, pair[1], join(field, key))
+			continue
+		}
+		switch additional := s.AdditionalProperties.(type) {
+		case bool:
+			if !additional {
+				message := "unknown field " + join(field, key)
+				if closest, ok := suggest.Closest(key, sortedKeys(s.Properties)); ok {
+					message += " (did you mean " + closest + "?)"
+				}
+				c.errs = append(c.errs, &SchemaError{
+					Field: join(field, key), Line: pair[0].Line, Column: pair[0].Column, Unknown: true, Message: message,
+				})
+			}
+		case *Schema:
+			c.check(additional, pair[1], join(field, key))
+		}
+	}
+	for _, name := range s.Required {
+		if !seen[name] {
+			c.errs = append(c.errs, &SchemaError{
+				Field: join(field, name), Line: node.Line, Column: node.Column, Missing: true,
+				Message: fmt.Sprintf("missing required field %s", join(field, name)),
+			})
+		}
+	}
+}
+
+// matches reports whether node matches s without problems.
+func (c *schemaChecker) matches(s *Schema, node *yaml.Node) bool {
+	sub := &schemaChecker{defs: c.defs, patterns: c.patterns}
+	sub.check(s, node, "")
+	return len(sub.errs) == 0
+}
+
+func (c *schemaChecker) pattern(expr string) *regexp.Regexp {
+	re, ok := c.patterns[expr]
+	if !ok {
+		re = regexp.MustCompile(expr)
+		c.patterns[expr] = re
+	}
+	return re
+}
+
+// typeName describes the values s accepts, e.g. "a list of strings".
+func (c *schemaChecker) typeName(s *Schema) string {
+	if s.Ref != "" {
+		return "a mapping"
+	}
+	switch s.Type {
+	case "string":
+		return "a string"
+	case "boolean":
+		return "true or false"
+	case "integer":
+		return "an integer"
+	case "array":
+		if s.Items != nil && s.Items.Type == "string" {
+			return "a list of strings"
+		}
+		return "a list"
+	case "object":
+		return "a mapping"
+	}
+	return "a value"
+}
+
+// matchesType reports whether node is of JSON Schema type t.
+func matchesType(t string, node *yaml.Node) bool {
+	switch t {
+	case "string":
+		return node.Kind == yaml.ScalarNode
+	case "boolean":
+		return node.Kind == yaml.ScalarNode && node.Tag == "!!bool"
+	case "integer":
+		return node.Kind == yaml.ScalarNode && node.Tag == "!!int"
+	case "array":
+		return node.Kind == yaml.SequenceNode
+	case "object":
+		return node.Kind == yaml.MappingNode
+	}
+	return true
+}
+
+// mappingPairs returns the key and value nodes of mapping node, with the
+// keys of merged mappings ("<<") that it does not set itself.
+func mappingPairs(node *yaml.Node) [][2]*yaml.Node {
+	var pairs, merged [][2]*yaml.Node
+	set := make(map[string]bool)
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+		if key.Value != "<<" || key.Tag != "!!merge" {
+			pairs = append(pairs, [2]*yaml.Node{key, value})
+			set[key.Value] = true
+			continue
+		}
+		for value.Kind == yaml.AliasNode {
+			value = value.Alias
+		}
+		sources := []*yaml.Node{value}
+		if value.Kind == yaml.SequenceNode {
+			sources = value.Content
+		}
+		for _, source := range sources {
+			for source.Kind == yaml.AliasNode {
+				source = source.Alias
+			}
+			if source.Kind == yaml.MappingNode {
+				merged = append(merged, mappingPairs(source)...)
+			}
+		}
+	}
+	for _, pair := range merged {
+		if !set[pair[0].Value] {
+			pairs = append(pairs, pair)
+			set[pair[0].Value] = true
+		}
+	}
+	return pairs
+}
+
+// label names field in messages; the document itself is "document".
+func label(field string) string {
+	if field == "" {
+		return "document"
+	}
+	return field
+}
+
+func join(field, key string) string {
+	if field == "" {
+		return key
+	}
+	return field + "." + key
+}
+
+func sortedKeys(properties map[string]*Schema) []string {
+	keys := make([]string, 0, len(properties))
+	for key := range properties {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
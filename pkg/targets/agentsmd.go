@@ -0,0 +1,270 @@
+package targets
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/jomadu/ai-resource-compiler-go/internal/format"
+	"github.com/jomadu/ai-resource-compiler-go/pkg/compiler"
+)
+
+// agentsRulesHeading opens the rules part of AGENTS.md, with the table of
+// contents below it.
+const agentsRulesHeading = "# Rules"
+
+// agentsAnchor matches the anchor line that starts each rule section, which
+// the table of contents links to.
+var agentsAnchor = regexp.MustCompile(`(?m)^<a id="([^"]+)"></a>\n`)
+
+// AgentsMDCompiler compiles rules into a single AGENTS.md, following the
+// agents.md convention many coding agents read: a table of contents, then a
+// section per rule headed with its enforcement. Contexts open the document,
+// ahead of the rules. Like GEMINI.md, AGENTS.md cannot scope a rule to files,
+// so a scoped rule's section names the files it applies to, and sections
+// never carry the metadata block. AGENTS.md has no place for prompts or
+// commands, so they compile to nothing.
+type AgentsMDCompiler struct {
+	ContentOptions
+}
+
+func init() {
+	compiler.RegisterDefaultTarget(compiler.TargetAgentsMD, &AgentsMDCompiler{})
+}
+
+func (a *AgentsMDCompiler) Name() string {
+	return "agentsmd"
+}
+
+func (a *AgentsMDCompiler) SupportedVersions() []string {
+	return []string{"ai-resource/draft"}
+}
+
+// DefaultOutputDir returns the project root, where agents read AGENTS.md.
+func (a *AgentsMDCompiler) DefaultOutputDir() string {
+	return "."
+}
+
+// AddBanner adds banner as a comment; see commentBanner.
+func (a *AgentsMDCompiler) AddBanner(path, content, banner string) string {
+	return commentBanner(path, content, banner)
+}
+
+// Configure accepts the content options.
+func (a *AgentsMDCompiler) Configure(options map[string]any) (compiler.TargetCompiler, error) {
+	if err := checkOptions(options, contentOptionNames...); err != nil {
+		return nil, err
+	}
+	configured := *a
+	if err := configured.ContentOptions.configure(options); err != nil {
+		return nil, err
+	}
+	return &configured, nil
+}
+
+func (a *AgentsMDCompiler) Compile(resource *compiler.Resource) ([]compiler.CompilationResult, error) {
+	if resource.APIVersion != "ai-resource/draft" {
+		return nil, fmt.Errorf("%w: %s for agentsmd", compiler.ErrUnsupportedVersion, resource.APIVersion)
+	}
+
+	switch resource.Kind {
+	case "Rule":
+		return a.compileRule(resource)
+	case "Ruleset":
+		return a.compileRuleset(resource)
+	case "Prompt", "Promptset", "Command":
+		return nil, nil
+	case "Context":
+		return compileContext(resource, agentsContextFile)
+	default:
+		return nil, fmt.Errorf("%w: %s", compiler.ErrUnsupportedKind, resource.Kind)
+	}
+}
+
+// Merge joins every section into one AGENTS.md: contexts first, then the
+// table of contents and the rule sections, each in the order compiled.
+func (a *AgentsMDCompiler) Merge(results []compiler.CompilationResult) ([]compiler.CompilationResult, error) {
+	var merged []compiler.CompilationResult
+	var documents []*agentsDocument
+	index := make(map[string]int)
+	for _, result := range results {
+		i, seen := index[result.Path]
+		if !seen {
+			i = len(merged)
+			index[result.Path] = i
+			merged = append(merged, compiler.CompilationResult{Path: result.Path})
+			documents = append(documents, &agentsDocument{})
+		}
+		documents[i].add(result.Content)
+	}
+	for i, doc := range documents {
+		merged[i].Content = doc.String()
+	}
+	return merged, nil
+}
+
+// Explain describes how resource compiles to AGENTS.md sections.
+func (a *AgentsMDCompiler) Explain(resource *compiler.Resource) ([]compiler.Explanation, error) {
+	return explain(a, resource, func(item explainItem) compiler.Explanation {
+		if item.context {
+			return contextExplanation(item, agentsContextFile)
+		}
+		if !item.rule {
+			return compiler.Explanation{Path: "(none)", Mappings: []compiler.Mapping{
+				{Field: "(item)", Output: "not compiled: AGENTS.md has no prompts or commands"},
+			}}
+		}
+
+		heading := format.EnforcementHeader(item.name, item.enforcement)
+		mappings := []compiler.Mapping{
+			{Field: "(file)", Output: "section of the shared " + agentsContextFile},
+			{Field: "id", Output: fmt.Sprintf("anchor %q, linked from the table of contents", item.ref())},
+		}
+		if files := extractScopeFiles(item.scope); len(files) > 0 {
+			mappings = append(mappings, compiler.Mapping{Field: scopeField(item.scope), Output: "\"Applies to files matching\" line"})
+		}
+		mappings = append(mappings,
+			compiler.Mapping{
+				Field:  fmt.Sprintf("name, enforcement: %s", item.enforcement),
+				Output: fmt.Sprintf("heading %q", "#"+heading),
+			},
+			compiler.Mapping{Field: "body", Output: "content below the heading, fragments resolved"})
+		return compiler.Explanation{Path: agentsContextFile, Mappings: mappings}
+	})
+}
+
+func (a *AgentsMDCompiler) compileRule(resource *compiler.Resource) ([]compiler.CompilationResult, error) {
+	rule := resource.Spec.(*format.Rule)
+
+	if err := format.ValidateID(rule.Metadata.ID); err != nil {
+		return nil, err
+	}
+	if err := format.ValidateRuleName(rule.Metadata.Name); err != nil {
+		return nil, err
+	}
+
+	content := a.section(rule.Metadata.ID, rule.Metadata.Name, rule.Spec.Enforcement, rule.Spec.Scope,
+		format.ResolveBody(rule.Spec.Body, rule.Spec.Fragments))
+	resourceValue := compiler.Resource{APIVersion: "ai-resource/draft", Kind: "Rule", Spec: rule}
+	content += a.sourceSection(resource.Source, rule.Metadata.ID, resourceValue)
+
+	return []compiler.CompilationResult{{Path: agentsContextFile, Content: content}}, nil
+}
+
+func (a *AgentsMDCompiler) compileRuleset(resource *compiler.Resource) ([]compiler.CompilationResult, error) {
+	ruleset := resource.Spec.(*format.Ruleset)
+
+	if err := format.ValidateID(ruleset.Metadata.ID); err != nil {
+		return nil, err
+	}
+
+	var results []compiler.CompilationResult
+	for _, ruleID := range ruleset.Spec.RuleIDs() {
+		if err := format.ValidateID(ruleID); err != nil {
+			return nil, err
+		}
+		ruleSpec := ruleset.Spec.Rules[ruleID]
+		if err := format.ValidateRuleName(ruleSpec.Name); err != nil {
+			return nil, err
+		}
+
+		ref := ruleset.Metadata.ID + "/" + ruleID
+		content := a.section(ref, ruleSpec.Name, ruleSpec.Enforcement, ruleSpec.Scope,
+			format.ResolveBody(ruleSpec.Body, ruleset.Spec.Fragments))
+		item := map[string]map[string]format.RuleItem{"rules": {ruleID: ruleSpec}}
+		content += a.sourceSection(resource.Source, ref, item)
+
+		results = append(results, compiler.CompilationResult{Path: agentsContextFile, Content: content})
+	}
+
+	return results, nil
+}
+
+// section returns a rule's AGENTS.md section: the anchor the table of
+// contents links to, its enforcement heading, the files it applies to, if
+// scoped, and its body.
+func (a *AgentsMDCompiler) section(ref, name, enforcement string, scope []format.ScopeEntry, body string) string {
+	var sb strings.Builder
+	sb.WriteString(`<a id="` + ref + `"></a>` + "\n")
+	sb.WriteString("#" + format.EnforcementHeader(name, enforcement))
+	sb.WriteString("\n\n")
+	if applies := appliesTo(scope); applies != "" {
+		sb.WriteString(applies + "\n\n")
+	}
+	sb.WriteString(body)
+	return sb.String()
+}
+
+// agentsDocument collects the parts of an AGENTS.md being merged.
+type agentsDocument struct {
+	contexts []string
+	rules    []agentsRule
+}
+
+// agentsRule is a rule section of AGENTS.md.
+type agentsRule struct {
+	ref     string
+	heading string
+	content string
+}
+
+// add adds the parts of content, which is a context or rule section or an
+// already merged document, whose table of contents is dropped and rebuilt.
+func (d *agentsDocument) add(content string) {
+	anchors := agentsAnchor.FindAllStringSubmatchIndex(content, -1)
+	if len(anchors) == 0 {
+		d.contexts = append(d.contexts, content)
+		return
+	}
+
+	intro := strings.TrimRight(content[:anchors[0][0]], "\n")
+	if i := strings.LastIndex("\n"+intro, "\n"+agentsRulesHeading+"\n"); i >= 0 && isTableOfContents(intro[i+len(agentsRulesHeading):]) {
+		intro = strings.TrimRight(intro[:i], "\n")
+	}
+	if intro != "" {
+		d.contexts = append(d.contexts, intro)
+	}
+
+	for i, anchor := range anchors {
+		end := len(content)
+		if i+1 < len(anchors) {
+			end = anchors[i+1][0]
+		}
+		section := strings.TrimRight(content[anchor[0]:end], "\n")
+		heading, _, _ := strings.Cut(content[anchor[1]:end], "\n")
+		d.rules = append(d.rules, agentsRule{
+			ref:     content[anchor[2]:anchor[3]],
+			heading: strings.TrimPrefix(heading, "## "),
+			content: section,
+		})
+	}
+}
+
+// isTableOfContents reports whether text holds nothing but table of
+// contents entries.
+func isTableOfContents(text string) bool {
+	for _, line := range strings.Split(text, "\n") {
+		if line != "" && !strings.HasPrefix(line, "- [") {
+			return false
+		}
+	}
+	return true
+}
+
+// String returns the document: its contexts, then the rules under a table
+// of contents.
+func (d *agentsDocument) String() string {
+	parts := append([]string(nil), d.contexts...)
+	if len(d.rules) > 0 {
+		var toc strings.Builder
+		toc.WriteString(agentsRulesHeading + "\n")
+		for _, rule := range d.rules {
+			toc.WriteString("\n- [" + rule.heading + "](#" + rule.ref + ")")
+		}
+		parts = append(parts, toc.String())
+		for _, rule := range d.rules {
+			parts = append(parts, rule.content)
+		}
+	}
+	return strings.Join(parts, "\n\n")
+}
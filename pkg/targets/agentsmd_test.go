@@ -0,0 +1,58 @@
+package targets
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/jomadu/ai-resource-compiler-go/internal/format"
+	"github.com/jomadu/ai-resource-compiler-go/pkg/compiler"
+)
+
+func TestAgentsMDCompiler_Merge(t *testing.T) {
+	a := &AgentsMDCompiler{}
+	resources := []*compiler.Resource{
+		{APIVersion: "ai-resource/draft", Kind: "Rule", Spec: &format.Rule{
+			Metadata: format.Metadata{ID: "naming", Name: "Naming"},
+			Spec:     format.RuleSpec{Enforcement: "must", Body: format.Body{String: strPtr("Use descriptive names.")}},
+		}},
+		{APIVersion: "ai-resource/draft", Kind: "Context", Spec: &format.Context{
+			Metadata: format.Metadata{ID: "overview", Name: "Overview"},
+			Spec:     format.ContextSpec{Body: format.Body{String: strPtr("A billing service.")}},
+		}},
+		{APIVersion: "ai-resource/draft", Kind: "Prompt", Spec: &format.Prompt{
+			Metadata: format.Metadata{ID: "review"},
+			Spec:     format.PromptSpec{Body: format.Body{String: strPtr("Review.")}},
+		}},
+		{APIVersion: "ai-resource/draft", Kind: "Rule", Spec: &format.Rule{
+			Metadata: format.Metadata{ID: "errors", Name: "Errors"},
+			Spec:     format.RuleSpec{Enforcement: "should", Body: format.Body{String: strPtr("Wrap errors.")}},
+		}},
+	}
+	var results []compiler.CompilationResult
+	for _, resource := range resources {
+		compiled, err := a.Compile(resource)
+		if err != nil {
+			t.Fatalf("Compile() error = %v", err)
+		}
+		results = append(results, compiled...)
+	}
+
+	merged, err := a.Merge(results)
+	if err != nil {
+		t.Fatalf("Merge() error = %v", err)
+	}
+	want := []compiler.CompilationResult{{Path: "AGENTS.md", Content: "# Overview\n\nA billing service.\n\n" +
+		"# Rules\n\n- [Naming (MUST)](#naming)\n- [Errors (SHOULD)](#errors)\n\n" +
+		"<a id=\"naming\"></a>\n## Naming (MUST)\n\nUse descriptive names.\n\n" +
+		"<a id=\"errors\"></a>\n## Errors (SHOULD)\n\nWrap errors."}}
+	if !reflect.DeepEqual(merged, want) {
+		t.Errorf("Merge() = %+v, want %+v", merged, want)
+	}
+
+	// Merging in steps, as for several resources, gives the same document.
+	first, _ := a.Merge(results[:2])
+	stepwise, err := a.Merge(append(first, results[2:]...))
+	if err != nil || !reflect.DeepEqual(stepwise, want) {
+		t.Errorf("stepwise Merge() = %+v, %v, want %+v", stepwise, err, want)
+	}
+}
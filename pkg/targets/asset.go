@@ -0,0 +1,30 @@
+package targets
+
+import (
+	"fmt"
+	"path"
+
+	"github.com/jomadu/ai-resource-compiler-go/internal/format"
+	"github.com/jomadu/ai-resource-compiler-go/pkg/compiler"
+)
+
+// assetResults returns the assets of the prompt compiled to promptPath, in
+// order, as results in the prompt's directory. Assets are taken from each
+// list in turn, e.g. those of the promptset and then the prompt's own.
+func assetResults(promptPath string, lists ...[]format.Asset) ([]compiler.CompilationResult, error) {
+	dir, file := path.Split(promptPath)
+	var results []compiler.CompilationResult
+	for _, assets := range lists {
+		for _, asset := range assets {
+			name := path.Clean(asset.FileName())
+			if name == file {
+				return nil, fmt.Errorf("asset %s would replace %s", asset.Path, promptPath)
+			}
+			if asset.Data == nil {
+				return nil, fmt.Errorf("asset %s was not read: load the resource with the loader or set Data", asset.Path)
+			}
+			results = append(results, compiler.CompilationResult{Path: dir + name, Content: string(asset.Data), Asset: true})
+		}
+	}
+	return results, nil
+}
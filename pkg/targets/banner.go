@@ -0,0 +1,19 @@
+package targets
+
+import "strings"
+
+// commentBanner returns content, the file at path, with banner added as a
+// comment in the file's own syntax: a YAML comment opening its frontmatter
+// or metadata block, which must stay on the first line, a comment line in
+// TOML, and otherwise an HTML comment, which Markdown renders as nothing.
+func commentBanner(path, content, banner string) string {
+	switch {
+	case strings.HasSuffix(path, ".toml"):
+		return "# " + banner + "\n" + content
+	case strings.HasPrefix(content, "---\n"):
+		return "---\n# " + banner + "\n" + strings.TrimPrefix(content, "---\n")
+	default:
+		// "--" may not appear within an HTML comment.
+		return "<!-- " + strings.ReplaceAll(banner, "--", "- -") + " -->\n\n" + content
+	}
+}
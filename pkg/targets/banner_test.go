@@ -0,0 +1,35 @@
+package targets
+
+import "testing"
+
+func TestCommentBanner(t *testing.T) {
+	tests := []struct {
+		name    string
+		path    string
+		content string
+		want    string
+	}{
+		{"frontmatter", "rule.md", "---\nid: rule\n---\n\nBody", "---\n# Generated\nid: rule\n---\n\nBody"},
+		{"markdown", "prompt.md", "Body", "<!-- Generated -->\n\nBody"},
+		{"toml", ".gemini/commands/deploy.toml", "prompt = \"Deploy\"\n", "# Generated\nprompt = \"Deploy\"\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := commentBanner(tt.path, tt.content, "Generated"); got != tt.want {
+				t.Errorf("commentBanner() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCursorAddBanner(t *testing.T) {
+	c := &CursorCompiler{}
+	got := c.AddBanner("rule.mdc", "---\ndescription: Rule\n---\n\nBody", "Generated by arc")
+	want := "---\ngenerated: Generated by arc\ndescription: Rule\n---\n\nBody"
+	if got != want {
+		t.Errorf("AddBanner() = %q, want %q", got, want)
+	}
+	if got := c.AddBanner("prompt.md", "Body", "Generated by arc"); got != "<!-- Generated by arc -->\n\nBody" {
+		t.Errorf("AddBanner() on a prompt = %q, want an HTML comment", got)
+	}
+}
@@ -2,14 +2,42 @@ package targets
 
 import (
 	"fmt"
+	"path"
+	"regexp"
 	"strings"
+	"unicode/utf8"
 
 	"github.com/jomadu/ai-resource-compiler-go/internal/format"
 	"github.com/jomadu/ai-resource-compiler-go/pkg/compiler"
-	"gopkg.in/yaml.v3"
 )
 
-type ClaudeCompiler struct{}
+// Claude skill directory naming schemes for the skillNames option.
+const (
+	ClaudeSkillNamesID    = "id"    // {promptsetID}_{promptID}, as in the resource
+	ClaudeSkillNamesKebab = "kebab" // lowercase and hyphenated, e.g. release-notes
+)
+
+// Directories of .claude, relative to it, that rules, skills, and custom
+// commands are written to.
+const (
+	claudeRulesDir    = "rules/"
+	claudeSkillsDir   = "skills/"
+	claudeCommandsDir = "commands/"
+)
+
+type ClaudeCompiler struct {
+	ContentOptions
+
+	// SkillNames names skill directories: ClaudeSkillNamesID (the default
+	// when "") or ClaudeSkillNamesKebab, which matches the lowercase,
+	// hyphenated names Claude expects of skills.
+	SkillNames string
+
+	// ClaudeMD also writes a CLAUDE.md importing every compiled rule with
+	// Claude's @path syntax, for setups that load rules from it rather than
+	// from .claude/rules.
+	ClaudeMD bool
+}
 
 func init() {
 	compiler.RegisterDefaultTarget(compiler.TargetClaude, &ClaudeCompiler{})
@@ -23,9 +51,66 @@ func (c *ClaudeCompiler) SupportedVersions() []string {
 	return []string{"ai-resource/draft"}
 }
 
+// DefaultOutputDir returns .claude, whose rules, skills, and commands
+// directories Claude reads.
+func (c *ClaudeCompiler) DefaultOutputDir() string {
+	return ".claude"
+}
+
+// ProjectRoot returns CLAUDE.md, which Claude reads from the project root,
+// apart from the other results, its imports of them pointed into .claude.
+func (c *ClaudeCompiler) ProjectRoot(results []compiler.CompilationResult) (root, rest []compiler.CompilationResult) {
+	root, rest = splitResults(results, claudeContextFile)
+	paths := make(map[string]bool, len(rest))
+	for _, result := range rest {
+		paths[result.Path] = true
+	}
+	for i, result := range root {
+		lines := strings.Split(result.Content, "\n")
+		for j, line := range lines {
+			if strings.HasPrefix(line, "@") && paths[line[1:]] {
+				lines[j] = "@" + c.DefaultOutputDir() + "/" + line[1:]
+			}
+		}
+		root[i].Content = strings.Join(lines, "\n")
+	}
+	return root, rest
+}
+
+// AddBanner adds banner as a comment; see commentBanner.
+func (c *ClaudeCompiler) AddBanner(path, content, banner string) string {
+	return commentBanner(path, content, banner)
+}
+
+// Configure accepts the content options, skillNames, and claudeMd.
+func (c *ClaudeCompiler) Configure(options map[string]any) (compiler.TargetCompiler, error) {
+	if err := checkOptions(options, append(contentOptionNames, "skillNames", "claudeMd")...); err != nil {
+		return nil, err
+	}
+	configured := *c
+	if err := configured.ContentOptions.configure(options); err != nil {
+		return nil, err
+	}
+	skillNames, err := stringOption(options, "skillNames", ClaudeSkillNamesID, ClaudeSkillNamesKebab)
+	if err != nil {
+		return nil, err
+	}
+	if skillNames != "" {
+		configured.SkillNames = skillNames
+	}
+	claudeMD, err := boolOption(options, "claudeMd")
+	if err != nil {
+		return nil, err
+	}
+	if claudeMD != nil {
+		configured.ClaudeMD = *claudeMD
+	}
+	return &configured, nil
+}
+
 func (c *ClaudeCompiler) Compile(resource *compiler.Resource) ([]compiler.CompilationResult, error) {
 	if resource.APIVersion != "ai-resource/draft" {
-		return nil, fmt.Errorf("unsupported apiVersion: %s for claude", resource.APIVersion)
+		return nil, fmt.Errorf("%w: %s for claude", compiler.ErrUnsupportedVersion, resource.APIVersion)
 	}
 
 	switch resource.Kind {
@@ -37,11 +122,68 @@ func (c *ClaudeCompiler) Compile(resource *compiler.Resource) ([]compiler.Compil
 		return c.compilePrompt(resource)
 	case "Promptset":
 		return c.compilePromptset(resource)
+	case "Command":
+		return c.compileCommand(resource)
+	case "Context":
+		return compileContext(resource, claudeContextFile)
 	default:
-		return nil, fmt.Errorf("unsupported kind: %s", resource.Kind)
+		return nil, fmt.Errorf("%w: %s", compiler.ErrUnsupportedKind, resource.Kind)
 	}
 }
 
+// Merge joins the sections of every Context into one CLAUDE.md, followed by
+// the imports of the rules compiled with ClaudeMD, one per line. Other
+// results are returned unchanged.
+func (c *ClaudeCompiler) Merge(results []compiler.CompilationResult) ([]compiler.CompilationResult, error) {
+	return mergeImports(mergeContext(results, claudeContextFile), claudeContextFile), nil
+}
+
+// Explain describes how resource compiles to Claude rules and skills.
+func (c *ClaudeCompiler) Explain(resource *compiler.Resource) ([]compiler.Explanation, error) {
+	return explain(c, resource, func(item explainItem) compiler.Explanation {
+		if item.context {
+			return contextExplanation(item, claudeContextFile)
+		}
+		var mappings []compiler.Mapping
+		if item.rule {
+			if files := extractScopeFiles(item.scope); len(files) > 0 {
+				mappings = append(mappings, compiler.Mapping{Field: scopeField(item.scope), Output: "paths: " + strings.Join(files, ", ")})
+			} else {
+				mappings = append(mappings, compiler.Mapping{Field: scopeField(item.scope), Output: "no frontmatter (applies everywhere)"})
+			}
+			if c.ClaudeMD {
+				mappings = append(mappings, compiler.Mapping{Field: "(file)", Output: "imported by " + claudeContextFile + " as @" + claudeRulesDir + item.path(".md")})
+			}
+			return compiler.Explanation{Path: claudeRulesDir + item.path(".md"), Mappings: append(mappings, c.contentMappings(item)...)}
+		}
+
+		if item.command {
+			mappings = append(mappings, compiler.Mapping{Field: "description", Output: "description"})
+			if item.arguments != "" {
+				mappings = append(mappings, compiler.Mapping{Field: "arguments", Output: "argument-hint: " + item.arguments})
+			}
+			mappings = append(mappings, argumentMapping(item.commandArguments, claudeArgument(len(item.commandArguments)))...)
+			if len(item.allowedTools) > 0 {
+				tools := strings.Join(item.allowedTools, ", ")
+				mappings = append(mappings, compiler.Mapping{Field: "allowedTools: " + tools, Output: "allowed-tools: " + tools})
+			}
+			return compiler.Explanation{Path: claudeCommandsDir + item.path(".md"), Mappings: append(mappings, c.contentMappings(item)...)}
+		}
+
+		path := c.skillPath(item.collection, item.id)
+		mappings = append(mappings, compiler.Mapping{Field: "id", Output: "name: " + skillName(item.collection, item.id)})
+		mappings = append(mappings, compiler.Mapping{Field: "description", Output: "description (the name, or else the skill name, if unset)"})
+		if len(item.allowedTools) > 0 {
+			tools := strings.Join(item.allowedTools, ", ")
+			mappings = append(mappings, compiler.Mapping{Field: "allowedTools: " + tools, Output: "allowed-tools: " + tools})
+		}
+		if item.arguments != "" {
+			mappings = append(mappings, compiler.Mapping{Field: "arguments: " + item.arguments, Output: "argument-hint: " + item.arguments})
+		}
+		return compiler.Explanation{Path: path, Mappings: append(mappings, c.contentMappings(item)...)}
+	})
+}
+
 func (c *ClaudeCompiler) compileRule(resource *compiler.Resource) ([]compiler.CompilationResult, error) {
 	rule := resource.Spec.(*format.Rule)
 
@@ -52,8 +194,8 @@ func (c *ClaudeCompiler) compileRule(resource *compiler.Resource) ([]compiler.Co
 		return nil, err
 	}
 
-	path := format.BuildStandalonePath(rule.Metadata.ID, ".md")
-	metadataBlock := format.GenerateRuleMetadataBlockFromRule(rule)
+	path := claudeRulesDir + format.BuildStandalonePath(rule.Metadata.ID, ".md")
+	metadataBlock := c.ruleContent(rule, resource.Source)
 	
 	var content strings.Builder
 	if len(rule.Spec.Scope) > 0 {
@@ -62,7 +204,7 @@ func (c *ClaudeCompiler) compileRule(resource *compiler.Resource) ([]compiler.Co
 	}
 	content.WriteString(metadataBlock)
 
-	return []compiler.CompilationResult{{Path: path, Content: content.String()}}, nil
+	return c.withImport([]compiler.CompilationResult{{Path: path, Content: content.String()}}), nil
 }
 
 func (c *ClaudeCompiler) compileRuleset(resource *compiler.Resource) ([]compiler.CompilationResult, error) {
@@ -73,7 +215,7 @@ func (c *ClaudeCompiler) compileRuleset(resource *compiler.Resource) ([]compiler
 	}
 
 	var results []compiler.CompilationResult
-	for ruleID := range ruleset.Spec.Rules {
+	for _, ruleID := range ruleset.Spec.RuleIDs() {
 		if err := format.ValidateID(ruleID); err != nil {
 			return nil, err
 		}
@@ -82,8 +224,8 @@ func (c *ClaudeCompiler) compileRuleset(resource *compiler.Resource) ([]compiler
 			return nil, err
 		}
 
-		path := format.BuildCollectionPath(ruleset.Metadata.ID, ruleID, ".md")
-		metadataBlock := format.GenerateRuleMetadataBlockFromRuleset(ruleset, ruleID)
+		path := claudeRulesDir + format.BuildCollectionPath(ruleset.Metadata.ID, ruleID, ".md")
+		metadataBlock := c.rulesetRuleContent(ruleset, ruleID, resource.Source)
 		
 		var content strings.Builder
 		if len(ruleSpec.Scope) > 0 {
@@ -92,12 +234,47 @@ func (c *ClaudeCompiler) compileRuleset(resource *compiler.Resource) ([]compiler
 		}
 		content.WriteString(metadataBlock)
 
-		results = append(results, compiler.CompilationResult{Path: path, Content: content.String()})
+		results = append(results, c.withImport([]compiler.CompilationResult{{Path: path, Content: content.String()}})...)
 	}
 
 	return results, nil
 }
 
+// ImportRule reads a rule file of .claude/rules: rules with paths
+// frontmatter are should, and others, which Claude loads in every session,
+// must, unless the file has arc's enforcement header. Skills and context
+// files are skipped.
+func (c *ClaudeCompiler) ImportRule(p string, content []byte) (compiler.ImportedRule, bool, error) {
+	switch path.Base(p) {
+	case "SKILL.md", claudeContextFile, agentsContextFile:
+		return compiler.ImportedRule{}, false, nil
+	}
+	if !strings.HasSuffix(p, ".md") {
+		return compiler.ImportedRule{}, false, nil
+	}
+	file := parseImported(p, content)
+	var frontmatter struct {
+		Paths importGlobs `yaml:"paths"`
+	}
+	if err := file.decodeFrontmatter(&frontmatter); err != nil {
+		return compiler.ImportedRule{}, false, err
+	}
+	enforcement := "must"
+	if len(frontmatter.Paths) > 0 {
+		enforcement = "should"
+	}
+	return file.rule("", enforcement, frontmatter.Paths), true, nil
+}
+
+// withImport returns the result of a rule followed, if ClaudeMD is set, by
+// its import into CLAUDE.md, which Merge gathers after the context.
+func (c *ClaudeCompiler) withImport(results []compiler.CompilationResult) []compiler.CompilationResult {
+	if !c.ClaudeMD {
+		return results
+	}
+	return append(results, compiler.CompilationResult{Path: claudeContextFile, Content: "@" + results[0].Path})
+}
+
 func (c *ClaudeCompiler) compilePrompt(resource *compiler.Resource) ([]compiler.CompilationResult, error) {
 	prompt := resource.Spec.(*format.Prompt)
 
@@ -105,10 +282,18 @@ func (c *ClaudeCompiler) compilePrompt(resource *compiler.Resource) ([]compiler.
 		return nil, err
 	}
 
-	path := format.BuildClaudeStandalonePath(prompt.Metadata.ID)
-	content := format.ResolveBody(prompt.Spec.Body, prompt.Spec.Fragments)
+	path := c.skillPath("", prompt.Metadata.ID)
+	frontmatter, err := generateSkillFrontmatter(skillName("", prompt.Metadata.ID), prompt.Metadata.Description, prompt.Metadata.Name, prompt.Spec.AllowedTools, prompt.Spec.Arguments)
+	if err != nil {
+		return nil, fmt.Errorf("prompt %s: %w", prompt.Metadata.ID, err)
+	}
+	content := frontmatter + format.ResolveBody(prompt.Spec.Body, prompt.Spec.Fragments)
 
-	return []compiler.CompilationResult{{Path: path, Content: content}}, nil
+	assets, err := assetResults(path, prompt.Spec.Assets)
+	if err != nil {
+		return nil, fmt.Errorf("prompt %s: %w", prompt.Metadata.ID, err)
+	}
+	return append([]compiler.CompilationResult{{Path: path, Content: content}}, assets...), nil
 }
 
 func (c *ClaudeCompiler) compilePromptset(resource *compiler.Resource) ([]compiler.CompilationResult, error) {
@@ -119,38 +304,185 @@ func (c *ClaudeCompiler) compilePromptset(resource *compiler.Resource) ([]compil
 	}
 
 	var results []compiler.CompilationResult
-	for promptID := range promptset.Spec.Prompts {
+	for _, promptID := range promptset.Spec.PromptIDs() {
 		if err := format.ValidateID(promptID); err != nil {
 			return nil, err
 		}
 
 		promptSpec := promptset.Spec.Prompts[promptID]
-		path := format.BuildClaudeCollectionPath(promptset.Metadata.ID, promptID)
-		content := format.ResolveBody(promptSpec.Body, promptset.Spec.Fragments)
+		path := c.skillPath(promptset.Metadata.ID, promptID)
+		frontmatter, err := generateSkillFrontmatter(skillName(promptset.Metadata.ID, promptID), promptSpec.Description, promptSpec.Name, promptSpec.AllowedTools, promptSpec.Arguments)
+		if err != nil {
+			return nil, fmt.Errorf("prompt %s/%s: %w", promptset.Metadata.ID, promptID, err)
+		}
+		content := frontmatter + format.ResolveBody(promptSpec.Body, promptset.Spec.Fragments)
 
 		results = append(results, compiler.CompilationResult{Path: path, Content: content})
+		assets, err := assetResults(path, promptset.Spec.Assets, promptSpec.Assets)
+		if err != nil {
+			return nil, fmt.Errorf("prompt %s/%s: %w", promptset.Metadata.ID, promptID, err)
+		}
+		results = append(results, assets...)
 	}
 
 	return results, nil
 }
 
-func generatePathsFrontmatter(scope []format.ScopeEntry) string {
-	var files []string
-	for _, entry := range scope {
-		files = append(files, entry.Files...)
+// compileCommand compiles a command to a Claude custom command under
+// commands/, the directory of .claude that Claude reads them from. Arguments become $ARGUMENTS when the
+// command takes one and $1, $2, ... when it takes several.
+func (c *ClaudeCompiler) compileCommand(resource *compiler.Resource) ([]compiler.CompilationResult, error) {
+	command := resource.Spec.(*format.Command)
+
+	if err := format.ValidateID(command.Metadata.ID); err != nil {
+		return nil, err
 	}
 
-	frontmatter := map[string]interface{}{
-		"paths": files,
+	var content strings.Builder
+	frontmatter := commandFrontmatter{
+		Description:  commandDescription(command),
+		ArgumentHint: argumentHint(command.Spec.Arguments),
+		AllowedTools: strings.Join(command.Spec.AllowedTools, ", "),
 	}
+	if frontmatter != (commandFrontmatter{}) {
+		content.WriteString(encodeFrontmatter(frontmatter))
+		content.WriteString("\n\n")
+	}
+	content.WriteString(commandBody(command, claudeArgument(len(command.Spec.Arguments))))
 
-	var b strings.Builder
-	b.WriteString("---\n")
-	encoder := yaml.NewEncoder(&b)
-	encoder.SetIndent(2)
-	encoder.Encode(frontmatter)
-	encoder.Close()
-	b.WriteString("---")
+	path := claudeCommandsDir + format.BuildStandalonePath(command.Metadata.ID, ".md")
+	return []compiler.CompilationResult{{Path: path, Content: content.String()}}, nil
+}
+
+// claudeArgument returns the placeholder for the arguments of a command
+// that takes count of them.
+func claudeArgument(count int) func(int, format.CommandArgument) string {
+	return func(i int, _ format.CommandArgument) string {
+		if count == 1 {
+			return "$ARGUMENTS"
+		}
+		return fmt.Sprintf("$%d", i+1)
+	}
+}
 
+// skillPath returns the SKILL.md path of a prompt in skills, named by
+// SkillNames. collection is "" for a standalone prompt.
+func (c *ClaudeCompiler) skillPath(collection, id string) string {
+	if c.SkillNames == ClaudeSkillNamesKebab {
+		return claudeSkillsDir + skillName(collection, id) + "/SKILL.md"
+	}
+	if collection == "" {
+		return claudeSkillsDir + format.BuildClaudeStandalonePath(id)
+	}
+	return claudeSkillsDir + format.BuildClaudeCollectionPath(collection, id)
+}
+
+// skillName returns the name of a prompt's skill: its ID in kebab case,
+// after that of its collection, if any.
+func skillName(collection, id string) string {
+	name := kebabCase(id)
+	if collection != "" {
+		name = kebabCase(collection) + "-" + name
+	}
+	return name
+}
+
+// kebabCase lowercases an ID and separates its words with hyphens, splitting
+// at underscores, hyphens, and lower-to-upper case changes:
+// "releaseNotes_v2" becomes "release-notes-v2".
+func kebabCase(id string) string {
+	var b strings.Builder
+	separate, lower := false, false
+	for _, r := range id {
+		if r == '_' || r == '-' {
+			separate, lower = b.Len() > 0, false
+			continue
+		}
+		upper := r >= 'A' && r <= 'Z'
+		if separate || (upper && lower) {
+			b.WriteByte('-')
+		}
+		separate = false
+		lower = !upper
+		if upper {
+			r += 'a' - 'A'
+		}
+		b.WriteRune(r)
+	}
 	return b.String()
 }
+
+func generatePathsFrontmatter(scope []format.ScopeEntry) string {
+	return encodeFrontmatter(pathsFrontmatter{Paths: extractScopeFiles(scope)})
+}
+
+// Limits of skill frontmatter, per the Agent Skills specification.
+const (
+	maxSkillNameLength        = 64
+	maxSkillDescriptionLength = 1024
+)
+
+// reservedSkillWords may not appear in skill names.
+var reservedSkillWords = []string{"anthropic", "claude"}
+
+// xmlTag matches an XML tag, which skill descriptions may not contain.
+var xmlTag = regexp.MustCompile(`<[A-Za-z/][^<>]*>`)
+
+// generateSkillFrontmatter returns the frontmatter of a skill, followed by
+// a blank line: its name and description, which Claude uses to decide when
+// to load it, and its allowed-tools and argument-hint, if set. A prompt
+// without a description is described by its display name, or else by the
+// skill name.
+func generateSkillFrontmatter(name, description, displayName string, allowedTools []string, arguments string) (string, error) {
+	if err := validateSkillName(name); err != nil {
+		return "", err
+	}
+	description = strings.TrimSpace(description)
+	if description == "" {
+		description = strings.TrimSpace(displayName)
+	}
+	if description == "" {
+		description = name
+	}
+	if err := validateSkillDescription(description); err != nil {
+		return "", err
+	}
+
+	return encodeFrontmatter(skillFrontmatter{
+		Name:         name,
+		Description:  description,
+		AllowedTools: strings.Join(allowedTools, ", "),
+		ArgumentHint: arguments,
+	}) + "\n\n", nil
+}
+
+// validateSkillName checks a skill name: at most 64 lowercase letters,
+// digits, and hyphens, without a reserved word.
+func validateSkillName(name string) error {
+	if name == "" || len(name) > maxSkillNameLength {
+		return fmt.Errorf("skill name %q must be 1 to %d characters", name, maxSkillNameLength)
+	}
+	for _, r := range name {
+		if !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '-') {
+			return fmt.Errorf("skill name %q may only contain lowercase letters, digits, and hyphens", name)
+		}
+	}
+	for _, word := range reservedSkillWords {
+		if strings.Contains(name, word) {
+			return fmt.Errorf("skill name %q contains the reserved word %q", name, word)
+		}
+	}
+	return nil
+}
+
+// validateSkillDescription checks a skill description: at most 1024
+// characters, without XML tags.
+func validateSkillDescription(description string) error {
+	if n := utf8.RuneCountInString(description); n > maxSkillDescriptionLength {
+		return fmt.Errorf("skill description is %d characters, over the limit of %d", n, maxSkillDescriptionLength)
+	}
+	if tag := xmlTag.FindString(description); tag != "" {
+		return fmt.Errorf("skill description may not contain XML tags, found %s", tag)
+	}
+	return nil
+}
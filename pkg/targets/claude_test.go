@@ -1,6 +1,7 @@
 package targets
 
 import (
+	"reflect"
 	"strings"
 	"testing"
 
@@ -54,8 +55,8 @@ func TestClaudeCompiler_CompileRuleWithScope(t *testing.T) {
 	}
 
 	result := results[0]
-	if result.Path != "testRule.md" {
-		t.Errorf("Path = %v, want testRule.md", result.Path)
+	if result.Path != "rules/testRule.md" {
+		t.Errorf("Path = %v, want rules/testRule.md", result.Path)
 	}
 
 	if !strings.Contains(result.Content, "paths:") {
@@ -122,10 +123,7 @@ func TestClaudeCompiler_CompileRuleset(t *testing.T) {
 				Name:        "Test Ruleset",
 				Description: "A test ruleset",
 			},
-			Spec: struct {
-				Rules     map[string]format.RuleItem
-				Fragments map[string]string
-			}{
+			Spec: format.RulesetSpec{
 				Rules: map[string]format.RuleItem{
 					"rule1": {
 						Name:        "Rule One",
@@ -152,11 +150,11 @@ func TestClaudeCompiler_CompileRuleset(t *testing.T) {
 	}
 
 	paths := []string{results[0].Path, results[1].Path}
-	if !contains(paths, "testRuleset_rule1.md") {
-		t.Error("Missing testRuleset_rule1.md")
+	if !contains(paths, "rules/testRuleset_rule1.md") {
+		t.Error("Missing rules/testRuleset_rule1.md")
 	}
-	if !contains(paths, "testRuleset_rule2.md") {
-		t.Error("Missing testRuleset_rule2.md")
+	if !contains(paths, "rules/testRuleset_rule2.md") {
+		t.Error("Missing rules/testRuleset_rule2.md")
 	}
 }
 
@@ -187,12 +185,13 @@ func TestClaudeCompiler_CompilePrompt(t *testing.T) {
 	}
 
 	result := results[0]
-	if result.Path != "testPrompt/SKILL.md" {
-		t.Errorf("Path = %v, want testPrompt/SKILL.md", result.Path)
+	if result.Path != "skills/testPrompt/SKILL.md" {
+		t.Errorf("Path = %v, want skills/testPrompt/SKILL.md", result.Path)
 	}
 
-	if result.Content != "Prompt body content" {
-		t.Errorf("Content = %v, want 'Prompt body content'", result.Content)
+	want := "---\nname: test-prompt\ndescription: A test prompt\n---\n\nPrompt body content"
+	if result.Content != want {
+		t.Errorf("Content = %q, want %q", result.Content, want)
 	}
 }
 
@@ -207,10 +206,7 @@ func TestClaudeCompiler_CompilePromptset(t *testing.T) {
 				Name:        "Test Promptset",
 				Description: "A test promptset",
 			},
-			Spec: struct {
-				Prompts   map[string]format.PromptItem
-				Fragments map[string]string
-			}{
+			Spec: format.PromptsetSpec{
 				Prompts: map[string]format.PromptItem{
 					"prompt1": {
 						Body: format.Body{String: strPtr("First prompt")},
@@ -233,10 +229,273 @@ func TestClaudeCompiler_CompilePromptset(t *testing.T) {
 	}
 
 	paths := []string{results[0].Path, results[1].Path}
-	if !contains(paths, "testPromptset_prompt1/SKILL.md") {
-		t.Error("Missing testPromptset_prompt1/SKILL.md")
+	if !contains(paths, "skills/testPromptset_prompt1/SKILL.md") {
+		t.Error("Missing skills/testPromptset_prompt1/SKILL.md")
 	}
-	if !contains(paths, "testPromptset_prompt2/SKILL.md") {
-		t.Error("Missing testPromptset_prompt2/SKILL.md")
+	if !contains(paths, "skills/testPromptset_prompt2/SKILL.md") {
+		t.Error("Missing skills/testPromptset_prompt2/SKILL.md")
+	}
+}
+
+func TestClaudeCompiler_CompilePromptWithToolsAndArguments(t *testing.T) {
+	c := &ClaudeCompiler{}
+	resource := &compiler.Resource{
+		APIVersion: "ai-resource/draft",
+		Kind:       "Promptset",
+		Spec: &format.Promptset{
+			Metadata: format.Metadata{ID: "git"},
+			Spec: format.PromptsetSpec{
+				Prompts: map[string]format.PromptItem{
+					"commit": {
+						AllowedTools: []string{"Bash(git add:*)", "Bash(git commit:*)"},
+						Arguments:    "[message]",
+						Body:         format.Body{String: strPtr("Commit the staged changes.")},
+					},
+				},
+			},
+		},
+	}
+
+	results, err := c.Compile(resource)
+	if err != nil {
+		t.Fatalf("Compile() error = %v", err)
+	}
+
+	want := "---\nname: git-commit\ndescription: git-commit\nallowed-tools: Bash(git add:*), Bash(git commit:*)\nargument-hint: '[message]'\n---\n\nCommit the staged changes."
+	if results[0].Content != want {
+		t.Errorf("Content = %q, want %q", results[0].Content, want)
+	}
+}
+
+func TestClaudeCompiler_ConfigureSkillNames(t *testing.T) {
+	configured, err := (&ClaudeCompiler{}).Configure(map[string]any{"skillNames": "kebab"})
+	if err != nil {
+		t.Fatalf("Configure() error = %v", err)
+	}
+
+	resource := &compiler.Resource{
+		APIVersion: "ai-resource/draft",
+		Kind:       "Promptset",
+		Spec: &format.Promptset{
+			Metadata: format.Metadata{ID: "gitTools"},
+			Spec: format.PromptsetSpec{
+				Prompts: map[string]format.PromptItem{
+					"releaseNotes_v2": {Body: format.Body{String: strPtr("Write release notes.")}},
+				},
+			},
+		},
+	}
+
+	results, err := configured.Compile(resource)
+	if err != nil {
+		t.Fatalf("Compile() error = %v", err)
+	}
+	if results[0].Path != "skills/git-tools-release-notes-v2/SKILL.md" {
+		t.Errorf("Path = %q, want skills/git-tools-release-notes-v2/SKILL.md", results[0].Path)
+	}
+
+	if _, err := (&ClaudeCompiler{}).Configure(map[string]any{"skillNames": "snake"}); err == nil {
+		t.Error("Configure() expected error for unknown skillNames scheme")
+	}
+}
+
+func TestKebabCase(t *testing.T) {
+	tests := map[string]string{
+		"commit":        "commit",
+		"releaseNotes":  "release-notes",
+		"release_notes": "release-notes",
+		"HTTPClient":    "httpclient",
+		"v2Final":       "v2-final",
+		"trailing_":     "trailing",
+		"already-kebab": "already-kebab",
+		"double__under": "double-under",
+	}
+	for id, want := range tests {
+		if got := kebabCase(id); got != want {
+			t.Errorf("kebabCase(%q) = %q, want %q", id, got, want)
+		}
+	}
+}
+
+func TestClaudeCompiler_CompileCommandWithOneArgument(t *testing.T) {
+	c := &ClaudeCompiler{}
+	resource := &compiler.Resource{
+		APIVersion: "ai-resource/draft",
+		Kind:       "Command",
+		Spec: &format.Command{
+			Metadata: format.Metadata{ID: "explain"},
+			Spec: format.CommandSpec{
+				Arguments: []format.CommandArgument{{Name: "topic", Required: true}},
+				Body:      format.Body{String: strPtr("Explain ${arg:topic}.")},
+			},
+		},
+	}
+
+	results, err := c.Compile(resource)
+	if err != nil {
+		t.Fatalf("Compile() error = %v", err)
+	}
+
+	if results[0].Path != "commands/explain.md" {
+		t.Errorf("Path = %q, want %q", results[0].Path, "commands/explain.md")
+	}
+	want := "---\nargument-hint: <topic>\n---\n\nExplain $ARGUMENTS."
+	if results[0].Content != want {
+		t.Errorf("Content = %q, want %q", results[0].Content, want)
+	}
+}
+
+func TestClaudeCompiler_CompilePromptAssets(t *testing.T) {
+	c := &ClaudeCompiler{}
+	resource := &compiler.Resource{
+		APIVersion: "ai-resource/draft",
+		Kind:       "Promptset",
+		Spec: &format.Promptset{
+			Metadata: format.Metadata{ID: "review"},
+			Spec: format.PromptsetSpec{
+				Assets: []format.Asset{{Path: "shared/logo.png", Data: []byte{0x89, 'P', 'N', 'G', 0}}},
+				Prompts: map[string]format.PromptItem{
+					"check": {
+						Body:   format.Body{String: strPtr("Run scripts/check.sh.")},
+						Assets: []format.Asset{{Path: "check.sh", Name: "scripts/check.sh", Data: []byte("#!/bin/sh\n")}},
+					},
+				},
+			},
+		},
+	}
+
+	results, err := c.Compile(resource)
+	if err != nil {
+		t.Fatalf("Compile() error = %v", err)
+	}
+	want := []compiler.CompilationResult{
+		{Path: "skills/review_check/SKILL.md", Content: "---\nname: review-check\ndescription: review-check\n---\n\nRun scripts/check.sh."},
+		{Path: "skills/review_check/logo.png", Content: "\x89PNG\x00", Asset: true},
+		{Path: "skills/review_check/scripts/check.sh", Content: "#!/bin/sh\n", Asset: true},
+	}
+	if !reflect.DeepEqual(results, want) {
+		t.Errorf("Compile() = %+v, want %+v", results, want)
+	}
+
+	spec := resource.Spec.(*format.Promptset)
+	spec.Spec.Assets = []format.Asset{{Path: "SKILL.md", Data: []byte("x")}}
+	if _, err := c.Compile(resource); err == nil || !strings.Contains(err.Error(), "would replace") {
+		t.Errorf("Compile() error = %v, want an asset replacing SKILL.md rejected", err)
+	}
+	spec.Spec.Assets = []format.Asset{{Path: "unread.sh"}}
+	if _, err := c.Compile(resource); err == nil || !strings.Contains(err.Error(), "was not read") {
+		t.Errorf("Compile() error = %v, want an asset without data rejected", err)
+	}
+}
+
+func TestClaudeCompiler_SkillFrontmatterValidation(t *testing.T) {
+	c := &ClaudeCompiler{}
+	prompt := func(id, description string) *compiler.Resource {
+		return &compiler.Resource{
+			APIVersion: "ai-resource/draft",
+			Kind:       "Promptset",
+			Spec: &format.Promptset{
+				Metadata: format.Metadata{ID: "tools"},
+				Spec: format.PromptsetSpec{
+					Prompts: map[string]format.PromptItem{
+						id: {Name: "Display Name", Description: description, Body: format.Body{String: strPtr("Body.")}},
+					},
+				},
+			},
+		}
+	}
+
+	results, err := c.Compile(prompt("review", ""))
+	if err != nil {
+		t.Fatalf("Compile() error = %v", err)
+	}
+	if want := "---\nname: tools-review\ndescription: Display Name\n---\n\nBody."; results[0].Content != want {
+		t.Errorf("Content = %q, want the display name as the description", results[0].Content)
+	}
+
+	tests := []struct {
+		name        string
+		id          string
+		description string
+		wantErr     string
+	}{
+		{name: "reserved word", id: "askClaude", wantErr: "reserved word"},
+		{name: "long name", id: strings.Repeat("a", 60), wantErr: "1 to 64 characters"},
+		{name: "long description", id: "review", description: strings.Repeat("a", 1025), wantErr: "over the limit of 1024"},
+		{name: "XML tag", id: "review", description: "Use <example> tags", wantErr: "XML tags"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := c.Compile(prompt(tt.id, tt.description)); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Compile() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestClaudeCompiler_ClaudeMD(t *testing.T) {
+	configured, err := (&ClaudeCompiler{}).Configure(map[string]any{"claudeMd": true})
+	if err != nil {
+		t.Fatalf("Configure() error = %v", err)
+	}
+	c := configured.(*ClaudeCompiler)
+
+	ruleset := &compiler.Resource{
+		APIVersion: "ai-resource/draft",
+		Kind:       "Ruleset",
+		Spec: &format.Ruleset{
+			Metadata: format.Metadata{ID: "style"},
+			Spec: format.RulesetSpec{
+				Rules: map[string]format.RuleItem{
+					"naming":  {Enforcement: "must", Body: format.Body{String: strPtr("Name things well.")}},
+					"testing": {Enforcement: "should", Body: format.Body{String: strPtr("Test things.")}},
+				},
+				Order: []string{"naming", "testing"},
+			},
+		},
+	}
+	context := &compiler.Resource{
+		APIVersion: "ai-resource/draft",
+		Kind:       "Context",
+		Spec: &format.Context{
+			Metadata: format.Metadata{ID: "project", Name: "Project"},
+			Spec:     format.ContextSpec{Body: format.Body{String: strPtr("A Go service.")}},
+		},
+	}
+
+	var all []compiler.CompilationResult
+	for _, resource := range []*compiler.Resource{ruleset, context} {
+		results, err := c.Compile(resource)
+		if err != nil {
+			t.Fatalf("Compile() error = %v", err)
+		}
+		// Each resource is merged on its own, then all of them together.
+		merged, err := c.Merge(results)
+		if err != nil {
+			t.Fatalf("Merge() error = %v", err)
+		}
+		all = append(all, merged...)
+	}
+	merged, err := c.Merge(all)
+	if err != nil {
+		t.Fatalf("Merge() error = %v", err)
+	}
+
+	var claudeMD string
+	for _, result := range merged {
+		if result.Path == "CLAUDE.md" {
+			claudeMD = result.Content
+		}
+	}
+	if want := "# Project\n\nA Go service.\n\n@rules/style_naming.md\n@rules/style_testing.md"; claudeMD != want {
+		t.Errorf("CLAUDE.md = %q, want %q", claudeMD, want)
+	}
+	if len(merged) != 3 {
+		t.Errorf("Merge() returned %d results, want the two rules and CLAUDE.md", len(merged))
+	}
+
+	results, err := (&ClaudeCompiler{}).Compile(ruleset)
+	if err != nil || len(results) != 2 {
+		t.Errorf("Compile() without claudeMd = %d results, %v, want the rules alone", len(results), err)
 	}
 }
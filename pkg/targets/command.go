@@ -0,0 +1,82 @@
+package targets
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jomadu/ai-resource-compiler-go/internal/format"
+	"github.com/jomadu/ai-resource-compiler-go/pkg/compiler"
+)
+
+// commandBody returns the resolved body of command with each ${arg:name}
+// reference replaced by placeholder(i, arg), i being the argument's index.
+func commandBody(command *format.Command, placeholder func(int, format.CommandArgument) string) string {
+	body := format.ResolveBody(command.Spec.Body, command.Spec.Fragments)
+	return format.ExpandArguments(body, command.Spec.Arguments, placeholder)
+}
+
+// namedArgument writes an argument as <name>, for tools that append the
+// arguments given to a command to its prompt rather than substituting them.
+func namedArgument(_ int, arg format.CommandArgument) string {
+	return "<" + arg.Name + ">"
+}
+
+// commandDescription returns the description of a command, or its name if
+// it has none.
+func commandDescription(command *format.Command) string {
+	if command.Metadata.Description != "" {
+		return command.Metadata.Description
+	}
+	return command.Metadata.Name
+}
+
+// argumentHint returns the usage of a command's arguments, e.g.
+// "<issue> [branch]": required arguments in angle brackets and optional ones
+// in square brackets.
+func argumentHint(args []format.CommandArgument) string {
+	hints := make([]string, len(args))
+	for i, arg := range args {
+		if arg.Required {
+			hints[i] = "<" + arg.Name + ">"
+		} else {
+			hints[i] = "[" + arg.Name + "]"
+		}
+	}
+	return strings.Join(hints, " ")
+}
+
+// argumentsSection returns a section listing a command's arguments, which
+// follows the body for tools that append arguments to the prompt, or "" if
+// it has none.
+func argumentsSection(args []format.CommandArgument) string {
+	if len(args) == 0 {
+		return ""
+	}
+	var sb strings.Builder
+	sb.WriteString("\n\n## Arguments\n\nGiven after the command, in order:\n")
+	for _, arg := range args {
+		sb.WriteString("\n- `<" + arg.Name + ">`")
+		if arg.Required {
+			sb.WriteString(" (required)")
+		}
+		if arg.Description != "" {
+			sb.WriteString(": " + arg.Description)
+		}
+	}
+	return sb.String()
+}
+
+// argumentMapping describes how a command's ${arg:name} references compile,
+// for Explain, or returns nil if it has no arguments.
+func argumentMapping(args []format.CommandArgument, placeholder func(int, format.CommandArgument) string) []compiler.Mapping {
+	if len(args) == 0 {
+		return nil
+	}
+	refs := make([]string, len(args))
+	outputs := make([]string, len(args))
+	for i, arg := range args {
+		refs[i] = fmt.Sprintf("${arg:%s}", arg.Name)
+		outputs[i] = placeholder(i, arg)
+	}
+	return []compiler.Mapping{{Field: strings.Join(refs, ", "), Output: strings.Join(outputs, ", ")}}
+}
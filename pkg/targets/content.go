@@ -0,0 +1,103 @@
+package targets
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/jomadu/ai-resource-compiler-go/internal/format"
+	"github.com/jomadu/ai-resource-compiler-go/pkg/compiler"
+	"gopkg.in/yaml.v3"
+)
+
+// contentOptionNames are the options accepted by every built-in target.
+var contentOptionNames = []string{"lean", "embedSource"}
+
+// EmbedSource modes.
+const (
+	EmbedSourcePath = "path" // comment naming the source file and rule
+	EmbedSourceYAML = "yaml" // the rule's source YAML in a collapsed section
+)
+
+// ContentOptions control the rule content shared by every built-in target.
+type ContentOptions struct {
+	// Lean omits the metadata block, leaving the enforcement header and body.
+	Lean bool
+
+	// EmbedSource appends a trace back to the rule's source: EmbedSourcePath,
+	// EmbedSourceYAML, or "" for none.
+	EmbedSource string
+}
+
+// configure applies the content options present in options.
+func (o *ContentOptions) configure(options map[string]any) error {
+	lean, err := boolOption(options, "lean")
+	if err != nil {
+		return err
+	}
+	if lean != nil {
+		o.Lean = *lean
+	}
+
+	if value, ok := options["embedSource"]; ok {
+		mode, _ := value.(string)
+		switch mode {
+		case "", EmbedSourcePath, EmbedSourceYAML:
+			o.EmbedSource = mode
+		default:
+			return fmt.Errorf("option embedSource must be %q or %q, got %v", EmbedSourcePath, EmbedSourceYAML, value)
+		}
+	}
+	return nil
+}
+
+// ruleContent returns the content of a standalone rule loaded from source.
+func (o ContentOptions) ruleContent(rule *format.Rule, source string) string {
+	var content string
+	if o.Lean {
+		content = format.GenerateLeanRuleFromRule(rule)
+	} else {
+		content = format.GenerateRuleMetadataBlockFromRule(rule)
+	}
+
+	resource := compiler.Resource{APIVersion: "ai-resource/draft", Kind: "Rule", Spec: rule}
+	return content + o.sourceSection(source, rule.Metadata.ID, resource)
+}
+
+// rulesetRuleContent returns the content of one rule of a ruleset loaded
+// from source.
+func (o ContentOptions) rulesetRuleContent(ruleset *format.Ruleset, ruleID, source string) string {
+	var content string
+	if o.Lean {
+		content = format.GenerateLeanRuleFromRuleset(ruleset, ruleID)
+	} else {
+		content = format.GenerateRuleMetadataBlockFromRuleset(ruleset, ruleID)
+	}
+
+	item := map[string]map[string]format.RuleItem{"rules": {ruleID: ruleset.Spec.Rules[ruleID]}}
+	return content + o.sourceSection(source, ruleset.Metadata.ID+"/"+ruleID, item)
+}
+
+// sourceSection returns the EmbedSource trailer for a rule, or "" when
+// EmbedSource is not set.
+func (o ContentOptions) sourceSection(source, ref string, value any) string {
+	origin := ref
+	if source != "" {
+		origin = filepath.ToSlash(source) + "#" + ref
+	}
+
+	switch o.EmbedSource {
+	case EmbedSourcePath:
+		return fmt.Sprintf("\n\n<!-- source: %s -->", origin)
+	case EmbedSourceYAML:
+		var b strings.Builder
+		encoder := yaml.NewEncoder(&b)
+		encoder.SetIndent(2)
+		if err := encoder.Encode(value); err != nil {
+			return fmt.Sprintf("\n\n<!-- source: %s -->", origin)
+		}
+		encoder.Close()
+		return fmt.Sprintf("\n\n<details>\n<summary>Source: %s</summary>\n\n```yaml\n%s```\n\n</details>", origin, b.String())
+	}
+	return ""
+}
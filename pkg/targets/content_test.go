@@ -0,0 +1,94 @@
+package targets
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jomadu/ai-resource-compiler-go/internal/format"
+	"github.com/jomadu/ai-resource-compiler-go/pkg/compiler"
+)
+
+func TestContentOptions_EmbedSource(t *testing.T) {
+	ruleset := &format.Ruleset{Metadata: format.Metadata{ID: "cleanCode"}}
+	ruleset.Spec.Rules = map[string]format.RuleItem{
+		"meaningfulNames": {
+			Name:        "Meaningful Names",
+			Enforcement: "must",
+			Body:        format.Body{String: strPtr("Use descriptive names.")},
+		},
+	}
+	resource := &compiler.Resource{
+		APIVersion: "ai-resource/draft",
+		Kind:       "Ruleset",
+		Spec:       ruleset,
+		Source:     "rules/clean-code.yaml",
+	}
+
+	tests := []struct {
+		mode string
+		want string
+	}{
+		{
+			mode: "path",
+			want: "Use descriptive names.\n\n<!-- source: rules/clean-code.yaml#cleanCode/meaningfulNames -->",
+		},
+		{
+			mode: "yaml",
+			want: "Use descriptive names.\n\n<details>\n<summary>Source: rules/clean-code.yaml#cleanCode/meaningfulNames</summary>\n\n" +
+				"```yaml\nrules:\n  meaningfulNames:\n    name: Meaningful Names\n    enforcement: must\n    body: Use descriptive names.\n```\n\n</details>",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			c, err := (&MarkdownCompiler{}).Configure(map[string]any{"embedSource": tt.mode})
+			if err != nil {
+				t.Fatalf("Configure() error = %v", err)
+			}
+			results, err := c.Compile(resource)
+			if err != nil {
+				t.Fatalf("Compile() error = %v", err)
+			}
+			if !strings.HasSuffix(results[0].Content, tt.want) {
+				t.Errorf("Content = %q, want suffix %q", results[0].Content, tt.want)
+			}
+		})
+	}
+}
+
+func TestContentOptions_EmbedSourceStandaloneRule(t *testing.T) {
+	c, err := (&CursorCompiler{}).Configure(map[string]any{"embedSource": "yaml", "lean": true})
+	if err != nil {
+		t.Fatalf("Configure() error = %v", err)
+	}
+	resource := &compiler.Resource{
+		APIVersion: "ai-resource/draft",
+		Kind:       "Rule",
+		Spec: &format.Rule{
+			Metadata: format.Metadata{ID: "noSecrets", Name: "No Secrets"},
+			Spec: format.RuleSpec{
+				Enforcement: "must",
+				Body:        format.Body{String: strPtr("Never commit secrets.")},
+			},
+		},
+	}
+
+	results, err := c.Compile(resource)
+	if err != nil {
+		t.Fatalf("Compile() error = %v", err)
+	}
+	for _, want := range []string{"<summary>Source: noSecrets</summary>", "kind: Rule\nmetadata:\n  id: noSecrets"} {
+		if !strings.Contains(results[0].Content, want) {
+			t.Errorf("Content missing %q:\n%s", want, results[0].Content)
+		}
+	}
+}
+
+func TestContentOptions_Errors(t *testing.T) {
+	if _, err := (&MarkdownCompiler{}).Configure(map[string]any{"embedSource": "link"}); err == nil {
+		t.Error("Configure() expected error for unknown embedSource mode")
+	}
+	if _, err := (&MarkdownCompiler{}).Configure(map[string]any{"lean": "yes"}); err == nil {
+		t.Error("Configure() expected error for non-boolean lean")
+	}
+}
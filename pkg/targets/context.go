@@ -0,0 +1,120 @@
+package targets
+
+import (
+	"path"
+	"strings"
+
+	"github.com/jomadu/ai-resource-compiler-go/internal/format"
+	"github.com/jomadu/ai-resource-compiler-go/pkg/compiler"
+)
+
+// Context files, the single files tools load in every session. Each Context
+// resource compiles to a section of its target's context file, and Merge
+// joins the sections of every Context into it.
+const (
+	agentsContextFile  = "AGENTS.md"
+	claudeContextFile  = "CLAUDE.md"
+	copilotContextFile = "copilot-instructions.md"
+)
+
+// compileContext compiles a Context to its section of file: the resolved body
+// under a heading with the context's name, or the body alone if it has none.
+func compileContext(resource *compiler.Resource, file string) ([]compiler.CompilationResult, error) {
+	context := resource.Spec.(*format.Context)
+
+	if err := format.ValidateID(context.Metadata.ID); err != nil {
+		return nil, err
+	}
+
+	content := format.ResolveBody(context.Spec.Body, context.Spec.Fragments)
+	if context.Metadata.Name != "" {
+		content = "# " + context.Metadata.Name + "\n\n" + content
+	}
+	return []compiler.CompilationResult{{Path: file, Content: content}}, nil
+}
+
+// splitResults returns the results whose file name is file apart from the
+// others.
+func splitResults(results []compiler.CompilationResult, file string) (matched, rest []compiler.CompilationResult) {
+	for _, result := range results {
+		if path.Base(result.Path) == file {
+			matched = append(matched, result)
+		} else {
+			rest = append(rest, result)
+		}
+	}
+	return matched, rest
+}
+
+// mergeContext joins the results whose file name is file, in order and
+// separated by blank lines, into the first of them. Other results are
+// returned unchanged.
+func mergeContext(results []compiler.CompilationResult, file string) []compiler.CompilationResult {
+	var merged []compiler.CompilationResult
+	index := make(map[string]int)
+	for _, result := range results {
+		if path.Base(result.Path) != file {
+			merged = append(merged, result)
+			continue
+		}
+		if i, seen := index[result.Path]; seen {
+			merged[i].Content += "\n\n" + result.Content
+			continue
+		}
+		index[result.Path] = len(merged)
+		merged = append(merged, result)
+	}
+	return merged
+}
+
+// mergeImports moves the @path import lines of the context files named file
+// after their sections, one per line and each once. mergeContext leaves the
+// imports of rules among the sections, separated by blank lines; a file
+// merged again keeps its imports at the end.
+func mergeImports(results []compiler.CompilationResult, file string) []compiler.CompilationResult {
+	for i, result := range results {
+		if path.Base(result.Path) != file {
+			continue
+		}
+		var sections, imports []string
+		seen := make(map[string]bool)
+		for _, block := range strings.Split(result.Content, "\n\n") {
+			lines := strings.Split(block, "\n")
+			if !isImportBlock(lines) {
+				sections = append(sections, block)
+				continue
+			}
+			for _, line := range lines {
+				if !seen[line] {
+					seen[line] = true
+					imports = append(imports, line)
+				}
+			}
+		}
+		if len(imports) > 0 {
+			results[i].Content = strings.Join(append(sections, strings.Join(imports, "\n")), "\n\n")
+		}
+	}
+	return results
+}
+
+// isImportBlock reports whether every line of a block is an @path import.
+func isImportBlock(lines []string) bool {
+	for _, line := range lines {
+		if !strings.HasPrefix(line, "@") || strings.ContainsAny(line, " \t") {
+			return false
+		}
+	}
+	return len(lines) > 0
+}
+
+// contextExplanation describes how a Context compiles to its section of
+// file.
+func contextExplanation(item explainItem, file string) compiler.Explanation {
+	mappings := []compiler.Mapping{{Field: "(file)", Output: "section of the shared " + file}}
+	if item.name != "" {
+		mappings = append(mappings, compiler.Mapping{Field: "name", Output: "heading \"# " + item.name + "\""})
+	}
+	mappings = append(mappings, compiler.Mapping{Field: "body", Output: "section content, fragments resolved"})
+	return compiler.Explanation{Path: file, Mappings: mappings}
+}
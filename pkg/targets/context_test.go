@@ -0,0 +1,44 @@
+package targets
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/jomadu/ai-resource-compiler-go/internal/format"
+	"github.com/jomadu/ai-resource-compiler-go/pkg/compiler"
+)
+
+func TestClaudeCompiler_MergeContexts(t *testing.T) {
+	c := &ClaudeCompiler{}
+	var results []compiler.CompilationResult
+	for _, context := range []*format.Context{
+		{Metadata: format.Metadata{ID: "overview", Name: "Overview"}, Spec: format.ContextSpec{Body: format.Body{String: strPtr("A billing service.")}}},
+		{Metadata: format.Metadata{ID: "commands"}, Spec: format.ContextSpec{Body: format.Body{String: strPtr("Run make test.")}}},
+	} {
+		compiled, err := c.Compile(&compiler.Resource{APIVersion: "ai-resource/draft", Kind: "Context", Spec: context})
+		if err != nil {
+			t.Fatalf("Compile() error = %v", err)
+		}
+		results = append(results, compiled...)
+	}
+	results = append(results, compiler.CompilationResult{Path: "naming.md", Content: "# Naming"})
+
+	merged, err := c.Merge(results)
+	if err != nil {
+		t.Fatalf("Merge() error = %v", err)
+	}
+	want := []compiler.CompilationResult{
+		{Path: "CLAUDE.md", Content: "# Overview\n\nA billing service.\n\nRun make test."},
+		{Path: "naming.md", Content: "# Naming"},
+	}
+	if !reflect.DeepEqual(merged, want) {
+		t.Errorf("Merge() = %+v, want %+v", merged, want)
+	}
+
+	// Merging in steps, as for several resources, gives the same file.
+	first, _ := c.Merge(results[:1])
+	stepwise, err := c.Merge(append(first, results[1:]...))
+	if err != nil || !reflect.DeepEqual(stepwise, want) {
+		t.Errorf("stepwise Merge() = %+v, %v, want %+v", stepwise, err, want)
+	}
+}
@@ -2,6 +2,7 @@ package targets
 
 import (
 	"fmt"
+	"slices"
 	"strings"
 
 	"github.com/jomadu/ai-resource-compiler-go/internal/format"
@@ -9,7 +10,26 @@ import (
 	"gopkg.in/yaml.v3"
 )
 
-type CopilotCompiler struct{}
+// Copilot output subdirectories, matching .github/instructions and
+// .github/prompts where VS Code discovers them.
+const (
+	copilotInstructionsDir = "instructions/"
+	copilotPromptsDir      = "prompts/"
+)
+
+// Copilot agents an instructions file can be hidden from with excludeAgent.
+const (
+	CopilotAgentCodeReview  = "code-review"
+	CopilotAgentCodingAgent = "coding-agent"
+)
+
+type CopilotCompiler struct {
+	ContentOptions
+
+	// ExcludeAgent, if set, hides every instructions file from one Copilot
+	// agent: CopilotAgentCodeReview or CopilotAgentCodingAgent.
+	ExcludeAgent string
+}
 
 func init() {
 	compiler.RegisterDefaultTarget(compiler.TargetCopilot, &CopilotCompiler{})
@@ -23,9 +43,39 @@ func (c *CopilotCompiler) SupportedVersions() []string {
 	return []string{"ai-resource/draft"}
 }
 
+// DefaultOutputDir returns .github, which holds the instructions/ and
+// prompts/ directories VS Code discovers.
+func (c *CopilotCompiler) DefaultOutputDir() string {
+	return ".github"
+}
+
+// AddBanner adds banner as a comment; see commentBanner.
+func (c *CopilotCompiler) AddBanner(path, content, banner string) string {
+	return commentBanner(path, content, banner)
+}
+
+// Configure accepts the content options and excludeAgent.
+func (c *CopilotCompiler) Configure(options map[string]any) (compiler.TargetCompiler, error) {
+	if err := checkOptions(options, append(contentOptionNames, "excludeAgent")...); err != nil {
+		return nil, err
+	}
+	configured := *c
+	if err := configured.ContentOptions.configure(options); err != nil {
+		return nil, err
+	}
+	excludeAgent, err := stringOption(options, "excludeAgent", CopilotAgentCodeReview, CopilotAgentCodingAgent)
+	if err != nil {
+		return nil, err
+	}
+	if excludeAgent != "" {
+		configured.ExcludeAgent = excludeAgent
+	}
+	return &configured, nil
+}
+
 func (c *CopilotCompiler) Compile(resource *compiler.Resource) ([]compiler.CompilationResult, error) {
 	if resource.APIVersion != "ai-resource/draft" {
-		return nil, fmt.Errorf("unsupported apiVersion: %s for copilot", resource.APIVersion)
+		return nil, fmt.Errorf("%w: %s for copilot", compiler.ErrUnsupportedVersion, resource.APIVersion)
 	}
 
 	switch resource.Kind {
@@ -37,11 +87,64 @@ func (c *CopilotCompiler) Compile(resource *compiler.Resource) ([]compiler.Compi
 		return c.compilePrompt(resource)
 	case "Promptset":
 		return c.compilePromptset(resource)
+	case "Command":
+		return c.compileCommand(resource)
+	case "Context":
+		return compileContext(resource, copilotContextFile)
 	default:
-		return nil, fmt.Errorf("unsupported kind: %s", resource.Kind)
+		return nil, fmt.Errorf("%w: %s", compiler.ErrUnsupportedKind, resource.Kind)
 	}
 }
 
+// Merge joins the sections of every Context into one copilot-instructions.md. Other results
+// are returned unchanged.
+func (c *CopilotCompiler) Merge(results []compiler.CompilationResult) ([]compiler.CompilationResult, error) {
+	return mergeContext(results, copilotContextFile), nil
+}
+
+// Explain describes how resource compiles to Copilot instructions and prompts.
+func (c *CopilotCompiler) Explain(resource *compiler.Resource) ([]compiler.Explanation, error) {
+	return explain(c, resource, func(item explainItem) compiler.Explanation {
+		if item.context {
+			return contextExplanation(item, copilotContextFile)
+		}
+		if item.command {
+			mappings := []compiler.Mapping{{Field: "description", Output: "description"}}
+			mappings = append(mappings, argumentMapping(item.commandArguments, copilotArgument)...)
+			return compiler.Explanation{Path: copilotPromptsDir + item.path(".prompt.md"), Mappings: append(mappings, c.contentMappings(item)...)}
+		}
+		if !item.rule {
+			mappings := []compiler.Mapping{{Field: "description (or name)", Output: "description"}}
+			if item.mode != "" {
+				mappings = append(mappings, compiler.Mapping{Field: "mode", Output: "mode: " + item.mode})
+			}
+			if item.model != "" {
+				mappings = append(mappings, compiler.Mapping{Field: "model", Output: "model: " + item.model})
+			}
+			if len(item.tools) > 0 {
+				mappings = append(mappings, compiler.Mapping{Field: "tools", Output: "tools: " + strings.Join(item.tools, ", ")})
+			}
+			return compiler.Explanation{Path: copilotPromptsDir + item.path(".prompt.md"), Mappings: append(mappings, c.contentMappings(item)...)}
+		}
+
+		applyTo := "no applyTo (attached by hand)"
+		switch files := extractScopeFiles(item.scope); {
+		case len(files) > 0:
+			applyTo = "applyTo: " + strings.Join(files, ", ")
+		case activation(item.enforcement) == compiler.ActivationAlways:
+			applyTo = fmt.Sprintf(`applyTo: "**" (enforcement: %s)`, item.enforcement)
+		}
+		mappings := []compiler.Mapping{
+			{Field: "description (or name)", Output: "description"},
+			{Field: scopeField(item.scope), Output: applyTo},
+		}
+		if c.ExcludeAgent != "" {
+			mappings = append(mappings, compiler.Mapping{Field: "(option excludeAgent)", Output: "excludeAgent: " + c.ExcludeAgent})
+		}
+		return compiler.Explanation{Path: copilotInstructionsDir + item.path(".instructions.md"), Mappings: append(mappings, c.contentMappings(item)...)}
+	})
+}
+
 func (c *CopilotCompiler) compileRule(resource *compiler.Resource) ([]compiler.CompilationResult, error) {
 	rule := resource.Spec.(*format.Rule)
 
@@ -52,10 +155,9 @@ func (c *CopilotCompiler) compileRule(resource *compiler.Resource) ([]compiler.C
 		return nil, err
 	}
 
-	scopeFiles := extractScopeFiles(rule.Spec.Scope)
-	frontmatter := generateApplyToFrontmatter(scopeFiles)
-	path := format.BuildStandalonePath(rule.Metadata.ID, ".instructions.md")
-	metadataBlock := format.GenerateRuleMetadataBlockFromRule(rule)
+	frontmatter := c.instructionsFrontmatter(rule.Metadata.Description, rule.Metadata.Name, rule.Metadata.ID, rule.Spec.Scope, rule.Spec.Enforcement)
+	path := copilotInstructionsDir + format.BuildStandalonePath(rule.Metadata.ID, ".instructions.md")
+	metadataBlock := c.ruleContent(rule, resource.Source)
 	content := frontmatter + "\n" + metadataBlock
 
 	return []compiler.CompilationResult{{Path: path, Content: content}}, nil
@@ -69,7 +171,7 @@ func (c *CopilotCompiler) compileRuleset(resource *compiler.Resource) ([]compile
 	}
 
 	var results []compiler.CompilationResult
-	for ruleID := range ruleset.Spec.Rules {
+	for _, ruleID := range ruleset.Spec.RuleIDs() {
 		if err := format.ValidateID(ruleID); err != nil {
 			return nil, err
 		}
@@ -78,10 +180,9 @@ func (c *CopilotCompiler) compileRuleset(resource *compiler.Resource) ([]compile
 			return nil, err
 		}
 
-		scopeFiles := extractScopeFiles(ruleSpec.Scope)
-		frontmatter := generateApplyToFrontmatter(scopeFiles)
-		path := format.BuildCollectionPath(ruleset.Metadata.ID, ruleID, ".instructions.md")
-		metadataBlock := format.GenerateRuleMetadataBlockFromRuleset(ruleset, ruleID)
+		frontmatter := c.instructionsFrontmatter(ruleSpec.Description, ruleSpec.Name, ruleID, ruleSpec.Scope, ruleSpec.Enforcement)
+		path := copilotInstructionsDir + format.BuildCollectionPath(ruleset.Metadata.ID, ruleID, ".instructions.md")
+		metadataBlock := c.rulesetRuleContent(ruleset, ruleID, resource.Source)
 		content := frontmatter + "\n" + metadataBlock
 
 		results = append(results, compiler.CompilationResult{Path: path, Content: content})
@@ -97,10 +198,21 @@ func (c *CopilotCompiler) compilePrompt(resource *compiler.Resource) ([]compiler
 		return nil, err
 	}
 
-	frontmatter := generateApplyToFrontmatter([]string{})
-	path := format.BuildStandalonePath(prompt.Metadata.ID, ".prompt.md")
-	body := format.ResolveBody(prompt.Spec.Body, prompt.Spec.Fragments)
-	content := frontmatter + "\n" + body
+	description := prompt.Metadata.Description
+	if description == "" {
+		description = prompt.Metadata.Name
+	}
+	frontmatter, err := generatePromptFileFrontmatter(promptFileFrontmatter{
+		Description: description,
+		Mode:        prompt.Spec.Mode,
+		Model:       prompt.Spec.Model,
+		Tools:       prompt.Spec.Tools,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("prompt %s: %w", prompt.Metadata.ID, err)
+	}
+	path := copilotPromptsDir + format.BuildStandalonePath(prompt.Metadata.ID, ".prompt.md")
+	content := frontmatter + format.ResolveBody(prompt.Spec.Body, prompt.Spec.Fragments)
 
 	return []compiler.CompilationResult{{Path: path, Content: content}}, nil
 }
@@ -113,16 +225,27 @@ func (c *CopilotCompiler) compilePromptset(resource *compiler.Resource) ([]compi
 	}
 
 	var results []compiler.CompilationResult
-	for promptID := range promptset.Spec.Prompts {
+	for _, promptID := range promptset.Spec.PromptIDs() {
 		if err := format.ValidateID(promptID); err != nil {
 			return nil, err
 		}
 
 		promptSpec := promptset.Spec.Prompts[promptID]
-		frontmatter := generateApplyToFrontmatter([]string{})
-		path := format.BuildCollectionPath(promptset.Metadata.ID, promptID, ".prompt.md")
-		body := format.ResolveBody(promptSpec.Body, promptset.Spec.Fragments)
-		content := frontmatter + "\n" + body
+		description := promptSpec.Description
+		if description == "" {
+			description = promptSpec.Name
+		}
+		frontmatter, err := generatePromptFileFrontmatter(promptFileFrontmatter{
+			Description: description,
+			Mode:        promptSpec.Mode,
+			Model:       promptSpec.Model,
+			Tools:       promptSpec.Tools,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("prompt %s/%s: %w", promptset.Metadata.ID, promptID, err)
+		}
+		path := copilotPromptsDir + format.BuildCollectionPath(promptset.Metadata.ID, promptID, ".prompt.md")
+		content := frontmatter + format.ResolveBody(promptSpec.Body, promptset.Spec.Fragments)
 
 		results = append(results, compiler.CompilationResult{Path: path, Content: content})
 	}
@@ -130,18 +253,106 @@ func (c *CopilotCompiler) compilePromptset(resource *compiler.Resource) ([]compi
 	return results, nil
 }
 
-func generateApplyToFrontmatter(files []string) string {
-	frontmatter := map[string]interface{}{
-		"applyTo": files,
+// Copilot chat modes a prompt file can run in.
+var copilotModes = []string{"ask", "edit", "agent"}
+
+// generatePromptFileFrontmatter returns the frontmatter of a prompt file,
+// followed by a blank line, or "" if it sets nothing. An unknown mode is an
+// error.
+func generatePromptFileFrontmatter(frontmatter promptFileFrontmatter) (string, error) {
+	if frontmatter.Mode != "" && !slices.Contains(copilotModes, frontmatter.Mode) {
+		return "", fmt.Errorf("unknown Copilot mode %q (expected %s)", frontmatter.Mode, strings.Join(copilotModes, ", "))
+	}
+	if frontmatter.Description == "" && frontmatter.Mode == "" && frontmatter.Model == "" && len(frontmatter.Tools) == 0 {
+		return "", nil
 	}
+	return encodeFrontmatter(frontmatter) + "\n\n", nil
+}
 
-	var b strings.Builder
-	b.WriteString("---\n")
-	encoder := yaml.NewEncoder(&b)
-	encoder.SetIndent(2)
-	encoder.Encode(frontmatter)
-	encoder.Close()
-	b.WriteString("---")
+// instructionsFrontmatter returns the frontmatter of an instructions file
+// for a rule: its description, or failing that its name or ID, and where
+// it applies.
+func (c *CopilotCompiler) instructionsFrontmatter(description, name, id string, scope []format.ScopeEntry, enforcement string) string {
+	if description == "" {
+		description = name
+	}
+	if description == "" {
+		description = id
+	}
+	return encodeFrontmatter(instructionsFrontmatter{
+		Description:  description,
+		ApplyTo:      copilotApplyTo(extractScopeFiles(scope), enforcement),
+		ExcludeAgent: c.ExcludeAgent,
+	})
+}
 
-	return b.String()
+// copilotApplyTo returns the applyTo of a rule: its scope globs, or "**" for
+// a rule loaded always, such as a must rule, that has no scope. Other rules
+// without scope get no applyTo, so Copilot applies them only when they are
+// attached by hand. Copilot reads applyTo as one string, so several globs
+// are joined with commas.
+func copilotApplyTo(files []string, enforcement string) *yaml.Node {
+	if len(files) > 0 {
+		return format.GlobNode(strings.Join(files, ","))
+	}
+	if activation(enforcement) == compiler.ActivationAlways {
+		return format.GlobNode("**")
+	}
+	return nil
+}
+
+// ImportRule reads a .instructions.md file: instructions applied to "**"
+// are must, those with other applyTo globs should, and others may, unless
+// the file has arc's enforcement header.
+func (c *CopilotCompiler) ImportRule(p string, content []byte) (compiler.ImportedRule, bool, error) {
+	if !strings.HasSuffix(p, ".instructions.md") {
+		return compiler.ImportedRule{}, false, nil
+	}
+	file := parseImported(p, content)
+	var frontmatter struct {
+		Description string      `yaml:"description"`
+		ApplyTo     importGlobs `yaml:"applyTo"`
+	}
+	if err := file.decodeFrontmatter(&frontmatter); err != nil {
+		return compiler.ImportedRule{}, false, err
+	}
+	globs := []string(frontmatter.ApplyTo)
+	enforcement := "may"
+	switch {
+	case slices.Equal(globs, []string{"**"}):
+		enforcement, globs = "must", nil
+	case len(globs) > 0:
+		enforcement = "should"
+	}
+	return file.rule(frontmatter.Description, enforcement, globs), true, nil
+}
+
+// compileCommand compiles a command to a Copilot prompt file, whose
+// arguments are input variables Copilot asks for when it runs.
+func (c *CopilotCompiler) compileCommand(resource *compiler.Resource) ([]compiler.CompilationResult, error) {
+	command := resource.Spec.(*format.Command)
+
+	if err := format.ValidateID(command.Metadata.ID); err != nil {
+		return nil, err
+	}
+
+	var content strings.Builder
+	if description := commandDescription(command); description != "" {
+		content.WriteString(encodeFrontmatter(promptFileFrontmatter{Description: description}))
+		content.WriteString("\n\n")
+	}
+	content.WriteString(commandBody(command, copilotArgument))
+
+	path := copilotPromptsDir + format.BuildStandalonePath(command.Metadata.ID, ".prompt.md")
+	return []compiler.CompilationResult{{Path: path, Content: content.String()}}, nil
+}
+
+// copilotArgument writes an argument as a prompt file input variable,
+// ${input:name:description}, with the description as its placeholder text
+// when it has one that fits.
+func copilotArgument(_ int, arg format.CommandArgument) string {
+	if arg.Description == "" || strings.ContainsAny(arg.Description, "}\n") {
+		return "${input:" + arg.Name + "}"
+	}
+	return "${input:" + arg.Name + ":" + arg.Description + "}"
 }
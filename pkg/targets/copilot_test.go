@@ -54,8 +54,8 @@ func TestCopilotCompiler_CompileRule(t *testing.T) {
 	}
 
 	result := results[0]
-	if result.Path != "testRule.instructions.md" {
-		t.Errorf("Path = %v, want testRule.instructions.md", result.Path)
+	if result.Path != "instructions/testRule.instructions.md" {
+		t.Errorf("Path = %v, want instructions/testRule.instructions.md", result.Path)
 	}
 
 	if !strings.Contains(result.Content, "applyTo:") {
@@ -83,10 +83,7 @@ func TestCopilotCompiler_CompileRuleset(t *testing.T) {
 				Name:        "Test Ruleset",
 				Description: "A test ruleset",
 			},
-			Spec: struct {
-				Rules     map[string]format.RuleItem
-				Fragments map[string]string
-			}{
+			Spec: format.RulesetSpec{
 				Rules: map[string]format.RuleItem{
 					"rule1": {
 						Name:        "Rule One",
@@ -113,11 +110,11 @@ func TestCopilotCompiler_CompileRuleset(t *testing.T) {
 	}
 
 	paths := []string{results[0].Path, results[1].Path}
-	if !contains(paths, "testRuleset_rule1.instructions.md") {
-		t.Error("Missing testRuleset_rule1.instructions.md")
+	if !contains(paths, "instructions/testRuleset_rule1.instructions.md") {
+		t.Error("Missing instructions/testRuleset_rule1.instructions.md")
 	}
-	if !contains(paths, "testRuleset_rule2.instructions.md") {
-		t.Error("Missing testRuleset_rule2.instructions.md")
+	if !contains(paths, "instructions/testRuleset_rule2.instructions.md") {
+		t.Error("Missing instructions/testRuleset_rule2.instructions.md")
 	}
 }
 
@@ -148,15 +145,48 @@ func TestCopilotCompiler_CompilePrompt(t *testing.T) {
 	}
 
 	result := results[0]
-	if result.Path != "testPrompt.prompt.md" {
-		t.Errorf("Path = %v, want testPrompt.prompt.md", result.Path)
+	if result.Path != "prompts/testPrompt.prompt.md" {
+		t.Errorf("Path = %v, want prompts/testPrompt.prompt.md", result.Path)
 	}
 
-	if !strings.Contains(result.Content, "applyTo:") {
-		t.Error("Content missing applyTo frontmatter")
+	if want := "---\ndescription: A test prompt\n---\n\nPrompt body content"; result.Content != want {
+		t.Errorf("Content = %q, want %q", result.Content, want)
 	}
-	if !strings.Contains(result.Content, "Prompt body content") {
-		t.Error("Content missing body")
+}
+
+func TestCopilotCompiler_CompilePromptModeModelTools(t *testing.T) {
+	c := &CopilotCompiler{}
+	resource := &compiler.Resource{
+		APIVersion: "ai-resource/draft",
+		Kind:       "Promptset",
+		Spec: &format.Promptset{
+			Metadata: format.Metadata{ID: "review"},
+			Spec: format.PromptsetSpec{
+				Prompts: map[string]format.PromptItem{
+					"security": {
+						Description: "Review for security issues",
+						Mode:        "agent",
+						Model:       "GPT-4o",
+						Tools:       []string{"codebase", "githubRepo"},
+						Body:        format.Body{String: strPtr("Review the diff.")},
+					},
+				},
+			},
+		},
+	}
+
+	results, err := c.Compile(resource)
+	if err != nil {
+		t.Fatalf("Compile() error = %v", err)
+	}
+	want := "---\ndescription: Review for security issues\nmode: agent\nmodel: GPT-4o\ntools:\n  - codebase\n  - githubRepo\n---\n\nReview the diff."
+	if results[0].Content != want {
+		t.Errorf("Content = %q, want %q", results[0].Content, want)
+	}
+
+	resource.Spec.(*format.Promptset).Spec.Prompts["security"] = format.PromptItem{Mode: "chat", Body: format.Body{String: strPtr("x")}}
+	if _, err := c.Compile(resource); err == nil || !strings.Contains(err.Error(), `unknown Copilot mode "chat"`) {
+		t.Errorf("Compile() error = %v, want unknown mode", err)
 	}
 }
 
@@ -171,10 +201,7 @@ func TestCopilotCompiler_CompilePromptset(t *testing.T) {
 				Name:        "Test Promptset",
 				Description: "A test promptset",
 			},
-			Spec: struct {
-				Prompts   map[string]format.PromptItem
-				Fragments map[string]string
-			}{
+			Spec: format.PromptsetSpec{
 				Prompts: map[string]format.PromptItem{
 					"prompt1": {
 						Body: format.Body{String: strPtr("First prompt")},
@@ -197,10 +224,118 @@ func TestCopilotCompiler_CompilePromptset(t *testing.T) {
 	}
 
 	paths := []string{results[0].Path, results[1].Path}
-	if !contains(paths, "testPromptset_prompt1.prompt.md") {
-		t.Error("Missing testPromptset_prompt1.prompt.md")
+	if !contains(paths, "prompts/testPromptset_prompt1.prompt.md") {
+		t.Error("Missing prompts/testPromptset_prompt1.prompt.md")
+	}
+	if !contains(paths, "prompts/testPromptset_prompt2.prompt.md") {
+		t.Error("Missing prompts/testPromptset_prompt2.prompt.md")
 	}
-	if !contains(paths, "testPromptset_prompt2.prompt.md") {
-		t.Error("Missing testPromptset_prompt2.prompt.md")
+}
+
+func TestCopilotCompiler_ApplyTo(t *testing.T) {
+	tests := []struct {
+		name        string
+		enforcement string
+		scope       []format.ScopeEntry
+		want        string
+	}{
+		{"scoped", "must", []format.ScopeEntry{{Files: []string{"*.go"}}}, "---\ndescription: Test Rule\napplyTo: \"*.go\"\n---\n"},
+		{"several globs", "should", []format.ScopeEntry{{Files: []string{"*.go", "*.mod"}}, {Files: []string{"cmd/**"}}}, "---\ndescription: Test Rule\napplyTo: \"*.go,*.mod,cmd/**\"\n---\n"},
+		{"must without scope", "must", nil, "---\ndescription: Test Rule\napplyTo: \"**\"\n---\n"},
+		{"should without scope", "should", nil, "---\ndescription: Test Rule\n---\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resource := &compiler.Resource{
+				APIVersion: "ai-resource/draft",
+				Kind:       "Rule",
+				Spec: &format.Rule{
+					Metadata: format.Metadata{ID: "testRule", Name: "Test Rule"},
+					Spec: format.RuleSpec{
+						Enforcement: tt.enforcement,
+						Scope:       tt.scope,
+						Body:        format.Body{String: strPtr("Rule body content")},
+					},
+				},
+			}
+			results, err := (&CopilotCompiler{}).Compile(resource)
+			if err != nil {
+				t.Fatalf("Compile() error = %v", err)
+			}
+			if !strings.HasPrefix(results[0].Content, tt.want) {
+				t.Errorf("Content = %q, want prefix %q", results[0].Content, tt.want)
+			}
+		})
+	}
+}
+
+func TestCopilotCompiler_DescriptionFallsBackToID(t *testing.T) {
+	resource := &compiler.Resource{
+		APIVersion: "ai-resource/draft",
+		Kind:       "Ruleset",
+		Spec: &format.Ruleset{
+			Metadata: format.Metadata{ID: "testRuleset"},
+			Spec: format.RulesetSpec{
+				Rules: map[string]format.RuleItem{
+					"unnamed": {
+						Enforcement: "should",
+						Body:        format.Body{String: strPtr("Rule body content")},
+					},
+				},
+			},
+		},
+	}
+	results, err := (&CopilotCompiler{}).Compile(resource)
+	if err != nil {
+		t.Fatalf("Compile() error = %v", err)
+	}
+	if want := "---\ndescription: unnamed\n---\n"; !strings.HasPrefix(results[0].Content, want) {
+		t.Errorf("Content = %q, want prefix %q", results[0].Content, want)
+	}
+}
+
+func TestCopilotCompiler_ConfigureExcludeAgent(t *testing.T) {
+	configured, err := (&CopilotCompiler{}).Configure(map[string]any{"excludeAgent": "code-review"})
+	if err != nil {
+		t.Fatalf("Configure() error = %v", err)
+	}
+
+	rule := &compiler.Resource{
+		APIVersion: "ai-resource/draft",
+		Kind:       "Rule",
+		Spec: &format.Rule{
+			Metadata: format.Metadata{ID: "testRule", Name: "Test Rule"},
+			Spec: format.RuleSpec{
+				Enforcement: "must",
+				Body:        format.Body{String: strPtr("Rule body content")},
+			},
+		},
+	}
+	results, err := configured.Compile(rule)
+	if err != nil {
+		t.Fatalf("Compile() error = %v", err)
+	}
+	if !strings.HasPrefix(results[0].Content, "---\ndescription: Test Rule\napplyTo: \"**\"\nexcludeAgent: code-review\n---\n") {
+		t.Errorf("Content missing excludeAgent frontmatter:\n%s", results[0].Content)
+	}
+
+	prompt := &compiler.Resource{
+		APIVersion: "ai-resource/draft",
+		Kind:       "Prompt",
+		Spec: &format.Prompt{
+			Metadata: format.Metadata{ID: "testPrompt"},
+			Spec:     format.PromptSpec{Body: format.Body{String: strPtr("Prompt body")}},
+		},
+	}
+	results, err = configured.Compile(prompt)
+	if err != nil {
+		t.Fatalf("Compile() error = %v", err)
+	}
+	if strings.Contains(results[0].Content, "excludeAgent") {
+		t.Errorf("Prompt content has excludeAgent:\n%s", results[0].Content)
+	}
+
+	if _, err := (&CopilotCompiler{}).Configure(map[string]any{"excludeAgent": "chat"}); err == nil {
+		t.Error("Configure() expected error for unknown agent")
 	}
 }
@@ -2,14 +2,67 @@ package targets
 
 import (
 	"fmt"
+	"path"
 	"strings"
 
 	"github.com/jomadu/ai-resource-compiler-go/internal/format"
 	"github.com/jomadu/ai-resource-compiler-go/pkg/compiler"
-	"gopkg.in/yaml.v3"
 )
 
-type CursorCompiler struct{}
+// Cursor rule types, which decide how Cursor attaches a rule to a request.
+const (
+	CursorRuleAlways = "always" // alwaysApply, included in every request
+	CursorRuleAuto   = "auto"   // attached when a file matches the scope globs
+	CursorRuleAgent  = "agent"  // description only, the agent decides
+	CursorRuleManual = "manual" // included only when referenced explicitly
+)
+
+// DefaultCursorRuleTypes maps enforcement levels to Cursor rule types:
+// may rules are agent-requested, should rules auto-attached, and must rules
+// always applied. Levels missing from it use the rule type of their
+// activation; see cursorActivations.
+var DefaultCursorRuleTypes = map[string]string{
+	"may":    CursorRuleAgent,
+	"should": CursorRuleAuto,
+	"must":   CursorRuleAlways,
+}
+
+// Cursor directories: cursorDir, relative to the project root, and those of
+// rules and commands in it.
+const (
+	cursorDir         = ".cursor/"
+	cursorRulesDir    = "rules/"
+	cursorCommandsDir = "commands/"
+)
+
+// cursorActivations maps enforcement activations to Cursor rule types.
+var cursorActivations = map[compiler.Activation]string{
+	compiler.ActivationAlways:    CursorRuleAlways,
+	compiler.ActivationScoped:    CursorRuleAuto,
+	compiler.ActivationOnRequest: CursorRuleAgent,
+	compiler.ActivationManual:    CursorRuleManual,
+}
+
+// CursorCompiler compiles resources to Cursor rules (.mdc) and commands.
+type CursorCompiler struct {
+	ContentOptions
+
+	// AlwaysApply, if set, overrides the alwaysApply frontmatter of every
+	// rule instead of deriving it from enforcement.
+	AlwaysApply *bool
+
+	// RuleTypes, if set, maps enforcement levels to Cursor rule types.
+	// Levels missing from the map use DefaultCursorRuleTypes. When nil, every
+	// rule keeps its scope globs and only must rules are always applied.
+	RuleTypes map[string]string
+
+	// NestedRules writes results relative to the project root instead of
+	// .cursor: a rule whose scope points into a single directory goes
+	// to that directory's .cursor/rules, which Cursor reads for files under
+	// it, other rules to .cursor/rules, prompts and commands to
+	// .cursor/commands, and AGENTS.md to the root.
+	NestedRules bool
+}
 
 func init() {
 	compiler.RegisterDefaultTarget(compiler.TargetCursor, &CursorCompiler{})
@@ -23,9 +76,76 @@ func (c *CursorCompiler) SupportedVersions() []string {
 	return []string{"ai-resource/draft"}
 }
 
+// DefaultOutputDir returns .cursor, whose rules and commands directories
+// Cursor reads, or the project root with NestedRules.
+func (c *CursorCompiler) DefaultOutputDir() string {
+	if c.NestedRules {
+		return "."
+	}
+	return ".cursor"
+}
+
+// ProjectRoot returns AGENTS.md, which Cursor reads from the project root,
+// apart from the other results. With NestedRules, every result is relative
+// to the root already.
+func (c *CursorCompiler) ProjectRoot(results []compiler.CompilationResult) (root, rest []compiler.CompilationResult) {
+	if c.NestedRules {
+		return nil, results
+	}
+	return splitResults(results, agentsContextFile)
+}
+
+// AddBanner adds banner to a rule's frontmatter as its generated key, and to
+// other files as a comment; see commentBanner.
+func (c *CursorCompiler) AddBanner(path, content, banner string) string {
+	if !strings.HasSuffix(path, ".mdc") || !strings.HasPrefix(content, "---\n") {
+		return commentBanner(path, content, banner)
+	}
+	key := format.EncodeYAML(struct {
+		Generated string `yaml:"generated"`
+	}{banner})
+	return "---\n" + key + strings.TrimPrefix(content, "---\n")
+}
+
+// Configure accepts the content options, alwaysApply (bool), ruleTypes
+// (true for DefaultCursorRuleTypes, or a map of enforcement level to rule
+// type), and nestedRules (bool).
+func (c *CursorCompiler) Configure(options map[string]any) (compiler.TargetCompiler, error) {
+	if err := checkOptions(options, append(contentOptionNames, "alwaysApply", "ruleTypes", "nestedRules")...); err != nil {
+		return nil, err
+	}
+	configured := *c
+	if err := configured.ContentOptions.configure(options); err != nil {
+		return nil, err
+	}
+	alwaysApply, err := boolOption(options, "alwaysApply")
+	if err != nil {
+		return nil, err
+	}
+	if alwaysApply != nil {
+		configured.AlwaysApply = alwaysApply
+	}
+	nestedRules, err := boolOption(options, "nestedRules")
+	if err != nil {
+		return nil, err
+	}
+	if nestedRules != nil {
+		configured.NestedRules = *nestedRules
+	}
+	ruleTypes, set, err := enforcementMappingOption(options, "ruleTypes",
+		CursorRuleAlways, CursorRuleAuto, CursorRuleAgent, CursorRuleManual)
+	if err != nil {
+		return nil, err
+	}
+	if set {
+		configured.RuleTypes = ruleTypes
+	}
+	return &configured, nil
+}
+
 func (c *CursorCompiler) Compile(resource *compiler.Resource) ([]compiler.CompilationResult, error) {
 	if resource.APIVersion != "ai-resource/draft" {
-		return nil, fmt.Errorf("unsupported apiVersion: %s for cursor", resource.APIVersion)
+		return nil, fmt.Errorf("%w: %s for cursor", compiler.ErrUnsupportedVersion, resource.APIVersion)
 	}
 
 	switch resource.Kind {
@@ -37,11 +157,67 @@ func (c *CursorCompiler) Compile(resource *compiler.Resource) ([]compiler.Compil
 		return c.compilePrompt(resource)
 	case "Promptset":
 		return c.compilePromptset(resource)
+	case "Command":
+		return c.compileCommand(resource)
+	case "Context":
+		return compileContext(resource, agentsContextFile)
 	default:
-		return nil, fmt.Errorf("unsupported kind: %s", resource.Kind)
+		return nil, fmt.Errorf("%w: %s", compiler.ErrUnsupportedKind, resource.Kind)
 	}
 }
 
+// Merge joins the sections of every Context into one AGENTS.md. Other results
+// are returned unchanged.
+func (c *CursorCompiler) Merge(results []compiler.CompilationResult) ([]compiler.CompilationResult, error) {
+	return mergeContext(results, agentsContextFile), nil
+}
+
+// Explain describes how resource compiles to Cursor rules and commands.
+func (c *CursorCompiler) Explain(resource *compiler.Resource) ([]compiler.Explanation, error) {
+	return explain(c, resource, func(item explainItem) compiler.Explanation {
+		if item.context {
+			return contextExplanation(item, agentsContextFile)
+		}
+		if item.command {
+			mappings := argumentMapping(item.commandArguments, namedArgument)
+			if len(item.commandArguments) > 0 {
+				mappings = append(mappings, compiler.Mapping{Field: "arguments", Output: "\"Arguments\" section after the body"})
+			}
+			return compiler.Explanation{Path: c.commandPath(item.path(".md")), Mappings: append(mappings, c.contentMappings(item)...)}
+		}
+		if !item.rule {
+			return compiler.Explanation{Path: c.commandPath(item.path(".md")), Mappings: c.contentMappings(item)}
+		}
+
+		desc, globs, alwaysApply, ruleType := c.ruleFields(item.description, item.name, item.scope, item.enforcement)
+		descField := "description: " + item.description
+		if item.description == "" {
+			descField = "name: " + item.name
+		}
+		globsOutput := "globs: " + strings.Join(globs, ", ")
+		if len(globs) == 0 {
+			globsOutput = "globs: (none)"
+		}
+		applyOutput := fmt.Sprintf("alwaysApply: %t", alwaysApply)
+		if ruleType != "" {
+			applyOutput += fmt.Sprintf(" (rule type %s)", ruleType)
+		}
+		if c.AlwaysApply != nil {
+			applyOutput += " (alwaysApply option)"
+		}
+
+		mappings := []compiler.Mapping{
+			{Field: descField, Output: "description: " + desc},
+			{Field: scopeField(item.scope), Output: globsOutput},
+			{Field: "enforcement: " + item.enforcement, Output: applyOutput},
+		}
+		if dir := nestedRulesDir(item.scope); c.NestedRules && dir != "" {
+			mappings = append(mappings, compiler.Mapping{Field: scopeField(item.scope), Output: "nested in " + dir + "/" + cursorDir + cursorRulesDir + " (nestedRules)"})
+		}
+		return compiler.Explanation{Path: c.rulePath(item.scope, item.path(".mdc")), Mappings: append(mappings, c.contentMappings(item)...)}
+	})
+}
+
 func (c *CursorCompiler) compileRule(resource *compiler.Resource) ([]compiler.CompilationResult, error) {
 	rule := resource.Spec.(*format.Rule)
 
@@ -52,10 +228,9 @@ func (c *CursorCompiler) compileRule(resource *compiler.Resource) ([]compiler.Co
 		return nil, err
 	}
 
-	scopeFiles := extractScopeFiles(rule.Spec.Scope)
-	frontmatter := generateMDCFrontmatter(rule.Metadata.Description, rule.Metadata.Name, scopeFiles, rule.Spec.Enforcement)
-	path := format.BuildStandalonePath(rule.Metadata.ID, ".mdc")
-	metadataBlock := format.GenerateRuleMetadataBlockFromRule(rule)
+	frontmatter := c.frontmatter(rule.Metadata.Description, rule.Metadata.Name, rule.Spec.Scope, rule.Spec.Enforcement)
+	path := c.rulePath(rule.Spec.Scope, format.BuildStandalonePath(rule.Metadata.ID, ".mdc"))
+	metadataBlock := c.ruleContent(rule, resource.Source)
 	content := frontmatter + "\n" + metadataBlock
 
 	return []compiler.CompilationResult{{Path: path, Content: content}}, nil
@@ -69,7 +244,7 @@ func (c *CursorCompiler) compileRuleset(resource *compiler.Resource) ([]compiler
 	}
 
 	var results []compiler.CompilationResult
-	for ruleID := range ruleset.Spec.Rules {
+	for _, ruleID := range ruleset.Spec.RuleIDs() {
 		if err := format.ValidateID(ruleID); err != nil {
 			return nil, err
 		}
@@ -78,10 +253,9 @@ func (c *CursorCompiler) compileRuleset(resource *compiler.Resource) ([]compiler
 			return nil, err
 		}
 
-		scopeFiles := extractScopeFiles(ruleSpec.Scope)
-		frontmatter := generateMDCFrontmatter(ruleSpec.Description, ruleSpec.Name, scopeFiles, ruleSpec.Enforcement)
-		path := format.BuildCollectionPath(ruleset.Metadata.ID, ruleID, ".mdc")
-		metadataBlock := format.GenerateRuleMetadataBlockFromRuleset(ruleset, ruleID)
+		frontmatter := c.frontmatter(ruleSpec.Description, ruleSpec.Name, ruleSpec.Scope, ruleSpec.Enforcement)
+		path := c.rulePath(ruleSpec.Scope, format.BuildCollectionPath(ruleset.Metadata.ID, ruleID, ".mdc"))
+		metadataBlock := c.rulesetRuleContent(ruleset, ruleID, resource.Source)
 		content := frontmatter + "\n" + metadataBlock
 
 		results = append(results, compiler.CompilationResult{Path: path, Content: content})
@@ -97,7 +271,7 @@ func (c *CursorCompiler) compilePrompt(resource *compiler.Resource) ([]compiler.
 		return nil, err
 	}
 
-	path := format.BuildStandalonePath(prompt.Metadata.ID, ".md")
+	path := c.commandPath(format.BuildStandalonePath(prompt.Metadata.ID, ".md"))
 	content := format.ResolveBody(prompt.Spec.Body, prompt.Spec.Fragments)
 
 	return []compiler.CompilationResult{{Path: path, Content: content}}, nil
@@ -111,13 +285,13 @@ func (c *CursorCompiler) compilePromptset(resource *compiler.Resource) ([]compil
 	}
 
 	var results []compiler.CompilationResult
-	for promptID := range promptset.Spec.Prompts {
+	for _, promptID := range promptset.Spec.PromptIDs() {
 		if err := format.ValidateID(promptID); err != nil {
 			return nil, err
 		}
 
 		promptSpec := promptset.Spec.Prompts[promptID]
-		path := format.BuildCollectionPath(promptset.Metadata.ID, promptID, ".md")
+		path := c.commandPath(format.BuildCollectionPath(promptset.Metadata.ID, promptID, ".md"))
 		content := format.ResolveBody(promptSpec.Body, promptset.Spec.Fragments)
 
 		results = append(results, compiler.CompilationResult{Path: path, Content: content})
@@ -126,35 +300,185 @@ func (c *CursorCompiler) compilePromptset(resource *compiler.Resource) ([]compil
 	return results, nil
 }
 
+// ImportRule reads a .mdc rule: alwaysApply rules are must, rules with
+// globs should, and others may, unless the file has arc's enforcement
+// header.
+func (c *CursorCompiler) ImportRule(p string, content []byte) (compiler.ImportedRule, bool, error) {
+	if !strings.HasSuffix(p, ".mdc") {
+		return compiler.ImportedRule{}, false, nil
+	}
+	file := parseImported(p, content)
+	var frontmatter struct {
+		Description string      `yaml:"description"`
+		Globs       importGlobs `yaml:"globs"`
+		AlwaysApply bool        `yaml:"alwaysApply"`
+	}
+	if err := file.decodeFrontmatter(&frontmatter); err != nil {
+		return compiler.ImportedRule{}, false, err
+	}
+	enforcement := "may"
+	switch {
+	case frontmatter.AlwaysApply:
+		enforcement = "must"
+	case len(frontmatter.Globs) > 0:
+		enforcement = "should"
+	}
+	return file.rule(frontmatter.Description, enforcement, frontmatter.Globs), true, nil
+}
+
+// rulePath returns the path of the rule file named file with the given
+// scope: file in rules, or with NestedRules, file in the .cursor/rules of the
+// directory the scope points into, or of the project root.
+func (c *CursorCompiler) rulePath(scope []format.ScopeEntry, file string) string {
+	if !c.NestedRules {
+		return cursorRulesDir + file
+	}
+	if dir := nestedRulesDir(scope); dir != "" {
+		return dir + "/" + cursorDir + cursorRulesDir + file
+	}
+	return cursorDir + cursorRulesDir + file
+}
+
+// commandPath returns the path of the prompt or command file named file:
+// file in commands, or in .cursor/commands with NestedRules.
+func (c *CursorCompiler) commandPath(file string) string {
+	if !c.NestedRules {
+		return cursorCommandsDir + file
+	}
+	return cursorDir + cursorCommandsDir + file
+}
+
+// nestedRulesDir returns the directory every glob a scope includes lies in,
+// such as packages/api for packages/api/** and packages/api/src/*.go, or ""
+// if the globs lie in different directories, the scope matches files
+// anywhere, or there is no scope.
+func nestedRulesDir(scope []format.ScopeEntry) string {
+	var dir string
+	for i, glob := range format.ScopeIncludes(scope) {
+		d := literalDir(glob)
+		switch {
+		case d == "" || d == ".." || strings.HasPrefix(d, "../") || path.IsAbs(d):
+			return ""
+		case i == 0:
+			dir = d
+		default:
+			dir = commonDir(dir, d)
+			if dir == "" {
+				return ""
+			}
+		}
+	}
+	return dir
+}
+
+// literalDir returns the directories glob begins with before its first
+// wildcard or, for a glob without wildcards, its directory.
+func literalDir(glob string) string {
+	parts := strings.Split(strings.TrimPrefix(path.Clean(glob), "./"), "/")
+	n := len(parts) - 1
+	for i, part := range parts[:n] {
+		if strings.ContainsAny(part, "*?[{") {
+			n = i
+			break
+		}
+	}
+	return strings.Join(parts[:n], "/")
+}
+
+// commonDir returns the longest directory a and b both lie in, or "".
+func commonDir(a, b string) string {
+	as, bs := strings.Split(a, "/"), strings.Split(b, "/")
+	n := 0
+	for n < len(as) && n < len(bs) && as[n] == bs[n] {
+		n++
+	}
+	return strings.Join(as[:n], "/")
+}
+
+// extractScopeFiles returns the glob list of a rule's scope, exclusions
+// written as "!" globs; see format.ScopeGlobs.
 func extractScopeFiles(scope []format.ScopeEntry) []string {
-	var files []string
-	for _, entry := range scope {
-		files = append(files, entry.Files...)
+	return format.ScopeGlobs(scope)
+}
+
+// appliesTo describes the files a scoped rule applies to, for targets that
+// state its scope in prose, or returns "" for an unscoped rule.
+func appliesTo(scope []format.ScopeEntry) string {
+	files := format.ScopeIncludes(scope)
+	if len(files) == 0 {
+		return ""
+	}
+	s := "Applies to files matching: `" + strings.Join(files, "`, `") + "`"
+	if exclude := format.ScopeExcludes(scope); len(exclude) > 0 {
+		s += ", except those matching: `" + strings.Join(exclude, "`, `") + "`"
 	}
-	return files
+	return s
 }
 
-func generateMDCFrontmatter(description, name string, globs []string, enforcement string) string {
+// frontmatter returns the MDC frontmatter for a rule.
+func (c *CursorCompiler) frontmatter(description, name string, scope []format.ScopeEntry, enforcement string) string {
+	desc, globs, alwaysApply, _ := c.ruleFields(description, name, scope, enforcement)
+	return generateMDCFrontmatter(desc, globs, alwaysApply)
+}
+
+// ruleFields returns the description, globs, and alwaysApply frontmatter of
+// a rule, and its rule type when RuleTypes is set. With RuleTypes set, the
+// rule type mapped from enforcement decides which fields are emitted. An auto
+// rule without scope globs cannot be attached automatically, so it is
+// agent-requested instead.
+func (c *CursorCompiler) ruleFields(description, name string, scope []format.ScopeEntry, enforcement string) (string, []string, bool, string) {
 	desc := description
 	if desc == "" {
 		desc = name
 	}
+	globs := extractScopeFiles(scope)
+	alwaysApply := activation(enforcement) == compiler.ActivationAlways
+
+	var ruleType string
+	if c.RuleTypes != nil {
+		var ok bool
+		ruleType, ok = c.RuleTypes[enforcement]
+		if !ok {
+			ruleType = defaultMode(DefaultCursorRuleTypes, cursorActivations, enforcement)
+		}
+		if ruleType == CursorRuleAuto && len(globs) == 0 {
+			ruleType = CursorRuleAgent
+		}
+
+		switch ruleType {
+		case CursorRuleAlways:
+			globs, alwaysApply = nil, true
+		case CursorRuleAuto:
+			alwaysApply = false
+		case CursorRuleAgent:
+			globs, alwaysApply = nil, false
+		case CursorRuleManual:
+			desc, globs, alwaysApply = "", nil, false
+		}
+	}
 
-	alwaysApply := enforcement == "must"
+	if c.AlwaysApply != nil {
+		alwaysApply = *c.AlwaysApply
+	}
+	return desc, globs, alwaysApply, ruleType
+}
 
-	frontmatter := map[string]interface{}{
-		"description": desc,
-		"globs":       globs,
-		"alwaysApply": alwaysApply,
+func generateMDCFrontmatter(description string, globs []string, alwaysApply bool) string {
+	return encodeFrontmatter(mdcFrontmatter{Description: description, Globs: globs, AlwaysApply: alwaysApply})
+}
+
+// compileCommand compiles a command to a Cursor command, to be installed in
+// .cursor/commands/. Cursor appends what follows the command to its prompt,
+// so arguments are written as <name> and listed after the body.
+func (c *CursorCompiler) compileCommand(resource *compiler.Resource) ([]compiler.CompilationResult, error) {
+	command := resource.Spec.(*format.Command)
+
+	if err := format.ValidateID(command.Metadata.ID); err != nil {
+		return nil, err
 	}
 
-	var b strings.Builder
-	b.WriteString("---\n")
-	encoder := yaml.NewEncoder(&b)
-	encoder.SetIndent(2)
-	encoder.Encode(frontmatter)
-	encoder.Close()
-	b.WriteString("---")
+	path := c.commandPath(format.BuildStandalonePath(command.Metadata.ID, ".md"))
+	content := commandBody(command, namedArgument) + argumentsSection(command.Spec.Arguments)
 
-	return b.String()
+	return []compiler.CompilationResult{{Path: path, Content: content}}, nil
 }
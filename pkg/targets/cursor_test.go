@@ -1,6 +1,7 @@
 package targets
 
 import (
+	"reflect"
 	"strings"
 	"testing"
 
@@ -54,8 +55,8 @@ func TestCursorCompiler_CompileRule(t *testing.T) {
 	}
 
 	result := results[0]
-	if result.Path != "testRule.mdc" {
-		t.Errorf("Path = %v, want testRule.mdc", result.Path)
+	if result.Path != "rules/testRule.mdc" {
+		t.Errorf("Path = %v, want rules/testRule.mdc", result.Path)
 	}
 
 	if !strings.Contains(result.Content, "description: A test rule") {
@@ -86,10 +87,7 @@ func TestCursorCompiler_CompileRuleset(t *testing.T) {
 				Name:        "Test Ruleset",
 				Description: "A test ruleset",
 			},
-			Spec: struct {
-				Rules     map[string]format.RuleItem
-				Fragments map[string]string
-			}{
+			Spec: format.RulesetSpec{
 				Rules: map[string]format.RuleItem{
 					"rule1": {
 						Name:        "Rule One",
@@ -116,11 +114,11 @@ func TestCursorCompiler_CompileRuleset(t *testing.T) {
 	}
 
 	paths := []string{results[0].Path, results[1].Path}
-	if !contains(paths, "testRuleset_rule1.mdc") {
-		t.Error("Missing testRuleset_rule1.mdc")
+	if !contains(paths, "rules/testRuleset_rule1.mdc") {
+		t.Error("Missing rules/testRuleset_rule1.mdc")
 	}
-	if !contains(paths, "testRuleset_rule2.mdc") {
-		t.Error("Missing testRuleset_rule2.mdc")
+	if !contains(paths, "rules/testRuleset_rule2.mdc") {
+		t.Error("Missing rules/testRuleset_rule2.mdc")
 	}
 }
 
@@ -151,8 +149,8 @@ func TestCursorCompiler_CompilePrompt(t *testing.T) {
 	}
 
 	result := results[0]
-	if result.Path != "testPrompt.md" {
-		t.Errorf("Path = %v, want testPrompt.md", result.Path)
+	if result.Path != "commands/testPrompt.md" {
+		t.Errorf("Path = %v, want commands/testPrompt.md", result.Path)
 	}
 
 	if result.Content != "Prompt body content" {
@@ -171,10 +169,7 @@ func TestCursorCompiler_CompilePromptset(t *testing.T) {
 				Name:        "Test Promptset",
 				Description: "A test promptset",
 			},
-			Spec: struct {
-				Prompts   map[string]format.PromptItem
-				Fragments map[string]string
-			}{
+			Spec: format.PromptsetSpec{
 				Prompts: map[string]format.PromptItem{
 					"prompt1": {
 						Body: format.Body{String: strPtr("First prompt")},
@@ -197,10 +192,249 @@ func TestCursorCompiler_CompilePromptset(t *testing.T) {
 	}
 
 	paths := []string{results[0].Path, results[1].Path}
-	if !contains(paths, "testPromptset_prompt1.md") {
-		t.Error("Missing testPromptset_prompt1.md")
+	if !contains(paths, "commands/testPromptset_prompt1.md") {
+		t.Error("Missing commands/testPromptset_prompt1.md")
 	}
-	if !contains(paths, "testPromptset_prompt2.md") {
-		t.Error("Missing testPromptset_prompt2.md")
+	if !contains(paths, "commands/testPromptset_prompt2.md") {
+		t.Error("Missing commands/testPromptset_prompt2.md")
+	}
+}
+
+func TestCursorCompiler_ConfigureAlwaysApply(t *testing.T) {
+	base := &CursorCompiler{}
+	configured, err := base.Configure(map[string]any{"alwaysApply": true})
+	if err != nil {
+		t.Fatalf("Configure() error = %v", err)
+	}
+	if base.AlwaysApply != nil {
+		t.Error("Configure() modified the receiver")
+	}
+
+	resource := &compiler.Resource{
+		APIVersion: "ai-resource/draft",
+		Kind:       "Rule",
+		Spec: &format.Rule{
+			Metadata: format.Metadata{ID: "testRule", Name: "Test Rule"},
+			Spec: format.RuleSpec{
+				Enforcement: "should",
+				Body:        format.Body{String: strPtr("Rule body content")},
+			},
+		},
+	}
+
+	results, err := configured.Compile(resource)
+	if err != nil {
+		t.Fatalf("Compile() error = %v", err)
+	}
+	if !strings.Contains(results[0].Content, "alwaysApply: true") {
+		t.Errorf("Content missing alwaysApply override:\n%s", results[0].Content)
+	}
+
+	results, err = base.Compile(resource)
+	if err != nil {
+		t.Fatalf("Compile() error = %v", err)
+	}
+	if !strings.Contains(results[0].Content, "alwaysApply: false") {
+		t.Errorf("Content = %s, want alwaysApply derived from enforcement", results[0].Content)
+	}
+}
+
+func TestCursorCompiler_ConfigureErrors(t *testing.T) {
+	c := &CursorCompiler{}
+	if _, err := c.Configure(map[string]any{"alwaysApply": "yes"}); err == nil {
+		t.Error("Configure() expected error for non-boolean alwaysApply")
+	}
+	_, err := c.Configure(map[string]any{"globs": true})
+	if err == nil || !strings.Contains(err.Error(), "unknown option(s) globs") {
+		t.Errorf("Configure() error = %v, want unknown option error", err)
+	}
+}
+
+func TestCursorCompiler_RuleTypes(t *testing.T) {
+	tests := []struct {
+		name        string
+		ruleTypes   any
+		enforcement string
+		scope       []format.ScopeEntry
+		want        []string
+		notWant     []string
+	}{
+		{
+			name:        "may is agent requested",
+			ruleTypes:   true,
+			enforcement: "may",
+			scope:       []format.ScopeEntry{{Files: []string{"**/*.go"}}},
+			want:        []string{"description: A test rule", "globs: []", "alwaysApply: false"},
+			notWant:     []string{"**/*.go\n---"},
+		},
+		{
+			name:        "should is auto attached",
+			ruleTypes:   true,
+			enforcement: "should",
+			scope:       []format.ScopeEntry{{Files: []string{"**/*.go"}}},
+			want:        []string{"globs:\n  - \"**/*.go\"", "alwaysApply: false"},
+		},
+		{
+			name:        "should without scope is agent requested",
+			ruleTypes:   true,
+			enforcement: "should",
+			want:        []string{"description: A test rule", "globs: []", "alwaysApply: false"},
+		},
+		{
+			name:        "must is always applied",
+			ruleTypes:   true,
+			enforcement: "must",
+			scope:       []format.ScopeEntry{{Files: []string{"**/*.go"}}},
+			want:        []string{"globs: []", "alwaysApply: true"},
+		},
+		{
+			name:        "custom mapping",
+			ruleTypes:   map[string]any{"must": "manual"},
+			enforcement: "must",
+			want:        []string{"description: \"\"", "globs: []", "alwaysApply: false"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := (&CursorCompiler{}).Configure(map[string]any{"ruleTypes": tt.ruleTypes})
+			if err != nil {
+				t.Fatalf("Configure() error = %v", err)
+			}
+			resource := &compiler.Resource{
+				APIVersion: "ai-resource/draft",
+				Kind:       "Rule",
+				Spec: &format.Rule{
+					Metadata: format.Metadata{ID: "testRule", Name: "Test Rule", Description: "A test rule"},
+					Spec: format.RuleSpec{
+						Enforcement: tt.enforcement,
+						Scope:       tt.scope,
+						Body:        format.Body{String: strPtr("Rule body content")},
+					},
+				},
+			}
+
+			results, err := c.Compile(resource)
+			if err != nil {
+				t.Fatalf("Compile() error = %v", err)
+			}
+			frontmatter := results[0].Content[:strings.Index(results[0].Content, "\n---\n")+4]
+			for _, want := range tt.want {
+				if !strings.Contains(frontmatter, want) {
+					t.Errorf("Frontmatter missing %q:\n%s", want, frontmatter)
+				}
+			}
+			for _, notWant := range tt.notWant {
+				if strings.Contains(frontmatter, notWant) {
+					t.Errorf("Frontmatter contains %q:\n%s", notWant, frontmatter)
+				}
+			}
+		})
+	}
+}
+
+func TestCursorCompiler_RuleTypesErrors(t *testing.T) {
+	c := &CursorCompiler{}
+	for _, value := range []any{"yes", map[string]any{"always": "auto"}, map[string]any{"must": "sometimes"}} {
+		if _, err := c.Configure(map[string]any{"ruleTypes": value}); err == nil {
+			t.Errorf("Configure(ruleTypes: %v) expected error", value)
+		}
+	}
+}
+
+func TestCursorCompiler_ScopeLanguagesAndExclude(t *testing.T) {
+	resource := &compiler.Resource{
+		APIVersion: "ai-resource/draft",
+		Kind:       "Rule",
+		Spec: &format.Rule{
+			Metadata: format.Metadata{ID: "goRule", Name: "Go Rule"},
+			Spec: format.RuleSpec{
+				Enforcement: "should",
+				Scope: []format.ScopeEntry{
+					{Languages: []string{"go"}, Directories: []string{"internal"}, Exclude: []string{"**/*_test.go"}},
+				},
+				Body: format.Body{String: strPtr("Rule body content")},
+			},
+		},
+	}
+
+	results, err := (&CursorCompiler{}).Compile(resource)
+	if err != nil {
+		t.Fatalf("Compile() error = %v", err)
+	}
+	want := "globs:\n  - \"**/*.go\"\n  - \"internal/**\"\n  - \"!**/*_test.go\"\n"
+	if !strings.Contains(results[0].Content, want) {
+		t.Errorf("Content missing %s:\n%s", want, results[0].Content)
+	}
+
+	results, err = (&GeminiCompiler{}).Compile(resource)
+	if err != nil {
+		t.Fatalf("Compile() error = %v", err)
+	}
+	want = "Applies to files matching: `**/*.go`, `internal/**`, except those matching: `**/*_test.go`"
+	if !strings.Contains(results[0].Content, want) {
+		t.Errorf("GEMINI.md section missing %q:\n%s", want, results[0].Content)
+	}
+}
+
+func TestCursorCompiler_NestedRules(t *testing.T) {
+	configured, err := (&CursorCompiler{}).Configure(map[string]any{"nestedRules": true})
+	if err != nil {
+		t.Fatalf("Configure() error = %v", err)
+	}
+	if dir := configured.(*CursorCompiler).DefaultOutputDir(); dir != "." {
+		t.Errorf("DefaultOutputDir() = %q, want the project root", dir)
+	}
+
+	resource := &compiler.Resource{
+		APIVersion: "ai-resource/draft",
+		Kind:       "Ruleset",
+		Spec: &format.Ruleset{
+			Metadata: format.Metadata{ID: "style"},
+			Spec: format.RulesetSpec{
+				Rules: map[string]format.RuleItem{
+					"api": {Name: "API", Enforcement: "should", Body: format.Body{String: strPtr("a")},
+						Scope: []format.ScopeEntry{{Directories: []string{"packages/api"}, Files: []string{"packages/api/src/*.go"}}}},
+					"both": {Name: "Both", Enforcement: "should", Body: format.Body{String: strPtr("b")},
+						Scope: []format.ScopeEntry{{Files: []string{"packages/api/**", "packages/web/**"}}}},
+					"everywhere": {Name: "Everywhere", Enforcement: "should", Body: format.Body{String: strPtr("c")},
+						Scope: []format.ScopeEntry{{Languages: []string{"go"}}}},
+					"unscoped": {Name: "Unscoped", Enforcement: "must", Body: format.Body{String: strPtr("d")}},
+				},
+			},
+		},
+	}
+	results, err := configured.Compile(resource)
+	if err != nil {
+		t.Fatalf("Compile() error = %v", err)
+	}
+	var paths []string
+	for _, result := range results {
+		paths = append(paths, result.Path)
+	}
+	want := []string{
+		"packages/api/.cursor/rules/style_api.mdc",
+		"packages/.cursor/rules/style_both.mdc",
+		".cursor/rules/style_everywhere.mdc",
+		".cursor/rules/style_unscoped.mdc",
+	}
+	if !reflect.DeepEqual(paths, want) {
+		t.Errorf("paths = %v, want %v", paths, want)
+	}
+
+	command := &compiler.Resource{
+		APIVersion: "ai-resource/draft",
+		Kind:       "Prompt",
+		Spec: &format.Prompt{
+			Metadata: format.Metadata{ID: "review"},
+			Spec:     format.PromptSpec{Body: format.Body{String: strPtr("Review.")}},
+		},
+	}
+	results, err = configured.Compile(command)
+	if err != nil {
+		t.Fatalf("Compile() error = %v", err)
+	}
+	if results[0].Path != ".cursor/commands/review.md" {
+		t.Errorf("prompt path = %q, want .cursor/commands/review.md", results[0].Path)
 	}
 }
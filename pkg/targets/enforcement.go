@@ -0,0 +1,20 @@
+package targets
+
+import "github.com/jomadu/ai-resource-compiler-go/pkg/compiler"
+
+// activation returns when tools load rules of enforcement, or "" for a
+// level that is not registered.
+func activation(enforcement string) compiler.Activation {
+	level, _ := compiler.LookupEnforcement(enforcement)
+	return level.Activation
+}
+
+// defaultMode returns a target's default mode, such as a Cursor rule type,
+// for rules of enforcement: the one defaults gives the level, or else the
+// one activations gives its activation.
+func defaultMode(defaults map[string]string, activations map[compiler.Activation]string, enforcement string) string {
+	if mode, ok := defaults[enforcement]; ok {
+		return mode
+	}
+	return activations[activation(enforcement)]
+}
@@ -0,0 +1,52 @@
+package targets
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jomadu/ai-resource-compiler-go/internal/format"
+	"github.com/jomadu/ai-resource-compiler-go/pkg/compiler"
+)
+
+func TestRegisteredEnforcementLevel(t *testing.T) {
+	if err := compiler.RegisterEnforcementLevel(compiler.EnforcementLevel{Name: "must-not", Rank: 3, Activation: compiler.ActivationAlways}); err != nil {
+		t.Fatalf("RegisterEnforcementLevel() error = %v", err)
+	}
+	resource := &compiler.Resource{
+		APIVersion: "ai-resource/draft",
+		Kind:       "Rule",
+		Spec: &format.Rule{
+			Metadata: format.Metadata{ID: "noSecrets", Name: "No Secrets"},
+			Spec: format.RuleSpec{
+				Enforcement: "must-not",
+				Scope:       []format.ScopeEntry{{Files: []string{"**/*.go"}}},
+				Body:        format.Body{String: strPtr("Never commit secrets.")},
+			},
+		},
+	}
+
+	tests := []struct {
+		name   string
+		target compiler.TargetCompiler
+		want   string
+	}{
+		{"cursor", &CursorCompiler{}, "alwaysApply: true"},
+		{"cursor rule types", &CursorCompiler{RuleTypes: map[string]string{}}, "alwaysApply: true"},
+		{"kiro inclusion", &KiroCompiler{Inclusion: map[string]string{}}, "inclusion: always"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results, err := tt.target.Compile(resource)
+			if err != nil {
+				t.Fatalf("Compile() error = %v", err)
+			}
+			if !strings.Contains(results[0].Content, tt.want) {
+				t.Errorf("Content missing %q:\n%s", tt.want, results[0].Content)
+			}
+		})
+	}
+
+	if _, err := (&KiroCompiler{}).Configure(map[string]any{"inclusion": map[string]any{"must-not": "manual"}}); err != nil {
+		t.Errorf("Configure() error = %v for a registered level", err)
+	}
+}
@@ -0,0 +1,132 @@
+package targets
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/jomadu/ai-resource-compiler-go/internal/format"
+	"github.com/jomadu/ai-resource-compiler-go/pkg/compiler"
+)
+
+// explainItem is one rule, prompt, command, or context of a resource,
+// flattened so each target can describe how it maps the item.
+type explainItem struct {
+	rule         bool
+	command      bool
+	context      bool
+	collection   string // ruleset or promptset ID, "" for standalone resources
+	id           string
+	name         string
+	description  string
+	enforcement  string
+	scope        []format.ScopeEntry
+	allowedTools []string
+	arguments    string
+	mode         string
+	model        string
+	tools        []string
+
+	commandArguments []format.CommandArgument
+}
+
+// ref returns the item's ID, prefixed with its collection's ID.
+func (i explainItem) ref() string {
+	if i.collection == "" {
+		return i.id
+	}
+	return i.collection + "/" + i.id
+}
+
+// path returns the file name the item compiles to with the given extension.
+func (i explainItem) path(extension string) string {
+	if i.collection == "" {
+		return format.BuildStandalonePath(i.id, extension)
+	}
+	return format.BuildCollectionPath(i.collection, i.id, extension)
+}
+
+// explain compiles resource with tc, so invalid resources fail as they would
+// when compiling, then builds one explanation per rule or prompt with
+// describe.
+func explain(tc compiler.TargetCompiler, resource *compiler.Resource, describe func(explainItem) compiler.Explanation) ([]compiler.Explanation, error) {
+	if _, err := tc.Compile(resource); err != nil {
+		return nil, err
+	}
+
+	var items []explainItem
+	switch spec := resource.Spec.(type) {
+	case *format.Rule:
+		items = append(items, explainItem{rule: true, id: spec.Metadata.ID, name: spec.Metadata.Name,
+			description: spec.Metadata.Description, enforcement: spec.Spec.Enforcement, scope: spec.Spec.Scope})
+	case *format.Ruleset:
+		for id, item := range spec.Spec.Rules {
+			items = append(items, explainItem{rule: true, collection: spec.Metadata.ID, id: id, name: item.Name,
+				description: item.Description, enforcement: item.Enforcement, scope: item.Scope})
+		}
+	case *format.Prompt:
+		items = append(items, explainItem{id: spec.Metadata.ID, name: spec.Metadata.Name,
+			description: spec.Metadata.Description, allowedTools: spec.Spec.AllowedTools, arguments: spec.Spec.Arguments,
+			mode: spec.Spec.Mode, model: spec.Spec.Model, tools: spec.Spec.Tools})
+	case *format.Promptset:
+		for id, item := range spec.Spec.Prompts {
+			items = append(items, explainItem{collection: spec.Metadata.ID, id: id, name: item.Name,
+				description: item.Description, allowedTools: item.AllowedTools, arguments: item.Arguments,
+				mode: item.Mode, model: item.Model, tools: item.Tools})
+		}
+	case *format.Command:
+		items = append(items, explainItem{command: true, id: spec.Metadata.ID, name: spec.Metadata.Name,
+			description: spec.Metadata.Description, allowedTools: spec.Spec.AllowedTools,
+			arguments: argumentHint(spec.Spec.Arguments), commandArguments: spec.Spec.Arguments})
+	case *format.Context:
+		items = append(items, explainItem{context: true, id: spec.Metadata.ID, name: spec.Metadata.Name,
+			description: spec.Metadata.Description})
+	}
+	sort.Slice(items, func(a, b int) bool { return items[a].ref() < items[b].ref() })
+
+	var explanations []compiler.Explanation
+	for _, item := range items {
+		explanation := describe(item)
+		explanation.Item = item.ref()
+		explanations = append(explanations, explanation)
+	}
+	return explanations, nil
+}
+
+// contentMappings describes the rule content every target emits after its
+// frontmatter, or the body of a prompt.
+func (o ContentOptions) contentMappings(item explainItem) []compiler.Mapping {
+	if !item.rule {
+		return []compiler.Mapping{{Field: "body", Output: "file content, fragments resolved"}}
+	}
+
+	var mappings []compiler.Mapping
+	if o.Lean {
+		mappings = append(mappings, compiler.Mapping{Field: "metadata", Output: "omitted (lean)"})
+	} else {
+		mappings = append(mappings, compiler.Mapping{Field: "metadata", Output: "YAML metadata block with ruleset and rule context"})
+	}
+	mappings = append(mappings,
+		compiler.Mapping{
+			Field:  fmt.Sprintf("name, enforcement: %s", item.enforcement),
+			Output: fmt.Sprintf("heading %q", format.EnforcementHeader(item.name, item.enforcement)),
+		},
+		compiler.Mapping{Field: "body", Output: "content below the heading, fragments resolved"})
+
+	switch o.EmbedSource {
+	case EmbedSourcePath:
+		mappings = append(mappings, compiler.Mapping{Field: "source", Output: "trailing <!-- source: file#" + item.ref() + " --> comment"})
+	case EmbedSourceYAML:
+		mappings = append(mappings, compiler.Mapping{Field: "source", Output: "source YAML in a trailing <details> section"})
+	}
+	return mappings
+}
+
+// scopeField describes a rule's scope for a mapping.
+func scopeField(scope []format.ScopeEntry) string {
+	files := extractScopeFiles(scope)
+	if len(files) == 0 {
+		return "scope: (none)"
+	}
+	return "scope: " + strings.Join(files, ", ")
+}
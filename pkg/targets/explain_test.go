@@ -0,0 +1,165 @@
+package targets
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jomadu/ai-resource-compiler-go/internal/format"
+	"github.com/jomadu/ai-resource-compiler-go/pkg/compiler"
+)
+
+func explainRuleset() *compiler.Resource {
+	return &compiler.Resource{
+		APIVersion: "ai-resource/draft",
+		Kind:       "Ruleset",
+		Spec: &format.Ruleset{
+			Metadata: format.Metadata{ID: "cleanCode"},
+			Spec: format.RulesetSpec{
+				Rules: map[string]format.RuleItem{
+					"names": {
+						Name:        "Meaningful Names",
+						Enforcement: "should",
+						Scope:       []format.ScopeEntry{{Files: []string{"**/*.go"}}},
+						Body:        format.Body{String: strPtr("Use names.")},
+					},
+					"tests": {
+						Name:        "Tests",
+						Enforcement: "may",
+						Body:        format.Body{String: strPtr("Write tests.")},
+					},
+				},
+			},
+		},
+	}
+}
+
+// outputs returns the mapping outputs of an explanation keyed by field.
+func outputs(e compiler.Explanation) map[string]string {
+	m := make(map[string]string)
+	for _, mapping := range e.Mappings {
+		m[mapping.Field] = mapping.Output
+	}
+	return m
+}
+
+func TestExplainCursorRuleTypes(t *testing.T) {
+	tc, err := (&CursorCompiler{}).Configure(map[string]any{"ruleTypes": true})
+	if err != nil {
+		t.Fatalf("Configure() error = %v", err)
+	}
+
+	explanations, err := tc.(compiler.ExplainingTarget).Explain(explainRuleset())
+	if err != nil {
+		t.Fatalf("Explain() error = %v", err)
+	}
+	if len(explanations) != 2 {
+		t.Fatalf("Explain() returned %d explanations, want 2", len(explanations))
+	}
+
+	names := explanations[0]
+	if names.Path != "rules/cleanCode_names.mdc" || names.Item != "cleanCode/names" {
+		t.Errorf("explanation = %s (%s), want rules/cleanCode_names.mdc (cleanCode/names)", names.Path, names.Item)
+	}
+	got := outputs(names)
+	if got["enforcement: should"] != "alwaysApply: false (rule type auto)" {
+		t.Errorf("enforcement output = %q", got["enforcement: should"])
+	}
+	if got["scope: **/*.go"] != "globs: **/*.go" {
+		t.Errorf("scope output = %q", got["scope: **/*.go"])
+	}
+
+	got = outputs(explanations[1])
+	if got["enforcement: may"] != "alwaysApply: false (rule type agent)" {
+		t.Errorf("enforcement output = %q", got["enforcement: may"])
+	}
+}
+
+func TestExplainKiroInclusion(t *testing.T) {
+	k := &KiroCompiler{Inclusion: map[string]string{}}
+	explanations, err := k.Explain(explainRuleset())
+	if err != nil {
+		t.Fatalf("Explain() error = %v", err)
+	}
+
+	got := outputs(explanations[0])
+	if got["enforcement: should"] != "inclusion: fileMatch" {
+		t.Errorf("enforcement output = %q", got["enforcement: should"])
+	}
+	if got["scope: **/*.go"] != "fileMatchPattern: **/*.go" {
+		t.Errorf("scope output = %q", got["scope: **/*.go"])
+	}
+	if got := outputs(explanations[1])["enforcement: may"]; got != "inclusion: manual" {
+		t.Errorf("enforcement output = %q, want inclusion: manual", got)
+	}
+}
+
+func TestExplainClaudePrompt(t *testing.T) {
+	resource := &compiler.Resource{
+		APIVersion: "ai-resource/draft",
+		Kind:       "Prompt",
+		Spec: &format.Prompt{
+			Metadata: format.Metadata{ID: "commit"},
+			Spec: format.PromptSpec{
+				AllowedTools: []string{"Bash(git commit:*)"},
+				Arguments:    "[message]",
+				Body:         format.Body{String: strPtr("Commit.")},
+			},
+		},
+	}
+
+	explanations, err := (&ClaudeCompiler{}).Explain(resource)
+	if err != nil {
+		t.Fatalf("Explain() error = %v", err)
+	}
+	if explanations[0].Path != "skills/commit/SKILL.md" {
+		t.Errorf("Path = %q, want skills/commit/SKILL.md", explanations[0].Path)
+	}
+	got := outputs(explanations[0])
+	if got["allowedTools: Bash(git commit:*)"] != "allowed-tools: Bash(git commit:*)" {
+		t.Errorf("mappings = %v, want allowed-tools", explanations[0].Mappings)
+	}
+	if got["arguments: [message]"] != "argument-hint: [message]" {
+		t.Errorf("mappings = %v, want argument-hint", explanations[0].Mappings)
+	}
+}
+
+func TestExplainMatchesCompiledPaths(t *testing.T) {
+	targets := []compiler.ExplainingTarget{
+		&MarkdownCompiler{}, &KiroCompiler{}, &CursorCompiler{}, &ClaudeCompiler{}, &CopilotCompiler{},
+	}
+	for _, tc := range targets {
+		t.Run(tc.Name(), func(t *testing.T) {
+			results, err := tc.Compile(explainRuleset())
+			if err != nil {
+				t.Fatalf("Compile() error = %v", err)
+			}
+			explanations, err := tc.Explain(explainRuleset())
+			if err != nil {
+				t.Fatalf("Explain() error = %v", err)
+			}
+
+			paths := make(map[string]bool)
+			for _, r := range results {
+				paths[r.Path] = true
+			}
+			for _, e := range explanations {
+				if !paths[e.Path] {
+					t.Errorf("explained path %s not in compiled results", e.Path)
+				}
+			}
+			if len(explanations) != len(results) {
+				t.Errorf("Explain() returned %d explanations for %d results", len(explanations), len(results))
+			}
+		})
+	}
+}
+
+func TestExplainInvalidResource(t *testing.T) {
+	resource := explainRuleset()
+	resource.Spec.(*format.Ruleset).Metadata.ID = "bad id"
+
+	_, err := (&MarkdownCompiler{}).Explain(resource)
+	if err == nil || !strings.Contains(err.Error(), "invalid character") {
+		t.Errorf("Explain() error = %v, want ID validation error", err)
+	}
+}
@@ -0,0 +1,61 @@
+package targets
+
+import (
+	"github.com/jomadu/ai-resource-compiler-go/internal/format"
+	"gopkg.in/yaml.v3"
+)
+
+// Frontmatter is encoded from structs, or yaml.Node mappings when keys are
+// chosen at run time, so keys appear in a fixed, documented order. Maps are
+// not used: their keys would be sorted by the encoder, not chosen by us.
+// Glob patterns use format.Globs so they are always quoted.
+
+// mdcFrontmatter is the frontmatter of a Cursor rule.
+type mdcFrontmatter struct {
+	Description string       `yaml:"description"`
+	Globs       format.Globs `yaml:"globs"`
+	AlwaysApply bool         `yaml:"alwaysApply"`
+}
+
+// instructionsFrontmatter is the frontmatter of a Copilot instructions
+// file. ApplyTo is a single quoted string of comma-separated globs; see
+// copilotApplyTo.
+type instructionsFrontmatter struct {
+	Description  string     `yaml:"description"`
+	ApplyTo      *yaml.Node `yaml:"applyTo,omitempty"`
+	ExcludeAgent string     `yaml:"excludeAgent,omitempty"`
+}
+
+// pathsFrontmatter is the frontmatter of a scoped Claude rule.
+type pathsFrontmatter struct {
+	Paths format.Globs `yaml:"paths"`
+}
+
+// skillFrontmatter is the frontmatter of a Claude skill.
+type skillFrontmatter struct {
+	Name         string `yaml:"name"`
+	Description  string `yaml:"description"`
+	AllowedTools string `yaml:"allowed-tools,omitempty"`
+	ArgumentHint string `yaml:"argument-hint,omitempty"`
+}
+
+// commandFrontmatter is the frontmatter of a Claude custom command.
+type commandFrontmatter struct {
+	Description  string `yaml:"description,omitempty"`
+	ArgumentHint string `yaml:"argument-hint,omitempty"`
+	AllowedTools string `yaml:"allowed-tools,omitempty"`
+}
+
+// promptFileFrontmatter is the frontmatter of a Copilot prompt file.
+type promptFileFrontmatter struct {
+	Description string   `yaml:"description,omitempty"`
+	Mode        string   `yaml:"mode,omitempty"`
+	Model       string   `yaml:"model,omitempty"`
+	Tools       []string `yaml:"tools,omitempty"`
+}
+
+// encodeFrontmatter returns v as YAML between "---" lines, without a
+// trailing newline.
+func encodeFrontmatter(v any) string {
+	return "---\n" + format.EncodeYAML(v) + "---"
+}
@@ -0,0 +1,62 @@
+package targets
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jomadu/ai-resource-compiler-go/internal/format"
+	"github.com/jomadu/ai-resource-compiler-go/pkg/compiler"
+	"gopkg.in/yaml.v3"
+)
+
+// TestFrontmatterBraceGlobs checks that every target's frontmatter parses
+// back to the scope globs, including ones with brace expansion. Copilot's
+// applyTo is a single comma-joined string.
+func TestFrontmatterBraceGlobs(t *testing.T) {
+	globs := []string{"{src,lib}/**/*.{ts,tsx}", "*.go"}
+	resource := &compiler.Resource{
+		APIVersion: "ai-resource/draft",
+		Kind:       "Rule",
+		Spec: &format.Rule{
+			Metadata: format.Metadata{ID: "braces", Name: "Braces", Description: "{not: a mapping}"},
+			Spec: format.RuleSpec{
+				Enforcement: "should",
+				Scope:       []format.ScopeEntry{{Files: globs}},
+				Body:        format.Body{String: strPtr("Body")},
+			},
+		},
+	}
+	tests := []struct {
+		compiler compiler.TargetCompiler
+		key      string
+	}{
+		{&CursorCompiler{}, "globs"},
+		{&CopilotCompiler{}, "applyTo"},
+		{&ClaudeCompiler{}, "paths"},
+		{&KiroCompiler{}, "fileMatchPattern"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.compiler.Name(), func(t *testing.T) {
+			results, err := tt.compiler.Compile(resource)
+			if err != nil {
+				t.Fatalf("Compile() error = %v", err)
+			}
+			frontmatter := strings.SplitN(strings.TrimPrefix(results[0].Content, "---\n"), "---\n", 2)[0]
+
+			var parsed map[string]any
+			if err := yaml.Unmarshal([]byte(frontmatter), &parsed); err != nil {
+				t.Fatalf("frontmatter is not valid YAML: %v\n%s", err, frontmatter)
+			}
+			if tt.key == "applyTo" {
+				if want := strings.Join(globs, ","); parsed[tt.key] != want {
+					t.Errorf("%s = %v, want %q", tt.key, parsed[tt.key], want)
+				}
+			} else if got, ok := parsed[tt.key].([]any); !ok || len(got) != len(globs) || got[0] != globs[0] || got[1] != globs[1] {
+				t.Errorf("%s = %v, want %v", tt.key, parsed[tt.key], globs)
+			}
+			if strings.Contains(frontmatter, "description") && parsed["description"] != "{not: a mapping}" {
+				t.Errorf("description = %v", parsed["description"])
+			}
+		})
+	}
+}
@@ -0,0 +1,319 @@
+package targets
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jomadu/ai-resource-compiler-go/internal/format"
+	"github.com/jomadu/ai-resource-compiler-go/pkg/compiler"
+)
+
+// Gemini CLI output paths: rules are merged into the GEMINI.md context file
+// and prompts become custom commands, both relative to the project root.
+const (
+	geminiContextFile = "GEMINI.md"
+	geminiCommandsDir = ".gemini/commands/"
+)
+
+// GeminiCompiler compiles rules into sections of a single GEMINI.md and
+// prompts into Gemini CLI custom commands. Rules have no per-file scoping in
+// GEMINI.md, so a rule's scope is stated in its section instead. Sections
+// never carry the metadata block, which would repeat for every rule; the
+// lean option is accepted so -lean applies to every target alike.
+type GeminiCompiler struct {
+	ContentOptions
+}
+
+func init() {
+	compiler.RegisterDefaultTarget(compiler.TargetGemini, &GeminiCompiler{})
+}
+
+func (g *GeminiCompiler) Name() string {
+	return "gemini"
+}
+
+func (g *GeminiCompiler) SupportedVersions() []string {
+	return []string{"ai-resource/draft"}
+}
+
+// DefaultOutputDir returns the project root, where Gemini CLI reads GEMINI.md
+// and .gemini/commands.
+func (g *GeminiCompiler) DefaultOutputDir() string {
+	return "."
+}
+
+// AddBanner adds banner as a comment; see commentBanner.
+func (g *GeminiCompiler) AddBanner(path, content, banner string) string {
+	return commentBanner(path, content, banner)
+}
+
+// Configure accepts the content options.
+func (g *GeminiCompiler) Configure(options map[string]any) (compiler.TargetCompiler, error) {
+	if err := checkOptions(options, contentOptionNames...); err != nil {
+		return nil, err
+	}
+	configured := *g
+	if err := configured.ContentOptions.configure(options); err != nil {
+		return nil, err
+	}
+	return &configured, nil
+}
+
+func (g *GeminiCompiler) Compile(resource *compiler.Resource) ([]compiler.CompilationResult, error) {
+	if resource.APIVersion != "ai-resource/draft" {
+		return nil, fmt.Errorf("%w: %s for gemini", compiler.ErrUnsupportedVersion, resource.APIVersion)
+	}
+
+	switch resource.Kind {
+	case "Rule":
+		return g.compileRule(resource)
+	case "Ruleset":
+		return g.compileRuleset(resource)
+	case "Prompt":
+		return g.compilePrompt(resource)
+	case "Promptset":
+		return g.compilePromptset(resource)
+	case "Command":
+		return g.compileCommand(resource)
+	case "Context":
+		return compileContext(resource, geminiContextFile)
+	default:
+		return nil, fmt.Errorf("%w: %s", compiler.ErrUnsupportedKind, resource.Kind)
+	}
+}
+
+// Merge joins the GEMINI.md sections of all results into one document.
+// Command files cannot be merged, so two different commands with the same
+// path are an error.
+func (g *GeminiCompiler) Merge(results []compiler.CompilationResult) ([]compiler.CompilationResult, error) {
+	var merged []compiler.CompilationResult
+	index := make(map[string]int)
+	for _, result := range results {
+		i, seen := index[result.Path]
+		switch {
+		case !seen:
+			index[result.Path] = len(merged)
+			merged = append(merged, result)
+		case strings.HasSuffix(result.Path, geminiContextFile):
+			merged[i].Content += "\n\n" + result.Content
+		case merged[i].Content != result.Content:
+			return nil, fmt.Errorf("gemini: %s is produced by more than one prompt", result.Path)
+		}
+	}
+	return merged, nil
+}
+
+// Explain describes how resource compiles to GEMINI.md sections and commands.
+func (g *GeminiCompiler) Explain(resource *compiler.Resource) ([]compiler.Explanation, error) {
+	return explain(g, resource, func(item explainItem) compiler.Explanation {
+		if item.context {
+			return contextExplanation(item, geminiContextFile)
+		}
+		if item.command {
+			mappings := []compiler.Mapping{{Field: "description", Output: "description"}}
+			mappings = append(mappings, argumentMapping(item.commandArguments, geminiArgument(len(item.commandArguments)))...)
+			if len(item.commandArguments) > 1 {
+				mappings = append(mappings, compiler.Mapping{Field: "arguments", Output: "\"Arguments\" section after the prompt"})
+			}
+			return compiler.Explanation{Path: geminiCommandsDir + item.path(".toml"), Mappings: append(mappings,
+				compiler.Mapping{Field: "body", Output: "prompt, fragments resolved"})}
+		}
+		if !item.rule {
+			return compiler.Explanation{Path: geminiCommandsDir + item.path(".toml"), Mappings: []compiler.Mapping{
+				{Field: "name", Output: "description"},
+				{Field: "body", Output: "prompt, fragments resolved"},
+			}}
+		}
+
+		mappings := []compiler.Mapping{{Field: "(file)", Output: "section of the shared " + geminiContextFile}}
+		if files := extractScopeFiles(item.scope); len(files) > 0 {
+			mappings = append(mappings, compiler.Mapping{Field: scopeField(item.scope), Output: "\"Applies to files matching\" line"})
+		}
+		mappings = append(mappings,
+			compiler.Mapping{
+				Field:  fmt.Sprintf("name, enforcement: %s", item.enforcement),
+				Output: fmt.Sprintf("heading %q", format.EnforcementHeader(item.name, item.enforcement)),
+			},
+			compiler.Mapping{Field: "body", Output: "content below the heading, fragments resolved"})
+		return compiler.Explanation{Path: geminiContextFile, Mappings: mappings}
+	})
+}
+
+func (g *GeminiCompiler) compileRule(resource *compiler.Resource) ([]compiler.CompilationResult, error) {
+	rule := resource.Spec.(*format.Rule)
+
+	if err := format.ValidateID(rule.Metadata.ID); err != nil {
+		return nil, err
+	}
+	if err := format.ValidateRuleName(rule.Metadata.Name); err != nil {
+		return nil, err
+	}
+
+	content := g.section(rule.Metadata.Name, rule.Spec.Enforcement, rule.Spec.Scope,
+		format.ResolveBody(rule.Spec.Body, rule.Spec.Fragments))
+	resourceValue := compiler.Resource{APIVersion: "ai-resource/draft", Kind: "Rule", Spec: rule}
+	content += g.sourceSection(resource.Source, rule.Metadata.ID, resourceValue)
+
+	return []compiler.CompilationResult{{Path: geminiContextFile, Content: content}}, nil
+}
+
+func (g *GeminiCompiler) compileRuleset(resource *compiler.Resource) ([]compiler.CompilationResult, error) {
+	ruleset := resource.Spec.(*format.Ruleset)
+
+	if err := format.ValidateID(ruleset.Metadata.ID); err != nil {
+		return nil, err
+	}
+
+	var results []compiler.CompilationResult
+	for _, ruleID := range ruleset.Spec.RuleIDs() {
+		if err := format.ValidateID(ruleID); err != nil {
+			return nil, err
+		}
+		ruleSpec := ruleset.Spec.Rules[ruleID]
+		if err := format.ValidateRuleName(ruleSpec.Name); err != nil {
+			return nil, err
+		}
+
+		content := g.section(ruleSpec.Name, ruleSpec.Enforcement, ruleSpec.Scope,
+			format.ResolveBody(ruleSpec.Body, ruleset.Spec.Fragments))
+		item := map[string]map[string]format.RuleItem{"rules": {ruleID: ruleSpec}}
+		content += g.sourceSection(resource.Source, ruleset.Metadata.ID+"/"+ruleID, item)
+
+		results = append(results, compiler.CompilationResult{Path: geminiContextFile, Content: content})
+	}
+
+	return results, nil
+}
+
+func (g *GeminiCompiler) compilePrompt(resource *compiler.Resource) ([]compiler.CompilationResult, error) {
+	prompt := resource.Spec.(*format.Prompt)
+
+	if err := format.ValidateID(prompt.Metadata.ID); err != nil {
+		return nil, err
+	}
+
+	description := prompt.Metadata.Description
+	if description == "" {
+		description = prompt.Metadata.Name
+	}
+	path := geminiCommandsDir + format.BuildStandalonePath(prompt.Metadata.ID, ".toml")
+	content := geminiCommand(description, format.ResolveBody(prompt.Spec.Body, prompt.Spec.Fragments))
+
+	return []compiler.CompilationResult{{Path: path, Content: content}}, nil
+}
+
+func (g *GeminiCompiler) compilePromptset(resource *compiler.Resource) ([]compiler.CompilationResult, error) {
+	promptset := resource.Spec.(*format.Promptset)
+
+	if err := format.ValidateID(promptset.Metadata.ID); err != nil {
+		return nil, err
+	}
+
+	var results []compiler.CompilationResult
+	for _, promptID := range promptset.Spec.PromptIDs() {
+		if err := format.ValidateID(promptID); err != nil {
+			return nil, err
+		}
+
+		promptSpec := promptset.Spec.Prompts[promptID]
+		description := promptSpec.Description
+		if description == "" {
+			description = promptSpec.Name
+		}
+		path := geminiCommandsDir + format.BuildCollectionPath(promptset.Metadata.ID, promptID, ".toml")
+		content := geminiCommand(description, format.ResolveBody(promptSpec.Body, promptset.Spec.Fragments))
+
+		results = append(results, compiler.CompilationResult{Path: path, Content: content})
+	}
+
+	return results, nil
+}
+
+// compileCommand compiles a command to a Gemini CLI custom command. A
+// single argument becomes {{args}}; Gemini appends the arguments to prompts
+// without {{args}}, so several are written as <name> and listed after the
+// prompt.
+func (g *GeminiCompiler) compileCommand(resource *compiler.Resource) ([]compiler.CompilationResult, error) {
+	command := resource.Spec.(*format.Command)
+
+	if err := format.ValidateID(command.Metadata.ID); err != nil {
+		return nil, err
+	}
+
+	args := command.Spec.Arguments
+	prompt := commandBody(command, geminiArgument(len(args)))
+	if len(args) > 1 {
+		prompt += argumentsSection(args)
+	}
+	path := geminiCommandsDir + format.BuildStandalonePath(command.Metadata.ID, ".toml")
+	content := geminiCommand(commandDescription(command), prompt)
+
+	return []compiler.CompilationResult{{Path: path, Content: content}}, nil
+}
+
+// geminiArgument returns the placeholder for the arguments of a command
+// that takes count of them.
+func geminiArgument(count int) func(int, format.CommandArgument) string {
+	if count == 1 {
+		return func(int, format.CommandArgument) string { return "{{args}}" }
+	}
+	return namedArgument
+}
+
+// section returns a rule's GEMINI.md section: its enforcement heading, the
+// files it applies to, if scoped, and its body.
+func (g *GeminiCompiler) section(name, enforcement string, scope []format.ScopeEntry, body string) string {
+	var sb strings.Builder
+	sb.WriteString(format.EnforcementHeader(name, enforcement))
+	sb.WriteString("\n\n")
+	if applies := appliesTo(scope); applies != "" {
+		sb.WriteString(applies + "\n\n")
+	}
+	sb.WriteString(body)
+	return sb.String()
+}
+
+// geminiCommand returns a Gemini CLI custom command file.
+func geminiCommand(description, prompt string) string {
+	var sb strings.Builder
+	if description != "" {
+		sb.WriteString("description = " + tomlString(description) + "\n")
+	}
+	sb.WriteString("prompt = " + tomlMultilineString(prompt) + "\n")
+	return sb.String()
+}
+
+// tomlString quotes s as a TOML basic string.
+func tomlString(s string) string {
+	var sb strings.Builder
+	sb.WriteByte('"')
+	for _, r := range s {
+		switch {
+		case r == '"' || r == '\\':
+			sb.WriteByte('\\')
+			sb.WriteRune(r)
+		case r == '\n':
+			sb.WriteString(`\n`)
+		case r == '\t':
+			sb.WriteString(`\t`)
+		case r < 0x20 || r == 0x7f:
+			fmt.Fprintf(&sb, `\u%04X`, r)
+		default:
+			sb.WriteRune(r)
+		}
+	}
+	sb.WriteByte('"')
+	return sb.String()
+}
+
+// tomlMultilineString quotes s as a TOML multi-line literal string, which
+// needs no escaping, unless s contains the delimiter of three single quotes
+// or control characters a literal string cannot hold.
+func tomlMultilineString(s string) string {
+	if strings.Contains(s, "'''") || strings.ContainsFunc(s, func(r rune) bool {
+		return (r < 0x20 && r != '\n' && r != '\t') || r == 0x7f
+	}) {
+		return tomlString(s)
+	}
+	return "'''\n" + s + "'''"
+}
@@ -0,0 +1,135 @@
+package targets
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/jomadu/ai-resource-compiler-go/internal/format"
+	"github.com/jomadu/ai-resource-compiler-go/pkg/compiler"
+	"gopkg.in/yaml.v3"
+)
+
+func TestGeminiCompiler_Name(t *testing.T) {
+	g := &GeminiCompiler{}
+	if got := g.Name(); got != "gemini" {
+		t.Errorf("Name() = %v, want gemini", got)
+	}
+}
+
+func TestGeminiCompiler_CompileRule(t *testing.T) {
+	g := &GeminiCompiler{}
+	resource := &compiler.Resource{
+		APIVersion: "ai-resource/draft",
+		Kind:       "Rule",
+		Spec: &format.Rule{
+			Metadata: format.Metadata{ID: "testRule", Name: "Test Rule"},
+			Spec: format.RuleSpec{
+				Enforcement: "must",
+				Scope:       []format.ScopeEntry{{Files: []string{"**/*.ts"}}},
+				Body:        format.Body{String: strPtr("Rule body content")},
+			},
+		},
+	}
+
+	results, err := g.Compile(resource)
+	if err != nil {
+		t.Fatalf("Compile() error = %v", err)
+	}
+	want := "# Test Rule (MUST)\n\nApplies to files matching: `**/*.ts`\n\nRule body content"
+	if len(results) != 1 || results[0].Path != "GEMINI.md" || results[0].Content != want {
+		t.Errorf("Compile() = %+v, want GEMINI.md with %q", results, want)
+	}
+}
+
+func TestGeminiCompiler_Merge(t *testing.T) {
+	g := &GeminiCompiler{}
+	results := []compiler.CompilationResult{
+		{Path: "GEMINI.md", Content: "# A"},
+		{Path: ".gemini/commands/a.toml", Content: "prompt = 'a'"},
+		{Path: "GEMINI.md", Content: "# B"},
+		{Path: ".gemini/commands/a.toml", Content: "prompt = 'a'"},
+		{Path: "GEMINI.md", Content: "# C"},
+	}
+
+	merged, err := g.Merge(results)
+	if err != nil {
+		t.Fatalf("Merge() error = %v", err)
+	}
+	want := []compiler.CompilationResult{
+		{Path: "GEMINI.md", Content: "# A\n\n# B\n\n# C"},
+		{Path: ".gemini/commands/a.toml", Content: "prompt = 'a'"},
+	}
+	if !reflect.DeepEqual(merged, want) {
+		t.Errorf("Merge() = %+v, want %+v", merged, want)
+	}
+
+	// Merging in steps, as for several resources, gives the same document.
+	first, _ := g.Merge(results[:2])
+	stepwise, err := g.Merge(append(first, results[2:]...))
+	if err != nil || !reflect.DeepEqual(stepwise, want) {
+		t.Errorf("stepwise Merge() = %+v, %v, want %+v", stepwise, err, want)
+	}
+
+	conflict := []compiler.CompilationResult{
+		{Path: ".gemini/commands/a.toml", Content: "prompt = 'a'"},
+		{Path: ".gemini/commands/a.toml", Content: "prompt = 'b'"},
+	}
+	if _, err := g.Merge(conflict); err == nil || !strings.Contains(err.Error(), "a.toml") {
+		t.Errorf("Merge() error = %v, want conflicting command error", err)
+	}
+}
+
+func TestGeminiCommand(t *testing.T) {
+	tests := []struct {
+		name, description, prompt, want string
+	}{
+		{"literal", "Review", "Review the diff.\nBe brief.",
+			"description = \"Review\"\nprompt = '''\nReview the diff.\nBe brief.'''\n"},
+		{"escaped", `Say "hi"`, "Use ''' quotes",
+			"description = \"Say \\\"hi\\\"\"\nprompt = \"Use ''' quotes\"\n"},
+		{"no description", "", "Go.", "prompt = '''\nGo.'''\n"},
+	}
+	for _, tt := range tests {
+		if got := geminiCommand(tt.description, tt.prompt); got != tt.want {
+			t.Errorf("%s: geminiCommand() = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+
+	if got := tomlString("a\\b\tc\x01"); got != `"a\\b\tc\u0001"` {
+		t.Errorf("tomlString() = %s", got)
+	}
+}
+
+func TestGeminiCompiler_RulesetOrder(t *testing.T) {
+	var resource compiler.Resource
+	content := `apiVersion: ai-resource/draft
+kind: Ruleset
+metadata:
+  id: style
+spec:
+  rules:
+    zeta:
+      enforcement: must
+      body: Zeta body.
+    alpha:
+      enforcement: must
+      body: Alpha body.
+`
+	if err := yaml.Unmarshal([]byte(content), &resource); err != nil {
+		t.Fatal(err)
+	}
+	g := &GeminiCompiler{}
+	results, err := g.Compile(&resource)
+	if err != nil {
+		t.Fatalf("Compile() error = %v", err)
+	}
+	merged, err := g.Merge(results)
+	if err != nil || len(merged) != 1 {
+		t.Fatalf("Merge() = %+v, %v, want GEMINI.md", merged, err)
+	}
+	doc := merged[0].Content
+	if zeta, alpha := strings.Index(doc, "Zeta body."), strings.Index(doc, "Alpha body."); zeta < 0 || alpha < zeta {
+		t.Errorf("GEMINI.md lists rules out of source order:\n%s", doc)
+	}
+}
@@ -0,0 +1,89 @@
+package targets
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jomadu/ai-resource-compiler-go/pkg/compiler"
+	"github.com/jomadu/ai-resource-compiler-go/pkg/loader"
+)
+
+var update = flag.Bool("update", false, "rewrite golden files in testdata/golden")
+
+// TestGolden compiles the resources in testdata/resources with each target
+// and compares the output, byte for byte, with testdata/golden. Run
+// "go test ./pkg/targets -update" after an intended output change and review
+// the diff.
+func TestGolden(t *testing.T) {
+	kiroInclusion, err := (&KiroCompiler{}).Configure(map[string]any{"inclusion": true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cases := map[string]compiler.TargetCompiler{
+		"markdown":       &MarkdownCompiler{},
+		"kiro":           &KiroCompiler{},
+		"kiro-inclusion": kiroInclusion,
+		"cursor":         &CursorCompiler{},
+		"claude":         &ClaudeCompiler{},
+		"copilot":        &CopilotCompiler{},
+		"gemini":         &GeminiCompiler{},
+		"json":           &JSONCompiler{},
+		"agentsmd":       &AgentsMDCompiler{},
+	}
+
+	files, err := filepath.Glob(filepath.Join("testdata", "resources", "*.yaml"))
+	if err != nil || len(files) == 0 {
+		t.Fatalf("no golden resources: %v", err)
+	}
+
+	for name, tc := range cases {
+		for _, file := range files {
+			resourceName := strings.TrimSuffix(filepath.Base(file), ".yaml")
+			t.Run(name+"/"+resourceName, func(t *testing.T) {
+				resource, err := loader.Load(file)
+				if err != nil {
+					t.Fatalf("Load() error = %v", err)
+				}
+				resource.Source = filepath.ToSlash(file)
+				results, err := tc.Compile(resource)
+				if err != nil {
+					t.Fatalf("Compile() error = %v", err)
+				}
+				if merging, ok := tc.(compiler.MergingTarget); ok {
+					if results, err = merging.Merge(results); err != nil {
+						t.Fatalf("Merge() error = %v", err)
+					}
+				}
+
+				var b strings.Builder
+				for _, result := range results {
+					b.WriteString("=== " + result.Path + " ===\n")
+					b.WriteString(result.Content)
+					b.WriteString("\n")
+				}
+				got := b.String()
+
+				golden := filepath.Join("testdata", "golden", name, resourceName+".golden")
+				if *update {
+					if err := os.MkdirAll(filepath.Dir(golden), 0755); err != nil {
+						t.Fatal(err)
+					}
+					if err := os.WriteFile(golden, []byte(got), 0644); err != nil {
+						t.Fatal(err)
+					}
+					return
+				}
+				want, err := os.ReadFile(golden)
+				if err != nil {
+					t.Fatalf("missing golden file (run with -update): %v", err)
+				}
+				if got != string(want) {
+					t.Errorf("output differs from %s:\n--- got ---\n%s\n--- want ---\n%s", golden, got, want)
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,254 @@
+package targets
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/jomadu/ai-resource-compiler-go/internal/format"
+	"github.com/jomadu/ai-resource-compiler-go/pkg/compiler"
+	"gopkg.in/yaml.v3"
+)
+
+// enforcementHeader matches the heading arc writes above a rule's body,
+// e.g. "# No Secrets (MUST)".
+var enforcementHeader = regexp.MustCompile(`^# (.*) \(([A-Z][A-Z0-9_-]*)\)$`)
+
+// importedFile is a rule file split into its frontmatter and body, with
+// what arc adds to the rules it compiles read back from the body: the
+// metadata block and the enforcement header. A hand-written file's first
+// heading is read as the rule's name.
+type importedFile struct {
+	frontmatter []byte // YAML between the opening "---" lines, nil if none
+
+	id          string
+	name        string
+	description string
+	enforcement string // "" unless read from the metadata block or header
+	scope       []format.ScopeEntry
+	body        string
+}
+
+// importedMetadata is the metadata block of a compiled rule: the rule's
+// fields at the top level for a standalone Rule, under rule for a rule of
+// a Ruleset.
+type importedMetadata struct {
+	importedRuleMetadata `yaml:",inline"`
+	Rule                 *importedRuleMetadata `yaml:"rule"`
+}
+
+type importedRuleMetadata struct {
+	ID          string `yaml:"id"`
+	Name        string `yaml:"name"`
+	Description string `yaml:"description"`
+	Enforcement string `yaml:"enforcement"`
+	Scope       *struct {
+		Files   []string `yaml:"files"`
+		Exclude []string `yaml:"exclude"`
+	} `yaml:"scope"`
+}
+
+// importGlobs is a glob list in frontmatter, written as a YAML list or as
+// one string of comma-separated globs.
+type importGlobs []string
+
+// UnmarshalYAML implements yaml.Unmarshaler.
+func (g *importGlobs) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		*g = nil
+		for _, glob := range strings.Split(node.Value, ",") {
+			if glob = strings.TrimSpace(glob); glob != "" {
+				*g = append(*g, glob)
+			}
+		}
+		return nil
+	}
+	var globs []string
+	if err := node.Decode(&globs); err != nil {
+		return err
+	}
+	*g = globs
+	return nil
+}
+
+// parseImported splits the rule file at p, relative to the directory
+// imported, into its frontmatter and body. The rule's ID is the file name
+// up to its first dot, or that of the metadata block.
+func parseImported(p string, content []byte) importedFile {
+	text := strings.ReplaceAll(string(content), "\r\n", "\n")
+	file := importedFile{id: importID(p)}
+
+	for range 2 {
+		block, rest, ok := cutYAMLBlock(strings.TrimLeft(text, "\n"))
+		if !ok {
+			break
+		}
+		var metadata importedMetadata
+		if yaml.Unmarshal([]byte(block), &metadata) == nil && (metadata.Enforcement != "" || metadata.Rule != nil && metadata.Rule.Enforcement != "") {
+			rule := metadata.importedRuleMetadata
+			if metadata.Rule != nil {
+				rule = *metadata.Rule
+			}
+			if rule.ID != "" {
+				file.id = rule.ID
+			}
+			file.name, file.description, file.enforcement = rule.Name, rule.Description, rule.Enforcement
+			if rule.Scope != nil {
+				file.scope = []format.ScopeEntry{{Files: rule.Scope.Files, Exclude: rule.Scope.Exclude}}
+			}
+			text = rest
+			break
+		}
+		if file.frontmatter != nil {
+			break
+		}
+		file.frontmatter = []byte(block)
+		text = rest
+	}
+
+	text = strings.TrimLeft(text, "\n")
+	heading, rest, _ := strings.Cut(text, "\n")
+	if m := enforcementHeader.FindStringSubmatch(heading); m != nil {
+		if _, ok := compiler.LookupEnforcement(strings.ToLower(m[2])); ok {
+			file.name, file.enforcement = m[1], strings.ToLower(m[2])
+			text = rest
+		}
+	} else if name, ok := strings.CutPrefix(heading, "# "); ok && file.name == "" && format.ValidateRuleName(name) == nil {
+		file.name = strings.TrimSpace(name)
+		text = rest
+	}
+	if i := strings.LastIndex(text, "\n<!-- source: "); i >= 0 && strings.HasSuffix(strings.TrimSpace(text), "-->") {
+		text = text[:i]
+	}
+	file.body = strings.TrimSpace(text)
+
+	if file.name == "" {
+		file.name = file.id
+	}
+	return file
+}
+
+// rule returns the imported rule, with the enforcement and scope read from
+// the file, or else those given, which the target derives from its
+// frontmatter. A description equal to the name is dropped, as targets
+// write the name when a rule has no description.
+func (f importedFile) rule(description, enforcement string, globs []string) compiler.ImportedRule {
+	if f.enforcement != "" {
+		enforcement = f.enforcement
+	}
+	scope := f.scope
+	if scope == nil {
+		scope = globsScope(globs)
+	}
+	if f.description != "" {
+		description = f.description
+	}
+	if description == f.name {
+		description = ""
+	}
+	body := f.body
+	return compiler.ImportedRule{
+		ID: f.id,
+		Rule: format.RuleItem{
+			Name:        f.name,
+			Description: description,
+			Enforcement: enforcement,
+			Scope:       scope,
+			Body:        format.Body{String: &body},
+		},
+	}
+}
+
+// cutYAMLBlock returns the YAML between the "---" line text starts with and
+// the next, and the text after it.
+func cutYAMLBlock(text string) (block, rest string, ok bool) {
+	text, ok = strings.CutPrefix(text, "---\n")
+	if !ok {
+		return "", "", false
+	}
+	if rest, ok := strings.CutPrefix(text, "---\n"); ok {
+		return "", rest, true
+	}
+	i := strings.Index(text, "\n---\n")
+	if i < 0 {
+		if strings.HasSuffix(text, "\n---") {
+			return text[:len(text)-4], "", true
+		}
+		return "", "", false
+	}
+	return text[:i+1], text[i+5:], true
+}
+
+// importID returns the rule ID for the file at p: its name up to the first
+// dot, with characters IDs cannot hold replaced by hyphens.
+func importID(p string) string {
+	name := path.Base(p)
+	if i := strings.Index(name, "."); i > 0 {
+		name = name[:i]
+	}
+	return strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_' {
+			return r
+		}
+		return '-'
+	}, name)
+}
+
+// globsScope returns the scope of a frontmatter glob list, globs prefixed
+// with "!" being exclusions, or nil for no globs.
+func globsScope(globs []string) []format.ScopeEntry {
+	var entry format.ScopeEntry
+	for _, glob := range globs {
+		if exclude, ok := strings.CutPrefix(glob, "!"); ok {
+			entry.Exclude = append(entry.Exclude, exclude)
+		} else {
+			entry.Files = append(entry.Files, glob)
+		}
+	}
+	if len(entry.Files) == 0 {
+		return nil
+	}
+	return []format.ScopeEntry{entry}
+}
+
+// decodeFrontmatter decodes the frontmatter of file into v, if it has any.
+// Tools accept frontmatter that is not valid YAML, such as the unquoted
+// glob in "globs: **/*.ts", so frontmatter that fails to parse is parsed
+// again with every value quoted.
+func (f importedFile) decodeFrontmatter(v any) error {
+	if len(f.frontmatter) == 0 {
+		return nil
+	}
+	err := yaml.Unmarshal(f.frontmatter, v)
+	if err == nil {
+		return nil
+	}
+	if yaml.Unmarshal(quoteFrontmatter(f.frontmatter), v) == nil {
+		return nil
+	}
+	return fmt.Errorf("invalid frontmatter: %w", err)
+}
+
+// quoteFrontmatter double-quotes the values of "key: value" and "- value"
+// lines that are not quoted already.
+func quoteFrontmatter(frontmatter []byte) []byte {
+	lines := strings.Split(string(frontmatter), "\n")
+	for i, line := range lines {
+		prefix, value := "", ""
+		if item, ok := strings.CutPrefix(strings.TrimLeft(line, " "), "- "); ok {
+			prefix, value = line[:len(line)-len(item)], item
+		} else if key, rest, ok := strings.Cut(line, ": "); ok && !strings.HasPrefix(strings.TrimSpace(line), "#") {
+			prefix, value = key+": ", rest
+		} else {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		if value == "" || value == "true" || value == "false" || strings.ContainsAny(value[:1], `"'[{`) {
+			continue
+		}
+		lines[i] = prefix + strconv.Quote(value)
+	}
+	return []byte(strings.Join(lines, "\n"))
+}
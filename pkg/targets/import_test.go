@@ -0,0 +1,157 @@
+package targets
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/jomadu/ai-resource-compiler-go/internal/format"
+	"github.com/jomadu/ai-resource-compiler-go/pkg/compiler"
+)
+
+func TestImportRule(t *testing.T) {
+	tests := []struct {
+		name     string
+		importer compiler.ImportingTarget
+		path     string
+		content  string
+		want     compiler.ImportedRule
+		skipped  bool
+	}{
+		{
+			name:     "cursor globs as a string",
+			importer: &CursorCompiler{},
+			path:     "go-style.mdc",
+			content:  "---\ndescription: Go conventions\nglobs: **/*.go, !**/*_test.go\nalwaysApply: false\n---\n# Go Style\n\nUse gofmt.\n",
+			want: compiler.ImportedRule{ID: "go-style", Rule: format.RuleItem{
+				Name: "Go Style", Description: "Go conventions", Enforcement: "should",
+				Scope: []format.ScopeEntry{{Files: []string{"**/*.go"}, Exclude: []string{"**/*_test.go"}}},
+				Body:  format.Body{String: strPtr("Use gofmt.")},
+			}},
+		},
+		{
+			name:     "cursor always applied",
+			importer: &CursorCompiler{},
+			path:     "sub/concise.mdc",
+			content:  "---\nalwaysApply: true\n---\nBe concise.",
+			want: compiler.ImportedRule{ID: "concise", Rule: format.RuleItem{
+				Name: "concise", Enforcement: "must", Body: format.Body{String: strPtr("Be concise.")},
+			}},
+		},
+		{
+			name:     "cursor skips other files",
+			importer: &CursorCompiler{},
+			path:     "README.md",
+			content:  "# Rules",
+			skipped:  true,
+		},
+		{
+			name:     "copilot applied everywhere",
+			importer: &CopilotCompiler{},
+			path:     "instructions/security.instructions.md",
+			content:  "---\napplyTo: \"**\"\n---\nNever log secrets.",
+			want: compiler.ImportedRule{ID: "security", Rule: format.RuleItem{
+				Name: "security", Enforcement: "must", Body: format.Body{String: strPtr("Never log secrets.")},
+			}},
+		},
+		{
+			name:     "copilot skips prompt files",
+			importer: &CopilotCompiler{},
+			path:     "prompts/review.prompt.md",
+			content:  "Review.",
+			skipped:  true,
+		},
+		{
+			name:     "kiro without frontmatter",
+			importer: &KiroCompiler{},
+			path:     "tech.md",
+			content:  "# Tech Stack\n\nWe use Go.",
+			want: compiler.ImportedRule{ID: "tech", Rule: format.RuleItem{
+				Name: "Tech Stack", Enforcement: "must", Body: format.Body{String: strPtr("We use Go.")},
+			}},
+		},
+		{
+			name:     "kiro manual",
+			importer: &KiroCompiler{},
+			path:     "deploy.md",
+			content:  "---\ninclusion: manual\n---\nDeploy with care.",
+			want: compiler.ImportedRule{ID: "deploy", Rule: format.RuleItem{
+				Name: "deploy", Enforcement: "may", Body: format.Body{String: strPtr("Deploy with care.")},
+			}},
+		},
+		{
+			name:     "claude paths",
+			importer: &ClaudeCompiler{},
+			path:     "api.md",
+			content:  "---\npaths:\n  - \"src/api/**\"\n---\nValidate input.",
+			want: compiler.ImportedRule{ID: "api", Rule: format.RuleItem{
+				Name: "api", Enforcement: "should", Scope: []format.ScopeEntry{{Files: []string{"src/api/**"}}},
+				Body: format.Body{String: strPtr("Validate input.")},
+			}},
+		},
+		{
+			name:     "claude skips skills",
+			importer: &ClaudeCompiler{},
+			path:     "review/SKILL.md",
+			content:  "---\nname: review\n---\nReview.",
+			skipped:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok, err := tt.importer.ImportRule(tt.path, []byte(tt.content))
+			if err != nil {
+				t.Fatalf("ImportRule() error = %v", err)
+			}
+			if ok == tt.skipped {
+				t.Fatalf("ImportRule() ok = %v, want %v", ok, !tt.skipped)
+			}
+			if ok && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ImportRule() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestImportRoundTrip checks that importing compiled rules gives back the
+// rules compiled.
+func TestImportRoundTrip(t *testing.T) {
+	ruleset := &format.Ruleset{
+		Metadata: format.Metadata{ID: "team", Name: "Team"},
+		Spec: format.RulesetSpec{
+			Rules: map[string]format.RuleItem{
+				"secrets": {Name: "No Secrets", Description: "Keep secrets out", Enforcement: "must",
+					Body: format.Body{String: strPtr("Never commit secrets.")}},
+				"naming": {Name: "Naming", Enforcement: "should",
+					Scope: []format.ScopeEntry{{Files: []string{"**/*.go"}, Exclude: []string{"**/*_test.go"}}},
+					Body:  format.Body{String: strPtr("Name things well.\n\nShort names in small scopes.")}},
+				"docs": {Name: "Docs", Enforcement: "may", Body: format.Body{String: strPtr("Document exported names.")}},
+			},
+			Order: []string{"docs", "naming", "secrets"},
+		},
+	}
+	resource := &compiler.Resource{APIVersion: "ai-resource/draft", Kind: "Ruleset", Spec: ruleset}
+	resource.Metadata.ID = "team"
+
+	for _, target := range []compiler.Target{compiler.TargetCursor, compiler.TargetCopilot, compiler.TargetKiro, compiler.TargetClaude} {
+		t.Run(string(target), func(t *testing.T) {
+			c := compiler.NewCompiler()
+			results, err := c.Compile(resource, compiler.CompileOptions{Targets: []compiler.Target{target}})
+			if err != nil {
+				t.Fatalf("Compile() error = %v", err)
+			}
+			files := make(map[string][]byte)
+			for _, result := range results {
+				files[result.Path] = []byte(result.Content)
+			}
+
+			imported, err := c.Import(target, "team", files)
+			if err != nil {
+				t.Fatalf("Import() error = %v", err)
+			}
+			got := imported.Spec.(*format.Ruleset).Spec.Rules
+			if !reflect.DeepEqual(got, ruleset.Spec.Rules) {
+				t.Errorf("Import() rules = %+v, want %+v", got, ruleset.Spec.Rules)
+			}
+		})
+	}
+}
@@ -0,0 +1,347 @@
+package targets
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/jomadu/ai-resource-compiler-go/internal/format"
+	"github.com/jomadu/ai-resource-compiler-go/pkg/compiler"
+)
+
+// jsonDocument is a rule, prompt, command, or context as the json target
+// emits it.
+// Bodies are resolved and variables expanded, so consumers never see
+// fragments. Command bodies keep their ${arg:name} references for consumers
+// to substitute.
+type jsonDocument struct {
+	Kind         string           `json:"kind"` // "rule", "prompt", "command", or "context"
+	ID           string           `json:"id"`
+	Namespace    string           `json:"namespace,omitempty"`
+	Collection   *jsonCollection  `json:"collection,omitempty"`
+	Name         string           `json:"name,omitempty"`
+	Description  string           `json:"description,omitempty"`
+	Enforcement  string           `json:"enforcement,omitempty"`
+	Tags         []string         `json:"tags,omitempty"` // the collection's tags followed by the item's own
+	Scope        []jsonScopeEntry `json:"scope,omitempty"`
+	AllowedTools []string         `json:"allowedTools,omitempty"`
+	Arguments    string           `json:"arguments,omitempty"`
+	Parameters   []jsonParameter  `json:"parameters,omitempty"`
+	Body         string           `json:"body"`
+	Source       string           `json:"source,omitempty"`
+}
+
+// jsonCollection identifies the ruleset or promptset an item belongs to.
+type jsonCollection struct {
+	ID          string `json:"id"`
+	Name        string `json:"name,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+// jsonScopeEntry is an entry of a rule's scope. Files includes the globs
+// its languages and directories stand for, so consumers that only read
+// files still match the same files.
+type jsonScopeEntry struct {
+	Files       []string `json:"files"`
+	Exclude     []string `json:"exclude,omitempty"`
+	Languages   []string `json:"languages,omitempty"`
+	Directories []string `json:"directories,omitempty"`
+}
+
+// jsonParameter is an argument of a command.
+type jsonParameter struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Required    bool   `json:"required,omitempty"`
+}
+
+// JSONCompiler emits each rule and prompt as a JSON document for tooling
+// that consumes compiled resources without parsing markdown. Documents carry
+// no metadata block, so the lean option has no effect; embedSource adds a
+// source field naming the file and item.
+type JSONCompiler struct {
+	ContentOptions
+}
+
+func init() {
+	compiler.RegisterDefaultTarget(compiler.TargetJSON, &JSONCompiler{})
+}
+
+func (j *JSONCompiler) Name() string {
+	return "json"
+}
+
+func (j *JSONCompiler) SupportedVersions() []string {
+	return []string{"ai-resource/draft"}
+}
+
+// Configure accepts the content options.
+func (j *JSONCompiler) Configure(options map[string]any) (compiler.TargetCompiler, error) {
+	if err := checkOptions(options, contentOptionNames...); err != nil {
+		return nil, err
+	}
+	configured := *j
+	if err := configured.ContentOptions.configure(options); err != nil {
+		return nil, err
+	}
+	return &configured, nil
+}
+
+func (j *JSONCompiler) Compile(resource *compiler.Resource) ([]compiler.CompilationResult, error) {
+	if resource.APIVersion != "ai-resource/draft" {
+		return nil, fmt.Errorf("%w: %s for json", compiler.ErrUnsupportedVersion, resource.APIVersion)
+	}
+
+	switch resource.Kind {
+	case "Rule":
+		return j.compileRule(resource)
+	case "Ruleset":
+		return j.compileRuleset(resource)
+	case "Prompt":
+		return j.compilePrompt(resource)
+	case "Promptset":
+		return j.compilePromptset(resource)
+	case "Command":
+		return j.compileCommand(resource)
+	case "Context":
+		return j.compileContext(resource)
+	default:
+		return nil, fmt.Errorf("%w: %s", compiler.ErrUnsupportedKind, resource.Kind)
+	}
+}
+
+// Explain describes how resource compiles to JSON documents.
+func (j *JSONCompiler) Explain(resource *compiler.Resource) ([]compiler.Explanation, error) {
+	return explain(j, resource, func(item explainItem) compiler.Explanation {
+		mappings := []compiler.Mapping{{Field: "metadata", Output: "id, namespace, name, and description fields"}}
+		if item.collection != "" {
+			mappings = append(mappings, compiler.Mapping{Field: "collection metadata", Output: "collection object"})
+		}
+		switch {
+		case item.rule:
+			mappings = append(mappings,
+				compiler.Mapping{Field: "enforcement: " + item.enforcement, Output: "enforcement field"},
+				compiler.Mapping{Field: scopeField(item.scope), Output: "scope field"})
+		case item.command:
+			mappings = append(mappings, compiler.Mapping{Field: "allowedTools, arguments", Output: "allowedTools, arguments (usage), and parameters fields"})
+		case !item.context:
+			mappings = append(mappings, compiler.Mapping{Field: "allowedTools, arguments", Output: "allowedTools and arguments fields"})
+		}
+		mappings = append(mappings, compiler.Mapping{Field: "body", Output: "body field, fragments resolved"})
+		if j.EmbedSource != "" {
+			mappings = append(mappings, compiler.Mapping{Field: "source", Output: "source field, file#" + item.ref()})
+		}
+		return compiler.Explanation{Path: item.path(".json"), Mappings: mappings}
+	})
+}
+
+func (j *JSONCompiler) compileRule(resource *compiler.Resource) ([]compiler.CompilationResult, error) {
+	rule := resource.Spec.(*format.Rule)
+
+	if err := format.ValidateID(rule.Metadata.ID); err != nil {
+		return nil, err
+	}
+	if err := format.ValidateRuleName(rule.Metadata.Name); err != nil {
+		return nil, err
+	}
+
+	doc := jsonDocument{
+		Kind:        "rule",
+		ID:          rule.Metadata.ID,
+		Namespace:   rule.Metadata.Namespace,
+		Name:        rule.Metadata.Name,
+		Description: rule.Metadata.Description,
+		Enforcement: rule.Spec.Enforcement,
+		Tags:        rule.Metadata.Tags,
+		Scope:       jsonScope(rule.Spec.Scope),
+		Body:        format.ResolveBody(rule.Spec.Body, rule.Spec.Fragments),
+		Source:      j.source(resource.Source, rule.Metadata.ID),
+	}
+	return j.results(format.BuildStandalonePath(rule.Metadata.ID, ".json"), doc)
+}
+
+func (j *JSONCompiler) compileRuleset(resource *compiler.Resource) ([]compiler.CompilationResult, error) {
+	ruleset := resource.Spec.(*format.Ruleset)
+
+	if err := format.ValidateID(ruleset.Metadata.ID); err != nil {
+		return nil, err
+	}
+
+	collection := &jsonCollection{ID: ruleset.Metadata.ID, Name: ruleset.Metadata.Name, Description: ruleset.Metadata.Description}
+	var results []compiler.CompilationResult
+	for _, ruleID := range ruleset.Spec.RuleIDs() {
+		if err := format.ValidateID(ruleID); err != nil {
+			return nil, err
+		}
+		ruleSpec := ruleset.Spec.Rules[ruleID]
+		if err := format.ValidateRuleName(ruleSpec.Name); err != nil {
+			return nil, err
+		}
+
+		doc := jsonDocument{
+			Kind:        "rule",
+			ID:          ruleID,
+			Namespace:   ruleset.Metadata.Namespace,
+			Collection:  collection,
+			Name:        ruleSpec.Name,
+			Description: ruleSpec.Description,
+			Enforcement: ruleSpec.Enforcement,
+			Tags:        format.MergeTags(ruleset.Metadata.Tags, ruleSpec.Tags),
+			Scope:       jsonScope(ruleSpec.Scope),
+			Body:        format.ResolveBody(ruleSpec.Body, ruleset.Spec.Fragments),
+			Source:      j.source(resource.Source, ruleset.Metadata.ID+"/"+ruleID),
+		}
+		itemResults, err := j.results(format.BuildCollectionPath(ruleset.Metadata.ID, ruleID, ".json"), doc)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, itemResults...)
+	}
+
+	return results, nil
+}
+
+func (j *JSONCompiler) compilePrompt(resource *compiler.Resource) ([]compiler.CompilationResult, error) {
+	prompt := resource.Spec.(*format.Prompt)
+
+	if err := format.ValidateID(prompt.Metadata.ID); err != nil {
+		return nil, err
+	}
+
+	doc := jsonDocument{
+		Kind:         "prompt",
+		ID:           prompt.Metadata.ID,
+		Namespace:    prompt.Metadata.Namespace,
+		Name:         prompt.Metadata.Name,
+		Description:  prompt.Metadata.Description,
+		Tags:         prompt.Metadata.Tags,
+		AllowedTools: prompt.Spec.AllowedTools,
+		Arguments:    prompt.Spec.Arguments,
+		Body:         format.ResolveBody(prompt.Spec.Body, prompt.Spec.Fragments),
+		Source:       j.source(resource.Source, prompt.Metadata.ID),
+	}
+	return j.results(format.BuildStandalonePath(prompt.Metadata.ID, ".json"), doc)
+}
+
+func (j *JSONCompiler) compilePromptset(resource *compiler.Resource) ([]compiler.CompilationResult, error) {
+	promptset := resource.Spec.(*format.Promptset)
+
+	if err := format.ValidateID(promptset.Metadata.ID); err != nil {
+		return nil, err
+	}
+
+	collection := &jsonCollection{ID: promptset.Metadata.ID, Name: promptset.Metadata.Name, Description: promptset.Metadata.Description}
+	var results []compiler.CompilationResult
+	for _, promptID := range promptset.Spec.PromptIDs() {
+		if err := format.ValidateID(promptID); err != nil {
+			return nil, err
+		}
+
+		promptSpec := promptset.Spec.Prompts[promptID]
+		doc := jsonDocument{
+			Kind:         "prompt",
+			ID:           promptID,
+			Namespace:    promptset.Metadata.Namespace,
+			Collection:   collection,
+			Name:         promptSpec.Name,
+			Description:  promptSpec.Description,
+			Tags:         format.MergeTags(promptset.Metadata.Tags, promptSpec.Tags),
+			AllowedTools: promptSpec.AllowedTools,
+			Arguments:    promptSpec.Arguments,
+			Body:         format.ResolveBody(promptSpec.Body, promptset.Spec.Fragments),
+			Source:       j.source(resource.Source, promptset.Metadata.ID+"/"+promptID),
+		}
+		itemResults, err := j.results(format.BuildCollectionPath(promptset.Metadata.ID, promptID, ".json"), doc)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, itemResults...)
+	}
+
+	return results, nil
+}
+
+func (j *JSONCompiler) compileCommand(resource *compiler.Resource) ([]compiler.CompilationResult, error) {
+	command := resource.Spec.(*format.Command)
+
+	if err := format.ValidateID(command.Metadata.ID); err != nil {
+		return nil, err
+	}
+
+	doc := jsonDocument{
+		Kind:         "command",
+		ID:           command.Metadata.ID,
+		Namespace:    command.Metadata.Namespace,
+		Name:         command.Metadata.Name,
+		Description:  command.Metadata.Description,
+		Tags:         command.Metadata.Tags,
+		AllowedTools: command.Spec.AllowedTools,
+		Arguments:    argumentHint(command.Spec.Arguments),
+		Body:         format.ResolveBody(command.Spec.Body, command.Spec.Fragments),
+		Source:       j.source(resource.Source, command.Metadata.ID),
+	}
+	for _, arg := range command.Spec.Arguments {
+		doc.Parameters = append(doc.Parameters, jsonParameter(arg))
+	}
+	return j.results(format.BuildStandalonePath(command.Metadata.ID, ".json"), doc)
+}
+
+func (j *JSONCompiler) compileContext(resource *compiler.Resource) ([]compiler.CompilationResult, error) {
+	context := resource.Spec.(*format.Context)
+
+	if err := format.ValidateID(context.Metadata.ID); err != nil {
+		return nil, err
+	}
+
+	doc := jsonDocument{
+		Kind:        "context",
+		ID:          context.Metadata.ID,
+		Namespace:   context.Metadata.Namespace,
+		Name:        context.Metadata.Name,
+		Description: context.Metadata.Description,
+		Tags:        context.Metadata.Tags,
+		Body:        format.ResolveBody(context.Spec.Body, context.Spec.Fragments),
+		Source:      j.source(resource.Source, context.Metadata.ID),
+	}
+	return j.results(format.BuildStandalonePath(context.Metadata.ID, ".json"), doc)
+}
+
+// results encodes doc as the single result at path. Markdown in bodies is
+// kept readable rather than escaped for embedding in HTML.
+func (j *JSONCompiler) results(path string, doc jsonDocument) ([]compiler.CompilationResult, error) {
+	var b strings.Builder
+	encoder := json.NewEncoder(&b)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(doc); err != nil {
+		return nil, err
+	}
+	return []compiler.CompilationResult{{Path: path, Content: b.String()}}, nil
+}
+
+// source returns the source field for the item ref, or "" unless
+// EmbedSource is set.
+func (j *JSONCompiler) source(source, ref string) string {
+	if j.EmbedSource == "" {
+		return ""
+	}
+	if source == "" {
+		return ref
+	}
+	return filepath.ToSlash(source) + "#" + ref
+}
+
+// jsonScope converts a rule's scope, keeping each entry's files together.
+func jsonScope(scope []format.ScopeEntry) []jsonScopeEntry {
+	var entries []jsonScopeEntry
+	for _, entry := range scope {
+		entries = append(entries, jsonScopeEntry{
+			Files:       format.ScopeIncludes([]format.ScopeEntry{entry}),
+			Exclude:     entry.Exclude,
+			Languages:   entry.Languages,
+			Directories: entry.Directories,
+		})
+	}
+	return entries
+}
@@ -0,0 +1,58 @@
+package targets
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/jomadu/ai-resource-compiler-go/internal/format"
+	"github.com/jomadu/ai-resource-compiler-go/pkg/compiler"
+)
+
+func TestJSONCompiler_CompileRule(t *testing.T) {
+	configured, err := (&JSONCompiler{}).Configure(map[string]any{"embedSource": "path"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	resource := &compiler.Resource{
+		APIVersion: "ai-resource/draft",
+		Kind:       "Rule",
+		Source:     "rules/test.yaml",
+		Spec: &format.Rule{
+			Metadata: format.Metadata{ID: "testRule", Name: "Test Rule"},
+			Spec: format.RuleSpec{
+				Enforcement: "must",
+				Body:        format.Body{Array: []string{"Use <T> && more.", "$tail"}},
+				Fragments:   map[string]string{"tail": "keep it short"},
+			},
+		},
+	}
+
+	results, err := configured.Compile(resource)
+	if err != nil {
+		t.Fatalf("Compile() error = %v", err)
+	}
+	if len(results) != 1 || results[0].Path != "testRule.json" {
+		t.Fatalf("Compile() = %+v, want testRule.json", results)
+	}
+
+	var doc map[string]any
+	if err := json.Unmarshal([]byte(results[0].Content), &doc); err != nil {
+		t.Fatalf("Compile() produced invalid JSON: %v\n%s", err, results[0].Content)
+	}
+	want := map[string]any{
+		"kind":        "rule",
+		"id":          "testRule",
+		"name":        "Test Rule",
+		"enforcement": "must",
+		"body":        "Use <T> && more.\n\nkeep it short",
+		"source":      "rules/test.yaml#testRule",
+	}
+	for field, value := range want {
+		if doc[field] != value {
+			t.Errorf("%s = %#v, want %#v", field, doc[field], value)
+		}
+	}
+	if _, ok := doc["scope"]; ok {
+		t.Error("unscoped rule has a scope field")
+	}
+}
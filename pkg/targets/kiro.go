@@ -2,12 +2,53 @@ package targets
 
 import (
 	"fmt"
+	"path"
+	"strings"
 
 	"github.com/jomadu/ai-resource-compiler-go/internal/format"
 	"github.com/jomadu/ai-resource-compiler-go/pkg/compiler"
+	"gopkg.in/yaml.v3"
 )
 
-type KiroCompiler struct{}
+// Kiro steering inclusion modes.
+const (
+	KiroInclusionAlways    = "always"    // loaded into every interaction
+	KiroInclusionFileMatch = "fileMatch" // loaded when a file matches fileMatchPattern
+	KiroInclusionManual    = "manual"    // loaded when referenced with #name
+)
+
+// DefaultKiroInclusion maps enforcement levels to Kiro inclusion modes: must
+// rules always load, should rules load for matching files, and may rules are
+// manual. Levels missing from it use the inclusion mode of their activation;
+// see kiroActivations.
+var DefaultKiroInclusion = map[string]string{
+	"may":    KiroInclusionManual,
+	"should": KiroInclusionFileMatch,
+	"must":   KiroInclusionAlways,
+}
+
+// kiroActivations maps enforcement activations to Kiro inclusion modes.
+// Kiro has no agent-requested mode, so those rules are manual.
+var kiroActivations = map[compiler.Activation]string{
+	compiler.ActivationAlways:    KiroInclusionAlways,
+	compiler.ActivationScoped:    KiroInclusionFileMatch,
+	compiler.ActivationOnRequest: KiroInclusionManual,
+	compiler.ActivationManual:    KiroInclusionManual,
+}
+
+// KiroCompiler compiles resources to Kiro steering files and prompts.
+type KiroCompiler struct {
+	ContentOptions
+
+	// Inclusion maps enforcement levels to the steering inclusion modes of
+	// the inclusion frontmatter every rule opens with. Levels missing from
+	// the map, or all levels when it is nil, use DefaultKiroInclusion.
+	Inclusion map[string]string
+
+	// OmitInclusion leaves rules without frontmatter, so Kiro includes every
+	// steering file in every interaction.
+	OmitInclusion bool
+}
 
 func init() {
 	compiler.RegisterDefaultTarget(compiler.TargetKiro, &KiroCompiler{})
@@ -21,9 +62,42 @@ func (k *KiroCompiler) SupportedVersions() []string {
 	return []string{"ai-resource/draft"}
 }
 
+// DefaultOutputDir returns .kiro/steering, where Kiro reads steering files.
+func (k *KiroCompiler) DefaultOutputDir() string {
+	return ".kiro/steering"
+}
+
+// AddBanner adds banner as a comment; see commentBanner.
+func (k *KiroCompiler) AddBanner(path, content, banner string) string {
+	return commentBanner(path, content, banner)
+}
+
+// Configure accepts the content options and inclusion (true for
+// DefaultKiroInclusion, false for no frontmatter, or a map of enforcement
+// level to inclusion mode).
+func (k *KiroCompiler) Configure(options map[string]any) (compiler.TargetCompiler, error) {
+	if err := checkOptions(options, append(contentOptionNames, "inclusion")...); err != nil {
+		return nil, err
+	}
+	configured := *k
+	if err := configured.ContentOptions.configure(options); err != nil {
+		return nil, err
+	}
+	inclusion, set, err := enforcementMappingOption(options, "inclusion",
+		KiroInclusionAlways, KiroInclusionFileMatch, KiroInclusionManual)
+	if err != nil {
+		return nil, err
+	}
+	if set {
+		configured.Inclusion = inclusion
+		configured.OmitInclusion = inclusion == nil
+	}
+	return &configured, nil
+}
+
 func (k *KiroCompiler) Compile(resource *compiler.Resource) ([]compiler.CompilationResult, error) {
 	if resource.APIVersion != "ai-resource/draft" {
-		return nil, fmt.Errorf("unsupported apiVersion: %s for kiro", resource.APIVersion)
+		return nil, fmt.Errorf("%w: %s for kiro", compiler.ErrUnsupportedVersion, resource.APIVersion)
 	}
 
 	switch resource.Kind {
@@ -35,11 +109,49 @@ func (k *KiroCompiler) Compile(resource *compiler.Resource) ([]compiler.Compilat
 		return k.compilePrompt(resource)
 	case "Promptset":
 		return k.compilePromptset(resource)
+	case "Command":
+		return k.compileCommand(resource)
+	case "Context":
+		return compileContext(resource, agentsContextFile)
 	default:
-		return nil, fmt.Errorf("unsupported kind: %s", resource.Kind)
+		return nil, fmt.Errorf("%w: %s", compiler.ErrUnsupportedKind, resource.Kind)
 	}
 }
 
+// Merge joins the sections of every Context into one AGENTS.md. Other results
+// are returned unchanged.
+func (k *KiroCompiler) Merge(results []compiler.CompilationResult) ([]compiler.CompilationResult, error) {
+	return mergeContext(results, agentsContextFile), nil
+}
+
+// Explain describes how resource compiles to Kiro steering files and prompts.
+func (k *KiroCompiler) Explain(resource *compiler.Resource) ([]compiler.Explanation, error) {
+	return explain(k, resource, func(item explainItem) compiler.Explanation {
+		if item.context {
+			return contextExplanation(item, agentsContextFile)
+		}
+		var mappings []compiler.Mapping
+		if item.rule {
+			enforcement := "enforcement: " + item.enforcement
+			switch inclusion := k.inclusion(item.scope, item.enforcement); inclusion {
+			case "":
+				mappings = append(mappings, compiler.Mapping{Field: enforcement, Output: "no frontmatter (inclusion: false)"})
+			case KiroInclusionFileMatch:
+				mappings = append(mappings,
+					compiler.Mapping{Field: enforcement, Output: "inclusion: " + inclusion},
+					compiler.Mapping{Field: scopeField(item.scope), Output: "fileMatchPattern: " + strings.Join(extractScopeFiles(item.scope), ", ")})
+			default:
+				mappings = append(mappings, compiler.Mapping{Field: enforcement, Output: "inclusion: " + inclusion})
+			}
+		}
+		if item.command && len(item.commandArguments) > 0 {
+			mappings = append(argumentMapping(item.commandArguments, namedArgument),
+				compiler.Mapping{Field: "arguments", Output: "\"Arguments\" section after the body"})
+		}
+		return compiler.Explanation{Path: item.path(".md"), Mappings: append(mappings, k.contentMappings(item)...)}
+	})
+}
+
 func (k *KiroCompiler) compileRule(resource *compiler.Resource) ([]compiler.CompilationResult, error) {
 	rule := resource.Spec.(*format.Rule)
 	
@@ -51,7 +163,7 @@ func (k *KiroCompiler) compileRule(resource *compiler.Resource) ([]compiler.Comp
 	}
 
 	path := format.BuildStandalonePath(rule.Metadata.ID, ".md")
-	content := format.GenerateRuleMetadataBlockFromRule(rule)
+	content := k.frontmatter(rule.Spec.Scope, rule.Spec.Enforcement) + k.ruleContent(rule, resource.Source)
 
 	return []compiler.CompilationResult{{Path: path, Content: content}}, nil
 }
@@ -64,7 +176,7 @@ func (k *KiroCompiler) compileRuleset(resource *compiler.Resource) ([]compiler.C
 	}
 
 	var results []compiler.CompilationResult
-	for ruleID := range ruleset.Spec.Rules {
+	for _, ruleID := range ruleset.Spec.RuleIDs() {
 		if err := format.ValidateID(ruleID); err != nil {
 			return nil, err
 		}
@@ -74,7 +186,7 @@ func (k *KiroCompiler) compileRuleset(resource *compiler.Resource) ([]compiler.C
 		}
 
 		path := format.BuildCollectionPath(ruleset.Metadata.ID, ruleID, ".md")
-		content := format.GenerateRuleMetadataBlockFromRuleset(ruleset, ruleID)
+		content := k.frontmatter(ruleSpec.Scope, ruleSpec.Enforcement) + k.rulesetRuleContent(ruleset, ruleID, resource.Source)
 
 		results = append(results, compiler.CompilationResult{Path: path, Content: content})
 	}
@@ -103,7 +215,7 @@ func (k *KiroCompiler) compilePromptset(resource *compiler.Resource) ([]compiler
 	}
 
 	var results []compiler.CompilationResult
-	for promptID := range promptset.Spec.Prompts {
+	for _, promptID := range promptset.Spec.PromptIDs() {
 		if err := format.ValidateID(promptID); err != nil {
 			return nil, err
 		}
@@ -117,3 +229,88 @@ func (k *KiroCompiler) compilePromptset(resource *compiler.Resource) ([]compiler
 
 	return results, nil
 }
+
+// frontmatter returns the steering inclusion frontmatter for a rule followed
+// by a newline, or "" with OmitInclusion.
+func (k *KiroCompiler) frontmatter(scope []format.ScopeEntry, enforcement string) string {
+	inclusion := k.inclusion(scope, enforcement)
+	if inclusion == "" {
+		return ""
+	}
+	patterns := extractScopeFiles(scope)
+
+	frontmatter := yaml.Node{Kind: yaml.MappingNode}
+	frontmatter.Content = append(frontmatter.Content,
+		&yaml.Node{Kind: yaml.ScalarNode, Value: "inclusion"},
+		&yaml.Node{Kind: yaml.ScalarNode, Value: inclusion})
+	if inclusion == KiroInclusionFileMatch {
+		var pattern yaml.Node
+		if len(patterns) == 1 {
+			pattern = *format.GlobNode(patterns[0])
+		} else {
+			pattern.Encode(format.Globs(patterns))
+		}
+		frontmatter.Content = append(frontmatter.Content,
+			&yaml.Node{Kind: yaml.ScalarNode, Value: "fileMatchPattern"}, &pattern)
+	}
+
+	return encodeFrontmatter(&frontmatter) + "\n"
+}
+
+// inclusion returns the inclusion mode of a rule, or "" with OmitInclusion.
+// A fileMatch rule without scope globs has nothing to match, so it is always
+// included instead.
+func (k *KiroCompiler) inclusion(scope []format.ScopeEntry, enforcement string) string {
+	if k.OmitInclusion {
+		return ""
+	}
+	inclusion, ok := k.Inclusion[enforcement]
+	if !ok {
+		inclusion = defaultMode(DefaultKiroInclusion, kiroActivations, enforcement)
+	}
+	if inclusion == KiroInclusionFileMatch && len(extractScopeFiles(scope)) == 0 {
+		inclusion = KiroInclusionAlways
+	}
+	return inclusion
+}
+
+// ImportRule reads a steering file: always included files are must,
+// fileMatch files should, and manual files may, unless the file has arc's
+// enforcement header. Files without inclusion frontmatter are always
+// included. AGENTS.md is not a steering file and is skipped.
+func (k *KiroCompiler) ImportRule(p string, content []byte) (compiler.ImportedRule, bool, error) {
+	if !strings.HasSuffix(p, ".md") || path.Base(p) == agentsContextFile {
+		return compiler.ImportedRule{}, false, nil
+	}
+	file := parseImported(p, content)
+	var frontmatter struct {
+		Inclusion        string      `yaml:"inclusion"`
+		FileMatchPattern importGlobs `yaml:"fileMatchPattern"`
+	}
+	if err := file.decodeFrontmatter(&frontmatter); err != nil {
+		return compiler.ImportedRule{}, false, err
+	}
+	enforcement := "must"
+	switch frontmatter.Inclusion {
+	case KiroInclusionFileMatch:
+		enforcement = "should"
+	case KiroInclusionManual:
+		enforcement = "may"
+	}
+	return file.rule("", enforcement, frontmatter.FileMatchPattern), true, nil
+}
+
+// compileCommand compiles a command like a prompt, with its arguments
+// written as <name> and listed after the body.
+func (k *KiroCompiler) compileCommand(resource *compiler.Resource) ([]compiler.CompilationResult, error) {
+	command := resource.Spec.(*format.Command)
+
+	if err := format.ValidateID(command.Metadata.ID); err != nil {
+		return nil, err
+	}
+
+	path := format.BuildStandalonePath(command.Metadata.ID, ".md")
+	content := commandBody(command, namedArgument) + argumentsSection(command.Spec.Arguments)
+
+	return []compiler.CompilationResult{{Path: path, Content: content}}, nil
+}
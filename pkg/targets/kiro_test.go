@@ -77,10 +77,7 @@ func TestKiroCompiler_CompileRuleset(t *testing.T) {
 				Name:        "Test Ruleset",
 				Description: "A test ruleset",
 			},
-			Spec: struct {
-				Rules     map[string]format.RuleItem
-				Fragments map[string]string
-			}{
+			Spec: format.RulesetSpec{
 				Rules: map[string]format.RuleItem{
 					"rule1": {
 						Name:        "Rule One",
@@ -162,10 +159,7 @@ func TestKiroCompiler_CompilePromptset(t *testing.T) {
 				Name:        "Test Promptset",
 				Description: "A test promptset",
 			},
-			Spec: struct {
-				Prompts   map[string]format.PromptItem
-				Fragments map[string]string
-			}{
+			Spec: format.PromptsetSpec{
 				Prompts: map[string]format.PromptItem{
 					"prompt1": {
 						Body: format.Body{String: strPtr("First prompt")},
@@ -195,3 +189,105 @@ func TestKiroCompiler_CompilePromptset(t *testing.T) {
 		t.Error("Missing testPromptset_prompt2.md")
 	}
 }
+
+func TestKiroCompiler_Inclusion(t *testing.T) {
+	tests := []struct {
+		name        string
+		inclusion   any
+		enforcement string
+		scope       []format.ScopeEntry
+		want        string
+	}{
+		{
+			name:        "must always",
+			inclusion:   true,
+			enforcement: "must",
+			scope:       []format.ScopeEntry{{Files: []string{"**/*.go"}}},
+			want:        "---\ninclusion: always\n---\n",
+		},
+		{
+			name:        "default",
+			enforcement: "should",
+			scope:       []format.ScopeEntry{{Files: []string{"**/*.go"}}},
+			want:        "---\ninclusion: fileMatch\nfileMatchPattern: \"**/*.go\"\n---\n",
+		},
+		{
+			name:        "should file match",
+			inclusion:   true,
+			enforcement: "should",
+			scope:       []format.ScopeEntry{{Files: []string{"**/*.go"}}},
+			want:        "---\ninclusion: fileMatch\nfileMatchPattern: \"**/*.go\"\n---\n",
+		},
+		{
+			name:        "should with several patterns",
+			inclusion:   true,
+			enforcement: "should",
+			scope:       []format.ScopeEntry{{Files: []string{"**/*.ts", "**/*.tsx"}}},
+			want:        "---\ninclusion: fileMatch\nfileMatchPattern:\n  - \"**/*.ts\"\n  - \"**/*.tsx\"\n---\n",
+		},
+		{
+			name:        "should without scope",
+			inclusion:   true,
+			enforcement: "should",
+			want:        "---\ninclusion: always\n---\n",
+		},
+		{
+			name:        "may manual",
+			inclusion:   true,
+			enforcement: "may",
+			want:        "---\ninclusion: manual\n---\n",
+		},
+		{
+			name:        "should overridden to manual",
+			inclusion:   map[string]any{"should": "manual"},
+			enforcement: "should",
+			scope:       []format.ScopeEntry{{Files: []string{"**/*.go"}}},
+			want:        "---\ninclusion: manual\n---\n",
+		},
+		{
+			name:        "disabled",
+			inclusion:   false,
+			enforcement: "must",
+			want:        "---\nid: testRule\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var k compiler.TargetCompiler = &KiroCompiler{}
+			if tt.inclusion != nil {
+				var err error
+				if k, err = k.(*KiroCompiler).Configure(map[string]any{"inclusion": tt.inclusion}); err != nil {
+					t.Fatalf("Configure() error = %v", err)
+				}
+			}
+			resource := &compiler.Resource{
+				APIVersion: "ai-resource/draft",
+				Kind:       "Rule",
+				Spec: &format.Rule{
+					Metadata: format.Metadata{ID: "testRule", Name: "Test Rule"},
+					Spec: format.RuleSpec{
+						Enforcement: tt.enforcement,
+						Scope:       tt.scope,
+						Body:        format.Body{String: strPtr("Rule body content")},
+					},
+				},
+			}
+
+			results, err := k.Compile(resource)
+			if err != nil {
+				t.Fatalf("Compile() error = %v", err)
+			}
+			if !strings.HasPrefix(results[0].Content, tt.want) {
+				t.Errorf("Content = %q, want prefix %q", results[0].Content, tt.want)
+			}
+		})
+	}
+}
+
+func TestKiroCompiler_InclusionErrors(t *testing.T) {
+	k := &KiroCompiler{}
+	if _, err := k.Configure(map[string]any{"inclusion": map[string]any{"should": "auto"}}); err == nil {
+		t.Error("Configure() expected error for unknown inclusion mode")
+	}
+}
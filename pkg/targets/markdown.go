@@ -7,7 +7,9 @@ import (
 	"github.com/jomadu/ai-resource-compiler-go/pkg/compiler"
 )
 
-type MarkdownCompiler struct{}
+type MarkdownCompiler struct {
+	ContentOptions
+}
 
 func init() {
 	compiler.RegisterDefaultTarget(compiler.TargetMarkdown, &MarkdownCompiler{})
@@ -21,9 +23,26 @@ func (m *MarkdownCompiler) SupportedVersions() []string {
 	return []string{"ai-resource/draft"}
 }
 
+// AddBanner adds banner as a comment; see commentBanner.
+func (m *MarkdownCompiler) AddBanner(path, content, banner string) string {
+	return commentBanner(path, content, banner)
+}
+
+// Configure accepts the content options.
+func (m *MarkdownCompiler) Configure(options map[string]any) (compiler.TargetCompiler, error) {
+	if err := checkOptions(options, contentOptionNames...); err != nil {
+		return nil, err
+	}
+	configured := *m
+	if err := configured.ContentOptions.configure(options); err != nil {
+		return nil, err
+	}
+	return &configured, nil
+}
+
 func (m *MarkdownCompiler) Compile(resource *compiler.Resource) ([]compiler.CompilationResult, error) {
 	if resource.APIVersion != "ai-resource/draft" {
-		return nil, fmt.Errorf("unsupported apiVersion: %s for markdown", resource.APIVersion)
+		return nil, fmt.Errorf("%w: %s for markdown", compiler.ErrUnsupportedVersion, resource.APIVersion)
 	}
 
 	switch resource.Kind {
@@ -35,11 +54,36 @@ func (m *MarkdownCompiler) Compile(resource *compiler.Resource) ([]compiler.Comp
 		return m.compilePrompt(resource)
 	case "Promptset":
 		return m.compilePromptset(resource)
+	case "Command":
+		return m.compileCommand(resource)
+	case "Context":
+		return compileContext(resource, agentsContextFile)
 	default:
-		return nil, fmt.Errorf("unsupported kind: %s", resource.Kind)
+		return nil, fmt.Errorf("%w: %s", compiler.ErrUnsupportedKind, resource.Kind)
 	}
 }
 
+// Merge joins the sections of every Context into one AGENTS.md. Other results
+// are returned unchanged.
+func (m *MarkdownCompiler) Merge(results []compiler.CompilationResult) ([]compiler.CompilationResult, error) {
+	return mergeContext(results, agentsContextFile), nil
+}
+
+// Explain describes how resource compiles to markdown.
+func (m *MarkdownCompiler) Explain(resource *compiler.Resource) ([]compiler.Explanation, error) {
+	return explain(m, resource, func(item explainItem) compiler.Explanation {
+		if item.context {
+			return contextExplanation(item, agentsContextFile)
+		}
+		var mappings []compiler.Mapping
+		if item.command && len(item.commandArguments) > 0 {
+			mappings = append(argumentMapping(item.commandArguments, namedArgument),
+				compiler.Mapping{Field: "arguments", Output: "\"Arguments\" section after the body"})
+		}
+		return compiler.Explanation{Path: item.path(".md"), Mappings: append(mappings, m.contentMappings(item)...)}
+	})
+}
+
 func (m *MarkdownCompiler) compileRule(resource *compiler.Resource) ([]compiler.CompilationResult, error) {
 	rule := resource.Spec.(*format.Rule)
 	
@@ -51,7 +95,7 @@ func (m *MarkdownCompiler) compileRule(resource *compiler.Resource) ([]compiler.
 	}
 
 	path := format.BuildStandalonePath(rule.Metadata.ID, ".md")
-	content := format.GenerateRuleMetadataBlockFromRule(rule)
+	content := m.ruleContent(rule, resource.Source)
 
 	return []compiler.CompilationResult{{Path: path, Content: content}}, nil
 }
@@ -64,7 +108,7 @@ func (m *MarkdownCompiler) compileRuleset(resource *compiler.Resource) ([]compil
 	}
 
 	var results []compiler.CompilationResult
-	for ruleID := range ruleset.Spec.Rules {
+	for _, ruleID := range ruleset.Spec.RuleIDs() {
 		if err := format.ValidateID(ruleID); err != nil {
 			return nil, err
 		}
@@ -74,7 +118,7 @@ func (m *MarkdownCompiler) compileRuleset(resource *compiler.Resource) ([]compil
 		}
 
 		path := format.BuildCollectionPath(ruleset.Metadata.ID, ruleID, ".md")
-		content := format.GenerateRuleMetadataBlockFromRuleset(ruleset, ruleID)
+		content := m.rulesetRuleContent(ruleset, ruleID, resource.Source)
 
 		results = append(results, compiler.CompilationResult{Path: path, Content: content})
 	}
@@ -103,7 +147,7 @@ func (m *MarkdownCompiler) compilePromptset(resource *compiler.Resource) ([]comp
 	}
 
 	var results []compiler.CompilationResult
-	for promptID := range promptset.Spec.Prompts {
+	for _, promptID := range promptset.Spec.PromptIDs() {
 		if err := format.ValidateID(promptID); err != nil {
 			return nil, err
 		}
@@ -117,3 +161,18 @@ func (m *MarkdownCompiler) compilePromptset(resource *compiler.Resource) ([]comp
 
 	return results, nil
 }
+
+// compileCommand compiles a command like a prompt, with its arguments
+// written as <name> and listed after the body.
+func (m *MarkdownCompiler) compileCommand(resource *compiler.Resource) ([]compiler.CompilationResult, error) {
+	command := resource.Spec.(*format.Command)
+
+	if err := format.ValidateID(command.Metadata.ID); err != nil {
+		return nil, err
+	}
+
+	path := format.BuildStandalonePath(command.Metadata.ID, ".md")
+	content := commandBody(command, namedArgument) + argumentsSection(command.Spec.Arguments)
+
+	return []compiler.CompilationResult{{Path: path, Content: content}}, nil
+}
@@ -1,6 +1,7 @@
 package targets
 
 import (
+	"errors"
 	"strings"
 	"testing"
 
@@ -77,10 +78,7 @@ func TestMarkdownCompiler_CompileRuleset(t *testing.T) {
 				Name:        "Test Ruleset",
 				Description: "A test ruleset",
 			},
-			Spec: struct {
-				Rules     map[string]format.RuleItem
-				Fragments map[string]string
-			}{
+			Spec: format.RulesetSpec{
 				Rules: map[string]format.RuleItem{
 					"rule1": {
 						Name:        "Rule One",
@@ -162,10 +160,7 @@ func TestMarkdownCompiler_CompilePromptset(t *testing.T) {
 				Name:        "Test Promptset",
 				Description: "A test promptset",
 			},
-			Spec: struct {
-				Prompts   map[string]format.PromptItem
-				Fragments map[string]string
-			}{
+			Spec: format.PromptsetSpec{
 				Prompts: map[string]format.PromptItem{
 					"prompt1": {
 						Body: format.Body{String: strPtr("First prompt")},
@@ -208,3 +203,60 @@ func contains(slice []string, item string) bool {
 	}
 	return false
 }
+
+func TestMarkdownCompiler_Lean(t *testing.T) {
+	c, err := (&MarkdownCompiler{}).Configure(map[string]any{"lean": true})
+	if err != nil {
+		t.Fatalf("Configure() error = %v", err)
+	}
+	resource := &compiler.Resource{
+		APIVersion: "ai-resource/draft",
+		Kind:       "Rule",
+		Spec: &format.Rule{
+			Metadata: format.Metadata{ID: "testRule", Name: "Test Rule"},
+			Spec: format.RuleSpec{
+				Enforcement: "must",
+				Body:        format.Body{String: strPtr("Rule body content")},
+			},
+		},
+	}
+
+	results, err := c.Compile(resource)
+	if err != nil {
+		t.Fatalf("Compile() error = %v", err)
+	}
+	if want := "# Test Rule (MUST)\n\nRule body content"; results[0].Content != want {
+		t.Errorf("Content = %q, want %q", results[0].Content, want)
+	}
+
+	if _, err := (&MarkdownCompiler{}).Configure(map[string]any{"alwaysApply": true}); err == nil {
+		t.Error("Configure() expected error for cursor-only option")
+	}
+}
+
+func TestMarkdownCompiler_Errors(t *testing.T) {
+	m := &MarkdownCompiler{}
+
+	_, err := m.Compile(&compiler.Resource{APIVersion: "ai-resource/v99", Kind: "Rule"})
+	if !errors.Is(err, compiler.ErrUnsupportedVersion) {
+		t.Errorf("Compile() error = %v, want ErrUnsupportedVersion", err)
+	}
+
+	_, err = m.Compile(&compiler.Resource{APIVersion: "ai-resource/draft", Kind: "Agent"})
+	if !errors.Is(err, compiler.ErrUnsupportedKind) {
+		t.Errorf("Compile() error = %v, want ErrUnsupportedKind", err)
+	}
+
+	_, err = m.Compile(&compiler.Resource{
+		APIVersion: "ai-resource/draft",
+		Kind:       "Rule",
+		Spec: &format.Rule{
+			Metadata: format.Metadata{ID: "bad id"},
+			Spec:     format.RuleSpec{Enforcement: "must", Body: format.Body{String: strPtr("body")}},
+		},
+	})
+	var verr *compiler.ValidationError
+	if !errors.As(err, &verr) || verr.Field != "id" {
+		t.Errorf("Compile() error = %v, want id ValidationError", err)
+	}
+}
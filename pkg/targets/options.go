@@ -0,0 +1,101 @@
+package targets
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/jomadu/ai-resource-compiler-go/pkg/compiler"
+)
+
+// checkOptions returns an error naming any option not in known.
+func checkOptions(options map[string]any, known ...string) error {
+	var unknown []string
+	for name := range options {
+		found := false
+		for _, k := range known {
+			if name == k {
+				found = true
+				break
+			}
+		}
+		if !found {
+			unknown = append(unknown, name)
+		}
+	}
+	if len(unknown) == 0 {
+		return nil
+	}
+	sort.Strings(unknown)
+	return fmt.Errorf("unknown option(s) %s (supported: %s)", strings.Join(unknown, ", "), strings.Join(known, ", "))
+}
+
+// boolOption returns the named boolean option, or nil if it is not set.
+func boolOption(options map[string]any, name string) (*bool, error) {
+	value, ok := options[name]
+	if !ok {
+		return nil, nil
+	}
+	b, ok := value.(bool)
+	if !ok {
+		return nil, fmt.Errorf("option %s must be a boolean, got %v", name, value)
+	}
+	return &b, nil
+}
+
+// enforcementMappingOption returns the named option mapping enforcement
+// levels to one of values. The option is either a boolean (true for an empty
+// mapping, so every level uses the target's defaults; false for nil) or a map
+// of enforcement level to value. set reports whether the option was given.
+func enforcementMappingOption(options map[string]any, name string, values ...string) (mapping map[string]string, set bool, err error) {
+	value, ok := options[name]
+	if !ok {
+		return nil, false, nil
+	}
+
+	switch v := value.(type) {
+	case bool:
+		if !v {
+			return nil, true, nil
+		}
+		return map[string]string{}, true, nil
+	case map[string]any:
+		mapping = make(map[string]string, len(v))
+		for enforcement, raw := range v {
+			if _, ok := compiler.LookupEnforcement(enforcement); !ok {
+				return nil, true, fmt.Errorf("option %s: unknown enforcement %q (expected one of %s)", name, enforcement, strings.Join(compiler.EnforcementNames(), ", "))
+			}
+			s, _ := raw.(string)
+			valid := false
+			for _, allowed := range values {
+				if s == allowed {
+					valid = true
+					break
+				}
+			}
+			if !valid {
+				return nil, true, fmt.Errorf("option %s: invalid value %v for %s (expected %s)", name, raw, enforcement, strings.Join(values, ", "))
+			}
+			mapping[enforcement] = s
+		}
+		return mapping, true, nil
+	default:
+		return nil, true, fmt.Errorf("option %s must be a boolean or a map of enforcement level to value, got %v", name, value)
+	}
+}
+
+// stringOption returns the named option, which must be one of values, or ""
+// if it is not set.
+func stringOption(options map[string]any, name string, values ...string) (string, error) {
+	value, ok := options[name]
+	if !ok {
+		return "", nil
+	}
+	s, _ := value.(string)
+	for _, allowed := range values {
+		if s == allowed {
+			return s, nil
+		}
+	}
+	return "", fmt.Errorf("option %s must be one of %s, got %v", name, strings.Join(values, ", "), value)
+}
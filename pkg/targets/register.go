@@ -1,4 +1,23 @@
 package targets
 
+import "github.com/jomadu/ai-resource-compiler-go/pkg/compiler"
+
 // This file ensures all target compilers are registered via their init() functions.
 // Import this package to automatically register all built-in targets.
+
+// Defaults returns new instances of the built-in target compilers, for
+// compilers that register their targets explicitly:
+//
+//	c := compiler.NewCompiler(compiler.WithoutDefaults(), compiler.WithTargets(targets.Defaults()...))
+func Defaults() []compiler.TargetCompiler {
+	return []compiler.TargetCompiler{
+		&AgentsMDCompiler{},
+		&ClaudeCompiler{},
+		&CopilotCompiler{},
+		&CursorCompiler{},
+		&GeminiCompiler{},
+		&JSONCompiler{},
+		&KiroCompiler{},
+		&MarkdownCompiler{},
+	}
+}
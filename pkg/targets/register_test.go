@@ -0,0 +1,15 @@
+package targets
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/jomadu/ai-resource-compiler-go/pkg/compiler"
+)
+
+func TestDefaults(t *testing.T) {
+	explicit := compiler.NewCompiler(compiler.WithoutDefaults(), compiler.WithTargets(Defaults()...))
+	if got, want := explicit.Targets(), compiler.NewCompiler().Targets(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Defaults() targets = %v, want the registered defaults %v", got, want)
+	}
+}